@@ -24,6 +24,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/api/resource"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -35,6 +36,8 @@ import (
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/clock"
+	gwclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+	gwinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
 
 	"github.com/jetstack/cert-manager/cmd/controller/app/options"
 	"github.com/jetstack/cert-manager/pkg/acme/accounts"
@@ -42,11 +45,15 @@ import (
 	intscheme "github.com/jetstack/cert-manager/pkg/client/clientset/versioned/scheme"
 	informers "github.com/jetstack/cert-manager/pkg/client/informers/externalversions"
 	"github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/controller/cabundle"
 	"github.com/jetstack/cert-manager/pkg/controller/clusterissuers"
+	"github.com/jetstack/cert-manager/pkg/feature"
 	dnsutil "github.com/jetstack/cert-manager/pkg/issuer/acme/dns/util"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/metrics"
 	"github.com/jetstack/cert-manager/pkg/util"
+	utilfeature "github.com/jetstack/cert-manager/pkg/util/feature"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 )
 
 const controllerAgentName = "cert-manager"
@@ -67,6 +74,18 @@ func Run(opts *options.ControllerOptions, stopCh <-chan struct{}) {
 		os.Exit(1)
 	}
 
+	if opts.CRDWaitTimeout > 0 {
+		crdClient, err := apiextensionsclient.NewForConfig(kubeCfg)
+		if err != nil {
+			log.Error(err, "error creating apiextensions client")
+			os.Exit(1)
+		}
+		if err := waitForCRDsEstablished(rootCtx, crdClient, requiredCRDs, crdWaitInterval, opts.CRDWaitTimeout); err != nil {
+			log.Error(err, "cert-manager CustomResourceDefinitions are not ready")
+			os.Exit(1)
+		}
+	}
+
 	enabledControllers := opts.EnabledControllers()
 	log.Info(fmt.Sprintf("enabled controllers: %s", enabledControllers.List()))
 
@@ -87,8 +106,8 @@ func Run(opts *options.ControllerOptions, stopCh <-chan struct{}) {
 				continue
 			}
 
-			// don't run clusterissuers controller if scoped to a single namespace
-			if ctx.Namespace != "" && n == clusterissuers.ControllerName {
+			// don't run controllers that rely on ClusterIssuers if scoped to a single namespace
+			if ctx.Namespace != "" && (n == clusterissuers.ControllerName || n == cabundle.ControllerName) {
 				log.V(logf.InfoLevel).Info("not starting controller as cert-manager has been scoped to a single namespace")
 				continue
 			}
@@ -116,9 +135,15 @@ func Run(opts *options.ControllerOptions, stopCh <-chan struct{}) {
 		log.V(logf.DebugLevel).Info("starting shared informer factories")
 		ctx.SharedInformerFactory.Start(stopCh)
 		ctx.KubeSharedInformerFactory.Start(stopCh)
+		if ctx.GWShared != nil {
+			ctx.GWShared.Start(stopCh)
+		}
 		wg.Wait()
 		log.V(logf.InfoLevel).Info("control loops exited")
 		ctx.Metrics.Shutdown(metricsServer)
+		if err := tracing.Shutdown(context.TODO(), ctx.TracerProvider); err != nil {
+			log.Error(err, "error exporting buffered traces")
+		}
 		os.Exit(0)
 	}
 
@@ -163,6 +188,16 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 		return nil, nil, fmt.Errorf("error creating kubernetes client: %s", err.Error())
 	}
 
+	// Create a Gateway API client. This is only done if Gateway API support
+	// is enabled, as the Gateway API CRDs may not be installed.
+	var gwcl gwclient.Interface
+	if utilfeature.DefaultFeatureGate.Enabled(feature.ExperimentalGatewayAPISupport) {
+		gwcl, err = gwclient.NewForConfig(kubeCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating gateway api client: %s", err.Error())
+		}
+	}
+
 	nameservers := opts.DNS01RecursiveNameservers
 	if len(nameservers) == 0 {
 		nameservers = dnsutil.RecursiveNameservers
@@ -201,21 +236,33 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 
 	sharedInformerFactory := informers.NewSharedInformerFactoryWithOptions(intcl, resyncPeriod, informers.WithNamespace(opts.Namespace))
 	kubeSharedInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(cl, resyncPeriod, kubeinformers.WithNamespace(opts.Namespace))
+	var gwSharedInformerFactory gwinformers.SharedInformerFactory
+	if gwcl != nil {
+		gwSharedInformerFactory = gwinformers.NewSharedInformerFactoryWithOptions(gwcl, resyncPeriod, gwinformers.WithNamespace(opts.Namespace))
+	}
 
 	acmeAccountRegistry := accounts.NewDefaultRegistry()
 
+	tracerProvider, err := tracing.NewTracerProvider(opts.TracingExporter, controllerAgentName, os.Stdout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating tracer provider: %s", err.Error())
+	}
+
 	return &controller.Context{
 		RootContext:               ctx,
 		StopCh:                    stopCh,
 		RESTConfig:                kubeCfg,
 		Client:                    cl,
 		CMClient:                  intcl,
+		GWClient:                  gwcl,
 		Recorder:                  recorder,
 		KubeSharedInformerFactory: kubeSharedInformerFactory,
 		SharedInformerFactory:     sharedInformerFactory,
+		GWShared:                  gwSharedInformerFactory,
 		Namespace:                 opts.Namespace,
 		Clock:                     clock.RealClock{},
 		Metrics:                   metrics.New(log),
+		TracerProvider:            tracerProvider,
 		ACMEOptions: controller.ACMEOptions{
 			HTTP01SolverImage:                 opts.ACMEHTTP01SolverImage,
 			HTTP01SolverResourceRequestCPU:    HTTP01SolverResourceRequestCPU,
@@ -226,11 +273,19 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 			DNS01Nameservers:                  nameservers,
 			AccountRegistry:                   acmeAccountRegistry,
 			DNS01CheckRetryPeriod:             opts.DNS01CheckRetryPeriod,
+			DNS01BatchWindow:                  opts.DNS01BatchWindow,
+			DNS01AzureFederatedTokenFile:      opts.DNS01AzureFederatedTokenFile,
+			Finalizer:                         opts.ACMEFinalizer,
+			LegacyFinalizers:                  opts.ACMELegacyFinalizers,
 		},
 		IssuerOptions: controller.IssuerOptions{
-			ClusterIssuerAmbientCredentials: opts.ClusterIssuerAmbientCredentials,
-			IssuerAmbientCredentials:        opts.IssuerAmbientCredentials,
-			ClusterResourceNamespace:        opts.ClusterResourceNamespace,
+			ClusterIssuerAmbientCredentials:  opts.ClusterIssuerAmbientCredentials,
+			IssuerAmbientCredentials:         opts.IssuerAmbientCredentials,
+			ClusterResourceNamespace:         opts.ClusterResourceNamespace,
+			NotAfterRounding:                 opts.IssuedCertificateNotAfterRounding,
+			NotAfterRoundUp:                  opts.IssuedCertificateNotAfterRoundUp,
+			CAExpiryPolicy:                   opts.IssuedCertificateCAExpiryPolicy,
+			TransientSigningErrorRetryPeriod: opts.TransientSigningErrorRetryPeriod,
 		},
 		IngressShimOptions: controller.IngressShimOptions{
 			DefaultIssuerName:                 opts.DefaultIssuerName,
@@ -239,11 +294,36 @@ func buildControllerContext(ctx context.Context, stopCh <-chan struct{}, opts *o
 			DefaultAutoCertificateAnnotations: opts.DefaultAutoCertificateAnnotations,
 		},
 		CertificateOptions: controller.CertificateOptions{
-			EnableOwnerRef: opts.EnableCertificateOwnerRef,
+			EnableOwnerRef:            opts.EnableCertificateOwnerRef,
+			ClockSkewTolerance:        opts.CertificateClockSkewTolerance,
+			MaxChainDepth:             opts.MaxCertificateChainDepth,
+			EnableSecretEvents:        opts.EnableCertificateSecretEvents,
+			RequestAnnotationPrefix:   opts.CertificateRequestAnnotationPrefix,
+			RequesterAnnotations:      opts.CertificateRequesterAnnotations,
+			IssuanceFailureWebhookURL: opts.IssuanceFailureWebhookURL,
+			IssuanceAuditLogPath:      opts.IssuanceAuditLogPath,
+			ManifestSigningKeyPath:    opts.IssuanceManifestSigningKeyPath,
+			CRLCheckInterval:          opts.CertificateCRLCheckInterval,
+			SpecChangeDebounce:        opts.CertificateSpecChangeDebounce,
+			SecretRefreshInterval:     opts.CertificateSecretRefreshInterval,
+			IssuanceRateLimit:         opts.NamespaceIssuanceRateLimit,
+			IssuanceRateBurst:         opts.NamespaceIssuanceRateBurst,
+			RequestDeleteRateLimit:    opts.CertificateRequestDeleteRateLimit,
+			RequestDeleteRateBurst:    opts.CertificateRequestDeleteRateBurst,
+			RequestNaming:             opts.CertificateRequestNaming,
 		},
 		SchedulerOptions: controller.SchedulerOptions{
 			MaxConcurrentChallenges: opts.MaxConcurrentChallenges,
 		},
+		CABundleOptions: controller.CABundleOptions{
+			ClusterIssuerName: opts.CABundleClusterIssuer,
+			ConfigMapName:     opts.CABundleConfigMapName,
+			Namespaces:        opts.CABundleNamespaces,
+		},
+		ApproverOptions: controller.ApproverOptions{
+			TrustedServiceAccounts:  opts.ApproverTrustedServiceAccounts,
+			UntrustedRequestTimeout: opts.ApproverUntrustedRequestTimeout,
+		},
 	}, kubeCfg, nil
 }
 
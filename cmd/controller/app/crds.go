@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/jetstack/cert-manager/pkg/apis/acme"
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+)
+
+// crdWaitInterval is how often the CustomResourceDefinitions are checked
+// while waiting for them to become established.
+const crdWaitInterval = 2 * time.Second
+
+// requiredCRDs are the names of the CustomResourceDefinitions that must be
+// established before the controllers can be started.
+var requiredCRDs = []string{
+	"certificates." + certmanager.GroupName,
+	"certificaterequests." + certmanager.GroupName,
+	"issuers." + certmanager.GroupName,
+	"clusterissuers." + certmanager.GroupName,
+	"orders." + acme.GroupName,
+	"challenges." + acme.GroupName,
+}
+
+// waitForCRDsEstablished blocks until all of the named
+// CustomResourceDefinitions exist and have the Established condition, the
+// timeout expires, or the context is cancelled. Each CRD that is not yet
+// ready is logged on every check so that a missing installation step is
+// obvious from the controller's logs.
+func waitForCRDsEstablished(ctx context.Context, cl apiextensionsclient.Interface, names []string, interval, timeout time.Duration) error {
+	log := logf.FromContext(ctx, "wait-for-crds")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var pending []string
+	err := wait.PollImmediateUntil(interval, func() (bool, error) {
+		pending = nil
+		for _, name := range names {
+			log := log.WithValues("crd", name)
+
+			crd, err := cl.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+				log.Info("waiting for CustomResourceDefinition to be installed")
+				pending = append(pending, name)
+				continue
+			case err != nil:
+				log.Error(err, "failed to get CustomResourceDefinition")
+				pending = append(pending, name)
+				continue
+			}
+
+			if !crdEstablished(crd) {
+				log.Info("waiting for CustomResourceDefinition to be established")
+				pending = append(pending, name)
+			}
+		}
+
+		return len(pending) == 0, nil
+	}, ctx.Done())
+	if err != nil {
+		return fmt.Errorf("timed out after %s waiting for CustomResourceDefinitions to be established: %s", timeout, strings.Join(pending, ", "))
+	}
+
+	log.V(logf.InfoLevel).Info("all required CustomResourceDefinitions are established")
+	return nil
+}
+
+// crdEstablished returns true if the CustomResourceDefinition has an
+// Established condition with status True.
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established {
+			return cond.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func crd(name string, established bool) *apiextensionsv1.CustomResourceDefinition {
+	status := apiextensionsv1.ConditionFalse
+	if established {
+		status = apiextensionsv1.ConditionTrue
+	}
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.Established, Status: status},
+			},
+		},
+	}
+}
+
+func TestWaitForCRDsEstablished(t *testing.T) {
+	tests := map[string]struct {
+		// CRDs that exist when the wait starts
+		existing []runtime.Object
+		// CRDs that are created or updated while waiting
+		later []*apiextensionsv1.CustomResourceDefinition
+
+		expErr     bool
+		expPending []string
+	}{
+		"should return immediately if all CRDs are established": {
+			existing: []runtime.Object{crd("a.example.com", true), crd("b.example.com", true)},
+		},
+		"should wait for missing CRDs to be created": {
+			existing: []runtime.Object{crd("a.example.com", true)},
+			later:    []*apiextensionsv1.CustomResourceDefinition{crd("b.example.com", true)},
+		},
+		"should wait for existing CRDs to become established": {
+			existing: []runtime.Object{crd("a.example.com", true), crd("b.example.com", false)},
+			later:    []*apiextensionsv1.CustomResourceDefinition{crd("b.example.com", true)},
+		},
+		"should time out if a CRD is never created": {
+			existing:   []runtime.Object{crd("a.example.com", true)},
+			expErr:     true,
+			expPending: []string{"b.example.com"},
+		},
+		"should time out if a CRD never becomes established": {
+			existing:   []runtime.Object{crd("a.example.com", false), crd("b.example.com", true)},
+			expErr:     true,
+			expPending: []string{"a.example.com"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cl := fake.NewSimpleClientset(test.existing...)
+
+			timeout := 200 * time.Millisecond
+			if len(test.later) > 0 {
+				timeout = 5 * time.Second
+				go func() {
+					time.Sleep(50 * time.Millisecond)
+					crds := cl.ApiextensionsV1().CustomResourceDefinitions()
+					for _, c := range test.later {
+						if _, err := crds.Update(ctx, c, metav1.UpdateOptions{}); err == nil {
+							continue
+						}
+						if _, err := crds.Create(ctx, c, metav1.CreateOptions{}); err != nil {
+							t.Errorf("failed to create CRD %q: %v", c.Name, err)
+						}
+					}
+				}()
+			}
+
+			err := waitForCRDsEstablished(ctx, cl, []string{"a.example.com", "b.example.com"}, 10*time.Millisecond, timeout)
+			if test.expErr != (err != nil) {
+				t.Fatalf("expected error=%t, got: %v", test.expErr, err)
+			}
+			for _, name := range test.expPending {
+				if !strings.Contains(err.Error(), name) {
+					t.Errorf("expected error to name pending CRD %q, got: %v", name, err)
+				}
+			}
+		})
+	}
+}
@@ -19,15 +19,20 @@ package options
 import (
 	"fmt"
 	"net"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	cm "github.com/jetstack/cert-manager/pkg/apis/certmanager"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	challengescontroller "github.com/jetstack/cert-manager/pkg/controller/acmechallenges"
 	orderscontroller "github.com/jetstack/cert-manager/pkg/controller/acmeorders"
+	cabundlecontroller "github.com/jetstack/cert-manager/pkg/controller/cabundle"
 	cracmecontroller "github.com/jetstack/cert-manager/pkg/controller/certificaterequests/acme"
 	crapprovercontroller "github.com/jetstack/cert-manager/pkg/controller/certificaterequests/approver"
 	crcacontroller "github.com/jetstack/cert-manager/pkg/controller/certificaterequests/ca"
@@ -49,6 +54,7 @@ import (
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/util"
 	utilfeature "github.com/jetstack/cert-manager/pkg/util/feature"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 )
 
 type ControllerOptions struct {
@@ -74,9 +80,31 @@ type ControllerOptions struct {
 	ACMEHTTP01SolverResourceLimitsCPU     string
 	ACMEHTTP01SolverResourceLimitsMemory  string
 
+	// ACMEFinalizer is the name of the finalizer added to ACME Challenge
+	// resources, which is removed once the Challenge has been cleaned up.
+	ACMEFinalizer string
+
+	// ACMELegacyFinalizers are finalizer names previously configured with
+	// ACMEFinalizer, which are removed from Challenge resources once they
+	// have been cleaned up.
+	ACMELegacyFinalizers []string
+
 	ClusterIssuerAmbientCredentials bool
 	IssuerAmbientCredentials        bool
 
+	// IssuedCertificateNotAfterRounding is the boundary that the notAfter of
+	// certificates signed by the CA and SelfSigned issuers is rounded to.
+	IssuedCertificateNotAfterRounding time.Duration
+	IssuedCertificateNotAfterRoundUp  bool
+
+	// IssuedCertificateCAExpiryPolicy is what the CA issuer does when a
+	// requested certificate would outlive the CA certificate that signs it.
+	IssuedCertificateCAExpiryPolicy string
+
+	// TransientSigningErrorRetryPeriod is how long to wait before retrying a
+	// CertificateRequest whose signer returned a transient error.
+	TransientSigningErrorRetryPeriod time.Duration
+
 	// Default issuer/certificates details consumed by ingress-shim
 	DefaultIssuerName                 string
 	DefaultIssuerKind                 string
@@ -91,8 +119,98 @@ type ControllerOptions struct {
 
 	EnableCertificateOwnerRef bool
 
+	// CertificateClockSkewTolerance is the maximum amount of time a
+	// certificate's notBefore may be in the future and still be considered
+	// valid, to allow for clock skew between cert-manager and the issuer.
+	CertificateClockSkewTolerance time.Duration
+
+	// MaxCertificateChainDepth is the maximum number of certificates in an
+	// issued certificate chain for the Certificate to be considered Ready.
+	// A value of 0 disables the check.
+	MaxCertificateChainDepth int
+
+	EnableCertificateSecretEvents bool
+
+	// CertificateRequestAnnotationPrefix is the prefix of CertificateRequest
+	// annotations that are copied onto the issued Secret.
+	CertificateRequestAnnotationPrefix string
+
+	// CertificateRequesterAnnotations are the annotations that are not
+	// copied from a Certificate onto its CertificateRequests, as they are
+	// used by trusted delegators to attribute CertificateRequests to a user.
+	CertificateRequesterAnnotations []string
+
+	// IssuanceFailureWebhookURL, if set, is the URL that notifications of
+	// failed certificate issuances are POSTed to.
+	IssuanceFailureWebhookURL string
+
+	// IssuanceAuditLogPath, if set, is the path of the file that issuance
+	// audit records are appended to. "-" writes them to stdout.
+	IssuanceAuditLogPath string
+
+	// TracingExporter is the exporter that OpenTelemetry traces of
+	// certificate issuance are sent to. Tracing is disabled if empty.
+	TracingExporter string
+
+	// IssuanceManifestSigningKeyPath, if set, is the path of the PEM encoded
+	// private key used to sign the issuance manifests stored in the Secrets
+	// of issued certificates.
+	IssuanceManifestSigningKeyPath string
+
+	// CertificateCRLCheckInterval is how often issued certificates are
+	// checked for revocation. Disabled if zero.
+	CertificateCRLCheckInterval time.Duration
+
+	// CertificateSpecChangeDebounce is how long the spec of a Certificate
+	// must be unchanged for before it is issued, or the CertificateRequest of
+	// an issuance in progress is replaced. Disabled if zero.
+	CertificateSpecChangeDebounce time.Duration
+
+	// CertificateSecretRefreshInterval is how often the data derived from
+	// issued certificates, such as keystores, is re-created in their Secrets.
+	// Disabled if zero.
+	CertificateSecretRefreshInterval time.Duration
+
+	// NamespaceIssuanceRateLimit is the maximum number of CertificateRequests
+	// created per hour in each namespace. Disabled if zero.
+	NamespaceIssuanceRateLimit float64
+	// NamespaceIssuanceRateBurst is the number of CertificateRequests that
+	// may be created at once in a namespace before the rate limit applies.
+	NamespaceIssuanceRateBurst int
+
+	// CertificateRequestDeleteRateLimit is the maximum number of old
+	// CertificateRequests deleted per second. Disabled if zero.
+	CertificateRequestDeleteRateLimit float64
+	// CertificateRequestDeleteRateBurst is the number of CertificateRequests
+	// that may be deleted at once before the rate limit applies.
+	CertificateRequestDeleteRateBurst int
+
+	// CertificateRequestNaming is the scheme used to name the
+	// CertificateRequests created for Certificates.
+	CertificateRequestNaming string
+
 	MaxConcurrentChallenges int
 
+	// CABundleClusterIssuer is the name of the CA ClusterIssuer whose CA
+	// certificates are distributed by the ca-bundle controller.
+	CABundleClusterIssuer string
+	// CABundleConfigMapName is the name of the ConfigMaps the ca-bundle
+	// controller writes the CA certificates to.
+	CABundleConfigMapName string
+	// CABundleNamespaces are the namespaces the ca-bundle controller maintains
+	// the ConfigMap in. "*" means all namespaces.
+	CABundleNamespaces []string
+
+	// ApproverTrustedServiceAccounts are the ServiceAccounts, in the form
+	// '<namespace>:<name>', whose CertificateRequests are approved by the
+	// certificaterequests-approver controller. All requests are approved if
+	// empty.
+	ApproverTrustedServiceAccounts []string
+	// ApproverUntrustedRequestTimeout is how long CertificateRequests of
+	// untrusted requesters are left for manual approval before they are
+	// denied. Never denied if zero.
+	ApproverUntrustedRequestTimeout time.Duration
+
 	// The host and port address, separated by a ':', that the Prometheus server
 	// should expose metrics on.
 	MetricsListenAddress string
@@ -101,6 +219,21 @@ type ControllerOptions struct {
 	EnablePprof bool
 
 	DNS01CheckRetryPeriod time.Duration
+
+	// DNS01BatchWindow is the time to wait for further DNS01 records in the
+	// same zone so that they can be sent to the DNS provider in a single
+	// call. Zero disables batching.
+	DNS01BatchWindow time.Duration
+
+	// DNS01AzureFederatedTokenFile is the path of the projected service
+	// account token file used by azureDNS solvers configured for Azure
+	// Workload Identity.
+	DNS01AzureFederatedTokenFile string
+
+	// CRDWaitTimeout is the maximum amount of time to wait on startup for the
+	// cert-manager CustomResourceDefinitions to be established. Zero disables
+	// the check.
+	CRDWaitTimeout time.Duration
 }
 
 const (
@@ -121,18 +254,62 @@ const (
 	defaultClusterIssuerAmbientCredentials = true
 	defaultIssuerAmbientCredentials        = false
 
+	defaultIssuedCertificateNotAfterRounding = time.Duration(0)
+	defaultIssuedCertificateNotAfterRoundUp  = false
+	defaultIssuedCertificateCAExpiryPolicy   = crcacontroller.CAExpiryPolicyAllow
+
+	defaultTransientSigningErrorRetryPeriod = 5 * time.Second
+
 	defaultTLSACMEIssuerName         = ""
 	defaultTLSACMEIssuerKind         = "Issuer"
 	defaultTLSACMEIssuerGroup        = cm.GroupName
 	defaultEnableCertificateOwnerRef = false
 
+	defaultEnableCertificateSecretEvents = false
+
+	defaultCertificateRequestAnnotationPrefix = ""
+
+	defaultIssuanceFailureWebhookURL = ""
+
+	defaultIssuanceAuditLogPath = ""
+
+	defaultIssuanceManifestSigningKeyPath = ""
+
+	defaultTracingExporter = tracing.ExporterNone
+
+	defaultCertificateCRLCheckInterval = time.Duration(0)
+
+	defaultCertificateSpecChangeDebounce = time.Duration(0)
+
+	defaultCertificateSecretRefreshInterval = time.Duration(0)
+
+	defaultNamespaceIssuanceRateLimit = float64(0)
+	defaultNamespaceIssuanceRateBurst = 10
+
+	defaultCertificateRequestDeleteRateLimit = float64(0)
+	defaultCertificateRequestDeleteRateBurst = 10
+
+	defaultCertificateRequestNaming = requestmanager.RequestNamingRandom
+
+	defaultCertificateClockSkewTolerance = 5 * time.Minute
+
+	defaultMaxCertificateChainDepth = 0
+
 	defaultDNS01RecursiveNameserversOnly = false
 
 	defaultMaxConcurrentChallenges = 60
 
+	defaultCABundleClusterIssuer = ""
+	defaultCABundleConfigMapName = "cert-manager-ca-bundle"
+
+	defaultApproverUntrustedRequestTimeout = time.Duration(0)
+
 	defaultPrometheusMetricsServerAddress = "0.0.0.0:9402"
 
 	defaultDNS01CheckRetryPeriod = 10 * time.Second
+	defaultDNS01BatchWindow      = 0
+
+	defaultCRDWaitTimeout = 2 * time.Minute
 )
 
 var (
@@ -142,8 +319,12 @@ var (
 	defaultACMEHTTP01SolverResourceLimitsCPU     = "100m"
 	defaultACMEHTTP01SolverResourceLimitsMemory  = "64Mi"
 
+	defaultACMEFinalizer = cmacme.ACMEFinalizer
+
 	defaultAutoCertificateAnnotations = []string{"kubernetes.io/tls-acme"}
 
+	defaultCertificateRequesterAnnotations = []string{cmapi.RequesterUsernameAnnotationKey, cmapi.RequesterGroupsAnnotationKey}
+
 	allControllers = []string{
 		issuerscontroller.ControllerName,
 		clusterissuerscontroller.ControllerName,
@@ -164,6 +345,7 @@ var (
 		requestmanager.ControllerName,
 		readiness.ControllerName,
 		revisionmanager.ControllerName,
+		cabundlecontroller.ControllerName,
 	}
 
 	defaultEnabledControllers = []string{
@@ -195,29 +377,58 @@ var (
 
 func NewControllerOptions() *ControllerOptions {
 	return &ControllerOptions{
-		APIServerHost:                     defaultAPIServerHost,
-		ClusterResourceNamespace:          defaultClusterResourceNamespace,
-		KubernetesAPIQPS:                  defaultKubernetesAPIQPS,
-		KubernetesAPIBurst:                defaultKubernetesAPIBurst,
-		Namespace:                         defaultNamespace,
-		LeaderElect:                       defaultLeaderElect,
-		LeaderElectionNamespace:           defaultLeaderElectionNamespace,
-		LeaderElectionLeaseDuration:       defaultLeaderElectionLeaseDuration,
-		LeaderElectionRenewDeadline:       defaultLeaderElectionRenewDeadline,
-		LeaderElectionRetryPeriod:         defaultLeaderElectionRetryPeriod,
-		controllers:                       defaultEnabledControllers,
-		ClusterIssuerAmbientCredentials:   defaultClusterIssuerAmbientCredentials,
-		IssuerAmbientCredentials:          defaultIssuerAmbientCredentials,
-		DefaultIssuerName:                 defaultTLSACMEIssuerName,
-		DefaultIssuerKind:                 defaultTLSACMEIssuerKind,
-		DefaultIssuerGroup:                defaultTLSACMEIssuerGroup,
-		DefaultAutoCertificateAnnotations: defaultAutoCertificateAnnotations,
-		DNS01RecursiveNameservers:         []string{},
-		DNS01RecursiveNameserversOnly:     defaultDNS01RecursiveNameserversOnly,
-		EnableCertificateOwnerRef:         defaultEnableCertificateOwnerRef,
-		MetricsListenAddress:              defaultPrometheusMetricsServerAddress,
-		DNS01CheckRetryPeriod:             defaultDNS01CheckRetryPeriod,
-		EnablePprof:                       false,
+		APIServerHost:                      defaultAPIServerHost,
+		ClusterResourceNamespace:           defaultClusterResourceNamespace,
+		KubernetesAPIQPS:                   defaultKubernetesAPIQPS,
+		KubernetesAPIBurst:                 defaultKubernetesAPIBurst,
+		Namespace:                          defaultNamespace,
+		LeaderElect:                        defaultLeaderElect,
+		LeaderElectionNamespace:            defaultLeaderElectionNamespace,
+		LeaderElectionLeaseDuration:        defaultLeaderElectionLeaseDuration,
+		LeaderElectionRenewDeadline:        defaultLeaderElectionRenewDeadline,
+		LeaderElectionRetryPeriod:          defaultLeaderElectionRetryPeriod,
+		controllers:                        defaultEnabledControllers,
+		ClusterIssuerAmbientCredentials:    defaultClusterIssuerAmbientCredentials,
+		IssuerAmbientCredentials:           defaultIssuerAmbientCredentials,
+		IssuedCertificateNotAfterRounding:  defaultIssuedCertificateNotAfterRounding,
+		IssuedCertificateNotAfterRoundUp:   defaultIssuedCertificateNotAfterRoundUp,
+		IssuedCertificateCAExpiryPolicy:    defaultIssuedCertificateCAExpiryPolicy,
+		TransientSigningErrorRetryPeriod:   defaultTransientSigningErrorRetryPeriod,
+		DefaultIssuerName:                  defaultTLSACMEIssuerName,
+		DefaultIssuerKind:                  defaultTLSACMEIssuerKind,
+		DefaultIssuerGroup:                 defaultTLSACMEIssuerGroup,
+		DefaultAutoCertificateAnnotations:  defaultAutoCertificateAnnotations,
+		ACMEFinalizer:                      defaultACMEFinalizer,
+		DNS01RecursiveNameservers:          []string{},
+		DNS01RecursiveNameserversOnly:      defaultDNS01RecursiveNameserversOnly,
+		EnableCertificateOwnerRef:          defaultEnableCertificateOwnerRef,
+		CertificateClockSkewTolerance:      defaultCertificateClockSkewTolerance,
+		MaxCertificateChainDepth:           defaultMaxCertificateChainDepth,
+		EnableCertificateSecretEvents:      defaultEnableCertificateSecretEvents,
+		CertificateRequestAnnotationPrefix: defaultCertificateRequestAnnotationPrefix,
+		CertificateRequesterAnnotations:    defaultCertificateRequesterAnnotations,
+		IssuanceFailureWebhookURL:          defaultIssuanceFailureWebhookURL,
+		IssuanceAuditLogPath:               defaultIssuanceAuditLogPath,
+		IssuanceManifestSigningKeyPath:     defaultIssuanceManifestSigningKeyPath,
+		TracingExporter:                    defaultTracingExporter,
+		CertificateCRLCheckInterval:        defaultCertificateCRLCheckInterval,
+		CertificateSpecChangeDebounce:      defaultCertificateSpecChangeDebounce,
+		CertificateSecretRefreshInterval:   defaultCertificateSecretRefreshInterval,
+		NamespaceIssuanceRateLimit:         defaultNamespaceIssuanceRateLimit,
+		NamespaceIssuanceRateBurst:         defaultNamespaceIssuanceRateBurst,
+		CertificateRequestDeleteRateLimit:  defaultCertificateRequestDeleteRateLimit,
+		CertificateRequestDeleteRateBurst:  defaultCertificateRequestDeleteRateBurst,
+		CertificateRequestNaming:           defaultCertificateRequestNaming,
+		CABundleClusterIssuer:              defaultCABundleClusterIssuer,
+		CABundleConfigMapName:              defaultCABundleConfigMapName,
+		CABundleNamespaces:                 []string{},
+		ApproverTrustedServiceAccounts:     []string{},
+		ApproverUntrustedRequestTimeout:    defaultApproverUntrustedRequestTimeout,
+		MetricsListenAddress:               defaultPrometheusMetricsServerAddress,
+		DNS01CheckRetryPeriod:              defaultDNS01CheckRetryPeriod,
+		DNS01BatchWindow:                   defaultDNS01BatchWindow,
+		CRDWaitTimeout:                     defaultCRDWaitTimeout,
+		EnablePprof:                        false,
 	}
 }
 
@@ -277,6 +488,16 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.ACMEHTTP01SolverResourceLimitsMemory, "acme-http01-solver-resource-limits-memory", defaultACMEHTTP01SolverResourceLimitsMemory, ""+
 		"Defines the resource limits Memory size when spawning new ACME HTTP01 challenge solver pods.")
 
+	fs.StringVar(&s.ACMEFinalizer, "acme-finalizer", defaultACMEFinalizer, ""+
+		"The name of the finalizer added to ACME Challenge resources to ensure they are cleaned up before "+
+		"being deleted. Only change this if another cert-manager installation is running in the same cluster "+
+		"and the two would otherwise act on each other's Challenges.")
+	fs.StringSliceVar(&s.ACMELegacyFinalizers, "acme-legacy-finalizers", nil, ""+
+		"Finalizer names previously configured with --acme-finalizer, for example the default "+
+		cmacme.ACMEFinalizer+" after changing --acme-finalizer. They are removed from ACME Challenge resources "+
+		"in the same way as --acme-finalizer once the Challenge has been cleaned up, so that Challenges created "+
+		"before the change can be deleted.")
+
 	fs.BoolVar(&s.ClusterIssuerAmbientCredentials, "cluster-issuer-ambient-credentials", defaultClusterIssuerAmbientCredentials, ""+
 		"Whether a cluster-issuer may make use of ambient credentials for issuers. 'Ambient Credentials' are credentials drawn from the environment, metadata services, or local files which are not explicitly configured in the ClusterIssuer API object. "+
 		"When this flag is enabled, the following sources for credentials are also used: "+
@@ -285,6 +506,23 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 		"Whether an issuer may make use of ambient credentials. 'Ambient Credentials' are credentials drawn from the environment, metadata services, or local files which are not explicitly configured in the Issuer API object. "+
 		"When this flag is enabled, the following sources for credentials are also used: "+
 		"AWS - All sources the Go SDK defaults to, notably including any EC2 IAM roles available via instance metadata.")
+	fs.DurationVar(&s.IssuedCertificateNotAfterRounding, "issued-certificate-not-after-rounding", defaultIssuedCertificateNotAfterRounding, ""+
+		"If greater than zero, the notAfter of certificates signed by the CA and SelfSigned issuers is rounded down to a "+
+		"multiple of this duration in UTC, e.g. 1m, 1h or 24h to round to the minute, hour or day. Use this to match CAs "+
+		"that round the notAfter of the certificates they issue. The notAfter is rounded up instead if rounding it down "+
+		"would leave the certificate due for renewal.")
+	fs.BoolVar(&s.IssuedCertificateNotAfterRoundUp, "issued-certificate-not-after-round-up", defaultIssuedCertificateNotAfterRoundUp, ""+
+		"If true, the notAfter of signed certificates is rounded up rather than down to the boundary set by "+
+		"--issued-certificate-not-after-rounding.")
+	fs.StringVar(&s.IssuedCertificateCAExpiryPolicy, "issued-certificate-ca-expiry-policy", defaultIssuedCertificateCAExpiryPolicy, ""+
+		"What the CA issuer does when a requested certificate would be valid for longer than the CA certificate that signs it. "+
+		"One of 'Allow' to issue the certificate unchanged, 'Clamp' to reduce its notAfter to that of the CA and fire an event "+
+		"(failing the request if the clamped certificate would already be due for renewal), or 'Reject' to fail the request.")
+	fs.DurationVar(&s.TransientSigningErrorRetryPeriod, "transient-signing-error-retry-period", defaultTransientSigningErrorRetryPeriod, ""+
+		"How long to wait before retrying a CertificateRequest when its signer returns an error that is likely to be "+
+		"resolved by retrying, such as a network error or a server error. Errors that are not transient, such as the "+
+		"signer rejecting the request, fail the CertificateRequest. After 5 consecutive transient errors the CertificateRequest "+
+		"is retried with exponential back-off instead. Set to 0 to always retry transient errors with exponential back-off.")
 	fs.StringSliceVar(&s.DefaultAutoCertificateAnnotations, "auto-certificate-annotations", defaultAutoCertificateAnnotations, ""+
 		"The annotation consumed by the ingress-shim controller to indicate a ingress is requesting a certificate")
 
@@ -313,11 +551,97 @@ func (s *ControllerOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&s.EnableCertificateOwnerRef, "enable-certificate-owner-ref", defaultEnableCertificateOwnerRef, ""+
 		"Whether to set the certificate resource as an owner of secret where the tls certificate is stored. "+
 		"When this flag is enabled, the secret will be automatically removed when the certificate resource is deleted.")
+	fs.DurationVar(&s.CertificateClockSkewTolerance, "certificate-clock-skew-tolerance", defaultCertificateClockSkewTolerance, ""+
+		"The maximum amount of time a certificate's notBefore may be in the future while the certificate is still "+
+		"considered Ready. This allows for small amounts of clock skew between cert-manager and the issuer.")
+	fs.IntVar(&s.MaxCertificateChainDepth, "max-certificate-chain-depth", defaultMaxCertificateChainDepth, ""+
+		"The maximum number of certificates, including the leaf certificate, that an issued certificate chain may "+
+		"contain while the certificate is still considered Ready. Longer chains are reported with the ChainTooLong "+
+		"reason, which helps to detect misconfigured cross-signed intermediates. A value of 0 disables the check.")
+	fs.BoolVar(&s.EnableCertificateSecretEvents, "enable-certificate-secret-events", defaultEnableCertificateSecretEvents, ""+
+		"Whether to also record certificate issuance events against the secret where the tls certificate is stored, "+
+		"in addition to the certificate resource.")
+	fs.StringVar(&s.CertificateRequestAnnotationPrefix, "certificate-request-annotation-prefix", defaultCertificateRequestAnnotationPrefix, ""+
+		"If set, annotations on a CertificateRequest whose keys begin with this prefix will be copied onto the "+
+		"secret where the tls certificate is stored when the certificate is issued. Disabled if empty.")
+	fs.StringSliceVar(&s.CertificateRequesterAnnotations, "certificate-request-requester-annotations", defaultCertificateRequesterAnnotations, ""+
+		"Annotations that are never copied from a Certificate onto its CertificateRequests, as the webhook uses them "+
+		"to attribute CertificateRequests created by a trusted delegator to another user. This should match the "+
+		"webhook's --requester-username-annotation and --requester-groups-annotation flags.")
+	fs.StringVar(&s.IssuanceFailureWebhookURL, "issuance-failure-webhook-url", defaultIssuanceFailureWebhookURL, ""+
+		"If set, a JSON payload containing the name, namespace, reason and message is POSTed to this URL whenever "+
+		"the issuance of a certificate fails. Delivery is retried with backoff. Disabled if empty.")
+	fs.StringVar(&s.IssuanceAuditLogPath, "issuance-audit-log-path", defaultIssuanceAuditLogPath, ""+
+		"If set, a JSON audit record of every successful and failed certificate issuance is appended to the file "+
+		"at this path, one record per line. Use '-' to write the records to stdout. Disabled if empty.")
+	fs.StringVar(&s.IssuanceManifestSigningKeyPath, "issuance-manifest-signing-key-path", defaultIssuanceManifestSigningKeyPath, ""+
+		"If set, the path of a PEM encoded RSA or ECDSA private key used to sign a JSON manifest of every issued "+
+		"certificate. The manifest and its detached signature are stored in the certificate's Secret under the "+
+		"'manifest.json' and 'manifest.json.sig' keys. The key is read again whenever a manifest is signed, so it "+
+		"can be rotated without restarting the controller. Disabled if empty.")
+	fs.StringVar(&s.TracingExporter, "tracing-exporter", defaultTracingExporter, ""+
+		"The exporter that OpenTelemetry traces of certificate issuance are sent to. If set to 'stdout', each span "+
+		"is written to stdout as JSON. Tracing is disabled if empty.")
+	fs.DurationVar(&s.CertificateCRLCheckInterval, "certificate-crl-check-interval", defaultCertificateCRLCheckInterval, ""+
+		"If greater than zero, how often the revocation status of issued certificates is checked using the CRLs "+
+		"at their CRL distribution points. Revoked certificates are re-issued. Disabled if zero.")
+	fs.DurationVar(&s.CertificateSpecChangeDebounce, "certificate-spec-change-debounce", defaultCertificateSpecChangeDebounce, ""+
+		"If greater than zero, how long the spec of a Certificate must be unchanged for before an issuance is "+
+		"triggered for it, so that several edits made in quick succession result in a single issuance once the spec "+
+		"settles. Disabled if zero.")
+	fs.DurationVar(&s.CertificateSecretRefreshInterval, "certificate-secret-refresh-interval", defaultCertificateSecretRefreshInterval, ""+
+		"If greater than zero, how often the PKCS#12 and JKS keystores and additional output formats stored in the "+
+		"Secrets of issued certificates are re-created without re-issuing the certificates, so that changes to "+
+		"keystore passwords are picked up. Keystores are also re-created as soon as their password Secret changes. "+
+		"Keystores that already hold the issued certificate and key using the current password are left unchanged. "+
+		"Disabled if zero.")
+	fs.Float64Var(&s.NamespaceIssuanceRateLimit, "namespace-issuance-rate-limit", defaultNamespaceIssuanceRateLimit, ""+
+		"If greater than zero, the maximum number of CertificateRequests that are created per hour for the Certificates "+
+		"in each namespace. The creation of CertificateRequests beyond this rate is deferred. Disabled if zero.")
+	fs.IntVar(&s.NamespaceIssuanceRateBurst, "namespace-issuance-rate-burst", defaultNamespaceIssuanceRateBurst, ""+
+		"The number of CertificateRequests that may be created at once for the Certificates in a namespace before "+
+		"the namespace-issuance-rate-limit applies.")
+	fs.Float64Var(&s.CertificateRequestDeleteRateLimit, "certificate-request-delete-rate-limit", defaultCertificateRequestDeleteRateLimit, ""+
+		"If greater than zero, the maximum number of old CertificateRequests that are deleted per second across all "+
+		"Certificates when pruning their revision history. Deletes beyond this rate are deferred. Disabled if zero.")
+	fs.IntVar(&s.CertificateRequestDeleteRateBurst, "certificate-request-delete-rate-burst", defaultCertificateRequestDeleteRateBurst, ""+
+		"The number of old CertificateRequests that may be deleted at once before the "+
+		"certificate-request-delete-rate-limit applies.")
+	fs.StringVar(&s.CertificateRequestNaming, "certificate-request-naming", defaultCertificateRequestNaming, ""+
+		"How the CertificateRequests created for Certificates are named. 'random' appends a random suffix to the name "+
+		"of the Certificate. 'revision' names them '<certificate>-<revision>', appending a random suffix only if that "+
+		"name is already in use.")
 	fs.IntVar(&s.MaxConcurrentChallenges, "max-concurrent-challenges", defaultMaxConcurrentChallenges, ""+
 		"The maximum number of challenges that can be scheduled as 'processing' at once.")
+	fs.StringVar(&s.CABundleClusterIssuer, "ca-bundle-cluster-issuer", defaultCABundleClusterIssuer, ""+
+		"Name of the CA ClusterIssuer whose CA certificates the ca-bundle controller writes to a ConfigMap. "+
+		"Required if the ca-bundle controller is enabled.")
+	fs.StringVar(&s.CABundleConfigMapName, "ca-bundle-configmap-name", defaultCABundleConfigMapName, ""+
+		"Name of the ConfigMap the ca-bundle controller writes the CA certificates to, under the key 'ca.crt'.")
+	fs.StringSliceVar(&s.CABundleNamespaces, "ca-bundle-namespaces", []string{}, ""+
+		"Namespaces the ca-bundle controller maintains the ConfigMap in. Use '*' for all namespaces. "+
+		"Defaults to the cluster resource namespace.")
+	fs.StringSliceVar(&s.ApproverTrustedServiceAccounts, "approver-trusted-service-accounts", []string{}, ""+
+		"ServiceAccounts, in the form '<namespace>:<name>', whose CertificateRequests are automatically approved "+
+		"by the "+crapprovercontroller.ControllerName+" controller. CertificateRequests created by anyone else are "+
+		"left for manual approval. If not set, all CertificateRequests are automatically approved.")
+	fs.DurationVar(&s.ApproverUntrustedRequestTimeout, "approver-untrusted-request-timeout", defaultApproverUntrustedRequestTimeout, ""+
+		"How long CertificateRequests created by ServiceAccounts not listed in approver-trusted-service-accounts "+
+		"are left for manual approval before they are denied. Never denied if zero.")
 	fs.DurationVar(&s.DNS01CheckRetryPeriod, "dns01-check-retry-period", defaultDNS01CheckRetryPeriod, ""+
 		"The duration the controller should wait between checking if a ACME dns entry exists."+
 		"This should be a valid duration string, for example 180s or 1h")
+	fs.DurationVar(&s.DNS01BatchWindow, "dns01-batch-window", defaultDNS01BatchWindow, ""+
+		"The duration to wait for further ACME DNS01 records in the same zone to be presented or cleaned up, "+
+		"so that they are sent to the DNS provider in a single call. Only used for DNS providers that support "+
+		"changing many records at once, currently Route53. Set to 0 to disable batching.")
+	fs.StringVar(&s.DNS01AzureFederatedTokenFile, "dns01-azure-federated-token-file", "", ""+
+		"The path of the projected service account token file used by azureDNS DNS01 solvers that authenticate "+
+		"with Azure Workload Identity. If not set, the path in the AZURE_FEDERATED_TOKEN_FILE environment variable, "+
+		"which is set by the Azure Workload Identity webhook, is used.")
+	fs.DurationVar(&s.CRDWaitTimeout, "crd-wait-timeout", defaultCRDWaitTimeout, ""+
+		"The maximum amount of time to wait on startup for the cert-manager CustomResourceDefinitions to be "+
+		"established before giving up. The controllers are not started until they are. Set to 0 to disable the check.")
 
 	fs.StringVar(&s.MetricsListenAddress, "metrics-listen-address", defaultPrometheusMetricsServerAddress, ""+
 		"The host and port that the metrics endpoint should listen on.")
@@ -345,6 +669,109 @@ func (o *ControllerOptions) Validate() error {
 		return fmt.Errorf("invalid value for kube-api-burst: %v must be higher or equal to kube-api-qps: %v", o.KubernetesAPIQPS, o.KubernetesAPIQPS)
 	}
 
+	if errs := validation.IsQualifiedName(o.ACMEFinalizer); len(errs) > 0 {
+		return fmt.Errorf("invalid value for acme-finalizer: %q: %s", o.ACMEFinalizer, strings.Join(errs, ", "))
+	}
+	for _, f := range o.ACMELegacyFinalizers {
+		if errs := validation.IsQualifiedName(f); len(errs) > 0 {
+			return fmt.Errorf("invalid value for acme-legacy-finalizers: %q: %s", f, strings.Join(errs, ", "))
+		}
+	}
+
+	if o.IssuedCertificateNotAfterRounding < 0 {
+		return fmt.Errorf("invalid value for issued-certificate-not-after-rounding: %v must not be negative", o.IssuedCertificateNotAfterRounding)
+	}
+
+	switch o.IssuedCertificateCAExpiryPolicy {
+	case crcacontroller.CAExpiryPolicyAllow, crcacontroller.CAExpiryPolicyClamp, crcacontroller.CAExpiryPolicyReject:
+	default:
+		return fmt.Errorf("invalid value for issued-certificate-ca-expiry-policy: %q must be one of %q, %q or %q",
+			o.IssuedCertificateCAExpiryPolicy, crcacontroller.CAExpiryPolicyAllow, crcacontroller.CAExpiryPolicyClamp, crcacontroller.CAExpiryPolicyReject)
+	}
+
+	switch o.TracingExporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	default:
+		return fmt.Errorf("invalid value for tracing-exporter: %q must be %q or empty", o.TracingExporter, tracing.ExporterStdout)
+	}
+
+	if o.TransientSigningErrorRetryPeriod < 0 {
+		return fmt.Errorf("invalid value for transient-signing-error-retry-period: %v must not be negative", o.TransientSigningErrorRetryPeriod)
+	}
+
+	if o.MaxCertificateChainDepth < 0 {
+		return fmt.Errorf("invalid value for max-certificate-chain-depth: %v must not be negative", o.MaxCertificateChainDepth)
+	}
+
+	if o.CertificateCRLCheckInterval < 0 {
+		return fmt.Errorf("invalid value for certificate-crl-check-interval: %v must not be negative", o.CertificateCRLCheckInterval)
+	}
+
+	if o.CertificateSpecChangeDebounce < 0 {
+		return fmt.Errorf("invalid value for certificate-spec-change-debounce: %v must not be negative", o.CertificateSpecChangeDebounce)
+	}
+
+	if o.CertificateSecretRefreshInterval < 0 {
+		return fmt.Errorf("invalid value for certificate-secret-refresh-interval: %v must not be negative", o.CertificateSecretRefreshInterval)
+	}
+
+	if o.NamespaceIssuanceRateLimit < 0 {
+		return fmt.Errorf("invalid value for namespace-issuance-rate-limit: %v must not be negative", o.NamespaceIssuanceRateLimit)
+	}
+
+	if o.NamespaceIssuanceRateLimit > 0 && o.NamespaceIssuanceRateBurst < 1 {
+		return fmt.Errorf("invalid value for namespace-issuance-rate-burst: %v must be at least 1", o.NamespaceIssuanceRateBurst)
+	}
+
+	if o.CertificateRequestDeleteRateLimit < 0 {
+		return fmt.Errorf("invalid value for certificate-request-delete-rate-limit: %v must not be negative", o.CertificateRequestDeleteRateLimit)
+	}
+
+	if o.CertificateRequestDeleteRateLimit > 0 && o.CertificateRequestDeleteRateBurst < 1 {
+		return fmt.Errorf("invalid value for certificate-request-delete-rate-burst: %v must be at least 1", o.CertificateRequestDeleteRateBurst)
+	}
+
+	switch o.CertificateRequestNaming {
+	case requestmanager.RequestNamingRandom, requestmanager.RequestNamingRevision:
+	default:
+		return fmt.Errorf("invalid value for certificate-request-naming: %q must be one of %q or %q",
+			o.CertificateRequestNaming, requestmanager.RequestNamingRandom, requestmanager.RequestNamingRevision)
+	}
+
+	if o.DNS01BatchWindow < 0 {
+		return fmt.Errorf("invalid value for dns01-batch-window: %v must not be negative", o.DNS01BatchWindow)
+	}
+
+	if o.CRDWaitTimeout < 0 {
+		return fmt.Errorf("invalid value for crd-wait-timeout: %v must not be negative", o.CRDWaitTimeout)
+	}
+
+	if o.IssuanceFailureWebhookURL != "" {
+		u, err := url.Parse(o.IssuanceFailureWebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid value for issuance-failure-webhook-url: %q must be an http or https URL", o.IssuanceFailureWebhookURL)
+		}
+	}
+
+	if o.EnabledControllers().Has(cabundlecontroller.ControllerName) {
+		if o.CABundleClusterIssuer == "" {
+			return fmt.Errorf("ca-bundle-cluster-issuer must be set when the %s controller is enabled", cabundlecontroller.ControllerName)
+		}
+		if o.CABundleConfigMapName == "" {
+			return fmt.Errorf("ca-bundle-configmap-name must be set when the %s controller is enabled", cabundlecontroller.ControllerName)
+		}
+	}
+
+	for _, sa := range o.ApproverTrustedServiceAccounts {
+		if parts := strings.Split(sa, ":"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid value for approver-trusted-service-accounts: %q must be of the form '<namespace>:<name>'", sa)
+		}
+	}
+
+	if o.ApproverUntrustedRequestTimeout < 0 {
+		return fmt.Errorf("invalid value for approver-untrusted-request-timeout: %v must not be negative", o.ApproverUntrustedRequestTimeout)
+	}
+
 	for _, server := range o.DNS01RecursiveNameservers {
 		// ensure all servers have a port number
 		_, _, err := net.SplitHostPort(server)
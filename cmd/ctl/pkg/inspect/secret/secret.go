@@ -28,7 +28,6 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
@@ -38,7 +37,7 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 	k8sclock "k8s.io/utils/clock"
 
-	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
@@ -167,7 +166,8 @@ func (o *Options) Run(ctx context.Context, args []string) error {
 		return fmt.Errorf("error when finding Secret %q: %w\n", args[0], err)
 	}
 
-	certData := secret.Data[corev1.TLSCertKey]
+	_, certKey, caKey := apiutil.SecretKeyNamesForSecret(secret)
+	certData := secret.Data[certKey]
 	certs, err := splitPEMs(certData)
 	if err != nil {
 		return err
@@ -184,7 +184,7 @@ func (o *Options) Run(ctx context.Context, args []string) error {
 	// we only want to inspect the leaf certificate
 	x509Cert, err := pki.DecodeX509CertificateBytes(certs[0])
 	if err != nil {
-		return fmt.Errorf("error when parsing 'tls.crt': %w", err)
+		return fmt.Errorf("error when parsing %q: %w", certKey, err)
 	}
 
 	out := []string{
@@ -193,7 +193,7 @@ func (o *Options) Run(ctx context.Context, args []string) error {
 		describeIssuedBy(x509Cert),
 		describeIssuedFor(x509Cert),
 		describeCertificate(x509Cert),
-		describeDebugging(x509Cert, intermediates, secret.Data[cmmeta.TLSCAKey]),
+		describeDebugging(x509Cert, intermediates, secret.Data[caKey]),
 	}
 
 	fmt.Println(strings.Join(out, "\n\n"))
@@ -326,6 +326,58 @@ MA6koCR/K23HZfML8vT6lcHvQJp9XXaHRIe9NX/M/2f6VpfO7JjKWLou5k5a
 				},
 			},
 		},
+		"Correct information extracted from Secret resource with custom key names": {
+			inputData: &Data{
+				Certificate: gen.Certificate("test-crt",
+					gen.SetCertificateNamespace(ns)),
+				Secret: gen.Secret("existing-tls-secret",
+					gen.SetSecretNamespace(ns),
+					gen.SetSecretAnnotations(map[string]string{cmapi.SecretCertificateKeyAnnotationKey: "cert.pem"}),
+					gen.SetSecretData(map[string][]byte{"cert.pem": tlsCrt})),
+				SecretError:  nil,
+				SecretEvents: dummyEventList,
+			},
+			expOutput: &CertificateStatus{
+				Name:         "test-crt",
+				Namespace:    ns,
+				CreationTime: metav1.Time{},
+				SecretStatus: &SecretStatus{
+					Error:              nil,
+					Name:               "existing-tls-secret",
+					IssuerCountry:      nil,
+					IssuerOrganisation: nil,
+					IssuerCommonName:   "test",
+					KeyUsage:           x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+					ExtKeyUsage:        nil,
+					PublicKeyAlgorithm: x509.RSA,
+					SignatureAlgorithm: x509.SHA256WithRSA,
+					SubjectKeyId:       nil,
+					AuthorityKeyId:     nil,
+					SerialNumber:       serialNum,
+					Events:             dummyEventList,
+				},
+			},
+		},
+		"Error reported for Secret resource missing the custom certificate key": {
+			inputData: &Data{
+				Certificate: gen.Certificate("test-crt",
+					gen.SetCertificateNamespace(ns)),
+				Secret: gen.Secret("existing-tls-secret",
+					gen.SetSecretNamespace(ns),
+					gen.SetSecretAnnotations(map[string]string{cmapi.SecretCertificateKeyAnnotationKey: "cert.pem"}),
+					gen.SetSecretData(map[string][]byte{"tls.crt": tlsCrt})),
+				SecretError:  nil,
+				SecretEvents: dummyEventList,
+			},
+			expOutput: &CertificateStatus{
+				Name:         "test-crt",
+				Namespace:    ns,
+				CreationTime: metav1.Time{},
+				SecretStatus: &SecretStatus{
+					Error: errors.New("error: 'cert.pem' of Secret \"existing-tls-secret\" is not set\n"),
+				},
+			},
+		},
 		"Correct information extracted from CR resource": {
 			inputData: &Data{
 				Certificate: gen.Certificate("test-crt",
@@ -29,6 +29,7 @@ import (
 	"k8s.io/kubectl/pkg/describe"
 
 	"github.com/jetstack/cert-manager/cmd/ctl/pkg/status/util"
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1beta1"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
@@ -198,16 +199,17 @@ func (status *CertificateStatus) withSecret(secret *v1.Secret, secretEvents *v1.
 	if secret == nil {
 		return status
 	}
-	certData := secret.Data["tls.crt"]
+	_, certKey, _ := apiutil.SecretKeyNamesForSecret(secret)
+	certData := secret.Data[certKey]
 
 	if len(certData) == 0 {
-		status.SecretStatus = &SecretStatus{Error: fmt.Errorf("error: 'tls.crt' of Secret %q is not set\n", secret.Name)}
+		status.SecretStatus = &SecretStatus{Error: fmt.Errorf("error: '%s' of Secret %q is not set\n", certKey, secret.Name)}
 		return status
 	}
 
 	x509Cert, err := pki.DecodeX509CertificateBytes(certData)
 	if err != nil {
-		status.SecretStatus = &SecretStatus{Error: fmt.Errorf("error when parsing 'tls.crt' of Secret %q: %s\n", secret.Name, err)}
+		status.SecretStatus = &SecretStatus{Error: fmt.Errorf("error when parsing '%s' of Secret %q: %s\n", certKey, secret.Name, err)}
 		return status
 	}
 
@@ -0,0 +1,295 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/jetstack/cert-manager/cmd/ctl/pkg/status/util"
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	"github.com/jetstack/cert-manager/pkg/util/predicate"
+)
+
+var (
+	long = templates.LongDesc(i18n.T(`
+List the status of cert-manager Certificate resources.
+
+Lists whether each Certificate is Ready, the reason and message of its most
+recently changed condition, and the name and state of its latest
+CertificateRequest. Use --not-ready to only list the Certificates that are not
+Ready, e.g. to see every failing Certificate during an incident.`))
+
+	example = templates.Examples(i18n.T(`
+# List the status of Certificates in the current context namespace.
+kubectl cert-manager status certificates
+
+# List the Certificates in all namespaces that are not Ready.
+kubectl cert-manager status certificates --all-namespaces --not-ready`))
+)
+
+// Options is a struct to support status certificates command
+type Options struct {
+	CMClient cmclient.Interface
+
+	// The Namespace that the Certificates to be listed reside in.
+	// This flag registration is handled by cmdutil.Factory
+	Namespace     string
+	LabelSelector string
+	AllNamespaces bool
+
+	// NotReady, if true, only lists the Certificates that are not Ready.
+	NotReady bool
+
+	genericclioptions.IOStreams
+}
+
+// Status is the status of a single Certificate.
+type Status struct {
+	Namespace string
+	Name      string
+	// Ready is the status of the Certificate's Ready condition, or Unknown
+	// if it does not have one.
+	Ready cmmeta.ConditionStatus
+	// Reason and Message are those of the Certificate's most recently
+	// changed condition.
+	Reason  string
+	Message string
+	// Request is the name of the latest CertificateRequest owned by the
+	// Certificate, if any, and RequestState is its state.
+	Request      string
+	RequestState string
+}
+
+// NewOptions returns initialized Options
+func NewOptions(ioStreams genericclioptions.IOStreams) *Options {
+	return &Options{
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdStatusCertificates returns a cobra command for status certificates
+func NewCmdStatusCertificates(ctx context.Context, ioStreams genericclioptions.IOStreams, factory cmdutil.Factory) *cobra.Command {
+	o := NewOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:     "certificates",
+		Short:   "List the status of cert-manager Certificate resources",
+		Long:    long,
+		Example: example,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Validate(args))
+			cmdutil.CheckErr(o.Complete(factory))
+			cmdutil.CheckErr(o.Run(ctx))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list Certificates across namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().BoolVar(&o.NotReady, "not-ready", o.NotReady, "If present, only list Certificates that are not Ready.")
+
+	return cmd
+}
+
+// Validate validates the provided options
+func (o *Options) Validate(args []string) error {
+	if len(args) > 0 {
+		return errors.New("certificates does not accept arguments")
+	}
+	return nil
+}
+
+// Complete takes the factory and infers any remaining options.
+func (o *Options) Complete(f cmdutil.Factory) error {
+	var err error
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.CMClient, err = cmclient.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Run executes status certificates command
+func (o *Options) Run(ctx context.Context) error {
+	ns := o.Namespace
+	if o.AllNamespaces {
+		ns = metav1.NamespaceAll
+	}
+
+	crtsList, err := o.CMClient.CertmanagerV1().Certificates(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: o.LabelSelector,
+	})
+	if err != nil {
+		return fmt.Errorf("error when listing Certificate resources: %v", err)
+	}
+
+	reqsList, err := o.CMClient.CertmanagerV1().CertificateRequests(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error when listing CertificateRequest resources: %v", err)
+	}
+
+	statuses := NewStatuses(crtsList.Items, reqsList.Items, o.NotReady)
+	if len(statuses) == 0 {
+		switch {
+		case o.NotReady && o.AllNamespaces:
+			fmt.Fprintln(o.ErrOut, "No Certificates that are not Ready found")
+		case o.NotReady:
+			fmt.Fprintf(o.ErrOut, "No Certificates that are not Ready found in %s namespace.\n", o.Namespace)
+		case o.AllNamespaces:
+			fmt.Fprintln(o.ErrOut, "No Certificates found")
+		default:
+			fmt.Fprintf(o.ErrOut, "No Certificates found in %s namespace.\n", o.Namespace)
+		}
+		return nil
+	}
+
+	WriteStatuses(o.Out, statuses)
+
+	return nil
+}
+
+// NewStatuses returns the status of each of the given Certificates, ordered
+// by namespace and name. The latest CertificateRequest of each Certificate is
+// found from reqs. If notReady is true, only Certificates that are not Ready
+// are included.
+func NewStatuses(crts []cmapi.Certificate, reqs []cmapi.CertificateRequest, notReady bool) []Status {
+	var statuses []Status
+	for i := range crts {
+		crt := &crts[i]
+
+		ready := cmmeta.ConditionUnknown
+		if cond := apiutil.GetCertificateCondition(crt, cmapi.CertificateConditionReady); cond != nil {
+			ready = cond.Status
+		}
+		if notReady && ready == cmmeta.ConditionTrue {
+			continue
+		}
+
+		status := Status{Namespace: crt.Namespace, Name: crt.Name, Ready: ready}
+		if cond := latestCondition(crt); cond != nil {
+			status.Reason, status.Message = cond.Reason, cond.Message
+		}
+		if req := latestRequest(crt, reqs); req != nil {
+			status.Request, status.RequestState = req.Name, requestState(req)
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// WriteStatuses writes the given Certificate statuses to out as a table.
+func WriteStatuses(out io.Writer, statuses []Status) {
+	w := util.NewTabWriter(out)
+	fmt.Fprint(w, "NAMESPACE\tNAME\tREADY\tREASON\tMESSAGE\tREQUEST\tREQUEST STATE\n")
+	for _, s := range statuses {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Namespace, s.Name, s.Ready,
+			orNone(s.Reason), orNone(s.Message), orNone(s.Request), orNone(s.RequestState))
+	}
+	w.Flush()
+}
+
+// latestCondition returns the condition of the Certificate that most
+// recently changed status, or nil if it has no conditions.
+func latestCondition(crt *cmapi.Certificate) *cmapi.CertificateCondition {
+	var latest *cmapi.CertificateCondition
+	for i := range crt.Status.Conditions {
+		cond := &crt.Status.Conditions[i]
+		if latest == nil || transitionTime(cond).After(transitionTime(latest).Time) {
+			latest = cond
+		}
+	}
+	return latest
+}
+
+func transitionTime(cond *cmapi.CertificateCondition) metav1.Time {
+	if cond.LastTransitionTime == nil {
+		return metav1.Time{}
+	}
+	return *cond.LastTransitionTime
+}
+
+// latestRequest returns the CertificateRequest owned by the Certificate with
+// the highest revision, or the most recently created one if their revisions
+// are the same. It returns nil if the Certificate owns no requests.
+func latestRequest(crt *cmapi.Certificate, reqs []cmapi.CertificateRequest) *cmapi.CertificateRequest {
+	var latest *cmapi.CertificateRequest
+	latestRevision := 0
+	for i := range reqs {
+		req := &reqs[i]
+		if !predicate.ResourceOwnedBy(crt)(req) {
+			continue
+		}
+		revision, _ := strconv.Atoi(req.Annotations[cmapi.CertificateRequestRevisionAnnotationKey])
+		if latest == nil || revision > latestRevision ||
+			(revision == latestRevision && latest.CreationTimestamp.Before(&req.CreationTimestamp)) {
+			latest, latestRevision = req, revision
+		}
+	}
+	return latest
+}
+
+// requestState returns Denied if the CertificateRequest has been denied, and
+// otherwise the reason of its Ready condition.
+func requestState(req *cmapi.CertificateRequest) string {
+	if apiutil.CertificateRequestIsDenied(req) {
+		return cmapi.CertificateRequestReasonDenied
+	}
+	if cond := apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionReady); cond != nil {
+		return cond.Reason
+	}
+	return ""
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+var start = time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+// condition returns a Certificate condition that last changed offset after
+// start.
+func condition(condType cmapi.CertificateConditionType, status cmmeta.ConditionStatus, reason, message string, offset time.Duration) cmapi.CertificateCondition {
+	transitionTime := metav1.NewTime(start.Add(offset))
+	return cmapi.CertificateCondition{
+		Type:               condType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: &transitionTime,
+	}
+}
+
+// request returns a CertificateRequest with the given revision owned by the
+// Certificate.
+func request(name, revision string, crt cmapi.Certificate, mods ...gen.CertificateRequestModifier) cmapi.CertificateRequest {
+	mods = append([]gen.CertificateRequestModifier{
+		gen.SetCertificateRequestNamespace(crt.Namespace),
+		gen.SetCertificateRequestRevision(revision),
+		gen.AddCertificateRequestOwnerReferences(*metav1.NewControllerRef(&crt, cmapi.SchemeGroupVersion.WithKind("Certificate"))),
+	}, mods...)
+	return *gen.CertificateRequest(name, mods...)
+}
+
+func testResources() ([]cmapi.Certificate, []cmapi.CertificateRequest) {
+	ready := *gen.Certificate("ready",
+		gen.SetCertificateNamespace("default"),
+		gen.SetCertificateUID(types.UID("ready")),
+		gen.SetCertificateStatusCondition(condition(cmapi.CertificateConditionReady, cmmeta.ConditionTrue, "Ready", "Certificate is up to date and has not expired", 0)),
+	)
+	failing := *gen.Certificate("failing",
+		gen.SetCertificateNamespace("team-a"),
+		gen.SetCertificateUID(types.UID("failing")),
+		gen.SetCertificateStatusCondition(condition(cmapi.CertificateConditionReady, cmmeta.ConditionFalse, "DoesNotExist", "Issuing certificate as Secret does not exist", 0)),
+		gen.SetCertificateStatusCondition(condition(cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, "Failed", "The certificate request has failed to complete", time.Hour)),
+	)
+	denied := *gen.Certificate("denied",
+		gen.SetCertificateNamespace("default"),
+		gen.SetCertificateUID(types.UID("denied")),
+		gen.SetCertificateStatusCondition(condition(cmapi.CertificateConditionIssuing, cmmeta.ConditionTrue, "Issuing", "Issuing certificate as Secret does not exist", time.Hour)),
+		gen.SetCertificateStatusCondition(condition(cmapi.CertificateConditionReady, cmmeta.ConditionFalse, "DoesNotExist", "Issuing certificate as Secret does not exist", 0)),
+	)
+	notIssued := *gen.Certificate("not-issued",
+		gen.SetCertificateNamespace("team-a"),
+		gen.SetCertificateUID(types.UID("not-issued")),
+	)
+
+	reqs := []cmapi.CertificateRequest{
+		request("ready-1", "1", ready,
+			gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionReady, Status: cmmeta.ConditionTrue, Reason: cmapi.CertificateRequestReasonIssued}),
+		),
+		request("failing-1", "1", failing,
+			gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionReady, Status: cmmeta.ConditionTrue, Reason: cmapi.CertificateRequestReasonIssued}),
+		),
+		request("failing-2", "2", failing,
+			gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionReady, Status: cmmeta.ConditionFalse, Reason: cmapi.CertificateRequestReasonFailed}),
+		),
+		request("denied-1", "1", denied,
+			gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionReady, Status: cmmeta.ConditionFalse, Reason: cmapi.CertificateRequestReasonPending}),
+			gen.AddCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionDenied, Status: cmmeta.ConditionTrue, Reason: "Foo"}),
+		),
+		// not owned by any of the Certificates
+		*gen.CertificateRequest("unowned", gen.SetCertificateRequestNamespace("default")),
+	}
+
+	return []cmapi.Certificate{ready, failing, denied, notIssued}, reqs
+}
+
+func TestNewStatuses(t *testing.T) {
+	crts, reqs := testResources()
+
+	readyStatus := Status{Namespace: "default", Name: "ready", Ready: cmmeta.ConditionTrue,
+		Reason: "Ready", Message: "Certificate is up to date and has not expired", Request: "ready-1", RequestState: cmapi.CertificateRequestReasonIssued}
+	deniedStatus := Status{Namespace: "default", Name: "denied", Ready: cmmeta.ConditionFalse,
+		Reason: "Issuing", Message: "Issuing certificate as Secret does not exist", Request: "denied-1", RequestState: cmapi.CertificateRequestReasonDenied}
+	failingStatus := Status{Namespace: "team-a", Name: "failing", Ready: cmmeta.ConditionFalse,
+		Reason: "Failed", Message: "The certificate request has failed to complete", Request: "failing-2", RequestState: cmapi.CertificateRequestReasonFailed}
+	notIssuedStatus := Status{Namespace: "team-a", Name: "not-issued", Ready: cmmeta.ConditionUnknown}
+
+	tests := map[string]struct {
+		notReady bool
+		expected []Status
+	}{
+		"lists every Certificate": {
+			expected: []Status{deniedStatus, readyStatus, failingStatus, notIssuedStatus},
+		},
+		"only lists Certificates that are not Ready": {
+			notReady: true,
+			expected: []Status{deniedStatus, failingStatus, notIssuedStatus},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, NewStatuses(crts, reqs, test.notReady))
+		})
+	}
+}
+
+func TestNewStatusesNoneNotReady(t *testing.T) {
+	crts, reqs := testResources()
+	assert.Empty(t, NewStatuses(crts[:1], reqs, true))
+}
+
+func TestWriteStatuses(t *testing.T) {
+	crts, reqs := testResources()
+
+	out := &bytes.Buffer{}
+	WriteStatuses(out, NewStatuses(crts, reqs, true))
+
+	expected := `NAMESPACE  NAME        READY    REASON   MESSAGE                                         REQUEST    REQUEST STATE
+default    denied      False    Issuing  Issuing certificate as Secret does not exist    denied-1   Denied
+team-a     failing     False    Failed   The certificate request has failed to complete  failing-2  Failed
+team-a     not-issued  Unknown  <none>   <none>                                          <none>     <none>
+`
+	assert.Equal(t, expected, out.String())
+}
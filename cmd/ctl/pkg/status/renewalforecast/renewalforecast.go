@@ -0,0 +1,320 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package renewalforecast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/jetstack/cert-manager/cmd/ctl/pkg/status/util"
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	"github.com/jetstack/cert-manager/pkg/controller/certificates"
+)
+
+var (
+	long = templates.LongDesc(i18n.T(`
+Forecast when cert-manager Certificate resources will next be renewed.
+
+Lists the next renewal time of each Certificate, computed in the same way as
+the cert-manager controller, and groups the renewals into hourly or daily
+buckets. Buckets with considerably more renewals than average are highlighted
+as spikes.`))
+
+	example = templates.Examples(i18n.T(`
+# Forecast renewals of Certificates in the current context namespace, grouped by day.
+kubectl cert-manager status renewal-forecast
+
+# Forecast renewals of Certificates in all namespaces, grouped by hour.
+kubectl cert-manager status renewal-forecast --all-namespaces --bucket hour`))
+)
+
+const (
+	// BucketHour groups renewals by the hour in which they will happen.
+	BucketHour = "hour"
+	// BucketDay groups renewals by the day on which they will happen.
+	BucketDay = "day"
+)
+
+// Options is a struct to support status renewal-forecast command
+type Options struct {
+	CMClient cmclient.Interface
+
+	// The Namespace that the Certificates to be forecast reside in.
+	// This flag registration is handled by cmdutil.Factory
+	Namespace     string
+	LabelSelector string
+	AllNamespaces bool
+
+	// Bucket is the size of the buckets renewals are grouped into, either
+	// "hour" or "day".
+	Bucket string
+	// SpikeFactor is the multiple of the average number of renewals per
+	// bucket above which a bucket is highlighted as a spike.
+	SpikeFactor float64
+
+	genericclioptions.IOStreams
+}
+
+// Renewal is the next renewal time of a single Certificate.
+type Renewal struct {
+	Namespace string
+	Name      string
+	Time      time.Time
+}
+
+// Bucket is the number of renewals that will happen within a period
+// starting at Start.
+type Bucket struct {
+	Start time.Time
+	Count int
+	// Spike is true if the bucket contains considerably more renewals than
+	// the average bucket.
+	Spike bool
+}
+
+// Forecast is the renewal forecast for a set of Certificates.
+type Forecast struct {
+	// Renewals is the next renewal of each Certificate, ordered by time.
+	Renewals []Renewal
+	// Unscheduled contains each Certificate for which no renewal time could
+	// be computed, e.g. because it has not been issued yet. The Time of these
+	// renewals is unset.
+	Unscheduled []Renewal
+	// Buckets contains an entry for each period in which at least one
+	// renewal will happen, ordered by time.
+	Buckets []Bucket
+}
+
+// NewOptions returns initialized Options
+func NewOptions(ioStreams genericclioptions.IOStreams) *Options {
+	return &Options{
+		Bucket:      BucketDay,
+		SpikeFactor: 2,
+		IOStreams:   ioStreams,
+	}
+}
+
+// NewCmdStatusRenewalForecast returns a cobra command for status renewal-forecast
+func NewCmdStatusRenewalForecast(ctx context.Context, ioStreams genericclioptions.IOStreams, factory cmdutil.Factory) *cobra.Command {
+	o := NewOptions(ioStreams)
+	cmd := &cobra.Command{
+		Use:     "renewal-forecast",
+		Short:   "Forecast when cert-manager Certificate resources will next be renewed",
+		Long:    long,
+		Example: example,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Validate(args))
+			cmdutil.CheckErr(o.Complete(factory))
+			cmdutil.CheckErr(o.Run(ctx))
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, forecast renewals of Certificates across namespaces. Namespace in current context is ignored even if specified with --namespace.")
+	cmd.Flags().StringVar(&o.Bucket, "bucket", o.Bucket, `The period to group renewals by, either "hour" or "day".`)
+	cmd.Flags().Float64Var(&o.SpikeFactor, "spike-factor", o.SpikeFactor, "Highlight periods with more than this multiple of the average number of renewals per period.")
+
+	return cmd
+}
+
+// Validate validates the provided options
+func (o *Options) Validate(args []string) error {
+	if len(args) > 0 {
+		return errors.New("renewal-forecast does not accept arguments")
+	}
+	if _, err := bucketSize(o.Bucket); err != nil {
+		return err
+	}
+	if o.SpikeFactor <= 0 {
+		return errors.New("--spike-factor must be greater than zero")
+	}
+	return nil
+}
+
+// Complete takes the factory and infers any remaining options.
+func (o *Options) Complete(f cmdutil.Factory) error {
+	var err error
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+
+	o.CMClient, err = cmclient.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Run executes status renewal-forecast command
+func (o *Options) Run(ctx context.Context) error {
+	ns := o.Namespace
+	if o.AllNamespaces {
+		ns = metav1.NamespaceAll
+	}
+
+	crtsList, err := o.CMClient.CertmanagerV1().Certificates(ns).List(ctx, metav1.ListOptions{
+		LabelSelector: o.LabelSelector,
+	})
+	if err != nil {
+		return fmt.Errorf("error when listing Certificate resources: %v", err)
+	}
+
+	if len(crtsList.Items) == 0 {
+		if o.AllNamespaces {
+			fmt.Fprintln(o.ErrOut, "No Certificates found")
+		} else {
+			fmt.Fprintf(o.ErrOut, "No Certificates found in %s namespace.\n", o.Namespace)
+		}
+		return nil
+	}
+
+	size, err := bucketSize(o.Bucket)
+	if err != nil {
+		return err
+	}
+
+	forecast := NewForecast(crtsList.Items, size, o.SpikeFactor)
+	forecast.Write(o.Out, o.Bucket)
+
+	return nil
+}
+
+// NewForecast computes the next renewal time of each of the given
+// Certificates and groups the renewals into buckets of the given size.
+// A bucket is marked as a spike if it contains more than spikeFactor times the
+// average number of renewals across all buckets.
+func NewForecast(crts []cmapi.Certificate, size time.Duration, spikeFactor float64) *Forecast {
+	f := &Forecast{}
+	for i := range crts {
+		crt := &crts[i]
+		renewalTime, ok := nextRenewalTime(crt)
+		if !ok {
+			f.Unscheduled = append(f.Unscheduled, Renewal{Namespace: crt.Namespace, Name: crt.Name})
+			continue
+		}
+		f.Renewals = append(f.Renewals, Renewal{Namespace: crt.Namespace, Name: crt.Name, Time: renewalTime})
+	}
+
+	sort.SliceStable(f.Renewals, func(i, j int) bool {
+		return f.Renewals[i].Time.Before(f.Renewals[j].Time)
+	})
+
+	for _, r := range f.Renewals {
+		start := r.Time.UTC().Truncate(size)
+		if n := len(f.Buckets); n > 0 && f.Buckets[n-1].Start.Equal(start) {
+			f.Buckets[n-1].Count++
+			continue
+		}
+		f.Buckets = append(f.Buckets, Bucket{Start: start, Count: 1})
+	}
+
+	// A single bucket cannot stand out from the average.
+	if len(f.Buckets) > 1 {
+		average := float64(len(f.Renewals)) / float64(len(f.Buckets))
+		for i := range f.Buckets {
+			f.Buckets[i].Spike = float64(f.Buckets[i].Count) > spikeFactor*average
+		}
+	}
+
+	return f
+}
+
+// Write writes the forecast to out, labelling buckets according to the
+// given bucket name.
+func (f *Forecast) Write(out io.Writer, bucket string) {
+	w := util.NewTabWriter(out)
+
+	fmt.Fprint(w, "NAMESPACE\tNAME\tRENEWAL TIME\n")
+	for _, r := range f.Renewals {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Namespace, r.Name, r.Time.UTC().Format(time.RFC3339))
+	}
+	for _, r := range f.Unscheduled {
+		fmt.Fprintf(w, "%s\t%s\t<unknown>\n", r.Namespace, r.Name)
+	}
+	w.Flush()
+
+	layout := "2006-01-02"
+	if bucket == BucketHour {
+		layout = "2006-01-02 15:00"
+	}
+
+	fmt.Fprintf(out, "\nRenewals per %s:\n", bucket)
+	w = util.NewTabWriter(out)
+	fmt.Fprint(w, "PERIOD (UTC)\tRENEWALS\n")
+	for _, b := range f.Buckets {
+		if b.Spike {
+			fmt.Fprintf(w, "%s\t%d\t<- spike\n", b.Start.Format(layout), b.Count)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\n", b.Start.Format(layout), b.Count)
+	}
+	w.Flush()
+}
+
+// nextRenewalTime returns the time at which the given Certificate will next
+// be renewed, computed from the validity period of the currently issued
+// certificate and deferred to its renewal window in the same way as the
+// readiness controller. If the Certificate has not been issued, its
+// status.renewalTime is used if set.
+func nextRenewalTime(crt *cmapi.Certificate) (time.Time, bool) {
+	if crt.Status.NotBefore != nil && crt.Status.NotAfter != nil {
+		notBefore, notAfter := crt.Status.NotBefore.Time, crt.Status.NotAfter.Time
+		renewalTime := certificates.RenewalTimeWrapper(cmapi.DefaultRenewBefore)(notBefore, notAfter, crt.Spec.RenewBefore)
+		renewalTime = certificates.RenewalTimeWithIssuanceLatency(renewalTime, notBefore, notAfter, crt.Status.IssuanceLatency)
+		// An invalid renewal window does not defer the renewal.
+		if windowTime, err := apiutil.RenewalTimeInWindow(crt.Spec.RenewalWindow, renewalTime.Time, notAfter); err == nil {
+			return windowTime, true
+		}
+		return renewalTime.Time, true
+	}
+	if crt.Status.RenewalTime != nil {
+		return crt.Status.RenewalTime.Time, true
+	}
+	return time.Time{}, false
+}
+
+func bucketSize(bucket string) (time.Duration, error) {
+	switch bucket {
+	case BucketHour:
+		return time.Hour, nil
+	case BucketDay:
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("invalid bucket %q: must be %q or %q", bucket, BucketHour, BucketDay)
+	}
+}
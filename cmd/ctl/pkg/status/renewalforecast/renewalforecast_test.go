@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package renewalforecast
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+var start = time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+// issuedCertificate returns a Certificate whose current certificate is valid
+// for the given duration, starting offset after start.
+func issuedCertificate(name string, offset, duration time.Duration, mods ...gen.CertificateModifier) cmapi.Certificate {
+	mods = append([]gen.CertificateModifier{
+		gen.SetCertificateNamespace("default"),
+		gen.SetCertificateNotBefore(metav1.NewTime(start.Add(offset))),
+		gen.SetCertificateNotAfter(metav1.NewTime(start.Add(offset + duration))),
+	}, mods...)
+	return *gen.Certificate(name, mods...)
+}
+
+func testCertificates() []cmapi.Certificate {
+	renewalTime := metav1.NewTime(start.Add(28*24*time.Hour + 3*time.Hour))
+	return []cmapi.Certificate{
+		// renewed after 2/3 of its 90 day duration, on 2021-07-31
+		issuedCertificate("ninety-days", 0, 90*24*time.Hour),
+		// renewed after 2/3 of their 1 day duration, on 2021-06-01
+		issuedCertificate("one-day-a", 0, 24*time.Hour),
+		issuedCertificate("one-day-b", time.Hour, 24*time.Hour),
+		issuedCertificate("one-day-c", 2*time.Hour, 24*time.Hour),
+		// renewed 2 days before its 30 day duration ends, on 2021-06-29
+		issuedCertificate("renew-before", 0, 30*24*time.Hour, gen.SetCertificateRenewBefore(48*time.Hour)),
+		// only has a renewal time, on 2021-06-29
+		*gen.Certificate("renewal-time", gen.SetCertificateNamespace("default"), gen.SetCertificateRenewalTIme(renewalTime)),
+		// not issued yet
+		*gen.Certificate("not-issued", gen.SetCertificateNamespace("default")),
+	}
+}
+
+func TestNewForecast(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := map[string]struct {
+		size        time.Duration
+		spikeFactor float64
+		expBuckets  []Bucket
+	}{
+		"renewals grouped by day should be bucketed by UTC day": {
+			size:        day,
+			spikeFactor: 2,
+			expBuckets: []Bucket{
+				{Start: start, Count: 3},
+				{Start: start.Add(28 * day), Count: 2},
+				{Start: start.Add(60 * day), Count: 1},
+			},
+		},
+		"a day with more renewals than the spike factor allows should be marked as a spike": {
+			size:        day,
+			spikeFactor: 1.2,
+			expBuckets: []Bucket{
+				{Start: start, Count: 3, Spike: true},
+				{Start: start.Add(28 * day), Count: 2},
+				{Start: start.Add(60 * day), Count: 1},
+			},
+		},
+		"renewals grouped by hour should be bucketed by hour": {
+			size:        time.Hour,
+			spikeFactor: 2,
+			expBuckets: []Bucket{
+				{Start: start.Add(16 * time.Hour), Count: 1},
+				{Start: start.Add(17 * time.Hour), Count: 1},
+				{Start: start.Add(18 * time.Hour), Count: 1},
+				{Start: start.Add(28 * day), Count: 1},
+				{Start: start.Add(28*day + 3*time.Hour), Count: 1},
+				{Start: start.Add(60 * day), Count: 1},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			forecast := NewForecast(testCertificates(), test.size, test.spikeFactor)
+
+			assert.Equal(t, test.expBuckets, forecast.Buckets)
+
+			var names []string
+			for _, r := range forecast.Renewals {
+				names = append(names, r.Name)
+			}
+			assert.Equal(t, []string{"one-day-a", "one-day-b", "one-day-c", "renew-before", "renewal-time", "ninety-days"}, names)
+			assert.Equal(t, []Renewal{{Namespace: "default", Name: "not-issued"}}, forecast.Unscheduled)
+		})
+	}
+}
+
+func TestForecastWrite(t *testing.T) {
+	crts := []cmapi.Certificate{
+		issuedCertificate("a", 0, 24*time.Hour),
+		issuedCertificate("b", 0, 24*time.Hour),
+		issuedCertificate("c", 0, 24*time.Hour),
+		issuedCertificate("d", 0, 90*24*time.Hour),
+		*gen.Certificate("e", gen.SetCertificateNamespace("default")),
+	}
+
+	var out bytes.Buffer
+	NewForecast(crts, 24*time.Hour, 1).Write(&out, BucketDay)
+
+	// Newlines are part of the expected output
+	expOutput := `NAMESPACE  NAME  RENEWAL TIME
+default    a     2021-06-01T16:00:00Z
+default    b     2021-06-01T16:00:00Z
+default    c     2021-06-01T16:00:00Z
+default    d     2021-07-31T00:00:00Z
+default    e     <unknown>
+
+Renewals per day:
+PERIOD (UTC)  RENEWALS
+2021-06-01    3  <- spike
+2021-07-31    1
+`
+	assert.Equal(t, expOutput, out.String())
+}
+
+func TestNextRenewalTime(t *testing.T) {
+	tests := map[string]struct {
+		crt            cmapi.Certificate
+		expRenewalTime time.Time
+		expOK          bool
+	}{
+		"renewal time should be computed from the current certificate": {
+			// renewed after 2/3 of its 90 day duration, at midnight
+			crt:            issuedCertificate("ninety-days", 0, 90*24*time.Hour),
+			expRenewalTime: start.Add(60 * 24 * time.Hour),
+			expOK:          true,
+		},
+		"renewal time should be deferred until the renewal window opens": {
+			crt: issuedCertificate("window", 0, 90*24*time.Hour,
+				gen.SetCertificateRenewalWindow(cmapi.CertificateRenewalWindow{Ranges: []string{"02:00-04:00"}})),
+			expRenewalTime: start.Add(60*24*time.Hour + 2*time.Hour),
+			expOK:          true,
+		},
+		"renewal time should not be deferred if the renewal window is open": {
+			crt: issuedCertificate("window-open", 0, 90*24*time.Hour,
+				gen.SetCertificateRenewalWindow(cmapi.CertificateRenewalWindow{Ranges: []string{"22:00-02:00"}})),
+			expRenewalTime: start.Add(60 * 24 * time.Hour),
+			expOK:          true,
+		},
+		"renewal time should not be known for a Certificate that has not been issued": {
+			crt: *gen.Certificate("not-issued", gen.SetCertificateNamespace("default")),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			renewalTime, ok := nextRenewalTime(&test.crt)
+			assert.Equal(t, test.expOK, ok)
+			assert.True(t, test.expRenewalTime.Equal(renewalTime), "expected renewal time %s, got %s", test.expRenewalTime, renewalTime)
+		})
+	}
+}
@@ -24,6 +24,8 @@ import (
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 
 	"github.com/jetstack/cert-manager/cmd/ctl/pkg/status/certificate"
+	"github.com/jetstack/cert-manager/cmd/ctl/pkg/status/certificates"
+	"github.com/jetstack/cert-manager/cmd/ctl/pkg/status/renewalforecast"
 )
 
 func NewCmdStatus(ctx context.Context, ioStreams genericclioptions.IOStreams, factory cmdutil.Factory) *cobra.Command {
@@ -34,6 +36,8 @@ func NewCmdStatus(ctx context.Context, ioStreams genericclioptions.IOStreams, fa
 	}
 
 	cmds.AddCommand(certificate.NewCmdStatusCert(ctx, ioStreams, factory))
+	cmds.AddCommand(certificates.NewCmdStatusCertificates(ctx, ioStreams, factory))
+	cmds.AddCommand(renewalforecast.NewCmdStatusRenewalForecast(ctx, ioStreams, factory))
 
 	return cmds
 }
@@ -18,9 +18,12 @@ package options
 
 import (
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	cliflag "k8s.io/component-base/cli/flag"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 )
 
 type WebhookOptions struct {
@@ -56,6 +59,29 @@ type WebhookOptions struct {
 	// MinTLSVersion is the minimum TLS version supported.
 	// Values are from tls package constants (https://golang.org/pkg/crypto/tls/#pkg-constants).
 	MinTLSVersion string
+
+	// DefaultCertificateSecretName enables defaulting the spec.secretName
+	// field of Certificates to the name of the Certificate when omitted.
+	DefaultCertificateSecretName bool
+
+	// LowercaseCertificateDNSNames enables converting the spec.dnsNames of
+	// Certificates to lower case.
+	LowercaseCertificateDNSNames bool
+
+	// MinimumCertificateDuration is the shortest spec.duration of
+	// Certificates that is accepted.
+	MinimumCertificateDuration time.Duration
+
+	// TrustedRequestDelegators are the usernames that may attribute the
+	// CertificateRequests they create to another user with the requester
+	// annotations.
+	TrustedRequestDelegators []string
+
+	// RequesterUsernameAnnotation and RequesterGroupsAnnotation are the
+	// annotations that trusted delegators use to record the user a
+	// CertificateRequest was created on behalf of.
+	RequesterUsernameAnnotation string
+	RequesterGroupsAnnotation   string
 }
 
 func (o *WebhookOptions) AddFlags(fs *pflag.FlagSet) {
@@ -81,6 +107,25 @@ func (o *WebhookOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.MinTLSVersion, "tls-min-version", o.MinTLSVersion,
 		"Minimum TLS version supported. "+
 			"Possible values: "+strings.Join(tlsPossibleVersions, ", "))
+	fs.BoolVar(&o.DefaultCertificateSecretName, "default-certificate-secret-name", true, ""+
+		"If true, the spec.secretName field of Certificate resources will be set to the "+
+		"name of the Certificate if it is not specified.")
+	fs.BoolVar(&o.LowercaseCertificateDNSNames, "lowercase-certificate-dns-names", true, ""+
+		"If true, the spec.dnsNames of Certificate resources will be converted to lower case when they are created, "+
+		"so that they match the DNS names of issued certificates. Set to false to preserve their case.")
+	fs.DurationVar(&o.MinimumCertificateDuration, "minimum-certificate-duration", cmapi.MinimumCertificateDuration, ""+
+		"The shortest spec.duration of Certificate resources that will be accepted. Certificates with "+
+		"shorter durations are rejected, as they would be renewed almost continuously.")
+	fs.StringSliceVar(&o.TrustedRequestDelegators, "trusted-request-delegators", nil, ""+
+		"Usernames that may attribute the CertificateRequests they create to another user with the "+
+		"requester annotations, which are then recorded in spec.extra. The requester annotations on "+
+		"CertificateRequests created by any other user are ignored.")
+	fs.StringVar(&o.RequesterUsernameAnnotation, "requester-username-annotation", cmapi.RequesterUsernameAnnotationKey, ""+
+		"The annotation holding the username of the user that a CertificateRequest created by a trusted "+
+		"delegator was created on behalf of.")
+	fs.StringVar(&o.RequesterGroupsAnnotation, "requester-groups-annotation", cmapi.RequesterGroupsAnnotationKey, ""+
+		"The annotation holding a comma separated list of the groups of the user that a CertificateRequest "+
+		"created by a trusted delegator was created on behalf of.")
 }
 
 func FileTLSSourceEnabled(o WebhookOptions) bool {
@@ -19,13 +19,16 @@ package app
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/jetstack/cert-manager/cmd/webhook/app/options"
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/util"
 	"github.com/jetstack/cert-manager/pkg/webhook"
@@ -35,8 +38,9 @@ import (
 	"github.com/jetstack/cert-manager/pkg/webhook/server/tls"
 )
 
-var validationHook handlers.ValidatingAdmissionHook = handlers.NewRegistryBackedValidator(logf.Log, webhook.Scheme, webhook.ValidationRegistry)
-var mutationHook handlers.MutatingAdmissionHook = handlers.NewRegistryBackedMutator(logf.Log, webhook.Scheme, webhook.MutationRegistry)
+// resyncPeriod is the resync period of the informers used by the webhook.
+const resyncPeriod = 10 * time.Hour
+
 var conversionHook handlers.ConversionHook = handlers.NewSchemeBackedConverter(logf.Log, webhook.Scheme)
 
 func NewServerWithOptions(log logr.Logger, opts options.WebhookOptions) (*server.Server, error) {
@@ -49,7 +53,29 @@ func NewServerWithOptions(log logr.Logger, opts options.WebhookOptions) (*server
 	if err != nil {
 		return nil, fmt.Errorf("error creating kubernetes client: %s", err)
 	}
-	validationHook.InitPlugins(cl)
+	cmcl, err := cmclient.NewForConfig(restcfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cert-manager client: %s", err)
+	}
+	if opts.MinimumCertificateDuration <= 0 {
+		return nil, fmt.Errorf("minimum certificate duration must be greater than zero, got %s", opts.MinimumCertificateDuration)
+	}
+
+	// The informer factory is started by the server before it begins serving
+	// requests.
+	factory := informers.NewSharedInformerFactory(cl, resyncPeriod)
+	registryOpts := webhook.Options{
+		DefaultCertificateSecretName: opts.DefaultCertificateSecretName,
+		LowercaseCertificateDNSNames: opts.LowercaseCertificateDNSNames,
+		MinimumCertificateDuration:   opts.MinimumCertificateDuration,
+		NamespaceLister:              factory.Core().V1().Namespaces().Lister(),
+		TrustedRequestDelegators:     opts.TrustedRequestDelegators,
+		RequesterUsernameAnnotation:  opts.RequesterUsernameAnnotation,
+		RequesterGroupsAnnotation:    opts.RequesterGroupsAnnotation,
+	}
+	validationHook := handlers.NewRegistryBackedValidator(logf.Log, webhook.Scheme, webhook.NewValidationRegistry(registryOpts))
+	validationHook.InitPlugins(cl, cmcl)
+	mutationHook := handlers.NewRegistryBackedMutator(logf.Log, webhook.Scheme, webhook.NewMutationRegistry(registryOpts))
 
 	var source tls.CertificateSource
 	switch {
@@ -91,6 +117,7 @@ func NewServerWithOptions(log logr.Logger, opts options.WebhookOptions) (*server
 		ValidationWebhook: validationHook,
 		MutationWebhook:   mutationHook,
 		ConversionWebhook: conversionHook,
+		InformerFactory:   factory,
 		Log:               log,
 	}, nil
 }
@@ -55,21 +55,43 @@ func NewClient(client *http.Client, config cmacme.ACMEIssuer, privateKey *rsa.Pr
 // itself.
 // In future, we may change to having two global HTTP clients - one that ignores
 // TLS connection errors, and the other that does not.
-func BuildHTTPClient(metrics *metrics.Metrics, skipTLSVerify bool) *http.Client {
+// If userAgent is not empty, it is sent as the User-Agent header of every
+// request made by the client, overriding the default cert-manager user agent.
+func BuildHTTPClient(metrics *metrics.Metrics, skipTLSVerify bool, userAgent string) *http.Client {
+	var transport http.RoundTripper = &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: skipTLSVerify},
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if userAgent != "" {
+		transport = &userAgentTransport{userAgent: userAgent, wrappedRT: transport}
+	}
+
 	return acmecl.NewInstrumentedClient(metrics,
 		&http.Client{
-			Transport: &http.Transport{
-				Proxy: http.ProxyFromEnvironment,
-				DialContext: (&net.Dialer{
-					Timeout:   30 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-				TLSClientConfig:       &tls.Config{InsecureSkipVerify: skipTLSVerify},
-				MaxIdleConns:          100,
-				IdleConnTimeout:       90 * time.Second,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ExpectContinueTimeout: 1 * time.Second,
-			},
-			Timeout: time.Second * 30,
+			Transport: transport,
+			Timeout:   time.Second * 30,
 		})
 }
+
+// userAgentTransport is an http.RoundTripper that sets the User-Agent header
+// of every request before forwarding it to the wrapped RoundTripper.
+type userAgentTransport struct {
+	userAgent string
+	wrappedRT http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper. The request is cloned, as a
+// RoundTripper must not modify the request it is given.
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.wrappedRT.RoundTrip(req)
+}
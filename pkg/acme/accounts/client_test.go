@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/metrics"
+	"github.com/jetstack/cert-manager/pkg/util"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+func TestBuildHTTPClientUserAgent(t *testing.T) {
+	var (
+		lock       sync.Mutex
+		userAgents []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		userAgents = append(userAgents, r.UserAgent())
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"newNonce": %q, "newAccount": %q, "newOrder": %q}`,
+			"http://"+r.Host+"/nonce", "http://"+r.Host+"/account", "http://"+r.Host+"/order")
+	}))
+	defer srv.Close()
+
+	pk, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		userAgent string
+		// expPrefix is the expected prefix of the User-Agent header, as the
+		// ACME library appends its own product token to the default
+		expPrefix string
+		expExact  bool
+	}{
+		"the default cert-manager user agent should be sent if none is configured": {
+			expPrefix: util.CertManagerUserAgent,
+		},
+		"the configured user agent should be sent instead of the default": {
+			userAgent: "corp-proxy-allowed/1.0",
+			expPrefix: "corp-proxy-allowed/1.0",
+			expExact:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			userAgents = nil
+			httpClient := BuildHTTPClient(metrics.New(logf.Log), false, test.userAgent)
+			cl := NewClient(httpClient, cmacme.ACMEIssuer{Server: srv.URL}, pk)
+			if _, err := cl.Discover(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if len(userAgents) == 0 {
+				t.Fatal("expected a request to be made to the ACME server")
+			}
+			for _, ua := range userAgents {
+				if test.expExact && ua != test.expPrefix {
+					t.Errorf("unexpected User-Agent, exp=%q got=%q", test.expPrefix, ua)
+				}
+				if !strings.HasPrefix(ua, test.expPrefix) {
+					t.Errorf("unexpected User-Agent, exp prefix=%q got=%q", test.expPrefix, ua)
+				}
+			}
+		})
+	}
+}
@@ -79,6 +79,7 @@ type registry struct {
 type stableOptions struct {
 	serverURL     string
 	skipVerifyTLS bool
+	userAgent     string
 	issuerUID     string
 	publicKey     string
 	exponent      int
@@ -94,6 +95,7 @@ func newStableOptions(uid string, config cmacme.ACMEIssuer, privateKey *rsa.Priv
 	return stableOptions{
 		serverURL:     config.Server,
 		skipVerifyTLS: config.SkipTLSVerify,
+		userAgent:     config.UserAgent,
 		issuerUID:     uid,
 		publicKey:     string(publicNBytes),
 		exponent:      privateKey.PublicKey.E,
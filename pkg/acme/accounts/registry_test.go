@@ -145,3 +145,36 @@ func TestRegistry_AddClient_UpdatesExistingWhenPrivateKeyChanges(t *testing.T) {
 		t.Errorf("expected ListClients to have 1 item but it has %d", len(l))
 	}
 }
+
+func TestRegistry_AddClient_ReplacesExistingWhenUserAgentChanges(t *testing.T) {
+	r := NewDefaultRegistry()
+	pk, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.AddClient(http.DefaultClient, "abc", cmacme.ACMEIssuer{}, pk)
+	c1, err := r.GetClient("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Registering the same options should not replace the client
+	r.AddClient(http.DefaultClient, "abc", cmacme.ACMEIssuer{}, pk)
+	c2, err := r.GetClient("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 != c2 {
+		t.Errorf("expected client to not be replaced when the options are unchanged")
+	}
+
+	r.AddClient(http.DefaultClient, "abc", cmacme.ACMEIssuer{UserAgent: "corp-proxy-allowed/1.0"}, pk)
+	c3, err := r.GetClient("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1 == c3 {
+		t.Errorf("expected client to be replaced when the user agent changes")
+	}
+}
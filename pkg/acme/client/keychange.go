@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+
+	"golang.org/x/crypto/acme"
+
+	acmeutil "github.com/jetstack/cert-manager/pkg/acme/util"
+	"github.com/jetstack/cert-manager/pkg/util"
+)
+
+// This file implements the ACME account key rollover flow described in
+// RFC 8555 section 7.3.5 (https://tools.ietf.org/html/rfc8555#section-7.3.5).
+// The ACME library we use does not expose this flow, nor the JWS helpers it
+// uses internally, so the request is built and signed here.
+
+// AccountKeyRolloverFunc is a function type for changing the key of an
+// existing ACME account.
+type AccountKeyRolloverFunc func(ctx context.Context, httpClient *http.Client, directoryURL, accountURL string, oldKey, newKey *rsa.PrivateKey) error
+
+var _ AccountKeyRolloverFunc = AccountKeyRollover
+
+// AccountKeyRollover changes the key of the ACME account at accountURL,
+// which must currently be registered with oldKey, to newKey. The account
+// URL is retained by the ACME server.
+func AccountKeyRollover(ctx context.Context, httpClient *http.Client, directoryURL, accountURL string, oldKey, newKey *rsa.PrivateKey) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	cl := &acme.Client{
+		HTTPClient:   httpClient,
+		DirectoryURL: directoryURL,
+		UserAgent:    util.CertManagerUserAgent,
+		RetryBackoff: acmeutil.RetryBackoff,
+	}
+	dir, err := cl.Discover(ctx)
+	if err != nil {
+		return err
+	}
+	if dir.KeyChangeURL == "" {
+		return fmt.Errorf("ACME server at %q does not support account key rollover", directoryURL)
+	}
+
+	// The inner JWS is signed by the new key and proves possession of it.
+	inner, err := jwsEncodeJSON(newKey, map[string]interface{}{
+		"alg": "RS256",
+		"jwk": jwkEncode(&newKey.PublicKey),
+		"url": dir.KeyChangeURL,
+	}, struct {
+		Account string      `json:"account"`
+		OldKey  interface{} `json:"oldKey"`
+	}{
+		Account: accountURL,
+		OldKey:  jwkEncode(&oldKey.PublicKey),
+	})
+	if err != nil {
+		return err
+	}
+
+	nonce, err := fetchNonce(ctx, httpClient, dir.NonceURL)
+	if err != nil {
+		return err
+	}
+
+	// The outer JWS is signed by the old key, authenticating the request as
+	// coming from the existing account.
+	outer, err := jwsEncodeJSON(oldKey, map[string]interface{}{
+		"alg":   "RS256",
+		"kid":   accountURL,
+		"nonce": nonce,
+		"url":   dir.KeyChangeURL,
+	}, json.RawMessage(inner))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dir.KeyChangeURL, bytes.NewReader(outer))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/jose+json")
+	req.Header.Set("User-Agent", util.CertManagerUserAgent)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	return nil
+}
+
+// fetchNonce retrieves a fresh anti-replay nonce from the ACME server.
+func fetchNonce(ctx context.Context, httpClient *http.Client, nonceURL string) (string, error) {
+	if nonceURL == "" {
+		return "", fmt.Errorf("ACME server did not advertise a newNonce URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, nonceURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", util.CertManagerUserAgent)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	nonce := res.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		if res.StatusCode > 299 {
+			return "", responseError(res)
+		}
+		return "", fmt.Errorf("ACME server did not return a Replay-Nonce header")
+	}
+	return nonce, nil
+}
+
+// responseError converts an error response from the ACME server into an
+// *acme.Error.
+func responseError(res *http.Response) error {
+	body, _ := ioutil.ReadAll(res.Body)
+	problem := struct {
+		Type   string `json:"type"`
+		Detail string `json:"detail"`
+	}{}
+	if err := json.Unmarshal(body, &problem); err != nil {
+		problem.Detail = string(body)
+	}
+	return &acme.Error{
+		StatusCode:  res.StatusCode,
+		ProblemType: problem.Type,
+		Detail:      problem.Detail,
+		Header:      res.Header,
+	}
+}
+
+// jwsEncodeJSON signs payload with key using RS256 and returns the JWS in
+// flattened JSON serialization.
+func jwsEncodeJSON(key *rsa.PrivateKey, header map[string]interface{}, payload interface{}) ([]byte, error) {
+	phead, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	protected := base64.RawURLEncoding.EncodeToString(phead)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(body)
+
+	digest := sha256.Sum256([]byte(protected + "." + encodedPayload))
+	sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}{
+		Protected: protected,
+		Payload:   encodedPayload,
+		Signature: base64.RawURLEncoding.EncodeToString(sig),
+	})
+}
+
+// jwkEncode returns the JSON Web Key representation of an RSA public key.
+func jwkEncode(pub *rsa.PublicKey) interface{} {
+	return struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+	}
+}
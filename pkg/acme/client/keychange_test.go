@@ -0,0 +1,280 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/acme"
+)
+
+// fakeKeyChangeServer is a minimal ACME server that implements the directory,
+// newNonce and keyChange endpoints.
+type fakeKeyChangeServer struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	nonces   map[string]bool
+	accounts map[string]*rsa.PublicKey
+}
+
+type jws struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+type jwsHeader struct {
+	Alg   string          `json:"alg"`
+	KID   string          `json:"kid"`
+	JWK   json.RawMessage `json:"jwk"`
+	Nonce string          `json:"nonce"`
+	URL   string          `json:"url"`
+}
+
+func newFakeKeyChangeServer(t *testing.T) *fakeKeyChangeServer {
+	s := &fakeKeyChangeServer{
+		nonces:   make(map[string]bool),
+		accounts: make(map[string]*rsa.PublicKey),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"newNonce": %q, "newOrder": %q, "keyChange": %q}`, s.URL+"/new-nonce", s.URL+"/new-order", s.URL+"/key-change")
+	})
+	mux.HandleFunc("/new-nonce", func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		nonce := fmt.Sprintf("nonce-%d", len(s.nonces))
+		s.nonces[nonce] = true
+		w.Header().Set("Replay-Nonce", nonce)
+	})
+	mux.HandleFunc("/key-change", func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if err := s.keyChange(r); err != nil {
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"type": "urn:ietf:params:acme:error:unauthorized", "detail": %q}`, err.Error())
+		}
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *fakeKeyChangeServer) keyChange(r *http.Request) error {
+	var outer jws
+	if err := json.NewDecoder(r.Body).Decode(&outer); err != nil {
+		return err
+	}
+	var outerHeader jwsHeader
+	if err := decodeSegment(outer.Protected, &outerHeader); err != nil {
+		return err
+	}
+	if !s.nonces[outerHeader.Nonce] {
+		return fmt.Errorf("invalid nonce %q", outerHeader.Nonce)
+	}
+	delete(s.nonces, outerHeader.Nonce)
+	if outerHeader.URL != s.URL+"/key-change" {
+		return fmt.Errorf("unexpected outer url %q", outerHeader.URL)
+	}
+	oldKey, ok := s.accounts[outerHeader.KID]
+	if !ok {
+		return fmt.Errorf("account %q does not exist", outerHeader.KID)
+	}
+	if err := verify(oldKey, outer); err != nil {
+		return fmt.Errorf("outer signature: %v", err)
+	}
+
+	var inner jws
+	if err := decodeSegment(outer.Payload, &inner); err != nil {
+		return err
+	}
+	var innerHeader jwsHeader
+	if err := decodeSegment(inner.Protected, &innerHeader); err != nil {
+		return err
+	}
+	if innerHeader.URL != outerHeader.URL {
+		return fmt.Errorf("inner url %q does not match outer url %q", innerHeader.URL, outerHeader.URL)
+	}
+	newKey, err := decodeJWK(innerHeader.JWK)
+	if err != nil {
+		return err
+	}
+	if err := verify(newKey, inner); err != nil {
+		return fmt.Errorf("inner signature: %v", err)
+	}
+
+	var payload struct {
+		Account string          `json:"account"`
+		OldKey  json.RawMessage `json:"oldKey"`
+	}
+	if err := decodeSegment(inner.Payload, &payload); err != nil {
+		return err
+	}
+	if payload.Account != outerHeader.KID {
+		return fmt.Errorf("inner account %q does not match outer kid %q", payload.Account, outerHeader.KID)
+	}
+	payloadOldKey, err := decodeJWK(payload.OldKey)
+	if err != nil {
+		return err
+	}
+	if !payloadOldKey.Equal(oldKey) {
+		return fmt.Errorf("oldKey does not match the account key")
+	}
+
+	s.accounts[payload.Account] = newKey
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func decodeJWK(raw json.RawMessage) (*rsa.PublicKey, error) {
+	var jwk struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return nil, err
+	}
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("unexpected key type %q", jwk.Kty)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func verify(pub *rsa.PublicKey, sig jws) error {
+	s, err := base64.RawURLEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(sig.Protected + "." + sig.Payload))
+	return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], s)
+}
+
+func mustGenerateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestAccountKeyRollover(t *testing.T) {
+	oldKey := mustGenerateRSAKey(t)
+	newKey := mustGenerateRSAKey(t)
+	otherKey := mustGenerateRSAKey(t)
+
+	tests := map[string]struct {
+		// key the account is registered with on the server
+		registeredKey *rsa.PrivateKey
+		// key used to sign the outer JWS
+		oldKey *rsa.PrivateKey
+
+		expErr bool
+		// key the account should be registered with after the rollover
+		expKey *rsa.PrivateKey
+	}{
+		"account key should be rolled over to the new key": {
+			registeredKey: oldKey,
+			oldKey:        oldKey,
+			expKey:        newKey,
+		},
+		"rollover should fail if the account is not registered with the old key": {
+			registeredKey: otherKey,
+			oldKey:        oldKey,
+			expErr:        true,
+			expKey:        otherKey,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newFakeKeyChangeServer(t)
+			accountURL := s.URL + "/account/1"
+			s.accounts[accountURL] = &test.registeredKey.PublicKey
+
+			err := AccountKeyRollover(context.Background(), s.Client(), s.URL+"/directory", accountURL, test.oldKey, newKey)
+			if test.expErr != (err != nil) {
+				t.Fatalf("expected error=%t, got: %v", test.expErr, err)
+			}
+			if err != nil {
+				if _, ok := err.(*acme.Error); !ok {
+					t.Errorf("expected an *acme.Error, got: %T", err)
+				}
+			}
+
+			if len(s.accounts) != 1 {
+				t.Fatalf("expected a single account to exist, got: %d", len(s.accounts))
+			}
+			got, ok := s.accounts[accountURL]
+			if !ok {
+				t.Fatalf("expected account URL %q to be retained", accountURL)
+			}
+			if !got.Equal(&test.expKey.PublicKey) {
+				t.Errorf("account is not registered with the expected key")
+			}
+		})
+	}
+}
+
+func TestAccountKeyRolloverNotSupported(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"newNonce": "https://example.com/new-nonce", "newOrder": "https://example.com/new-order"}`)
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+
+	err := AccountKeyRollover(context.Background(), s.Client(), s.URL+"/directory", s.URL+"/account/1", mustGenerateRSAKey(t), mustGenerateRSAKey(t))
+	if err == nil {
+		t.Fatal("expected an error when the server does not advertise a keyChange URL")
+	}
+}
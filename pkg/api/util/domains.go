@@ -0,0 +1,64 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+)
+
+// DNSNameAllowed returns true if the DNS name is permitted by the given
+// allowedDomains of an Issuer. An entry of the form "*.example.com" permits
+// any subdomain of example.com, and any other entry permits the domain
+// itself as well as any of its subdomains. All DNS names are permitted if
+// allowedDomains is empty.
+func DNSNameAllowed(allowedDomains []string, dnsName string) bool {
+	if len(allowedDomains) == 0 {
+		return true
+	}
+
+	dnsName = normaliseDomain(dnsName)
+	for _, allowed := range allowedDomains {
+		allowed = normaliseDomain(allowed)
+		if suffix := strings.TrimPrefix(allowed, "*"); suffix != allowed {
+			if strings.HasSuffix(dnsName, suffix) && len(dnsName) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if dnsName == allowed || strings.HasSuffix(dnsName, "."+allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DisallowedDNSNames returns the DNS names that are not permitted by the
+// given allowedDomains of an Issuer, in the order they are given.
+func DisallowedDNSNames(allowedDomains []string, dnsNames []string) []string {
+	var disallowed []string
+	for _, dnsName := range dnsNames {
+		if !DNSNameAllowed(allowedDomains, dnsName) {
+			disallowed = append(disallowed, dnsName)
+		}
+	}
+	return disallowed
+}
+
+func normaliseDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
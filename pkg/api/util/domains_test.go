@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestDNSNameAllowed(t *testing.T) {
+	tests := map[string]struct {
+		allowedDomains []string
+		dnsName        string
+		exp            bool
+	}{
+		"any name is allowed if allowedDomains is empty": {
+			dnsName: "example.com",
+			exp:     true,
+		},
+		"a domain entry allows the domain itself": {
+			allowedDomains: []string{"example.com"},
+			dnsName:        "example.com",
+			exp:            true,
+		},
+		"a domain entry allows subdomains": {
+			allowedDomains: []string{"example.com"},
+			dnsName:        "foo.bar.example.com",
+			exp:            true,
+		},
+		"a domain entry allows wildcard subdomains": {
+			allowedDomains: []string{"example.com"},
+			dnsName:        "*.example.com",
+			exp:            true,
+		},
+		"a domain entry does not allow names that only share a suffix": {
+			allowedDomains: []string{"example.com"},
+			dnsName:        "badexample.com",
+			exp:            false,
+		},
+		"a wildcard entry allows subdomains": {
+			allowedDomains: []string{"*.example.com"},
+			dnsName:        "foo.example.com",
+			exp:            true,
+		},
+		"a wildcard entry does not allow the domain itself": {
+			allowedDomains: []string{"*.example.com"},
+			dnsName:        "example.com",
+			exp:            false,
+		},
+		"a wildcard entry does not allow names that only share a suffix": {
+			allowedDomains: []string{"*.example.com"},
+			dnsName:        "badexample.com",
+			exp:            false,
+		},
+		"names are compared case insensitively and ignoring a trailing dot": {
+			allowedDomains: []string{"Example.COM."},
+			dnsName:        "foo.EXAMPLE.com",
+			exp:            true,
+		},
+		"a name is allowed if any entry matches": {
+			allowedDomains: []string{"example.org", "*.example.com"},
+			dnsName:        "foo.example.com",
+			exp:            true,
+		},
+		"a name is not allowed if no entry matches": {
+			allowedDomains: []string{"example.org", "*.example.com"},
+			dnsName:        "example.net",
+			exp:            false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := DNSNameAllowed(test.allowedDomains, test.dnsName); got != test.exp {
+				t.Errorf("unexpected result, exp=%t got=%t", test.exp, got)
+			}
+		})
+	}
+}
@@ -47,7 +47,7 @@ func TestComputeName(t *testing.T) {
 				},
 			},
 			wantErr: false,
-			want:    "unit.test.jetstack.io-1683025094",
+			want:    "unit.test.jetstack.io-1064602867",
 		},
 		{
 			name: "Name generation too long domains",
@@ -62,7 +62,7 @@ func TestComputeName(t *testing.T) {
 				},
 			},
 			wantErr: false,
-			want:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-108802726",
+			want:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-1169391635",
 		},
 		{
 			name: "Name generation for dot as 52nd char",
@@ -77,7 +77,7 @@ func TestComputeName(t *testing.T) {
 				},
 			},
 			wantErr: false,
-			want:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-225297437",
+			want:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-1044997040",
 		},
 		{
 			name: "Name generation for dot as 54td char",
@@ -92,7 +92,7 @@ func TestComputeName(t *testing.T) {
 				},
 			},
 			wantErr: false,
-			want:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-1448584771",
+			want:    "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-161411634",
 		},
 	}
 	for _, tt := range tests {
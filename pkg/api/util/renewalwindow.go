@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// RenewalWindowExpiryMargin is the minimum time that a certificate must
+// remain valid for after its renewal window next opens for its renewal to be
+// deferred until then.
+const RenewalWindowExpiryMargin = time.Hour
+
+// ParseTimeOfDayRange parses a range of times of day in the form
+// "HH:MM-HH:MM", and returns its start and end as offsets from midnight. The
+// end is before the start if the range spans midnight.
+func ParseTimeOfDayRange(r string) (start, end time.Duration, err error) {
+	parts := strings.Split(r, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%q is not of the form HH:MM-HH:MM", r)
+	}
+	if start, err = parseTimeOfDay(parts[0]); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseTimeOfDay(parts[1]); err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("%q must not start and end at the same time", r)
+	}
+	return start, end, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid time of day of the form HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// NextRenewalWindow returns the earliest time at or after now at which the
+// given renewal window is open. If the window is open at now, now is
+// returned.
+func NextRenewalWindow(window *v1.CertificateRenewalWindow, now time.Time) (time.Time, error) {
+	loc := time.UTC
+	if window.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(window.TimeZone); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time zone %q: %w", window.TimeZone, err)
+		}
+	}
+
+	local := now.In(loc)
+	var next time.Time
+	for _, r := range window.Ranges {
+		start, end, err := ParseTimeOfDayRange(r)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if end < start {
+			end += 24 * time.Hour
+		}
+
+		// A range that spans midnight may have opened on the previous day.
+		for day := -1; day <= 1; day++ {
+			midnight := time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, loc)
+			opens, closes := midnight.Add(start), midnight.Add(end)
+			if !local.Before(opens) && local.Before(closes) {
+				return now, nil
+			}
+			if opens.After(local) && (next.IsZero() || opens.Before(next)) {
+				next = opens
+			}
+		}
+	}
+
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("renewal window has no ranges")
+	}
+	return next, nil
+}
+
+// RenewalTimeInWindow returns the time at which a certificate that expires at
+// notAfter and is due for renewal at renewalTime is renewed, given the
+// Certificate's renewal window. The renewal is deferred until the window next
+// opens, unless the certificate would expire within RenewalWindowExpiryMargin
+// of it opening. renewalTime is returned if the window is nil or open at
+// renewalTime.
+func RenewalTimeInWindow(window *v1.CertificateRenewalWindow, renewalTime, notAfter time.Time) (time.Time, error) {
+	if window == nil {
+		return renewalTime, nil
+	}
+
+	next, err := NextRenewalWindow(window, renewalTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if notAfter.Sub(next) < RenewalWindowExpiryMargin {
+		return renewalTime, nil
+	}
+	return next, nil
+}
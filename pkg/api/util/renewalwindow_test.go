@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+func TestNextRenewalWindow(t *testing.T) {
+	tests := map[string]struct {
+		window  cmapi.CertificateRenewalWindow
+		now     time.Time
+		expNext time.Time
+		expErr  bool
+	}{
+		"should return now if the window is open": {
+			window:  cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}},
+			now:     time.Date(2021, time.May, 4, 19, 30, 0, 0, time.UTC),
+			expNext: time.Date(2021, time.May, 4, 19, 30, 0, 0, time.UTC),
+		},
+		"should return the start of the window later in the day": {
+			window:  cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}},
+			now:     time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC),
+			expNext: time.Date(2021, time.May, 4, 18, 0, 0, 0, time.UTC),
+		},
+		"should return the start of the window on the next day once it has closed": {
+			window:  cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}},
+			now:     time.Date(2021, time.May, 4, 22, 0, 0, 0, time.UTC),
+			expNext: time.Date(2021, time.May, 5, 18, 0, 0, 0, time.UTC),
+		},
+		"should return now if a window spanning midnight opened on the previous day": {
+			window:  cmapi.CertificateRenewalWindow{Ranges: []string{"22:00-06:00"}},
+			now:     time.Date(2021, time.May, 4, 3, 0, 0, 0, time.UTC),
+			expNext: time.Date(2021, time.May, 4, 3, 0, 0, 0, time.UTC),
+		},
+		"should return the soonest of multiple ranges": {
+			window:  cmapi.CertificateRenewalWindow{Ranges: []string{"20:00-22:00", "13:00-14:00"}},
+			now:     time.Date(2021, time.May, 4, 9, 0, 0, 0, time.UTC),
+			expNext: time.Date(2021, time.May, 4, 13, 0, 0, 0, time.UTC),
+		},
+		"should interpret the ranges in the given time zone": {
+			window:  cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}, TimeZone: "America/New_York"},
+			now:     time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC),
+			expNext: time.Date(2021, time.May, 4, 22, 0, 0, 0, time.UTC),
+		},
+		"should error on an invalid range": {
+			window: cmapi.CertificateRenewalWindow{Ranges: []string{"18:00"}},
+			now:    time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC),
+			expErr: true,
+		},
+		"should error on an invalid time zone": {
+			window: cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}, TimeZone: "Not/AZone"},
+			now:    time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC),
+			expErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			next, err := NextRenewalWindow(&test.window, test.now)
+			if test.expErr != (err != nil) {
+				t.Fatalf("expected error=%t, got: %v", test.expErr, err)
+			}
+			if !next.Equal(test.expNext) {
+				t.Errorf("expected next renewal window at %s, got %s", test.expNext, next)
+			}
+		})
+	}
+}
+
+func TestRenewalTimeInWindow(t *testing.T) {
+	renewalTime := time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC)
+	window := &cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}}
+
+	tests := map[string]struct {
+		window         *cmapi.CertificateRenewalWindow
+		notAfter       time.Time
+		expRenewalTime time.Time
+		expErr         bool
+	}{
+		"should return the renewal time if there is no window": {
+			notAfter:       renewalTime.Add(24 * time.Hour),
+			expRenewalTime: renewalTime,
+		},
+		"should return the renewal time if the window is open": {
+			window:         &cmapi.CertificateRenewalWindow{Ranges: []string{"10:00-14:00"}},
+			notAfter:       renewalTime.Add(24 * time.Hour),
+			expRenewalTime: renewalTime,
+		},
+		"should defer the renewal until the window opens": {
+			window:         window,
+			notAfter:       renewalTime.Add(24 * time.Hour),
+			expRenewalTime: time.Date(2021, time.May, 4, 18, 0, 0, 0, time.UTC),
+		},
+		"should not defer the renewal if the certificate expires within the margin of the window opening": {
+			window:         window,
+			notAfter:       time.Date(2021, time.May, 4, 18, 30, 0, 0, time.UTC),
+			expRenewalTime: renewalTime,
+		},
+		"should error on an invalid window": {
+			window:   &cmapi.CertificateRenewalWindow{Ranges: []string{"18:00"}},
+			notAfter: renewalTime.Add(24 * time.Hour),
+			expErr:   true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := RenewalTimeInWindow(test.window, renewalTime, test.notAfter)
+			if test.expErr != (err != nil) {
+				t.Fatalf("expected error=%t, got: %v", test.expErr, err)
+			}
+			if !got.Equal(test.expRenewalTime) {
+				t.Errorf("expected renewal at %s, got %s", test.expRenewalTime, got)
+			}
+		})
+	}
+}
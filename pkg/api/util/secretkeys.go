@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+)
+
+// SecretKeyNames returns the names of the keys in the Certificate's Secret
+// that the private key, certificate and CA are stored under, as configured by
+// spec.secretKeys. The default names are returned for any that are not set.
+func SecretKeyNames(crt *cmapi.Certificate) (privateKey, certificate, ca string) {
+	privateKey, certificate, ca = corev1.TLSPrivateKeyKey, corev1.TLSCertKey, cmmeta.TLSCAKey
+	keys := crt.Spec.SecretKeys
+	if keys == nil {
+		return
+	}
+	if keys.PrivateKey != "" {
+		privateKey = keys.PrivateKey
+	}
+	if keys.Certificate != "" {
+		certificate = keys.Certificate
+	}
+	if keys.CA != "" {
+		ca = keys.CA
+	}
+	return
+}
+
+// SecretKeyNamesForSecret returns the names of the keys in a Certificate's
+// Secret that the private key, certificate and CA are stored under, as
+// recorded in the Secret's annotations, for consumers that only have the
+// Secret and not the Certificate. The default names are returned for any
+// that are not recorded.
+func SecretKeyNamesForSecret(secret *corev1.Secret) (privateKey, certificate, ca string) {
+	privateKey, certificate, ca = corev1.TLSPrivateKeyKey, corev1.TLSCertKey, cmmeta.TLSCAKey
+	if name := secret.Annotations[cmapi.SecretPrivateKeyKeyAnnotationKey]; name != "" {
+		privateKey = name
+	}
+	if name := secret.Annotations[cmapi.SecretCertificateKeyAnnotationKey]; name != "" {
+		certificate = name
+	}
+	if name := secret.Annotations[cmapi.SecretCAKeyAnnotationKey]; name != "" {
+		ca = name
+	}
+	return
+}
+
+// SecretKeyNamesAreTLSCompatible returns true if the Certificate stores its
+// private key and certificate under the names required by Secrets of type
+// kubernetes.io/tls.
+func SecretKeyNamesAreTLSCompatible(crt *cmapi.Certificate) bool {
+	privateKey, certificate, _ := SecretKeyNames(crt)
+	return privateKey == corev1.TLSPrivateKeyKey && certificate == corev1.TLSCertKey
+}
+
+// SecretWithDefaultKeyNames returns a copy of the Certificate's Secret in
+// which the private key, certificate and CA stored under the names configured
+// by spec.secretKeys are moved to the default names, so that the Secret can
+// be inspected without regard for the configured names. The Secret is
+// returned unchanged if the default names are used.
+func SecretWithDefaultKeyNames(crt *cmapi.Certificate, secret *corev1.Secret) *corev1.Secret {
+	if secret == nil {
+		return nil
+	}
+	privateKey, certificate, ca := SecretKeyNames(crt)
+	if privateKey == corev1.TLSPrivateKeyKey && certificate == corev1.TLSCertKey && ca == cmmeta.TLSCAKey {
+		return secret
+	}
+
+	secret = secret.DeepCopy()
+	data := make(map[string][]byte, len(secret.Data))
+	for k, v := range secret.Data {
+		if k == corev1.TLSPrivateKeyKey || k == corev1.TLSCertKey || k == cmmeta.TLSCAKey {
+			continue
+		}
+		data[k] = v
+	}
+	for from, to := range map[string]string{
+		privateKey:  corev1.TLSPrivateKeyKey,
+		certificate: corev1.TLSCertKey,
+		ca:          cmmeta.TLSCAKey,
+	} {
+		if v, ok := secret.Data[from]; ok {
+			data[to] = v
+		}
+	}
+	secret.Data = data
+	return secret
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+)
+
+func TestSecretKeyNames(t *testing.T) {
+	tests := map[string]struct {
+		keys                  *cmapi.CertificateSecretKeys
+		expPK, expCert, expCA string
+		expTLSCompatible      bool
+	}{
+		"default names are used if secretKeys is not set": {
+			expPK: corev1.TLSPrivateKeyKey, expCert: corev1.TLSCertKey, expCA: cmmeta.TLSCAKey,
+			expTLSCompatible: true,
+		},
+		"default names are used for names that are not set": {
+			keys:  &cmapi.CertificateSecretKeys{CA: "root.pem"},
+			expPK: corev1.TLSPrivateKeyKey, expCert: corev1.TLSCertKey, expCA: "root.pem",
+			expTLSCompatible: true,
+		},
+		"configured names are used": {
+			keys:  &cmapi.CertificateSecretKeys{PrivateKey: "key.pem", Certificate: "cert.pem", CA: "root.pem"},
+			expPK: "key.pem", expCert: "cert.pem", expCA: "root.pem",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretKeys: test.keys}}
+			pk, cert, ca := SecretKeyNames(crt)
+			assert.Equal(t, test.expPK, pk)
+			assert.Equal(t, test.expCert, cert)
+			assert.Equal(t, test.expCA, ca)
+			assert.Equal(t, test.expTLSCompatible, SecretKeyNamesAreTLSCompatible(crt))
+		})
+	}
+}
+
+func TestSecretKeyNamesForSecret(t *testing.T) {
+	tests := map[string]struct {
+		annotations           map[string]string
+		expPK, expCert, expCA string
+	}{
+		"default names are used if none are recorded": {
+			expPK: corev1.TLSPrivateKeyKey, expCert: corev1.TLSCertKey, expCA: cmmeta.TLSCAKey,
+		},
+		"default names are used for names that are not recorded": {
+			annotations: map[string]string{cmapi.SecretCAKeyAnnotationKey: "root.pem"},
+			expPK:       corev1.TLSPrivateKeyKey, expCert: corev1.TLSCertKey, expCA: "root.pem",
+		},
+		"recorded names are used": {
+			annotations: map[string]string{
+				cmapi.SecretPrivateKeyKeyAnnotationKey:  "key.pem",
+				cmapi.SecretCertificateKeyAnnotationKey: "cert.pem",
+				cmapi.SecretCAKeyAnnotationKey:          "root.pem",
+			},
+			expPK: "key.pem", expCert: "cert.pem", expCA: "root.pem",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			pk, cert, ca := SecretKeyNamesForSecret(secret)
+			assert.Equal(t, test.expPK, pk)
+			assert.Equal(t, test.expCert, cert)
+			assert.Equal(t, test.expCA, ca)
+		})
+	}
+}
+
+func TestSecretWithDefaultKeyNames(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{
+		"key.pem":  []byte("key"),
+		"cert.pem": []byte("cert"),
+		"other":    []byte("other"),
+		// ignored as it is not the configured name
+		corev1.TLSCertKey: []byte("stale"),
+	}}
+
+	t.Run("the secret is returned unchanged if the default names are used", func(t *testing.T) {
+		crt := &cmapi.Certificate{}
+		assert.Same(t, secret, SecretWithDefaultKeyNames(crt, secret))
+	})
+
+	t.Run("the configured names are moved to the default names", func(t *testing.T) {
+		crt := &cmapi.Certificate{Spec: cmapi.CertificateSpec{SecretKeys: &cmapi.CertificateSecretKeys{
+			PrivateKey: "key.pem", Certificate: "cert.pem", CA: "root.pem",
+		}}}
+		got := SecretWithDefaultKeyNames(crt, secret)
+		assert.Equal(t, map[string][]byte{
+			corev1.TLSPrivateKeyKey: []byte("key"),
+			corev1.TLSCertKey:       []byte("cert"),
+			"key.pem":               []byte("key"),
+			"cert.pem":              []byte("cert"),
+			"other":                 []byte("other"),
+		}, got.Data)
+		// the original secret is not modified
+		assert.Equal(t, []byte("stale"), secret.Data[corev1.TLSCertKey])
+	})
+}
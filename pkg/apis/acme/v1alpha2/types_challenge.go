@@ -142,4 +142,10 @@ type ChallengeStatus struct {
 	// If not set, the state of the challenge is unknown.
 	// +optional
 	State State `json:"state,omitempty"`
+
+	// FailedAttempts is the number of times presenting this challenge, or
+	// accepting it with the ACME server, has failed. It is only recorded if
+	// the issuer sets maxChallengeAttempts.
+	// +optional
+	FailedAttempts int32 `json:"failedAttempts,omitempty"`
 }
@@ -22,7 +22,8 @@ package v1alpha2
 
 import (
 	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/networking/v1"
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
@@ -149,6 +150,13 @@ func (in *ACMEChallengeSolverDNS01) DeepCopyInto(out *ACMEChallengeSolverDNS01)
 		*out = new(ACMEIssuerDNS01ProviderWebhook)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdditionalProviders != nil {
+		in, out := &in.AdditionalProviders, &out.AdditionalProviders
+		*out = make([]ACMEChallengeSolverDNS01, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -170,6 +178,11 @@ func (in *ACMEChallengeSolverHTTP01) DeepCopyInto(out *ACMEChallengeSolverHTTP01
 		*out = new(ACMEChallengeSolverHTTP01Ingress)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GatewayHTTPRoute != nil {
+		in, out := &in.GatewayHTTPRoute, &out.GatewayHTTPRoute
+		*out = new(ACMEChallengeSolverHTTP01GatewayHTTPRoute)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -183,6 +196,46 @@ func (in *ACMEChallengeSolverHTTP01) DeepCopy() *ACMEChallengeSolverHTTP01 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ACMEChallengeSolverHTTP01GatewayHTTPRoute) DeepCopyInto(out *ACMEChallengeSolverHTTP01GatewayHTTPRoute) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.GatewayRef = in.GatewayRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ACMEChallengeSolverHTTP01GatewayHTTPRoute.
+func (in *ACMEChallengeSolverHTTP01GatewayHTTPRoute) DeepCopy() *ACMEChallengeSolverHTTP01GatewayHTTPRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(ACMEChallengeSolverHTTP01GatewayHTTPRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ACMEChallengeSolverHTTP01GatewayReference) DeepCopyInto(out *ACMEChallengeSolverHTTP01GatewayReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ACMEChallengeSolverHTTP01GatewayReference.
+func (in *ACMEChallengeSolverHTTP01GatewayReference) DeepCopy() *ACMEChallengeSolverHTTP01GatewayReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ACMEChallengeSolverHTTP01GatewayReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ACMEChallengeSolverHTTP01Ingress) DeepCopyInto(out *ACMEChallengeSolverHTTP01Ingress) {
 	*out = *in
@@ -191,6 +244,16 @@ func (in *ACMEChallengeSolverHTTP01Ingress) DeepCopyInto(out *ACMEChallengeSolve
 		*out = new(string)
 		**out = **in
 	}
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.PathType != nil {
+		in, out := &in.PathType, &out.PathType
+		*out = new(v1.PathType)
+		**out = **in
+	}
 	if in.PodTemplate != nil {
 		in, out := &in.PodTemplate, &out.PodTemplate
 		*out = new(ACMEChallengeSolverHTTP01IngressPodTemplate)
@@ -286,16 +349,21 @@ func (in *ACMEChallengeSolverHTTP01IngressPodSpec) DeepCopyInto(out *ACMEChallen
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -370,6 +438,11 @@ func (in *ACMEIssuer) DeepCopyInto(out *ACMEIssuer) {
 		**out = **in
 	}
 	out.PrivateKey = in.PrivateKey
+	if in.PreviousPrivateKey != nil {
+		in, out := &in.PreviousPrivateKey, &out.PreviousPrivateKey
+		*out = new(metav1.SecretKeySelector)
+		**out = **in
+	}
 	if in.Solvers != nil {
 		in, out := &in.Solvers, &out.Solvers
 		*out = make([]ACMEChallengeSolver, len(*in))
@@ -377,6 +450,11 @@ func (in *ACMEIssuer) DeepCopyInto(out *ACMEIssuer) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MaxChallengeAttempts != nil {
+		in, out := &in.MaxChallengeAttempts, &out.MaxChallengeAttempts
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -434,6 +512,11 @@ func (in *ACMEIssuerDNS01ProviderAzureDNS) DeepCopyInto(out *ACMEIssuerDNS01Prov
 		*out = new(metav1.SecretKeySelector)
 		**out = **in
 	}
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(AzureWorkloadIdentity)
+		**out = **in
+	}
 	return
 }
 
@@ -553,6 +636,11 @@ func (in *ACMEIssuerDNS01ProviderWebhook) DeepCopyInto(out *ACMEIssuerDNS01Provi
 		*out = new(v1beta1.JSON)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ACMEIssuerDNS01ProviderWebhookTLS)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -566,9 +654,35 @@ func (in *ACMEIssuerDNS01ProviderWebhook) DeepCopy() *ACMEIssuerDNS01ProviderWeb
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ACMEIssuerDNS01ProviderWebhookTLS) DeepCopyInto(out *ACMEIssuerDNS01ProviderWebhookTLS) {
+	*out = *in
+	out.ClientCertSecretRef = in.ClientCertSecretRef
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ACMEIssuerDNS01ProviderWebhookTLS.
+func (in *ACMEIssuerDNS01ProviderWebhookTLS) DeepCopy() *ACMEIssuerDNS01ProviderWebhookTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ACMEIssuerDNS01ProviderWebhookTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ACMEIssuerStatus) DeepCopyInto(out *ACMEIssuerStatus) {
 	*out = *in
+	if in.RateLimitedUntil != nil {
+		in, out := &in.RateLimitedUntil, &out.RateLimitedUntil
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -582,6 +696,22 @@ func (in *ACMEIssuerStatus) DeepCopy() *ACMEIssuerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureWorkloadIdentity) DeepCopyInto(out *AzureWorkloadIdentity) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureWorkloadIdentity.
+func (in *AzureWorkloadIdentity) DeepCopy() *AzureWorkloadIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureWorkloadIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateDNSNameSelector) DeepCopyInto(out *CertificateDNSNameSelector) {
 	*out = *in
@@ -18,7 +18,9 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 )
@@ -47,8 +49,9 @@ type ACMEIssuer struct {
 	// endpoint.
 	// For example, for Let's Encrypt's DST crosssign you would use:
 	// "DST Root CA X3" or "ISRG Root X1" for the newer Let's Encrypt root CA.
-	// This value picks the first certificate bundle in the ACME alternative
-	// chains that has a certificate with this value as its issuer's CN
+	// This value picks the first certificate bundle offered by the ACME
+	// server, checking the default bundle before any alternative chains,
+	// whose last certificate has this value as its issuer's CN.
 	// +optional
 	// +kubebuilder:validation:MaxLength=64
 	PreferredChain string `json:"preferredChain"`
@@ -63,6 +66,12 @@ type ACMEIssuer struct {
 	// +optional
 	SkipTLSVerify bool `json:"skipTLSVerify,omitempty"`
 
+	// UserAgent, if set, is sent as the User-Agent header of every request
+	// made to the ACME server instead of the default cert-manager user agent,
+	// e.g. so that requests are allowed by a proxy that filters on it.
+	// +optional
+	UserAgent string `json:"userAgent,omitempty"`
+
 	// ExternalAccountBinding is a reference to a CA external account of the ACME
 	// server.
 	// If set, upon registration cert-manager will attempt to associate the given
@@ -77,6 +86,19 @@ type ACMEIssuer struct {
 	// If `key` is not specified, a default of `tls.key` will be used.
 	PrivateKey cmmeta.SecretKeySelector `json:"privateKeySecretRef"`
 
+	// PreviousPrivateKey is a reference to a Secret containing the private key
+	// that the ACME account referenced by `status.acme.uri` is currently
+	// registered with.
+	// If set, and the key in `privateKeySecretRef` is not yet associated with
+	// an ACME account, the existing account is rolled over to the key in
+	// `privateKeySecretRef` using the ACME key change flow, retaining the
+	// account URL. This field may be removed once the rollover has completed.
+	// Optionally, a `key` may be specified to select a specific entry within
+	// the named Secret resource.
+	// If `key` is not specified, a default of `tls.key` will be used.
+	// +optional
+	PreviousPrivateKey *cmmeta.SecretKeySelector `json:"previousPrivateKeySecretRef,omitempty"`
+
 	// Solvers is a list of challenge solvers that will be used to solve
 	// ACME challenges for the matching domains.
 	// Solver configurations must be provided in order to obtain certificates
@@ -101,6 +123,17 @@ type ACMEIssuer struct {
 	// Defaults to false.
 	// +optional
 	EnableDurationFeature bool `json:"enableDurationFeature,omitempty"`
+
+	// MaxChallengeAttempts is the number of times presenting a challenge, or
+	// accepting it with the ACME server, may fail before the Challenge and
+	// the Order it belongs to are marked as failed. Failed propagation self
+	// checks are not counted, as they are expected whilst waiting for the
+	// challenge to propagate. The count is kept per Challenge, so it starts
+	// again for every new Order.
+	// If not set, failed attempts are retried indefinitely.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxChallengeAttempts *int32 `json:"maxChallengeAttempts,omitempty"`
 }
 
 // ACMEExternalAccountBinding is a reference to a CA external account of the ACME
@@ -204,6 +237,14 @@ type ACMEChallengeSolverHTTP01 struct {
 	// provisioned by cert-manager for each Challenge to be completed.
 	// +optional
 	Ingress *ACMEChallengeSolverHTTP01Ingress `json:"ingress,omitempty"`
+
+	// The Gateway API HTTP01 challenge solver will solve challenges by
+	// creating HTTPRoute resources attached to an existing Gateway in order
+	// to route requests for '/.well-known/acme-challenge/XYZ' to 'challenge
+	// solver' pods that are provisioned by cert-manager for each Challenge
+	// to be completed. Requires the ExperimentalGatewayAPISupport feature gate.
+	// +optional
+	GatewayHTTPRoute *ACMEChallengeSolverHTTP01GatewayHTTPRoute `json:"gatewayHTTPRoute,omitempty"`
 }
 
 type ACMEChallengeSolverHTTP01Ingress struct {
@@ -213,10 +254,26 @@ type ACMEChallengeSolverHTTP01Ingress struct {
 
 	// The ingress class to use when creating Ingress resources to solve ACME
 	// challenges that use this challenge solver.
-	// Only one of 'class' or 'name' may be specified.
+	// Only one of 'class', 'ingressClassName' or 'name' may be specified.
 	// +optional
 	Class *string `json:"class,omitempty"`
 
+	// The name of the IngressClass to set in the spec.ingressClassName field
+	// of Ingress resources created to solve ACME challenges that use this
+	// challenge solver. Unlike 'class', which sets the deprecated
+	// kubernetes.io/ingress.class annotation, this field is understood by
+	// ingress controllers that only honour the IngressClass API.
+	// Only one of 'class', 'ingressClassName' or 'name' may be specified.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// The path type to set on the HTTP01 challenge path added to solver
+	// Ingress resources. Defaults to the ingress controller's behaviour for
+	// paths with no type set if not specified.
+	// +kubebuilder:validation:Enum=Exact;Prefix;ImplementationSpecific
+	// +optional
+	PathType *networkingv1.PathType `json:"pathType,omitempty"`
+
 	// The name of the ingress resource that should have ACME challenge solving
 	// routes inserted into it in order to solve HTTP01 challenges.
 	// This is typically used in conjunction with ingress controllers like
@@ -236,6 +293,36 @@ type ACMEChallengeSolverHTTP01Ingress struct {
 	IngressTemplate *ACMEChallengeSolverHTTP01IngressTemplate `json:"ingressTemplate,omitempty"`
 }
 
+// The ACMEChallengeSolverHTTP01GatewayHTTPRoute solver will create HTTPRoute
+// resources attached to a Gateway that route requests to an ACME challenge
+// solver pod.
+type ACMEChallengeSolverHTTP01GatewayHTTPRoute struct {
+	// Optional service type for Kubernetes solver service
+	// +optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// Labels that should be added to the HTTPRoute created to solve the
+	// challenge. These should match the route selector of a listener on
+	// the referenced Gateway.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// The Gateway that the HTTPRoute created to solve the challenge should
+	// be attached to.
+	GatewayRef ACMEChallengeSolverHTTP01GatewayReference `json:"gatewayRef"`
+}
+
+// ACMEChallengeSolverHTTP01GatewayReference identifies a Gateway resource.
+type ACMEChallengeSolverHTTP01GatewayReference struct {
+	// Name of the Gateway.
+	Name string `json:"name"`
+
+	// Namespace of the Gateway. If not specified, the namespace of the
+	// Challenge is used.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
 type ACMEChallengeSolverHTTP01IngressPodTemplate struct {
 	// ObjectMeta overrides for the pod used to solve HTTP01 challenges.
 	// Only the 'labels' and 'annotations' fields may be set.
@@ -284,6 +371,12 @@ type ACMEChallengeSolverHTTP01IngressPodSpec struct {
 	// If specified, the pod's service account
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// If specified, the resource requirements of the solver container.
+	// These override the default requests and limits configured on the
+	// cert-manager controller.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
 type ACMEChallengeSolverHTTP01IngressTemplate struct {
@@ -352,6 +445,18 @@ type ACMEChallengeSolverDNS01 struct {
 	// DNS01 challenge records.
 	// +optional
 	Webhook *ACMEIssuerDNS01ProviderWebhook `json:"webhook,omitempty"`
+
+	// AdditionalProviders is a list of further DNS01 providers that the
+	// challenge record is also presented to and cleaned up from, for zones
+	// that are served by more than one independent DNS provider. Each entry
+	// must configure a single provider, and may not itself have additional
+	// providers. When set, the propagation self-check always queries every
+	// authoritative nameserver for the zone, so that it only passes once the
+	// record is served by all of the providers.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	AdditionalProviders []ACMEChallengeSolverDNS01 `json:"additionalProviders,omitempty"`
 }
 
 // CNAMEStrategy configures how the DNS01 provider should handle CNAME records
@@ -389,6 +494,13 @@ type ACMEIssuerDNS01ProviderCloudDNS struct {
 	ServiceAccount *cmmeta.SecretKeySelector `json:"serviceAccountSecretRef,omitempty"`
 	Project        string                    `json:"project"`
 
+	// ServiceAccountFile is the path of a file mounted into the cert-manager
+	// controller that contains the service account key, for use instead of
+	// serviceAccountSecretRef when credentials are not stored in a Secret.
+	// Files are only read for issuers that may use ambient credentials.
+	// +optional
+	ServiceAccountFile string `json:"serviceAccountFile,omitempty"`
+
 	// HostedZoneName is an optional field that tells cert-manager in which
 	// Cloud DNS zone the challenge record has to be created.
 	// If left empty cert-manager will automatically choose a zone.
@@ -434,11 +546,25 @@ type ACMEIssuerDNS01ProviderRoute53 struct {
 	// +optional
 	SecretAccessKey cmmeta.SecretKeySelector `json:"secretAccessKeySecretRef"`
 
+	// SecretAccessKeyFile is the path of a file mounted into the cert-manager
+	// controller that contains the SecretAccessKey, for use instead of
+	// secretAccessKeySecretRef when credentials are not stored in a Secret.
+	// Files are only read for issuers that may use ambient credentials.
+	// +optional
+	SecretAccessKeyFile string `json:"secretAccessKeyFile,omitempty"`
+
 	// Role is a Role ARN which the Route53 provider will assume using either the explicit credentials AccessKeyID/SecretAccessKey
 	// or the inferred credentials from environment variables, shared credentials file or AWS Instance metadata
 	// +optional
 	Role string `json:"role,omitempty"`
 
+	// ExternalID is the external ID passed to STS when assuming Role, as
+	// required by roles whose trust policy is conditional on one. Different
+	// roles may be used for different Certificates by configuring a solver
+	// per role and selecting between them with the solver's selector.
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
+
 	// If set, the provider will manage only this zone in Route53 and will not do an lookup using the route53:ListHostedZonesByName api call.
 	// +optional
 	HostedZoneID string `json:"hostedZoneID,omitempty"`
@@ -458,6 +584,13 @@ type ACMEIssuerDNS01ProviderAzureDNS struct {
 	// +optional
 	ClientSecret *cmmeta.SecretKeySelector `json:"clientSecretSecretRef,omitempty"`
 
+	// ClientSecretFile is the path of a file mounted into the cert-manager
+	// controller that contains the client secret, for use instead of
+	// clientSecretSecretRef when credentials are not stored in a Secret.
+	// Files are only read for issuers that may use ambient credentials.
+	// +optional
+	ClientSecretFile string `json:"clientSecretFile,omitempty"`
+
 	SubscriptionID string `json:"subscriptionID"`
 
 	// when specifying ClientID and ClientSecret then this field is also needed
@@ -471,8 +604,24 @@ type ACMEIssuerDNS01ProviderAzureDNS struct {
 
 	// +optional
 	Environment AzureDNSEnvironment `json:"environment,omitempty"`
+
+	// WorkloadIdentity, if set, authenticates with Azure using Azure
+	// Workload Identity: the federated token in cert-manager's projected
+	// service account token file is exchanged for an access token, instead of
+	// using a client secret or managed identity. The token file is configured
+	// on the controller, not on the issuer. ClientID and TenantID default to
+	// the AZURE_CLIENT_ID and AZURE_TENANT_ID environment variables if not set.
+	// +optional
+	WorkloadIdentity *AzureWorkloadIdentity `json:"workloadIdentity,omitempty"`
 }
 
+// AzureWorkloadIdentity configures authentication with Azure using a
+// federated service account token. The path of the token file is set with the
+// controller's --dns01-azure-federated-token-file flag, and defaults to the
+// AZURE_FEDERATED_TOKEN_FILE environment variable set by the Azure Workload
+// Identity webhook.
+type AzureWorkloadIdentity struct{}
+
 // +kubebuilder:validation:Enum=AzurePublicCloud;AzureChinaCloud;AzureGermanCloud;AzureUSGovernmentCloud
 type AzureDNSEnvironment string
 
@@ -542,6 +691,36 @@ type ACMEIssuerDNS01ProviderWebhook struct {
 	// implementation's documentation.
 	// +optional
 	Config *apiext.JSON `json:"config,omitempty"`
+
+	// TLS configures requests to be sent directly to the webhook solver over
+	// a mutual TLS connection, bypassing the Kubernetes apiserver.
+	// If not specified, requests are sent through the Kubernetes apiserver
+	// aggregation layer using the credentials of the cert-manager controller.
+	// +optional
+	TLS *ACMEIssuerDNS01ProviderWebhookTLS `json:"tls,omitempty"`
+}
+
+// ACMEIssuerDNS01ProviderWebhookTLS configures a direct mutual TLS connection
+// to a webhook DNS01 provider.
+type ACMEIssuerDNS01ProviderWebhookTLS struct {
+	// URL is the https base URL of the webhook solver's apiserver, for
+	// example `https://my-solver.my-namespace.svc:443`.
+	// When TLS is configured, requests are sent directly to this URL instead
+	// of through the Kubernetes apiserver aggregation layer, so that the
+	// client certificate is presented to the webhook solver itself.
+	URL string `json:"url"`
+
+	// ClientCertSecretRef is a reference to a Secret in the issuer's resource
+	// namespace containing the client certificate and private key that will
+	// be presented to the webhook solver, stored under the `tls.crt` and
+	// `tls.key` keys.
+	ClientCertSecretRef cmmeta.LocalObjectReference `json:"clientCertSecretRef"`
+
+	// CABundle is a PEM encoded bundle of CA certificates used to verify the
+	// serving certificate of the webhook solver.
+	// If not specified, the system trust roots are used.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
 }
 
 type ACMEIssuerStatus struct {
@@ -555,4 +734,15 @@ type ACMEIssuerStatus struct {
 	// associated with the  Issuer
 	// +optional
 	LastRegisteredEmail string `json:"lastRegisteredEmail,omitempty"`
+
+	// LastRateLimitError is the detail of the most recent rate limit error
+	// returned by the ACME server when creating an order for this issuer.
+	// +optional
+	LastRateLimitError string `json:"lastRateLimitError,omitempty"`
+
+	// RateLimitedUntil is the time until which no new orders will be created
+	// for this issuer, as the ACME server has rate limited it. It is set from
+	// the Retry-After header of the rate limit error, if present.
+	// +optional
+	RateLimitedUntil *metav1.Time `json:"rateLimitedUntil,omitempty"`
 }
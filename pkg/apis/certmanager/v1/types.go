@@ -48,9 +48,26 @@ const (
 	// Annotation key for the 'group' of the Issuer resource.
 	IssuerGroupAnnotationKey = "cert-manager.io/issuer-group"
 
+	// Annotation key set on a Namespace to name the Issuer that Certificates
+	// created in that Namespace without an issuerRef should reference.
+	DefaultIssuerNameAnnotationKey = "cert-manager.io/default-issuer"
+
+	// Annotation key set on a Namespace for the 'kind' of the default Issuer.
+	DefaultIssuerKindAnnotationKey = "cert-manager.io/default-issuer-kind"
+
+	// Annotation key set on a Namespace for the 'group' of the default Issuer.
+	DefaultIssuerGroupAnnotationKey = "cert-manager.io/default-issuer-group"
+
 	// Annotation key for the name of the certificate that a resource is related to.
 	CertificateNameKey = "cert-manager.io/certificate-name"
 
+	// Annotation keys set on a Certificate's Secret to record the data keys
+	// that the private key, certificate and CA are stored under, if they are
+	// not the default keys because the Certificate sets spec.secretKeys.
+	SecretPrivateKeyKeyAnnotationKey  = "cert-manager.io/private-key-secret-key"
+	SecretCertificateKeyAnnotationKey = "cert-manager.io/certificate-secret-key"
+	SecretCAKeyAnnotationKey          = "cert-manager.io/ca-secret-key"
+
 	// Annotation key used to denote whether a Secret is named on a Certificate
 	// as a 'next private key' Secret resource.
 	IsNextPrivateKeySecretLabelKey = "cert-manager.io/next-private-key"
@@ -85,6 +102,49 @@ const (
 
 	// Annotation to declare the CertificateRequest "revision", belonging to a Certificate Resource
 	CertificateRequestRevisionAnnotationKey = "cert-manager.io/certificate-revision"
+
+	// Annotation to record the generation of the Certificate resource that a
+	// CertificateRequest was created for
+	CertificateRequestCertificateGenerationAnnotationKey = "cert-manager.io/certificate-generation"
+
+	// Annotation to record the generation of the issuer that a
+	// CertificateRequest was created for
+	CertificateRequestIssuerGenerationAnnotationKey = "cert-manager.io/issuer-generation"
+
+	// Annotation to record the UID of the issuer that a CertificateRequest
+	// was created for
+	CertificateRequestIssuerUIDAnnotationKey = "cert-manager.io/issuer-uid"
+
+	// Annotation to record the renewBefore of the Certificate resource that a
+	// CertificateRequest was created for
+	CertificateRequestRenewBeforeAnnotationKey = "cert-manager.io/renew-before"
+
+	// TraceContextAnnotationKey is an annotation used to propagate the
+	// OpenTelemetry trace context of an issuance from the controller that
+	// creates a CertificateRequest to the controllers that act on it. Its
+	// value is a W3C traceparent header.
+	TraceContextAnnotationKey = "cert-manager.io/trace-context"
+
+	// RequesterUsernameAnnotationKey is the default annotation that a trusted
+	// delegator, configured with the webhook's `--trusted-request-delegators`
+	// flag, can add to the CertificateRequests it creates to attribute them to
+	// the user they were created on behalf of. The webhook records the value
+	// in the `spec.extra` field under the same key, alongside the identity of
+	// the delegator. The annotation is ignored on CertificateRequests created
+	// by any other user, and is never copied from a Certificate to its
+	// CertificateRequests.
+	RequesterUsernameAnnotationKey = "cert-manager.io/requester-username"
+
+	// RequesterGroupsAnnotationKey is the default annotation holding a comma
+	// separated list of the groups of the user a CertificateRequest was
+	// created on behalf of. It is recorded in `spec.extra` in the same way as
+	// RequesterUsernameAnnotationKey.
+	RequesterGroupsAnnotationKey = "cert-manager.io/requester-groups"
+
+	// CertificateRequestStagingAnnotationKey is set to "true" on
+	// CertificateRequests created for the staging issuer of a Certificate.
+	// The certificates issued for these requests are never stored.
+	CertificateRequestStagingAnnotationKey = "cert-manager.io/staging-request"
 )
 
 const (
@@ -94,6 +154,92 @@ const (
 	// stored in the target Secret resource whilst the real Issuer is processing
 	// the certificate request.
 	IssueTemporaryCertificateAnnotation = "cert-manager.io/issue-temporary-certificate"
+
+	// SecretWriterAnnotationKey is an annotation that can be added to
+	// Certificate resources to name a registered secret writer. If present,
+	// the issued private key, certificate and CA data will also be handed to
+	// that writer, e.g. to store it in an external secret store, in addition
+	// to the `spec.secretName` Secret resource.
+	SecretWriterAnnotationKey = "cert-manager.io/secret-writer"
+
+	// ImmutableSecretAnnotationKey is an annotation that can be added to
+	// Certificate resources. If set to "true", the `spec.secretName` Secret
+	// resource will be marked as immutable. Immutable Secrets are deleted and
+	// recreated rather than updated when the Certificate is renewed.
+	ImmutableSecretAnnotationKey = "cert-manager.io/immutable-secret"
+
+	// PEMLineEndingsAnnotationKey is an annotation that can be added to
+	// Certificate resources to choose the line endings of the PEM encoded
+	// data stored in the `spec.secretName` Secret resource. One of "LF", the
+	// default, or "CRLF" for consumers that require Windows line endings.
+	PEMLineEndingsAnnotationKey = "cert-manager.io/pem-line-endings"
+
+	// IntermediatesSecretNameAnnotationKey is an annotation that can be added
+	// to Certificate resources to name a Secret resource, other than
+	// `spec.secretName`, that the intermediate certificates of the issued
+	// chain are also stored in, for consumers that load the leaf certificate
+	// and its intermediates from separate Secrets. The Secret is created if
+	// it does not exist, kept up to date with the Certificate's Secret, and
+	// the intermediates are removed from it, deleting it if nothing else is
+	// left in it, once the annotation is removed.
+	IntermediatesSecretNameAnnotationKey = "cert-manager.io/intermediates-secret-name"
+
+	// IntermediatesSecretKeyAnnotationKey is an annotation that can be added
+	// to Certificate resources to choose the data key that the intermediate
+	// certificates are stored under in the Secret named by the
+	// IntermediatesSecretNameAnnotationKey annotation. Defaults to
+	// "chain.pem". It is also set on that Secret to record the key, so that
+	// the intermediates can be removed from it once the Certificate no longer
+	// names it.
+	IntermediatesSecretKeyAnnotationKey = "cert-manager.io/intermediates-secret-key"
+
+	// StagingIssuerNameAnnotationKey is an annotation that can be added to
+	// Certificate resources, or to the Issuer or ClusterIssuer they
+	// reference, to name an issuer of the same kind and group, such as an
+	// ACME issuer using the Let's Encrypt staging directory. Each certificate
+	// is first requested from the staging issuer, and only once it has been
+	// issued there is it requested from `spec.issuerRef` and stored. The
+	// annotation on a Certificate takes precedence over that on its issuer.
+	StagingIssuerNameAnnotationKey = "cert-manager.io/staging-issuer-name"
+)
+
+const (
+	// CustomExtensionAnnotationKeyPrefix is the prefix of annotations that can
+	// be added to Certificate and CertificateRequest resources to request a
+	// custom X.509 extension. The remainder of the annotation key is the
+	// dotted-decimal OID of the extension, and the value is the base64
+	// encoded DER value of the extension, e.g.
+	// `cert-manager.io/extension-1.3.6.1.4.1.311.21.7: MAoGCCsGAQUFBwMB`.
+	// The extension must be permitted by the Issuer's allowedCustomExtensions.
+	CustomExtensionAnnotationKeyPrefix = "cert-manager.io/extension-"
+
+	// CriticalExtensionsAnnotationKey is an annotation containing a comma
+	// separated list of OIDs of custom extensions that should be marked as
+	// critical.
+	CriticalExtensionsAnnotationKey = "cert-manager.io/critical-extensions"
+
+	// SubjectAltNamesCriticalAnnotationKey is an annotation that can be added
+	// to CertificateRequest resources. If set to "true", the subjectAltName
+	// extension of the issued certificate is marked as critical even if the
+	// certificate has a non-empty subject. The extension is always marked as
+	// critical if the subject is empty, as required by RFC 5280.
+	SubjectAltNamesCriticalAnnotationKey = "cert-manager.io/critical-subject-alt-names"
+
+	// OCSPNoCheckAnnotationKey is an annotation that can be added to
+	// Certificate and CertificateRequest resources. If set to "true", the
+	// issued certificate carries the id-pkix-ocsp-nocheck extension defined in
+	// RFC 6960, section 4.2.2.2.1, so that OCSP clients do not check the
+	// revocation status of the OCSP responder certificate. It may only be set
+	// if the "ocsp signing" usage is requested.
+	OCSPNoCheckAnnotationKey = "cert-manager.io/ocsp-no-check"
+
+	// CommonNameFromDNSNamesAnnotationKey is an annotation that can be added
+	// to Certificate resources. If set to "true" and `spec.commonName` is not
+	// set, the first of `spec.dnsNames` is used as the Common Name of the
+	// requested certificate. If that name is longer than the 64 characters
+	// permitted in a Common Name, the Common Name is omitted instead and the
+	// name is only carried by the subjectAltName extension.
+	CommonNameFromDNSNamesAnnotationKey = "cert-manager.io/common-name-from-dns-names"
 )
 
 // Common/known resource kinds.
@@ -70,8 +70,8 @@ type PrivateKeyEncoding string
 const (
 	// PKCS1 key encoding will produce PEM files that include the type of
 	// private key as part of the PEM header, e.g. `BEGIN RSA PRIVATE KEY`.
-	// If the keyAlgorithm is set to 'ECDSA', this will produce private keys
-	// that use the `BEGIN EC PRIVATE KEY` header.
+	// It only applies to RSA private keys. If the keyAlgorithm is set to
+	// 'ECDSA', the private key is always encoded using PKCS8.
 	PKCS1 PrivateKeyEncoding = "PKCS1"
 
 	// PKCS8 key encoding will produce PEM files with the `BEGIN PRIVATE KEY`
@@ -80,6 +80,29 @@ const (
 	PKCS8 PrivateKeyEncoding = "PKCS8"
 )
 
+// +kubebuilder:validation:Enum=SHA256WithRSA;SHA384WithRSA;SHA512WithRSA;ECDSAWithSHA256;ECDSAWithSHA384;ECDSAWithSHA512
+type SignatureAlgorithm string
+
+const (
+	// Denotes an RSA PKCS #1 v1.5 signature using SHA-256.
+	SHA256WithRSASignatureAlgorithm SignatureAlgorithm = "SHA256WithRSA"
+
+	// Denotes an RSA PKCS #1 v1.5 signature using SHA-384.
+	SHA384WithRSASignatureAlgorithm SignatureAlgorithm = "SHA384WithRSA"
+
+	// Denotes an RSA PKCS #1 v1.5 signature using SHA-512.
+	SHA512WithRSASignatureAlgorithm SignatureAlgorithm = "SHA512WithRSA"
+
+	// Denotes an ECDSA signature using SHA-256.
+	ECDSAWithSHA256SignatureAlgorithm SignatureAlgorithm = "ECDSAWithSHA256"
+
+	// Denotes an ECDSA signature using SHA-384.
+	ECDSAWithSHA384SignatureAlgorithm SignatureAlgorithm = "ECDSAWithSHA384"
+
+	// Denotes an ECDSA signature using SHA-512.
+	ECDSAWithSHA512SignatureAlgorithm SignatureAlgorithm = "ECDSAWithSHA512"
+)
+
 // CertificateSpec defines the desired state of Certificate.
 // A valid Certificate requires at least one of a CommonName, DNSName, or
 // URISAN to be valid.
@@ -96,6 +119,14 @@ type CertificateSpec struct {
 	// +optional
 	CommonName string `json:"commonName,omitempty"`
 
+	// ExcludeCommonNameFromSANs, if true, omits the common name from the
+	// subject alt names requested for the Certificate, for CAs that do not
+	// permit the common name to be duplicated in the subject alt names. The
+	// common name is removed from the requested dnsNames and ipAddresses,
+	// including when it is taken from the first DNS name.
+	// +optional
+	ExcludeCommonNameFromSANs bool `json:"excludeCommonNameFromSANs,omitempty"`
+
 	// The requested 'duration' (i.e. lifetime) of the Certificate. This option
 	// may be ignored/overridden by some issuer types. If unset this defaults to
 	// 90 days. Certificate will be renewed either 2/3 through its duration or
@@ -105,6 +136,16 @@ type CertificateSpec struct {
 	// +optional
 	Duration *metav1.Duration `json:"duration,omitempty"`
 
+	// The requested 'notBefore' time of the Certificate. If set, the
+	// certificate will be valid from this time until `notBefore` plus
+	// `duration`. If unset this defaults to the time of issuance. This option
+	// may be ignored/overridden by some issuer types. Must be no more than 1
+	// year in the past or future.
+	// Only the first issuance of the Certificate uses this time, renewals are
+	// valid from the time they are issued.
+	// +optional
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
 	// The amount of time before the currently issued certificate's `notAfter`
 	// time that cert-manager will begin to attempt to renew the certificate. If
 	// unset this defaults to 30 days. Certificate will be renewed either 2/3
@@ -115,6 +156,32 @@ type CertificateSpec struct {
 	// +optional
 	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
 
+	// The maximum amount of time that cert-manager will wait for a
+	// CertificateRequest to complete before the Certificate is marked as
+	// failed. Issuance will then be retried following the normal back-off
+	// for failed Certificates. If unset, cert-manager will wait indefinitely.
+	// Value must be in units accepted by Go time.ParseDuration
+	// https://golang.org/pkg/time/#ParseDuration
+	// +optional
+	IssuanceTimeout *metav1.Duration `json:"issuanceTimeout,omitempty"`
+
+	// RenewalWindow, if set, restricts the renewal of the Certificate to the
+	// given times of day. A renewal that becomes due outside of the window is
+	// deferred until the window next opens, unless the certificate would
+	// expire within an hour of that time. Re-issuances for other reasons,
+	// such as a change to the Certificate's spec, are never deferred.
+	// +optional
+	RenewalWindow *CertificateRenewalWindow `json:"renewalWindow,omitempty"`
+
+	// TrustAnchors references a key of a Secret in the Certificate's
+	// namespace containing one or more PEM encoded CA certificates. If set,
+	// the Certificate is only considered Ready if the issued certificate,
+	// along with any intermediates stored with it, chains to one of these
+	// certificates. This catches misconfigured issuers. If `key` is not
+	// specified, the `ca.crt` key is used.
+	// +optional
+	TrustAnchors *cmmeta.SecretKeySelector `json:"trustAnchors,omitempty"`
+
 	// DNSNames is a list of DNS subjectAltNames to be set on the Certificate.
 	// +optional
 	DNSNames []string `json:"dnsNames,omitempty"`
@@ -131,30 +198,79 @@ type CertificateSpec struct {
 	// +optional
 	EmailAddresses []string `json:"emailAddresses,omitempty"`
 
+	// OtherNames is a list of otherName subjectAltNames to be set on the
+	// Certificate, e.g. the userPrincipalName (OID 1.3.6.1.4.1.311.20.2.3)
+	// required for Windows smartcard logon.
+	// +optional
+	OtherNames []OtherName `json:"otherNames,omitempty"`
+
 	// SecretName is the name of the secret resource that will be automatically
 	// created and managed by this Certificate resource.
 	// It will be populated with a private key and certificate, signed by the
 	// denoted issuer.
-	SecretName string `json:"secretName"`
+	// If not specified, it defaults to the name of the Certificate.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// SecretType is the type of the Secret resource that will be created and
+	// managed by this Certificate resource, either `kubernetes.io/tls` or
+	// `Opaque`. Defaults to `kubernetes.io/tls`. Changing the type of an
+	// existing Secret causes it to be deleted and recreated.
+	// +optional
+	// +kubebuilder:validation:Enum=kubernetes.io/tls;Opaque
+	SecretType string `json:"secretType,omitempty"`
+
+	// SecretKeys configures the names of the keys in the Secret resource that
+	// the private key, certificate and CA are stored under, for consumers
+	// that expect names other than `tls.key`, `tls.crt` and `ca.crt`.
+	// As Secrets of type `kubernetes.io/tls` must store the private key and
+	// certificate under `tls.key` and `tls.crt`, the Secret is created with
+	// the `Opaque` type when other names are configured for them, unless
+	// secretType is set.
+	// +optional
+	SecretKeys *CertificateSecretKeys `json:"secretKeys,omitempty"`
 
 	// Keystores configures additional keystore output formats stored in the
 	// `secretName` Secret resource.
 	// +optional
 	Keystores *CertificateKeystores `json:"keystores,omitempty"`
 
+	// AdditionalOutputFormats defines extra output formats of the signed
+	// certificate chain to be written to the `secretName` Secret resource.
+	// +optional
+	AdditionalOutputFormats []CertificateAdditionalOutputFormat `json:"additionalOutputFormats,omitempty"`
+
 	// IssuerRef is a reference to the issuer for this certificate.
 	// If the `kind` field is not set, or set to `Issuer`, an Issuer resource
 	// with the given name in the same namespace as the Certificate will be used.
 	// If the `kind` field is set to `ClusterIssuer`, a ClusterIssuer with the
 	// provided name will be used.
 	// The `name` field in this stanza is required at all times.
+	// If not specified, the Issuer named by the `cert-manager.io/default-issuer`
+	// annotation on the Certificate's Namespace is used.
+	// +optional
 	IssuerRef cmmeta.ObjectReference `json:"issuerRef"`
 
+	// IssuerRefs is an ordered list of fallback issuers for this certificate.
+	// If issuance using `issuerRef` fails, each of these issuers will be
+	// attempted in turn. The Certificate will only fail to issue once all
+	// issuers have failed.
+	// +optional
+	IssuerRefs []cmmeta.ObjectReference `json:"issuerRefs,omitempty"`
+
 	// IsCA will mark this Certificate as valid for certificate signing.
 	// This will automatically add the `cert sign` usage to the list of `usages`.
 	// +optional
 	IsCA bool `json:"isCA,omitempty"`
 
+	// MaxPathLen is the maximum number of intermediate CA certificates that
+	// may follow this certificate in a valid certification path. It is
+	// encoded in the pathLenConstraint of the BasicConstraints extension and
+	// may only be set if isCA is true. If not set, no path length constraint
+	// will be added.
+	// +optional
+	MaxPathLen *int32 `json:"maxPathLen,omitempty"`
+
 	// Usages is the set of x509 usages that are requested for the certificate.
 	// Defaults to `digital signature` and `key encipherment` if not specified.
 	// +optional
@@ -164,11 +280,41 @@ type CertificateSpec struct {
 	// +optional
 	PrivateKey *CertificatePrivateKey `json:"privateKey,omitempty"`
 
+	// CSRSecretRef references a Secret containing a PEM encoded certificate
+	// signing request to be signed for this Certificate. If set, cert-manager
+	// will not generate or store a private key; the supplied request is
+	// submitted to the issuer as-is and only the signed certificate and CA
+	// are written to the `spec.secretName` Secret resource, which is of type
+	// `Opaque` unless `secretType` is set.
+	// The subject and subject alternative names of the issued certificate are
+	// taken from the request, so `commonName`, `subject`, `dnsNames`,
+	// `ipAddresses`, `uris`, `emailAddresses`, `privateKey` and `keystores`
+	// must not be set.
+	// If `key` is not specified, the request is read from the `tls.csr` key.
+	// +optional
+	CSRSecretRef *cmmeta.SecretKeySelector `json:"csrSecretRef,omitempty"`
+
 	// EncodeUsagesInRequest controls whether key usages should be present
 	// in the CertificateRequest
 	// +optional
 	EncodeUsagesInRequest *bool `json:"encodeUsagesInRequest,omitempty"`
 
+	// CSRSignatureAlgorithm is the signature algorithm used to sign the
+	// certificate signing request generated for this Certificate.
+	// If not set, it is chosen based on the private key algorithm and size.
+	// The signature algorithm must be compatible with the private key
+	// algorithm, and must not be set if `csrSecretRef` is specified.
+	// +optional
+	CSRSignatureAlgorithm SignatureAlgorithm `json:"csrSignatureAlgorithm,omitempty"`
+
+	// SignatureAlgorithm is the signature algorithm the issuer should use to
+	// sign the issued certificate, independent of the algorithm used for the
+	// issuer's own certificate. It must be compatible with the issuer's
+	// private key. If not set, the issuer chooses the signature algorithm.
+	// Currently only honored by the CA issuer.
+	// +optional
+	SignatureAlgorithm SignatureAlgorithm `json:"signatureAlgorithm,omitempty"`
+
 	// revisionHistoryLimit is the maximum number of CertificateRequest revisions
 	// that are maintained in the Certificate's history. Each revision represents
 	// a single `CertificateRequest` created by this Certificate, either when it
@@ -181,6 +327,52 @@ type CertificateSpec struct {
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"` // Validated by the validating webhook.
 }
 
+// OtherName is an otherName subject alternative name, as defined in RFC 5280,
+// section 4.2.1.6, whose value is a UTF-8 string.
+type OtherName struct {
+	// OID is the object identifier of the otherName type in dotted-decimal
+	// notation, e.g. `1.3.6.1.4.1.311.20.2.3` for a userPrincipalName.
+	OID string `json:"oid"`
+
+	// UTF8Value is the value of the otherName, which is encoded as an ASN.1
+	// UTF8String.
+	UTF8Value string `json:"utf8Value"`
+}
+
+// CertificateSecretKeys configures the names of the keys in a Certificate's
+// Secret resource that the issued private key, certificate and CA are stored
+// under.
+type CertificateSecretKeys struct {
+	// PrivateKey is the name of the key that the PEM encoded private key is
+	// stored under. Defaults to `tls.key`.
+	// +optional
+	PrivateKey string `json:"privateKey,omitempty"`
+
+	// Certificate is the name of the key that the PEM encoded certificate
+	// chain is stored under. Defaults to `tls.crt`.
+	// +optional
+	Certificate string `json:"certificate,omitempty"`
+
+	// CA is the name of the key that the PEM encoded CA certificate is stored
+	// under. Defaults to `ca.crt`.
+	// +optional
+	CA string `json:"ca,omitempty"`
+}
+
+// CertificateRenewalWindow is a set of daily time ranges during which a
+// Certificate may be renewed.
+type CertificateRenewalWindow struct {
+	// Ranges are the times of day during which the Certificate may be renewed,
+	// in the form "HH:MM-HH:MM", e.g. "22:00-06:00". A range whose end is
+	// before its start spans midnight.
+	Ranges []string `json:"ranges"`
+
+	// TimeZone is the IANA time zone name, such as "Europe/London", that the
+	// ranges are in. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
 // CertificatePrivateKey contains configuration options for private keys
 // used by the Certificate controller.
 // This allows control of how private keys are rotated.
@@ -193,6 +385,9 @@ type CertificatePrivateKey struct {
 	// to await user intervention.
 	// If set to Always, a private key matching the specified requirements
 	// will be generated whenever a re-issuance occurs.
+	// If set to External, the private key is provisioned in the target
+	// `spec.secretName` by an external controller and cert-manager will never
+	// generate or rotate it. Issuance will not proceed until the key exists.
 	// Default is 'Never' for backward compatibility.
 	// +optional
 	RotationPolicy PrivateKeyRotationPolicy `json:"rotationPolicy,omitempty"`
@@ -237,6 +432,11 @@ var (
 	// RotationPolicyAlways means a private key matching the specified
 	// requirements will be generated whenever a re-issuance occurs.
 	RotationPolicyAlways PrivateKeyRotationPolicy = "Always"
+
+	// RotationPolicyExternal means the private key is managed externally and
+	// read from the target `spec.secretName`. A private key will never be
+	// generated, and issuance will not proceed until the key exists.
+	RotationPolicyExternal PrivateKeyRotationPolicy = "External"
 )
 
 // X509Subject Full X509 name specification
@@ -373,6 +573,45 @@ type CertificateStatus struct {
 	// not set or False.
 	// +optional
 	NextPrivateKeySecretName *string `json:"nextPrivateKeySecretName,omitempty"`
+
+	// IssuanceLatency is a running average of the time taken to issue this
+	// certificate, measured from the creation of a CertificateRequest to it
+	// becoming Ready.
+	// It is used to start renewal of short-lived certificates early enough
+	// that a new certificate is issued before the current one expires.
+	// +optional
+	IssuanceLatency *metav1.Duration `json:"issuanceLatency,omitempty"`
+
+	// ActiveIssuerRef is a reference to the issuer that issued the current
+	// certificate. This will be either `spec.issuerRef` or one of
+	// `spec.issuerRefs`.
+	// This field is only set when `spec.issuerRefs` is specified.
+	// +optional
+	ActiveIssuerRef *cmmeta.ObjectReference `json:"activeIssuerRef,omitempty"`
+
+	// LastReconciledBy records the most recent actions taken on this
+	// Certificate by cert-manager's certificate controllers, oldest first.
+	// Only a bounded number of the most recent entries are retained.
+	// It is intended to aid in debugging interactions between controllers.
+	// +optional
+	LastReconciledBy []CertificateReconciliationRecord `json:"lastReconciledBy,omitempty"`
+
+	// IssuedCertificate is a summary of the certificate stored in the Secret
+	// named by `spec.secretName`, for consumption by other controllers. The
+	// validity period of the certificate is recorded in `notBefore` and
+	// `notAfter`.
+	// +optional
+	IssuedCertificate *IssuedCertificateSummary `json:"issuedCertificate,omitempty"`
+}
+
+// CertificateReconciliationRecord records a single action taken on a
+// Certificate by one of cert-manager's certificate controllers.
+type CertificateReconciliationRecord struct {
+	// Controller is the name of the controller that acted on the Certificate.
+	Controller string `json:"controller"`
+
+	// Time is the time at which the controller acted on the Certificate.
+	Time metav1.Time `json:"time"`
 }
 
 // CertificateCondition contains condition information for an Certificate.
@@ -436,4 +675,77 @@ const (
 	//
 	// It will be removed by the 'issuing' controller upon completing issuance.
 	CertificateConditionIssuing CertificateConditionType = "Issuing"
+
+	// CertificateConditionIssuerNotReady is set to True on Certificates whose
+	// issuer is not Ready, with the reason and message of the issuer's Ready
+	// condition. It is removed once the issuer is Ready again.
+	CertificateConditionIssuerNotReady CertificateConditionType = "IssuerNotReady"
+
+	// CertificateConditionStagingIssued is set to True on Certificates once
+	// their staging issuer has issued a certificate. Its message names the
+	// staging issuer and CertificateRequest, as staging requests are deleted
+	// once the certificate is requested from the Certificate's issuer.
+	CertificateConditionStagingIssued CertificateConditionType = "StagingIssued"
 )
+
+// CertificateOutputFormatType specifies an additional output format that
+// should be written to the Certificate's target Secret.
+// +kubebuilder:validation:Enum=ChainPEM
+type CertificateOutputFormatType string
+
+const (
+	// CertificateOutputFormatChainPEM writes the intermediate certificates of
+	// the signed certificate chain to the `chain.pem` key of the target
+	// Secret, excluding both the leaf and any self-signed root certificate.
+	// The key is empty if the chain only contains the leaf certificate.
+	CertificateOutputFormatChainPEM CertificateOutputFormatType = "ChainPEM"
+)
+
+// CertificateAdditionalOutputFormat defines an additional output format of a
+// Certificate resource.
+type CertificateAdditionalOutputFormat struct {
+	// Type is the name of the format type that should be written to the
+	// Certificate's target Secret.
+	Type CertificateOutputFormatType `json:"type"`
+}
+
+// IssuedCertificateSummary is a summary of an issued X.509 certificate.
+// Each list of subject alternative names contains at most 50 entries, so that
+// the size of the Certificate status remains bounded.
+type IssuedCertificateSummary struct {
+	// SerialNumber is the hex encoded serial number of the certificate.
+	SerialNumber string `json:"serialNumber"`
+
+	// Subject is the distinguished name of the subject of the certificate,
+	// formatted as described in RFC 2253.
+	// +optional
+	Subject string `json:"subject,omitempty"`
+
+	// IssuerCommonName is the common name of the issuer of the certificate.
+	// +optional
+	IssuerCommonName string `json:"issuerCommonName,omitempty"`
+
+	// DNSNames are the DNS subject alternative names of the certificate.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// IPAddresses are the IP address subject alternative names of the
+	// certificate.
+	// +optional
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+
+	// URIs are the URI subject alternative names of the certificate.
+	// +optional
+	URIs []string `json:"uris,omitempty"`
+
+	// EmailAddresses are the email address subject alternative names of the
+	// certificate.
+	// +optional
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+
+	// Truncated is true if any of the lists of subject alternative names has
+	// been truncated because the certificate has more than 50 entries of
+	// that type.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+}
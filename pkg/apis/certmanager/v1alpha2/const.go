@@ -30,6 +30,9 @@ const (
 
 	// Default duration before certificate expiration if  Issuer.spec.renewBefore is not set
 	DefaultRenewBefore = time.Hour * 24 * 30
+
+	// maximum amount of time a requested notBefore may be in the past or future
+	MaximumNotBeforeSkew = time.Hour * 24 * 365
 )
 
 const (
@@ -83,6 +83,28 @@ const (
 
 	// Annotation to declare the CertificateRequest "revision", belonging to a Certificate Resource
 	CertificateRequestRevisionAnnotationKey = "cert-manager.io/certificate-revision"
+
+	// Annotation to record the generation of the Certificate resource that a
+	// CertificateRequest was created for
+	CertificateRequestCertificateGenerationAnnotationKey = "cert-manager.io/certificate-generation"
+
+	// Annotation to record the generation of the issuer that a
+	// CertificateRequest was created for
+	CertificateRequestIssuerGenerationAnnotationKey = "cert-manager.io/issuer-generation"
+
+	// Annotation to record the UID of the issuer that a CertificateRequest
+	// was created for
+	CertificateRequestIssuerUIDAnnotationKey = "cert-manager.io/issuer-uid"
+
+	// Annotation to record the renewBefore of the Certificate resource that a
+	// CertificateRequest was created for
+	CertificateRequestRenewBeforeAnnotationKey = "cert-manager.io/renew-before"
+
+	// TraceContextAnnotationKey is an annotation used to propagate the
+	// OpenTelemetry trace context of an issuance from the controller that
+	// creates a CertificateRequest to the controllers that act on it. Its
+	// value is a W3C traceparent header.
+	TraceContextAnnotationKey = "cert-manager.io/trace-context"
 )
 
 const (
@@ -92,6 +114,44 @@ const (
 	// stored in the target Secret resource whilst the real Issuer is processing
 	// the certificate request.
 	IssueTemporaryCertificateAnnotation = "cert-manager.io/issue-temporary-certificate"
+
+	// SecretWriterAnnotationKey is an annotation that can be added to
+	// Certificate resources to name a registered secret writer. If present,
+	// the issued private key, certificate and CA data will also be handed to
+	// that writer, e.g. to store it in an external secret store, in addition
+	// to the `spec.secretName` Secret resource.
+	SecretWriterAnnotationKey = "cert-manager.io/secret-writer"
+)
+
+const (
+	// CustomExtensionAnnotationKeyPrefix is the prefix of annotations that can
+	// be added to Certificate and CertificateRequest resources to request a
+	// custom X.509 extension. The remainder of the annotation key is the
+	// dotted-decimal OID of the extension, and the value is the base64
+	// encoded DER value of the extension, e.g.
+	// `cert-manager.io/extension-1.3.6.1.4.1.311.21.7: MAoGCCsGAQUFBwMB`.
+	// The extension must be permitted by the Issuer's allowedCustomExtensions.
+	CustomExtensionAnnotationKeyPrefix = "cert-manager.io/extension-"
+
+	// CriticalExtensionsAnnotationKey is an annotation containing a comma
+	// separated list of OIDs of custom extensions that should be marked as
+	// critical.
+	CriticalExtensionsAnnotationKey = "cert-manager.io/critical-extensions"
+
+	// SubjectAltNamesCriticalAnnotationKey is an annotation that can be added
+	// to CertificateRequest resources. If set to "true", the subjectAltName
+	// extension of the issued certificate is marked as critical even if the
+	// certificate has a non-empty subject. The extension is always marked as
+	// critical if the subject is empty, as required by RFC 5280.
+	SubjectAltNamesCriticalAnnotationKey = "cert-manager.io/critical-subject-alt-names"
+
+	// CommonNameFromDNSNamesAnnotationKey is an annotation that can be added
+	// to Certificate resources. If set to "true" and `spec.commonName` is not
+	// set, the first of `spec.dnsNames` is used as the Common Name of the
+	// requested certificate. If that name is longer than the 64 characters
+	// permitted in a Common Name, the Common Name is omitted instead and the
+	// name is only carried by the subjectAltName extension.
+	CommonNameFromDNSNamesAnnotationKey = "cert-manager.io/common-name-from-dns-names"
 )
 
 // Common/known resource kinds.
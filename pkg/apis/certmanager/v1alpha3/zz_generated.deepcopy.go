@@ -40,6 +40,38 @@ func (in *CAIssuer) DeepCopyInto(out *CAIssuer) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.PolicyIdentifiers != nil {
+		in, out := &in.PolicyIdentifiers, &out.PolicyIdentifiers
+		*out = make([]CertificatePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InhibitAnyPolicy != nil {
+		in, out := &in.InhibitAnyPolicy, &out.InhibitAnyPolicy
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PolicyConstraints != nil {
+		in, out := &in.PolicyConstraints, &out.PolicyConstraints
+		*out = new(PolicyConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedCustomExtensions != nil {
+		in, out := &in.AllowedCustomExtensions, &out.AllowedCustomExtensions
+		*out = make([]CustomExtensionPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCSRExtensions != nil {
+		in, out := &in.AllowedCSRExtensions, &out.AllowedCSRExtensions
+		*out = make([]CustomExtensionPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.RotationSecretNames != nil {
+		in, out := &in.RotationSecretNames, &out.RotationSecretNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -81,6 +113,22 @@ func (in *Certificate) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateAdditionalOutputFormat) DeepCopyInto(out *CertificateAdditionalOutputFormat) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateAdditionalOutputFormat.
+func (in *CertificateAdditionalOutputFormat) DeepCopy() *CertificateAdditionalOutputFormat {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateAdditionalOutputFormat)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateCondition) DeepCopyInto(out *CertificateCondition) {
 	*out = *in
@@ -160,6 +208,27 @@ func (in *CertificateList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificatePolicy) DeepCopyInto(out *CertificatePolicy) {
+	*out = *in
+	if in.CPSURIs != nil {
+		in, out := &in.CPSURIs, &out.CPSURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificatePolicy.
+func (in *CertificatePolicy) DeepCopy() *CertificatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificatePrivateKey) DeepCopyInto(out *CertificatePrivateKey) {
 	*out = *in
@@ -176,6 +245,44 @@ func (in *CertificatePrivateKey) DeepCopy() *CertificatePrivateKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateReconciliationRecord) DeepCopyInto(out *CertificateReconciliationRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateReconciliationRecord.
+func (in *CertificateReconciliationRecord) DeepCopy() *CertificateReconciliationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateReconciliationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateRenewalWindow) DeepCopyInto(out *CertificateRenewalWindow) {
+	*out = *in
+	if in.Ranges != nil {
+		in, out := &in.Ranges, &out.Ranges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateRenewalWindow.
+func (in *CertificateRenewalWindow) DeepCopy() *CertificateRenewalWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateRenewalWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateRequest) DeepCopyInto(out *CertificateRequest) {
 	*out = *in
@@ -265,12 +372,21 @@ func (in *CertificateRequestSpec) DeepCopyInto(out *CertificateRequestSpec) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
 	out.IssuerRef = in.IssuerRef
 	if in.CSRPEM != nil {
 		in, out := &in.CSRPEM, &out.CSRPEM
 		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
+	if in.MaxPathLen != nil {
+		in, out := &in.MaxPathLen, &out.MaxPathLen
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Usages != nil {
 		in, out := &in.Usages, &out.Usages
 		*out = make([]KeyUsage, len(*in))
@@ -346,6 +462,22 @@ func (in *CertificateRequestStatus) DeepCopy() *CertificateRequestStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSecretKeys) DeepCopyInto(out *CertificateSecretKeys) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSecretKeys.
+func (in *CertificateSecretKeys) DeepCopy() *CertificateSecretKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSecretKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
 	*out = *in
@@ -359,11 +491,30 @@ func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
 	if in.RenewBefore != nil {
 		in, out := &in.RenewBefore, &out.RenewBefore
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.IssuanceTimeout != nil {
+		in, out := &in.IssuanceTimeout, &out.IssuanceTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RenewalWindow != nil {
+		in, out := &in.RenewalWindow, &out.RenewalWindow
+		*out = new(CertificateRenewalWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TrustAnchors != nil {
+		in, out := &in.TrustAnchors, &out.TrustAnchors
+		*out = new(metav1.SecretKeySelector)
+		**out = **in
+	}
 	if in.DNSNames != nil {
 		in, out := &in.DNSNames, &out.DNSNames
 		*out = make([]string, len(*in))
@@ -384,12 +535,37 @@ func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.OtherNames != nil {
+		in, out := &in.OtherNames, &out.OtherNames
+		*out = make([]OtherName, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretKeys != nil {
+		in, out := &in.SecretKeys, &out.SecretKeys
+		*out = new(CertificateSecretKeys)
+		**out = **in
+	}
 	if in.Keystores != nil {
 		in, out := &in.Keystores, &out.Keystores
 		*out = new(CertificateKeystores)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdditionalOutputFormats != nil {
+		in, out := &in.AdditionalOutputFormats, &out.AdditionalOutputFormats
+		*out = make([]CertificateAdditionalOutputFormat, len(*in))
+		copy(*out, *in)
+	}
 	out.IssuerRef = in.IssuerRef
+	if in.IssuerRefs != nil {
+		in, out := &in.IssuerRefs, &out.IssuerRefs
+		*out = make([]metav1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxPathLen != nil {
+		in, out := &in.MaxPathLen, &out.MaxPathLen
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Usages != nil {
 		in, out := &in.Usages, &out.Usages
 		*out = make([]KeyUsage, len(*in))
@@ -400,6 +576,11 @@ func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
 		*out = new(CertificatePrivateKey)
 		**out = **in
 	}
+	if in.CSRSecretRef != nil {
+		in, out := &in.CSRSecretRef, &out.CSRSecretRef
+		*out = new(metav1.SecretKeySelector)
+		**out = **in
+	}
 	if in.EncodeUsagesInRequest != nil {
 		in, out := &in.EncodeUsagesInRequest, &out.EncodeUsagesInRequest
 		*out = new(bool)
@@ -459,6 +640,28 @@ func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.IssuanceLatency != nil {
+		in, out := &in.IssuanceLatency, &out.IssuanceLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ActiveIssuerRef != nil {
+		in, out := &in.ActiveIssuerRef, &out.ActiveIssuerRef
+		*out = new(metav1.ObjectReference)
+		**out = **in
+	}
+	if in.LastReconciledBy != nil {
+		in, out := &in.LastReconciledBy, &out.LastReconciledBy
+		*out = make([]CertificateReconciliationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IssuedCertificate != nil {
+		in, out := &in.IssuedCertificate, &out.IssuedCertificate
+		*out = new(IssuedCertificateSummary)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -533,6 +736,58 @@ func (in *ClusterIssuerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomExtensionPolicy) DeepCopyInto(out *CustomExtensionPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomExtensionPolicy.
+func (in *CustomExtensionPolicy) DeepCopy() *CustomExtensionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomExtensionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuedCertificateSummary) DeepCopyInto(out *IssuedCertificateSummary) {
+	*out = *in
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.URIs != nil {
+		in, out := &in.URIs, &out.URIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EmailAddresses != nil {
+		in, out := &in.EmailAddresses, &out.EmailAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuedCertificateSummary.
+func (in *IssuedCertificateSummary) DeepCopy() *IssuedCertificateSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuedCertificateSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Issuer) DeepCopyInto(out *Issuer) {
 	*out = *in
@@ -659,6 +914,16 @@ func (in *IssuerList) DeepCopyObject() runtime.Object {
 func (in *IssuerSpec) DeepCopyInto(out *IssuerSpec) {
 	*out = *in
 	in.IssuerConfig.DeepCopyInto(&out.IssuerConfig)
+	if in.AllowedDomains != nil {
+		in, out := &in.AllowedDomains, &out.AllowedDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SPIFFE != nil {
+		in, out := &in.SPIFFE, &out.SPIFFE
+		*out = new(SPIFFEOptions)
+		**out = **in
+	}
 	return
 }
 
@@ -685,7 +950,7 @@ func (in *IssuerStatus) DeepCopyInto(out *IssuerStatus) {
 	if in.ACME != nil {
 		in, out := &in.ACME, &out.ACME
 		*out = new(acmev1alpha3.ACMEIssuerStatus)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -717,6 +982,22 @@ func (in *JKSKeystore) DeepCopy() *JKSKeystore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OtherName) DeepCopyInto(out *OtherName) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OtherName.
+func (in *OtherName) DeepCopy() *OtherName {
+	if in == nil {
+		return nil
+	}
+	out := new(OtherName)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PKCS12Keystore) DeepCopyInto(out *PKCS12Keystore) {
 	*out = *in
@@ -734,6 +1015,48 @@ func (in *PKCS12Keystore) DeepCopy() *PKCS12Keystore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyConstraints) DeepCopyInto(out *PolicyConstraints) {
+	*out = *in
+	if in.RequireExplicitPolicy != nil {
+		in, out := &in.RequireExplicitPolicy, &out.RequireExplicitPolicy
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InhibitPolicyMapping != nil {
+		in, out := &in.InhibitPolicyMapping, &out.InhibitPolicyMapping
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyConstraints.
+func (in *PolicyConstraints) DeepCopy() *PolicyConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SPIFFEOptions) DeepCopyInto(out *SPIFFEOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SPIFFEOptions.
+func (in *SPIFFEOptions) DeepCopy() *SPIFFEOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SPIFFEOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelfSignedIssuer) DeepCopyInto(out *SelfSignedIssuer) {
 	*out = *in
@@ -742,6 +1065,16 @@ func (in *SelfSignedIssuer) DeepCopyInto(out *SelfSignedIssuer) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AllowedCustomExtensions != nil {
+		in, out := &in.AllowedCustomExtensions, &out.AllowedCustomExtensions
+		*out = make([]CustomExtensionPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCSRExtensions != nil {
+		in, out := &in.AllowedCSRExtensions, &out.AllowedCSRExtensions
+		*out = make([]CustomExtensionPolicy, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -82,6 +82,12 @@ type CertificateRequestSpec struct {
 	// +optional
 	Duration *metav1.Duration `json:"duration,omitempty"`
 
+	// The requested 'notBefore' time of the Certificate. If unset this
+	// defaults to the time of issuance.
+	// This option may be ignored/overridden by some issuer types.
+	// +optional
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
 	// IssuerRef is a reference to the issuer for this CertificateRequest.  If
 	// the `kind` field is not set, or set to `Issuer`, an Issuer resource with
 	// the given name in the same namespace as the CertificateRequest will be
@@ -101,6 +107,19 @@ type CertificateRequestSpec struct {
 	// +optional
 	IsCA bool `json:"isCA,omitempty"`
 
+	// MaxPathLen is the maximum number of intermediate CA certificates that
+	// may follow the requested certificate in a valid certification path. It
+	// may only be set if isCA is true. If not set, no path length constraint
+	// will be requested.
+	// +optional
+	MaxPathLen *int32 `json:"maxPathLen,omitempty"`
+
+	// SignatureAlgorithm is the signature algorithm the issuer should use to
+	// sign the requested certificate. It must be compatible with the issuer's
+	// private key. If not set, the issuer chooses the signature algorithm.
+	// +optional
+	SignatureAlgorithm SignatureAlgorithm `json:"signatureAlgorithm,omitempty"`
+
 	// Usages is the set of x509 usages that are requested for the certificate.
 	// Defaults to `digital signature` and `key encipherment` if not specified.
 	// +optional
@@ -89,6 +89,30 @@ type IssuerList struct {
 // configuration required for the issuer.
 type IssuerSpec struct {
 	IssuerConfig `json:",inline"`
+
+	// AllowedDomains restricts the DNS names that this issuer will sign
+	// certificates for. Each entry is either a domain, which permits that
+	// domain and any of its subdomains, or a wildcard of the form
+	// "*.example.com", which permits only subdomains of example.com.
+	// CertificateRequests containing a DNS name that does not match any entry
+	// are denied.
+	// If not set, all DNS names are permitted.
+	// +optional
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+
+	// SPIFFE configures how this issuer handles SPIFFE IDs, the URI SANs
+	// with the "spiffe" scheme, requested by CertificateRequests.
+	// +optional
+	SPIFFE *SPIFFEOptions `json:"spiffe,omitempty"`
+}
+
+// SPIFFEOptions configures how an issuer handles SPIFFE IDs.
+type SPIFFEOptions struct {
+	// TrustDomain is the SPIFFE trust domain that every SPIFFE ID requested
+	// from this issuer must belong to, e.g. "example.org" for SPIFFE IDs of
+	// the form "spiffe://example.org/...". CertificateRequests containing a
+	// SPIFFE ID from a different trust domain are denied.
+	TrustDomain string `json:"trustDomain"`
 }
 
 // The configuration for the issuer.
@@ -181,6 +205,26 @@ type SelfSignedIssuer struct {
 	// If not set certificate will be issued without CDP. Values are strings.
 	// +optional
 	CRLDistributionPoints []string `json:"crlDistributionPoints,omitempty"`
+
+	// AllowedCustomExtensions is the list of custom X.509 extensions that may
+	// be requested by CertificateRequests using the
+	// `cert-manager.io/extension-<oid>` annotation.
+	// Requests for extensions that are not listed here will be rejected.
+	// If not set, no custom extensions may be requested.
+	// +optional
+	AllowedCustomExtensions []CustomExtensionPolicy `json:"allowedCustomExtensions,omitempty"`
+
+	// AllowedCSRExtensions is the list of X.509 extensions requested by the
+	// extensionRequest attribute of a CertificateRequest's CSR that will be
+	// copied to issued certificates. An extension that the CSR marks as
+	// critical is only copied if its policy sets allowCritical.
+	// Requests for extensions that are not listed here will be rejected,
+	// other than for the extensions that cert-manager derives from the
+	// CertificateRequest itself, such as subject alternative names and key
+	// usages.
+	// If not set, extensions requested by the CSR are ignored.
+	// +optional
+	AllowedCSRExtensions []CustomExtensionPolicy `json:"allowedCSRExtensions,omitempty"`
 }
 
 // Configures an issuer to sign certificates using a HashiCorp Vault
@@ -283,6 +327,155 @@ type CAIssuer struct {
 	// OCSP server URL could be "http://ocsp.int-x3.letsencrypt.org".
 	// +optional
 	OCSPServers []string `json:"ocspServers,omitempty"`
+
+	// PolicyIdentifiers is the list of certificate policies that will be
+	// included in the certificatePolicies X.509 v3 extension of certificates
+	// issued by this Issuer. If not set, certificates will be issued without
+	// a certificatePolicies extension.
+	// +optional
+	PolicyIdentifiers []CertificatePolicy `json:"policyIdentifiers,omitempty"`
+
+	// InhibitAnyPolicy, if set, adds a critical inhibitAnyPolicy X.509 v3
+	// extension to CA certificates issued by this Issuer, with the number of
+	// additional non-self-issued certificates that may appear in the path
+	// before the anyPolicy policy is no longer permitted. It is not added to
+	// certificates that are not CAs.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	InhibitAnyPolicy *int32 `json:"inhibitAnyPolicy,omitempty"`
+
+	// PolicyConstraints, if set, adds a critical policyConstraints X.509 v3
+	// extension to CA certificates issued by this Issuer. It is not added to
+	// certificates that are not CAs.
+	// +optional
+	PolicyConstraints *PolicyConstraints `json:"policyConstraints,omitempty"`
+
+	// AllowedCustomExtensions is the list of custom X.509 extensions that may
+	// be requested by CertificateRequests using the
+	// `cert-manager.io/extension-<oid>` annotation.
+	// Requests for extensions that are not listed here will be rejected.
+	// If not set, no custom extensions may be requested.
+	// +optional
+	AllowedCustomExtensions []CustomExtensionPolicy `json:"allowedCustomExtensions,omitempty"`
+
+	// AllowedCSRExtensions is the list of X.509 extensions requested by the
+	// extensionRequest attribute of a CertificateRequest's CSR that will be
+	// copied to issued certificates. An extension that the CSR marks as
+	// critical is only copied if its policy sets allowCritical.
+	// Requests for extensions that are not listed here will be rejected,
+	// other than for the extensions that cert-manager derives from the
+	// CertificateRequest itself, such as subject alternative names and key
+	// usages.
+	// If not set, extensions requested by the CSR are ignored.
+	// +optional
+	AllowedCSRExtensions []CustomExtensionPolicy `json:"allowedCSRExtensions,omitempty"`
+
+	// PreferredChain is the CommonName of the root certificate that the chain
+	// returned with issued certificates should terminate at, when the signing
+	// Secret contains cross-signed intermediates that make it possible to
+	// build chains to more than one root.
+	// If not set, or if no chain terminates at the given root, the first
+	// chain found in the order certificates appear in the Secret is used.
+	// +optional
+	// +kubebuilder:validation:MaxLength=64
+	PreferredChain string `json:"preferredChain,omitempty"`
+
+	// SubjectKeyIdentifierMethod is the method used to derive the subject key
+	// identifier of issued certificates from their public key, for interop
+	// with relying parties that expect a particular derivation. One of
+	// "RFC5280Method1" (the full SHA-1 hash of the public key) or
+	// "RFC5280Method2" (a 4-bit type field followed by the least significant
+	// 60 bits of the SHA-1 hash).
+	// If not set, no subject key identifier is added to leaf certificates, and
+	// CA certificates use the RFC 5280 method 1 identifier.
+	// +optional
+	SubjectKeyIdentifierMethod SubjectKeyIdentifierMethod `json:"subjectKeyIdentifierMethod,omitempty"`
+
+	// LegacyCommonNameOnly, if true, issues certificates without a
+	// subjectAltName extension when the only name requested is the Common
+	// Name, i.e. the CertificateRequest requests no subject alternative names
+	// other than a single DNS name equal to its Common Name. This is for
+	// legacy clients that reject certificates with a subjectAltName extension,
+	// and goes against modern best practice, as most clients ignore the Common
+	// Name. Certificates requesting any other names are issued unchanged.
+	// +optional
+	LegacyCommonNameOnly bool `json:"legacyCommonNameOnly,omitempty"`
+
+	// RotationSecretNames is the list of names of Secrets containing other CA
+	// certificates for the private key in SecretName, such as the old and new
+	// intermediates during a rotation of the CA certificate. The chains of
+	// these certificates are added to the ca.crt returned with issued
+	// certificates, alongside the chain of the certificate in SecretName, so
+	// that issued certificates are trusted whichever chain a relying party
+	// has. Requests are left pending if a listed certificate is not for the
+	// same private key. Remove a Secret from the list to end its rotation
+	// window.
+	// +optional
+	RotationSecretNames []string `json:"rotationSecretNames,omitempty"`
+}
+
+// SubjectKeyIdentifierMethod is a method of deriving a subject key identifier
+// from a public key, as described in RFC 5280, section 4.2.1.2.
+// +kubebuilder:validation:Enum=RFC5280Method1;RFC5280Method2
+type SubjectKeyIdentifierMethod string
+
+const (
+	// SubjectKeyIdentifierMethod1 derives the subject key identifier as the
+	// 160-bit SHA-1 hash of the subjectPublicKey BIT STRING.
+	SubjectKeyIdentifierMethod1 SubjectKeyIdentifierMethod = "RFC5280Method1"
+
+	// SubjectKeyIdentifierMethod2 derives the subject key identifier as a
+	// four-bit type field with the value 0100 followed by the least
+	// significant 60 bits of the SHA-1 hash of the subjectPublicKey BIT
+	// STRING.
+	SubjectKeyIdentifierMethod2 SubjectKeyIdentifierMethod = "RFC5280Method2"
+)
+
+// CustomExtensionPolicy permits a custom X.509 extension to be requested from
+// an issuer.
+type CustomExtensionPolicy struct {
+	// OID is the object identifier of the extension in dotted-decimal
+	// notation, e.g. "1.3.6.1.4.1.311.21.7".
+	OID string `json:"oid"`
+
+	// AllowCritical permits the extension to be marked as critical using the
+	// `cert-manager.io/critical-extensions` annotation.
+	// If false, requests that mark this extension as critical will be
+	// rejected.
+	// +optional
+	AllowCritical bool `json:"allowCritical,omitempty"`
+}
+
+// CertificatePolicy is a certificate policy that will be asserted in
+// certificates issued by an issuer.
+type CertificatePolicy struct {
+	// OID is the object identifier of the policy in dotted-decimal notation,
+	// e.g. "2.23.140.1.2.1".
+	OID string `json:"oid"`
+
+	// CPSURIs is a list of URIs of certification practice statements that
+	// apply to the policy. Each URI is included as a CPS policy qualifier.
+	// +optional
+	CPSURIs []string `json:"cpsURIs,omitempty"`
+}
+
+// PolicyConstraints configures the policyConstraints X.509 v3 extension
+// defined in RFC 5280, section 4.2.1.11. At least one of its fields must be
+// set.
+type PolicyConstraints struct {
+	// RequireExplicitPolicy is the number of additional non-self-issued
+	// certificates that may appear in the path before an explicit policy is
+	// required for the entire path.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RequireExplicitPolicy *int32 `json:"requireExplicitPolicy,omitempty"`
+
+	// InhibitPolicyMapping is the number of additional non-self-issued
+	// certificates that may appear in the path before policy mapping is no
+	// longer permitted.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	InhibitPolicyMapping *int32 `json:"inhibitPolicyMapping,omitempty"`
 }
 
 // IssuerStatus contains status information about an Issuer
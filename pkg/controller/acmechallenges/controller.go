@@ -30,6 +30,7 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/jetstack/cert-manager/pkg/acme/accounts"
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	cmacmelisters "github.com/jetstack/cert-manager/pkg/client/listers/acme/v1"
 	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1"
@@ -79,6 +80,21 @@ type controller struct {
 	dns01Nameservers []string
 
 	DNS01CheckRetryPeriod time.Duration
+
+	// DNS01BatchWindow is the time after which a Challenge is retried if its
+	// DNS01 record is waiting to be sent to the DNS provider as part of a
+	// batch.
+	DNS01BatchWindow time.Duration
+
+	// finalizer is the name of the finalizer that this controller is
+	// responsible for removing once a Challenge has been cleaned up.
+	finalizer string
+
+	// legacyFinalizers are finalizer names previously used by this
+	// controller. They are removed in the same way as finalizer, so that
+	// Challenges created before the finalizer name was changed can still be
+	// cleaned up and deleted.
+	legacyFinalizers []string
 }
 
 func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitingInterface, []cache.InformerSynced, error) {
@@ -107,6 +123,12 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 		serviceInformer.Informer().HasSynced,
 		ingressInformer.Informer().HasSynced,
 	}
+	// the HTTPRoute informer is only registered if Gateway API support is
+	// enabled, as the Gateway API CRDs may not be installed
+	if ctx.GWShared != nil {
+		httpRouteInformer := ctx.GWShared.Networking().V1alpha1().HTTPRoutes()
+		mustSync = append(mustSync, httpRouteInformer.Informer().HasSynced)
+	}
 
 	// set all the references to the listers for used by the Sync function
 	c.challengeLister = challengeInformer.Lister()
@@ -140,6 +162,12 @@ func (c *controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	// read options from context
 	c.dns01Nameservers = ctx.ACMEOptions.DNS01Nameservers
 	c.DNS01CheckRetryPeriod = ctx.ACMEOptions.DNS01CheckRetryPeriod
+	c.DNS01BatchWindow = ctx.ACMEOptions.DNS01BatchWindow
+	c.finalizer = ctx.ACMEOptions.Finalizer
+	if c.finalizer == "" {
+		c.finalizer = cmacme.ACMEFinalizer
+	}
+	c.legacyFinalizers = ctx.ACMEOptions.LegacyFinalizers
 
 	return c.queue, mustSync, nil
 }
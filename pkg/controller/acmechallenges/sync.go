@@ -18,6 +18,7 @@ package acmechallenges
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	acmeapi "golang.org/x/crypto/acme"
@@ -32,6 +33,7 @@ import (
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/feature"
+	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns"
 	dnsutil "github.com/jetstack/cert-manager/pkg/issuer/acme/dns/util"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	utilfeature "github.com/jetstack/cert-manager/pkg/util/feature"
@@ -103,6 +105,10 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 			}
 
 			err = solver.CleanUp(ctx, genericIssuer, ch)
+			if errors.Is(err, dns.ErrBatchPending) {
+				log.V(logf.DebugLevel).Info("waiting for the challenge to be cleaned up as part of a batch")
+				return c.requeueAfterBatchWindow(ch)
+			}
 			if err != nil {
 				c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonCleanUpError, "Error cleaning up challenge: %v", err)
 				ch.Status.Reason = err.Error()
@@ -170,9 +176,17 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 
 	if !ch.Status.Presented {
 		err := solver.Present(ctx, genericIssuer, ch)
+		if errors.Is(err, dns.ErrBatchPending) {
+			log.V(logf.DebugLevel).Info("waiting for the challenge to be presented as part of a batch")
+			return c.requeueAfterBatchWindow(ch)
+		}
 		if err != nil {
 			c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonPresentError, "Error presenting challenge: %v", err)
 			ch.Status.Reason = err.Error()
+			if recordFailedAttempt(ch, genericIssuer, err) {
+				// the challenge has failed, so there is nothing to retry
+				return nil
+			}
 			return err
 		}
 
@@ -198,12 +212,49 @@ func (c *controller) Sync(ctx context.Context, ch *cmacme.Challenge) (err error)
 
 	err = c.acceptChallenge(ctx, cl, ch)
 	if err != nil {
+		// Only count errors returned by the ACME server, as other errors,
+		// such as network errors, say nothing about the challenge itself.
+		if _, ok := err.(*acmeapi.Error); ok && recordFailedAttempt(ch, genericIssuer, err) {
+			return nil
+		}
 		return err
 	}
 
 	return nil
 }
 
+// requeueAfterBatchWindow requeues the challenge once the DNS01 batch window
+// has elapsed, so that the result of the batch its record was added to can be
+// collected.
+func (c *controller) requeueAfterBatchWindow(ch *cmacme.Challenge) error {
+	key, err := controllerpkg.KeyFunc(ch)
+	if err != nil {
+		return err
+	}
+	c.queue.AddAfter(key, c.DNS01BatchWindow)
+	return nil
+}
+
+// recordFailedAttempt records a failed attempt to present the challenge, or
+// to accept it with the ACME server, if the issuer limits the number of
+// attempts, and marks the challenge as errored once that limit has been
+// reached. It returns true if the challenge has been marked as errored.
+func recordFailedAttempt(ch *cmacme.Challenge, issuer cmapi.GenericIssuer, err error) bool {
+	acmeIssuer := issuer.GetSpec().ACME
+	if acmeIssuer == nil || acmeIssuer.MaxChallengeAttempts == nil {
+		return false
+	}
+
+	ch.Status.FailedAttempts++
+	if ch.Status.FailedAttempts < *acmeIssuer.MaxChallengeAttempts {
+		return false
+	}
+
+	ch.Status.State = cmacme.Errored
+	ch.Status.Reason = fmt.Sprintf("Reached the issuer's maxChallengeAttempts limit of %d failed attempts: %v", ch.Status.FailedAttempts, err)
+	return true
+}
+
 // handleError will handle ACME error types, updating the challenge resource
 // with any new information found whilst inspecting the error response.
 // This may include marking the challenge as expired.
@@ -238,6 +289,20 @@ func handleError(ch *cmacme.Challenge, err error) error {
 	return err
 }
 
+// ownsFinalizer returns true if the given finalizer is the one added to
+// Challenges by this controller, or one that it used to add.
+func (c *controller) ownsFinalizer(finalizer string) bool {
+	if finalizer == c.finalizer {
+		return true
+	}
+	for _, f := range c.legacyFinalizers {
+		if finalizer == f {
+			return true
+		}
+	}
+	return false
+}
+
 // handleFinalizer will attempt to 'finalize' the Challenge resource by calling
 // CleanUp if the resource is in a 'processing' state.
 func (c *controller) handleFinalizer(ctx context.Context, ch *cmacme.Challenge) (err error) {
@@ -245,12 +310,18 @@ func (c *controller) handleFinalizer(ctx context.Context, ch *cmacme.Challenge)
 	if len(ch.Finalizers) == 0 {
 		return nil
 	}
-	if ch.Finalizers[0] != cmacme.ACMEFinalizer {
+	if !c.ownsFinalizer(ch.Finalizers[0]) {
 		log.V(logf.DebugLevel).Info("waiting to run challenge finalization...")
 		return nil
 	}
 
+	// waitingForBatch is set if the record is waiting to be cleaned up as
+	// part of a batch, in which case the finalizer is not yet removed
+	waitingForBatch := false
 	defer func() {
+		if waitingForBatch {
+			return
+		}
 		// call UpdateStatus first as we may have updated the challenge.status.reason field
 		ch, updateErr := c.cmClient.AcmeV1().Challenges(ch.Namespace).UpdateStatus(ctx, ch, metav1.UpdateOptions{})
 		if updateErr != nil {
@@ -282,6 +353,11 @@ func (c *controller) handleFinalizer(ctx context.Context, ch *cmacme.Challenge)
 	}
 
 	err = solver.CleanUp(ctx, genericIssuer, ch)
+	if errors.Is(err, dns.ErrBatchPending) {
+		log.V(logf.DebugLevel).Info("waiting for the challenge to be cleaned up as part of a batch")
+		waitingForBatch = true
+		return c.requeueAfterBatchWindow(ch)
+	}
 	if err != nil {
 		c.recorder.Eventf(ch, corev1.EventTypeWarning, reasonCleanUpError, "Error cleaning up challenge: %v", err)
 		ch.Status.Reason = err.Error()
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	acmeapi "golang.org/x/crypto/acme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
 
@@ -30,8 +31,10 @@ import (
 	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
 	"github.com/jetstack/cert-manager/pkg/issuer"
+	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
@@ -154,6 +157,27 @@ func TestSyncHappyPath(t *testing.T) {
 				},
 			},
 		},
+		"requeue without updating the challenge if its DNS01 record is waiting to be presented as part of a batch": {
+			challenge: gen.ChallengeFrom(baseChallenge,
+				gen.SetChallengeProcessing(true),
+				gen.SetChallengeURL("testurl"),
+				gen.SetChallengeState(cmacme.Pending),
+				gen.SetChallengeType(cmacme.ACMEChallengeTypeDNS01),
+			),
+			dnsSolver: &fakeSolver{
+				fakePresent: func(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
+					return dns.ErrBatchPending
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(baseChallenge,
+					gen.SetChallengeProcessing(true),
+					gen.SetChallengeURL("testurl"),
+					gen.SetChallengeState(cmacme.Pending),
+					gen.SetChallengeType(cmacme.ACMEChallengeTypeDNS01),
+				), testIssuerHTTP01Enabled},
+			},
+		},
 		"accept the challenge if the self check is passing": {
 			challenge: gen.ChallengeFrom(baseChallenge,
 				gen.SetChallengeProcessing(true),
@@ -411,6 +435,247 @@ func TestSyncHappyPath(t *testing.T) {
 	}
 }
 
+func TestSyncMaxChallengeAttempts(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+	issuerWithMaxAttempts := func(maxAttempts int32) *v1.Issuer {
+		return gen.Issuer("testissuer", gen.SetIssuerACME(cmacme.ACMEIssuer{
+			MaxChallengeAttempts: int32Ptr(maxAttempts),
+			Solvers: []cmacme.ACMEChallengeSolver{
+				{
+					HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+						Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{},
+					},
+				},
+			},
+		}))
+	}
+	pendingChallenge := gen.Challenge("testchal",
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{
+			Name: "testissuer",
+		}),
+		gen.SetChallengeProcessing(true),
+		gen.SetChallengeURL("testurl"),
+		gen.SetChallengeState(cmacme.Pending),
+		gen.SetChallengeType(cmacme.ACMEChallengeTypeHTTP01),
+	)
+	presentedChallenge := gen.ChallengeFrom(pendingChallenge, gen.SetChallengePresented(true))
+	failingSolver := &fakeSolver{
+		fakePresent: func(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
+			return fmt.Errorf("some error")
+		},
+		fakeCheck: func(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
+			return fmt.Errorf("some error")
+		},
+	}
+	passingSolver := &fakeSolver{
+		fakeCheck: func(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
+			return nil
+		},
+	}
+	acceptErr := &acmeapi.Error{StatusCode: 503, Detail: "service unavailable"}
+	rejectingClient := &acmecl.FakeACME{
+		FakeAccept: func(context.Context, *acmeapi.Challenge) (*acmeapi.Challenge, error) {
+			return nil, acceptErr
+		},
+	}
+
+	tests := map[string]testT{
+		"do not record a failed self check attempt if the issuer sets maxChallengeAttempts": {
+			challenge:  presentedChallenge,
+			httpSolver: failingSolver,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{presentedChallenge, issuerWithMaxAttempts(1)},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(presentedChallenge,
+							gen.SetChallengeReason("Waiting for HTTP-01 challenge propagation: some error"),
+						))),
+				},
+			},
+		},
+		"record a failed attempt if the ACME server rejects accepting the challenge": {
+			challenge:  presentedChallenge,
+			httpSolver: passingSolver,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{presentedChallenge, issuerWithMaxAttempts(3)},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(presentedChallenge,
+							gen.SetChallengeFailedAttempts(1),
+							gen.SetChallengeReason("Error accepting challenge: "+acceptErr.Error()),
+						))),
+				},
+			},
+			acmeClient: rejectingClient,
+			expectErr:  true,
+		},
+		"mark the challenge as errored once accepting has been rejected maxChallengeAttempts times": {
+			challenge:  gen.ChallengeFrom(presentedChallenge, gen.SetChallengeFailedAttempts(2)),
+			httpSolver: passingSolver,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.ChallengeFrom(presentedChallenge, gen.SetChallengeFailedAttempts(2)),
+					issuerWithMaxAttempts(3),
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(presentedChallenge,
+							gen.SetChallengeFailedAttempts(3),
+							gen.SetChallengeState(cmacme.Errored),
+							gen.SetChallengeReason("Reached the issuer's maxChallengeAttempts limit of 3 failed attempts: "+acceptErr.Error()),
+						))),
+				},
+			},
+			acmeClient: rejectingClient,
+		},
+		"mark the challenge as errored once presenting has failed maxChallengeAttempts times": {
+			challenge:  pendingChallenge,
+			httpSolver: failingSolver,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{pendingChallenge, issuerWithMaxAttempts(1)},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(pendingChallenge,
+							gen.SetChallengeFailedAttempts(1),
+							gen.SetChallengeState(cmacme.Errored),
+							gen.SetChallengeReason("Reached the issuer's maxChallengeAttempts limit of 1 failed attempts: some error"),
+						))),
+				},
+				ExpectedEvents: []string{
+					"Warning PresentError Error presenting challenge: some error",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			runTest(t, test)
+		})
+	}
+}
+
+func TestSyncFinalizer(t *testing.T) {
+	customFinalizer := "finalizer.acme.example.com"
+	deletedChallenge := gen.Challenge("testchal",
+		gen.SetChallengeIssuer(cmmeta.ObjectReference{
+			Name: "testissuer",
+		}),
+		gen.SetChallengeDeletionTimestamp(metav1.Now()),
+	)
+	customFinalizerContext := func() *controllerpkg.Context {
+		return &controllerpkg.Context{
+			RootContext: context.Background(),
+			ACMEOptions: controllerpkg.ACMEOptions{Finalizer: customFinalizer},
+		}
+	}
+
+	tests := map[string]testT{
+		"remove the default finalizer from a deleted challenge": {
+			challenge: gen.ChallengeFrom(deletedChallenge,
+				gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+			),
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(deletedChallenge,
+					gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+				)},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(deletedChallenge,
+							gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+						))),
+					testpkg.NewAction(coretesting.NewUpdateAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(deletedChallenge,
+							gen.SetChallengeFinalizers([]string{}),
+						))),
+				},
+			},
+		},
+		"remove the configured finalizer from a deleted challenge": {
+			challenge: gen.ChallengeFrom(deletedChallenge,
+				gen.SetChallengeFinalizers([]string{customFinalizer}),
+			),
+			builder: &testpkg.Builder{
+				Context: customFinalizerContext(),
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(deletedChallenge,
+					gen.SetChallengeFinalizers([]string{customFinalizer}),
+				)},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(deletedChallenge,
+							gen.SetChallengeFinalizers([]string{customFinalizer}),
+						))),
+					testpkg.NewAction(coretesting.NewUpdateAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(deletedChallenge,
+							gen.SetChallengeFinalizers([]string{}),
+						))),
+				},
+			},
+		},
+		"do not remove the default finalizer if a different finalizer is configured": {
+			challenge: gen.ChallengeFrom(deletedChallenge,
+				gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+			),
+			builder: &testpkg.Builder{
+				Context: customFinalizerContext(),
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(deletedChallenge,
+					gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+				)},
+			},
+		},
+		"remove a legacy finalizer from a deleted challenge": {
+			challenge: gen.ChallengeFrom(deletedChallenge,
+				gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+			),
+			builder: &testpkg.Builder{
+				Context: &controllerpkg.Context{
+					RootContext: context.Background(),
+					ACMEOptions: controllerpkg.ACMEOptions{
+						Finalizer:        customFinalizer,
+						LegacyFinalizers: []string{cmacme.ACMEFinalizer},
+					},
+				},
+				CertManagerObjects: []runtime.Object{gen.ChallengeFrom(deletedChallenge,
+					gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+				)},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(deletedChallenge,
+							gen.SetChallengeFinalizers([]string{cmacme.ACMEFinalizer}),
+						))),
+					testpkg.NewAction(coretesting.NewUpdateAction(cmacme.SchemeGroupVersion.WithResource("challenges"),
+						gen.DefaultTestNamespace,
+						gen.ChallengeFrom(deletedChallenge,
+							gen.SetChallengeFinalizers([]string{}),
+						))),
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			runTest(t, test)
+		})
+	}
+}
+
 func runTest(t *testing.T, test testT) {
 	test.builder.T = t
 	test.builder.Init()
@@ -30,6 +30,7 @@ import (
 	"k8s.io/utils/clock"
 
 	"github.com/jetstack/cert-manager/pkg/acme/accounts"
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	cminformers "github.com/jetstack/cert-manager/pkg/client/informers/externalversions"
 	cmacmelisters "github.com/jetstack/cert-manager/pkg/client/listers/acme/v1"
@@ -72,6 +73,10 @@ type controller struct {
 
 	// logger to be used by this controller
 	log logr.Logger
+
+	// finalizer is the name of the finalizer added to the Challenges created
+	// by this controller.
+	finalizer string
 }
 
 // NewController constructs an orders controller using the provided options.
@@ -153,6 +158,7 @@ func NewController(
 		recorder:            recorder,
 		cmClient:            cmClient,
 		accountRegistry:     accountRegistry,
+		finalizer:           cmacme.ACMEFinalizer,
 	}, queue, mustSync
 
 }
@@ -218,6 +224,9 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.Clock,
 		isNamespaced,
 	)
+	if ctx.ACMEOptions.Finalizer != "" {
+		ctrl.finalizer = ctx.ACMEOptions.Finalizer
+	}
 	c.controller = ctrl
 
 	return queue, mustSync, nil
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acmeorders
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	acmeapi "golang.org/x/crypto/acme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+)
+
+const (
+	// rateLimitedProblemType is the ACME problem type returned when a request
+	// is rejected because it exceeds a rate limit.
+	rateLimitedProblemType = "urn:ietf:params:acme:error:rateLimited"
+
+	// DefaultRateLimitCooldown is how long new orders are deferred for after
+	// a rate limit error that does not have a Retry-After header.
+	DefaultRateLimitCooldown = time.Hour
+)
+
+// rateLimitError returns the ACME error if err is a rate limit error
+// returned by the ACME server, or nil otherwise.
+func rateLimitError(err error) *acmeapi.Error {
+	acmeErr, ok := err.(*acmeapi.Error)
+	if !ok {
+		return nil
+	}
+	if acmeErr.ProblemType != rateLimitedProblemType && acmeErr.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	return acmeErr
+}
+
+// rateLimitCooldown returns how long to wait before retrying after the given
+// rate limit error, as given by its Retry-After header. The header may either
+// be a number of seconds or an HTTP date.
+func rateLimitCooldown(acmeErr *acmeapi.Error, now time.Time) time.Duration {
+	v := acmeErr.Header.Get("Retry-After")
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return DefaultRateLimitCooldown
+}
+
+// rateLimitedUntil returns the time until which new orders for the issuer
+// are deferred, or nil if they are not.
+func rateLimitedUntil(iss cmapi.GenericIssuer, now time.Time) *metav1.Time {
+	status := iss.GetStatus()
+	if status == nil || status.ACME == nil || status.ACME.RateLimitedUntil == nil {
+		return nil
+	}
+	if !now.Before(status.ACME.RateLimitedUntil.Time) {
+		return nil
+	}
+	return status.ACME.RateLimitedUntil
+}
+
+// recordRateLimit stores the rate limit error and the time until which new
+// orders are deferred on the status of the issuer, and returns that time.
+func (c *controller) recordRateLimit(ctx context.Context, iss cmapi.GenericIssuer, acmeErr *acmeapi.Error) (time.Time, error) {
+	now := c.clock.Now()
+	until := metav1.NewTime(now.Add(rateLimitCooldown(acmeErr, now)))
+
+	setStatus := func(status *cmapi.IssuerStatus) {
+		status.ACMEStatus().LastRateLimitError = acmeErr.Detail
+		status.ACMEStatus().RateLimitedUntil = &until
+	}
+
+	var err error
+	switch iss := iss.(type) {
+	case *cmapi.Issuer:
+		iss = iss.DeepCopy()
+		setStatus(&iss.Status)
+		_, err = c.cmClient.CertmanagerV1().Issuers(iss.Namespace).UpdateStatus(ctx, iss, metav1.UpdateOptions{})
+	case *cmapi.ClusterIssuer:
+		iss = iss.DeepCopy()
+		setStatus(&iss.Status)
+		_, err = c.cmClient.CertmanagerV1().ClusterIssuers().UpdateStatus(ctx, iss, metav1.UpdateOptions{})
+	default:
+		err = fmt.Errorf("unsupported issuer type %T", iss)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error recording rate limit on issuer %q: %v", iss.GetName(), err)
+	}
+
+	return until.Time, nil
+}
+
+// deferOrder schedules the Order to be processed again at the given time.
+func (c *controller) deferOrder(ctx context.Context, o *cmacme.Order, until time.Time) {
+	key, err := cache.MetaNamespaceKeyFunc(o)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "failed to construct key for deferred Order")
+		return
+	}
+	c.scheduledWorkQueue.Add(key, until.Sub(c.clock.Now()))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acmeorders
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	acmeapi "golang.org/x/crypto/acme"
+)
+
+func TestRateLimitError(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		expRateLimit bool
+	}{
+		"rate limited problem type": {
+			err:          &acmeapi.Error{StatusCode: http.StatusForbidden, ProblemType: rateLimitedProblemType},
+			expRateLimit: true,
+		},
+		"too many requests status code": {
+			err:          &acmeapi.Error{StatusCode: http.StatusTooManyRequests},
+			expRateLimit: true,
+		},
+		"other acme error": {
+			err: &acmeapi.Error{StatusCode: http.StatusBadRequest, ProblemType: "urn:ietf:params:acme:error:malformed"},
+		},
+		"non acme error": {
+			err: errors.New("connection refused"),
+		},
+		"no error": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := rateLimitError(test.err) != nil; got != test.expRateLimit {
+				t.Errorf("expected rate limit error=%t, got=%t", test.expRateLimit, got)
+			}
+		})
+	}
+}
+
+func TestRateLimitCooldown(t *testing.T) {
+	now := time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		retryAfter string
+		exp        time.Duration
+	}{
+		"retry after a number of seconds": {
+			retryAfter: "120",
+			exp:        2 * time.Minute,
+		},
+		"retry after an http date": {
+			retryAfter: now.Add(3 * time.Hour).Format(http.TimeFormat),
+			exp:        3 * time.Hour,
+		},
+		"retry after an http date in the past uses the default": {
+			retryAfter: now.Add(-time.Hour).Format(http.TimeFormat),
+			exp:        DefaultRateLimitCooldown,
+		},
+		"invalid retry after uses the default": {
+			retryAfter: "soon",
+			exp:        DefaultRateLimitCooldown,
+		},
+		"no retry after uses the default": {
+			exp: DefaultRateLimitCooldown,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			acmeErr := &acmeapi.Error{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			if test.retryAfter != "" {
+				acmeErr.Header.Set("Retry-After", test.retryAfter)
+			}
+			if got := rateLimitCooldown(acmeErr, now); got != test.exp {
+				t.Errorf("expected cooldown %s, got %s", test.exp, got)
+			}
+		})
+	}
+}
@@ -85,8 +85,13 @@ func (c *controller) Sync(ctx context.Context, o *cmacme.Order) (err error) {
 
 	switch {
 	case o.Status.URL == "":
+		if until := rateLimitedUntil(genericIssuer, c.clock.Now()); until != nil {
+			log.V(logf.InfoLevel).Info("Deferring creating new ACME order as the issuer is rate limited by the ACME server", "rate_limited_until", until.Time)
+			c.deferOrder(ctx, o, until.Time)
+			return nil
+		}
 		log.V(logf.DebugLevel).Info("Creating new ACME order as status.url is not set")
-		return c.createOrder(ctx, cl, o)
+		return c.createOrder(ctx, cl, o, genericIssuer)
 	case o.Status.FinalizeURL == "":
 		log.V(logf.DebugLevel).Info("Updating Order status as status.finalizeURL is not set")
 		_, err := c.updateOrderStatus(ctx, cl, o)
@@ -108,7 +113,7 @@ func (c *controller) Sync(ctx context.Context, o *cmacme.Order) (err error) {
 		return nil
 	case o.Status.State == cmacme.Valid && o.Status.Certificate == nil:
 		log.V(logf.DebugLevel).Info("Order is in a Valid state but the Certificate data is empty, fetching existing Certificate")
-		return c.fetchCertificateData(ctx, cl, o)
+		return c.fetchCertificateData(ctx, cl, o, genericIssuer)
 	case o.Status.State == cmacme.Valid && len(o.Status.Certificate) > 0:
 		log.V(logf.DebugLevel).Info("Order has already been completed, cleaning up any owned Challenge resources")
 		// if the Order is valid and the certificate data has been set, clean
@@ -117,7 +122,7 @@ func (c *controller) Sync(ctx context.Context, o *cmacme.Order) (err error) {
 	}
 
 	dbg.Info("Computing list of Challenge resources that need to exist to complete this Order")
-	requiredChallenges, err := buildRequiredChallenges(ctx, cl, genericIssuer, o)
+	requiredChallenges, err := buildRequiredChallenges(ctx, cl, genericIssuer, o, c.finalizer)
 	if err != nil {
 		log.Error(err, "Failed to determine the list of Challenge resources needed for the Order")
 		c.recorder.Eventf(o, corev1.EventTypeWarning, reasonSolver, "Failed to determine a valid solver configuration for the set of domains on the Order: %v", err)
@@ -152,6 +157,15 @@ func (c *controller) Sync(ctx context.Context, o *cmacme.Order) (err error) {
 		return err
 	}
 
+	// The ACME server will not fail the order for challenges that were never
+	// accepted, so fail it here once a challenge has used up its attempts.
+	if ch := challengeExceedingMaxAttempts(challenges, genericIssuer.GetSpec().ACME.MaxChallengeAttempts); ch != nil {
+		log.V(logf.InfoLevel).Info("Marking Order as failed as a Challenge has reached the issuer's maxChallengeAttempts", "challenge", ch.Name)
+		c.setOrderState(&o.Status, string(cmacme.Errored))
+		o.Status.Reason = fmt.Sprintf("Challenge for %q failed %d times, which is the maximum allowed by the issuer's maxChallengeAttempts: %s", ch.Spec.DNSName, ch.Status.FailedAttempts, ch.Status.Reason)
+		return nil
+	}
+
 	acmeOrder, err := getACMEOrder(ctx, cl, o)
 	// Order probably has been deleted, we cannot recover here.
 	if acmeErr, ok := err.(*acmeapi.Error); ok {
@@ -233,7 +247,7 @@ func (c *controller) Sync(ctx context.Context, o *cmacme.Order) (err error) {
 	return nil
 }
 
-func (c *controller) createOrder(ctx context.Context, cl acmecl.Interface, o *cmacme.Order) error {
+func (c *controller) createOrder(ctx context.Context, cl acmecl.Interface, o *cmacme.Order, genericIssuer cmapi.GenericIssuer) error {
 	log := logf.FromContext(ctx)
 
 	if o.Status.URL != "" {
@@ -259,6 +273,17 @@ func (c *controller) createOrder(ctx context.Context, cl acmecl.Interface, o *cm
 		options = append(options, acmeapi.WithOrderNotAfter(c.clock.Now().Add(o.Spec.Duration.Duration)))
 	}
 	acmeOrder, err := cl.AuthorizeOrder(ctx, authzIDs, options...)
+	if acmeErr := rateLimitError(err); acmeErr != nil {
+		// Rather than failing the Order, defer it and all other new orders
+		// for the issuer until the rate limit has expired.
+		until, err := c.recordRateLimit(ctx, genericIssuer, acmeErr)
+		if err != nil {
+			return err
+		}
+		log.V(logf.InfoLevel).Info("ACME server rate limited creating the order, deferring new orders for the issuer", "rate_limited_until", until, "error", acmeErr.Detail)
+		c.deferOrder(ctx, o, until)
+		return nil
+	}
 	if acmeErr, ok := err.(*acmeapi.Error); ok {
 		if acmeErr.StatusCode >= 400 && acmeErr.StatusCode < 500 {
 			log.Error(err, "failed to create Order resource due to bad request, marking Order as failed")
@@ -531,32 +556,64 @@ func (c *controller) finalizeOrder(ctx context.Context, cl acmecl.Interface, o *
 	}
 
 	if issuer.GetSpec().ACME != nil && issuer.GetSpec().ACME.PreferredChain != "" {
-		altBundles, err := cl.FetchCertAlternatives(ctx, certURL, true)
+		certSlice, err = findPreferredChain(ctx, cl, certURL, certSlice, issuer.GetSpec().ACME.PreferredChain)
 		if err != nil {
-			return fmt.Errorf("error fetching alternate certificates: %w", err)
-		}
-		for _, altBundle := range altBundles {
-			for _, certPEM := range altBundle {
-				cert, err := x509.ParseCertificate(certPEM)
-				if err != nil {
-					return fmt.Errorf("error parsing alternate certificates: %w", err)
-				}
-
-				log.V(logf.DebugLevel).WithValues("Issuer CN", cert.Issuer.CommonName).Info("Found alternative ACME bundle")
-				if cert.Issuer.CommonName == issuer.GetSpec().ACME.PreferredChain {
-					// if the issuer's CN matched the preferred chain it means this bundle is
-					// signed by the requested chain
-					return c.storeCertificateOnStatus(ctx, o, altBundle)
-				}
-			}
+			return err
 		}
-		// if no match is found we return to the actual cert
-		// it is a *preferred* chain after all
 	}
 
 	return c.storeCertificateOnStatus(ctx, o, certSlice)
 }
 
+// findPreferredChain returns the first certificate chain offered by the ACME
+// server whose root issuer's CommonName is preferredChain, checking the
+// default chain before any alternate chains. The root issuer of a chain is
+// the issuer of the last certificate in it. If no chain matches, the default
+// chain is returned.
+func findPreferredChain(ctx context.Context, cl acmecl.Interface, certURL string, defaultChain [][]byte, preferredChain string) ([][]byte, error) {
+	log := logf.FromContext(ctx)
+
+	match, err := chainHasRootIssuer(defaultChain, preferredChain)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing certificates: %w", err)
+	}
+	if match {
+		return defaultChain, nil
+	}
+
+	altBundles, err := cl.FetchCertAlternatives(ctx, certURL, true)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching alternate certificates: %w", err)
+	}
+	for _, altBundle := range altBundles {
+		match, err := chainHasRootIssuer(altBundle, preferredChain)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing alternate certificates: %w", err)
+		}
+		if match {
+			log.V(logf.DebugLevel).WithValues("Issuer CN", preferredChain).Info("Found preferred alternative ACME bundle")
+			return altBundle, nil
+		}
+	}
+
+	// if no match is found we return to the actual cert
+	// it is a *preferred* chain after all
+	return defaultChain, nil
+}
+
+// chainHasRootIssuer returns true if the issuer of the last DER encoded
+// certificate in chain has the given CommonName.
+func chainHasRootIssuer(chain [][]byte, commonName string) (bool, error) {
+	if len(chain) == 0 {
+		return false, nil
+	}
+	cert, err := x509.ParseCertificate(chain[len(chain)-1])
+	if err != nil {
+		return false, err
+	}
+	return cert.Issuer.CommonName == commonName, nil
+}
+
 func (c *controller) storeCertificateOnStatus(ctx context.Context, o *cmacme.Order, certs [][]byte) error {
 	log := logf.FromContext(ctx)
 	// encode the retrieved certificates (including the chain)
@@ -577,7 +634,7 @@ func (c *controller) storeCertificateOnStatus(ctx context.Context, o *cmacme.Ord
 	return nil
 }
 
-func (c *controller) fetchCertificateData(ctx context.Context, cl acmecl.Interface, o *cmacme.Order) error {
+func (c *controller) fetchCertificateData(ctx context.Context, cl acmecl.Interface, o *cmacme.Order, issuer cmapi.GenericIssuer) error {
 	log := logf.FromContext(ctx)
 	acmeOrder, err := c.updateOrderStatus(ctx, cl, o)
 	if acmeErr, ok := err.(*acmeapi.Error); ok {
@@ -616,6 +673,13 @@ func (c *controller) fetchCertificateData(ctx context.Context, cl acmecl.Interfa
 		return err
 	}
 
+	if issuer.GetSpec().ACME != nil && issuer.GetSpec().ACME.PreferredChain != "" {
+		certs, err = findPreferredChain(ctx, cl, acmeOrder.CertURL, certs, issuer.GetSpec().ACME.PreferredChain)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = c.storeCertificateOnStatus(ctx, o, certs)
 	if err != nil {
 		return err
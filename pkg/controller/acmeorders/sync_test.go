@@ -18,9 +18,15 @@ package acmeorders
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
 	"testing"
 	"time"
 
@@ -29,13 +35,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
 	fakeclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 
 	accountstest "github.com/jetstack/cert-manager/pkg/acme/accounts/test"
 	acmecl "github.com/jetstack/cert-manager/pkg/acme/client"
 	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
 	schedulertest "github.com/jetstack/cert-manager/pkg/scheduler/test"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
@@ -81,6 +91,9 @@ func TestSyncHappyPath(t *testing.T) {
 		},
 	}))
 
+	testIssuerHTTP01TestComMaxAttempts := testIssuerHTTP01TestCom.DeepCopy()
+	testIssuerHTTP01TestComMaxAttempts.Spec.ACME.MaxChallengeAttempts = pointer.Int32Ptr(3)
+
 	testOrder := gen.Order("testorder",
 		gen.SetOrderCommonName("test.com"),
 		gen.SetOrderIssuer(cmmeta.ObjectReference{
@@ -162,20 +175,37 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 	testOrderValidAltCert.Status.State = cmacme.Valid
 	testOrderValidAltCert.Status.Certificate = testCert
 
+	testOrderValidNoCert := testOrderValid.DeepCopy()
+	testOrderValidNoCert.Status.Certificate = nil
+
+	// default chain offered by the ACME server, rooted in a different CA to
+	// the preferred chain
+	testDefaultChainCert := mustCreateCertificate(t, "Let's Encrypt Authority X3", "DST Root CA X3")
+
 	fakeHTTP01ACMECl := &acmecl.FakeACME{
 		FakeHTTP01ChallengeResponse: func(s string) (string, error) {
 			// TODO: assert s = "token"
 			return "key", nil
 		},
 	}
-	testAuthorizationChallenge, err := buildChallenge(context.TODO(), fakeHTTP01ACMECl, testIssuerHTTP01TestCom, testOrderPending, testOrderPending.Status.Authorizations[0])
+	testAuthorizationChallenge, err := buildChallenge(context.TODO(), fakeHTTP01ACMECl, testIssuerHTTP01TestCom, testOrderPending, testOrderPending.Status.Authorizations[0], cmacme.ACMEFinalizer)
 	if err != nil {
 		t.Fatalf("error building Challenge resource test fixture: %v", err)
 	}
+	testAuthorizationChallengeCustomFinalizer := testAuthorizationChallenge.DeepCopy()
+	testAuthorizationChallengeCustomFinalizer.Finalizers = []string{"finalizer.acme.example.com"}
 	testAuthorizationChallengeValid := testAuthorizationChallenge.DeepCopy()
 	testAuthorizationChallengeValid.Status.State = cmacme.Valid
 	testAuthorizationChallengeInvalid := testAuthorizationChallenge.DeepCopy()
 	testAuthorizationChallengeInvalid.Status.State = cmacme.Invalid
+	testAuthorizationChallengeMaxAttempts := testAuthorizationChallenge.DeepCopy()
+	testAuthorizationChallengeMaxAttempts.Status.State = cmacme.Errored
+	testAuthorizationChallengeMaxAttempts.Status.FailedAttempts = 3
+	testAuthorizationChallengeMaxAttempts.Status.Reason = "some error"
+	testOrderMaxAttemptsErrored := testOrderPending.DeepCopy()
+	testOrderMaxAttemptsErrored.Status.State = cmacme.Errored
+	testOrderMaxAttemptsErrored.Status.FailureTime = &nowMetaTime
+	testOrderMaxAttemptsErrored.Status.Reason = `Challenge for "test.com" failed 3 times, which is the maximum allowed by the issuer's maxChallengeAttempts: some error`
 
 	testACMEAuthorizationPending := &acmeapi.Authorization{
 		URI:    "http://authzurl",
@@ -216,7 +246,74 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 	*testACMEOrderInvalid = *testACMEOrderPending
 	testACMEOrderInvalid.Status = acmeapi.StatusInvalid
 
+	rateLimitedUntil := metav1.NewTime(nowTime.Add(time.Hour))
+	rateLimitExpired := metav1.NewTime(nowTime.Add(-time.Minute))
+	testIssuerRateLimited := gen.IssuerFrom(testIssuerHTTP01TestCom, gen.SetIssuerACMERateLimit("too many certificates already issued", &rateLimitedUntil))
+	testIssuerRateLimitExpired := gen.IssuerFrom(testIssuerHTTP01TestCom, gen.SetIssuerACMERateLimit("too many certificates already issued", &rateLimitExpired))
+
 	tests := map[string]testT{
+		"defer creating a new order if the issuer is rate limited": {
+			order: testOrder,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerRateLimited, testOrder},
+				ExpectedActions:    []testpkg.Action{},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeAuthorizeOrder: func(ctx context.Context, id []acmeapi.AuthzID, opt ...acmeapi.OrderOption) (*acmeapi.Order, error) {
+					return nil, errors.New("order should not be created while the issuer is rate limited")
+				},
+			},
+			shouldSchedule: true,
+		},
+		"record the rate limit on the issuer and defer the order if the acme server rate limits creating the order": {
+			order: testOrder,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerHTTP01TestCom, testOrder},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmapi.SchemeGroupVersion.WithResource("issuers"),
+						"status",
+						testIssuerHTTP01TestCom.Namespace,
+						gen.IssuerFrom(testIssuerHTTP01TestCom, gen.SetIssuerACMERateLimit("too many certificates already issued", &rateLimitedUntil)))),
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeAuthorizeOrder: func(ctx context.Context, id []acmeapi.AuthzID, opt ...acmeapi.OrderOption) (*acmeapi.Order, error) {
+					return nil, &acmeapi.Error{
+						StatusCode:  http.StatusTooManyRequests,
+						ProblemType: "urn:ietf:params:acme:error:rateLimited",
+						Detail:      "too many certificates already issued",
+						Header:      http.Header{"Retry-After": []string{"3600"}},
+					}
+				},
+			},
+			shouldSchedule: true,
+		},
+		"create a new order with the acme server once the issuer's rate limit has expired": {
+			order: testOrder,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerRateLimitExpired, testOrder},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("orders"),
+						"status",
+						testOrderPending.Namespace,
+						gen.OrderFrom(testOrder, gen.SetOrderStatus(cmacme.OrderStatus{
+							State:       cmacme.Pending,
+							URL:         "http://testurl.com/abcde",
+							FinalizeURL: "http://testurl.com/abcde/finalize",
+							Authorizations: []cmacme.ACMEAuthorization{
+								{
+									URL: "http://authzurl",
+								},
+							},
+						})))),
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeAuthorizeOrder: func(ctx context.Context, id []acmeapi.AuthzID, opt ...acmeapi.OrderOption) (*acmeapi.Order, error) {
+					return testACMEOrderPending, nil
+				},
+			},
+		},
 		"create a new order with the acme server, set the order url on the status resource and return nil to avoid cache timing issues": {
 			order: testOrder,
 			builder: &testpkg.Builder{
@@ -310,6 +407,28 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 				},
 			},
 		},
+		"create a challenge resource with the configured finalizer": {
+			order: testOrderPending,
+			builder: &testpkg.Builder{
+				Context: &controllerpkg.Context{
+					RootContext: context.Background(),
+					ACMEOptions: controllerpkg.ACMEOptions{Finalizer: "finalizer.acme.example.com"},
+				},
+				CertManagerObjects: []runtime.Object{testIssuerHTTP01TestCom, testOrderPending},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(cmacme.SchemeGroupVersion.WithResource("challenges"), testAuthorizationChallengeCustomFinalizer.Namespace, testAuthorizationChallengeCustomFinalizer)),
+				},
+				ExpectedEvents: []string{
+					`Normal Created Created Challenge resource "testorder-2179654896" for domain "test.com"`,
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeHTTP01ChallengeResponse: func(s string) (string, error) {
+					// TODO: assert s = "token"
+					return "key", nil
+				},
+			},
+		},
 		"should refuse to create a challenge if only an unknown challenge type is offered": {
 			order: gen.OrderFrom(testOrderPending, gen.SetOrderStatus(cmacme.OrderStatus{
 				State:       cmacme.Pending,
@@ -526,8 +645,7 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 					return testACMEOrderValid, nil
 				},
 				FakeCreateOrderCert: func(_ context.Context, url string, csr []byte, bundle bool) ([][]byte, string, error) {
-					testData := []byte("test")
-					return [][]byte{testData}, "http://testurl", nil
+					return [][]byte{testDefaultChainCert}, "http://testurl", nil
 				},
 				FakeFetchCertAlternatives: func(_ context.Context, url string, bundle bool) ([][][]byte, error) {
 					if url != "http://testurl" {
@@ -542,6 +660,31 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 				},
 			},
 		},
+		"fetch the certificate of a valid order and select the preferred alternate chain": {
+			order: testOrderValidNoCert,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerHTTP01TestComPreferredChain, testOrderValidNoCert},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("orders"),
+						"status",
+						testOrderValid.Namespace, testOrderValidAltCert)),
+				},
+				ExpectedEvents: []string{
+					"Normal Complete Order completed successfully",
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeGetOrder: func(_ context.Context, url string) (*acmeapi.Order, error) {
+					return testACMEOrderValid, nil
+				},
+				FakeFetchCert: func(_ context.Context, url string, bundle bool) ([][]byte, error) {
+					return [][]byte{testDefaultChainCert}, nil
+				},
+				FakeFetchCertAlternatives: func(_ context.Context, url string, bundle bool) ([][][]byte, error) {
+					return [][][]byte{{rawTestCert.Bytes}}, nil
+				},
+			},
+		},
 		"call GetOrder and update the order state if the challenge is 'failed'": {
 			order: testOrderPending,
 			builder: &testpkg.Builder{
@@ -577,6 +720,22 @@ rUCGwbCUDI0mxadJ3Bz4WxR6fyNpBK2yAinWEsikxqEt
 				},
 			},
 		},
+		"mark the order as errored if a challenge has reached the issuer's maxChallengeAttempts": {
+			order: testOrderPending,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{testIssuerHTTP01TestComMaxAttempts, testOrderPending, testAuthorizationChallengeMaxAttempts},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmacme.SchemeGroupVersion.WithResource("orders"),
+						"status",
+						testOrderMaxAttemptsErrored.Namespace, testOrderMaxAttemptsErrored)),
+				},
+			},
+			acmeClient: &acmecl.FakeACME{
+				FakeHTTP01ChallengeResponse: func(s string) (string, error) {
+					return "key", nil
+				},
+			},
+		},
 		"do nothing if the order is valid": {
 			order: testOrderValid,
 			builder: &testpkg.Builder{
@@ -656,3 +815,108 @@ func runTest(t *testing.T, test testT) {
 
 	test.builder.CheckAndFinish(err)
 }
+
+func TestFindPreferredChain(t *testing.T) {
+	leaf := mustCreateCertificate(t, "example.com", "Intermediate")
+	intermediateFromA := mustCreateCertificate(t, "Intermediate", "Root A")
+	intermediateFromB := mustCreateCertificate(t, "Intermediate", "Root B")
+	rootBFromA := mustCreateCertificate(t, "Root B", "Root A")
+
+	// defaultChain is rooted in Root A
+	defaultChain := [][]byte{leaf, intermediateFromA}
+	// crossSignedChain contains an intermediate issued by Root B, but is
+	// rooted in Root A
+	crossSignedChain := [][]byte{leaf, intermediateFromB, rootBFromA}
+	// altChain is rooted in Root B
+	altChain := [][]byte{leaf, intermediateFromB}
+
+	tests := map[string]struct {
+		preferredChain string
+		defaultChain   [][]byte
+		altChains      [][][]byte
+		altErr         error
+
+		expChain [][]byte
+		expErr   bool
+	}{
+		"default chain should be used if its root issuer matches": {
+			preferredChain: "Root A",
+			defaultChain:   defaultChain,
+			altChains:      [][][]byte{altChain},
+			expChain:       defaultChain,
+		},
+		"alternate chain should be used if its root issuer matches": {
+			preferredChain: "Root B",
+			defaultChain:   defaultChain,
+			altChains:      [][][]byte{altChain},
+			expChain:       altChain,
+		},
+		"alternate chain should be matched on its root issuer rather than any issuer": {
+			preferredChain: "Root B",
+			defaultChain:   defaultChain,
+			altChains:      [][][]byte{crossSignedChain, altChain},
+			expChain:       altChain,
+		},
+		"default chain should be used if no chain matches": {
+			preferredChain: "Root C",
+			defaultChain:   defaultChain,
+			altChains:      [][][]byte{crossSignedChain, altChain},
+			expChain:       defaultChain,
+		},
+		"an error should be returned if alternate chains cannot be fetched": {
+			preferredChain: "Root B",
+			defaultChain:   defaultChain,
+			altErr:         errors.New("some error"),
+			expErr:         true,
+		},
+		"an error should be returned if an alternate chain cannot be parsed": {
+			preferredChain: "Root B",
+			defaultChain:   defaultChain,
+			altChains:      [][][]byte{{[]byte("test")}},
+			expErr:         true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cl := &acmecl.FakeACME{
+				FakeFetchCertAlternatives: func(_ context.Context, url string, bundle bool) ([][][]byte, error) {
+					if url != "http://testurl" {
+						return nil, errors.New("Cert URL is incorrect")
+					}
+					return test.altChains, test.altErr
+				},
+			}
+
+			chain, err := findPreferredChain(context.Background(), cl, "http://testurl", test.defaultChain, test.preferredChain)
+			if (err != nil) != test.expErr {
+				t.Fatalf("expected error=%t, got: %v", test.expErr, err)
+			}
+			if !reflect.DeepEqual(chain, test.expChain) {
+				t.Errorf("unexpected chain returned, exp=%d certificates got=%d certificates", len(test.expChain), len(chain))
+			}
+		})
+	}
+}
+
+// mustCreateCertificate returns a DER encoded certificate with the given
+// subject and issuer CommonNames. The certificate is not signed by its issuer.
+func mustCreateCertificate(t *testing.T, commonName, issuerCommonName string) []byte {
+	t.Helper()
+	key, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+	}
+	parent := &x509.Certificate{
+		Subject: pkix.Name{CommonName: issuerCommonName},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
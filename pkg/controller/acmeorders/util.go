@@ -35,7 +35,7 @@ var (
 	orderGvk = cmacme.SchemeGroupVersion.WithKind("Order")
 )
 
-func buildRequiredChallenges(ctx context.Context, cl acmecl.Interface, issuer cmapi.GenericIssuer, o *cmacme.Order) ([]cmacme.Challenge, error) {
+func buildRequiredChallenges(ctx context.Context, cl acmecl.Interface, issuer cmapi.GenericIssuer, o *cmacme.Order, finalizer string) ([]cmacme.Challenge, error) {
 	chs := make([]cmacme.Challenge, 0)
 	for _, a := range o.Status.Authorizations {
 		if a.InitialState == cmacme.Valid {
@@ -46,7 +46,7 @@ func buildRequiredChallenges(ctx context.Context, cl acmecl.Interface, issuer cm
 			logf.FromContext(ctx).V(logf.DebugLevel).Info("Authorization already valid, not creating Challenge resource", "identifier", a.Identifier, "is_wildcard", wc)
 			continue
 		}
-		ch, err := buildChallenge(ctx, cl, issuer, o, a)
+		ch, err := buildChallenge(ctx, cl, issuer, o, a, finalizer)
 		if err != nil {
 			return nil, err
 		}
@@ -55,7 +55,7 @@ func buildRequiredChallenges(ctx context.Context, cl acmecl.Interface, issuer cm
 	return chs, nil
 }
 
-func buildChallenge(ctx context.Context, cl acmecl.Interface, issuer cmapi.GenericIssuer, o *cmacme.Order, authz cmacme.ACMEAuthorization) (*cmacme.Challenge, error) {
+func buildChallenge(ctx context.Context, cl acmecl.Interface, issuer cmapi.GenericIssuer, o *cmacme.Order, authz cmacme.ACMEAuthorization, finalizer string) (*cmacme.Challenge, error) {
 	chSpec, err := challengeSpecForAuthorization(ctx, cl, issuer, o, authz)
 	if err != nil {
 		// TODO: in this case, we should probably not return the error as it's
@@ -73,7 +73,7 @@ func buildChallenge(ctx context.Context, cl acmecl.Interface, issuer cmapi.Gener
 			Name:            chName,
 			Namespace:       o.Namespace,
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(o, orderGvk)},
-			Finalizers:      []string{cmacme.ACMEFinalizer},
+			Finalizers:      []string{finalizer},
 		},
 		Spec: *chSpec,
 	}, nil
@@ -333,6 +333,21 @@ func keyForChallenge(cl acmecl.Interface, token string, chType cmacme.ACMEChalle
 	}
 }
 
+// challengeExceedingMaxAttempts returns the first failed Challenge that has
+// reached the given maximum number of failed attempts, or nil if there is
+// none or no maximum is set.
+func challengeExceedingMaxAttempts(chs []*cmacme.Challenge, maxAttempts *int32) *cmacme.Challenge {
+	if maxAttempts == nil {
+		return nil
+	}
+	for _, ch := range chs {
+		if acme.IsFailureState(ch.Status.State) && ch.Status.FailedAttempts >= *maxAttempts {
+			return ch
+		}
+	}
+	return nil
+}
+
 func anyChallengesFailed(chs []*cmacme.Challenge) bool {
 	for _, ch := range chs {
 		if acme.IsFailureState(ch.Status.State) {
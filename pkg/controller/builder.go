@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"time"
 
+	"k8s.io/client-go/util/workqueue"
+
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 )
 
@@ -92,6 +94,12 @@ func (b *Builder) Complete() (Interface, error) {
 	if b.impl == nil {
 		return nil, fmt.Errorf("controller implementation must be non-nil")
 	}
+	if b.context.Metrics != nil {
+		// Expose metrics for the workqueue created by the controller on
+		// Register. The provider can only be set once per process, so all
+		// controllers share the same Metrics.
+		workqueue.SetProvider(b.context.Metrics.WorkqueueMetricsProvider())
+	}
 	queue, mustSync, err := b.impl.Register(b.context)
 	if err != nil {
 		return nil, fmt.Errorf("error registering controller: %v", err)
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	logtesting "github.com/jetstack/cert-manager/pkg/logs/testing"
+	"github.com/jetstack/cert-manager/pkg/metrics"
+)
+
+type fakeQueueingController struct {
+	name string
+}
+
+func (f *fakeQueueingController) Register(*Context) (workqueue.RateLimitingInterface, []cache.InformerSynced, error) {
+	return workqueue.NewNamedRateLimitingQueue(DefaultItemBasedRateLimiter(), f.name), nil, nil
+}
+
+func (f *fakeQueueingController) ProcessItem(context.Context, string) error {
+	return nil
+}
+
+func TestBuilderRegistersWorkqueueMetrics(t *testing.T) {
+	m := metrics.New(logtesting.TestLogger{T: t})
+	server, err := m.Start("127.0.0.1:0", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Shutdown(server)
+
+	ctx := &Context{
+		RootContext: context.Background(),
+		Metrics:     m,
+	}
+	if _, err := NewBuilder(ctx, "test-controller").
+		For(&fakeQueueingController{name: "test-controller"}).
+		Complete(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get("http://" + server.Addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, metric := range []string{
+		`certmanager_workqueue_depth{name="test-controller"}`,
+		`certmanager_workqueue_adds_total{name="test-controller"}`,
+		`certmanager_workqueue_retries_total{name="test-controller"}`,
+		`certmanager_workqueue_unfinished_work_seconds{name="test-controller"}`,
+		`certmanager_workqueue_longest_running_processor_seconds{name="test-controller"}`,
+	} {
+		if !strings.Contains(string(body), metric) {
+			t.Errorf("expected metric %s to be registered, got:\n%s", metric, body)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cabundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	cminformers "github.com/jetstack/cert-manager/pkg/client/informers/externalversions"
+	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	// ControllerName is the string used to refer to this controller
+	// when enabling or disabling it from command line flags.
+	ControllerName = "ca-bundle"
+
+	// AllNamespaces can be given as a namespace to maintain the ConfigMap in
+	// every namespace in the cluster.
+	AllNamespaces = "*"
+
+	// ClusterIssuerLabelKey is the label set on managed ConfigMaps, naming the
+	// ClusterIssuer whose CA certificates they contain.
+	ClusterIssuerLabelKey = "cert-manager.io/ca-bundle-cluster-issuer"
+)
+
+// This controller keeps a ConfigMap containing the CA certificates of a
+// designated CA ClusterIssuer up to date in one or more namespaces, so that
+// workloads can trust certificates issued by it. Items in the queue are the
+// names of the namespaces that the ConfigMap should be maintained in.
+type controller struct {
+	clusterIssuerLister cmlisters.ClusterIssuerLister
+	secretLister        corelisters.SecretLister
+	configMapLister     corelisters.ConfigMapLister
+	namespaceLister     corelisters.NamespaceLister
+
+	client kubernetes.Interface
+	queue  workqueue.RateLimitingInterface
+	log    logr.Logger
+
+	clusterResourceNamespace string
+	issuerName               string
+	configMapName            string
+	namespaces               []string
+	allNamespaces            bool
+}
+
+func NewController(
+	log logr.Logger,
+	kubeClient kubernetes.Interface,
+	factory informers.SharedInformerFactory,
+	cmFactory cminformers.SharedInformerFactory,
+	clusterResourceNamespace string,
+	opts controllerpkg.CABundleOptions,
+) (*controller, workqueue.RateLimitingInterface, []cache.InformerSynced) {
+	// create a queue used to queue up items to be processed
+	queue := workqueue.NewNamedRateLimitingQueue(controllerpkg.DefaultItemBasedRateLimiter(), ControllerName)
+
+	c := &controller{
+		client:                   kubeClient,
+		queue:                    queue,
+		log:                      log,
+		clusterResourceNamespace: clusterResourceNamespace,
+		issuerName:               opts.ClusterIssuerName,
+		configMapName:            opts.ConfigMapName,
+	}
+	for _, ns := range opts.Namespaces {
+		if ns == AllNamespaces {
+			c.allNamespaces = true
+			continue
+		}
+		c.namespaces = append(c.namespaces, ns)
+	}
+	if !c.allNamespaces && len(c.namespaces) == 0 {
+		c.namespaces = []string{clusterResourceNamespace}
+	}
+
+	// obtain references to all the informers used by this controller
+	clusterIssuerInformer := cmFactory.Certmanager().V1().ClusterIssuers()
+	secretsInformer := factory.Core().V1().Secrets()
+	configMapsInformer := factory.Core().V1().ConfigMaps()
+
+	clusterIssuerInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{WorkFunc: c.handleClusterIssuer})
+	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{WorkFunc: c.handleSecret})
+	configMapsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{WorkFunc: c.handleConfigMap})
+
+	// build a list of InformerSynced functions that will be returned by the Register method.
+	// the controller will only begin processing items once all of these informers have synced.
+	mustSync := []cache.InformerSynced{
+		clusterIssuerInformer.Informer().HasSynced,
+		secretsInformer.Informer().HasSynced,
+		configMapsInformer.Informer().HasSynced,
+	}
+
+	c.clusterIssuerLister = clusterIssuerInformer.Lister()
+	c.secretLister = secretsInformer.Lister()
+	c.configMapLister = configMapsInformer.Lister()
+
+	// Namespaces only need to be watched if the ConfigMap is maintained in
+	// all of them, so that it is created in new namespaces.
+	if c.allNamespaces {
+		namespaceInformer := factory.Core().V1().Namespaces()
+		namespaceInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: queue})
+		mustSync = append(mustSync, namespaceInformer.Informer().HasSynced)
+		c.namespaceLister = namespaceInformer.Lister()
+	}
+
+	return c, queue, mustSync
+}
+
+// handleClusterIssuer re-syncs the ConfigMap in all namespaces when the
+// designated ClusterIssuer changes.
+func (c *controller) handleClusterIssuer(obj interface{}) {
+	issuer, ok := obj.(*cmapi.ClusterIssuer)
+	if !ok || issuer.Name != c.issuerName {
+		return
+	}
+	c.enqueueAll()
+}
+
+// handleSecret re-syncs the ConfigMap in all namespaces when the Secret
+// containing the designated ClusterIssuer's CA changes, e.g. on rotation.
+func (c *controller) handleSecret(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || secret.Namespace != c.clusterResourceNamespace {
+		return
+	}
+	issuer, err := c.clusterIssuerLister.Get(c.issuerName)
+	if err != nil || issuer.Spec.CA == nil || issuer.Spec.CA.SecretName != secret.Name {
+		return
+	}
+	c.enqueueAll()
+}
+
+// handleConfigMap re-syncs the ConfigMap in its namespace when it is modified
+// or deleted, so that changes made to it by other actors are reverted.
+func (c *controller) handleConfigMap(obj interface{}) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok || cm.Name != c.configMapName || !c.managesNamespace(cm.Namespace) {
+		return
+	}
+	c.queue.Add(cm.Namespace)
+}
+
+// managesNamespace returns true if the ConfigMap is maintained in the given
+// namespace.
+func (c *controller) managesNamespace(namespace string) bool {
+	if c.allNamespaces {
+		return true
+	}
+	for _, ns := range c.namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *controller) enqueueAll() {
+	if !c.allNamespaces {
+		for _, ns := range c.namespaces {
+			c.queue.Add(ns)
+		}
+		return
+	}
+
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		c.log.Error(err, "failed to list namespaces")
+		return
+	}
+	for _, ns := range namespaces {
+		c.queue.Add(ns.Name)
+	}
+}
+
+func (c *controller) ProcessItem(ctx context.Context, key string) error {
+	// Set context deadline for full sync in 10 seconds
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	log := c.log.WithValues("namespace", key, "clusterissuer", c.issuerName)
+
+	issuer, err := c.clusterIssuerLister.Get(c.issuerName)
+	if apierrors.IsNotFound(err) {
+		log.V(logf.DebugLevel).Info("ClusterIssuer not found, not updating CA bundle")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if issuer.Spec.CA == nil {
+		log.Error(nil, "ClusterIssuer is not a CA issuer, not updating CA bundle")
+		return nil
+	}
+
+	secret, err := c.secretLister.Secrets(c.clusterResourceNamespace).Get(issuer.Spec.CA.SecretName)
+	if apierrors.IsNotFound(err) {
+		log.V(logf.DebugLevel).Info("CA Secret not found, not updating CA bundle", "secret", issuer.Spec.CA.SecretName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	bundle, err := caBundle(secret)
+	if err != nil {
+		// The Secret will be re-synced when it is fixed.
+		log.Error(err, "failed to read CA certificates from Secret", "secret", secret.Name)
+		return nil
+	}
+
+	return c.updateConfigMap(ctx, key, bundle)
+}
+
+// updateConfigMap creates or updates the ConfigMap in the given namespace so
+// that it contains the given CA bundle.
+func (c *controller) updateConfigMap(ctx context.Context, namespace string, bundle []byte) error {
+	existing, err := c.configMapLister.ConfigMaps(namespace).Get(c.configMapName)
+	if apierrors.IsNotFound(err) {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.configMapName,
+				Namespace: namespace,
+				Labels:    map[string]string{ClusterIssuerLabelKey: c.issuerName},
+			},
+			Data: map[string]string{cmmeta.TLSCAKey: string(bundle)},
+		}
+		_, err = c.client.CoreV1().ConfigMaps(namespace).Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.Data[cmmeta.TLSCAKey] == string(bundle) && existing.Labels[ClusterIssuerLabelKey] == c.issuerName {
+		return nil
+	}
+
+	cm := existing.DeepCopy()
+	if cm.Labels == nil {
+		cm.Labels = make(map[string]string)
+	}
+	cm.Labels[ClusterIssuerLabelKey] = c.issuerName
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[cmmeta.TLSCAKey] = string(bundle)
+	_, err = c.client.CoreV1().ConfigMaps(namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
+
+// caBundle returns a PEM bundle of the CA certificates in the given CA issuer
+// Secret: the signing certificate and any intermediates in tls.crt, followed
+// by the root in ca.crt if it is not already included.
+func caBundle(secret *corev1.Secret) ([]byte, error) {
+	var certs []*x509.Certificate
+	for _, key := range []string{corev1.TLSCertKey, cmmeta.TLSCAKey} {
+		data := secret.Data[key]
+		if len(data) == 0 {
+			continue
+		}
+		chain, err := pki.DecodeX509CertificateChainBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %q: %w", key, err)
+		}
+		for _, cert := range chain {
+			if !containsCertificate(certs, cert) {
+				certs = append(certs, cert)
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in %q or %q", corev1.TLSCertKey, cmmeta.TLSCAKey)
+	}
+
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
+
+// controllerWrapper wraps the `controller` structure to make it implement
+// the controllerpkg.queueingController interface
+type controllerWrapper struct {
+	*controller
+}
+
+func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.RateLimitingInterface, []cache.InformerSynced, error) {
+	if ctx.CABundleOptions.ClusterIssuerName == "" {
+		return nil, nil, fmt.Errorf("the %s controller requires a ClusterIssuer name to be configured", ControllerName)
+	}
+
+	// construct a new named logger to be reused throughout the controller
+	log := logf.FromContext(ctx.RootContext, ControllerName)
+
+	ctrl, queue, mustSync := NewController(log,
+		ctx.Client,
+		ctx.KubeSharedInformerFactory,
+		ctx.SharedInformerFactory,
+		ctx.IssuerOptions.ClusterResourceNamespace,
+		ctx.CABundleOptions,
+	)
+	c.controller = ctrl
+
+	return queue, mustSync, nil
+}
+
+func init() {
+	controllerpkg.Register(ControllerName, func(ctx *controllerpkg.Context) (controllerpkg.Interface, error) {
+		return controllerpkg.NewBuilder(ctx, ControllerName).
+			For(&controllerWrapper{}).
+			Complete()
+	})
+}
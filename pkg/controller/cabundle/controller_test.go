@@ -0,0 +1,248 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cabundle
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	coretesting "k8s.io/client-go/testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+func generateCACert(t *testing.T, name string, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, []byte, crypto.Signer) {
+	key, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		Version:               3,
+		BasicConstraintsValid: true,
+		SerialNumber:          big.NewInt(0),
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore: time.Now(),
+		NotAfter:  time.Now().Add(time.Minute),
+		KeyUsage:  x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		PublicKey: key.Public(),
+		IsCA:      true,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	pem, cert, err := pki.SignCertificate(tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, pem, key
+}
+
+func TestProcessItem(t *testing.T) {
+	rootCert, rootPEM, rootKey := generateCACert(t, "root", nil, nil)
+	_, intermediatePEM, _ := generateCACert(t, "intermediate", rootCert, rootKey)
+	_, rotatedPEM, _ := generateCACert(t, "rotated", nil, nil)
+
+	issuer := gen.ClusterIssuer("ca-issuer", gen.SetIssuerCASecretName("ca-secret"))
+	caSecret := func(tlsCrt, caCrt []byte) *corev1.Secret {
+		return gen.Secret("ca-secret",
+			gen.SetSecretNamespace("cert-manager"),
+			gen.SetSecretData(map[string][]byte{
+				corev1.TLSCertKey: tlsCrt,
+				cmmeta.TLSCAKey:   caCrt,
+			}),
+		)
+	}
+	configMap := func(bundle []byte) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ca-bundle",
+				Namespace: "testns",
+				Labels:    map[string]string{ClusterIssuerLabelKey: "ca-issuer"},
+			},
+			Data: map[string]string{cmmeta.TLSCAKey: string(bundle)},
+		}
+	}
+
+	tests := map[string]struct {
+		issuer           *cmapi.ClusterIssuer
+		existingObjects  []runtime.Object
+		expectedActions  []testpkg.Action
+		expectedErrorMsg string
+	}{
+		"should do nothing if the ClusterIssuer does not exist": {
+			existingObjects: []runtime.Object{caSecret(rootPEM, rootPEM)},
+		},
+		"should do nothing if the ClusterIssuer is not a CA issuer": {
+			issuer:          gen.ClusterIssuer("ca-issuer", gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{})),
+			existingObjects: []runtime.Object{caSecret(rootPEM, rootPEM)},
+		},
+		"should do nothing if the CA Secret does not exist": {
+			issuer: issuer,
+		},
+		"should do nothing if the CA Secret does not contain certificates": {
+			issuer:          issuer,
+			existingObjects: []runtime.Object{caSecret(nil, []byte("not a certificate"))},
+		},
+		"should create the ConfigMap if it does not exist": {
+			issuer:          issuer,
+			existingObjects: []runtime.Object{caSecret(rootPEM, rootPEM)},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewCreateAction(corev1.SchemeGroupVersion.WithResource("configmaps"), "testns", configMap(rootPEM))),
+			},
+		},
+		"should include both the intermediate and root certificates in the ConfigMap": {
+			issuer:          issuer,
+			existingObjects: []runtime.Object{caSecret(append(intermediatePEM, rootPEM...), rootPEM)},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewCreateAction(corev1.SchemeGroupVersion.WithResource("configmaps"), "testns", configMap(append(intermediatePEM, rootPEM...)))),
+			},
+		},
+		"should update the ConfigMap if the CA has been rotated": {
+			issuer:          issuer,
+			existingObjects: []runtime.Object{caSecret(rotatedPEM, rotatedPEM), configMap(rootPEM)},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewUpdateAction(corev1.SchemeGroupVersion.WithResource("configmaps"), "testns", configMap(rotatedPEM))),
+			},
+		},
+		"should not update the ConfigMap if it is up to date": {
+			issuer:          issuer,
+			existingObjects: []runtime.Object{caSecret(rootPEM, rootPEM), configMap(rootPEM)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{
+				T:               t,
+				KubeObjects:     test.existingObjects,
+				ExpectedActions: test.expectedActions,
+				Context: &controllerpkg.Context{
+					RootContext: context.Background(),
+					IssuerOptions: controllerpkg.IssuerOptions{
+						ClusterResourceNamespace: "cert-manager",
+					},
+					CABundleOptions: controllerpkg.CABundleOptions{
+						ClusterIssuerName: "ca-issuer",
+						ConfigMapName:     "ca-bundle",
+						Namespaces:        []string{"testns"},
+					},
+				},
+			}
+			if test.issuer != nil {
+				builder.CertManagerObjects = append(builder.CertManagerObjects, test.issuer)
+			}
+			builder.Init()
+
+			w := &controllerWrapper{}
+			if _, _, err := w.Register(builder.Context); err != nil {
+				t.Fatal(err)
+			}
+
+			builder.Start()
+			defer builder.Stop()
+
+			err := w.controller.ProcessItem(context.Background(), "testns")
+			switch {
+			case err != nil:
+				if test.expectedErrorMsg != err.Error() {
+					t.Errorf("error text did not match, got=%s, exp=%s", err.Error(), test.expectedErrorMsg)
+				}
+			default:
+				if test.expectedErrorMsg != "" {
+					t.Errorf("got no error but expected: %s", test.expectedErrorMsg)
+				}
+			}
+
+			if err := builder.AllActionsExecuted(); err != nil {
+				builder.T.Error(err)
+			}
+		})
+	}
+}
+
+func TestHandleConfigMap(t *testing.T) {
+	tests := map[string]struct {
+		namespaces []string
+		configMap  *corev1.ConfigMap
+		expected   []string
+	}{
+		"should enqueue the namespace of a managed ConfigMap": {
+			namespaces: []string{"testns"},
+			configMap:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "ca-bundle"}},
+			expected:   []string{"testns"},
+		},
+		"should enqueue a managed ConfigMap in any namespace if all namespaces are managed": {
+			namespaces: []string{AllNamespaces},
+			configMap:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "ca-bundle"}},
+			expected:   []string{"other"},
+		},
+		"should not enqueue a ConfigMap with a different name": {
+			namespaces: []string{"testns"},
+			configMap:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "other"}},
+		},
+		"should not enqueue a ConfigMap in a namespace that is not managed": {
+			namespaces: []string{"testns"},
+			configMap:  &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "ca-bundle"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{T: t}
+			builder.Init()
+			c, queue, _ := NewController(logf.Log, builder.Client, builder.KubeSharedInformerFactory, builder.SharedInformerFactory, "cert-manager",
+				controllerpkg.CABundleOptions{
+					ClusterIssuerName: "ca-issuer",
+					ConfigMapName:     "ca-bundle",
+					Namespaces:        test.namespaces,
+				},
+			)
+
+			c.handleConfigMap(test.configMap)
+
+			var got []string
+			for queue.Len() > 0 {
+				item, _ := queue.Get()
+				got = append(got, item.(string))
+				queue.Done(item)
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("unexpected queued items, exp=%v, got=%v", test.expected, got)
+			}
+		})
+	}
+}
@@ -32,8 +32,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 )
 
 // caDataSource knows how to extract CA data given a provided InjectTarget.
@@ -131,8 +131,10 @@ func (c *certificateDataSource) ReadCA(ctx context.Context, log logr.Logger, met
 		return nil, nil
 	}
 
-	// inject the CA data
-	caData, hasCAData := secret.Data[cmmeta.TLSCAKey]
+	// inject the CA data, which may be stored under a key other than ca.crt
+	// if the Certificate sets spec.secretKeys
+	_, _, caKey := apiutil.SecretKeyNamesForSecret(&secret)
+	caData, hasCAData := secret.Data[caKey]
 	if !hasCAData {
 		log.Error(nil, "certificate has no CA data")
 		// don't requeue, we'll get called when the secret gets updated
@@ -208,8 +210,10 @@ func (c *secretDataSource) ReadCA(ctx context.Context, log logr.Logger, metaObj
 		return nil, nil
 	}
 
-	// inject the CA data
-	caData, hasCAData := secret.Data[cmmeta.TLSCAKey]
+	// inject the CA data, which may be stored under a key other than ca.crt
+	// if the Certificate sets spec.secretKeys
+	_, _, caKey := apiutil.SecretKeyNamesForSecret(&secret)
+	caData, hasCAData := secret.Data[caKey]
 	if !hasCAData {
 		log.Error(nil, "certificate has no CA data")
 		// don't requeue, we'll get called when the secret gets updated
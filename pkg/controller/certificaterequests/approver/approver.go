@@ -19,16 +19,23 @@ package approver
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 )
 
@@ -38,9 +45,15 @@ const (
 
 // Controller is a CertificateRequest controller which manages the "Approved"
 // condition. In the absence of any automated policy engine, this controller
-// will _always_ set the "Approved" condition to True. All CertificateRequest
-// signing controllers should wait until the "Approved" condition is set to
-// True before processing.
+// will set the "Approved" condition to True, unless the request contains DNS
+// names that are not permitted by the allowedDomains of the referenced issuer,
+// or SPIFFE IDs outside of its SPIFFE trust domain, in which case the
+// "Denied" condition is set to True. If trusted
+// ServiceAccounts are configured, only requests created by them are
+// approved, and requests created by anyone else are left for manual approval
+// and optionally denied after a timeout. All CertificateRequest signing
+// controllers should wait until the "Approved" condition is set to True
+// before processing.
 type Controller struct {
 	// logger to be used by this controller
 	log logr.Logger
@@ -48,9 +61,30 @@ type Controller struct {
 	certificateRequestLister cmlisters.CertificateRequestLister
 	cmClient                 cmclient.Interface
 
+	// helper is used to read the issuer referenced by a CertificateRequest
+	helper issuer.Helper
+
 	recorder record.EventRecorder
+	clock    clock.Clock
+
+	// trustedUsernames are the usernames of the ServiceAccounts whose
+	// requests are approved. If empty, all requests are approved.
+	trustedUsernames sets.String
+	// untrustedRequestTimeout is how long requests of untrusted requesters
+	// are left for manual approval before they are denied. If zero, they are
+	// never denied.
+	untrustedRequestTimeout time.Duration
 
 	queue workqueue.RateLimitingInterface
+
+	// metrics is used to record how long requests wait for approval
+	metrics approvalMetrics
+}
+
+// approvalMetrics records how long CertificateRequests wait for approval.
+// It is implemented by *metrics.Metrics.
+type approvalMetrics interface {
+	ObserveCertificateRequestApprovalWait(time.Duration)
 }
 
 func init() {
@@ -71,10 +105,34 @@ func (c *Controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	certificateRequestInformer := ctx.SharedInformerFactory.Certmanager().V1().CertificateRequests()
 	mustSync := []cache.InformerSynced{certificateRequestInformer.Informer().HasSynced}
 	certificateRequestInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: c.queue})
+	// Requests may be approved by this controller or by an external
+	// approver, so approvals are observed as they are seen by the informer.
+	certificateRequestInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{UpdateFunc: c.observeApproval})
 
 	c.certificateRequestLister = certificateRequestInformer.Lister()
+
+	issuerInformer := ctx.SharedInformerFactory.Certmanager().V1().Issuers()
+	mustSync = append(mustSync, issuerInformer.Informer().HasSynced)
+	var clusterIssuerLister cmlisters.ClusterIssuerLister
+	// ClusterIssuers can only be read if cert-manager is not scoped to a
+	// single namespace
+	if ctx.Namespace == "" {
+		clusterIssuerInformer := ctx.SharedInformerFactory.Certmanager().V1().ClusterIssuers()
+		mustSync = append(mustSync, clusterIssuerInformer.Informer().HasSynced)
+		clusterIssuerLister = clusterIssuerInformer.Lister()
+	}
+	c.helper = issuer.NewHelper(issuerInformer.Lister(), clusterIssuerLister)
 	c.cmClient = ctx.CMClient
 	c.recorder = ctx.Recorder
+	c.clock = ctx.Clock
+	c.metrics = ctx.Metrics
+
+	c.trustedUsernames = sets.NewString()
+	for _, sa := range ctx.ApproverOptions.TrustedServiceAccounts {
+		namespace, name := splitServiceAccount(sa)
+		c.trustedUsernames.Insert(serviceAccountUsername(namespace, name))
+	}
+	c.untrustedRequestTimeout = ctx.ApproverOptions.UntrustedRequestTimeout
 
 	c.log.V(logf.DebugLevel).Info("certificate request approver controller registered")
 
@@ -104,3 +162,41 @@ func (c *Controller) ProcessItem(ctx context.Context, key string) error {
 	ctx = logf.NewContext(ctx, logf.WithResource(log, cr))
 	return c.Sync(ctx, cr)
 }
+
+// splitServiceAccount splits a ServiceAccount given in the form
+// '<namespace>:<name>' into its namespace and name.
+func splitServiceAccount(sa string) (string, string) {
+	parts := strings.SplitN(sa, ":", 2)
+	if len(parts) != 2 {
+		return "", sa
+	}
+	return parts[0], parts[1]
+}
+
+// serviceAccountUsername returns the username that requests authenticated as
+// the given ServiceAccount are made with.
+func serviceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// observeApproval records how long a CertificateRequest waited for approval,
+// measured from its creation, when it transitions to Approved.
+func (c *Controller) observeApproval(oldObj, newObj interface{}) {
+	oldCR, ok := oldObj.(*cmapi.CertificateRequest)
+	if !ok {
+		return
+	}
+	newCR, ok := newObj.(*cmapi.CertificateRequest)
+	if !ok {
+		return
+	}
+	if apiutil.CertificateRequestIsApproved(oldCR) || !apiutil.CertificateRequestIsApproved(newCR) {
+		return
+	}
+
+	approvedAt := c.clock.Now()
+	if cond := apiutil.GetCertificateRequestCondition(newCR, cmapi.CertificateRequestConditionApproved); cond != nil && cond.LastTransitionTime != nil {
+		approvedAt = cond.LastTransitionTime.Time
+	}
+	c.metrics.ObserveCertificateRequestApprovalWait(approvedAt.Sub(newCR.CreationTimestamp.Time))
+}
@@ -18,6 +18,9 @@ package approver
 
 import (
 	"context"
+	"crypto/x509"
+	"net/url"
+	"reflect"
 	"testing"
 	"time"
 
@@ -29,12 +32,38 @@ import (
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
 func TestProcessItem(t *testing.T) {
 	// now time is the current time at the start of the test (the clock is fixed)
 	now := time.Now()
 	metaNow := metav1.NewTime(now)
+
+	inPolicyCSR, _, err := gen.CSR(x509.RSA, gen.SetCSRDNSNames("foo.example.com", "bar.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outOfPolicyCSR, _, err := gen.CSR(x509.RSA, gen.SetCSRDNSNames("foo.example.com", "example.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowedDomainsIssuer := gen.Issuer("ca", gen.SetIssuerNamespace("testns"),
+		gen.SetIssuerCASecretName("ca"), gen.SetIssuerAllowedDomains("*.example.com"))
+	deniedMessage := `Certificate request has been denied by cert-manager.io: the DNS names [example.org] are not permitted by the allowedDomains [*.example.com] of Issuer "ca"`
+
+	inTrustDomainCSR, _, err := gen.CSR(x509.RSA, gen.SetCSRURIs(mustParseURL(t, "spiffe://example.org/ns/testns/sa/app"), mustParseURL(t, "https://example.com")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outOfTrustDomainCSR, _, err := gen.CSR(x509.RSA, gen.SetCSRURIs(mustParseURL(t, "spiffe://example.org/ns/testns/sa/app"), mustParseURL(t, "spiffe://evil.org/ns/testns/sa/app")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trustDomainIssuer := gen.Issuer("ca", gen.SetIssuerNamespace("testns"),
+		gen.SetIssuerCASecretName("ca"), gen.SetIssuerSPIFFETrustDomain("example.org"))
+	trustDomainDeniedMessage := `Certificate request has been denied by cert-manager.io: the SPIFFE IDs [spiffe://evil.org/ns/testns/sa/app] do not belong to the trust domain "example.org" of Issuer "ca"`
+	untrustedDeniedMessage := `Certificate request has been denied by cert-manager.io: requester "system:serviceaccount:testns:other" is not trusted and the request was not approved within 1h0m0s`
 	tests := map[string]struct {
 		// key that should be passed to ProcessItem.
 		// if not set, the 'namespace/name' of the 'CertificateRequest' field will be used.
@@ -45,6 +74,12 @@ func TestProcessItem(t *testing.T) {
 		// if not set, the 'key' will be passed to ProcessItem instead.
 		request *cmapi.CertificateRequest
 
+		// issuer, if set, is the Issuer referenced by the CertificateRequest.
+		issuer *cmapi.Issuer
+
+		// approverOptions are the options the controller is registered with.
+		approverOptions controllerpkg.ApproverOptions
+
 		// expectedEvent, if set, is an 'event string' that is expected to be fired.
 		expectedEvent string
 
@@ -165,6 +200,150 @@ func TestProcessItem(t *testing.T) {
 			},
 			expectedEvent: "Normal cert-manager.io Certificate request has been approved by cert-manager.io",
 		},
+		"approve CertificateRequest if its DNS names are permitted by the Issuer's allowedDomains": {
+			request: gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(inPolicyCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+			issuer: allowedDomainsIssuer,
+			expectedConditions: []cmapi.CertificateRequestCondition{
+				{
+					Type:               cmapi.CertificateRequestConditionApproved,
+					Status:             cmmeta.ConditionTrue,
+					Reason:             "cert-manager.io",
+					Message:            ApprovedMessage,
+					LastTransitionTime: &metaNow,
+				},
+			},
+			expectedEvent: "Normal cert-manager.io Certificate request has been approved by cert-manager.io",
+		},
+		"deny CertificateRequest if a DNS name is not permitted by the Issuer's allowedDomains": {
+			request: gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(outOfPolicyCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+			issuer: allowedDomainsIssuer,
+			expectedConditions: []cmapi.CertificateRequestCondition{
+				{
+					Type:               cmapi.CertificateRequestConditionDenied,
+					Status:             cmmeta.ConditionTrue,
+					Reason:             "DomainNotAllowed",
+					Message:            deniedMessage,
+					LastTransitionTime: &metaNow,
+				},
+			},
+			expectedEvent: "Warning DomainNotAllowed " + deniedMessage,
+		},
+		"approve CertificateRequest if its SPIFFE IDs belong to the Issuer's SPIFFE trust domain": {
+			request: gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(inTrustDomainCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+			issuer: trustDomainIssuer,
+			expectedConditions: []cmapi.CertificateRequestCondition{
+				{
+					Type:               cmapi.CertificateRequestConditionApproved,
+					Status:             cmmeta.ConditionTrue,
+					Reason:             "cert-manager.io",
+					Message:            ApprovedMessage,
+					LastTransitionTime: &metaNow,
+				},
+			},
+			expectedEvent: "Normal cert-manager.io Certificate request has been approved by cert-manager.io",
+		},
+		"deny CertificateRequest if a SPIFFE ID does not belong to the Issuer's SPIFFE trust domain": {
+			request: gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(outOfTrustDomainCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+			issuer: trustDomainIssuer,
+			expectedConditions: []cmapi.CertificateRequestCondition{
+				{
+					Type:               cmapi.CertificateRequestConditionDenied,
+					Status:             cmmeta.ConditionTrue,
+					Reason:             "SPIFFETrustDomainNotAllowed",
+					Message:            trustDomainDeniedMessage,
+					LastTransitionTime: &metaNow,
+				},
+			},
+			expectedEvent: "Warning SPIFFETrustDomainNotAllowed " + trustDomainDeniedMessage,
+		},
+		"approve CertificateRequest if the referenced Issuer does not exist": {
+			request: gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(outOfPolicyCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+			expectedConditions: []cmapi.CertificateRequestCondition{
+				{
+					Type:               cmapi.CertificateRequestConditionApproved,
+					Status:             cmmeta.ConditionTrue,
+					Reason:             "cert-manager.io",
+					Message:            ApprovedMessage,
+					LastTransitionTime: &metaNow,
+				},
+			},
+			expectedEvent: "Normal cert-manager.io Certificate request has been approved by cert-manager.io",
+		},
+		"approve CertificateRequest if it was created by a trusted ServiceAccount": {
+			request: gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(inPolicyCSR),
+				gen.SetCertificateRequestUsername("system:serviceaccount:cert-manager:cert-manager"),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+			approverOptions: controllerpkg.ApproverOptions{
+				TrustedServiceAccounts:  []string{"cert-manager:cert-manager"},
+				UntrustedRequestTimeout: time.Hour,
+			},
+			expectedConditions: []cmapi.CertificateRequestCondition{
+				{
+					Type:               cmapi.CertificateRequestConditionApproved,
+					Status:             cmmeta.ConditionTrue,
+					Reason:             "cert-manager.io",
+					Message:            ApprovedMessage,
+					LastTransitionTime: &metaNow,
+				},
+			},
+			expectedEvent: "Normal cert-manager.io Certificate request has been approved by cert-manager.io",
+		},
+		"do nothing if CertificateRequest was created by an untrusted requester and no timeout is set": {
+			request: gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(inPolicyCSR),
+				gen.SetCertificateRequestUsername("system:serviceaccount:testns:other"),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+			approverOptions: controllerpkg.ApproverOptions{
+				TrustedServiceAccounts: []string{"cert-manager:cert-manager"},
+			},
+		},
+		"do nothing if CertificateRequest was created by an untrusted requester within the timeout": {
+			request: gen.CertificateRequestFrom(gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(inPolicyCSR),
+				gen.SetCertificateRequestUsername("system:serviceaccount:testns:other"),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+				func(cr *cmapi.CertificateRequest) {
+					cr.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute * 30))
+				}),
+			approverOptions: controllerpkg.ApproverOptions{
+				TrustedServiceAccounts:  []string{"cert-manager:cert-manager"},
+				UntrustedRequestTimeout: time.Hour,
+			},
+		},
+		"deny CertificateRequest if it was created by an untrusted requester and not approved within the timeout": {
+			request: gen.CertificateRequestFrom(gen.CertificateRequest("test", gen.SetCertificateRequestNamespace("testns"),
+				gen.SetCertificateRequestCSR(inPolicyCSR),
+				gen.SetCertificateRequestUsername("system:serviceaccount:testns:other"),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "ca"})),
+				func(cr *cmapi.CertificateRequest) {
+					cr.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+				}),
+			approverOptions: controllerpkg.ApproverOptions{
+				TrustedServiceAccounts:  []string{"cert-manager:cert-manager"},
+				UntrustedRequestTimeout: time.Hour,
+			},
+			expectedConditions: []cmapi.CertificateRequestCondition{
+				{
+					Type:               cmapi.CertificateRequestConditionDenied,
+					Status:             cmmeta.ConditionTrue,
+					Reason:             ReasonRequesterNotTrusted,
+					Message:            untrustedDeniedMessage,
+					LastTransitionTime: &metaNow,
+				},
+			},
+			expectedEvent: "Warning RequesterNotTrusted " + untrustedDeniedMessage,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -176,7 +355,11 @@ func TestProcessItem(t *testing.T) {
 			if test.request != nil {
 				builder.CertManagerObjects = append(builder.CertManagerObjects, test.request)
 			}
+			if test.issuer != nil {
+				builder.CertManagerObjects = append(builder.CertManagerObjects, test.issuer)
+			}
 			builder.Init()
+			builder.Context.ApproverOptions = test.approverOptions
 
 			c := new(Controller)
 			_, _, err := c.Register(builder.Context)
@@ -238,3 +421,72 @@ func TestProcessItem(t *testing.T) {
 		})
 	}
 }
+
+type fakeApprovalMetrics struct {
+	waits []time.Duration
+}
+
+func (f *fakeApprovalMetrics) ObserveCertificateRequestApprovalWait(wait time.Duration) {
+	f.waits = append(f.waits, wait)
+}
+
+func TestObserveApproval(t *testing.T) {
+	now := time.Now()
+	created := metav1.NewTime(now.Add(-time.Hour))
+	approvedAt := metav1.NewTime(now.Add(-time.Minute))
+
+	pending := gen.CertificateRequest("cr", gen.SetCertificateRequestNamespace("testns"))
+	pending.CreationTimestamp = created
+	approved := gen.CertificateRequestFrom(pending,
+		gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+			Type:               cmapi.CertificateRequestConditionApproved,
+			Status:             cmmeta.ConditionTrue,
+			Reason:             "cert-manager.io",
+			LastTransitionTime: &approvedAt,
+		}),
+	)
+	denied := gen.CertificateRequestFrom(pending,
+		gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+			Type:   cmapi.CertificateRequestConditionDenied,
+			Status: cmmeta.ConditionTrue,
+			Reason: "Foo",
+		}),
+	)
+
+	tests := map[string]struct {
+		old, new *cmapi.CertificateRequest
+		expected []time.Duration
+	}{
+		"records the time from creation to approval when a request is approved": {
+			old:      pending,
+			new:      approved,
+			expected: []time.Duration{approvedAt.Sub(created.Time)},
+		},
+		"does not record anything if the request was already approved": {
+			old: approved,
+			new: approved,
+		},
+		"does not record anything if the request is denied": {
+			old: pending,
+			new: denied,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &fakeApprovalMetrics{}
+			c := &Controller{clock: fakeclock.NewFakeClock(now), metrics: m}
+			c.observeApproval(test.old, test.new)
+			if !reflect.DeepEqual(test.expected, m.waits) {
+				t.Errorf("unexpected observations, exp=%v, got=%v", test.expected, m.waits)
+			}
+		})
+	}
+}
+
+func mustParseURL(t *testing.T, s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
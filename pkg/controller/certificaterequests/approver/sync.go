@@ -18,23 +18,36 @@ package approver
 
 import (
 	"context"
+	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/controller/certificaterequests/util"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 )
 
 const (
 	ApprovedMessage = "Certificate request has been approved by cert-manager.io"
+
+	// ReasonRequesterNotTrusted is the reason used when a CertificateRequest
+	// created by a requester that is not trusted has not been manually
+	// approved within the untrusted request timeout.
+	ReasonRequesterNotTrusted = "RequesterNotTrusted"
 )
 
 // Sync will set the "Approved" condition to True on synced
-// CertificateRequests. If the "Denied", "Approved" or "Ready" condition
-// already exists, exit early.
+// CertificateRequests, or the "Denied" condition to True if the request is
+// not permitted by the allowedDomains or SPIFFE trust domain of the
+// referenced issuer. Requests that were not created by a trusted requester
+// are left for manual approval, and denied once the untrusted request
+// timeout has elapsed. If the "Denied",
+// "Approved" or "Ready" condition already exists, exit early.
 func (c *Controller) Sync(ctx context.Context, cr *cmapi.CertificateRequest) (err error) {
 	log := logf.FromContext(ctx, "approver")
 
@@ -52,6 +65,30 @@ func (c *Controller) Sync(ctx context.Context, cr *cmapi.CertificateRequest) (er
 		return nil
 	}
 
+	if len(c.trustedUsernames) > 0 && !c.trustedUsernames.Has(cr.Spec.Username) {
+		return c.syncUntrustedRequest(ctx, cr)
+	}
+
+	if reason, err := c.checkIssuerPolicy(ctx, cr); err != nil {
+		deniedMessage := fmt.Sprintf("Certificate request has been denied by cert-manager.io: %v", err)
+		apiutil.SetCertificateRequestCondition(cr,
+			cmapi.CertificateRequestConditionDenied,
+			cmmeta.ConditionTrue,
+			reason,
+			deniedMessage,
+		)
+
+		_, err = c.cmClient.CertmanagerV1().CertificateRequests(cr.Namespace).UpdateStatus(ctx, cr, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		c.recorder.Event(cr, corev1.EventTypeWarning, reason, deniedMessage)
+
+		log.V(logf.DebugLevel).Info("denied certificate request")
+
+		return nil
+	}
+
 	// Update the CertificateRequest approved condition to true.
 	apiutil.SetCertificateRequestCondition(cr,
 		cmapi.CertificateRequestConditionApproved,
@@ -71,3 +108,69 @@ func (c *Controller) Sync(ctx context.Context, cr *cmapi.CertificateRequest) (er
 
 	return nil
 }
+
+// syncUntrustedRequest leaves a CertificateRequest that was not created by a
+// trusted requester for manual approval, and sets the "Denied" condition to
+// True once it has not been approved within the untrusted request timeout.
+func (c *Controller) syncUntrustedRequest(ctx context.Context, cr *cmapi.CertificateRequest) error {
+	log := logf.FromContext(ctx, "approver")
+
+	if c.untrustedRequestTimeout <= 0 {
+		log.V(logf.DebugLevel).Info("certificate request was not created by a trusted requester, waiting for manual approval", "username", cr.Spec.Username)
+		return nil
+	}
+
+	if age := c.clock.Since(cr.CreationTimestamp.Time); age < c.untrustedRequestTimeout {
+		log.V(logf.DebugLevel).Info("certificate request was not created by a trusted requester, waiting for manual approval", "username", cr.Spec.Username)
+		key, err := controllerpkg.KeyFunc(cr)
+		if err != nil {
+			return err
+		}
+		c.queue.AddAfter(key, c.untrustedRequestTimeout-age)
+		return nil
+	}
+
+	deniedMessage := fmt.Sprintf("Certificate request has been denied by cert-manager.io: requester %q is not trusted and the request was not approved within %s", cr.Spec.Username, c.untrustedRequestTimeout)
+	apiutil.SetCertificateRequestCondition(cr,
+		cmapi.CertificateRequestConditionDenied,
+		cmmeta.ConditionTrue,
+		ReasonRequesterNotTrusted,
+		deniedMessage,
+	)
+
+	_, err := c.cmClient.CertmanagerV1().CertificateRequests(cr.Namespace).UpdateStatus(ctx, cr, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	c.recorder.Event(cr, corev1.EventTypeWarning, ReasonRequesterNotTrusted, deniedMessage)
+
+	log.V(logf.DebugLevel).Info("denied certificate request of untrusted requester")
+
+	return nil
+}
+
+// checkIssuerPolicy returns an error, and the reason to deny the request
+// with, if the CertificateRequest references a cert-manager issuer whose
+// allowedDomains do not permit the DNS names of the request, or whose SPIFFE
+// trust domain does not match the SPIFFE IDs of the request. Requests
+// referencing an issuer that cannot be read are not denied, as the policy is
+// also enforced by the signing controllers once the issuer exists.
+func (c *Controller) checkIssuerPolicy(ctx context.Context, cr *cmapi.CertificateRequest) (string, error) {
+	if !(cr.Spec.IssuerRef.Group == "" || cr.Spec.IssuerRef.Group == certmanager.GroupName) {
+		return "", nil
+	}
+
+	issuerObj, err := c.helper.GetGenericIssuer(cr.Spec.IssuerRef, cr.Namespace)
+	if err != nil {
+		logf.FromContext(ctx).V(logf.DebugLevel).Info("unable to read referenced issuer so not checking issuer policy", "error", err.Error())
+		return "", nil
+	}
+
+	if err := util.CheckAllowedDomains(issuerObj, cr); err != nil {
+		return util.ReasonDomainNotAllowed, err
+	}
+	if err := util.CheckSPIFFETrustDomain(issuerObj, cr); err != nil {
+		return util.ReasonSPIFFETrustDomainNotAllowed, err
+	}
+	return "", nil
+}
@@ -20,10 +20,14 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/record"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
@@ -41,6 +45,22 @@ const (
 	CRControllerName = "certificaterequests-issuer-ca"
 )
 
+const (
+	// CAExpiryPolicyAllow issues certificates that would outlive the signing
+	// CA certificate unchanged.
+	CAExpiryPolicyAllow = "Allow"
+
+	// CAExpiryPolicyClamp reduces the notAfter of certificates that would
+	// outlive the signing CA certificate to the notAfter of the CA, and
+	// fires an event on the CertificateRequest. Requests for which the
+	// clamped certificate would already be due for renewal are failed.
+	CAExpiryPolicyClamp = "Clamp"
+
+	// CAExpiryPolicyReject fails CertificateRequests for certificates that
+	// would outlive the signing CA certificate.
+	CAExpiryPolicyReject = "Reject"
+)
+
 type templateGenerator func(*cmapi.CertificateRequest) (*x509.Certificate, error)
 type signingFn func([]*x509.Certificate, crypto.Signer, *x509.Certificate) (pki.PEMBundle, error)
 
@@ -49,6 +69,7 @@ type CA struct {
 	secretsLister corelisters.SecretLister
 
 	reporter *crutil.Reporter
+	recorder record.EventRecorder
 
 	// Used for testing to get reproducible resulting certificates
 	templateGenerator templateGenerator
@@ -69,6 +90,7 @@ func NewCA(ctx *controllerpkg.Context) *CA {
 		issuerOptions:     ctx.IssuerOptions,
 		secretsLister:     ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
 		reporter:          crutil.NewReporter(ctx.Clock, ctx.Recorder),
+		recorder:          ctx.Recorder,
 		templateGenerator: pki.GenerateTemplateFromCertificateRequest,
 		signingFn:         pki.SignCSRTemplate,
 	}
@@ -91,7 +113,9 @@ func (c *CA) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerObj c
 		c.reporter.Pending(cr, err, "SecretMissing", message)
 		log.Error(err, message)
 
-		return nil, nil
+		// A missing Secret is a configuration error rather than a
+		// transient one, so retry with the workqueue's back-off.
+		return nil, err
 	}
 
 	if cmerrors.IsInvalidData(err) {
@@ -110,6 +134,53 @@ func (c *CA) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerObj c
 		return nil, err
 	}
 
+	// The signing Secret may contain cross-signed intermediates, in which
+	// case select the chain that terminates at the preferred root.
+	caCerts = pki.BuildCertificateChain(caCerts[0], caCerts[1:], issuerObj.GetSpec().CA.PreferredChain)
+
+	// During a rotation of the CA certificate, the chains of the other
+	// certificates for the same key are returned in the CA bundle too.
+	var rotationChains [][]*x509.Certificate
+	for _, rotationSecretName := range issuerObj.GetSpec().CA.RotationSecretNames {
+		rotationCerts, err := kube.SecretTLSCertChainAndCA(ctx, c.secretsLister, resourceNamespace, rotationSecretName)
+		if k8sErrors.IsNotFound(err) {
+			message := fmt.Sprintf("Referenced rotation secret %s/%s not found", resourceNamespace, rotationSecretName)
+
+			c.reporter.Pending(cr, err, "SecretMissing", message)
+			log.Error(err, message)
+
+			return nil, err
+		}
+
+		if cmerrors.IsInvalidData(err) {
+			message := fmt.Sprintf("Failed to parse rotation CA certificate from secret %s/%s", resourceNamespace, rotationSecretName)
+
+			c.reporter.Pending(cr, err, "SecretInvalidData", message)
+			log.Error(err, message)
+			return nil, nil
+		}
+
+		if err != nil {
+			message := fmt.Sprintf("Failed to get rotation CA certificate from secret %s/%s", resourceNamespace, rotationSecretName)
+			c.reporter.Pending(cr, err, "SecretGetError", message)
+			log.Error(err, message)
+			return nil, err
+		}
+
+		matches, err := pki.PublicKeyMatchesCertificate(caKey.Public(), rotationCerts[0])
+		if err == nil && !matches {
+			err = errors.New("the CA private key does not match the public key of the rotation CA certificate")
+		}
+		if err != nil {
+			message := fmt.Sprintf("Rotation CA certificate in secret %s/%s cannot be used with the CA's private key", resourceNamespace, rotationSecretName)
+			c.reporter.Pending(cr, err, "SecretInvalidData", message)
+			log.Error(err, message)
+			return nil, nil
+		}
+
+		rotationChains = append(rotationChains, pki.BuildCertificateChain(rotationCerts[0], rotationCerts[1:], issuerObj.GetSpec().CA.PreferredChain))
+	}
+
 	template, err := c.templateGenerator(cr)
 	if err != nil {
 		message := "Error generating certificate template"
@@ -118,9 +189,135 @@ func (c *CA) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerObj c
 		return nil, nil
 	}
 
+	renewBefore := crutil.RenewBefore(cr, template)
+	pki.RoundNotAfter(template, c.issuerOptions.NotAfterRounding, c.issuerOptions.NotAfterRoundUp, renewBefore)
+
+	if caNotAfter := caCerts[0].NotAfter; template.NotAfter.After(caNotAfter) {
+		switch c.issuerOptions.CAExpiryPolicy {
+		case CAExpiryPolicyClamp:
+			if !caNotAfter.After(template.NotBefore) {
+				err := fmt.Errorf("the CA certificate expires at %s, before the requested notBefore of %s",
+					caNotAfter.UTC().Format(time.RFC3339), template.NotBefore.UTC().Format(time.RFC3339))
+				message := "Requested certificate cannot be clamped to the CA's expiry"
+				c.reporter.Failed(cr, err, "CAExpiry", message)
+				log.Error(err, message)
+				return nil, nil
+			}
+			// A certificate that is due for renewal as soon as it is issued
+			// would be reissued, and clamped again, until the CA is rotated.
+			if caNotAfter.Sub(template.NotBefore) <= renewBefore {
+				err := fmt.Errorf("the CA certificate expires at %s, which is within the renewBefore of %s of the requested certificate",
+					caNotAfter.UTC().Format(time.RFC3339), renewBefore)
+				message := "Requested certificate clamped to the CA's expiry would be due for renewal immediately"
+				c.reporter.Failed(cr, err, "CAExpiry", message)
+				log.Error(err, message)
+				return nil, nil
+			}
+			message := fmt.Sprintf("Reduced the notAfter of the certificate from %s to %s, as it would otherwise be valid for longer than the CA certificate",
+				template.NotAfter.UTC().Format(time.RFC3339), caNotAfter.UTC().Format(time.RFC3339))
+			c.recorder.Event(cr, corev1.EventTypeWarning, "CAExpiryClamped", message)
+			log.V(logf.InfoLevel).Info(message)
+			template.NotAfter = caNotAfter
+		case CAExpiryPolicyReject:
+			err := fmt.Errorf("the certificate would be valid until %s, but the CA certificate expires at %s",
+				template.NotAfter.UTC().Format(time.RFC3339), caNotAfter.UTC().Format(time.RFC3339))
+			message := "Requested certificate would outlive the CA"
+			c.reporter.Failed(cr, err, "CAExpiry", message)
+			log.Error(err, message)
+			return nil, nil
+		}
+	}
+
+	if err := pki.SignatureAlgorithmMatchesKey(template.SignatureAlgorithm, caKey.Public()); err != nil {
+		message := "Requested signature algorithm cannot be used with the CA's private key"
+		c.reporter.Failed(cr, err, "SigningError", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+
 	template.CRLDistributionPoints = issuerObj.GetSpec().CA.CRLDistributionPoints
 	template.OCSPServer = issuerObj.GetSpec().CA.OCSPServers
 
+	if method := issuerObj.GetSpec().CA.SubjectKeyIdentifierMethod; method != "" {
+		template.SubjectKeyId, err = pki.SubjectKeyIdentifier(template.PublicKey, method)
+		if err != nil {
+			message := "Error deriving subject key identifier"
+			c.reporter.Failed(cr, err, "SigningError", message)
+			log.Error(err, message)
+			return nil, nil
+		}
+	}
+
+	if policies := issuerObj.GetSpec().CA.PolicyIdentifiers; len(policies) > 0 {
+		extension, err := pki.CertificatePoliciesExtension(policies)
+		if err != nil {
+			message := "Error building certificate policies extension"
+			c.reporter.Failed(cr, err, "SigningError", message)
+			log.Error(err, message)
+			return nil, nil
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, extension)
+	}
+
+	// the policy constraint extensions only constrain the paths of
+	// certificates issued by a CA, so are not added to leaf certificates
+	if template.IsCA {
+		if skipCerts := issuerObj.GetSpec().CA.InhibitAnyPolicy; skipCerts != nil {
+			extension, err := pki.InhibitAnyPolicyExtension(*skipCerts)
+			if err != nil {
+				message := "Error building inhibit any policy extension"
+				c.reporter.Failed(cr, err, "SigningError", message)
+				log.Error(err, message)
+				return nil, nil
+			}
+			template.ExtraExtensions = append(template.ExtraExtensions, extension)
+		}
+
+		if constraints := issuerObj.GetSpec().CA.PolicyConstraints; constraints != nil {
+			extension, err := pki.PolicyConstraintsExtension(*constraints)
+			if err != nil {
+				message := "Error building policy constraints extension"
+				c.reporter.Failed(cr, err, "SigningError", message)
+				log.Error(err, message)
+				return nil, nil
+			}
+			template.ExtraExtensions = append(template.ExtraExtensions, extension)
+		}
+	}
+
+	extensions, err := pki.CustomExtensionsFromAnnotations(cr.Annotations, issuerObj.GetSpec().CA.AllowedCustomExtensions)
+	if err != nil {
+		message := "Requested custom extensions are not permitted"
+		c.reporter.Failed(cr, err, "CustomExtensionsNotPermitted", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+	template.ExtraExtensions = append(template.ExtraExtensions, extensions...)
+
+	csrExtensions, err := pki.CustomExtensionsFromCSR(cr.Spec.Request, issuerObj.GetSpec().CA.AllowedCSRExtensions)
+	if err != nil {
+		message := "Extensions requested by the CSR are not permitted"
+		c.reporter.Failed(cr, err, "CustomExtensionsNotPermitted", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+	template.ExtraExtensions = append(template.ExtraExtensions, csrExtensions...)
+
+	if pki.OCSPNoCheckFromAnnotations(cr.Annotations) {
+		template.ExtraExtensions = append(template.ExtraExtensions, pki.OCSPNoCheckExtension())
+	}
+
+	if issuerObj.GetSpec().CA.LegacyCommonNameOnly && pki.OmitCommonNameSubjectAltName(template) {
+		log.V(logf.DebugLevel).Info("omitting the subjectAltName extension as the only name requested is the common name")
+	}
+
+	if err := pki.MarkSubjectAltNamesCritical(template, pki.SubjectAltNamesCriticalFromAnnotations(cr.Annotations)); err != nil {
+		message := "Error marking subject alternative names as critical"
+		c.reporter.Failed(cr, err, "SigningError", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+
 	bundle, err := c.signingFn(caCerts, caKey, template)
 	if err != nil {
 		message := "Error signing certificate"
@@ -129,6 +326,16 @@ func (c *CA) Sign(ctx context.Context, cr *cmapi.CertificateRequest, issuerObj c
 		return nil, err
 	}
 
+	if len(rotationChains) > 0 {
+		bundle.CAPEM, err = pki.EncodeX509CABundle(append([][]*x509.Certificate{caCerts}, rotationChains...)...)
+		if err != nil {
+			message := "Error encoding CA bundle"
+			c.reporter.Failed(cr, err, "SigningError", message)
+			log.Error(err, message)
+			return nil, err
+		}
+	}
+
 	log.V(logf.DebugLevel).Info("certificate issued")
 
 	return &issuerpkg.IssueResponse{
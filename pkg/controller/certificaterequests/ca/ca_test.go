@@ -28,17 +28,20 @@ import (
 	"errors"
 	"math"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientcorev1 "k8s.io/client-go/listers/core/v1"
 	coretesting "k8s.io/client-go/testing"
 	fakeclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
@@ -58,13 +61,14 @@ var (
 	fixedClock      = fakeclock.NewFakeClock(fixedClockStart)
 )
 
-func generateCSR(t *testing.T, secretKey crypto.Signer, sigAlg x509.SignatureAlgorithm) []byte {
+func generateCSR(t *testing.T, secretKey crypto.Signer, sigAlg x509.SignatureAlgorithm, extensions ...pkix.Extension) []byte {
 	asn1Subj, _ := asn1.Marshal(pkix.Name{
 		CommonName: "test",
 	}.ToRDNSequence())
 	template := x509.CertificateRequest{
 		RawSubject:         asn1Subj,
 		SignatureAlgorithm: sigAlg,
+		ExtraExtensions:    extensions,
 	}
 
 	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &template, secretKey)
@@ -213,7 +217,7 @@ func TestSign(t *testing.T) {
 				},
 			},
 		},
-		"a missing CA key pair should set the condition to pending and wait for a re-sync": {
+		"a missing CA key pair should set the condition to pending and back-off error to retry": {
 			certificateRequest: baseCR.DeepCopy(),
 			builder: &testpkg.Builder{
 				KubeObjects:        []runtime.Object{},
@@ -238,6 +242,7 @@ func TestSign(t *testing.T) {
 					)),
 				},
 			},
+			expectedErr: true,
 		},
 		"a secret with invalid data should set condition to pending and wait for re-sync": {
 			certificateRequest: baseCR.DeepCopy(),
@@ -471,13 +476,59 @@ func TestCA_Sign(t *testing.T) {
 		t.Fatal(err)
 	}
 	testCSR := generateCSR(t, testpk, x509.ECDSAWithSHA256)
+	testCSRWithExtension := generateCSR(t, testpk, x509.ECDSAWithSHA256,
+		pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}, Value: []byte{1, 2, 3}})
+	commonNameOnlyCSR, _, err := gen.CSR(x509.ECDSA, gen.SetCSRDNSNames("example.com"))
+	require.NoError(t, err)
+	multipleNamesCSR, _, err := gen.CSR(x509.ECDSA, gen.SetCSRDNSNames("example.com", "www.example.com"))
+	require.NoError(t, err)
+	upn := cmapi.OtherName{OID: "1.3.6.1.4.1.311.20.2.3", UTF8Value: "alice@corp.example.com"}
+	upnCSRTemplate, err := pki.GenerateCSR(&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+		CommonName: "alice",
+		OtherNames: []cmapi.OtherName{upn},
+		PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+	}})
+	require.NoError(t, err)
+	upnCSRDER, err := pki.EncodeCSR(upnCSRTemplate, testpk)
+	require.NoError(t, err)
+	upnCSR := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: upnCSRDER})
+	hasSubjectAltNameExtension := func(cert *x509.Certificate) bool {
+		for _, extension := range cert.Extensions {
+			if extension.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 17}) {
+				return true
+			}
+		}
+		return false
+	}
+	extensionValues := func(cert *x509.Certificate) map[string][]byte {
+		values := make(map[string][]byte)
+		for _, extension := range cert.Extensions {
+			if extension.Critical {
+				values[extension.Id.String()] = extension.Value
+			}
+		}
+		return values
+	}
+	policyConstraintsIssuer := gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+		SecretName:       "secret-1",
+		InhibitAnyPolicy: pointer.Int32Ptr(0),
+		PolicyConstraints: &cmapi.PolicyConstraints{
+			RequireExplicitPolicy: pointer.Int32Ptr(1),
+			InhibitPolicyMapping:  pointer.Int32Ptr(2),
+		},
+	}))
 
 	tests := map[string]struct {
 		givenCASecret    *corev1.Secret
 		givenCAIssuer    cmapi.GenericIssuer
 		givenCR          *cmapi.CertificateRequest
+		issuerOptions    controller.IssuerOptions
 		assertSignedCert func(t *testing.T, got *x509.Certificate)
 		wantErr          string
+		wantNoResponse   bool
+		// wantEventPrefix, if set, is the prefix of an event that is expected
+		// to be recorded
+		wantEventPrefix string
 	}{
 		"when the CertificateRequest has the duration field set, it should appear as notAfter on the signed ca": {
 			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
@@ -520,6 +571,263 @@ func TestCA_Sign(t *testing.T) {
 				assert.LessOrEqualf(t, deltaSec, 1., "expected a time delta lower than 1 second. Time expected='%s', got='%s'", expectNotAfter.String(), got.NotAfter.String())
 			},
 		},
+		"when the CA expiry policy is Clamp and the certificate would outlive the CA, the notAfter should be clamped to the CA's": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestDuration(&metav1.Duration{Duration: 2 * time.Minute}),
+			),
+			issuerOptions: controller.IssuerOptions{CAExpiryPolicy: CAExpiryPolicyClamp},
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, rootCert.NotAfter, got.NotAfter)
+			},
+			wantEventPrefix: "Warning CAExpiryClamped Reduced the notAfter of the certificate",
+		},
+		"when the CA expiry policy is Clamp and the clamped certificate would be due for renewal immediately, it should not be signed": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestDuration(&metav1.Duration{Duration: 30 * time.Minute}),
+			),
+			issuerOptions:   controller.IssuerOptions{CAExpiryPolicy: CAExpiryPolicyClamp},
+			wantNoResponse:  true,
+			wantEventPrefix: "Warning CAExpiry Requested certificate clamped to the CA's expiry would be due for renewal immediately",
+		},
+		"when the CA expiry policy is Clamp and the CA expires before the requested notBefore, it should not be signed": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestNotBefore(metav1.NewTime(rootCert.NotAfter.Add(time.Hour))),
+			),
+			issuerOptions:   controller.IssuerOptions{CAExpiryPolicy: CAExpiryPolicyClamp},
+			wantNoResponse:  true,
+			wantEventPrefix: "Warning CAExpiry Requested certificate cannot be clamped to the CA's expiry",
+		},
+		"when the CA expiry policy is Reject and the certificate would outlive the CA, it should not be signed": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestDuration(&metav1.Duration{Duration: 30 * time.Minute}),
+			),
+			issuerOptions:   controller.IssuerOptions{CAExpiryPolicy: CAExpiryPolicyReject},
+			wantNoResponse:  true,
+			wantEventPrefix: "Warning CAExpiry Requested certificate would outlive the CA",
+		},
+		"when the CA expiry policy is Reject and the certificate does not outlive the CA, it should be signed unchanged": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestDuration(&metav1.Duration{Duration: 30 * time.Second}),
+			),
+			issuerOptions: controller.IssuerOptions{CAExpiryPolicy: CAExpiryPolicyReject},
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.True(t, got.NotAfter.Before(rootCert.NotAfter))
+			},
+		},
+		"when the CertificateRequest has the notBefore field set, it should appear as the validity window on the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestNotBefore(metav1.NewTime(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))),
+				gen.SetCertificateRequestDuration(&metav1.Duration{
+					Duration: 30 * time.Minute,
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), got.NotBefore)
+				assert.Equal(t, time.Date(2021, time.January, 1, 0, 30, 0, 0, time.UTC), got.NotAfter)
+			},
+		},
+		"when notAfter rounding is configured, the notAfter of the signed cert should be rounded down": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestNotBefore(metav1.NewTime(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))),
+				gen.SetCertificateRequestDuration(&metav1.Duration{
+					Duration: 90 * time.Minute,
+				}),
+			),
+			issuerOptions: controller.IssuerOptions{NotAfterRounding: time.Hour},
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), got.NotBefore)
+				assert.Equal(t, time.Date(2021, time.January, 1, 1, 0, 0, 0, time.UTC), got.NotAfter)
+			},
+		},
+		"when rounding the notAfter of the signed cert down would leave it within a third of its duration of notBefore, it should be rounded up": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestNotBefore(metav1.NewTime(time.Date(2021, time.January, 1, 23, 0, 0, 0, time.UTC))),
+				gen.SetCertificateRequestDuration(&metav1.Duration{
+					Duration: 6 * time.Hour,
+				}),
+			),
+			issuerOptions: controller.IssuerOptions{NotAfterRounding: 24 * time.Hour},
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, time.Date(2021, time.January, 1, 23, 0, 0, 0, time.UTC), got.NotBefore)
+				assert.Equal(t, time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC), got.NotAfter)
+			},
+		},
+		"when rounding the notAfter of the signed cert down leaves it beyond the renewBefore of its Certificate, it should be rounded down": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestNotBefore(metav1.NewTime(time.Date(2021, time.January, 1, 23, 0, 0, 0, time.UTC))),
+				gen.SetCertificateRequestDuration(&metav1.Duration{
+					Duration: 6 * time.Hour,
+				}),
+				gen.AddCertificateRequestAnnotations(map[string]string{
+					cmapi.CertificateRequestRenewBeforeAnnotationKey: "30m",
+				}),
+			),
+			issuerOptions: controller.IssuerOptions{NotAfterRounding: 24 * time.Hour},
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, time.Date(2021, time.January, 1, 23, 0, 0, 0, time.UTC), got.NotBefore)
+				assert.Equal(t, time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC), got.NotAfter)
+			},
+		},
+		"when notAfter rounding up is configured, the notAfter of the signed cert should be rounded up": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestNotBefore(metav1.NewTime(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))),
+				gen.SetCertificateRequestDuration(&metav1.Duration{
+					Duration: 90 * time.Minute,
+				}),
+			),
+			issuerOptions: controller.IssuerOptions{NotAfterRounding: 24 * time.Hour, NotAfterRoundUp: true},
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), got.NotBefore)
+				assert.Equal(t, time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC), got.NotAfter)
+			},
+		},
+		"when legacyCommonNameOnly is set and only the common name is requested, the signed cert should not have a subjectAltName extension": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName:           "secret-1",
+				LegacyCommonNameOnly: true,
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(commonNameOnlyCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, "example.com", got.Subject.CommonName)
+				assert.Empty(t, got.DNSNames)
+				assert.False(t, hasSubjectAltNameExtension(got), "unexpected subjectAltName extension")
+			},
+		},
+		"when legacyCommonNameOnly is set and other names are requested, the signed cert should have a subjectAltName extension": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName:           "secret-1",
+				LegacyCommonNameOnly: true,
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(multipleNamesCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, []string{"example.com", "www.example.com"}, got.DNSNames)
+				assert.True(t, hasSubjectAltNameExtension(got), "expected a subjectAltName extension")
+			},
+		},
+		"when legacyCommonNameOnly is not set and only the common name is requested, the signed cert should have a subjectAltName extension": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(commonNameOnlyCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, []string{"example.com"}, got.DNSNames)
+				assert.True(t, hasSubjectAltNameExtension(got), "expected a subjectAltName extension")
+			},
+		},
 		"when the CertificateRequest has the isCA field set, it should appear on the signed ca": {
 			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
 			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
@@ -575,28 +883,449 @@ func TestCA_Sign(t *testing.T) {
 				assert.Equal(t, []string{"http://www.example.com/crl/test.crl"}, gotCA.CRLDistributionPoints)
 			},
 		},
-	}
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			rec := &testpkg.FakeRecorder{}
-
-			c := &CA{
-				issuerOptions: controller.IssuerOptions{
-					ClusterResourceNamespace:        "",
-					ClusterIssuerAmbientCredentials: false,
-					IssuerAmbientCredentials:        false,
+		"when the CertificateRequest is for a CA with a maxPathLen, it should appear in the BasicConstraints of the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestIsCA(true),
+				gen.SetCertificateRequestMaxPathLen(1),
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.True(t, got.BasicConstraintsValid)
+				assert.True(t, got.IsCA)
+				assert.Equal(t, 1, got.MaxPathLen)
+				assert.False(t, got.MaxPathLenZero)
+				assert.Equal(t, x509.KeyUsageCertSign|x509.KeyUsageCRLSign, got.KeyUsage&(x509.KeyUsageCertSign|x509.KeyUsageCRLSign))
+			},
+		},
+		"when the CertificateRequest is for a CA with a maxPathLen of zero, it should appear in the BasicConstraints of the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestIsCA(true),
+				gen.SetCertificateRequestMaxPathLen(0),
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.True(t, got.IsCA)
+				assert.Equal(t, 0, got.MaxPathLen)
+				assert.True(t, got.MaxPathLenZero)
+			},
+		},
+		"when the CertificateRequest is not for a CA, the signed cert should not be a CA": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.True(t, got.BasicConstraintsValid)
+				assert.False(t, got.IsCA)
+				assert.Equal(t, -1, got.MaxPathLen)
+				assert.Zero(t, got.KeyUsage&(x509.KeyUsageCertSign|x509.KeyUsageCRLSign))
+			},
+		},
+		"when the Issuer has policyIdentifiers set, they should appear in the certificatePolicies extension of the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+				PolicyIdentifiers: []cmapi.CertificatePolicy{
+					{OID: "2.23.140.1.2.1"},
+					{OID: "1.2.3.4", CPSURIs: []string{"http://example.com/cps"}},
 				},
-				reporter: util.NewReporter(fixedClock, rec),
-				secretsLister: testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
-					testlisters.SetFakeSecretNamespaceListerGet(test.givenCASecret, nil),
-				),
-				templateGenerator: pki.GenerateTemplateFromCertificateRequest,
-				signingFn:         pki.SignCSRTemplate,
-			}
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, []asn1.ObjectIdentifier{{2, 23, 140, 1, 2, 1}, {1, 2, 3, 4}}, got.PolicyIdentifiers)
 
-			gotIssueResp, gotErr := c.Sign(context.Background(), test.givenCR, test.givenCAIssuer)
-			if test.wantErr != "" {
+				var found *pkix.Extension
+				for i, ext := range got.Extensions {
+					if ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 32}) {
+						found = &got.Extensions[i]
+					}
+				}
+				require.NotNil(t, found, "certificatePolicies extension not present on signed cert")
+				assert.False(t, found.Critical)
+
+				type qualifier struct {
+					ID    asn1.ObjectIdentifier
+					Value string `asn1:"ia5"`
+				}
+				type policy struct {
+					ID         asn1.ObjectIdentifier
+					Qualifiers []qualifier `asn1:"optional,omitempty"`
+				}
+				var policies []policy
+				_, err := asn1.Unmarshal(found.Value, &policies)
+				require.NoError(t, err)
+				assert.Equal(t, []policy{
+					{ID: asn1.ObjectIdentifier{2, 23, 140, 1, 2, 1}},
+					{ID: asn1.ObjectIdentifier{1, 2, 3, 4}, Qualifiers: []qualifier{
+						{ID: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 1}, Value: "http://example.com/cps"},
+					}},
+				}, policies)
+			},
+		},
+		"when the CertificateRequest has the ocsp-no-check annotation, the signed cert should have the id-pkix-ocsp-nocheck extension": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageOCSPSigning),
+				gen.SetCertificateRequestAnnotations(map[string]string{cmapi.OCSPNoCheckAnnotationKey: "true"}),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Contains(t, got.ExtKeyUsage, x509.ExtKeyUsageOCSPSigning)
+
+				var found *pkix.Extension
+				for i, ext := range got.Extensions {
+					if ext.Id.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}) {
+						found = &got.Extensions[i]
+					}
+				}
+				require.NotNil(t, found, "id-pkix-ocsp-nocheck extension not present on signed cert")
+				assert.False(t, found.Critical)
+				assert.Equal(t, asn1.NullBytes, found.Value)
+			},
+		},
+		"when the CSR requests a userPrincipalName otherName, it should appear in the subject alt names of the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(upnCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				otherNames, err := pki.OtherNamesFromExtensions(got.Extensions)
+				require.NoError(t, err)
+				assert.Equal(t, []cmapi.OtherName{upn}, otherNames)
+			},
+		},
+		"when the Issuer has inhibitAnyPolicy and policyConstraints set, they should appear as critical extensions on a signed CA cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: policyConstraintsIssuer,
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIsCA(true),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.True(t, got.IsCA)
+				values := extensionValues(got)
+				// inhibitAnyPolicy is the INTEGER 0
+				assert.Equal(t, []byte{0x02, 0x01, 0x00}, values["2.5.29.54"])
+				// policyConstraints is a SEQUENCE of requireExplicitPolicy [0] 1
+				// and inhibitPolicyMapping [1] 2
+				assert.Equal(t, []byte{0x30, 0x06, 0x80, 0x01, 0x01, 0x81, 0x01, 0x02}, values["2.5.29.36"])
+			},
+		},
+		"when the Issuer has inhibitAnyPolicy and policyConstraints set, they should not appear on a signed leaf cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: policyConstraintsIssuer,
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.False(t, got.IsCA)
+				values := extensionValues(got)
+				assert.NotContains(t, values, "2.5.29.54")
+				assert.NotContains(t, values, "2.5.29.36")
+			},
+		},
+		"when the CertificateRequest does not have the ocsp-no-check annotation, the signed cert should not have the id-pkix-ocsp-nocheck extension": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageOCSPSigning),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				for _, ext := range got.Extensions {
+					assert.False(t, ext.Id.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}), "unexpected id-pkix-ocsp-nocheck extension on signed cert")
+				}
+			},
+		},
+		"when the Issuer has no subjectKeyIdentifierMethod set, the signed cert should have no subject key identifier": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "secret-1"})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Empty(t, got.SubjectKeyId)
+			},
+		},
+		"when the Issuer has subjectKeyIdentifierMethod set to method 1, the signed cert should have the full SHA-1 subject key identifier": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName:                 "secret-1",
+				SubjectKeyIdentifierMethod: cmapi.SubjectKeyIdentifierMethod1,
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, subjectKeyID(t, testpk.Public(), cmapi.SubjectKeyIdentifierMethod1), got.SubjectKeyId)
+				assert.Len(t, got.SubjectKeyId, 20)
+			},
+		},
+		"when the Issuer has subjectKeyIdentifierMethod set to method 2, the signed cert should have the truncated subject key identifier": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName:                 "secret-1",
+				SubjectKeyIdentifierMethod: cmapi.SubjectKeyIdentifierMethod2,
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestIsCA(true),
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, subjectKeyID(t, testpk.Public(), cmapi.SubjectKeyIdentifierMethod2), got.SubjectKeyId)
+				assert.Len(t, got.SubjectKeyId, 8)
+				assert.Equal(t, byte(0x40), got.SubjectKeyId[0]&0xf0)
+			},
+		},
+		"when the CertificateRequest requests a custom extension permitted by the Issuer, it should appear on the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+				AllowedCustomExtensions: []cmapi.CustomExtensionPolicy{
+					{OID: "1.3.6.1.4.1.311.21.7", AllowCritical: true},
+				},
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestAnnotations(map[string]string{
+					cmapi.CustomExtensionAnnotationKeyPrefix + "1.3.6.1.4.1.311.21.7": "AQID",
+					cmapi.CriticalExtensionsAnnotationKey:                             "1.3.6.1.4.1.311.21.7",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				var found *pkix.Extension
+				for i, ext := range got.Extensions {
+					if ext.Id.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}) {
+						found = &got.Extensions[i]
+					}
+				}
+				require.NotNil(t, found, "custom extension not present on signed cert")
+				assert.True(t, found.Critical)
+				assert.Equal(t, []byte{1, 2, 3}, found.Value)
+			},
+		},
+		"when the CertificateRequest requests a custom extension not permitted by the Issuer, it should not be signed": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+				AllowedCustomExtensions: []cmapi.CustomExtensionPolicy{
+					{OID: "1.3.6.1.4.1.311.21.7"},
+				},
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestAnnotations(map[string]string{
+					cmapi.CustomExtensionAnnotationKeyPrefix + "1.3.6.1.4.1.311.21.7": "AQID",
+					cmapi.CriticalExtensionsAnnotationKey:                             "1.3.6.1.4.1.311.21.7",
+				}),
+			),
+			wantNoResponse: true,
+		},
+		"when the CSR requests an extension permitted by the Issuer, it should be copied to the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+				AllowedCSRExtensions: []cmapi.CustomExtensionPolicy{
+					{OID: "1.3.6.1.4.1.311.21.7"},
+				},
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSRWithExtension),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				var found *pkix.Extension
+				for i, ext := range got.Extensions {
+					if ext.Id.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}) {
+						found = &got.Extensions[i]
+					}
+				}
+				require.NotNil(t, found, "CSR extension not present on signed cert")
+				assert.False(t, found.Critical)
+				assert.Equal(t, []byte{1, 2, 3}, found.Value)
+			},
+		},
+		"when the CSR requests an extension and the Issuer does not set allowedCSRExtensions, it should be dropped from the signed cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSRWithExtension),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				for _, ext := range got.Extensions {
+					assert.False(t, ext.Id.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}), "CSR extension should not be present on signed cert")
+				}
+			},
+		},
+		"when the CSR requests an extension not permitted by the Issuer, it should not be signed": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+				AllowedCSRExtensions: []cmapi.CustomExtensionPolicy{
+					{OID: "1.2.3.4"},
+				},
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSRWithExtension),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			),
+			wantNoResponse: true,
+		},
+		"when the CertificateRequest has the signatureAlgorithm field set, it should be used to sign the cert": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestSignatureAlgorithm(cmapi.ECDSAWithSHA384SignatureAlgorithm),
+			),
+			assertSignedCert: func(t *testing.T, got *x509.Certificate) {
+				assert.Equal(t, x509.ECDSAWithSHA256, rootCert.SignatureAlgorithm)
+				assert.Equal(t, x509.ECDSAWithSHA384, got.SignatureAlgorithm)
+			},
+		},
+		"when the CertificateRequest has a signatureAlgorithm incompatible with the CA's private key, it should not be signed": {
+			givenCASecret: gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(secretDataFor(t, rootPK, rootCert))),
+			givenCAIssuer: gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName: "secret-1",
+			})),
+			givenCR: gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+				gen.SetCertificateRequestSignatureAlgorithm(cmapi.SHA384WithRSASignatureAlgorithm),
+			),
+			wantNoResponse: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := &testpkg.FakeRecorder{}
+
+			c := &CA{
+				issuerOptions: test.issuerOptions,
+				reporter:      util.NewReporter(fixedClock, rec),
+				recorder:      rec,
+				secretsLister: testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
+					testlisters.SetFakeSecretNamespaceListerGet(test.givenCASecret, nil),
+				),
+				templateGenerator: pki.GenerateTemplateFromCertificateRequest,
+				signingFn:         pki.SignCSRTemplate,
+			}
+
+			gotIssueResp, gotErr := c.Sign(context.Background(), test.givenCR, test.givenCAIssuer)
+			if test.wantEventPrefix != "" {
+				require.Len(t, rec.Events, 1)
+				assert.True(t, strings.HasPrefix(rec.Events[0], test.wantEventPrefix), "unexpected event %q", rec.Events[0])
+			}
+			if test.wantErr != "" {
 				require.EqualError(t, gotErr, test.wantErr)
+			} else if test.wantNoResponse {
+				require.NoError(t, gotErr)
+				require.Nil(t, gotIssueResp)
 			} else {
 				require.NoError(t, gotErr)
 
@@ -610,6 +1339,265 @@ func TestCA_Sign(t *testing.T) {
 	}
 }
 
+func subjectKeyID(t *testing.T, pub crypto.PublicKey, method cmapi.SubjectKeyIdentifierMethod) []byte {
+	ski, err := pki.SubjectKeyIdentifier(pub, method)
+	require.NoError(t, err)
+	return ski
+}
+
+func TestCA_SignCrossSignedChain(t *testing.T) {
+	rootAPK, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	rootACert, rootAPEM := generateSelfSignedCACert(t, rootAPK, "root-a")
+
+	rootBPK, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	rootBCert, rootBPEM := generateSelfSignedCACert(t, rootBPK, "root-b")
+
+	// root-a cross-signed by root-b.
+	crossTmpl := *rootACert
+	crossTmpl.SerialNumber = big.NewInt(1)
+	crossAPEM, _, err := pki.SignCertificate(&crossTmpl, rootBCert, rootAPK.Public(), rootBPK)
+	require.NoError(t, err)
+
+	intPK, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	intTmpl := &x509.Certificate{
+		Version:               3,
+		BasicConstraintsValid: true,
+		SerialNumber:          big.NewInt(2),
+		Subject: pkix.Name{
+			CommonName: "intermediate",
+		},
+		NotBefore: time.Now(),
+		NotAfter:  time.Now().Add(time.Minute),
+		KeyUsage:  x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		PublicKey: intPK.Public(),
+		IsCA:      true,
+	}
+	intPEM, _, err := pki.SignCertificate(intTmpl, rootACert, intPK.Public(), rootAPK)
+	require.NoError(t, err)
+	intKeyPEM, err := pki.EncodeECPrivateKey(intPK)
+	require.NoError(t, err)
+
+	testpk, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	testCSR := generateCSR(t, testpk, x509.ECDSAWithSHA256)
+
+	givenCASecret := gen.SecretFrom(gen.Secret("secret-1"), gen.SetSecretNamespace("default"), gen.SetSecretData(map[string][]byte{
+		"tls.key": intKeyPEM,
+		"tls.crt": append(append([]byte{}, intPEM...), crossAPEM...),
+		"ca.crt":  append(append([]byte{}, rootAPEM...), rootBPEM...),
+	}))
+	givenCR := gen.CertificateRequest("cr-1",
+		gen.SetCertificateRequestCSR(testCSR),
+		gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+			Name:  "issuer-1",
+			Group: certmanager.GroupName,
+			Kind:  "Issuer",
+		}),
+	)
+
+	tests := map[string]struct {
+		preferredChain string
+		wantChain      [][]byte
+		wantCA         []byte
+	}{
+		"with no preferred chain, the first chain in the Secret should be used": {
+			wantChain: [][]byte{intPEM, crossAPEM},
+			wantCA:    rootBPEM,
+		},
+		"with a preferred chain of root-a, the chain should terminate at root-a": {
+			preferredChain: "root-a",
+			wantChain:      [][]byte{intPEM},
+			wantCA:         rootAPEM,
+		},
+		"with a preferred chain of root-b, the chain should include the cross-signed certificate": {
+			preferredChain: "root-b",
+			wantChain:      [][]byte{intPEM, crossAPEM},
+			wantCA:         rootBPEM,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &CA{
+				reporter: util.NewReporter(fixedClock, &testpkg.FakeRecorder{}),
+				secretsLister: testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
+					testlisters.SetFakeSecretNamespaceListerGet(givenCASecret, nil),
+				),
+				templateGenerator: pki.GenerateTemplateFromCertificateRequest,
+				signingFn:         pki.SignCSRTemplate,
+			}
+
+			givenCAIssuer := gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName:     "secret-1",
+				PreferredChain: test.preferredChain,
+			}))
+
+			gotIssueResp, err := c.Sign(context.Background(), givenCR, givenCAIssuer)
+			require.NoError(t, err)
+			require.NotNil(t, gotIssueResp)
+
+			gotCerts, err := pki.DecodeX509CertificateChainBytes(gotIssueResp.Certificate)
+			require.NoError(t, err)
+			require.Equal(t, len(test.wantChain)+1, len(gotCerts))
+			for i, wantPEM := range test.wantChain {
+				gotPEM, err := pki.EncodeX509(gotCerts[i+1])
+				require.NoError(t, err)
+				assert.Equal(t, string(wantPEM), string(gotPEM))
+			}
+			assert.Equal(t, string(test.wantCA), string(gotIssueResp.CA))
+		})
+	}
+}
+
+func TestCA_SignRotation(t *testing.T) {
+	rootPK, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	rootCert, rootPEM := generateSelfSignedCACert(t, rootPK, "root")
+
+	// The old and new intermediates share the same private key.
+	intPK, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	intKeyPEM, err := pki.EncodeECPrivateKey(intPK)
+	require.NoError(t, err)
+	signIntermediate := func(key crypto.Signer, serial int64, name string) []byte {
+		tmpl := &x509.Certificate{
+			Version:               3,
+			BasicConstraintsValid: true,
+			SerialNumber:          big.NewInt(serial),
+			Subject: pkix.Name{
+				CommonName: name,
+			},
+			NotBefore: time.Now(),
+			NotAfter:  time.Now().Add(time.Minute),
+			KeyUsage:  x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+			PublicKey: key.Public(),
+			IsCA:      true,
+		}
+		intPEM, _, err := pki.SignCertificate(tmpl, rootCert, key.Public(), rootPK)
+		require.NoError(t, err)
+		return intPEM
+	}
+	oldIntPEM := signIntermediate(intPK, 1, "intermediate")
+	newIntPEM := signIntermediate(intPK, 2, "intermediate")
+
+	otherPK, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	otherIntPEM := signIntermediate(otherPK, 3, "other-intermediate")
+
+	testpk, err := pki.GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	testCSR := generateCSR(t, testpk, x509.ECDSAWithSHA256)
+
+	secrets := map[string]*corev1.Secret{
+		"new": gen.SecretFrom(gen.Secret("new"), gen.SetSecretNamespace("default"), gen.SetSecretData(map[string][]byte{
+			"tls.key": intKeyPEM,
+			"tls.crt": newIntPEM,
+			"ca.crt":  rootPEM,
+		})),
+		// A rotation Secret does not need to contain the private key.
+		"old": gen.SecretFrom(gen.Secret("old"), gen.SetSecretNamespace("default"), gen.SetSecretData(map[string][]byte{
+			"tls.crt": oldIntPEM,
+			"ca.crt":  rootPEM,
+		})),
+		"other": gen.SecretFrom(gen.Secret("other"), gen.SetSecretNamespace("default"), gen.SetSecretData(map[string][]byte{
+			"tls.crt": otherIntPEM,
+			"ca.crt":  rootPEM,
+		})),
+	}
+
+	tests := map[string]struct {
+		rotationSecretNames []string
+		wantCA              [][]byte
+		wantPending         bool
+		wantErr             bool
+	}{
+		"with no rotation secrets, only the root should be returned as the CA": {
+			wantCA: [][]byte{rootPEM},
+		},
+		"with a rotation secret, the CA should contain the chains of both intermediates": {
+			rotationSecretNames: []string{"old"},
+			wantCA:              [][]byte{newIntPEM, rootPEM, oldIntPEM},
+		},
+		"with a rotation secret for a different key, the request should be pending": {
+			rotationSecretNames: []string{"old", "other"},
+			wantPending:         true,
+		},
+		"with a rotation secret that does not exist, the request should be pending and retried with back-off": {
+			rotationSecretNames: []string{"missing"},
+			wantPending:         true,
+			wantErr:             true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			secretNamespaceLister := testlisters.NewFakeSecretNamespaceLister()
+			secretNamespaceLister.GetFn = func(name string) (*corev1.Secret, error) {
+				if secret, ok := secrets[name]; ok {
+					return secret, nil
+				}
+				return nil, apierrors.NewNotFound(corev1.Resource("secrets"), name)
+			}
+			c := &CA{
+				reporter: util.NewReporter(fixedClock, &testpkg.FakeRecorder{}),
+				secretsLister: testlisters.FakeSecretListerFrom(testlisters.NewFakeSecretLister(),
+					testlisters.SetFakeSecretListerSecret(func(string) clientcorev1.SecretNamespaceLister { return secretNamespaceLister }),
+				),
+				templateGenerator: pki.GenerateTemplateFromCertificateRequest,
+				signingFn:         pki.SignCSRTemplate,
+			}
+
+			givenCR := gen.CertificateRequest("cr-1",
+				gen.SetCertificateRequestCSR(testCSR),
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer-1",
+					Group: certmanager.GroupName,
+					Kind:  "Issuer",
+				}),
+			)
+			givenCAIssuer := gen.Issuer("issuer-1", gen.SetIssuerCA(cmapi.CAIssuer{
+				SecretName:          "new",
+				RotationSecretNames: test.rotationSecretNames,
+			}))
+
+			gotIssueResp, err := c.Sign(context.Background(), givenCR, givenCAIssuer)
+			if test.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if test.wantPending {
+				assert.Nil(t, gotIssueResp)
+				assert.Equal(t, cmapi.CertificateRequestReasonPending, apiutil.CertificateRequestReadyReason(givenCR))
+				return
+			}
+			require.NotNil(t, gotIssueResp)
+
+			var wantCA []byte
+			for _, caPEM := range test.wantCA {
+				wantCA = append(wantCA, caPEM...)
+			}
+			assert.Equal(t, string(wantCA), string(gotIssueResp.CA))
+
+			// The issued certificate should be trusted through either
+			// intermediate.
+			leaf, err := pki.DecodeX509CertificateBytes(gotIssueResp.Certificate)
+			require.NoError(t, err)
+			roots := x509.NewCertPool()
+			roots.AddCert(rootCert)
+			for _, intPEM := range [][]byte{oldIntPEM, newIntPEM} {
+				intCert, err := pki.DecodeX509CertificateBytes(intPEM)
+				require.NoError(t, err)
+				intermediates := x509.NewCertPool()
+				intermediates.AddCert(intCert)
+				_, err = leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 // Returns a map that is meant to be used for creating a certificate Secret
 // that contains the fields "tls.crt" and "tls.key".
 func secretDataFor(t *testing.T, caKey *ecdsa.PrivateKey, caCrt *x509.Certificate) (secretData map[string][]byte) {
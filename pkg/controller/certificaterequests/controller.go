@@ -19,8 +19,10 @@ package certificaterequests
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -34,11 +36,18 @@ import (
 	"github.com/jetstack/cert-manager/pkg/controller/certificaterequests/util"
 	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/scheduler"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 )
 
 const (
 	// ControllerName is the name of the Certificate Requests controller.
 	ControllerName = "certificaterequests"
+
+	// maxTransientRetries is the number of consecutive transient signing
+	// errors of a certificate request that are retried after the transient
+	// retry period, before it is retried with exponential back-off instead.
+	maxTransientRetries = 5
 )
 
 var keyFunc = controllerpkg.KeyFunc
@@ -85,6 +94,22 @@ type Controller struct {
 	clock clock.Clock
 
 	reporter *util.Reporter
+
+	// tracer is used to trace the signing of certificate requests
+	tracer trace.Tracer
+
+	// scheduledWorkQueue is used to retry certificate requests after their
+	// signer returned a transient error
+	scheduledWorkQueue scheduler.ScheduledWorkQueue
+
+	// how long to wait before retrying a certificate request after a
+	// transient signing error
+	transientRetryPeriod time.Duration
+
+	// transientRetries counts the consecutive transient signing errors of
+	// each certificate request, so that only a limited number of them are
+	// retried after the transient retry period
+	transientRetries workqueue.RateLimiter
 }
 
 // New will construct a new certificaterequest controller using the given
@@ -169,6 +194,10 @@ func (c *Controller) Register(ctx *controllerpkg.Context) (workqueue.RateLimitin
 	c.recorder = ctx.Recorder
 	c.reporter = util.NewReporter(c.clock, c.recorder)
 	c.cmClient = ctx.CMClient
+	c.tracer = tracing.Tracer(ctx.TracerProvider)
+	c.scheduledWorkQueue = scheduler.NewScheduledWorkQueue(c.clock, c.queue.Add)
+	c.transientRetryPeriod = ctx.IssuerOptions.TransientSigningErrorRetryPeriod
+	c.transientRetries = workqueue.NewItemFastSlowRateLimiter(c.transientRetryPeriod, c.transientRetryPeriod, maxTransientRetries)
 
 	c.log.V(logf.DebugLevel).Info("new certificate request controller registered",
 		"type", c.issuerType)
@@ -130,8 +130,35 @@ func (s *SelfSigned) Sign(ctx context.Context, cr *cmapi.CertificateRequest, iss
 		return nil, nil
 	}
 
+	pki.RoundNotAfter(template, s.issuerOptions.NotAfterRounding, s.issuerOptions.NotAfterRoundUp, crutil.RenewBefore(cr, template))
+
 	template.CRLDistributionPoints = issuerObj.GetSpec().SelfSigned.CRLDistributionPoints
 
+	extensions, err := pki.CustomExtensionsFromAnnotations(cr.Annotations, issuerObj.GetSpec().SelfSigned.AllowedCustomExtensions)
+	if err != nil {
+		message := "Requested custom extensions are not permitted"
+		s.reporter.Failed(cr, err, "CustomExtensionsNotPermitted", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+	template.ExtraExtensions = append(template.ExtraExtensions, extensions...)
+
+	csrExtensions, err := pki.CustomExtensionsFromCSR(cr.Spec.Request, issuerObj.GetSpec().SelfSigned.AllowedCSRExtensions)
+	if err != nil {
+		message := "Extensions requested by the CSR are not permitted"
+		s.reporter.Failed(cr, err, "CustomExtensionsNotPermitted", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+	template.ExtraExtensions = append(template.ExtraExtensions, csrExtensions...)
+
+	if err := pki.MarkSubjectAltNamesCritical(template, pki.SubjectAltNamesCriticalFromAnnotations(cr.Annotations)); err != nil {
+		message := "Error marking subject alternative names as critical"
+		s.reporter.Failed(cr, err, "ErrorGenerating", message)
+		log.Error(err, message)
+		return nil, nil
+	}
+
 	if template.Subject.String() == "" {
 		// RFC 5280 (https://tools.ietf.org/html/rfc5280#section-4.1.2.4) says that:
 		// "The issuer field MUST contain a non-empty distinguished name (DN)."
@@ -52,13 +52,14 @@ var (
 	fixedClock      = fakeclock.NewFakeClock(fixedClockStart)
 )
 
-func generateCSR(t *testing.T, secretKey crypto.Signer, alg x509.SignatureAlgorithm, commonName string) []byte {
+func generateCSR(t *testing.T, secretKey crypto.Signer, alg x509.SignatureAlgorithm, commonName string, extensions ...pkix.Extension) []byte {
 	asn1Subj, _ := asn1.Marshal(pkix.Name{
 		CommonName: commonName,
 	}.ToRDNSequence())
 	template := x509.CertificateRequest{
 		RawSubject:         asn1Subj,
 		SignatureAlgorithm: alg,
+		ExtraExtensions:    extensions,
 	}
 
 	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &template, secretKey)
@@ -204,6 +205,33 @@ func TestSign(t *testing.T) {
 		t.FailNow()
 	}
 
+	customExtensionOID := "1.3.6.1.4.1.311.21.7"
+	extensionCR := gen.CertificateRequestFrom(baseCR,
+		gen.AddCertificateRequestAnnotations(map[string]string{
+			cmapi.CustomExtensionAnnotationKeyPrefix + customExtensionOID: "AQID",
+		}),
+	)
+	extensionIssuer := gen.IssuerFrom(baseIssuer,
+		gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{
+			AllowedCustomExtensions: []cmapi.CustomExtensionPolicy{{OID: customExtensionOID}},
+		}),
+	)
+	csrExtensionCR := gen.CertificateRequestFrom(baseCR,
+		gen.SetCertificateRequestCSR(generateCSR(t, skRSA, x509.SHA256WithRSA, "test-rsa",
+			pkix.Extension{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}, Value: []byte{1, 2, 3}})),
+	)
+	csrExtensionIssuer := gen.IssuerFrom(baseIssuer,
+		gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{
+			AllowedCSRExtensions: []cmapi.CustomExtensionPolicy{{OID: "1.2.3.4"}},
+		}),
+	)
+
+	notBefore := metav1.NewTime(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))
+	notBeforeCR := gen.CertificateRequestFrom(baseCR,
+		gen.SetCertificateRequestNotBefore(notBefore),
+		gen.SetCertificateRequestDuration(&metav1.Duration{Duration: time.Hour * 24}),
+	)
+
 	tests := map[string]testT{
 		"a CertificateRequest without an approved condition should do nothing": {
 			certificateRequest: baseCRNotApproved.DeepCopy(),
@@ -555,6 +583,149 @@ func TestSign(t *testing.T) {
 				},
 			},
 		},
+		"a CertificateRequest requesting a custom extension not permitted by the issuer should fail": {
+			certificateRequest: extensionCR.DeepCopy(),
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{rsaKeySecret},
+				CertManagerObjects: []runtime.Object{extensionCR.DeepCopy(), baseIssuer},
+				ExpectedEvents: []string{
+					`Warning CustomExtensionsNotPermitted Requested custom extensions are not permitted: extension "1.3.6.1.4.1.311.21.7" is not permitted by the issuer`,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(extensionCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             cmapi.CertificateRequestReasonFailed,
+								Message:            `Requested custom extensions are not permitted: extension "1.3.6.1.4.1.311.21.7" is not permitted by the issuer`,
+								LastTransitionTime: &metaFixedClockStart,
+							}),
+							gen.SetCertificateRequestFailureTime(metaFixedClockStart),
+						),
+					)),
+				},
+			},
+		},
+		"a CertificateRequest whose CSR requests an extension not permitted by the issuer should fail": {
+			certificateRequest: csrExtensionCR.DeepCopy(),
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{rsaKeySecret},
+				CertManagerObjects: []runtime.Object{csrExtensionCR.DeepCopy(), csrExtensionIssuer},
+				ExpectedEvents: []string{
+					`Warning CustomExtensionsNotPermitted Extensions requested by the CSR are not permitted: extension "1.3.6.1.4.1.311.21.7" requested by the CSR is not permitted by the issuer`,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(csrExtensionCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             cmapi.CertificateRequestReasonFailed,
+								Message:            `Extensions requested by the CSR are not permitted: extension "1.3.6.1.4.1.311.21.7" requested by the CSR is not permitted by the issuer`,
+								LastTransitionTime: &metaFixedClockStart,
+							}),
+							gen.SetCertificateRequestFailureTime(metaFixedClockStart),
+						),
+					)),
+				},
+			},
+		},
+		"should sign a cert with a custom extension permitted by the issuer": {
+			certificateRequest: extensionCR.DeepCopy(),
+			signingFn: func(c1 *x509.Certificate, c2 *x509.Certificate, pk crypto.PublicKey, sk interface{}) ([]byte, *x509.Certificate, error) {
+				_, cert, err := pki.SignCertificate(c1, c2, pk, sk)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				var found bool
+				for _, ext := range cert.Extensions {
+					if ext.Id.String() == customExtensionOID {
+						if ext.Critical || string(ext.Value) != "\x01\x02\x03" {
+							return nil, nil, fmt.Errorf("unexpected custom extension on issued certificate: %+v", ext)
+						}
+						found = true
+					}
+				}
+				if !found {
+					return nil, nil, errors.New("custom extension not present on issued certificate")
+				}
+
+				return certRSAPEM, nil, nil
+			},
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{rsaKeySecret},
+				CertManagerObjects: []runtime.Object{extensionCR.DeepCopy(), extensionIssuer},
+				ExpectedEvents: []string{
+					"Normal CertificateIssued Certificate fetched from issuer successfully",
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(extensionCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionTrue,
+								Reason:             cmapi.CertificateRequestReasonIssued,
+								Message:            "Certificate fetched from issuer successfully",
+								LastTransitionTime: &metaFixedClockStart,
+							}),
+							gen.SetCertificateRequestCertificate(certRSAPEM),
+							gen.SetCertificateRequestCA(certRSAPEM),
+						),
+					)),
+				},
+			},
+		},
+		"should sign a cert with the requested notBefore": {
+			certificateRequest: notBeforeCR.DeepCopy(),
+			signingFn: func(c1 *x509.Certificate, c2 *x509.Certificate, pk crypto.PublicKey, sk interface{}) ([]byte, *x509.Certificate, error) {
+				_, cert, err := pki.SignCertificate(c1, c2, pk, sk)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				if !cert.NotBefore.Equal(notBefore.Time) || !cert.NotAfter.Equal(notBefore.Add(time.Hour*24)) {
+					return nil, nil, fmt.Errorf("unexpected validity window on issued certificate: %s - %s", cert.NotBefore, cert.NotAfter)
+				}
+
+				return certRSAPEM, nil, nil
+			},
+			builder: &testpkg.Builder{
+				KubeObjects:        []runtime.Object{rsaKeySecret},
+				CertManagerObjects: []runtime.Object{notBeforeCR.DeepCopy(), baseIssuer},
+				ExpectedEvents: []string{
+					"Normal CertificateIssued Certificate fetched from issuer successfully",
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(notBeforeCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionTrue,
+								Reason:             cmapi.CertificateRequestReasonIssued,
+								Message:            "Certificate fetched from issuer successfully",
+								LastTransitionTime: &metaFixedClockStart,
+							}),
+							gen.SetCertificateRequestCertificate(certRSAPEM),
+							gen.SetCertificateRequestCA(certRSAPEM),
+						),
+					)),
+				},
+			},
+		},
 		"should sign a cert with no subject DN and create a warning event": {
 			certificateRequest: emptyCR.DeepCopy(),
 			signingFn: func(c1 *x509.Certificate, c2 *x509.Certificate, pk crypto.PublicKey, sk interface{}) ([]byte, *x509.Certificate, error) {
@@ -22,6 +22,8 @@ import (
 	"reflect"
 
 	"github.com/kr/pretty"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,8 +33,10 @@ import (
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/jetstack/cert-manager/pkg/controller/certificaterequests/util"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 )
 
 var (
@@ -121,6 +125,21 @@ func (c *Controller) Sync(ctx context.Context, cr *cmapi.CertificateRequest) (er
 		return nil
 	}
 
+	// The request may have been approved by an external approver, so the
+	// issuer's allowedDomains and SPIFFE trust domain must be enforced
+	// before signing.
+	if err := util.CheckAllowedDomains(issuerObj, crCopy); err != nil {
+		c.reporter.Failed(crCopy, err, util.ReasonDomainNotAllowed,
+			"Certificate request is not permitted by the referenced issuer")
+		return nil
+	}
+
+	if err := util.CheckSPIFFETrustDomain(issuerObj, crCopy); err != nil {
+		c.reporter.Failed(crCopy, err, util.ReasonSPIFFETrustDomainNotAllowed,
+			"Certificate request is not permitted by the referenced issuer")
+		return nil
+	}
+
 	dbg.Info("validating CertificateRequest resource object")
 
 	if len(crCopy.Status.Certificate) > 0 {
@@ -130,8 +149,37 @@ func (c *Controller) Sync(ctx context.Context, cr *cmapi.CertificateRequest) (er
 
 	dbg.Info("invoking sign function as existing certificate does not exist")
 
+	ctx, span := c.tracer.Start(tracing.ContextFromObject(ctx, cr), "certificaterequests-issuer-"+c.issuerType, trace.WithAttributes(
+		attribute.String("namespace", cr.Namespace),
+		attribute.String("name", cr.Name),
+	))
+	defer span.End()
+
 	// Attempt to call the Sign function on our issuer
 	resp, err := c.issuer.Sign(ctx, crCopy, issuerObj)
+	if err != nil && util.IsTransientError(err) && c.transientRetryPeriod > 0 {
+		log.Error(err, "error issuing certificate request")
+		key, keyErr := keyFunc(crCopy)
+		if keyErr != nil {
+			return err
+		}
+		// Transient errors, such as the signer being unreachable, are
+		// retried after a fixed period rather than with exponential back-off
+		// so that requests are signed soon after the signer recovers. If the
+		// signer is still failing after a number of retries it is likely to
+		// be unavailable for a while, so the error is returned to retry with
+		// the exponential back-off of the workqueue instead.
+		if c.transientRetries.NumRequeues(key) < maxTransientRetries {
+			c.scheduledWorkQueue.Add(key, c.transientRetries.When(key))
+			return nil
+		}
+		return err
+	}
+
+	if key, keyErr := keyFunc(crCopy); keyErr == nil {
+		c.transientRetries.Forget(key)
+	}
+
 	if err != nil {
 		log.Error(err, "error issuing certificate request")
 		return err
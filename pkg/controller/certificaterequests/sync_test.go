@@ -26,9 +26,14 @@ import (
 	"encoding/asn1"
 	"encoding/pem"
 	"errors"
+	"net/url"
+	"reflect"
 	"testing"
 	"time"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
@@ -38,11 +43,14 @@ import (
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"github.com/jetstack/cert-manager/pkg/controller/certificaterequests/fake"
+	crutil "github.com/jetstack/cert-manager/pkg/controller/certificaterequests/util"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
 	"github.com/jetstack/cert-manager/pkg/issuer"
 	issuerfake "github.com/jetstack/cert-manager/pkg/issuer/fake"
 	_ "github.com/jetstack/cert-manager/pkg/issuer/selfsigned"
+	schedulertest "github.com/jetstack/cert-manager/pkg/scheduler/test"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
@@ -141,6 +149,11 @@ func TestSync(t *testing.T) {
 		}),
 	)
 
+	spiffeCSR, _, err := gen.CSR(x509.RSA, gen.SetCSRURIs(&url.URL{Scheme: "spiffe", Host: "evil.org", Path: "/ns/default/sa/app"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	certRSAPEM := generateSelfSignedCert(t, baseCR, skRSA, fixedClockStart, fixedClockStart.Add(time.Hour*12))
 	certRSAPEMExpired := generateSelfSignedCert(t, baseCR, skRSA, fixedClockStart.Add(-time.Hour*13), fixedClockStart.Add(-time.Hour*12))
 
@@ -577,6 +590,108 @@ func TestSync(t *testing.T) {
 				},
 			},
 		},
+		"if the request has a DNS name that is not permitted by the issuer's allowedDomains then we fail": {
+			certificateRequest: baseCR.DeepCopy(),
+			issuerImpl: &fake.Issuer{
+				FakeSign: func(context.Context, *cmapi.CertificateRequest, cmapi.GenericIssuer) (*issuer.IssueResponse, error) {
+					return nil, errors.New("sign should not be called")
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{baseCR.DeepCopy(),
+					gen.IssuerFrom(baseIssuer, gen.SetIssuerAllowedDomains("example.com")),
+				},
+				ExpectedEvents: []string{
+					`Warning DomainNotAllowed Certificate request is not permitted by the referenced issuer: the DNS names [test] are not permitted by the allowedDomains [example.com] of Issuer "test-issuer"`,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(baseCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             "Failed",
+								Message:            `Certificate request is not permitted by the referenced issuer: the DNS names [test] are not permitted by the allowedDomains [example.com] of Issuer "test-issuer"`,
+								LastTransitionTime: &nowMetaTime,
+							}),
+							gen.SetCertificateRequestFailureTime(nowMetaTime),
+						),
+					)),
+				},
+			},
+		},
+		"if the request has a SPIFFE ID that does not belong to the issuer's SPIFFE trust domain then we fail": {
+			certificateRequest: gen.CertificateRequestFrom(baseCR, gen.SetCertificateRequestCSR(spiffeCSR)),
+			issuerImpl: &fake.Issuer{
+				FakeSign: func(context.Context, *cmapi.CertificateRequest, cmapi.GenericIssuer) (*issuer.IssueResponse, error) {
+					return nil, errors.New("sign should not be called")
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{gen.CertificateRequestFrom(baseCR, gen.SetCertificateRequestCSR(spiffeCSR)),
+					gen.IssuerFrom(baseIssuer, gen.SetIssuerSPIFFETrustDomain("example.org")),
+				},
+				ExpectedEvents: []string{
+					`Warning SPIFFETrustDomainNotAllowed Certificate request is not permitted by the referenced issuer: the SPIFFE IDs [spiffe://evil.org/ns/default/sa/app] do not belong to the trust domain "example.org" of Issuer "test-issuer"`,
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(baseCR,
+							gen.SetCertificateRequestCSR(spiffeCSR),
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             "Failed",
+								Message:            `Certificate request is not permitted by the referenced issuer: the SPIFFE IDs [spiffe://evil.org/ns/default/sa/app] do not belong to the trust domain "example.org" of Issuer "test-issuer"`,
+								LastTransitionTime: &nowMetaTime,
+							}),
+							gen.SetCertificateRequestFailureTime(nowMetaTime),
+						),
+					)),
+				},
+			},
+		},
+		"if the request only has DNS names permitted by the issuer's allowedDomains then we sign": {
+			certificateRequest: baseCR.DeepCopy(),
+			issuerImpl: &fake.Issuer{
+				FakeSign: func(context.Context, *cmapi.CertificateRequest, cmapi.GenericIssuer) (*issuer.IssueResponse, error) {
+					return &issuer.IssueResponse{
+						Certificate: certRSAPEM,
+					}, nil
+				},
+			},
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{baseCR.DeepCopy(),
+					gen.IssuerFrom(baseIssuer, gen.SetIssuerAllowedDomains("test")),
+				},
+				ExpectedEvents: []string{
+					"Normal CertificateIssued Certificate fetched from issuer successfully",
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(baseCR,
+							gen.SetCertificateRequestCertificate(certRSAPEM),
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionTrue,
+								Reason:             "Issued",
+								Message:            "Certificate fetched from issuer successfully",
+								LastTransitionTime: &nowMetaTime,
+							}),
+						),
+					)),
+				},
+			},
+		},
 		"if calling sign returns a response with a valid RSA signed certificate then set condition Ready": {
 			certificateRequest: baseCR.DeepCopy(),
 			issuerImpl: &fake.Issuer{
@@ -760,3 +875,195 @@ func runTest(t *testing.T, test testT) {
 	}
 	test.builder.CheckAndFinish(err)
 }
+
+func TestSyncTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	skRSA, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuerObj := gen.Issuer("test-issuer",
+		gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}),
+		gen.AddIssuerCondition(cmapi.IssuerCondition{
+			Type:   cmapi.IssuerConditionReady,
+			Status: cmmeta.ConditionTrue,
+		}),
+	)
+
+	// The CertificateRequest carries the trace context of the span that
+	// created it.
+	requestCtx, requestSpan := tracing.Tracer(provider).Start(context.Background(), "certificates-request-manager")
+	requestSpan.End()
+	cr := gen.CertificateRequest("test-cr",
+		gen.SetCertificateRequestCSR(generateCSR(t, skRSA, x509.SHA256WithRSA)),
+		gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+			Kind: issuerObj.Kind,
+			Name: issuerObj.Name,
+		}),
+		gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+			Type:   cmapi.CertificateRequestConditionApproved,
+			Status: cmmeta.ConditionTrue,
+			Reason: "cert-manager.io",
+		}),
+	)
+	tracing.AnnotateObject(requestCtx, cr)
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		CertManagerObjects: []runtime.Object{cr.DeepCopy(), issuerObj},
+	}
+	builder.Init()
+	builder.Context.TracerProvider = provider
+	defer builder.Stop()
+
+	var signSpan trace.SpanContext
+	c := New(util.IssuerSelfSigned, &fake.Issuer{
+		FakeSign: func(ctx context.Context, _ *cmapi.CertificateRequest, _ cmapi.GenericIssuer) (*issuer.IssueResponse, error) {
+			signSpan = trace.SpanContextFromContext(ctx)
+			return nil, nil
+		},
+	})
+	c.Register(builder.Context)
+	builder.Start()
+
+	if err := c.Sync(context.Background(), cr); err != nil {
+		t.Errorf("expected to not get an error, but got: %v", err)
+	}
+	builder.CheckAndFinish()
+
+	// The signing span is a child of the span that created the
+	// CertificateRequest, and is passed to the issuer.
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	span := spans[1]
+	if exp := "certificaterequests-issuer-selfsigned"; span.Name() != exp {
+		t.Errorf("unexpected span name, exp=%s got=%s", exp, span.Name())
+	}
+	if span.Parent().SpanID() != requestSpan.SpanContext().SpanID() {
+		t.Errorf("unexpected parent span, exp=%s got=%s", requestSpan.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+	if signSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("expected the signing span to be passed to the issuer, got=%s", signSpan.SpanID())
+	}
+}
+
+func TestSyncTransientSigningError(t *testing.T) {
+	skRSA, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuerObj := gen.Issuer("test-issuer",
+		gen.SetIssuerSelfSigned(cmapi.SelfSignedIssuer{}),
+		gen.AddIssuerCondition(cmapi.IssuerCondition{
+			Type:   cmapi.IssuerConditionReady,
+			Status: cmmeta.ConditionTrue,
+		}),
+	)
+	cr := gen.CertificateRequest("test-cr",
+		gen.SetCertificateRequestNamespace(gen.DefaultTestNamespace),
+		gen.SetCertificateRequestCSR(generateCSR(t, skRSA, x509.SHA256WithRSA)),
+		gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+			Kind: issuerObj.Kind,
+			Name: issuerObj.Name,
+		}),
+		gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+			Type:   cmapi.CertificateRequestConditionApproved,
+			Status: cmmeta.ConditionTrue,
+			Reason: "cert-manager.io",
+		}),
+	)
+
+	tests := map[string]struct {
+		signErr         error
+		retryPeriod     time.Duration
+		previousRetries int
+
+		expectedErr      bool
+		expectedSchedule bool
+	}{
+		"a transient error is retried after the retry period": {
+			signErr:          &crutil.TransientError{Err: errors.New("connection refused")},
+			retryPeriod:      5 * time.Second,
+			expectedSchedule: true,
+		},
+		"a transient error is retried after the retry period until the maximum number of retries": {
+			signErr:          &crutil.TransientError{Err: errors.New("connection refused")},
+			retryPeriod:      5 * time.Second,
+			previousRetries:  maxTransientRetries - 1,
+			expectedSchedule: true,
+		},
+		"a transient error is retried with back-off once the maximum number of retries is reached": {
+			signErr:         &crutil.TransientError{Err: errors.New("connection refused")},
+			retryPeriod:     5 * time.Second,
+			previousRetries: maxTransientRetries,
+			expectedErr:     true,
+		},
+		"a transient error is retried with back-off if the retry period is zero": {
+			signErr:     &crutil.TransientError{Err: errors.New("connection refused")},
+			expectedErr: true,
+		},
+		"an error that is not transient is retried with back-off": {
+			signErr:     errors.New("unexpected error"),
+			retryPeriod: 5 * time.Second,
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{
+				T:                  t,
+				Clock:              fixedClock,
+				CertManagerObjects: []runtime.Object{cr.DeepCopy(), issuerObj},
+			}
+			builder.Init()
+			builder.Context.IssuerOptions.TransientSigningErrorRetryPeriod = test.retryPeriod
+			defer builder.Stop()
+
+			c := New(util.IssuerSelfSigned, &fake.Issuer{
+				FakeSign: func(context.Context, *cmapi.CertificateRequest, cmapi.GenericIssuer) (*issuer.IssueResponse, error) {
+					return nil, test.signErr
+				},
+			})
+			c.Register(builder.Context)
+			for i := 0; i < test.previousRetries; i++ {
+				c.transientRetries.When(gen.DefaultTestNamespace + "/test-cr")
+			}
+
+			var scheduled []string
+			c.scheduledWorkQueue = &schedulertest.FakeScheduler{
+				AddFunc: func(obj interface{}, duration time.Duration) {
+					if duration != test.retryPeriod {
+						t.Errorf("expected the request to be retried after %s, got %s", test.retryPeriod, duration)
+					}
+					scheduled = append(scheduled, obj.(string))
+				},
+			}
+			builder.Start()
+
+			err := c.Sync(context.Background(), cr)
+			if err != nil && !test.expectedErr {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("expected to get an error but did not get one")
+			}
+
+			expectedScheduled := []string(nil)
+			if test.expectedSchedule {
+				expectedScheduled = []string{gen.DefaultTestNamespace + "/test-cr"}
+			}
+			if !reflect.DeepEqual(expectedScheduled, scheduled) {
+				t.Errorf("expected scheduled requests %v, got %v", expectedScheduled, scheduled)
+			}
+			builder.CheckAndFinish()
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	// ReasonDomainNotAllowed is the reason used when a CertificateRequest
+	// requests a DNS name that is not permitted by the allowedDomains of the
+	// referenced issuer.
+	ReasonDomainNotAllowed = "DomainNotAllowed"
+
+	// oidUserPrincipalName is the OID of the userPrincipalName otherName.
+	oidUserPrincipalName = "1.3.6.1.4.1.311.20.2.3"
+)
+
+// CheckAllowedDomains returns an error describing the DNS names requested by
+// the CertificateRequest that are not permitted by the allowedDomains of the
+// given issuer. The common name of the request is checked as well as its DNS
+// names, as it is commonly treated as a DNS name by clients.
+// An error is also returned if the request cannot be decoded.
+func CheckAllowedDomains(issuerObj cmapi.GenericIssuer, cr *cmapi.CertificateRequest) error {
+	return CheckRequestAllowedDomains(issuerObj, apiutil.IssuerKind(cr.Spec.IssuerRef), cr.Spec.Request)
+}
+
+// CheckRequestAllowedDomains is CheckAllowedDomains for the given PEM encoded
+// certificate request, such as that of a CertificateSigningRequest, which
+// references an issuer of the given kind. The domains of userPrincipalName
+// otherNames are also checked. Other otherNames are not permitted by
+// allowedDomains, as their values are not domains that can be checked.
+func CheckRequestAllowedDomains(issuerObj cmapi.GenericIssuer, issuerKind string, request []byte) error {
+	allowedDomains := issuerObj.GetSpec().AllowedDomains
+	if len(allowedDomains) == 0 {
+		return nil
+	}
+
+	csr, err := pki.DecodeX509CertificateRequestBytes(request)
+	if err != nil {
+		return fmt.Errorf("failed to decode certificate request to check its DNS names: %w", err)
+	}
+
+	dnsNames := csr.DNSNames
+	if cn := csr.Subject.CommonName; len(cn) > 0 && !containsString(dnsNames, cn) {
+		dnsNames = append([]string{cn}, dnsNames...)
+	}
+
+	if disallowed := apiutil.DisallowedDNSNames(allowedDomains, dnsNames); len(disallowed) > 0 {
+		return fmt.Errorf("the DNS names [%s] are not permitted by the allowedDomains [%s] of %s %q",
+			strings.Join(disallowed, ", "), strings.Join(allowedDomains, ", "),
+			issuerKind, issuerObj.GetObjectMeta().Name)
+	}
+
+	otherNames, err := pki.OtherNamesFromExtensions(csr.Extensions)
+	if err != nil {
+		return fmt.Errorf("failed to decode certificate request to check its otherNames: %w", err)
+	}
+
+	var disallowed []string
+	for _, name := range otherNames {
+		if !otherNameAllowed(allowedDomains, name) {
+			disallowed = append(disallowed, name.OID+"="+name.UTF8Value)
+		}
+	}
+	if len(disallowed) > 0 {
+		return fmt.Errorf("the otherNames [%s] are not permitted by the allowedDomains [%s] of %s %q",
+			strings.Join(disallowed, ", "), strings.Join(allowedDomains, ", "),
+			issuerKind, issuerObj.GetObjectMeta().Name)
+	}
+
+	return nil
+}
+
+// otherNameAllowed returns true if the otherName is a userPrincipalName whose
+// domain is permitted by the given allowedDomains.
+func otherNameAllowed(allowedDomains []string, name cmapi.OtherName) bool {
+	if name.OID != oidUserPrincipalName {
+		return false
+	}
+	i := strings.LastIndex(name.UTF8Value, "@")
+	if i < 0 {
+		return false
+	}
+	return apiutil.DNSNameAllowed(allowedDomains, name.UTF8Value[i+1:])
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/pem"
+	"testing"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+func TestCheckRequestAllowedDomainsOtherNames(t *testing.T) {
+	sk, err := pki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := gen.ClusterIssuer("world", gen.SetIssuerAllowedDomains("*.example.com"))
+
+	tests := map[string]struct {
+		otherName cmapi.OtherName
+		wantErr   bool
+	}{
+		"a user principal name within the allowed domains is permitted": {
+			otherName: cmapi.OtherName{OID: oidUserPrincipalName, UTF8Value: "alice@corp.example.com"},
+		},
+		"a user principal name outside of the allowed domains is not permitted": {
+			otherName: cmapi.OtherName{OID: oidUserPrincipalName, UTF8Value: "alice@example.org"},
+			wantErr:   true,
+		},
+		"an otherName with an unknown OID is not permitted": {
+			otherName: cmapi.OtherName{OID: "1.2.3.4", UTF8Value: "corp.example.com"},
+			wantErr:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template, err := pki.GenerateCSR(gen.Certificate("test",
+				gen.SetCertificateDNSNames("foo.example.com"),
+				func(crt *cmapi.Certificate) { crt.Spec.OtherNames = []cmapi.OtherName{test.otherName} },
+			))
+			if err != nil {
+				t.Fatal(err)
+			}
+			der, err := pki.EncodeCSR(template, sk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+
+			err = CheckRequestAllowedDomains(issuer, cmapi.ClusterIssuerKind, request)
+			if test.wantErr != (err != nil) {
+				t.Errorf("unexpected error, wantErr=%t got=%v", test.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+const (
+	// ReasonTransientSigningError is the reason used when a CertificateRequest
+	// could not be signed because of an error that is likely to be resolved
+	// by retrying.
+	ReasonTransientSigningError = "TransientSigningError"
+)
+
+// TransientError wraps an error returned by an issuer's Sign function that
+// is likely to be resolved by retrying, such as a network error or a server
+// error response from the signer. The CertificateRequest is retried after the
+// transient error retry period rather than with exponential back-off.
+// Errors that are not transient are expected to be permanent, such as the
+// signer rejecting the request because of its policy, and should fail the
+// CertificateRequest instead.
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransientError returns true if err is, or wraps, a TransientError.
+func IsTransientError(err error) bool {
+	var transient *TransientError
+	return errors.As(err, &transient)
+}
+
+// IsTransientNetworkError returns true if err is, or wraps, an error from
+// communicating with a signer that is likely to be resolved by retrying: a
+// network error such as a refused connection or a timeout, or the connection
+// being closed before a full response was read.
+func IsTransientNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsTransientStatusCode returns true if the HTTP status code of a signer's
+// response indicates an error that is likely to be resolved by retrying,
+// i.e. a server error or the signer rate limiting requests.
+func IsTransientStatusCode(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
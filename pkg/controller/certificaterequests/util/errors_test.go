@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+func TestIsTransientNetworkError(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"nil is not transient": {},
+		"an error from the signer's policy is not transient": {
+			err: errors.New("role does not allow the requested common name"),
+		},
+		"a refused connection is transient": {
+			err:  fmt.Errorf("failed to sign certificate by vault: %w", &url.Error{Op: "Put", URL: "https://vault", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}),
+			want: true,
+		},
+		"a timeout is transient": {
+			err:  fmt.Errorf("failed to sign: %w", context.DeadlineExceeded),
+			want: true,
+		},
+		"a connection closed before the response was read is transient": {
+			err:  fmt.Errorf("failed to sign: %w", io.ErrUnexpectedEOF),
+			want: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsTransientNetworkError(test.err); got != test.want {
+				t.Errorf("expected IsTransientNetworkError to return %t but got %t", test.want, got)
+			}
+		})
+	}
+}
+
+func TestIsTransientStatusCode(t *testing.T) {
+	for code, want := range map[int]bool{
+		400: false,
+		403: false,
+		404: false,
+		429: true,
+		500: true,
+		503: true,
+	} {
+		if got := IsTransientStatusCode(code); got != want {
+			t.Errorf("expected IsTransientStatusCode(%d) to return %t but got %t", code, want, got)
+		}
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	if !IsTransientError(fmt.Errorf("wrapped: %w", &TransientError{Err: errors.New("connection refused")})) {
+		t.Error("expected a wrapped TransientError to be transient")
+	}
+	if IsTransientError(errors.New("connection refused")) {
+		t.Error("expected an error that is not a TransientError to not be transient")
+	}
+}
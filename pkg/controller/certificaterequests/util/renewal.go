@@ -0,0 +1,39 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/x509"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// RenewBefore returns the longest time before the expiry of a certificate
+// signed from the given template for the CertificateRequest at which it may
+// be renewed. Renewal never starts earlier than a third of the certificate's
+// duration before its expiry, or earlier than the renewBefore recorded on the
+// CertificateRequest, if any.
+func RenewBefore(cr *cmapi.CertificateRequest, template *x509.Certificate) time.Duration {
+	renewBefore := template.NotAfter.Sub(template.NotBefore) / 3
+	if v, ok := cr.Annotations[cmapi.CertificateRequestRenewBeforeAnnotationKey]; ok {
+		if d, err := time.ParseDuration(v); err == nil && d < renewBefore {
+			renewBefore = d
+		}
+	}
+	return renewBefore
+}
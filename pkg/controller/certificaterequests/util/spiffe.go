@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	// ReasonSPIFFETrustDomainNotAllowed is the reason used when a
+	// CertificateRequest requests a SPIFFE ID that does not belong to the
+	// SPIFFE trust domain of the referenced issuer.
+	ReasonSPIFFETrustDomainNotAllowed = "SPIFFETrustDomainNotAllowed"
+
+	spiffeScheme = "spiffe"
+)
+
+// CheckSPIFFETrustDomain returns an error describing the SPIFFE IDs requested
+// by the CertificateRequest that do not belong to the SPIFFE trust domain of
+// the given issuer. URI SANs that are not SPIFFE IDs are not checked.
+// An error is also returned if the request cannot be decoded.
+func CheckSPIFFETrustDomain(issuerObj cmapi.GenericIssuer, cr *cmapi.CertificateRequest) error {
+	return CheckRequestSPIFFETrustDomain(issuerObj, apiutil.IssuerKind(cr.Spec.IssuerRef), cr.Spec.Request)
+}
+
+// CheckRequestSPIFFETrustDomain is CheckSPIFFETrustDomain for the given PEM
+// encoded certificate request, such as that of a CertificateSigningRequest,
+// which references an issuer of the given kind.
+func CheckRequestSPIFFETrustDomain(issuerObj cmapi.GenericIssuer, issuerKind string, request []byte) error {
+	spiffe := issuerObj.GetSpec().SPIFFE
+	if spiffe == nil || len(spiffe.TrustDomain) == 0 {
+		return nil
+	}
+
+	csr, err := pki.DecodeX509CertificateRequestBytes(request)
+	if err != nil {
+		return fmt.Errorf("failed to decode certificate request to check its SPIFFE IDs: %w", err)
+	}
+
+	var disallowed []string
+	for _, uri := range csr.URIs {
+		if !strings.EqualFold(uri.Scheme, spiffeScheme) {
+			continue
+		}
+		// The trust domain is the host of the SPIFFE ID, so an ID with
+		// user info or a port never belongs to a trust domain.
+		if uri.User != nil || uri.Host != spiffe.TrustDomain {
+			disallowed = append(disallowed, uri.String())
+		}
+	}
+	if len(disallowed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("the SPIFFE IDs [%s] do not belong to the trust domain %q of %s %q",
+		strings.Join(disallowed, ", "), spiffe.TrustDomain,
+		issuerKind, issuerObj.GetObjectMeta().Name)
+}
@@ -18,7 +18,9 @@ package vault
 
 import (
 	"context"
+	"errors"
 
+	vaultapi "github.com/hashicorp/vault/api"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
@@ -83,17 +85,31 @@ func (v *Vault) Sign(ctx context.Context, cr *v1.CertificateRequest, issuerObj v
 		return nil, nil
 	}
 
-	// TODO: distinguish between network errors and other which might warrant a failure.
 	if err != nil {
 		message := "Failed to initialise vault client for signing"
 		v.reporter.Pending(cr, err, "VaultInitError", message)
 		log.Error(err, message)
+		if isTransientVaultError(err) {
+			return nil, &crutil.TransientError{Err: err}
+		}
 		return nil, nil
 	}
 
 	certDuration := apiutil.DefaultCertDuration(cr.Spec.Duration)
 	certPem, caPem, err := client.Sign(cr.Spec.Request, certDuration)
 	if err != nil {
+		// Errors from reaching Vault, or from Vault itself being unavailable,
+		// are retried. Any other error, such as the role not permitting the
+		// request, fails the CertificateRequest.
+		if isTransientVaultError(err) {
+			message := "Vault failed to sign certificate, will retry"
+
+			v.reporter.Pending(cr, err, crutil.ReasonTransientSigningError, message)
+			log.Error(err, message)
+
+			return nil, &crutil.TransientError{Err: err}
+		}
+
 		message := "Vault failed to sign certificate"
 
 		v.reporter.Failed(cr, err, "SigningError", message)
@@ -109,3 +125,14 @@ func (v *Vault) Sign(ctx context.Context, cr *v1.CertificateRequest, issuerObj v
 		CA:          caPem,
 	}, nil
 }
+
+// isTransientVaultError returns true if err is a network error from
+// communicating with Vault, or an error response from Vault that indicates it
+// is temporarily unable to handle the request.
+func isTransientVaultError(err error) bool {
+	var respErr *vaultapi.ResponseError
+	if errors.As(err, &respErr) {
+		return crutil.IsTransientStatusCode(respErr.StatusCode)
+	}
+	return crutil.IsTransientNetworkError(err)
+}
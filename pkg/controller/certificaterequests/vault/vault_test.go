@@ -30,6 +30,7 @@ import (
 	"testing"
 	"time"
 
+	vaultapi "github.com/hashicorp/vault/api"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -97,6 +98,15 @@ func generateSelfSignedCertFromCR(cr *cmapi.CertificateRequest, key crypto.Signe
 
 func TestSign(t *testing.T) {
 	metaFixedClockStart := metav1.NewTime(fixedClockStart)
+
+	// Vault being sealed is transient and the request is retried, but the
+	// role not permitting the request is not.
+	vaultUnavailableErr := fmt.Errorf("failed to sign certificate by vault: %w", &vaultapi.ResponseError{
+		HTTPMethod: "PUT", URL: "https://vault.example.com/v1/pki/sign/role", StatusCode: 503, Errors: []string{"Vault is sealed"},
+	})
+	vaultRejectedErr := fmt.Errorf("failed to sign certificate by vault: %w", &vaultapi.ResponseError{
+		HTTPMethod: "PUT", URL: "https://vault.example.com/v1/pki/sign/role", StatusCode: 400, Errors: []string{"common name not allowed by this role"},
+	})
 	baseIssuer := gen.Issuer("vault-issuer",
 		gen.SetIssuerVault(cmapi.VaultIssuer{}),
 		gen.AddIssuerCondition(cmapi.IssuerCondition{
@@ -372,6 +382,84 @@ func TestSign(t *testing.T) {
 			},
 			fakeVault: fakevault.New().WithSign(nil, nil, errors.New("failed to sign")),
 		},
+		"a client with a token secret referenced with token but Vault unavailable should report pending and retry": {
+			certificateRequest: baseCR.DeepCopy(),
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{tokenSecret},
+				CertManagerObjects: []runtime.Object{baseCR.DeepCopy(), gen.IssuerFrom(baseIssuer,
+					gen.SetIssuerVault(cmapi.VaultIssuer{
+						Auth: cmapi.VaultAuth{
+							TokenSecretRef: &cmmeta.SecretKeySelector{
+								Key: "my-token-key",
+								LocalObjectReference: cmmeta.LocalObjectReference{
+									Name: "token-secret",
+								},
+							},
+						},
+					}),
+				)},
+				ExpectedEvents: []string{
+					"Normal TransientSigningError Vault failed to sign certificate, will retry: " + vaultUnavailableErr.Error(),
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(baseCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             cmapi.CertificateRequestReasonPending,
+								Message:            "Vault failed to sign certificate, will retry: " + vaultUnavailableErr.Error(),
+								LastTransitionTime: &metaFixedClockStart,
+							}),
+						),
+					)),
+				},
+			},
+			fakeVault:   fakevault.New().WithSign(nil, nil, vaultUnavailableErr),
+			expectedErr: true,
+		},
+		"a client with a token secret referenced with token but rejected by Vault should report fail": {
+			certificateRequest: baseCR.DeepCopy(),
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{tokenSecret},
+				CertManagerObjects: []runtime.Object{baseCR.DeepCopy(), gen.IssuerFrom(baseIssuer,
+					gen.SetIssuerVault(cmapi.VaultIssuer{
+						Auth: cmapi.VaultAuth{
+							TokenSecretRef: &cmmeta.SecretKeySelector{
+								Key: "my-token-key",
+								LocalObjectReference: cmmeta.LocalObjectReference{
+									Name: "token-secret",
+								},
+							},
+						},
+					}),
+				)},
+				ExpectedEvents: []string{
+					"Warning SigningError Vault failed to sign certificate: " + vaultRejectedErr.Error(),
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+						"status",
+						gen.DefaultTestNamespace,
+						gen.CertificateRequestFrom(baseCR,
+							gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+								Type:               cmapi.CertificateRequestConditionReady,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             cmapi.CertificateRequestReasonFailed,
+								Message:            "Vault failed to sign certificate: " + vaultRejectedErr.Error(),
+								LastTransitionTime: &metaFixedClockStart,
+							}),
+							gen.SetCertificateRequestFailureTime(metaFixedClockStart),
+						),
+					)),
+				},
+			},
+			fakeVault: fakevault.New().WithSign(nil, nil, vaultRejectedErr),
+		},
 		"a client with a app role secret referenced with role but failed to sign should report fail": {
 			certificateRequest: baseCR.DeepCopy(),
 			builder: &testpkg.Builder{
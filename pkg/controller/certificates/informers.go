@@ -21,10 +21,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/util/predicate"
 )
@@ -67,3 +70,46 @@ func EnqueueCertificatesForResourceUsingPredicates(log logr.Logger, queue workqu
 		}
 	}
 }
+
+// EnqueueCertificatesForIssuers adds event handlers to the Issuer informer,
+// and the ClusterIssuer informer if cert-manager is not scoped to a single
+// namespace, of the given context that enqueue the Certificates referencing
+// an issuer in either 'spec.issuerRef' or 'spec.issuerRefs' when it changes.
+func EnqueueCertificatesForIssuers(ctx *controllerpkg.Context, log logr.Logger, queue workqueue.Interface, lister cmlisters.CertificateLister) {
+	ctx.SharedInformerFactory.Certmanager().V1().Issuers().Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		WorkFunc: EnqueueCertificatesForResourceUsingPredicates(log, queue, lister, labels.Everything(),
+			predicate.ExtractResourceName(issuerRefPredicate(cmapi.IssuerKind))),
+	})
+	if ctx.Namespace == "" {
+		ctx.SharedInformerFactory.Certmanager().V1().ClusterIssuers().Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+			WorkFunc: EnqueueCertificatesForResourceUsingPredicates(log, queue, lister, labels.Everything(),
+				predicate.ExtractResourceName(issuerRefPredicate(cmapi.ClusterIssuerKind))),
+		})
+	}
+}
+
+// issuerRefPredicate returns a function that builds a predicate selecting the
+// Certificates that reference the issuer of the given kind and name.
+func issuerRefPredicate(kind string) func(string) predicate.Func {
+	return func(name string) predicate.Func {
+		return predicate.CertificateIssuerRef(kind, name)
+	}
+}
+
+// NewIssuerHelper returns an issuer.Helper that reads Issuers, and
+// ClusterIssuers if cert-manager is not scoped to a single namespace, using
+// the shared informers of the given context. The InformerSynced functions of
+// the informers used are also returned.
+func NewIssuerHelper(ctx *controllerpkg.Context) (issuer.Helper, []cache.InformerSynced) {
+	issuerInformer := ctx.SharedInformerFactory.Certmanager().V1().Issuers()
+	mustSync := []cache.InformerSynced{issuerInformer.Informer().HasSynced}
+
+	var clusterIssuerLister cmlisters.ClusterIssuerLister
+	if ctx.Namespace == "" {
+		clusterIssuerInformer := ctx.SharedInformerFactory.Certmanager().V1().ClusterIssuers()
+		mustSync = append(mustSync, clusterIssuerInformer.Informer().HasSynced)
+		clusterIssuerLister = clusterIssuerInformer.Lister()
+	}
+
+	return issuer.NewHelper(issuerInformer.Lister(), clusterIssuerLister), mustSync
+}
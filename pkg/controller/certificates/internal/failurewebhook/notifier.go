@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package failurewebhook delivers notifications of failed Certificate
+// issuances to an external webhook.
+package failurewebhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/metrics"
+	"github.com/jetstack/cert-manager/pkg/util"
+)
+
+// defaultBackoff is the backoff used when retrying delivery of an issuance
+// failure notification. Delivery is attempted up to 5 times over roughly 15
+// seconds.
+var defaultBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Steps:    5,
+}
+
+const (
+	// attemptTimeout is the timeout of a single delivery attempt.
+	attemptTimeout = 10 * time.Second
+
+	// deliveryTimeout bounds the total time spent delivering a single
+	// notification, including retries.
+	deliveryTimeout = time.Minute
+
+	// queueSize is the number of notifications that may be waiting for
+	// delivery. Notifications are dropped while the queue is full.
+	queueSize = 100
+)
+
+// payload is the JSON body sent to the issuance failure webhook.
+type payload struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+// Notifier delivers notifications of failed issuances to an external webhook,
+// retrying with backoff. Notifications are queued and delivered by a single
+// worker so that a slow or unavailable receiver neither holds up the
+// controller nor accumulates goroutines.
+type Notifier struct {
+	log     logr.Logger
+	url     string
+	client  *http.Client
+	backoff wait.Backoff
+	metrics *metrics.Metrics
+	queue   chan payload
+}
+
+// New returns a Notifier that POSTs issuance failure notifications to the
+// given URL. Notifications are not delivered until Start is called.
+func New(log logr.Logger, url string, metrics *metrics.Metrics) *Notifier {
+	return &Notifier{
+		log:     log.WithName("failure-webhook"),
+		url:     url,
+		client:  &http.Client{Timeout: attemptTimeout},
+		backoff: defaultBackoff,
+		metrics: metrics,
+		queue:   make(chan payload, queueSize),
+	}
+}
+
+// Start starts the worker that delivers queued notifications. The worker
+// stops, abandoning any in-flight delivery, once the given context is
+// cancelled.
+func (n *Notifier) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case p := <-n.queue:
+				n.process(ctx, p)
+			}
+		}
+	}()
+}
+
+func (n *Notifier) process(ctx context.Context, p payload) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	if err := n.deliver(ctx, p); err != nil {
+		n.log.Error(err, "failed to deliver issuance failure notification", "url", n.url, "resource_namespace", p.Namespace, "resource_name", p.Name)
+		n.metrics.IncrementFailureWebhookDeliveryFailures()
+	}
+}
+
+// Notify queues a notification that issuance of the given Certificate has
+// failed with the given reason and message. It never blocks; if the queue is
+// full the notification is dropped and counted as a delivery failure.
+func (n *Notifier) Notify(crt *cmapi.Certificate, reason, message string) {
+	p := payload{
+		Name:      crt.Name,
+		Namespace: crt.Namespace,
+		Reason:    reason,
+		Message:   message,
+	}
+
+	select {
+	case n.queue <- p:
+	default:
+		logf.WithResource(n.log, crt).Error(nil, "dropping issuance failure notification as the delivery queue is full", "url", n.url)
+		n.metrics.IncrementFailureWebhookDeliveryFailures()
+	}
+}
+
+// deliver POSTs the payload to the webhook, retrying until it is accepted with
+// a 2xx response, the backoff is exhausted or the context is cancelled.
+func (n *Notifier) deliver(ctx context.Context, p payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	err = wait.ExponentialBackoffWithContext(ctx, n.backoff, func() (bool, error) {
+		if lastErr = n.post(ctx, body); lastErr != nil {
+			n.log.V(logf.DebugLevel).Info("issuance failure notification not delivered, retrying", "error", lastErr.Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout && lastErr != nil {
+		return lastErr
+	}
+	return err
+}
+
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", util.CertManagerUserAgent)
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status code %d", res.StatusCode)
+	}
+	return nil
+}
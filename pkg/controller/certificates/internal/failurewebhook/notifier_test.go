@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failurewebhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/metrics"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+var testBackoff = wait.Backoff{
+	Duration: time.Millisecond,
+	Factor:   1,
+	Steps:    3,
+}
+
+// fakeFailureReceiver is an HTTP server that records the issuance failure
+// payloads it receives. The first `failures` requests are rejected.
+type fakeFailureReceiver struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	failures int
+	attempts int
+	received []payload
+}
+
+func newFakeFailureReceiver(t *testing.T, failures int) *fakeFailureReceiver {
+	r := &fakeFailureReceiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		r.attempts++
+		if r.attempts <= r.failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("unexpected Content-Type %q", ct)
+		}
+		var payload payload
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		r.received = append(r.received, payload)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeFailureReceiver) attemptCount() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.attempts
+}
+
+func (r *fakeFailureReceiver) payloads() []payload {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]payload(nil), r.received...)
+}
+
+func testNotifier(url string) *Notifier {
+	n := New(logf.Log, url, metrics.New(logf.Log))
+	n.backoff = testBackoff
+	return n
+}
+
+func TestNotifierDeliver(t *testing.T) {
+	p := payload{
+		Name:      "test",
+		Namespace: "testns",
+		Reason:    "Failed",
+		Message:   "The certificate request has failed to complete and will be retried: boom",
+	}
+
+	tests := map[string]struct {
+		// number of requests the receiver rejects before accepting one
+		failures int
+
+		expErr      bool
+		expAttempts int
+		expReceived []payload
+	}{
+		"should deliver the payload on the first attempt": {
+			expAttempts: 1,
+			expReceived: []payload{p},
+		},
+		"should retry delivery if the receiver rejects the request": {
+			failures:    2,
+			expAttempts: 3,
+			expReceived: []payload{p},
+		},
+		"should give up after exhausting the backoff": {
+			failures:    3,
+			expErr:      true,
+			expAttempts: 3,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newFakeFailureReceiver(t, test.failures)
+
+			err := testNotifier(r.URL).deliver(context.Background(), p)
+			if test.expErr != (err != nil) {
+				t.Fatalf("expected error=%t, got: %v", test.expErr, err)
+			}
+			if attempts := r.attemptCount(); attempts != test.expAttempts {
+				t.Errorf("expected %d delivery attempts, got: %d", test.expAttempts, attempts)
+			}
+			if got := r.payloads(); !reflect.DeepEqual(got, test.expReceived) {
+				t.Errorf("unexpected payloads received, exp=%+v got=%+v", test.expReceived, got)
+			}
+		})
+	}
+}
+
+func TestNotifierNotify(t *testing.T) {
+	r := newFakeFailureReceiver(t, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := testNotifier(r.URL)
+	n.Start(ctx)
+
+	n.Notify(gen.Certificate("test", gen.SetCertificateNamespace("testns")), "Failed", "boom")
+
+	exp := []payload{{
+		Name:      "test",
+		Namespace: "testns",
+		Reason:    "Failed",
+		Message:   "boom",
+	}}
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(r.payloads()) > 0, nil
+	})
+	if err != nil {
+		t.Fatal("timed out waiting for the issuance failure notification")
+	}
+	if got := r.payloads(); !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected payloads received, exp=%+v got=%+v", exp, got)
+	}
+}
+
+func TestNotifierNotifyQueueFull(t *testing.T) {
+	r := newFakeFailureReceiver(t, 0)
+
+	// The worker is never started, so nothing is taken off the queue.
+	n := testNotifier(r.URL)
+	crt := gen.Certificate("test", gen.SetCertificateNamespace("testns"))
+	for i := 0; i < queueSize+1; i++ {
+		n.Notify(crt, "Failed", "boom")
+	}
+
+	if l := len(n.queue); l != queueSize {
+		t.Errorf("expected %d queued notifications, got: %d", queueSize, l)
+	}
+}
+
+func TestNotifierDeliverContextCancelled(t *testing.T) {
+	r := newFakeFailureReceiver(t, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := testNotifier(r.URL).deliver(ctx, payload{Name: "test"}); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	if attempts := r.attemptCount(); attempts != 0 {
+		t.Errorf("expected no delivery attempts, got: %d", attempts)
+	}
+}
@@ -169,3 +169,99 @@ func encodeJKSTruststore(password []byte, caPem []byte) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+// pkcs12KeystoresEqual returns true if the PKCS12 keystores a and b can both
+// be decrypted using the password provided, and contain the same private key
+// and certificates.
+func pkcs12KeystoresEqual(password string, a, b []byte) bool {
+	keyA, certA, casA, err := pkcs12.DecodeChain(a, password)
+	if err != nil {
+		return false
+	}
+	keyB, certB, casB, err := pkcs12.DecodeChain(b, password)
+	if err != nil {
+		return false
+	}
+	derA, err := x509.MarshalPKCS8PrivateKey(keyA)
+	if err != nil {
+		return false
+	}
+	derB, err := x509.MarshalPKCS8PrivateKey(keyB)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(derA, derB) && certA.Equal(certB) && certificatesEqual(casA, casB)
+}
+
+// pkcs12TruststoresEqual returns true if the PKCS12 trust stores a and b can
+// both be decrypted using the password provided, and contain the same
+// certificates.
+func pkcs12TruststoresEqual(password string, a, b []byte) bool {
+	certsA, err := pkcs12.DecodeTrustStore(a, password)
+	if err != nil {
+		return false
+	}
+	certsB, err := pkcs12.DecodeTrustStore(b, password)
+	if err != nil {
+		return false
+	}
+	return certificatesEqual(certsA, certsB)
+}
+
+// jksKeystoresEqual returns true if the JKS keystores a and b can both be
+// decrypted using the password provided, and contain the same entries. The
+// creation dates of the entries are ignored.
+func jksKeystoresEqual(password []byte, a, b []byte) bool {
+	ksA, err := jks.Decode(bytes.NewReader(a), password)
+	if err != nil {
+		return false
+	}
+	ksB, err := jks.Decode(bytes.NewReader(b), password)
+	if err != nil {
+		return false
+	}
+	if len(ksA) != len(ksB) {
+		return false
+	}
+	for alias, entryA := range ksA {
+		switch entryA := entryA.(type) {
+		case *jks.PrivateKeyEntry:
+			entryB, ok := ksB[alias].(*jks.PrivateKeyEntry)
+			if !ok || !bytes.Equal(entryA.PrivKey, entryB.PrivKey) || !jksCertificatesEqual(entryA.CertChain, entryB.CertChain) {
+				return false
+			}
+		case *jks.TrustedCertificateEntry:
+			entryB, ok := ksB[alias].(*jks.TrustedCertificateEntry)
+			if !ok || !jksCertificatesEqual([]jks.Certificate{entryA.Certificate}, []jks.Certificate{entryB.Certificate}) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func certificatesEqual(a, b []*x509.Certificate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func jksCertificatesEqual(a, b []jks.Certificate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || !bytes.Equal(a[i].Content, b[i].Content) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretsmanager
+
+import (
+	"bytes"
+	"fmt"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	// chainPEMSecretKey is the name of the data entry in the Secret resource
+	// used to store the intermediate certificates of the signed chain.
+	chainPEMSecretKey = "chain.pem"
+)
+
+// hasOutputFormat returns true if the Certificate requests the given
+// additional output format.
+func hasOutputFormat(crt *cmapi.Certificate, formatType cmapi.CertificateOutputFormatType) bool {
+	for _, f := range crt.Spec.AdditionalOutputFormats {
+		if f.Type == formatType {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeIntermediateChainPEM returns the PEM encoded intermediate certificates
+// of the given PEM encoded certificate chain, which must contain the leaf
+// certificate first. The leaf and any self-signed root certificate are
+// excluded, so the result is empty if the chain only contains the leaf.
+func encodeIntermediateChainPEM(certPem []byte) ([]byte, error) {
+	certs, err := pki.DecodeX509CertificateChainBytes(certPem)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := pki.EncodeX509Chain(certs[1:])
+	if err != nil {
+		return nil, err
+	}
+	if chain == nil {
+		chain = []byte{}
+	}
+	return chain, nil
+}
+
+// pemLineEnding returns the line ending selected by the Certificate's
+// cert-manager.io/pem-line-endings annotation, or an empty string if the
+// annotation is not set and the PEM data should be stored unchanged.
+func pemLineEnding(crt *cmapi.Certificate) (string, error) {
+	value, ok := crt.Annotations[cmapi.PEMLineEndingsAnnotationKey]
+	if !ok {
+		return "", nil
+	}
+	switch value {
+	case "LF":
+		return "\n", nil
+	case "CRLF":
+		return "\r\n", nil
+	default:
+		return "", fmt.Errorf("unsupported value %q for annotation %q, must be one of LF or CRLF", value, cmapi.PEMLineEndingsAnnotationKey)
+	}
+}
+
+// withLineEnding returns the given PEM data with every line ending replaced
+// by lineEnding. The data is returned unchanged if lineEnding is empty.
+func withLineEnding(data []byte, lineEnding string) []byte {
+	if lineEnding == "" || len(data) == 0 {
+		return data
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if lineEnding == "\n" {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte("\n"), []byte(lineEnding))
+}
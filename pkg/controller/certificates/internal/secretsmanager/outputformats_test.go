@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretsmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeIntermediateChainPEM(t *testing.T) {
+	chain := mustLeafWithChain(t)
+
+	t.Run("encodeIntermediateChainPEM excludes the leaf and root certificates", func(t *testing.T) {
+		out, err := encodeIntermediateChainPEM(chain.all.certsToPEM())
+		require.NoError(t, err)
+		assert.Equal(t, string(chain.cas[0].certPEM), string(out))
+	})
+
+	t.Run("encodeIntermediateChainPEM includes all intermediates if the chain does not contain a root", func(t *testing.T) {
+		out, err := encodeIntermediateChainPEM(chain.all[:2].certsToPEM())
+		require.NoError(t, err)
+		assert.Equal(t, string(chain.cas[0].certPEM), string(out))
+	})
+
+	t.Run("encodeIntermediateChainPEM returns an empty chain for a single certificate", func(t *testing.T) {
+		out, err := encodeIntermediateChainPEM(chain.leaf.certPEM)
+		require.NoError(t, err)
+		assert.NotNil(t, out)
+		assert.Empty(t, out)
+	})
+
+	t.Run("encodeIntermediateChainPEM errors if the chain cannot be decoded", func(t *testing.T) {
+		_, err := encodeIntermediateChainPEM([]byte("not a certificate"))
+		assert.Error(t, err)
+	})
+}
@@ -23,14 +23,18 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/pointer"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/jetstack/cert-manager/pkg/controller/certificates/secretwriter"
 	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
@@ -43,6 +47,11 @@ type SecretsManager struct {
 	kubeClient   kubernetes.Interface
 	secretLister corelisters.SecretLister
 
+	// secretWriter stores the Secret resource in the Kubernetes apiserver.
+	// Certificates may additionally select an alternate writer using the
+	// cert-manager.io/secret-writer annotation.
+	secretWriter secretwriter.Interface
+
 	// if true, Secret resources created by the controller will have an
 	// 'owner reference' set, meaning when the Certificate is deleted, the
 	// Secret resource will be automatically deleted.
@@ -50,9 +59,32 @@ type SecretsManager struct {
 	enableSecretOwnerReferences bool
 }
 
+const (
+	// manifestSecretKey is the name of the data entry in the Secret resource
+	// used to store the issuance manifest.
+	manifestSecretKey = "manifest.json"
+	// manifestSignatureSecretKey is the name of the data entry in the Secret
+	// resource used to store the detached signature of the manifest.
+	manifestSignatureSecretKey = "manifest.json.sig"
+)
+
 // SecretData is a structure wrapping private key, Certificate and CA data
 type SecretData struct {
 	PrivateKey, Certificate, CA []byte
+
+	// IssuerRef is the issuer that signed Certificate. If nil, the
+	// Certificate's spec.issuerRef is assumed.
+	IssuerRef *cmmeta.ObjectReference
+
+	// Annotations are additional annotations to set on the Secret. They do
+	// not override the annotations managed by cert-manager.
+	Annotations map[string]string
+
+	// Manifest and ManifestSignature, if set, are a JSON manifest of the
+	// issued certificate and a detached signature over it. They are stored
+	// under the manifest.json and manifest.json.sig keys, and removed if
+	// not set so that a stale manifest is never left in the Secret.
+	Manifest, ManifestSignature []byte
 }
 
 // New returns a new SecretsManager. Setting enableSecretOwnerReferences to
@@ -66,6 +98,7 @@ func New(
 	return &SecretsManager{
 		kubeClient:                  kubeClient,
 		secretLister:                secretLister,
+		secretWriter:                secretwriter.NewKubernetes(kubeClient, secretLister),
 		enableSecretOwnerReferences: enableSecretOwnerReferences,
 	}
 }
@@ -74,9 +107,21 @@ func New(
 // data as well as appropriate metadata.
 // If the Secret resource does not exist, it will be created.
 // Otherwise, the existing resource will be updated.
+// Only the private key, certificate and CA, the keystores and the additional
+// output formats are managed in an existing Secret resource; any other keys,
+// such as those added by users, are preserved.
 // The first return argument will be true if the resource was updated/created
 // without error.
 // UpdateData will also update deprecated annotations if they exist.
+// If the Certificate selects a secret writer using the
+// cert-manager.io/secret-writer annotation, the Secret is also handed to that
+// writer before the Secret resource is created or updated.
+// If the Certificate has the cert-manager.io/immutable-secret annotation set
+// to "true", the Secret resource is marked as immutable.
+// If the Certificate has the cert-manager.io/pem-line-endings annotation, the
+// PEM data is stored with the requested line endings.
+// If the Certificate has the cert-manager.io/intermediates-secret-name
+// annotation, the intermediate certificates are also stored in that Secret.
 func (s *SecretsManager) UpdateData(ctx context.Context, crt *cmapi.Certificate, data SecretData) error {
 	// Fetch a copy of the existing Secret resource
 	secret, err := s.secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
@@ -86,6 +131,11 @@ func (s *SecretsManager) UpdateData(ctx context.Context, crt *cmapi.Certificate,
 	}
 	secretExists := (secret != nil)
 
+	// Copy the Secret to avoid modifying the object in the lister's cache
+	if secretExists {
+		secret = secret.DeepCopy()
+	}
+
 	// If the secret does not exist yet, then we need to create one
 	if !secretExists {
 		secret = &corev1.Secret{
@@ -97,6 +147,19 @@ func (s *SecretsManager) UpdateData(ctx context.Context, crt *cmapi.Certificate,
 		}
 	}
 
+	// Secret types are immutable, so the secret writer recreates the Secret
+	// if the type requested by the Certificate has changed.
+	// Secrets of type kubernetes.io/tls must store the private key and
+	// certificate under the default key names, so Opaque is used if other
+	// names are configured, or if the private key is held outside the
+	// Secret because the Certificate references a CSR.
+	switch {
+	case crt.Spec.SecretType != "":
+		secret.Type = corev1.SecretType(crt.Spec.SecretType)
+	case !apiutil.SecretKeyNamesAreTLSCompatible(crt), crt.Spec.CSRSecretRef != nil:
+		secret.Type = corev1.SecretTypeOpaque
+	}
+
 	// secret will be overwritten by 'existingSecret' if existingSecret is non-nil
 	if s.enableSecretOwnerReferences {
 		secret.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(crt, certificateGvk)}
@@ -107,21 +170,252 @@ func (s *SecretsManager) UpdateData(ctx context.Context, crt *cmapi.Certificate,
 		return err
 	}
 
-	// If secret does not exist then create it
-	if !secretExists {
+	if err := s.write(ctx, crt, secret); err != nil {
+		return err
+	}
+
+	_, certificateKey, _ := apiutil.SecretKeyNames(crt)
+	return s.syncIntermediatesSecret(ctx, crt, secret.Data[certificateKey])
+}
+
+// SyncIntermediatesSecret ensures that the Secret resource named by the
+// Certificate's cert-manager.io/intermediates-secret-name annotation holds
+// the intermediates of the certificate stored in the Certificate's Secret,
+// recreating it if it has been deleted. The intermediates are removed from
+// any other Secret they were previously stored in for the Certificate, such
+// as after the annotation has been removed.
+func (s *SecretsManager) SyncIntermediatesSecret(ctx context.Context, crt *cmapi.Certificate) error {
+	var certificate []byte
+	secret, err := s.secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if !apierrors.IsNotFound(err) && err != nil {
+		return err
+	}
+	if secret != nil {
+		_, certificateKey, _ := apiutil.SecretKeyNames(crt)
+		certificate = secret.Data[certificateKey]
+	}
+	return s.syncIntermediatesSecret(ctx, crt, certificate)
+}
+
+// syncIntermediatesSecret removes the intermediates from the Secrets they are
+// no longer stored in for the Certificate, then stores the intermediates of
+// the given certificate chain in the Secret currently named by the
+// Certificate.
+func (s *SecretsManager) syncIntermediatesSecret(ctx context.Context, crt *cmapi.Certificate, certificate []byte) error {
+	if err := s.removeStaleIntermediates(ctx, crt); err != nil {
+		return err
+	}
+	return s.writeIntermediatesSecret(ctx, crt, certificate)
+}
+
+// RefreshDerivedData re-creates the keystores and additional output formats
+// stored in the Certificate's Secret from the private key, certificate and CA
+// already stored in it, without modifying any other data or metadata. This
+// allows changes to keystore passwords to be picked up without re-issuing
+// the certificate.
+// It is a no-op if the Certificate does not request any keystores or
+// additional output formats, or if its Secret does not contain a private key
+// and certificate.
+func (s *SecretsManager) RefreshDerivedData(ctx context.Context, crt *cmapi.Certificate) error {
+	if crt.Spec.Keystores == nil && len(crt.Spec.AdditionalOutputFormats) == 0 {
+		return nil
+	}
+
+	secret, err := s.secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	privateKeyKey, certificateKey, caKey := apiutil.SecretKeyNames(crt)
+	data := SecretData{
+		PrivateKey:  secret.Data[privateKeyKey],
+		Certificate: secret.Data[certificateKey],
+		CA:          secret.Data[caKey],
+	}
+	if len(data.PrivateKey) == 0 || len(data.Certificate) == 0 {
+		return nil
+	}
+
+	existing := secret
+	secret = secret.DeepCopy()
+	if err := s.setKeystores(crt, secret, data); err != nil {
+		return err
+	}
+	if err := setOutputFormats(crt, secret, data); err != nil {
+		return err
+	}
+
+	// Nothing to do if the keystores and output formats are up to date
+	if apiequality.Semantic.DeepEqual(existing.Data, secret.Data) {
+		return nil
+	}
+
+	return s.write(ctx, crt, secret)
+}
+
+// write stores the Secret resource using the secret writer, after handing it
+// to the alternate writer selected by the Certificate, if any.
+func (s *SecretsManager) write(ctx context.Context, crt *cmapi.Certificate, secret *corev1.Secret) error {
+	// Mark the Secret as immutable if requested. Secrets that are already
+	// immutable remain so, and are recreated by the secret writer.
+	if crt.Annotations[cmapi.ImmutableSecretAnnotationKey] == "true" {
+		secret.Immutable = pointer.BoolPtr(true)
+	}
+
+	// Hand the issued material to the alternate writer first, so that the
+	// Secret resource is only updated once it has been stored successfully.
+	if name, ok := crt.Annotations[cmapi.SecretWriterAnnotationKey]; ok {
+		writer, ok := secretwriter.SecretWriterFor(name)
+		if !ok {
+			return fmt.Errorf("no secret writer registered with name %q", name)
+		}
+		if err := writer.Write(ctx, crt, secret.DeepCopy()); err != nil {
+			return fmt.Errorf("writing Secret using secret writer %q: %w", name, err)
+		}
+	}
+
+	return s.secretWriter.Write(ctx, crt, secret)
+}
+
+// writeIntermediatesSecret stores the intermediate certificates of the given
+// PEM encoded certificate chain in the Secret resource named by the
+// Certificate's cert-manager.io/intermediates-secret-name annotation, creating
+// it if it does not exist. The data key is recorded in the Secret's
+// cert-manager.io/intermediates-secret-key annotation. Other data and
+// metadata of an existing Secret are left unchanged. It is a no-op if the
+// annotation is not set or there is no certificate.
+func (s *SecretsManager) writeIntermediatesSecret(ctx context.Context, crt *cmapi.Certificate, certificate []byte) error {
+	name, ok := crt.Annotations[cmapi.IntermediatesSecretNameAnnotationKey]
+	if !ok || len(certificate) == 0 {
+		return nil
+	}
+	if name == crt.Spec.SecretName {
+		return fmt.Errorf("intermediates Secret %q must not be the Certificate's spec.secretName", name)
+	}
+
+	key := crt.Annotations[cmapi.IntermediatesSecretKeyAnnotationKey]
+	if key == "" {
+		key = chainPEMSecretKey
+	}
+
+	chain, err := encodeIntermediateChainPEM(certificate)
+	if err != nil {
+		return fmt.Errorf("error encoding intermediate certificate chain: %w", err)
+	}
+	lineEnding, err := pemLineEnding(crt)
+	if err != nil {
+		return err
+	}
+
+	secret, err := s.secretLister.Secrets(crt.Namespace).Get(name)
+	if !apierrors.IsNotFound(err) && err != nil {
+		return err
+	}
+	if secret != nil {
+		secret = secret.DeepCopy()
+		// Remove the intermediates from the key they were previously stored
+		// under if the Certificate has since chosen another.
+		if previous, ok := secret.Annotations[cmapi.IntermediatesSecretKeyAnnotationKey]; ok && previous != key {
+			delete(secret.Data, previous)
+		}
+	} else {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: crt.Namespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+		}
+	}
+
+	if s.enableSecretOwnerReferences {
+		secret.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(crt, certificateGvk)}
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	secret.Annotations[cmapi.CertificateNameKey] = crt.Name
+	secret.Annotations[cmapi.IntermediatesSecretKeyAnnotationKey] = key
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[key] = withLineEnding(chain, lineEnding)
+
+	return s.secretWriter.Write(ctx, crt, secret)
+}
+
+// setSecretKeyNameAnnotations records the data keys that the private key,
+// certificate and CA are stored under in the Secret's annotations, so that
+// consumers of the Secret can find them without reading the Certificate.
+// The annotations are removed for keys that use the default names.
+func setSecretKeyNameAnnotations(secret *corev1.Secret, privateKeyKey, certificateKey, caKey string) {
+	setKeyNameAnnotation(secret, cmapi.SecretPrivateKeyKeyAnnotationKey, privateKeyKey, corev1.TLSPrivateKeyKey)
+	setKeyNameAnnotation(secret, cmapi.SecretCertificateKeyAnnotationKey, certificateKey, corev1.TLSCertKey)
+	setKeyNameAnnotation(secret, cmapi.SecretCAKeyAnnotationKey, caKey, cmmeta.TLSCAKey)
+}
+
+func setKeyNameAnnotation(secret *corev1.Secret, annotation, key, defaultKey string) {
+	if key == defaultKey {
+		delete(secret.Annotations, annotation)
+		return
+	}
+	secret.Annotations[annotation] = key
+}
 
-		_, err = s.kubeClient.CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+// removeStaleIntermediates removes the intermediates stored for the
+// Certificate from every Secret other than the one currently named by its
+// cert-manager.io/intermediates-secret-name annotation, along with the
+// metadata added to those Secrets. Secrets that hold no other data are
+// deleted.
+func (s *SecretsManager) removeStaleIntermediates(ctx context.Context, crt *cmapi.Certificate) error {
+	secrets, err := s.secretLister.Secrets(crt.Namespace).List(labels.Everything())
+	if err != nil {
 		return err
 	}
 
-	// Currently we are always updating. We should devise a way to not have to call an update if it is not necessary.
-	_, err = s.kubeClient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
-	return err
+	current, hasCurrent := crt.Annotations[cmapi.IntermediatesSecretNameAnnotationKey]
+	for _, secret := range secrets {
+		key, ok := secret.Annotations[cmapi.IntermediatesSecretKeyAnnotationKey]
+		if !ok || secret.Annotations[cmapi.CertificateNameKey] != crt.Name ||
+			secret.Name == crt.Spec.SecretName || (hasCurrent && secret.Name == current) {
+			continue
+		}
+
+		if _, onlyIntermediates := secret.Data[key]; len(secret.Data) == 0 || (len(secret.Data) == 1 && onlyIntermediates) {
+			err := s.kubeClient.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &secret.UID},
+			})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("deleting intermediates Secret %q: %w", secret.Name, err)
+			}
+			continue
+		}
+
+		secret = secret.DeepCopy()
+		delete(secret.Data, key)
+		delete(secret.Annotations, cmapi.CertificateNameKey)
+		delete(secret.Annotations, cmapi.IntermediatesSecretKeyAnnotationKey)
+		var ownerRefs []metav1.OwnerReference
+		for _, ref := range secret.OwnerReferences {
+			if ref.UID != crt.UID {
+				ownerRefs = append(ownerRefs, ref)
+			}
+		}
+		secret.OwnerReferences = ownerRefs
+		if _, err := s.kubeClient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("removing intermediates from Secret %q: %w", secret.Name, err)
+		}
+	}
+	return nil
 }
 
 // setValues will update the Secret resource 'secret' with the data contained
 // in the given secretData.
 // It will update labels and annotations on the Secret resource appropriately.
+// Keys that are not managed by cert-manager are left unchanged.
 // The Secret resource 's' must be non-nil, although may be a resource that does
 // not exist in the Kubernetes apiserver yet.
 // setValues will NOT actually update the resource in the apiserver.
@@ -134,92 +428,83 @@ func (s *SecretsManager) setValues(crt *cmapi.Certificate, secret *corev1.Secret
 		secret.Data = make(map[string][]byte)
 	}
 
+	// Convert the PEM data to the line endings requested by the Certificate
+	// before comparing it to the data already stored in the Secret.
+	lineEnding, err := pemLineEnding(crt)
+	if err != nil {
+		return err
+	}
+	data.PrivateKey = withLineEnding(data.PrivateKey, lineEnding)
+	data.Certificate = withLineEnding(data.Certificate, lineEnding)
+	data.CA = withLineEnding(data.CA, lineEnding)
+
+	// The data is stored under the key names configured by the Certificate,
+	// and removed from the default names if other names are configured.
+	privateKeyKey, certificateKey, caKey := apiutil.SecretKeyNames(crt)
+
 	// Only write a new PKCS12/JKS file if any of the private key/certificate/CA
 	// data has actually changed.
 	if data.PrivateKey != nil && data.Certificate != nil &&
-		(!bytes.Equal(secret.Data[corev1.TLSPrivateKeyKey], data.PrivateKey) ||
-			!bytes.Equal(secret.Data[corev1.TLSCertKey], data.Certificate) ||
-			!bytes.Equal(secret.Data[cmmeta.TLSCAKey], data.CA)) {
-
-		// Handle the experimental PKCS12 support
-		if crt.Spec.Keystores != nil && crt.Spec.Keystores.PKCS12 != nil && crt.Spec.Keystores.PKCS12.Create {
-			ref := crt.Spec.Keystores.PKCS12.PasswordSecretRef
-			pwSecret, err := s.secretLister.Secrets(crt.Namespace).Get(ref.Name)
-			if err != nil {
-				return fmt.Errorf("fetching PKCS12 keystore password from Secret: %v", err)
-			}
-			if pwSecret.Data == nil || len(pwSecret.Data[ref.Key]) == 0 {
-				return fmt.Errorf("PKCS12 keystore password Secret contains no data for key %q", ref.Key)
-			}
-			pw := pwSecret.Data[ref.Key]
-			keystoreData, err := encodePKCS12Keystore(string(pw), data.PrivateKey, data.Certificate, data.CA)
-			if err != nil {
-				return fmt.Errorf("error encoding PKCS12 bundle: %w", err)
-			}
-			// always overwrite the keystore entry for now
-			secret.Data[pkcs12SecretKey] = keystoreData
+		(!bytes.Equal(secret.Data[privateKeyKey], data.PrivateKey) ||
+			!bytes.Equal(secret.Data[certificateKey], data.Certificate) ||
+			!bytes.Equal(secret.Data[caKey], data.CA)) {
 
-			if len(data.CA) > 0 {
-				truststoreData, err := encodePKCS12Truststore(string(pw), data.CA)
-				if err != nil {
-					return fmt.Errorf("error encoding PKCS12 trust store bundle: %w", err)
-				}
-				// always overwrite the truststore entry
-				secret.Data[pkcs12TruststoreKey] = truststoreData
-			}
-		} else {
-			delete(secret.Data, pkcs12SecretKey)
-			delete(secret.Data, pkcs12TruststoreKey)
+		if err := s.setKeystores(crt, secret, data); err != nil {
+			return err
 		}
+	}
 
-		// Handle the experimental JKS support
-		if crt.Spec.Keystores != nil && crt.Spec.Keystores.JKS != nil && crt.Spec.Keystores.JKS.Create {
-			ref := crt.Spec.Keystores.JKS.PasswordSecretRef
-			pwSecret, err := s.secretLister.Secrets(crt.Namespace).Get(ref.Name)
-			if err != nil {
-				return fmt.Errorf("fetching JKS keystore password from Secret: %v", err)
-			}
-			if pwSecret.Data == nil || len(pwSecret.Data[ref.Key]) == 0 {
-				return fmt.Errorf("JKS keystore password Secret contains no data for key %q", ref.Key)
-			}
-			pw := pwSecret.Data[ref.Key]
-			keystoreData, err := encodeJKSKeystore(pw, data.PrivateKey, data.Certificate, data.CA)
-			if err != nil {
-				return fmt.Errorf("error encoding JKS bundle: %w", err)
-			}
-			// always overwrite the keystore entry
-			secret.Data[jksSecretKey] = keystoreData
-
-			if len(data.CA) > 0 {
-				truststoreData, err := encodeJKSTruststore(pw, data.CA)
-				if err != nil {
-					return fmt.Errorf("error encoding JKS trust store bundle: %w", err)
-				}
-				// always overwrite the keystore entry
-				secret.Data[jksTruststoreKey] = truststoreData
-			}
-		} else {
-			delete(secret.Data, jksSecretKey)
-			delete(secret.Data, jksTruststoreKey)
+	for key, defaultKey := range map[string]string{
+		privateKeyKey:  corev1.TLSPrivateKeyKey,
+		certificateKey: corev1.TLSCertKey,
+		caKey:          cmmeta.TLSCAKey,
+	} {
+		if key != defaultKey {
+			delete(secret.Data, defaultKey)
 		}
 	}
-
-	secret.Data[corev1.TLSPrivateKeyKey] = data.PrivateKey
-	secret.Data[corev1.TLSCertKey] = data.Certificate
+	// No private key is stored if the Certificate references a CSR.
+	if data.PrivateKey != nil {
+		secret.Data[privateKeyKey] = data.PrivateKey
+	} else {
+		delete(secret.Data, privateKeyKey)
+	}
+	secret.Data[certificateKey] = data.Certificate
 	if len(data.CA) > 0 {
-		secret.Data[cmmeta.TLSCAKey] = data.CA
+		secret.Data[caKey] = data.CA
+	} else {
+		delete(secret.Data, caKey)
+	}
+
+	if len(data.Manifest) > 0 && len(data.ManifestSignature) > 0 {
+		secret.Data[manifestSecretKey] = data.Manifest
+		secret.Data[manifestSignatureSecretKey] = data.ManifestSignature
 	} else {
-		delete(secret.Data, cmmeta.TLSCAKey)
+		delete(secret.Data, manifestSecretKey)
+		delete(secret.Data, manifestSignatureSecretKey)
+	}
+
+	if err := setOutputFormats(crt, secret, data); err != nil {
+		return err
 	}
 
 	if secret.Annotations == nil {
 		secret.Annotations = make(map[string]string)
 	}
 
+	for k, v := range data.Annotations {
+		secret.Annotations[k] = v
+	}
+
 	secret.Annotations[cmapi.CertificateNameKey] = crt.Name
-	secret.Annotations[cmapi.IssuerNameAnnotationKey] = crt.Spec.IssuerRef.Name
-	secret.Annotations[cmapi.IssuerKindAnnotationKey] = apiutil.IssuerKind(crt.Spec.IssuerRef)
-	secret.Annotations[cmapi.IssuerGroupAnnotationKey] = crt.Spec.IssuerRef.Group
+	setSecretKeyNameAnnotations(secret, privateKeyKey, certificateKey, caKey)
+	issuerRef := crt.Spec.IssuerRef
+	if data.IssuerRef != nil {
+		issuerRef = *data.IssuerRef
+	}
+	secret.Annotations[cmapi.IssuerNameAnnotationKey] = issuerRef.Name
+	secret.Annotations[cmapi.IssuerKindAnnotationKey] = apiutil.IssuerKind(issuerRef)
+	secret.Annotations[cmapi.IssuerGroupAnnotationKey] = issuerRef.Group
 
 	// if the certificate data is empty, clear the subject related annotations
 	if len(data.Certificate) == 0 {
@@ -242,3 +527,103 @@ func (s *SecretsManager) setValues(crt *cmapi.Certificate, secret *corev1.Secret
 
 	return nil
 }
+
+// setKeystores will update the PKCS12 and JKS keystores in the Secret resource
+// 'secret' from the given secretData, or remove them if they are not
+// requested by the Certificate. The 'Data' field of the Secret must be
+// non-nil.
+func (s *SecretsManager) setKeystores(crt *cmapi.Certificate, secret *corev1.Secret, data SecretData) error {
+	// Handle the experimental PKCS12 support
+	if crt.Spec.Keystores != nil && crt.Spec.Keystores.PKCS12 != nil && crt.Spec.Keystores.PKCS12.Create {
+		ref := crt.Spec.Keystores.PKCS12.PasswordSecretRef
+		pwSecret, err := s.secretLister.Secrets(crt.Namespace).Get(ref.Name)
+		if err != nil {
+			return fmt.Errorf("fetching PKCS12 keystore password from Secret: %v", err)
+		}
+		if pwSecret.Data == nil || len(pwSecret.Data[ref.Key]) == 0 {
+			return fmt.Errorf("PKCS12 keystore password Secret contains no data for key %q", ref.Key)
+		}
+		pw := pwSecret.Data[ref.Key]
+		keystoreData, err := encodePKCS12Keystore(string(pw), data.PrivateKey, data.Certificate, data.CA)
+		if err != nil {
+			return fmt.Errorf("error encoding PKCS12 bundle: %w", err)
+		}
+		// keep the existing keystore if it already holds the same key and
+		// certificates, as encoding a keystore is not deterministic
+		if !pkcs12KeystoresEqual(string(pw), secret.Data[pkcs12SecretKey], keystoreData) {
+			secret.Data[pkcs12SecretKey] = keystoreData
+		}
+
+		if len(data.CA) > 0 {
+			truststoreData, err := encodePKCS12Truststore(string(pw), data.CA)
+			if err != nil {
+				return fmt.Errorf("error encoding PKCS12 trust store bundle: %w", err)
+			}
+			if !pkcs12TruststoresEqual(string(pw), secret.Data[pkcs12TruststoreKey], truststoreData) {
+				secret.Data[pkcs12TruststoreKey] = truststoreData
+			}
+		}
+	} else {
+		delete(secret.Data, pkcs12SecretKey)
+		delete(secret.Data, pkcs12TruststoreKey)
+	}
+
+	// Handle the experimental JKS support
+	if crt.Spec.Keystores != nil && crt.Spec.Keystores.JKS != nil && crt.Spec.Keystores.JKS.Create {
+		ref := crt.Spec.Keystores.JKS.PasswordSecretRef
+		pwSecret, err := s.secretLister.Secrets(crt.Namespace).Get(ref.Name)
+		if err != nil {
+			return fmt.Errorf("fetching JKS keystore password from Secret: %v", err)
+		}
+		if pwSecret.Data == nil || len(pwSecret.Data[ref.Key]) == 0 {
+			return fmt.Errorf("JKS keystore password Secret contains no data for key %q", ref.Key)
+		}
+		pw := pwSecret.Data[ref.Key]
+		keystoreData, err := encodeJKSKeystore(pw, data.PrivateKey, data.Certificate, data.CA)
+		if err != nil {
+			return fmt.Errorf("error encoding JKS bundle: %w", err)
+		}
+		// keep the existing keystore if it already holds the same key and
+		// certificates, as encoding a keystore is not deterministic
+		if !jksKeystoresEqual(pw, secret.Data[jksSecretKey], keystoreData) {
+			secret.Data[jksSecretKey] = keystoreData
+		}
+
+		if len(data.CA) > 0 {
+			truststoreData, err := encodeJKSTruststore(pw, data.CA)
+			if err != nil {
+				return fmt.Errorf("error encoding JKS trust store bundle: %w", err)
+			}
+			if !jksKeystoresEqual(pw, secret.Data[jksTruststoreKey], truststoreData) {
+				secret.Data[jksTruststoreKey] = truststoreData
+			}
+		}
+	} else {
+		delete(secret.Data, jksSecretKey)
+		delete(secret.Data, jksTruststoreKey)
+	}
+
+	return nil
+}
+
+// setOutputFormats will update the additional output formats in the Secret
+// resource 'secret' from the given secretData, or remove them if they are not
+// requested by the Certificate. The 'Data' field of the Secret must be
+// non-nil.
+func setOutputFormats(crt *cmapi.Certificate, secret *corev1.Secret, data SecretData) error {
+	if hasOutputFormat(crt, cmapi.CertificateOutputFormatChainPEM) && len(data.Certificate) > 0 {
+		chain, err := encodeIntermediateChainPEM(data.Certificate)
+		if err != nil {
+			return fmt.Errorf("error encoding intermediate certificate chain: %w", err)
+		}
+		lineEnding, err := pemLineEnding(crt)
+		if err != nil {
+			return err
+		}
+		secret.Data[chainPEMSecretKey] = withLineEnding(chain, lineEnding)
+	} else {
+		delete(secret.Data, chainPEMSecretKey)
+	}
+
+	return nil
+}
@@ -18,20 +18,29 @@ package secretsmanager
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientcorev1 "k8s.io/client-go/listers/core/v1"
 	coretesting "k8s.io/client-go/testing"
 	fakeclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"software.sslmate.com/src/go-pkcs12"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	internaltest "github.com/jetstack/cert-manager/pkg/controller/certificates/internal/test"
+	"github.com/jetstack/cert-manager/pkg/controller/certificates/secretwriter"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
 	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/test/unit/gen"
@@ -63,6 +72,32 @@ func TestSecretsManager(t *testing.T) {
 		gen.SetCertificateDNSNames("example.com"),
 	), fixedClock)
 
+	upToDateSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: gen.DefaultTestNamespace,
+			Name:      "output",
+			Annotations: map[string]string{
+				"my-custom": "annotation",
+
+				cmapi.CertificateNameKey:       "test",
+				cmapi.IssuerGroupAnnotationKey: "foo.io",
+				cmapi.IssuerKindAnnotationKey:  "Issuer",
+				cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+				cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+				cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+				cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+				cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+			},
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       exampleBundle.CertBytes,
+			corev1.TLSPrivateKeyKey: []byte("test-key"),
+			cmmeta.TLSCAKey:         []byte("test-ca"),
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+
 	tests := map[string]testT{
 		"if secret does not exists and unable to decode certificate, then error": {
 			certificate: exampleBundle.Certificate,
@@ -116,6 +151,52 @@ func TestSecretsManager(t *testing.T) {
 			expectedErr: false,
 		},
 
+		"if secret does not exist, create new Secret with additional annotations, without overriding cert-manager annotations": {
+			certificate: exampleBundle.Certificate,
+			SecretData: SecretData{
+				Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key"),
+				Annotations: map[string]string{
+					"example.com/pipeline-id": "1234",
+					cmapi.CertificateNameKey:  "not-test",
+				},
+			},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									"example.com/pipeline-id": "1234",
+
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+
 		"if secret does exist, update existing Secret and leave custom annotations, with owner enabled": {
 			certificate: exampleBundle.Certificate,
 			certificateOptions: controllerpkg.CertificateOptions{
@@ -275,37 +356,1271 @@ func TestSecretsManager(t *testing.T) {
 			},
 			expectedErr: false,
 		},
-	}
 
-	// TODO: add to these tests once the JKS/PKCS12 support is updated
+		"if secret does exist and is immutable, delete and recreate it, preserving labels and annotations": {
+			certificate: exampleBundle.Certificate,
+			SecretData:  SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace:       gen.DefaultTestNamespace,
+							Name:            "output",
+							UID:             "existing-uid",
+							ResourceVersion: "1",
+							Labels: map[string]string{
+								"my-custom": "label",
+							},
+							Annotations: map[string]string{
+								"my-custom": "annotation",
+							},
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("foo"),
+							corev1.TLSPrivateKeyKey: []byte("foo"),
+							cmmeta.TLSCAKey:         []byte("foo"),
+						},
+						Type:      corev1.SecretTypeTLS,
+						Immutable: pointer.BoolPtr(true),
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewDeleteAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						"output",
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Labels: map[string]string{
+									"my-custom": "label",
+								},
+								Annotations: map[string]string{
+									"my-custom": "annotation",
 
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			fixedClock.SetTime(fixedClockStart)
-			test.builder.Clock = fixedClock
-			test.builder.T = t
-			test.builder.Init()
-			defer test.builder.Stop()
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
 
-			kubeClient := test.builder.Client
-			secretsLister := test.builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+							},
+							Type:      corev1.SecretTypeTLS,
+							Immutable: pointer.BoolPtr(true),
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
 
-			testManager := New(
-				kubeClient,
-				secretsLister,
-				test.certificateOptions.EnableOwnerRef,
-			)
+		"if secret does not exist and the Certificate requests an immutable Secret, create an immutable Secret": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.ImmutableSecretAnnotationKey: "true"}),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
 
-			test.builder.Start()
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+							},
+							Type:      corev1.SecretTypeTLS,
+							Immutable: pointer.BoolPtr(true),
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
 
-			err := testManager.UpdateData(context.Background(), test.certificate, test.SecretData)
-			if err != nil && !test.expectedErr {
-				t.Errorf("expected to not get an error, but got: %v", err)
-			}
-			if err == nil && test.expectedErr {
-				t.Errorf("expected to get an error but did not get one")
-			}
-			test.builder.CheckAndFinish(err)
+		"if secret does exist and the Certificate requests an immutable Secret, update it to be immutable": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.ImmutableSecretAnnotationKey: "true"}),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("foo"),
+							corev1.TLSPrivateKeyKey: []byte("foo"),
+							cmmeta.TLSCAKey:         []byte("foo"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+							},
+							Type:      corev1.SecretTypeTLS,
+							Immutable: pointer.BoolPtr(true),
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if secret does not exist and the Certificate requests an Opaque Secret, create an Opaque Secret": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateSecretType(string(corev1.SecretTypeOpaque)),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+							},
+							Type: corev1.SecretTypeOpaque,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+
+		"if secret does exist with a different type to the one requested by the Certificate, delete and recreate it": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateSecretType(string(corev1.SecretTypeOpaque)),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace:       gen.DefaultTestNamespace,
+							Name:            "output",
+							UID:             "existing-uid",
+							ResourceVersion: "1",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("foo"),
+							corev1.TLSPrivateKeyKey: []byte("foo"),
+							cmmeta.TLSCAKey:         []byte("foo"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewDeleteAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						"output",
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+							},
+							Type: corev1.SecretTypeOpaque,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if the issuance manifest is set, store it and its signature in the secret": {
+			certificate: exampleBundle.Certificate,
+			SecretData:  SecretData{Certificate: exampleBundle.CertBytes, PrivateKey: []byte("test-key"), Manifest: []byte("manifest"), ManifestSignature: []byte("signature")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:          []byte("foo"),
+							corev1.TLSPrivateKeyKey:    []byte("foo"),
+							manifestSecretKey:          []byte("stale-manifest"),
+							manifestSignatureSecretKey: []byte("stale-signature"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:          exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey:    []byte("test-key"),
+								manifestSecretKey:          []byte("manifest"),
+								manifestSignatureSecretKey: []byte("signature"),
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if the issuance manifest is not set, remove any stale manifest from the secret": {
+			certificate: exampleBundle.Certificate,
+			SecretData:  SecretData{Certificate: exampleBundle.CertBytes, PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:          []byte("foo"),
+							corev1.TLSPrivateKeyKey:    []byte("foo"),
+							manifestSecretKey:          []byte("stale-manifest"),
+							manifestSignatureSecretKey: []byte("stale-signature"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if secret does exist with keys not managed by cert-manager, preserve them when updating it": {
+			certificate: exampleBundle.Certificate,
+			SecretData:  SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("foo"),
+							corev1.TLSPrivateKeyKey: []byte("foo"),
+							cmmeta.TLSCAKey:         []byte("foo"),
+							"dhparam.pem":           []byte("dhparam"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+								"dhparam.pem":           []byte("dhparam"),
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if secret does exist with keys not managed by cert-manager, preserve them when recreating it": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateSecretType(string(corev1.SecretTypeOpaque)),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace:       gen.DefaultTestNamespace,
+							Name:            "output",
+							UID:             "existing-uid",
+							ResourceVersion: "1",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("foo"),
+							corev1.TLSPrivateKeyKey: []byte("foo"),
+							"dhparam.pem":           []byte("dhparam"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewDeleteAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						"output",
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+								"dhparam.pem":           []byte("dhparam"),
+							},
+							Type: corev1.SecretTypeOpaque,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if secret does not exist and the Certificate configures custom key names, create an Opaque Secret using those names": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateSecretKeys(cmapi.CertificateSecretKeys{PrivateKey: "key.pem", Certificate: "cert.pem", CA: "root.pem"}),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:                "test",
+									cmapi.IssuerGroupAnnotationKey:          "foo.io",
+									cmapi.IssuerKindAnnotationKey:           "Issuer",
+									cmapi.IssuerNameAnnotationKey:           "ca-issuer",
+									cmapi.SecretPrivateKeyKeyAnnotationKey:  "key.pem",
+									cmapi.SecretCertificateKeyAnnotationKey: "cert.pem",
+									cmapi.SecretCAKeyAnnotationKey:          "root.pem",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								"cert.pem": exampleBundle.CertBytes,
+								"key.pem":  []byte("test-key"),
+								"root.pem": []byte("test-ca"),
+							},
+							Type: corev1.SecretTypeOpaque,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if secret does exist and the Certificate configures a custom CA key name, move the CA to that name": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateSecretKeys(cmapi.CertificateSecretKeys{CA: "root.pem"}),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("foo"),
+							corev1.TLSPrivateKeyKey: []byte("foo"),
+							cmmeta.TLSCAKey:         []byte("foo"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+									cmapi.SecretCAKeyAnnotationKey: "root.pem",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								"root.pem":              []byte("test-ca"),
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+		"if the Certificate requests a ChainPEM output format and the chain only contains the leaf, write an empty chain.pem": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateAdditionalOutputFormats(cmapi.CertificateAdditionalOutputFormat{Type: cmapi.CertificateOutputFormatChainPEM}),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+								"chain.pem":             {},
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+
+		"if the Certificate no longer requests a ChainPEM output format, remove chain.pem": {
+			certificate: exampleBundle.Certificate,
+			SecretData:  SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: gen.DefaultTestNamespace,
+							Name:      "output",
+						},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("foo"),
+							corev1.TLSPrivateKeyKey: []byte("foo"),
+							cmmeta.TLSCAKey:         []byte("foo"),
+							"chain.pem":             []byte("foo"),
+						},
+						Type: corev1.SecretTypeTLS,
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						gen.DefaultTestNamespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: gen.DefaultTestNamespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+									cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+									cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+									cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+									cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertBytes,
+								corev1.TLSPrivateKeyKey: []byte("test-key"),
+								cmmeta.TLSCAKey:         []byte("test-ca"),
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+			},
+			expectedErr: false,
+		},
+
+		"if secret is already up to date, do not update it": {
+			certificate: exampleBundle.Certificate,
+			SecretData:  SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects:     []runtime.Object{upToDateSecret},
+				ExpectedActions: []testpkg.Action{},
+			},
+			expectedErr: false,
+		},
+
+		"if secret is immutable and already up to date, do not recreate it": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.ImmutableSecretAnnotationKey: "true"}),
+			),
+			SecretData: SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")},
+			builder: &testpkg.Builder{
+				KubeObjects: []runtime.Object{
+					func() *corev1.Secret {
+						secret := upToDateSecret.DeepCopy()
+						secret.Immutable = pointer.BoolPtr(true)
+						return secret
+					}(),
+				},
+				ExpectedActions: []testpkg.Action{},
+			},
+			expectedErr: false,
+		},
+	}
+
+	// TODO: add to these tests once the JKS/PKCS12 support is updated
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fixedClock.SetTime(fixedClockStart)
+			test.builder.Clock = fixedClock
+			test.builder.T = t
+			test.builder.Init()
+			defer test.builder.Stop()
+
+			kubeClient := test.builder.Client
+			secretsLister := test.builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+
+			testManager := New(
+				kubeClient,
+				secretsLister,
+				test.certificateOptions.EnableOwnerRef,
+			)
+
+			test.builder.Start()
+
+			err := testManager.UpdateData(context.Background(), test.certificate, test.SecretData)
+			if err != nil && !test.expectedErr {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("expected to get an error but did not get one")
+			}
+			test.builder.CheckAndFinish(err)
+		})
+	}
+}
+
+// fakeSecretWriter records the Secrets it is asked to write.
+type fakeSecretWriter struct {
+	written []*corev1.Secret
+	err     error
+}
+
+func (f *fakeSecretWriter) Write(_ context.Context, _ *cmapi.Certificate, secret *corev1.Secret) error {
+	f.written = append(f.written, secret)
+	return f.err
+}
+
+func TestSecretsManagerSecretWriter(t *testing.T) {
+	baseCert := gen.Certificate("test",
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "Issuer", Group: "foo.io"}),
+		gen.SetCertificateSecretName("output"),
+		gen.SetCertificateDNSNames("example.com"),
+	)
+	exampleBundle := internaltest.MustCreateCryptoBundle(t, baseCert, fixedClock)
+	secretData := SecretData{Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key")}
+	expectedSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: gen.DefaultTestNamespace,
+			Name:      "output",
+			Annotations: map[string]string{
+				cmapi.CertificateNameKey:       "test",
+				cmapi.IssuerGroupAnnotationKey: "foo.io",
+				cmapi.IssuerKindAnnotationKey:  "Issuer",
+				cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+
+				cmapi.CommonNameAnnotationKey: exampleBundle.Cert.Subject.CommonName,
+				cmapi.AltNamesAnnotationKey:   strings.Join(exampleBundle.Cert.DNSNames, ","),
+				cmapi.IPSANAnnotationKey:      strings.Join(utilpki.IPAddressesToString(exampleBundle.Cert.IPAddresses), ","),
+				cmapi.URISANAnnotationKey:     strings.Join(utilpki.URLsToString(exampleBundle.Cert.URIs), ","),
+			},
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       exampleBundle.CertBytes,
+			corev1.TLSPrivateKeyKey: []byte("test-key"),
+			cmmeta.TLSCAKey:         []byte("test-ca"),
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+
+	tests := map[string]struct {
+		writerName     string
+		writerErr      error
+		expectedWrites int
+		expectedErr    bool
+		expectedAction []testpkg.Action
+	}{
+		"should hand the issued material to the selected writer and create the Secret": {
+			writerName:     "fake-ok",
+			expectedWrites: 1,
+			expectedAction: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewCreateAction(corev1.SchemeGroupVersion.WithResource("secrets"), gen.DefaultTestNamespace, expectedSecret)),
+			},
+		},
+		"should not create the Secret if the selected writer fails": {
+			writerName:     "fake-error",
+			writerErr:      errors.New("external store unavailable"),
+			expectedWrites: 1,
+			expectedErr:    true,
+		},
+		"should error if the selected writer is not registered": {
+			writerName:  "not-registered",
+			expectedErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			writer := &fakeSecretWriter{err: test.writerErr}
+			if test.writerName != "not-registered" {
+				secretwriter.RegisterSecretWriter(test.writerName, writer)
+			}
+
+			builder := &testpkg.Builder{
+				T:               t,
+				Clock:           fixedClock,
+				ExpectedActions: test.expectedAction,
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			testManager := New(
+				builder.Client,
+				builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
+				false,
+			)
+			builder.Start()
+
+			crt := gen.CertificateFrom(exampleBundle.Certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.SecretWriterAnnotationKey: test.writerName}),
+			)
+			err := testManager.UpdateData(context.Background(), crt, secretData)
+			if err != nil && !test.expectedErr {
+				t.Errorf("expected to not get an error, but got: %v", err)
+			}
+			if err == nil && test.expectedErr {
+				t.Errorf("expected to get an error but did not get one")
+			}
+
+			if len(writer.written) != test.expectedWrites {
+				t.Fatalf("expected %d writes to the secret writer, got %d", test.expectedWrites, len(writer.written))
+			}
+			for _, written := range writer.written {
+				assert.Equal(t, expectedSecret, written)
+			}
+			builder.CheckAndFinish(err)
+		})
+	}
+}
+
+func TestSecretsManagerConsecutiveUpdates(t *testing.T) {
+	baseCert := gen.Certificate("test",
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "Issuer", Group: "foo.io"}),
+		gen.SetCertificateSecretName("output"),
+		gen.SetCertificateDNSNames("example.com", "www.example.com"),
+		gen.SetCertificateAdditionalOutputFormats(cmapi.CertificateAdditionalOutputFormat{Type: cmapi.CertificateOutputFormatChainPEM}),
+	)
+	exampleBundle := internaltest.MustCreateCryptoBundle(t, baseCert, fixedClock)
+	secretData := SecretData{
+		Certificate: exampleBundle.CertBytes, CA: []byte("test-ca"), PrivateKey: []byte("test-key"),
+		Annotations: map[string]string{
+			"example.com/a": "1",
+			"example.com/b": "2",
+			"example.com/c": "3",
+		},
+	}
+
+	builder := &testpkg.Builder{
+		T:     t,
+		Clock: fixedClock,
+	}
+	builder.Init()
+	defer builder.Stop()
+
+	secretsLister := builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+	testManager := New(builder.Client, secretsLister, false)
+	builder.Start()
+
+	if err := testManager.UpdateData(context.Background(), exampleBundle.Certificate, secretData); err != nil {
+		t.Fatalf("unexpected error on first sync: %v", err)
+	}
+
+	// wait for the created Secret to be observed by the lister, as it would
+	// be before the next sync
+	err := wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		_, err := secretsLister.Secrets(gen.DefaultTestNamespace).Get("output")
+		return err == nil, nil
+	})
+	if err != nil {
+		t.Fatalf("timed out waiting for the Secret to be observed: %v", err)
+	}
+
+	if err := testManager.UpdateData(context.Background(), exampleBundle.Certificate, secretData); err != nil {
+		t.Fatalf("unexpected error on second sync: %v", err)
+	}
+
+	// only the first sync should have written the Secret
+	var verbs []string
+	for _, a := range builder.FakeKubeClient().Actions() {
+		if a.GetVerb() == "list" || a.GetVerb() == "watch" {
+			continue
+		}
+		verbs = append(verbs, a.GetVerb())
+	}
+	assert.Equal(t, []string{"create"}, verbs)
+}
+
+func TestSecretsManagerRefreshDerivedData(t *testing.T) {
+	pkBytes := mustGeneratePrivateKey(t, cmapi.PKCS8)
+	certBytes := mustSelfSignCertificate(t, pkBytes)
+	oldKeystore, err := encodePKCS12Keystore("old-password", pkBytes, certBytes, nil)
+	require.NoError(t, err)
+	currentKeystore, err := encodePKCS12Keystore("new-password", pkBytes, certBytes, nil)
+	require.NoError(t, err)
+	currentJKSKeystore, err := encodeJKSKeystore([]byte("new-password"), pkBytes, certBytes, nil)
+	require.NoError(t, err)
+
+	passwordSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gen.DefaultTestNamespace, Name: "keystore-password"},
+		Data:       map[string][]byte{"password": []byte("new-password")},
+	}
+	existingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   gen.DefaultTestNamespace,
+			Name:        "output",
+			Annotations: map[string]string{cmapi.CertificateNameKey: "test"},
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certBytes,
+			corev1.TLSPrivateKeyKey: pkBytes,
+			pkcs12SecretKey:         oldKeystore,
+		},
+		Type: corev1.SecretTypeTLS,
+	}
+	withPKCS12 := gen.SetCertificateKeystores(&cmapi.CertificateKeystores{
+		PKCS12: &cmapi.PKCS12Keystore{
+			Create: true,
+			PasswordSecretRef: cmmeta.SecretKeySelector{
+				LocalObjectReference: cmmeta.LocalObjectReference{Name: "keystore-password"},
+				Key:                  "password",
+			},
+		},
+	})
+	withJKS := gen.SetCertificateKeystores(&cmapi.CertificateKeystores{
+		JKS: &cmapi.JKSKeystore{
+			Create: true,
+			PasswordSecretRef: cmmeta.SecretKeySelector{
+				LocalObjectReference: cmmeta.LocalObjectReference{Name: "keystore-password"},
+				Key:                  "password",
+			},
+		},
+	})
+
+	tests := map[string]struct {
+		certificate *cmapi.Certificate
+		secrets     []runtime.Object
+
+		// verify is called with the updated Secret, or nil if the Secret was
+		// not updated
+		verify func(t *testing.T, secret *corev1.Secret)
+	}{
+		"should re-create the keystore using the current password": {
+			certificate: gen.Certificate("test", gen.SetCertificateSecretName("output"), withPKCS12),
+			secrets:     []runtime.Object{passwordSecret, existingSecret},
+			verify: func(t *testing.T, secret *corev1.Secret) {
+				require.NotNil(t, secret)
+				pk, cert, err := pkcs12.Decode(secret.Data[pkcs12SecretKey], "new-password")
+				require.NoError(t, err)
+				assert.NotNil(t, pk)
+				assert.NotNil(t, cert)
+				assert.Equal(t, pkBytes, secret.Data[corev1.TLSPrivateKeyKey])
+				assert.Equal(t, certBytes, secret.Data[corev1.TLSCertKey])
+				assert.Equal(t, existingSecret.Annotations, secret.Annotations)
+			},
+		},
+		"should not update the Secret if its PKCS12 keystore already holds the same key and certificate using the current password": {
+			certificate: gen.Certificate("test", gen.SetCertificateSecretName("output"), withPKCS12),
+			secrets: []runtime.Object{passwordSecret, gen.SecretFrom(existingSecret, gen.SetSecretData(map[string][]byte{
+				corev1.TLSCertKey:       certBytes,
+				corev1.TLSPrivateKeyKey: pkBytes,
+				pkcs12SecretKey:         currentKeystore,
+			}))},
+			verify: func(t *testing.T, secret *corev1.Secret) {
+				assert.Nil(t, secret)
+			},
+		},
+		"should not update the Secret if its JKS keystore already holds the same key and certificate using the current password": {
+			certificate: gen.Certificate("test", gen.SetCertificateSecretName("output"), withJKS),
+			secrets: []runtime.Object{passwordSecret, gen.SecretFrom(existingSecret, gen.SetSecretData(map[string][]byte{
+				corev1.TLSCertKey:       certBytes,
+				corev1.TLSPrivateKeyKey: pkBytes,
+				jksSecretKey:            currentJKSKeystore,
+			}))},
+			verify: func(t *testing.T, secret *corev1.Secret) {
+				assert.Nil(t, secret)
+			},
+		},
+		"should not update the Secret if no keystores or output formats are requested": {
+			certificate: gen.Certificate("test", gen.SetCertificateSecretName("output")),
+			secrets:     []runtime.Object{passwordSecret, existingSecret},
+			verify: func(t *testing.T, secret *corev1.Secret) {
+				assert.Nil(t, secret)
+			},
+		},
+		"should not create the Secret if it does not exist": {
+			certificate: gen.Certificate("test", gen.SetCertificateSecretName("output"), withPKCS12),
+			secrets:     []runtime.Object{passwordSecret},
+			verify: func(t *testing.T, secret *corev1.Secret) {
+				assert.Nil(t, secret)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{
+				T:           t,
+				Clock:       fixedClock,
+				KubeObjects: test.secrets,
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			testManager := New(builder.Client, builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister(), false)
+			builder.Start()
+
+			require.NoError(t, testManager.RefreshDerivedData(context.Background(), test.certificate))
+
+			var updated *corev1.Secret
+			for _, a := range builder.FakeKubeClient().Actions() {
+				switch a.GetVerb() {
+				case "list", "watch":
+				case "update":
+					updated = a.(coretesting.UpdateAction).GetObject().(*corev1.Secret)
+				default:
+					t.Errorf("unexpected %s action", a.GetVerb())
+				}
+			}
+			test.verify(t, updated)
+		})
+	}
+}
+
+func TestSecretsManagerPEMLineEndings(t *testing.T) {
+	pkBytes := mustGeneratePrivateKey(t, cmapi.PKCS8)
+	certBytes := mustSelfSignCertificate(t, pkBytes)
+	crlf := func(data []byte) []byte {
+		return []byte(strings.ReplaceAll(string(data), "\n", "\r\n"))
+	}
+
+	tests := map[string]struct {
+		lineEndings *string
+		data        SecretData
+
+		expectedErr bool
+		expected    SecretData
+	}{
+		"should store the PEM data unchanged if no line endings are requested": {
+			data:     SecretData{PrivateKey: pkBytes, Certificate: certBytes, CA: crlf(certBytes)},
+			expected: SecretData{PrivateKey: pkBytes, Certificate: certBytes, CA: crlf(certBytes)},
+		},
+		"should store the PEM data with CRLF line endings": {
+			lineEndings: pointer.StringPtr("CRLF"),
+			data:        SecretData{PrivateKey: pkBytes, Certificate: certBytes, CA: certBytes},
+			expected:    SecretData{PrivateKey: crlf(pkBytes), Certificate: crlf(certBytes), CA: crlf(certBytes)},
+		},
+		"should store the PEM data with LF line endings": {
+			lineEndings: pointer.StringPtr("LF"),
+			data:        SecretData{PrivateKey: crlf(pkBytes), Certificate: crlf(certBytes), CA: certBytes},
+			expected:    SecretData{PrivateKey: pkBytes, Certificate: certBytes, CA: certBytes},
+		},
+		"should error if the requested line endings are not supported": {
+			lineEndings: pointer.StringPtr("CR"),
+			data:        SecretData{PrivateKey: pkBytes, Certificate: certBytes},
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := gen.Certificate("test", gen.SetCertificateSecretName("output"))
+			if test.lineEndings != nil {
+				crt.Annotations = map[string]string{cmapi.PEMLineEndingsAnnotationKey: *test.lineEndings}
+			}
+
+			builder := &testpkg.Builder{
+				T:     t,
+				Clock: fixedClock,
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			testManager := New(builder.Client, builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister(), false)
+			builder.Start()
+
+			err := testManager.UpdateData(context.Background(), crt, test.data)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			secret, err := builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Get(context.Background(), "output", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, string(test.expected.PrivateKey), string(secret.Data[corev1.TLSPrivateKeyKey]))
+			assert.Equal(t, string(test.expected.Certificate), string(secret.Data[corev1.TLSCertKey]))
+			assert.Equal(t, string(test.expected.CA), string(secret.Data[cmmeta.TLSCAKey]))
+		})
+	}
+}
+
+func TestSecretsManagerIntermediatesSecret(t *testing.T) {
+	// waitForSecret waits for the Secret to be observed by the lister with
+	// the given data key, as it would be before the next sync.
+	waitForSecret := func(t *testing.T, secretsLister clientcorev1.SecretLister, name, key string) {
+		err := wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+			secret, err := secretsLister.Secrets(gen.DefaultTestNamespace).Get(name)
+			return err == nil && secret.Data[key] != nil, nil
+		})
+		require.NoError(t, err, "timed out waiting for the Secret to be observed")
+	}
+
+	tests := map[string]struct {
+		key         string
+		secretName  string
+		expectedKey string
+		expectedErr bool
+	}{
+		"should store the intermediates under chain.pem by default": {
+			secretName:  "intermediates",
+			expectedKey: "chain.pem",
+		},
+		"should store the intermediates under the requested key": {
+			secretName:  "intermediates",
+			key:         "intermediates.crt",
+			expectedKey: "intermediates.crt",
+		},
+		"should error if the intermediates Secret is the Certificate's Secret": {
+			secretName:  "output",
+			expectedErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			annotations := map[string]string{cmapi.IntermediatesSecretNameAnnotationKey: test.secretName}
+			if test.key != "" {
+				annotations[cmapi.IntermediatesSecretKeyAnnotationKey] = test.key
+			}
+			crt := gen.Certificate("test", gen.SetCertificateSecretName("output"), gen.AddCertificateAnnotations(annotations))
+
+			builder := &testpkg.Builder{
+				T:     t,
+				Clock: fixedClock,
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			secretsLister := builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+			testManager := New(builder.Client, secretsLister, false)
+			builder.Start()
+
+			chain := mustLeafWithChain(t)
+			err := testManager.UpdateData(context.Background(), crt, SecretData{PrivateKey: chain.leaf.keyPEM, Certificate: chain.all.certsToPEM()})
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			intermediates, err := builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Get(context.Background(), test.secretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, corev1.SecretTypeOpaque, intermediates.Type)
+			assert.Equal(t, "test", intermediates.Annotations[cmapi.CertificateNameKey])
+			assert.Equal(t, string(chain.cas[0].certPEM), string(intermediates.Data[test.expectedKey]))
+
+			// Other data in the intermediates Secret is preserved when the
+			// Certificate is renewed.
+			intermediates.Data["other"] = []byte("data")
+			_, err = builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Update(context.Background(), intermediates, metav1.UpdateOptions{})
+			require.NoError(t, err)
+			waitForSecret(t, secretsLister, test.secretName, "other")
+			waitForSecret(t, secretsLister, "output", corev1.TLSCertKey)
+
+			renewed := mustLeafWithChain(t)
+			err = testManager.UpdateData(context.Background(), crt, SecretData{PrivateKey: renewed.leaf.keyPEM, Certificate: renewed.all.certsToPEM()})
+			require.NoError(t, err)
+
+			intermediates, err = builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Get(context.Background(), test.secretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, string(renewed.cas[0].certPEM), string(intermediates.Data[test.expectedKey]))
+			assert.Equal(t, "data", string(intermediates.Data["other"]))
+		})
+	}
+}
+
+func TestSecretsManagerSyncIntermediatesSecret(t *testing.T) {
+	// waitForLister waits for the Secret with the given name to be observed
+	// by the lister in the given state, as it would be before the next sync.
+	waitForLister := func(t *testing.T, secretsLister clientcorev1.SecretLister, name string, fn func(*corev1.Secret, error) bool) {
+		err := wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+			return fn(secretsLister.Secrets(gen.DefaultTestNamespace).Get(name)), nil
+		})
+		require.NoError(t, err, "timed out waiting for the Secret to be observed")
+	}
+	exists := func(secret *corev1.Secret, err error) bool { return err == nil }
+	deleted := func(_ *corev1.Secret, err error) bool { return apierrors.IsNotFound(err) }
+
+	tests := map[string]struct {
+		otherData    bool
+		expectDelete bool
+	}{
+		"should delete the intermediates Secret once the annotation is removed": {
+			expectDelete: true,
+		},
+		"should only remove the intermediates from a Secret that holds other data once the annotation is removed": {
+			otherData: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := gen.Certificate("test", gen.SetCertificateSecretName("output"), gen.SetCertificateUID("test-uid"),
+				gen.AddCertificateAnnotations(map[string]string{cmapi.IntermediatesSecretNameAnnotationKey: "intermediates"}))
+
+			builder := &testpkg.Builder{
+				T:     t,
+				Clock: fixedClock,
+			}
+			builder.Init()
+			defer builder.Stop()
+
+			secretsLister := builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister()
+			testManager := New(builder.Client, secretsLister, true)
+			builder.Start()
+
+			chain := mustLeafWithChain(t)
+			require.NoError(t, testManager.UpdateData(context.Background(), crt, SecretData{PrivateKey: chain.leaf.keyPEM, Certificate: chain.all.certsToPEM()}))
+			waitForLister(t, secretsLister, "output", exists)
+
+			// The intermediates Secret is recreated if it is deleted.
+			require.NoError(t, builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Delete(context.Background(), "intermediates", metav1.DeleteOptions{}))
+			waitForLister(t, secretsLister, "intermediates", deleted)
+			require.NoError(t, testManager.SyncIntermediatesSecret(context.Background(), crt))
+			intermediates, err := builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Get(context.Background(), "intermediates", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, string(chain.cas[0].certPEM), string(intermediates.Data["chain.pem"]))
+
+			if test.otherData {
+				intermediates.Data["other"] = []byte("data")
+				_, err = builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Update(context.Background(), intermediates, metav1.UpdateOptions{})
+				require.NoError(t, err)
+			}
+			waitForLister(t, secretsLister, "intermediates", func(secret *corev1.Secret, err error) bool {
+				return err == nil && (!test.otherData || secret.Data["other"] != nil)
+			})
+
+			// The intermediates are removed once the annotation is removed.
+			crt = crt.DeepCopy()
+			delete(crt.Annotations, cmapi.IntermediatesSecretNameAnnotationKey)
+			require.NoError(t, testManager.SyncIntermediatesSecret(context.Background(), crt))
+
+			intermediates, err = builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Get(context.Background(), "intermediates", metav1.GetOptions{})
+			if test.expectDelete {
+				assert.True(t, apierrors.IsNotFound(err), "expected the intermediates Secret to be deleted, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string][]byte{"other": []byte("data")}, intermediates.Data)
+			assert.NotContains(t, intermediates.Annotations, cmapi.CertificateNameKey)
+			assert.NotContains(t, intermediates.Annotations, cmapi.IntermediatesSecretKeyAnnotationKey)
+			assert.Empty(t, intermediates.OwnerReferences)
+
+			// The Certificate's own Secret is left in place.
+			_, err = builder.Client.CoreV1().Secrets(gen.DefaultTestNamespace).Get(context.Background(), "output", metav1.GetOptions{})
+			assert.NoError(t, err)
 		})
 	}
 }
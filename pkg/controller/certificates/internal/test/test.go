@@ -129,10 +129,12 @@ func createCryptoBundle(originalCert *cmapi.Certificate, fixedClock *fakeclock.F
 			Annotations:     annotations,
 		},
 		Spec: cmapi.CertificateRequestSpec{
-			Request:   csrPEM,
-			Duration:  crt.Spec.Duration,
-			IssuerRef: crt.Spec.IssuerRef,
-			IsCA:      crt.Spec.IsCA,
+			Request:            csrPEM,
+			Duration:           crt.Spec.Duration,
+			IssuerRef:          crt.Spec.IssuerRef,
+			IsCA:               crt.Spec.IsCA,
+			MaxPathLen:         crt.Spec.MaxPathLen,
+			SignatureAlgorithm: crt.Spec.SignatureAlgorithm,
 		},
 	}
 
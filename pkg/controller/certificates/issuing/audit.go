@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issuing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+const (
+	// auditSchemaVersion is the version of the audit record schema. It must
+	// be bumped whenever a field is removed or changes meaning.
+	auditSchemaVersion = "v1"
+
+	auditOutcomeIssued = "Issued"
+	auditOutcomeFailed = "Failed"
+)
+
+// auditObjectReference identifies the namespaced resource an audit record
+// refers to.
+type auditObjectReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// auditRequester is the user that created the CertificateRequest.
+type auditRequester struct {
+	Username string   `json:"username,omitempty"`
+	UID      string   `json:"uid,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// auditIssuerRef is the issuer the CertificateRequest was sent to.
+type auditIssuerRef struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+// auditRecord is a single issuance audit record. Records are written as one
+// JSON object per line, and the field names form a stable schema that is
+// versioned by SchemaVersion.
+type auditRecord struct {
+	SchemaVersion      string               `json:"schemaVersion"`
+	Timestamp          string               `json:"timestamp"`
+	Outcome            string               `json:"outcome"`
+	Certificate        auditObjectReference `json:"certificate"`
+	CertificateRequest auditObjectReference `json:"certificateRequest"`
+	Requester          auditRequester       `json:"requester"`
+	IssuerRef          auditIssuerRef       `json:"issuerRef"`
+
+	CommonName     string   `json:"commonName,omitempty"`
+	DNSNames       []string `json:"dnsNames,omitempty"`
+	IPAddresses    []string `json:"ipAddresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+
+	// Only set for issued certificates
+	SerialNumber string `json:"serialNumber,omitempty"`
+	NotBefore    string `json:"notBefore,omitempty"`
+	NotAfter     string `json:"notAfter,omitempty"`
+
+	// Only set for failed issuances
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// auditLogger writes issuance audit records to a sink that is separate from
+// the controller's own logs.
+type auditLogger struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+// newAuditLogger returns an auditLogger writing to the file at the given
+// path, which is created if it does not exist and always appended to. A path
+// of "-" writes to stdout.
+func newAuditLogger(path string) (*auditLogger, error) {
+	if path == "-" {
+		return &auditLogger{w: os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open issuance audit log: %w", err)
+	}
+	return &auditLogger{w: f}, nil
+}
+
+// write encodes the record as a single line of JSON.
+func (a *auditLogger) write(record *auditRecord) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return json.NewEncoder(a.w).Encode(record)
+}
+
+// newAuditRecord builds the audit record of an issuance of the Certificate
+// using the given CertificateRequest. The subject and SANs are taken from the
+// signed certificate if there is one, and from the request otherwise.
+func newAuditRecord(now time.Time, outcome string, crt *cmapi.Certificate, req *cmapi.CertificateRequest) *auditRecord {
+	record := &auditRecord{
+		SchemaVersion:      auditSchemaVersion,
+		Timestamp:          now.UTC().Format(time.RFC3339),
+		Outcome:            outcome,
+		Certificate:        auditObjectReference{Name: crt.Name, Namespace: crt.Namespace},
+		CertificateRequest: auditObjectReference{Name: req.Name, Namespace: req.Namespace},
+		Requester: auditRequester{
+			Username: req.Spec.Username,
+			UID:      req.Spec.UID,
+			Groups:   req.Spec.Groups,
+		},
+		IssuerRef: auditIssuerRef{
+			Name:  req.Spec.IssuerRef.Name,
+			Kind:  req.Spec.IssuerRef.Kind,
+			Group: req.Spec.IssuerRef.Group,
+		},
+	}
+
+	if outcome == auditOutcomeIssued {
+		if cert, err := utilpki.DecodeX509CertificateBytes(req.Status.Certificate); err == nil {
+			record.CommonName = cert.Subject.CommonName
+			record.DNSNames = cert.DNSNames
+			record.IPAddresses = utilpki.IPAddressesToString(cert.IPAddresses)
+			record.URIs = utilpki.URLsToString(cert.URIs)
+			record.EmailAddresses = cert.EmailAddresses
+			record.SerialNumber = cert.SerialNumber.Text(16)
+			record.NotBefore = cert.NotBefore.UTC().Format(time.RFC3339)
+			record.NotAfter = cert.NotAfter.UTC().Format(time.RFC3339)
+			return record
+		}
+	}
+
+	if csr, err := utilpki.DecodeX509CertificateRequestBytes(req.Spec.Request); err == nil {
+		record.CommonName = csr.Subject.CommonName
+		record.DNSNames = csr.DNSNames
+		record.IPAddresses = utilpki.IPAddressesToString(csr.IPAddresses)
+		record.URIs = utilpki.URLsToString(csr.URIs)
+		record.EmailAddresses = csr.EmailAddresses
+	}
+	return record
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issuing
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	internaltest "github.com/jetstack/cert-manager/pkg/controller/certificates/internal/test"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+func TestAuditIssuance(t *testing.T) {
+	crt := gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateDNSNames("example.com", "www.example.com"),
+		gen.SetCertificateIPs("10.0.0.1"),
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "ClusterIssuer", Group: "cert-manager.io"}),
+	)
+	bundle := internaltest.MustCreateCryptoBundle(t, crt, fixedClock)
+	req := gen.CertificateRequestFrom(bundle.CertificateRequestReady,
+		gen.SetCertificateRequestUsername("alice"),
+		gen.SetCertificateRequestGroups([]string{"system:authenticated"}),
+	)
+	now := fixedClock.Now().UTC().Format(time.RFC3339)
+
+	tests := map[string]struct {
+		outcome, reason, message string
+		expRecord                map[string]interface{}
+	}{
+		"a successful issuance should record the issued certificate": {
+			outcome: auditOutcomeIssued,
+			expRecord: map[string]interface{}{
+				"schemaVersion":      "v1",
+				"timestamp":          now,
+				"outcome":            "Issued",
+				"certificate":        map[string]interface{}{"name": "test", "namespace": "testns"},
+				"certificateRequest": map[string]interface{}{"name": req.Name, "namespace": "testns"},
+				"requester":          map[string]interface{}{"username": "alice", "groups": []interface{}{"system:authenticated"}},
+				"issuerRef":          map[string]interface{}{"name": "ca-issuer", "kind": "ClusterIssuer", "group": "cert-manager.io"},
+				"commonName":         "example.com",
+				"dnsNames":           []interface{}{"example.com", "www.example.com"},
+				"ipAddresses":        []interface{}{"10.0.0.1"},
+				"serialNumber":       bundle.Cert.SerialNumber.Text(16),
+				"notBefore":          bundle.Cert.NotBefore.UTC().Format(time.RFC3339),
+				"notAfter":           bundle.Cert.NotAfter.UTC().Format(time.RFC3339),
+			},
+		},
+		"a failed issuance should record the request and failure reason": {
+			outcome: auditOutcomeFailed,
+			reason:  "Failed",
+			message: "The certificate request has failed to complete and will be retried: boom",
+			expRecord: map[string]interface{}{
+				"schemaVersion":      "v1",
+				"timestamp":          now,
+				"outcome":            "Failed",
+				"certificate":        map[string]interface{}{"name": "test", "namespace": "testns"},
+				"certificateRequest": map[string]interface{}{"name": req.Name, "namespace": "testns"},
+				"requester":          map[string]interface{}{"username": "alice", "groups": []interface{}{"system:authenticated"}},
+				"issuerRef":          map[string]interface{}{"name": "ca-issuer", "kind": "ClusterIssuer", "group": "cert-manager.io"},
+				"commonName":         "example.com",
+				"dnsNames":           []interface{}{"example.com", "www.example.com"},
+				"ipAddresses":        []interface{}{"10.0.0.1"},
+				"reason":             "Failed",
+				"message":            "The certificate request has failed to complete and will be retried: boom",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := &controller{
+				clock:       fixedClock,
+				auditLogger: &auditLogger{w: &buf},
+			}
+
+			c.auditIssuance(logf.Log, test.outcome, bundle.Certificate, req, test.reason, test.message)
+
+			if lines := strings.Count(buf.String(), "\n"); lines != 1 {
+				t.Fatalf("expected a single line audit record, got %d lines: %s", lines, buf.String())
+			}
+			var record map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("failed to decode audit record: %v", err)
+			}
+			if !reflect.DeepEqual(record, test.expRecord) {
+				t.Errorf("unexpected audit record\nexp=%v\ngot=%v", test.expRecord, record)
+			}
+		})
+	}
+}
+
+func TestAuditIssuanceDisabled(t *testing.T) {
+	c := &controller{clock: fixedClock}
+	// Must not panic when audit logging is disabled
+	c.auditIssuance(logf.Log, auditOutcomeIssued, gen.Certificate("test"), &cmapi.CertificateRequest{}, "", "")
+}
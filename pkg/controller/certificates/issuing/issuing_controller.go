@@ -20,9 +20,13 @@ import (
 	"context"
 	"crypto"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,15 +47,24 @@ import (
 	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates"
+	"github.com/jetstack/cert-manager/pkg/controller/certificates/internal/failurewebhook"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates/internal/secretsmanager"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/scheduler"
 	utilkube "github.com/jetstack/cert-manager/pkg/util/kube"
 	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/pkg/util/predicate"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 )
 
 const (
 	ControllerName = "certificates-issuing"
+
+	// KeyMismatchReason is the reason set on the Issuing condition when the
+	// signed certificate does not match the private key that would be stored
+	// alongside it in the Secret.
+	KeyMismatchReason = "KeyMismatch"
 )
 
 type localTemporarySignerFn func(crt *cmapi.Certificate, pk []byte) ([]byte, error)
@@ -72,6 +85,52 @@ type controller struct {
 	secretsManager *secretsmanager.SecretsManager
 	// localTemporarySigner signs a certificate that is stored temporarily
 	localTemporarySigner localTemporarySignerFn
+
+	// recordSecretEvents controls whether issuance events are also recorded
+	// against the Certificate's target Secret
+	recordSecretEvents bool
+
+	// requestAnnotationPrefix is the prefix of CertificateRequest annotations
+	// that are copied onto the Certificate's target Secret. Disabled if empty.
+	requestAnnotationPrefix string
+
+	// failureNotifier, if not nil, is notified of every failed issuance
+	failureNotifier *failurewebhook.Notifier
+
+	// auditLogger, if not nil, is sent an audit record of every successful
+	// and failed issuance
+	auditLogger *auditLogger
+
+	// manifestSigner, if not nil, signs a manifest of every issued
+	// certificate that is stored in its Secret
+	manifestSigner *manifestSigner
+
+	// issuerHelper, if set, is used to read the issuer referenced by a
+	// CertificateRequest, so that unsuccessful requests whose issuer has
+	// since changed are left for the requestmanager to replace
+	issuerHelper issuer.Helper
+
+	// tracer is used to trace the storing of signed certificates
+	tracer trace.Tracer
+
+	// secretRefreshInterval is how often the keystores and additional
+	// output formats in the Secrets of issued certificates are re-created.
+	// Disabled if zero.
+	secretRefreshInterval time.Duration
+	scheduledWorkQueue    scheduler.ScheduledWorkQueue
+
+	// lastSecretRefresh records when the derived data in the Secret of
+	// each Certificate was last refreshed, keyed by the Certificate's key
+	lastSecretRefreshLock sync.Mutex
+	lastSecretRefresh     map[string]secretRefresh
+}
+
+// secretRefresh records when the derived data in a Certificate's Secret was
+// last refreshed, and the versions of the keystore password Secrets it was
+// refreshed for.
+type secretRefresh struct {
+	time             time.Time
+	passwordVersions string
 }
 
 func NewController(
@@ -108,6 +167,17 @@ func NewController(
 		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
 			predicate.ExtractResourceName(predicate.CertificateSecretName)),
 	})
+	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		// Issuer restores the intermediates Secret named by the
+		// `cert-manager.io/intermediates-secret-name` annotation on changes
+		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
+			predicate.ExtractResourceName(predicate.CertificateIntermediatesSecretName)),
+	})
+	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		// Issuer refreshes keystores on changes to their password Secrets
+		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
+			predicate.ExtractResourceName(predicate.CertificateKeystorePasswordSecretName)),
+	})
 
 	// build a list of InformerSynced functions that will be returned by the Register method.
 	// the controller will only begin processing items once all of these informers have synced.
@@ -132,6 +202,12 @@ func NewController(
 		clock:                    clock,
 		secretsManager:           secretsManager,
 		localTemporarySigner:     certificates.GenerateLocallySignedTemporaryCertificate,
+		recordSecretEvents:       certificateControllerOptions.EnableSecretEvents,
+		requestAnnotationPrefix:  certificateControllerOptions.RequestAnnotationPrefix,
+		tracer:                   tracing.Tracer(nil),
+		secretRefreshInterval:    certificateControllerOptions.SecretRefreshInterval,
+		scheduledWorkQueue:       scheduler.NewScheduledWorkQueue(clock, queue.Add),
+		lastSecretRefresh:        make(map[string]secretRefresh),
 	}, queue, mustSync
 }
 
@@ -150,6 +226,9 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	crt, err := c.certificateLister.Certificates(namespace).Get(name)
 	if apierrors.IsNotFound(err) {
 		log.Error(err, "certificate not found for key")
+		c.lastSecretRefreshLock.Lock()
+		delete(c.lastSecretRefresh, key)
+		c.lastSecretRefreshLock.Unlock()
 		return nil
 	}
 	if err != nil {
@@ -163,43 +242,23 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		Type:   cmapi.CertificateConditionIssuing,
 		Status: cmmeta.ConditionTrue,
 	}) {
-		// Do nothing if an issuance is not in progress.
-		return nil
-	}
-
-	if crt.Status.NextPrivateKeySecretName == nil ||
-		len(*crt.Status.NextPrivateKeySecretName) == 0 {
-		// Do nothing if the next private key secret name is not set
-		return nil
+		// Do nothing if an issuance is not in progress, other than
+		// keeping the data derived from the issued certificate up to date.
+		if err := c.secretsManager.SyncIntermediatesSecret(ctx, crt); err != nil {
+			return fmt.Errorf("syncing intermediates Secret: %w", err)
+		}
+		return c.refreshSecretDataIfDue(ctx, key, crt)
 	}
 
-	// Fetch and parse the 'next private key secret'
-	nextPrivateKeySecret, err := c.secretLister.Secrets(crt.Namespace).Get(*crt.Status.NextPrivateKeySecretName)
-	if apierrors.IsNotFound(err) {
-		log.V(logf.DebugLevel).Info("Next private key secret does not exist, waiting for keymanager controller")
-		// If secret does not exist, do nothing (keymanager will handle this).
-		return nil
-	}
-	if err != nil {
-		return err
-	}
-	if nextPrivateKeySecret.Data == nil || len(nextPrivateKeySecret.Data[corev1.TLSPrivateKeyKey]) == 0 {
-		logf.WithResource(log, nextPrivateKeySecret).Info("Next private key secret does not contain any private key data, waiting for keymanager controller")
-		return nil
-	}
-	pk, _, err := utilkube.ParseTLSKeyFromSecret(nextPrivateKeySecret, corev1.TLSPrivateKeyKey)
-	if err != nil {
-		// If the private key cannot be parsed here, do nothing as the key manager will handle this.
-		logf.WithResource(log, nextPrivateKeySecret).Error(err, "failed to parse next private key, waiting for keymanager controller")
-		return nil
-	}
-	pkViolations, err := certificates.PrivateKeyMatchesSpec(pk, crt.Spec)
-	if err != nil {
-		return err
-	}
-	if len(pkViolations) > 0 {
-		logf.WithResource(log, nextPrivateKeySecret).Info("stored next private key does not match requirements on Certificate resource, waiting for keymanager controller", "violations", pkViolations)
-		return nil
+	// pk is the next private key, and is left unset if the Certificate uses a
+	// user supplied CSR as cert-manager does not manage its private key.
+	var pk crypto.Signer
+	if crt.Spec.CSRSecretRef == nil {
+		var err error
+		pk, err = c.nextPrivateKey(ctx, crt)
+		if err != nil || pk == nil {
+			return err
+		}
 	}
 
 	// CertificateRequest revisions begin from 1. If no revision is set on the
@@ -225,9 +284,15 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	req := reqs[0]
 	log = logf.WithResource(log, req)
 
+	ctx, span := c.tracer.Start(tracing.ContextFromObject(ctx, req), ControllerName, trace.WithAttributes(
+		attribute.String("namespace", crt.Namespace),
+		attribute.String("name", crt.Name),
+	))
+	defer span.End()
+
 	// Verify the CSR options match what is requested in certificate.spec.
 	// If there are violations in the spec, then the requestmanager will handle this.
-	requestViolations, err := certificates.RequestMatchesSpec(req, crt.Spec)
+	requestViolations, err := certificates.RequestMatchesCertificate(c.issuerHelper, req, crt)
 	if err != nil {
 		return err
 	}
@@ -249,7 +314,18 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	cond := apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionReady)
 	if cond == nil {
 		if apiutil.CertificateRequestIsDenied(req) {
-			return c.failIssueCertificate(ctx, log, crt, apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionDenied))
+			// If the Certificate spec has changed since the request was
+			// created, do nothing (requestmanager will replace the denied
+			// request with a new one).
+			if certificates.RequestPredatesGeneration(req, crt) {
+				log.V(logf.DebugLevel).Info("CertificateRequest was denied for an older generation of the Certificate, waiting for it to be replaced")
+				return nil
+			}
+			if certificates.RequestPredatesIssuerGeneration(c.issuerHelper, req) {
+				log.V(logf.DebugLevel).Info("CertificateRequest was denied for an older generation of the issuer, waiting for it to be replaced")
+				return nil
+			}
+			return c.failIssueCertificate(ctx, log, crt, req, apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionDenied))
 		}
 
 		log.V(logf.DebugLevel).Info("CertificateRequest does not have Ready condition, waiting...")
@@ -259,7 +335,19 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	// If the certificate request has failed, set the last failure time to now,
 	// and set the Issuing status condition to False with reason.
 	if cond.Reason == cmapi.CertificateRequestReasonFailed {
-		return c.failIssueCertificate(ctx, log, crt, apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionReady))
+		// If a fallback issuer remains, do nothing (requestmanager will
+		// retry the request with the next issuer).
+		// If the issuer has changed since the request was created, do nothing
+		// (requestmanager will replace the failed request with a new one).
+		if certificates.RequestPredatesIssuerGeneration(c.issuerHelper, req) {
+			log.V(logf.DebugLevel).Info("CertificateRequest failed for an older generation of the issuer, waiting for it to be replaced")
+			return nil
+		}
+		if issuerRef, ok := certificates.NextIssuerRef(crt.Spec, req.Spec.IssuerRef); ok {
+			log.V(logf.DebugLevel).Info("CertificateRequest has failed, waiting for requestmanager to retry with the next issuer", "issuer", issuerRef.Name)
+			return nil
+		}
+		return c.failIssueCertificate(ctx, log, crt, req, apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionReady))
 	}
 
 	// If public key does not match, do nothing (requestmanager will handle this).
@@ -267,26 +355,44 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	if err != nil {
 		return err
 	}
-	publicKeyMatchesCSR, err := utilpki.PublicKeyMatchesCSR(pk.Public(), csr)
-	if err != nil {
-		return err
-	}
-	if !publicKeyMatchesCSR {
-		logf.WithResource(log, nextPrivateKeySecret).Info("next private key does not match CSR public key, waiting for requestmanager controller")
-		return nil
+	publicKey := csr.PublicKey
+	if pk != nil {
+		publicKeyMatchesCSR, err := utilpki.PublicKeyMatchesCSR(pk.Public(), csr)
+		if err != nil {
+			return err
+		}
+		if !publicKeyMatchesCSR {
+			log.Info("next private key does not match CSR public key, waiting for requestmanager controller")
+			return nil
+		}
+		publicKey = pk.Public()
 	}
 
 	// If the CertificateRequest is valid and ready, verify its status and issue
 	// accordingly.
 	if cond.Reason == cmapi.CertificateRequestReasonIssued {
+		// Certificates issued by a staging issuer are never stored
+		// (requestmanager will replace the request with one for the
+		// Certificate's issuer).
+		if certificates.IsStagingRequest(req) {
+			log.V(logf.DebugLevel).Info("CertificateRequest was issued by the staging issuer, waiting for requestmanager to request the certificate from the Certificate's issuer")
+			return nil
+		}
+		// Never write a certificate to the Secret that does not match the
+		// private key it will be stored alongside.
+		if mismatch := signedCertificateKeyMismatch(req, publicKey); mismatch != nil {
+			return c.failIssueCertificate(ctx, log, crt, req, mismatch)
+		}
 		return c.issueCertificate(ctx, nextRevision, crt, req, pk)
 	}
 
 	// Issue temporary certificate if needed. If a certificate was issued, then
 	// return early - we will sync again since the target Secret has been
-	// updated.
-	if issued, err := c.ensureTemporaryCertificate(ctx, crt, pk); err != nil || issued {
-		return err
+	// updated. Temporary certificates cannot be issued without a private key.
+	if pk != nil {
+		if issued, err := c.ensureTemporaryCertificate(ctx, crt, pk); err != nil || issued {
+			return err
+		}
 	}
 
 	// CertificateRequest is not in a final state so do nothing.
@@ -294,10 +400,124 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	return nil
 }
 
+// refreshSecretDataIfDue re-creates the keystores and additional output
+// formats in the Secret of a Ready Certificate once the secret data refresh
+// interval has elapsed since they were last refreshed, or as soon as one of
+// its keystore password Secrets has changed, so that changes to keystore
+// passwords are picked up without re-issuing the certificate. The first time
+// a Certificate is seen its Secret is assumed to be up to date, so that all
+// Secrets are not rewritten when the controller starts.
+func (c *controller) refreshSecretDataIfDue(ctx context.Context, key string, crt *cmapi.Certificate) error {
+	if c.secretRefreshInterval <= 0 || !apiutil.CertificateHasCondition(crt, cmapi.CertificateCondition{
+		Type:   cmapi.CertificateConditionReady,
+		Status: cmmeta.ConditionTrue,
+	}) {
+		return nil
+	}
+
+	now := c.clock.Now()
+	passwordVersions := c.keystorePasswordVersions(crt)
+	c.lastSecretRefreshLock.Lock()
+	last, ok := c.lastSecretRefresh[key]
+	if !ok {
+		c.lastSecretRefresh[key] = secretRefresh{time: now, passwordVersions: passwordVersions}
+	}
+	c.lastSecretRefreshLock.Unlock()
+
+	if !ok {
+		c.scheduledWorkQueue.Add(key, c.secretRefreshInterval)
+		return nil
+	}
+	if elapsed := now.Sub(last.time); elapsed < c.secretRefreshInterval && passwordVersions == last.passwordVersions {
+		c.scheduledWorkQueue.Add(key, c.secretRefreshInterval-elapsed)
+		return nil
+	}
+
+	logf.FromContext(ctx).V(logf.DebugLevel).Info("refreshing keystores and additional output formats in Secret")
+	if err := c.secretsManager.RefreshDerivedData(ctx, crt); err != nil {
+		return fmt.Errorf("refreshing data in Secret %q: %w", crt.Spec.SecretName, err)
+	}
+
+	c.lastSecretRefreshLock.Lock()
+	c.lastSecretRefresh[key] = secretRefresh{time: now, passwordVersions: passwordVersions}
+	c.lastSecretRefreshLock.Unlock()
+	c.scheduledWorkQueue.Add(key, c.secretRefreshInterval)
+
+	return nil
+}
+
+// keystorePasswordVersions returns the resource versions of the keystore
+// password Secrets referenced by the Certificate, so that changes to them can
+// be detected. Secrets that cannot be read are given an empty version.
+func (c *controller) keystorePasswordVersions(crt *cmapi.Certificate) string {
+	if crt.Spec.Keystores == nil {
+		return ""
+	}
+
+	var refs []cmmeta.SecretKeySelector
+	if ks := crt.Spec.Keystores.PKCS12; ks != nil && ks.Create {
+		refs = append(refs, ks.PasswordSecretRef)
+	}
+	if ks := crt.Spec.Keystores.JKS; ks != nil && ks.Create {
+		refs = append(refs, ks.PasswordSecretRef)
+	}
+
+	versions := make([]string, len(refs))
+	for i, ref := range refs {
+		if secret, err := c.secretLister.Secrets(crt.Namespace).Get(ref.Name); err == nil {
+			versions[i] = ref.Name + "/" + secret.ResourceVersion
+		}
+	}
+	return strings.Join(versions, ",")
+}
+
+// nextPrivateKey fetches and parses the Certificate's 'next private key
+// secret'. If the secret is not yet usable, a nil key is returned and the
+// keymanager controller is left to handle it.
+func (c *controller) nextPrivateKey(ctx context.Context, crt *cmapi.Certificate) (crypto.Signer, error) {
+	log := logf.FromContext(ctx)
+	if crt.Status.NextPrivateKeySecretName == nil ||
+		len(*crt.Status.NextPrivateKeySecretName) == 0 {
+		// Do nothing if the next private key secret name is not set
+		return nil, nil
+	}
+
+	// Fetch and parse the 'next private key secret'
+	nextPrivateKeySecret, err := c.secretLister.Secrets(crt.Namespace).Get(*crt.Status.NextPrivateKeySecretName)
+	if apierrors.IsNotFound(err) {
+		log.V(logf.DebugLevel).Info("Next private key secret does not exist, waiting for keymanager controller")
+		// If secret does not exist, do nothing (keymanager will handle this).
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	log = logf.WithResource(log, nextPrivateKeySecret)
+	if nextPrivateKeySecret.Data == nil || len(nextPrivateKeySecret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		log.Info("Next private key secret does not contain any private key data, waiting for keymanager controller")
+		return nil, nil
+	}
+	pk, _, err := utilkube.ParseTLSKeyFromSecret(nextPrivateKeySecret, corev1.TLSPrivateKeyKey)
+	if err != nil {
+		// If the private key cannot be parsed here, do nothing as the key manager will handle this.
+		log.Error(err, "failed to parse next private key, waiting for keymanager controller")
+		return nil, nil
+	}
+	pkViolations, err := certificates.PrivateKeyMatchesSpec(pk, crt.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkViolations) > 0 {
+		log.Info("stored next private key does not match requirements on Certificate resource, waiting for keymanager controller", "violations", pkViolations)
+		return nil, nil
+	}
+	return pk, nil
+}
+
 // failIssueCertificate will mark the Issuing condition of this Certificate as
 // failed, and log an appropriate event. The reason and message of the
 // condition will be that of the CertificateRequest condition passed.
-func (c *controller) failIssueCertificate(ctx context.Context, log logr.Logger, crt *cmapi.Certificate, condition *cmapi.CertificateRequestCondition) error {
+func (c *controller) failIssueCertificate(ctx context.Context, log logr.Logger, crt *cmapi.Certificate, req *cmapi.CertificateRequest, condition *cmapi.CertificateRequestCondition) error {
 	nowTime := metav1.NewTime(c.clock.Now())
 	crt.Status.LastFailureTime = &nowTime
 
@@ -310,37 +530,93 @@ func (c *controller) failIssueCertificate(ctx context.Context, log logr.Logger,
 
 	crt = crt.DeepCopy()
 	apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, reason, message)
+	certificates.RecordReconciliation(crt, ControllerName, c.clock.Now())
 
 	_, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
 	if err != nil {
 		return err
 	}
 
-	c.recorder.Event(crt, corev1.EventTypeWarning, reason, message)
+	c.recordIssuanceEvent(crt, corev1.EventTypeWarning, reason, message)
+	if c.failureNotifier != nil {
+		c.failureNotifier.Notify(crt, reason, message)
+	}
+	c.auditIssuance(log, auditOutcomeFailed, crt, req, reason, message)
+
+	return nil
+}
+
+// auditIssuance writes an audit record of the outcome of issuing the
+// Certificate using the given CertificateRequest, if audit logging is
+// enabled. A failure to write the record does not fail the issuance.
+func (c *controller) auditIssuance(log logr.Logger, outcome string, crt *cmapi.Certificate, req *cmapi.CertificateRequest, reason, message string) {
+	if c.auditLogger == nil {
+		return
+	}
+	record := newAuditRecord(c.clock.Now(), outcome, crt, req)
+	record.Reason, record.Message = reason, message
+	if err := c.auditLogger.write(record); err != nil {
+		log.Error(err, "failed to write issuance audit record")
+	}
+}
 
+// signedCertificateKeyMismatch checks that the public key of the certificate
+// signed for the given CertificateRequest matches the given public key. If it
+// does not, a condition describing the mismatch is returned.
+func signedCertificateKeyMismatch(req *cmapi.CertificateRequest, publicKey crypto.PublicKey) *cmapi.CertificateRequestCondition {
+	x509Cert, err := utilpki.DecodeX509CertificateBytes(req.Status.Certificate)
+	if err != nil {
+		return &cmapi.CertificateRequestCondition{
+			Reason:  KeyMismatchReason,
+			Message: fmt.Sprintf("Failed to decode signed certificate: %v", err),
+		}
+	}
+	matches, err := utilpki.PublicKeyMatchesCertificate(publicKey, x509Cert)
+	if err != nil || !matches {
+		return &cmapi.CertificateRequestCondition{
+			Reason:  KeyMismatchReason,
+			Message: "Signed certificate does not match the next private key",
+		}
+	}
 	return nil
 }
 
 // issueCertificate will ensure the public key of the CSR matches the signed
 // certificate, and then store the certificate, CA and private key into the
-// Secret in the appropriate format type.
+// Secret in the appropriate format type. If pk is nil, as is the case for a
+// user supplied CSR, no private key is stored.
 func (c *controller) issueCertificate(ctx context.Context, nextRevision int, crt *cmapi.Certificate, req *cmapi.CertificateRequest, pk crypto.Signer) error {
 	crt = crt.DeepCopy()
 	if crt.Spec.PrivateKey == nil {
 		crt.Spec.PrivateKey = &cmapi.CertificatePrivateKey{}
 	}
 
-	pkData, err := utilpki.EncodePrivateKey(pk, crt.Spec.PrivateKey.Encoding)
-	if err != nil {
-		return err
-	}
 	secretData := secretsmanager.SecretData{
-		PrivateKey:  pkData,
 		Certificate: req.Status.Certificate,
 		CA:          req.Status.CA,
+		IssuerRef:   &req.Spec.IssuerRef,
+		Annotations: requestAnnotationsWithPrefix(req, c.requestAnnotationPrefix),
+	}
+	if pk != nil {
+		pkData, err := utilpki.EncodePrivateKey(pk, crt.Spec.PrivateKey.Encoding)
+		if err != nil {
+			return err
+		}
+		secretData.PrivateKey = pkData
+	}
+	if c.manifestSigner != nil {
+		manifest, signature, err := c.manifestSigner.sign(req)
+		if err != nil {
+			return err
+		}
+		secretData.Manifest, secretData.ManifestSignature = manifest, signature
 	}
 
-	err = c.secretsManager.UpdateData(ctx, crt, secretData)
+	secretCtx, span := c.tracer.Start(ctx, "WriteSecret", trace.WithAttributes(
+		attribute.String("secret", crt.Spec.SecretName),
+	))
+	err := c.secretsManager.UpdateData(secretCtx, crt, secretData)
+	span.End()
 	if err != nil {
 		return err
 	}
@@ -354,17 +630,91 @@ func (c *controller) issueCertificate(ctx context.Context, nextRevision int, crt
 	//Clear status.lastFailureTime (if set)
 	crt.Status.LastFailureTime = nil
 
+	// The next private key has now been promoted into the target Secret, so
+	// clear status.nextPrivateKeySecretName. The staged Secret resource is
+	// deleted by the keymanager once issuance is no longer in progress.
+	crt.Status.NextPrivateKeySecretName = nil
+
+	// Record which issuer signed the certificate if fallbacks are configured
+	crt.Status.ActiveIssuerRef = nil
+	if len(crt.Spec.IssuerRefs) > 0 {
+		issuerRef := req.Spec.IssuerRef
+		crt.Status.ActiveIssuerRef = &issuerRef
+	}
+
+	certificates.RecordReconciliation(crt, ControllerName, c.clock.Now())
+
+	// Update the running average of the time taken to issue this certificate
+	if latency, ok := issuanceLatency(req); ok {
+		crt.Status.IssuanceLatency = certificates.UpdateIssuanceLatency(crt.Status.IssuanceLatency, latency)
+	}
+
 	_, err = c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
 	if err != nil {
 		return err
 	}
 
 	message := "The certificate has been successfully issued"
-	c.recorder.Event(crt, corev1.EventTypeNormal, "Issuing", message)
+	c.recordIssuanceEvent(crt, corev1.EventTypeNormal, "Issuing", message)
+	c.auditIssuance(logf.FromContext(ctx), auditOutcomeIssued, crt, req, "", "")
 
 	return nil
 }
 
+// requestAnnotationsWithPrefix returns the annotations of the given
+// CertificateRequest whose keys begin with prefix. No annotations are
+// returned if prefix is empty.
+func requestAnnotationsWithPrefix(req *cmapi.CertificateRequest, prefix string) map[string]string {
+	if prefix == "" {
+		return nil
+	}
+	var annotations map[string]string
+	for k, v := range req.Annotations {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// recordIssuanceEvent records an event against the given Certificate, and also
+// against its target Secret if the controller is configured to do so.
+func (c *controller) recordIssuanceEvent(crt *cmapi.Certificate, eventtype, reason, message string) {
+	c.recorder.Event(crt, eventtype, reason, message)
+	if !c.recordSecretEvents {
+		return
+	}
+
+	secret, err := c.secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
+	if err != nil {
+		// The Secret may not exist yet, or may not yet have been observed by
+		// the lister, so record the event against a reference to it instead.
+		secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: crt.Namespace, Name: crt.Spec.SecretName}}
+	}
+	c.recorder.Event(secret, eventtype, reason, message)
+}
+
+// issuanceLatency returns the time taken for the given CertificateRequest to
+// become Ready since it was created. False is returned if the latency cannot
+// be determined.
+func issuanceLatency(req *cmapi.CertificateRequest) (time.Duration, bool) {
+	cond := apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionReady)
+	if cond == nil || cond.LastTransitionTime == nil || req.CreationTimestamp.IsZero() {
+		return 0, false
+	}
+
+	latency := cond.LastTransitionTime.Sub(req.CreationTimestamp.Time)
+	if latency < 0 {
+		return 0, false
+	}
+
+	return latency, true
+}
+
 // controllerWrapper wraps the `controller` structure to make it implement
 // the controllerpkg.queueingController interface
 type controllerWrapper struct {
@@ -384,6 +734,28 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.Clock,
 		ctx.CertificateOptions,
 	)
+	if url := ctx.CertificateOptions.IssuanceFailureWebhookURL; url != "" {
+		ctrl.failureNotifier = failurewebhook.New(log, url, ctx.Metrics)
+		ctrl.failureNotifier.Start(ctx.RootContext)
+	}
+	if path := ctx.CertificateOptions.IssuanceAuditLogPath; path != "" {
+		auditLogger, err := newAuditLogger(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctrl.auditLogger = auditLogger
+	}
+	if path := ctx.CertificateOptions.ManifestSigningKeyPath; path != "" {
+		manifestSigner, err := newManifestSigner(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctrl.manifestSigner = manifestSigner
+	}
+	issuerHelper, issuerSynced := certificates.NewIssuerHelper(ctx)
+	ctrl.issuerHelper = issuerHelper
+	ctrl.tracer = tracing.Tracer(ctx.TracerProvider)
+	mustSync = append(mustSync, issuerSynced...)
 	c.controller = ctrl
 
 	return queue, mustSync, nil
@@ -18,21 +18,36 @@ package issuing
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	coretesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	fakeclock "k8s.io/utils/clock/testing"
+	"software.sslmate.com/src/go-pkcs12"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	cmfake "github.com/jetstack/cert-manager/pkg/client/clientset/versioned/fake"
+	"github.com/jetstack/cert-manager/pkg/controller/certificates/internal/failurewebhook"
 	internaltest "github.com/jetstack/cert-manager/pkg/controller/certificates/internal/test"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/metrics"
+	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
@@ -53,6 +68,14 @@ func TestIssuingController(t *testing.T) {
 
 		certificate *cmapi.Certificate
 
+		// enableSecretEvents enables recording issuance events against the
+		// target Secret
+		enableSecretEvents bool
+
+		// expectedEventObjects, if set, is the 'Kind/name' of the object that
+		// each expected event is recorded against
+		expectedEventObjects []string
+
 		expectedErr bool
 	}
 
@@ -79,8 +102,23 @@ func TestIssuingController(t *testing.T) {
 		}),
 	)
 
+	// pkcs8Bundle requests the PKCS#8 encoding, and its next private key is
+	// stored using the PKCS#1 encoding
+	pkcs8Bundle := internaltest.MustCreateCryptoBundle(t, gen.CertificateFrom(baseCert.DeepCopy(),
+		gen.SetCertificateKeyEncoding(cmapi.PKCS8),
+	), fixedClock)
+	pkcs8IssuingCert := gen.CertificateFrom(issuingCert.DeepCopy(),
+		gen.SetCertificateKeyEncoding(cmapi.PKCS8),
+	)
+	pkcs1PrivateKeyBytes, err := utilpki.EncodePrivateKey(pkcs8Bundle.PrivateKey, cmapi.PKCS1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	metaFixedClockStart := metav1.NewTime(fixedClockStart)
 
+	fallbackIssuerRef := cmmeta.ObjectReference{Name: "fallback-issuer", Kind: "ClusterIssuer"}
+
 	tests := map[string]testT{
 		"if certificate is not in Issuing state, then do nothing": {
 			certificate: exampleBundle.Certificate,
@@ -246,6 +284,7 @@ func TestIssuingController(t *testing.T) {
 						"status",
 						exampleBundle.Certificate.Namespace,
 						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
 							gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
 								Type:               cmapi.CertificateConditionIssuing,
 								Status:             cmmeta.ConditionFalse,
@@ -264,6 +303,144 @@ func TestIssuingController(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		"if certificate is in Issuing state, one CertificateRequest, but has failed, and secret events are enabled, log the event on both the Certificate and Secret": {
+			certificate:        exampleBundle.Certificate,
+			enableSecretEvents: true,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestFailed,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+						gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:    cmapi.CertificateRequestConditionReady,
+							Status:  cmmeta.ConditionFalse,
+							Reason:  cmapi.CertificateRequestReasonFailed,
+							Message: "The certificate request failed because of reasons",
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						exampleBundle.Certificate.Namespace,
+						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
+							gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+								Type:               cmapi.CertificateConditionIssuing,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             "Failed",
+								Message:            "The certificate request has failed to complete and will be retried: The certificate request failed because of reasons",
+								LastTransitionTime: &metaFixedClockStart,
+								ObservedGeneration: 3,
+							}),
+							gen.SetCertificateLastFailureTime(metaFixedClockStart),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					"Warning Failed The certificate request has failed to complete and will be retried: The certificate request failed because of reasons",
+					"Warning Failed The certificate request has failed to complete and will be retried: The certificate request failed because of reasons",
+				},
+			},
+			expectedEventObjects: []string{"Certificate/test", "Secret/output"},
+			expectedErr:          false,
+		},
+		"if certificate is in Issuing state, one CertificateRequest, but has failed and a fallback issuer remains, do nothing": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateIssuerRefs(fallbackIssuerRef),
+			),
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert,
+						gen.SetCertificateIssuerRefs(fallbackIssuerRef),
+					),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestFailed,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+						gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:    cmapi.CertificateRequestConditionReady,
+							Status:  cmmeta.ConditionFalse,
+							Reason:  cmapi.CertificateRequestReasonFailed,
+							Message: "The certificate request failed because of reasons",
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{},
+			},
+			expectedErr: false,
+		},
+		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the signed certificate does not match the next private key, set failed state and log event": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+						// certificate signed for a different private key
+						gen.SetCertificateRequestCertificate(exampleBundleAlt.CertBytes),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						exampleBundle.Certificate.Namespace,
+						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
+							gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+								Type:               cmapi.CertificateConditionIssuing,
+								Status:             cmmeta.ConditionFalse,
+								Reason:             "KeyMismatch",
+								Message:            "The certificate request has failed to complete and will be retried: Signed certificate does not match the next private key",
+								LastTransitionTime: &metaFixedClockStart,
+								ObservedGeneration: 3,
+							}),
+							gen.SetCertificateLastFailureTime(metaFixedClockStart),
+						),
+					)),
+				},
+				ExpectedEvents: []string{
+					"Warning KeyMismatch The certificate request has failed to complete and will be retried: Signed certificate does not match the next private key",
+				},
+			},
+			expectedErr: false,
+		},
 		"if certificate is in Issuing state, one CertificateRequest, but has failed, but the private key does not exist, do nothing": {
 			certificate: exampleBundle.Certificate,
 			builder: &testpkg.Builder{
@@ -302,15 +479,320 @@ func TestIssuingController(t *testing.T) {
 			},
 			expectedErr: false,
 		},
-		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the private key stored in the Secret cannot be parsed, do nothing": {
-			certificate: exampleBundle.Certificate,
+		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the private key stored in the Secret cannot be parsed, do nothing": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: []byte("bad key"),
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{},
+				ExpectedEvents:  []string{},
+			},
+			expectedErr: false,
+		},
+		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the private key stored in the Secret does not match that creating the CSR, do nothing": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundleAlt.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{},
+				ExpectedEvents:  []string{},
+			},
+			expectedErr: false,
+		},
+		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the CertificateRequest contains a violation, do nothing": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+						gen.SetCertificateRequestKeyUsages(cmapi.UsageCRLSign),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{},
+				ExpectedEvents:  []string{},
+			},
+			expectedErr: false,
+		},
+
+		"if certificate is in Issuing state, one CertificateRequest issued by the staging issuer, and is ready, do not store the signed certificate": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+							cmapi.CertificateRequestStagingAnnotationKey:  "true",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "staging-issuer", Kind: "Issuer", Group: "foo.io"}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{},
+				ExpectedEvents:  []string{},
+			},
+			expectedErr: false,
+		},
+
+		"if certificate is in Issuing state, one CertificateRequests, and is ready, store the signed certificate, ca, and private key to a new secret, and log an event": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						exampleBundle.Certificate.Namespace,
+						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
+							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
+						),
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						exampleBundle.Certificate.Namespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: exampleBundle.Certificate.Namespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.CommonNameAnnotationKey:  "",
+									cmapi.AltNamesAnnotationKey:    "example.com",
+									cmapi.IPSANAnnotationKey:       "",
+									cmapi.URISANAnnotationKey:      "",
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertificateRequestReady.Status.Certificate,
+								corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Issuing The certificate has been successfully issued",
+				},
+			},
+			expectedErr: false,
+		},
+
+		"if certificate is in Issuing state, one CertificateRequests, and is ready, store the private key using the requested encoding": {
+			certificate: pkcs8Bundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(pkcs8IssuingCert),
+					gen.CertificateRequestFrom(pkcs8Bundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: pkcs8Bundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: pkcs1PrivateKeyBytes,
+						},
+					},
+				},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						pkcs8Bundle.Certificate.Namespace,
+						gen.CertificateFrom(pkcs8Bundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
+							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
+						),
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						pkcs8Bundle.Certificate.Namespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: pkcs8Bundle.Certificate.Namespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.CommonNameAnnotationKey:  "",
+									cmapi.AltNamesAnnotationKey:    "example.com",
+									cmapi.IPSANAnnotationKey:       "",
+									cmapi.URISANAnnotationKey:      "",
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       pkcs8Bundle.CertificateRequestReady.Status.Certificate,
+								corev1.TLSPrivateKeyKey: pkcs8Bundle.PrivateKeyBytes,
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Issuing The certificate has been successfully issued",
+				},
+			},
+			expectedErr: false,
+		},
+
+		"if certificate uses a supplied CSR and is in Issuing state, one CertificateRequests, and is ready, store only the signed certificate and ca to a new secret": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert,
+						gen.SetCertificateDNSNames(),
+						gen.SetCertificateNextPrivateKeySecretName(""),
+						gen.SetCertificateCSRSecretRef("csr", ""),
+					),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
+						}),
+					)},
+				KubeObjects: []runtime.Object{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						exampleBundle.Certificate.Namespace,
+						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.SetCertificateDNSNames(),
+							gen.SetCertificateNextPrivateKeySecretName(""),
+							gen.SetCertificateCSRSecretRef("csr", ""),
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
+							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
+						),
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						exampleBundle.Certificate.Namespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: exampleBundle.Certificate.Namespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.CommonNameAnnotationKey:  "",
+									cmapi.AltNamesAnnotationKey:    "example.com",
+									cmapi.IPSANAnnotationKey:       "",
+									cmapi.URISANAnnotationKey:      "",
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey: exampleBundle.CertificateRequestReady.Status.Certificate,
+							},
+							Type: corev1.SecretTypeOpaque,
+						},
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Issuing The certificate has been successfully issued",
+				},
+			},
+			expectedErr: false,
+		},
+
+		"if certificate is in Issuing state, one CertificateRequests issued by a fallback issuer, and is ready, store the signed certificate and record the active issuer": {
+			certificate: gen.CertificateFrom(exampleBundle.Certificate,
+				gen.SetCertificateIssuerRefs(fallbackIssuerRef),
+			),
 			builder: &testpkg.Builder{
 				CertManagerObjects: []runtime.Object{
-					gen.CertificateFrom(issuingCert),
+					gen.CertificateFrom(issuingCert,
+						gen.SetCertificateIssuerRefs(fallbackIssuerRef),
+					),
 					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
 						gen.AddCertificateRequestAnnotations(map[string]string{
 							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
 						}),
+						gen.SetCertificateRequestIssuer(fallbackIssuerRef),
 					)},
 				KubeObjects: []runtime.Object{
 					&corev1.Secret{
@@ -319,16 +801,57 @@ func TestIssuingController(t *testing.T) {
 							Namespace: exampleBundle.Certificate.Namespace,
 						},
 						Data: map[string][]byte{
-							corev1.TLSPrivateKeyKey: []byte("bad key"),
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
 						},
 					},
 				},
-				ExpectedActions: []testpkg.Action{},
-				ExpectedEvents:  []string{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						exampleBundle.Certificate.Namespace,
+						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
+							gen.SetCertificateIssuerRefs(fallbackIssuerRef),
+							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
+							gen.SetCertificateActiveIssuerRef(fallbackIssuerRef),
+						),
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						exampleBundle.Certificate.Namespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: exampleBundle.Certificate.Namespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerKindAnnotationKey:  "ClusterIssuer",
+									cmapi.IssuerNameAnnotationKey:  "fallback-issuer",
+									cmapi.IssuerGroupAnnotationKey: "",
+									cmapi.CommonNameAnnotationKey:  "",
+									cmapi.AltNamesAnnotationKey:    "example.com",
+									cmapi.IPSANAnnotationKey:       "",
+									cmapi.URISANAnnotationKey:      "",
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertificateRequestReady.Status.Certificate,
+								corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Issuing The certificate has been successfully issued",
+				},
 			},
 			expectedErr: false,
 		},
-		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the private key stored in the Secret does not match that creating the CSR, do nothing": {
+
+		"if certificate is in Issuing state, one CertificateRequests, and is ready, store the signed certificate, ca, and private key to a new secret, and record the issuance latency": {
 			certificate: exampleBundle.Certificate,
 			builder: &testpkg.Builder{
 				CertManagerObjects: []runtime.Object{
@@ -337,6 +860,13 @@ func TestIssuingController(t *testing.T) {
 						gen.AddCertificateRequestAnnotations(map[string]string{
 							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
 						}),
+						gen.SetCertificateRequestCreationTimestamp(metav1.NewTime(fixedClockStart.Add(-5*time.Minute))),
+						gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:               cmapi.CertificateRequestConditionReady,
+							Status:             cmmeta.ConditionTrue,
+							Reason:             cmapi.CertificateRequestReasonIssued,
+							LastTransitionTime: &metaFixedClockStart,
+						}),
 					)},
 				KubeObjects: []runtime.Object{
 					&corev1.Secret{
@@ -345,16 +875,56 @@ func TestIssuingController(t *testing.T) {
 							Namespace: exampleBundle.Certificate.Namespace,
 						},
 						Data: map[string][]byte{
-							corev1.TLSPrivateKeyKey: exampleBundleAlt.PrivateKeyBytes,
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
 						},
 					},
 				},
-				ExpectedActions: []testpkg.Action{},
-				ExpectedEvents:  []string{},
+				ExpectedActions: []testpkg.Action{
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						exampleBundle.Certificate.Namespace,
+						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
+							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
+							gen.SetCertificateIssuanceLatency(metav1.Duration{Duration: 5 * time.Minute}),
+						),
+					)),
+					testpkg.NewAction(coretesting.NewCreateAction(
+						corev1.SchemeGroupVersion.WithResource("secrets"),
+						exampleBundle.Certificate.Namespace,
+						&corev1.Secret{
+							ObjectMeta: metav1.ObjectMeta{
+								Namespace: exampleBundle.Certificate.Namespace,
+								Name:      "output",
+								Annotations: map[string]string{
+									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerKindAnnotationKey:  "Issuer",
+									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
+									cmapi.CommonNameAnnotationKey:  "",
+									cmapi.AltNamesAnnotationKey:    "example.com",
+									cmapi.IPSANAnnotationKey:       "",
+									cmapi.URISANAnnotationKey:      "",
+								},
+							},
+							Data: map[string][]byte{
+								corev1.TLSCertKey:       exampleBundle.CertificateRequestReady.Status.Certificate,
+								corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+							},
+							Type: corev1.SecretTypeTLS,
+						},
+					)),
+				},
+				ExpectedEvents: []string{
+					"Normal Issuing The certificate has been successfully issued",
+				},
 			},
 			expectedErr: false,
 		},
-		"if certificate is in Issuing state, one CertificateRequest, and is ready, but the CertificateRequest contains a violation, do nothing": {
+
+		"if certificate is in Issuing state, one CertificateRequests, and is ready, store the signed certificate, ca, and private key to an existing secret, and log an event": {
 			certificate: exampleBundle.Certificate,
 			builder: &testpkg.Builder{
 				CertManagerObjects: []runtime.Object{
@@ -363,7 +933,6 @@ func TestIssuingController(t *testing.T) {
 						gen.AddCertificateRequestAnnotations(map[string]string{
 							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
 						}),
-						gen.SetCertificateRequestKeyUsages(cmapi.UsageCRLSign),
 					)},
 				KubeObjects: []runtime.Object{
 					&corev1.Secret{
@@ -375,32 +944,15 @@ func TestIssuingController(t *testing.T) {
 							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
 						},
 					},
-				},
-				ExpectedActions: []testpkg.Action{},
-				ExpectedEvents:  []string{},
-			},
-			expectedErr: false,
-		},
-
-		"if certificate is in Issuing state, one CertificateRequests, and is ready, store the signed certificate, ca, and private key to a new secret, and log an event": {
-			certificate: exampleBundle.Certificate,
-			builder: &testpkg.Builder{
-				CertManagerObjects: []runtime.Object{
-					gen.CertificateFrom(issuingCert),
-					gen.CertificateRequestFrom(exampleBundle.CertificateRequestReady,
-						gen.AddCertificateRequestAnnotations(map[string]string{
-							cmapi.CertificateRequestRevisionAnnotationKey: "2", // Current Certificate revision=1
-						}),
-					)},
-				KubeObjects: []runtime.Object{
 					&corev1.Secret{
 						ObjectMeta: metav1.ObjectMeta{
-							Name:      nextPrivateKeySecretName,
 							Namespace: exampleBundle.Certificate.Namespace,
+							Name:      "output",
+							Annotations: map[string]string{
+								"my-custom": "annotation",
+							},
 						},
-						Data: map[string][]byte{
-							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
-						},
+						Type: corev1.SecretTypeTLS,
 					},
 				},
 				ExpectedActions: []testpkg.Action{
@@ -409,10 +961,12 @@ func TestIssuingController(t *testing.T) {
 						"status",
 						exampleBundle.Certificate.Namespace,
 						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
 							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
 						),
 					)),
-					testpkg.NewAction(coretesting.NewCreateAction(
+					testpkg.NewAction(coretesting.NewUpdateAction(
 						corev1.SchemeGroupVersion.WithResource("secrets"),
 						exampleBundle.Certificate.Namespace,
 						&corev1.Secret{
@@ -420,10 +974,11 @@ func TestIssuingController(t *testing.T) {
 								Namespace: exampleBundle.Certificate.Namespace,
 								Name:      "output",
 								Annotations: map[string]string{
+									"my-custom":                    "annotation",
 									cmapi.CertificateNameKey:       "test",
+									cmapi.IssuerGroupAnnotationKey: "foo.io",
 									cmapi.IssuerKindAnnotationKey:  "Issuer",
 									cmapi.IssuerNameAnnotationKey:  "ca-issuer",
-									cmapi.IssuerGroupAnnotationKey: "foo.io",
 									cmapi.CommonNameAnnotationKey:  "",
 									cmapi.AltNamesAnnotationKey:    "example.com",
 									cmapi.IPSANAnnotationKey:       "",
@@ -444,9 +999,9 @@ func TestIssuingController(t *testing.T) {
 			},
 			expectedErr: false,
 		},
-
-		"if certificate is in Issuing state, one CertificateRequests, and is ready, store the signed certificate, ca, and private key to an existing secret, and log an event": {
-			certificate: exampleBundle.Certificate,
+		"if certificate is in Issuing state, one CertificateRequests, and is ready, and secret events are enabled, log the event on both the Certificate and Secret": {
+			certificate:        exampleBundle.Certificate,
+			enableSecretEvents: true,
 			builder: &testpkg.Builder{
 				CertManagerObjects: []runtime.Object{
 					gen.CertificateFrom(issuingCert),
@@ -482,7 +1037,9 @@ func TestIssuingController(t *testing.T) {
 						"status",
 						exampleBundle.Certificate.Namespace,
 						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
 							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
 						),
 					)),
 					testpkg.NewAction(coretesting.NewUpdateAction(
@@ -514,9 +1071,11 @@ func TestIssuingController(t *testing.T) {
 				},
 				ExpectedEvents: []string{
 					"Normal Issuing The certificate has been successfully issued",
+					"Normal Issuing The certificate has been successfully issued",
 				},
 			},
-			expectedErr: false,
+			expectedEventObjects: []string{"Certificate/test", "Secret/output"},
+			expectedErr:          false,
 		},
 
 		"if certificate is in Issuing state with temp annotation, one CertificateRequest Pending, no target Secret, create target secret with temporary certificate": {
@@ -842,10 +1401,12 @@ func TestIssuingController(t *testing.T) {
 						"status",
 						exampleBundle.Certificate.Namespace,
 						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
 							gen.AddCertificateAnnotations(map[string]string{
 								cmapi.IssueTemporaryCertificateAnnotation: "true",
 							}),
 							gen.SetCertificateRevision(2),
+							gen.UnsetCertificateNextPrivateKeySecretName(),
 						),
 					)),
 					testpkg.NewAction(coretesting.NewCreateAction(
@@ -917,6 +1478,7 @@ func TestIssuingController(t *testing.T) {
 						"status",
 						exampleBundle.Certificate.Namespace,
 						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
 							gen.AddCertificateAnnotations(map[string]string{
 								cmapi.IssueTemporaryCertificateAnnotation: "true",
 							}),
@@ -971,6 +1533,7 @@ func TestIssuingController(t *testing.T) {
 						"status",
 						exampleBundle.Certificate.Namespace,
 						gen.CertificateFrom(exampleBundle.Certificate,
+							gen.AddCertificateLastReconciledBy(ControllerName, metaFixedClockStart),
 							gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
 								Type:               cmapi.CertificateConditionIssuing,
 								Status:             cmmeta.ConditionFalse,
@@ -989,6 +1552,37 @@ func TestIssuingController(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		"if certificate is in Issuing state, one CertificateRequest, but has been denied for an older generation of the certificate, do nothing": {
+			certificate: exampleBundle.Certificate,
+			builder: &testpkg.Builder{
+				CertManagerObjects: []runtime.Object{
+					gen.CertificateFrom(issuingCert),
+					gen.CertificateRequestFrom(exampleBundle.CertificateRequest,
+						gen.AddCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestRevisionAnnotationKey:              "2", // Current Certificate revision=1
+							cmapi.CertificateRequestCertificateGenerationAnnotationKey: "2", // Current Certificate generation=3
+						}),
+						gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+							Type:    cmapi.CertificateRequestConditionDenied,
+							Status:  cmmeta.ConditionTrue,
+							Reason:  "DeniedReason",
+							Message: "The certificate request has been denied",
+						}),
+					)},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      nextPrivateKeySecretName,
+							Namespace: exampleBundle.Certificate.Namespace,
+						},
+						Data: map[string][]byte{
+							corev1.TLSPrivateKeyKey: exampleBundle.PrivateKeyBytes,
+						},
+					},
+				},
+			},
+			expectedErr: false,
+		},
 	}
 
 	for name, test := range tests {
@@ -997,6 +1591,7 @@ func TestIssuingController(t *testing.T) {
 			test.builder.Clock = fixedClock
 			test.builder.T = t
 			test.builder.Init()
+			test.builder.CertificateOptions.EnableSecretEvents = test.enableSecretEvents
 			defer test.builder.Stop()
 
 			// Instantiate/setup the controller
@@ -1021,6 +1616,343 @@ func TestIssuingController(t *testing.T) {
 				t.Errorf("expected to get an error but did not get one")
 			}
 			test.builder.CheckAndFinish(err)
+
+			if test.expectedEventObjects != nil {
+				var eventObjects []string
+				for _, obj := range test.builder.Recorder.(*testpkg.FakeRecorder).Objects {
+					eventObjects = append(eventObjects, fmt.Sprintf("%s/%s", reflect.TypeOf(obj).Elem().Name(), obj.(metav1.Object).GetName()))
+				}
+				if !reflect.DeepEqual(eventObjects, test.expectedEventObjects) {
+					t.Errorf("unexpected event objects, exp=%v got=%v", test.expectedEventObjects, eventObjects)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestAnnotationsWithPrefix(t *testing.T) {
+	req := gen.CertificateRequest("test",
+		gen.SetCertificateRequestAnnotations(map[string]string{
+			"example.com/pipeline-id":                     "1234",
+			"example.com/commit":                          "abcdef",
+			"other.example.com/pipeline-id":               "5678",
+			cmapi.CertificateRequestRevisionAnnotationKey: "2",
+		}),
+	)
+
+	tests := map[string]struct {
+		prefix string
+		exp    map[string]string
+	}{
+		"if no prefix is configured, no annotations should be copied": {
+			prefix: "",
+			exp:    nil,
+		},
+		"if a prefix is configured, only annotations with that prefix should be copied": {
+			prefix: "example.com/",
+			exp: map[string]string{
+				"example.com/pipeline-id": "1234",
+				"example.com/commit":      "abcdef",
+			},
+		},
+		"if no annotations match the prefix, no annotations should be copied": {
+			prefix: "unmatched.example.com/",
+			exp:    nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := requestAnnotationsWithPrefix(req, test.prefix)
+			if !reflect.DeepEqual(got, test.exp) {
+				t.Errorf("unexpected annotations, exp=%v got=%v", test.exp, got)
+			}
 		})
 	}
 }
+
+func TestIssuingControllerTracing(t *testing.T) {
+	fixedClock.SetTime(fixedClockStart)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	baseCert := gen.Certificate("test",
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "Issuer", Group: "foo.io"}),
+		gen.SetCertificateSecretName("output"),
+		gen.SetCertificateDNSNames("example.com"),
+		gen.SetCertificateRevision(1),
+		gen.SetCertificateNextPrivateKeySecretName("next-private-key"),
+	)
+	bundle := internaltest.MustCreateCryptoBundle(t, baseCert.DeepCopy(), fixedClock)
+
+	// The CertificateRequest carries the trace context of the span that
+	// created it.
+	requestCtx, requestSpan := tracing.Tracer(provider).Start(context.Background(), "certificates-request-manager")
+	requestSpan.End()
+	req := gen.CertificateRequestFrom(bundle.CertificateRequestReady,
+		gen.AddCertificateRequestAnnotations(map[string]string{
+			cmapi.CertificateRequestRevisionAnnotationKey: "2",
+		}),
+	)
+	tracing.AnnotateObject(requestCtx, req)
+
+	builder := &testpkg.Builder{
+		T:     t,
+		Clock: fixedClock,
+		CertManagerObjects: []runtime.Object{
+			gen.CertificateFrom(baseCert,
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+					Type:   cmapi.CertificateConditionIssuing,
+					Status: cmmeta.ConditionTrue,
+				}),
+			),
+			req,
+		},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: baseCert.Namespace, Name: "next-private-key"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.PrivateKeyBytes},
+			},
+		},
+		ExpectedActions: []testpkg.Action{
+			testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+				cmapi.SchemeGroupVersion.WithResource("certificates"),
+				"status",
+				baseCert.Namespace,
+				gen.CertificateFrom(bundle.Certificate,
+					gen.AddCertificateLastReconciledBy(ControllerName, metav1.NewTime(fixedClockStart)),
+					gen.SetCertificateRevision(2),
+					gen.UnsetCertificateNextPrivateKeySecretName(),
+				),
+			)),
+			testpkg.NewAction(coretesting.NewCreateAction(
+				corev1.SchemeGroupVersion.WithResource("secrets"),
+				baseCert.Namespace,
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: baseCert.Namespace,
+						Name:      "output",
+						Annotations: map[string]string{
+							cmapi.CertificateNameKey:       "test",
+							cmapi.IssuerKindAnnotationKey:  "Issuer",
+							cmapi.IssuerNameAnnotationKey:  "ca-issuer",
+							cmapi.IssuerGroupAnnotationKey: "foo.io",
+							cmapi.CommonNameAnnotationKey:  "",
+							cmapi.AltNamesAnnotationKey:    "example.com",
+							cmapi.IPSANAnnotationKey:       "",
+							cmapi.URISANAnnotationKey:      "",
+						},
+					},
+					Data: map[string][]byte{
+						corev1.TLSCertKey:       bundle.CertificateRequestReady.Status.Certificate,
+						corev1.TLSPrivateKeyKey: bundle.PrivateKeyBytes,
+					},
+					Type: corev1.SecretTypeTLS,
+				},
+			)),
+		},
+		ExpectedEvents: []string{"Normal Issuing The certificate has been successfully issued"},
+	}
+	builder.Init()
+	builder.Context.TracerProvider = provider
+	defer builder.Stop()
+
+	w := controllerWrapper{}
+	w.Register(builder.Context)
+	builder.Start()
+
+	err := w.controller.ProcessItem(context.Background(), baseCert.Namespace+"/"+baseCert.Name)
+	if err != nil {
+		t.Errorf("expected to not get an error, but got: %v", err)
+	}
+	builder.CheckAndFinish(err)
+
+	// The issuing span is a child of the span that created the
+	// CertificateRequest, and the write of the Secret is a child of the
+	// issuing span.
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	secretSpan, issuingSpan := spans[1], spans[2]
+	if issuingSpan.Name() != ControllerName {
+		t.Errorf("unexpected span name, exp=%s got=%s", ControllerName, issuingSpan.Name())
+	}
+	if issuingSpan.Parent().SpanID() != requestSpan.SpanContext().SpanID() {
+		t.Errorf("unexpected parent of the issuing span, exp=%s got=%s", requestSpan.SpanContext().SpanID(), issuingSpan.Parent().SpanID())
+	}
+	if secretSpan.Name() != "WriteSecret" {
+		t.Errorf("unexpected span name, exp=WriteSecret got=%s", secretSpan.Name())
+	}
+	if secretSpan.Parent().SpanID() != issuingSpan.SpanContext().SpanID() {
+		t.Errorf("unexpected parent of the Secret write span, exp=%s got=%s", issuingSpan.SpanContext().SpanID(), secretSpan.Parent().SpanID())
+	}
+}
+
+func TestIssuingControllerSecretRefresh(t *testing.T) {
+	fixedClock.SetTime(fixedClockStart)
+
+	baseCert := gen.Certificate("test",
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "Issuer", Group: "foo.io"}),
+		gen.SetCertificateSecretName("output"),
+		gen.SetCertificateDNSNames("example.com"),
+		gen.SetCertificateRevision(1),
+		gen.SetCertificateKeystores(&cmapi.CertificateKeystores{
+			PKCS12: &cmapi.PKCS12Keystore{
+				Create: true,
+				PasswordSecretRef: cmmeta.SecretKeySelector{
+					LocalObjectReference: cmmeta.LocalObjectReference{Name: "keystore-password"},
+					Key:                  "password",
+				},
+			},
+		}),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+			Type:   cmapi.CertificateConditionReady,
+			Status: cmmeta.ConditionTrue,
+		}),
+	)
+	bundle := internaltest.MustCreateCryptoBundle(t, baseCert.DeepCopy(), fixedClock)
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		CertManagerObjects: []runtime.Object{baseCert},
+		KubeObjects: []runtime.Object{
+			// the password has been changed since the keystore was created
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: baseCert.Namespace, Name: "keystore-password"},
+				Data:       map[string][]byte{"password": []byte("new-password")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: baseCert.Namespace, Name: "output"},
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       bundle.CertBytes,
+					corev1.TLSPrivateKeyKey: bundle.PrivateKeyBytes,
+					"keystore.p12":          []byte("keystore encoded with the old password"),
+				},
+				Type: corev1.SecretTypeTLS,
+			},
+		},
+	}
+	builder.Init()
+	builder.Context.CertificateOptions.SecretRefreshInterval = time.Hour
+	defer builder.Stop()
+
+	w := controllerWrapper{}
+	w.Register(builder.Context)
+	builder.Start()
+
+	key := baseCert.Namespace + "/" + baseCert.Name
+	updatedSecrets := func() []*corev1.Secret {
+		var secrets []*corev1.Secret
+		for _, a := range builder.FakeKubeClient().Actions() {
+			if a.GetVerb() != "update" {
+				continue
+			}
+			if secret := a.(coretesting.UpdateAction).GetObject().(*corev1.Secret); secret.Name == "output" {
+				secrets = append(secrets, secret)
+			}
+		}
+		return secrets
+	}
+
+	// The Secret is not refreshed when the Certificate is first observed, nor
+	// before the refresh interval has elapsed.
+	for _, step := range []time.Duration{0, time.Minute * 30} {
+		fixedClock.Step(step)
+		if err := w.controller.ProcessItem(context.Background(), key); err != nil {
+			t.Fatalf("expected to not get an error, but got: %v", err)
+		}
+		if secrets := updatedSecrets(); len(secrets) != 0 {
+			t.Fatalf("expected the Secret to not be updated after %s, got %d updates", fixedClock.Since(fixedClockStart), len(secrets))
+		}
+	}
+
+	fixedClock.Step(time.Minute * 30)
+	if err := w.controller.ProcessItem(context.Background(), key); err != nil {
+		t.Fatalf("expected to not get an error, but got: %v", err)
+	}
+	secrets := updatedSecrets()
+	if len(secrets) != 1 {
+		t.Fatalf("expected the Secret to be updated once the refresh interval has elapsed, got %d updates", len(secrets))
+	}
+	if _, _, err := pkcs12.Decode(secrets[0].Data["keystore.p12"], "new-password"); err != nil {
+		t.Errorf("expected the keystore to be encoded with the new password, but got: %v", err)
+	}
+	if !reflect.DeepEqual(secrets[0].Data[corev1.TLSCertKey], bundle.CertBytes) {
+		t.Errorf("expected the certificate in the Secret to be unchanged")
+	}
+
+	// The Secret is refreshed before the refresh interval has elapsed if the
+	// password Secret is changed.
+	if _, err := builder.Client.CoreV1().Secrets(baseCert.Namespace).Update(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: baseCert.Namespace, Name: "keystore-password", ResourceVersion: "2"},
+		Data:       map[string][]byte{"password": []byte("newer-password")},
+	}, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		secret, err := builder.KubeSharedInformerFactory.Core().V1().Secrets().Lister().Secrets(baseCert.Namespace).Get("keystore-password")
+		return err == nil && secret.ResourceVersion == "2", nil
+	}); err != nil {
+		t.Fatalf("timed out waiting for the password Secret to be observed: %v", err)
+	}
+	fixedClock.Step(time.Minute)
+	if err := w.controller.ProcessItem(context.Background(), key); err != nil {
+		t.Fatalf("expected to not get an error, but got: %v", err)
+	}
+	secrets = updatedSecrets()
+	if len(secrets) != 2 {
+		t.Fatalf("expected the Secret to be updated once the password Secret has changed, got %d updates", len(secrets))
+	}
+	if _, _, err := pkcs12.Decode(secrets[1].Data["keystore.p12"], "newer-password"); err != nil {
+		t.Errorf("expected the keystore to be encoded with the changed password, but got: %v", err)
+	}
+}
+
+func TestFailIssueCertificateNotifiesWebhook(t *testing.T) {
+	received := make(chan map[string]string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifier := failurewebhook.New(logf.Log, srv.URL, metrics.New(logf.Log))
+	notifier.Start(ctx)
+
+	crt := gen.Certificate("test", gen.SetCertificateNamespace("testns"))
+	c := &controller{
+		client:          cmfake.NewSimpleClientset(crt),
+		recorder:        record.NewFakeRecorder(10),
+		clock:           fixedClock,
+		failureNotifier: notifier,
+	}
+
+	err := c.failIssueCertificate(ctx, logf.Log, crt, gen.CertificateRequest("test-1"), &cmapi.CertificateRequestCondition{
+		Reason:  "Failed",
+		Message: "boom",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]string{
+		"name":      "test",
+		"namespace": "testns",
+		"reason":    "Failed",
+		"message":   "The certificate request has failed to complete and will be retried: boom",
+	}
+	select {
+	case got := <-received:
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("unexpected payload received, exp=%+v got=%+v", exp, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the issuance failure notification")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issuing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+// manifestSchemaVersion is the version of the issuance manifest schema. It
+// must be bumped whenever a field is removed or changes meaning.
+const manifestSchemaVersion = "v1"
+
+// issuanceManifest describes an issued certificate, so that consumers of the
+// Secret can verify that the certificate was stored by cert-manager.
+type issuanceManifest struct {
+	SchemaVersion string         `json:"schemaVersion"`
+	SerialNumber  string         `json:"serialNumber"`
+	Issuer        string         `json:"issuer"`
+	IssuerRef     auditIssuerRef `json:"issuerRef"`
+
+	CommonName     string   `json:"commonName,omitempty"`
+	DNSNames       []string `json:"dnsNames,omitempty"`
+	IPAddresses    []string `json:"ipAddresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	EmailAddresses []string `json:"emailAddresses,omitempty"`
+
+	// CertificateSHA256 is the hex encoded SHA-256 digest of the DER encoded
+	// leaf certificate.
+	CertificateSHA256 string `json:"certificateSHA256"`
+}
+
+// manifestSigner signs the issuance manifests written alongside issued
+// certificates. The signing key is read from disk each time a manifest is
+// signed, so that a rotated key is used without restarting the controller.
+type manifestSigner struct {
+	path string
+
+	lock   sync.Mutex
+	keyPEM []byte
+	signer crypto.Signer
+}
+
+// newManifestSigner returns a manifestSigner using the PEM encoded RSA or
+// ECDSA private key stored in the file at the given path. The key is loaded
+// once up front so that an invalid key is reported at start up.
+func newManifestSigner(path string) (*manifestSigner, error) {
+	m := &manifestSigner{path: path}
+	if _, err := m.loadSigner(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// loadSigner reads the signing key from disk, only decoding it again if the
+// contents of the file have changed since it was last read.
+func (m *manifestSigner) loadSigner() (crypto.Signer, error) {
+	keyPEM, err := os.ReadFile(m.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issuance manifest signing key: %w", err)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.signer != nil && bytes.Equal(keyPEM, m.keyPEM) {
+		return m.signer, nil
+	}
+	signer, err := utilpki.DecodePrivateKeyBytes(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode issuance manifest signing key: %w", err)
+	}
+	m.keyPEM, m.signer = keyPEM, signer
+	return signer, nil
+}
+
+// sign returns the JSON encoded manifest of the certificate issued for the
+// given CertificateRequest, and a signature over the SHA-256 digest of the
+// manifest. RSA keys produce PKCS#1 v1.5 signatures and ECDSA keys ASN.1
+// encoded signatures, so the manifest can be verified with e.g.
+// `openssl dgst -sha256 -verify`.
+func (m *manifestSigner) sign(req *cmapi.CertificateRequest) (manifest, signature []byte, err error) {
+	signer, err := m.loadSigner()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := utilpki.DecodeX509CertificateBytes(req.Status.Certificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode issued certificate: %w", err)
+	}
+
+	certDigest := sha256.Sum256(cert.Raw)
+	manifest, err = json.Marshal(&issuanceManifest{
+		SchemaVersion: manifestSchemaVersion,
+		SerialNumber:  cert.SerialNumber.Text(16),
+		Issuer:        cert.Issuer.String(),
+		IssuerRef: auditIssuerRef{
+			Name:  req.Spec.IssuerRef.Name,
+			Kind:  req.Spec.IssuerRef.Kind,
+			Group: req.Spec.IssuerRef.Group,
+		},
+		CommonName:        cert.Subject.CommonName,
+		DNSNames:          cert.DNSNames,
+		IPAddresses:       utilpki.IPAddressesToString(cert.IPAddresses),
+		URIs:              utilpki.URLsToString(cert.URIs),
+		EmailAddresses:    cert.EmailAddresses,
+		CertificateSHA256: hex.EncodeToString(certDigest[:]),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	digest := sha256.Sum256(manifest)
+	signature, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign issuance manifest: %w", err)
+	}
+	return manifest, signature, nil
+}
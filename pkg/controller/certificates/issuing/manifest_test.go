@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package issuing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	internaltest "github.com/jetstack/cert-manager/pkg/controller/certificates/internal/test"
+	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+func TestManifestSigner(t *testing.T) {
+	crt := gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateCommonName("example.com"),
+		gen.SetCertificateDNSNames("example.com", "www.example.com"),
+		gen.SetCertificateIPs("10.0.0.1"),
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "ClusterIssuer", Group: "cert-manager.io"}),
+	)
+	bundle := internaltest.MustCreateCryptoBundle(t, crt, fixedClock)
+	certDigest := sha256.Sum256(bundle.Cert.Raw)
+
+	expManifest := map[string]interface{}{
+		"schemaVersion":     "v1",
+		"serialNumber":      bundle.Cert.SerialNumber.Text(16),
+		"issuer":            bundle.Cert.Issuer.String(),
+		"issuerRef":         map[string]interface{}{"name": "ca-issuer", "kind": "ClusterIssuer", "group": "cert-manager.io"},
+		"commonName":        "example.com",
+		"dnsNames":          []interface{}{"example.com", "www.example.com"},
+		"ipAddresses":       []interface{}{"10.0.0.1"},
+		"certificateSHA256": hex.EncodeToString(certDigest[:]),
+	}
+
+	rsaKey, err := utilpki.GenerateRSAPrivateKey(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := utilpki.GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		key    crypto.Signer
+		verify func(digest, signature []byte) bool
+	}{
+		"an RSA key should produce a PKCS#1 v1.5 signature": {
+			key: rsaKey,
+			verify: func(digest, signature []byte) bool {
+				return rsa.VerifyPKCS1v15(&rsaKey.PublicKey, crypto.SHA256, digest, signature) == nil
+			},
+		},
+		"an ECDSA key should produce an ASN.1 signature": {
+			key: ecKey,
+			verify: func(digest, signature []byte) bool {
+				return ecdsa.VerifyASN1(&ecKey.PublicKey, digest, signature)
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			keyPEM, err := utilpki.EncodePKCS8PrivateKey(test.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "key.pem")
+			if err := os.WriteFile(path, keyPEM, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			signer, err := newManifestSigner(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			manifest, signature, err := signer.sign(bundle.CertificateRequestReady)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(manifest, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, expManifest) {
+				t.Errorf("unexpected manifest, exp=%v got=%v", expManifest, got)
+			}
+
+			digest := sha256.Sum256(manifest)
+			if !test.verify(digest[:], signature) {
+				t.Error("signature does not verify")
+			}
+
+			// the signature must not verify if the manifest is modified
+			tampered := sha256.Sum256(append(manifest, ' '))
+			if test.verify(tampered[:], signature) {
+				t.Error("signature verifies for a modified manifest")
+			}
+		})
+	}
+}
+
+func TestNewManifestSignerInvalidKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newManifestSigner(path); err == nil {
+		t.Error("expected an error for an invalid signing key")
+	}
+	if _, err := newManifestSigner(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("expected an error for a missing signing key")
+	}
+}
+
+func TestManifestSignerReloadsKey(t *testing.T) {
+	crt := gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateCommonName("example.com"),
+	)
+	bundle := internaltest.MustCreateCryptoBundle(t, crt, fixedClock)
+
+	writeKey := func(path string) *ecdsa.PrivateKey {
+		key, err := utilpki.GenerateECPrivateKey(256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyPEM, err := utilpki.EncodePKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, keyPEM, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	writeKey(path)
+	signer, err := newManifestSigner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotate the signing key on disk
+	rotatedKey := writeKey(path)
+	manifest, signature, err := signer.sign(bundle.CertificateRequestReady)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(manifest)
+	if !ecdsa.VerifyASN1(&rotatedKey.PublicKey, digest[:], signature) {
+		t.Error("expected the manifest to be signed with the rotated key")
+	}
+
+	// signing fails rather than using a stale key if the key is removed
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := signer.sign(bundle.CertificateRequestReady); err == nil {
+		t.Error("expected an error once the signing key has been removed")
+	}
+}
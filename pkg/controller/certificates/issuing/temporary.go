@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates/internal/secretsmanager"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates/trigger/policies"
@@ -57,7 +58,7 @@ func (c *controller) ensureTemporaryCertificate(ctx context.Context, crt *cmapi.
 	if err != nil && !apierrors.IsNotFound(err) {
 		return false, err
 	}
-	input := policies.Input{Secret: secret}
+	input := policies.Input{Certificate: crt, Secret: apiutil.SecretWithDefaultKeyNames(crt, secret)}
 	// If the target Secret exists with a signed certificate and matching private
 	// key, do not issue.
 	if _, _, invalid := temporaryCertificatePolicyChain.Evaluate(input); !invalid {
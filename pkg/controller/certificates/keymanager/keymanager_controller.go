@@ -19,6 +19,7 @@ package keymanager
 import (
 	"context"
 	"crypto"
+	"errors"
 	"fmt"
 	"time"
 
@@ -28,12 +29,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
@@ -59,11 +62,13 @@ var (
 )
 
 type controller struct {
-	certificateLister cmlisters.CertificateLister
-	secretLister      corelisters.SecretLister
-	client            cmclient.Interface
-	coreClient        kubernetes.Interface
-	recorder          record.EventRecorder
+	certificateLister        cmlisters.CertificateLister
+	certificateRequestLister cmlisters.CertificateRequestLister
+	secretLister             corelisters.SecretLister
+	client                   cmclient.Interface
+	coreClient               kubernetes.Interface
+	recorder                 record.EventRecorder
+	clock                    clock.Clock
 }
 
 func NewController(
@@ -73,15 +78,25 @@ func NewController(
 	factory informers.SharedInformerFactory,
 	cmFactory cminformers.SharedInformerFactory,
 	recorder record.EventRecorder,
+	clock clock.Clock,
 ) (*controller, workqueue.RateLimitingInterface, []cache.InformerSynced) {
 	// create a queue used to queue up items to be processed
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Second*1, time.Second*30), ControllerName)
 
 	// obtain references to all the informers used by this controller
 	certificateInformer := cmFactory.Certmanager().V1().Certificates()
+	certificateRequestInformer := cmFactory.Certmanager().V1().CertificateRequests()
 	secretsInformer := factory.Core().V1().Secrets()
 
 	certificateInformer.Informer().AddEventHandler(&controllerpkg.QueuingEventHandler{Queue: queue})
+	certificateRequestInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		// Trigger reconciles on changes to any 'owned' CertificateRequest
+		// resources, so that retained Secrets are cleaned up once they are no
+		// longer in use
+		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
+			predicate.ResourceOwnerOf,
+		),
+	})
 
 	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
 		// Trigger reconciles on changes to any 'owned' secret resources
@@ -101,14 +116,17 @@ func NewController(
 	mustSync := []cache.InformerSynced{
 		secretsInformer.Informer().HasSynced,
 		certificateInformer.Informer().HasSynced,
+		certificateRequestInformer.Informer().HasSynced,
 	}
 
 	return &controller{
-		certificateLister: certificateInformer.Lister(),
-		secretLister:      secretsInformer.Lister(),
-		client:            client,
-		coreClient:        coreClient,
-		recorder:          recorder,
+		certificateLister:        certificateInformer.Lister(),
+		certificateRequestLister: certificateRequestInformer.Lister(),
+		secretLister:             secretsInformer.Lister(),
+		client:                   client,
+		coreClient:               coreClient,
+		recorder:                 recorder,
+		clock:                    clock,
 	}, queue, mustSync
 }
 
@@ -149,14 +167,43 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		return err
 	}
 
+	// The private key for a user supplied CSR is never seen by cert-manager,
+	// so there is no next private key to manage.
+	if crt.Spec.CSRSecretRef != nil {
+		log.V(logf.DebugLevel).Info("Cleaning up Secret resources and unsetting nextPrivateKeySecretName as the Certificate uses a supplied CSR")
+		if err := c.deleteSecretResources(ctx, secrets); err != nil {
+			return err
+		}
+		return c.setNextPrivateKeySecretName(ctx, crt, nil)
+	}
+
 	if !apiutil.CertificateHasCondition(crt, cmapi.CertificateCondition{
 		Type:   cmapi.CertificateConditionIssuing,
 		Status: cmmeta.ConditionTrue,
 	}) {
+		// Retain any Secret that is still referenced by an in-flight
+		// CertificateRequest, e.g. as it is used to sign the request by the
+		// SelfSigned issuer. It will be cleaned up once the request completes.
+		inUse, err := c.secretsReferencedByInFlightRequests(crt)
+		if err != nil {
+			return err
+		}
+		var unused []*corev1.Secret
+		for _, s := range secrets {
+			if inUse.Has(s.Name) {
+				logf.WithRelatedResource(log, s).V(logf.DebugLevel).Info("Retaining 'next private key' Secret resource as it is referenced by an in-flight CertificateRequest")
+				continue
+			}
+			unused = append(unused, s)
+		}
+
 		log.V(logf.DebugLevel).Info("Cleaning up Secret resources and unsetting nextPrivateKeySecretName as issuance is no longer in progress")
-		if err := c.deleteSecretResources(ctx, secrets); err != nil {
+		if err := c.deleteSecretResources(ctx, unused); err != nil {
 			return err
 		}
+		if len(unused) < len(secrets) {
+			return nil
+		}
 		return c.setNextPrivateKeySecretName(ctx, crt, nil)
 	}
 
@@ -172,6 +219,8 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		case cmapi.RotationPolicyAlways:
 			log.V(logf.DebugLevel).Info("Creating new nextPrivateKeySecretName Secret because no existing Secret found")
 			return c.createAndSetNextPrivateKey(ctx, crt)
+		case cmapi.RotationPolicyExternal:
+			return c.createNextPrivateKeyRotationPolicyExternal(ctx, crt)
 		default:
 			log.V(logf.WarnLevel).Info("Certificate with unknown certificate.spec.privateKey.rotationPolicy value", "rotation_policy", rotationPolicy)
 			return nil
@@ -223,30 +272,73 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	return nil
 }
 
-func (c *controller) createNextPrivateKeyRotationPolicyNever(ctx context.Context, crt *cmapi.Certificate) error {
-	log := logf.FromContext(ctx)
+var (
+	// errSecretNotFound is returned by existingSecretPrivateKey if the Secret
+	// named spec.secretName does not exist.
+	errSecretNotFound = errors.New("secret does not exist")
+
+	// errNoPrivateKey is returned by existingSecretPrivateKey if the Secret
+	// named spec.secretName does not contain a private key.
+	errNoPrivateKey = errors.New("secret does not contain a private key")
+)
+
+// unusablePrivateKeyError is returned by existingSecretPrivateKey if the
+// private key stored in the Secret named spec.secretName cannot be decoded,
+// or cannot be compared with the Certificate's spec.
+type unusablePrivateKeyError struct {
+	err error
+}
+
+func (e *unusablePrivateKeyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unusablePrivateKeyError) Unwrap() error {
+	return e.err
+}
+
+// existingSecretPrivateKey returns the private key stored in the Secret named
+// spec.secretName, along with the fields of the Certificate's spec that it
+// does not match.
+func (c *controller) existingSecretPrivateKey(crt *cmapi.Certificate) (crypto.Signer, []string, error) {
 	s, err := c.secretLister.Secrets(crt.Namespace).Get(crt.Spec.SecretName)
 	if apierrors.IsNotFound(err) {
-		log.V(logf.DebugLevel).Info("Creating new nextPrivateKeySecretName Secret because no existing Secret found and rotation policy is Never")
-		return c.createAndSetNextPrivateKey(ctx, crt)
+		return nil, nil, errSecretNotFound
 	}
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	s = apiutil.SecretWithDefaultKeyNames(crt, s)
 	if s.Data == nil || len(s.Data[corev1.TLSPrivateKeyKey]) == 0 {
-		log.V(logf.DebugLevel).Info("Creating new nextPrivateKeySecretName Secret because existing Secret contains empty data and rotation policy is Never")
-		return c.createAndSetNextPrivateKey(ctx, crt)
+		return nil, nil, errNoPrivateKey
 	}
-	existingPKData := s.Data[corev1.TLSPrivateKeyKey]
-	pk, err := pki.DecodePrivateKeyBytes(existingPKData)
+	pk, err := pki.DecodePrivateKeyBytes(s.Data[corev1.TLSPrivateKeyKey])
 	if err != nil {
-		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Failed to decode private key stored in Secret %q - generating new key", crt.Spec.SecretName)
-		return c.createAndSetNextPrivateKey(ctx, crt)
+		return nil, nil, &unusablePrivateKeyError{err: fmt.Errorf("failed to decode private key: %w", err)}
 	}
 	violations, err := certificates.PrivateKeyMatchesSpec(pk, crt.Spec)
 	if err != nil {
-		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Failed to check if private key stored in Secret %q is up to date - generating new key", crt.Spec.SecretName)
+		return nil, nil, &unusablePrivateKeyError{err: fmt.Errorf("failed to check if private key is up to date: %w", err)}
+	}
+	return pk, violations, nil
+}
+
+func (c *controller) createNextPrivateKeyRotationPolicyNever(ctx context.Context, crt *cmapi.Certificate) error {
+	log := logf.FromContext(ctx)
+	pk, violations, err := c.existingSecretPrivateKey(crt)
+	var unusable *unusablePrivateKeyError
+	switch {
+	case errors.Is(err, errSecretNotFound):
+		log.V(logf.DebugLevel).Info("Creating new nextPrivateKeySecretName Secret because no existing Secret found and rotation policy is Never")
+		return c.createAndSetNextPrivateKey(ctx, crt)
+	case errors.Is(err, errNoPrivateKey):
+		log.V(logf.DebugLevel).Info("Creating new nextPrivateKeySecretName Secret because existing Secret contains empty data and rotation policy is Never")
+		return c.createAndSetNextPrivateKey(ctx, crt)
+	case errors.As(err, &unusable):
+		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Unable to use private key stored in Secret %q - generating new key: %v", crt.Spec.SecretName, unusable)
 		return c.createAndSetNextPrivateKey(ctx, crt)
+	case err != nil:
+		return err
 	}
 	if len(violations) > 0 {
 		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Existing private key in Secret %q does not match requirements on Certificate resource, mismatching fields: %v", crt.Spec.SecretName, violations)
@@ -258,11 +350,64 @@ func (c *controller) createNextPrivateKeyRotationPolicyNever(ctx context.Context
 		return err
 	}
 
-	c.recorder.Event(crt, corev1.EventTypeNormal, "Reused", fmt.Sprintf("Reusing private key stored in existing Secret resource %q", s.Name))
+	c.recorder.Event(crt, corev1.EventTypeNormal, "Reused", fmt.Sprintf("Reusing private key stored in existing Secret resource %q", crt.Spec.SecretName))
+
+	return c.setNextPrivateKeySecretName(ctx, crt, &nextPkSecret.Name)
+}
+
+// createNextPrivateKeyRotationPolicyExternal copies the externally managed
+// private key stored in spec.secretName into the next private key Secret.
+// A private key is never generated. If the Secret or the key within it is
+// missing, issuance is failed until the external controller provisions it.
+// If the key cannot be used, a warning is raised and issuance waits for the
+// external controller to provision a suitable key.
+func (c *controller) createNextPrivateKeyRotationPolicyExternal(ctx context.Context, crt *cmapi.Certificate) error {
+	pk, violations, err := c.existingSecretPrivateKey(crt)
+	var unusable *unusablePrivateKeyError
+	switch {
+	case errors.Is(err, errSecretNotFound):
+		return c.failExternalKeyMissing(ctx, crt, fmt.Sprintf("Secret %q containing the externally managed private key does not exist", crt.Spec.SecretName))
+	case errors.Is(err, errNoPrivateKey):
+		return c.failExternalKeyMissing(ctx, crt, fmt.Sprintf("Secret %q does not contain the externally managed private key", crt.Spec.SecretName))
+	case errors.As(err, &unusable):
+		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Unable to use externally managed private key stored in Secret %q: %v", crt.Spec.SecretName, unusable)
+		return nil
+	case err != nil:
+		return err
+	}
+	if len(violations) > 0 {
+		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonDecodeFailed, "Externally managed private key in Secret %q does not match requirements on Certificate resource, mismatching fields: %v", crt.Spec.SecretName, violations)
+		return nil
+	}
+
+	nextPkSecret, err := c.createNewPrivateKeySecret(ctx, crt, pk)
+	if err != nil {
+		return err
+	}
+
+	c.recorder.Event(crt, corev1.EventTypeNormal, "Reused", fmt.Sprintf("Using externally managed private key stored in Secret resource %q", crt.Spec.SecretName))
 
 	return c.setNextPrivateKeySecretName(ctx, crt, &nextPkSecret.Name)
 }
 
+// failExternalKeyMissing marks the issuance of the Certificate as failed, as
+// its externally managed private key does not exist. The trigger controller
+// retries the issuance once the private key has been provisioned.
+func (c *controller) failExternalKeyMissing(ctx context.Context, crt *cmapi.Certificate, message string) error {
+	logf.FromContext(ctx).V(logf.DebugLevel).Info("Failing issuance as the externally managed private key does not exist and rotation policy is External")
+
+	crt = crt.DeepCopy()
+	nowTime := metav1.NewTime(c.clock.Now())
+	crt.Status.LastFailureTime = &nowTime
+	apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, certificates.ReasonExternalKeyMissing, message)
+	if _, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	c.recorder.Event(crt, corev1.EventTypeWarning, certificates.ReasonExternalKeyMissing, message)
+	return nil
+}
+
 func (c *controller) createAndSetNextPrivateKey(ctx context.Context, crt *cmapi.Certificate) error {
 	pk, err := pki.GeneratePrivateKeyForCertificate(crt)
 	if err != nil {
@@ -279,6 +424,26 @@ func (c *controller) createAndSetNextPrivateKey(ctx context.Context, crt *cmapi.
 	return c.setNextPrivateKeySecretName(ctx, crt, &s.Name)
 }
 
+// secretsReferencedByInFlightRequests returns the names of the private key
+// Secrets referenced by CertificateRequests owned by the given Certificate
+// that have not yet completed.
+func (c *controller) secretsReferencedByInFlightRequests(crt *cmapi.Certificate) (sets.String, error) {
+	reqs, err := certificates.ListCertificateRequestsMatchingPredicates(c.certificateRequestLister.CertificateRequests(crt.Namespace), labels.Everything(), predicate.ResourceOwnedBy(crt))
+	if err != nil {
+		return nil, err
+	}
+
+	names := sets.NewString()
+	for _, req := range reqs {
+		name := req.Annotations[cmapi.CertificateRequestPrivateKeyAnnotationKey]
+		if name == "" || !certificates.RequestInFlight(req) {
+			continue
+		}
+		names.Insert(name)
+	}
+	return names, nil
+}
+
 // deleteSecretResources will delete the given secret resources
 func (c *controller) deleteSecretResources(ctx context.Context, secrets []*corev1.Secret) error {
 	log := logf.FromContext(ctx)
@@ -360,6 +525,7 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.KubeSharedInformerFactory,
 		ctx.SharedInformerFactory,
 		ctx.Recorder,
+		ctx.Clock,
 	)
 	c.controller = ctrl
 
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/kr/pretty"
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	coretesting "k8s.io/client-go/testing"
+	fakeclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/pointer"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
@@ -37,6 +39,11 @@ import (
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
+var (
+	fixedNow   = metav1.NewTime(time.Now().Truncate(time.Second))
+	fixedClock = fakeclock.NewFakeClock(fixedNow.Time)
+)
+
 func mustGenerateRSA(t *testing.T, keySize int) []byte {
 	pk, err := pki.GenerateRSAPrivateKey(keySize)
 	if err != nil {
@@ -77,6 +84,7 @@ func relaxedSecretMatcher(l coretesting.Action, r coretesting.Action) error {
 }
 
 func TestProcessItem(t *testing.T) {
+	externalKey := mustGenerateRSA(t, 2048)
 	ownedSecretWithName := func(namespace, name, owner string, data map[string][]byte) *corev1.Secret {
 		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
@@ -93,6 +101,24 @@ func TestProcessItem(t *testing.T) {
 			Data: data,
 		}
 	}
+	ownedRequestWithCondition := func(namespace, name, owner, secretName string, cond *cmapi.CertificateRequestCondition) *cmapi.CertificateRequest {
+		req := &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Annotations: map[string]string{
+				cmapi.CertificateRequestPrivateKeyAnnotationKey: secretName,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(&cmapi.Certificate{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: owner, UID: types.UID(owner)},
+				}, certificateGvk),
+			},
+		}}
+		if cond != nil {
+			req.Status.Conditions = []cmapi.CertificateRequestCondition{*cond}
+		}
+		return req
+	}
 	tests := map[string]struct {
 		// key that should be passed to ProcessItem.
 		// if not set, the 'namespace/name' of the 'Certificate' field will be used.
@@ -189,6 +215,288 @@ func TestProcessItem(t *testing.T) {
 				), relaxedSecretMatcher),
 			},
 		},
+		"do not create a secret if the rotation policy is Never and the existing private key does not match spec": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+				Spec: cmapi.CertificateSpec{
+					SecretName: "test-secret",
+					PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyNever},
+				},
+				Status: cmapi.CertificateStatus{
+					Conditions: []cmapi.CertificateCondition{
+						{
+							Type:   cmapi.CertificateConditionIssuing,
+							Status: cmmeta.ConditionTrue,
+						},
+					},
+				},
+			},
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test-secret"},
+					Data:       map[string][]byte{"tls.key": mustGenerateECDSA(t, pki.ECCurve256)},
+				},
+			},
+			expectedEvents: []string{`Warning DecodeFailed Existing private key in Secret "test-secret" does not match requirements on Certificate resource, mismatching fields: [spec.keyAlgorithm]`},
+		},
+		"create a secret if the rotation policy is Always and the existing private key does not match spec": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+				Spec: cmapi.CertificateSpec{
+					SecretName: "test-secret",
+					PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyAlways},
+				},
+				Status: cmapi.CertificateStatus{
+					Conditions: []cmapi.CertificateCondition{
+						{
+							Type:   cmapi.CertificateConditionIssuing,
+							Status: cmmeta.ConditionTrue,
+						},
+					},
+				},
+			},
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test-secret"},
+					Data:       map[string][]byte{"tls.key": mustGenerateECDSA(t, pki.ECCurve256)},
+				},
+			},
+			expectedEvents: []string{`Normal Generated Stored new private key in temporary Secret resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"testns",
+					&cmapi.Certificate{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+						Spec: cmapi.CertificateSpec{
+							SecretName: "test-secret",
+							PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyAlways},
+						},
+						Status: cmapi.CertificateStatus{
+							NextPrivateKeySecretName: pointer.StringPtr("test-notrandom"),
+							Conditions: []cmapi.CertificateCondition{
+								{
+									Type:   cmapi.CertificateConditionIssuing,
+									Status: cmmeta.ConditionTrue,
+								},
+							},
+						},
+					},
+				)),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(
+					corev1.SchemeGroupVersion.WithResource("secrets"),
+					"testns",
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace:       "testns",
+							GenerateName:    "test-",
+							Labels:          map[string]string{cmapi.IsNextPrivateKeySecretLabelKey: "true"},
+							OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"}}, certificateGvk)},
+						},
+						Data: map[string][]byte{"tls.key": nil},
+					},
+				), relaxedSecretMatcher),
+			},
+		},
+		"do not generate a private key if the rotation policy is External and the Secret does not exist": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+				Spec: cmapi.CertificateSpec{
+					SecretName: "test-secret",
+					PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyExternal},
+				},
+				Status: cmapi.CertificateStatus{
+					Conditions: []cmapi.CertificateCondition{
+						{
+							Type:   cmapi.CertificateConditionIssuing,
+							Status: cmmeta.ConditionTrue,
+						},
+					},
+				},
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"testns",
+					&cmapi.Certificate{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+						Spec: cmapi.CertificateSpec{
+							SecretName: "test-secret",
+							PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyExternal},
+						},
+						Status: cmapi.CertificateStatus{
+							LastFailureTime: &fixedNow,
+							Conditions: []cmapi.CertificateCondition{
+								{
+									Type:               cmapi.CertificateConditionIssuing,
+									Status:             cmmeta.ConditionFalse,
+									Reason:             "ExternalKeyMissing",
+									Message:            `Secret "test-secret" containing the externally managed private key does not exist`,
+									LastTransitionTime: &fixedNow,
+								},
+							},
+						},
+					},
+				)),
+			},
+			expectedEvents: []string{`Warning ExternalKeyMissing Secret "test-secret" containing the externally managed private key does not exist`},
+		},
+		"do not generate a private key if the rotation policy is External and the Secret contains no private key": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+				Spec: cmapi.CertificateSpec{
+					SecretName: "test-secret",
+					PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyExternal},
+				},
+				Status: cmapi.CertificateStatus{
+					Conditions: []cmapi.CertificateCondition{
+						{
+							Type:   cmapi.CertificateConditionIssuing,
+							Status: cmmeta.ConditionTrue,
+						},
+					},
+				},
+			},
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test-secret"},
+					Data:       map[string][]byte{"tls.crt": []byte("cert")},
+				},
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"testns",
+					&cmapi.Certificate{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+						Spec: cmapi.CertificateSpec{
+							SecretName: "test-secret",
+							PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyExternal},
+						},
+						Status: cmapi.CertificateStatus{
+							LastFailureTime: &fixedNow,
+							Conditions: []cmapi.CertificateCondition{
+								{
+									Type:               cmapi.CertificateConditionIssuing,
+									Status:             cmmeta.ConditionFalse,
+									Reason:             "ExternalKeyMissing",
+									Message:            `Secret "test-secret" does not contain the externally managed private key`,
+									LastTransitionTime: &fixedNow,
+								},
+							},
+						},
+					},
+				)),
+			},
+			expectedEvents: []string{`Warning ExternalKeyMissing Secret "test-secret" does not contain the externally managed private key`},
+		},
+		"do not generate a private key if the rotation policy is External and the existing private key does not match spec": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+				Spec: cmapi.CertificateSpec{
+					SecretName: "test-secret",
+					PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyExternal},
+				},
+				Status: cmapi.CertificateStatus{
+					Conditions: []cmapi.CertificateCondition{
+						{
+							Type:   cmapi.CertificateConditionIssuing,
+							Status: cmmeta.ConditionTrue,
+						},
+					},
+				},
+			},
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test-secret"},
+					Data:       map[string][]byte{"tls.key": mustGenerateECDSA(t, pki.ECCurve256)},
+				},
+			},
+			expectedEvents: []string{`Warning DecodeFailed Externally managed private key in Secret "test-secret" does not match requirements on Certificate resource, mismatching fields: [spec.keyAlgorithm]`},
+		},
+		"copy the externally managed private key into a secret if the rotation policy is External": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+				Spec: cmapi.CertificateSpec{
+					SecretName: "test-secret",
+					PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyExternal},
+				},
+				Status: cmapi.CertificateStatus{
+					Conditions: []cmapi.CertificateCondition{
+						{
+							Type:   cmapi.CertificateConditionIssuing,
+							Status: cmmeta.ConditionTrue,
+						},
+					},
+				},
+			},
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test-secret"},
+					Data:       map[string][]byte{"tls.key": externalKey},
+				},
+			},
+			expectedEvents: []string{`Normal Reused Using externally managed private key stored in Secret resource "test-secret"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"testns",
+					&cmapi.Certificate{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+						Spec: cmapi.CertificateSpec{
+							SecretName: "test-secret",
+							PrivateKey: &cmapi.CertificatePrivateKey{RotationPolicy: cmapi.RotationPolicyExternal},
+						},
+						Status: cmapi.CertificateStatus{
+							NextPrivateKeySecretName: pointer.StringPtr("test-notrandom"),
+							Conditions: []cmapi.CertificateCondition{
+								{
+									Type:   cmapi.CertificateConditionIssuing,
+									Status: cmmeta.ConditionTrue,
+								},
+							},
+						},
+					},
+				)),
+				// the Secret must contain the externally managed key rather
+				// than a newly generated one
+				testpkg.NewAction(coretesting.NewCreateAction(
+					corev1.SchemeGroupVersion.WithResource("secrets"),
+					"testns",
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace:       "testns",
+							GenerateName:    "test-",
+							Labels:          map[string]string{cmapi.IsNextPrivateKeySecretLabelKey: "true"},
+							OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"}}, certificateGvk)},
+						},
+						Data: map[string][]byte{"tls.key": externalKey},
+					},
+				)),
+			},
+		},
+		"do not create a secret if the Certificate uses a supplied CSR": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+				Spec: cmapi.CertificateSpec{
+					CSRSecretRef: &cmmeta.SecretKeySelector{
+						LocalObjectReference: cmmeta.LocalObjectReference{Name: "csr"},
+					},
+				},
+				Status: cmapi.CertificateStatus{
+					Conditions: []cmapi.CertificateCondition{
+						{
+							Type:   cmapi.CertificateConditionIssuing,
+							Status: cmmeta.ConditionTrue,
+						},
+					},
+				},
+			},
+		},
 		"create a secret using the already allocated name if it is set": {
 			certificate: &cmapi.Certificate{
 				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
@@ -461,6 +769,94 @@ func TestProcessItem(t *testing.T) {
 				ownedSecretWithName("testns", "fixed-name", "test", map[string][]byte{"tls.key": mustGenerateRSA(t, 2048)}),
 			},
 		},
+		"delete the owned secret and unset nextPrivateKeySecretName once issuance has completed": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: types.UID("test")},
+				Status: cmapi.CertificateStatus{
+					NextPrivateKeySecretName: pointer.StringPtr("fixed-name"),
+				},
+			},
+			secrets: []runtime.Object{
+				ownedSecretWithName("testns", "fixed-name", "test", map[string][]byte{"tls.key": mustGenerateRSA(t, 2048)}),
+			},
+			requests: []*cmapi.CertificateRequest{
+				ownedRequestWithCondition("testns", "test-1", "test", "fixed-name", &cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionReady,
+					Status: cmmeta.ConditionTrue,
+					Reason: cmapi.CertificateRequestReasonIssued,
+				}),
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(
+					corev1.SchemeGroupVersion.WithResource("secrets"),
+					"testns",
+					"fixed-name",
+				)),
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"testns",
+					&cmapi.Certificate{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: types.UID("test")},
+					},
+				)),
+			},
+		},
+		"delete the owned secret once issuance has completed even if nextPrivateKeySecretName has already been unset": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: types.UID("test")},
+			},
+			secrets: []runtime.Object{
+				ownedSecretWithName("testns", "fixed-name", "test", map[string][]byte{"tls.key": mustGenerateRSA(t, 2048)}),
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(
+					corev1.SchemeGroupVersion.WithResource("secrets"),
+					"testns",
+					"fixed-name",
+				)),
+			},
+		},
+		"retain the owned secret if it is referenced by an in-flight CertificateRequest": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: types.UID("test")},
+				Status: cmapi.CertificateStatus{
+					NextPrivateKeySecretName: pointer.StringPtr("fixed-name"),
+				},
+			},
+			secrets: []runtime.Object{
+				ownedSecretWithName("testns", "fixed-name", "test", map[string][]byte{"tls.key": mustGenerateRSA(t, 2048)}),
+			},
+			requests: []*cmapi.CertificateRequest{
+				ownedRequestWithCondition("testns", "test-1", "test", "fixed-name", &cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionReady,
+					Status: cmmeta.ConditionFalse,
+					Reason: cmapi.CertificateRequestReasonPending,
+				}),
+			},
+		},
+		"delete the owned secret if the CertificateRequest referencing it has failed": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: types.UID("test")},
+			},
+			secrets: []runtime.Object{
+				ownedSecretWithName("testns", "fixed-name", "test", map[string][]byte{"tls.key": mustGenerateRSA(t, 2048)}),
+			},
+			requests: []*cmapi.CertificateRequest{
+				ownedRequestWithCondition("testns", "test-1", "test", "fixed-name", &cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionReady,
+					Status: cmmeta.ConditionFalse,
+					Reason: cmapi.CertificateRequestReasonFailed,
+				}),
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(
+					corev1.SchemeGroupVersion.WithResource("secrets"),
+					"testns",
+					"fixed-name",
+				)),
+			},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -470,6 +866,7 @@ func TestProcessItem(t *testing.T) {
 				ExpectedEvents:  test.expectedEvents,
 				ExpectedActions: test.expectedActions,
 				StringGenerator: func(i int) string { return "notrandom" },
+				Clock:           fixedClock,
 			}
 			if test.certificate != nil {
 				builder.CertManagerObjects = append(builder.CertManagerObjects, test.certificate)
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/utils/clock"
+)
+
+// RateLimiter limits the rate at which the certificates controllers perform
+// an operation, such as creating or deleting CertificateRequests. Each key,
+// e.g. a namespace, has its own token bucket which holds up to burst tokens
+// and is refilled at the configured rate.
+type RateLimiter struct {
+	clock clock.Clock
+	limit rate.Limit
+	burst int
+
+	lock     sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewRateLimiter returns a RateLimiter that allows perSecond operations per
+// second for each key, with bursts of up to burst operations.
+func NewRateLimiter(clock clock.Clock, perSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		clock:    clock,
+		limit:    rate.Limit(perSecond),
+		burst:    burst,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Reserve takes a token from the bucket of the given key if one is
+// available, and returns a function that returns the token to the bucket,
+// to be called if the operation could not be performed. Otherwise no token
+// is taken, and the time until a token will become available is returned.
+func (l *RateLimiter) Reserve(key string) (cancel func(), wait time.Duration) {
+	now := l.clock.Now()
+
+	l.lock.Lock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[key] = limiter
+	}
+	l.lock.Unlock()
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		// only possible if burst is zero, in which case no tokens are
+		// ever available
+		return nil, rate.InfDuration
+	}
+	if wait := reservation.DelayFrom(now); wait > 0 {
+		reservation.CancelAt(now)
+		return nil, wait
+	}
+	return func() { reservation.CancelAt(l.clock.Now()) }, 0
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	fakeclock "k8s.io/utils/clock/testing"
+)
+
+func TestRateLimiter(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	// two operations per second, with bursts of up to 2 operations
+	limiter := NewRateLimiter(clock, 2, 2)
+
+	reserve := func(key string) time.Duration {
+		_, wait := limiter.Reserve(key)
+		return wait
+	}
+
+	assert.Zero(t, reserve("ns-a"), "first operation of the burst should be allowed")
+	assert.Zero(t, reserve("ns-a"), "second operation of the burst should be allowed")
+	assert.Equal(t, 500*time.Millisecond, reserve("ns-a"), "operations beyond the burst should be deferred until a token is available")
+
+	// each key has its own bucket
+	assert.Zero(t, reserve("ns-b"))
+
+	// a deferred operation does not take a token
+	clock.Step(200 * time.Millisecond)
+	assert.Equal(t, 300*time.Millisecond, reserve("ns-a"))
+
+	clock.Step(300 * time.Millisecond)
+	assert.Zero(t, reserve("ns-a"))
+	assert.Equal(t, 500*time.Millisecond, reserve("ns-a"))
+
+	// the bucket is not refilled beyond the burst
+	clock.Step(time.Hour)
+	assert.Zero(t, reserve("ns-a"))
+	assert.Zero(t, reserve("ns-a"))
+	assert.Equal(t, 500*time.Millisecond, reserve("ns-a"))
+
+	// a cancelled reservation returns its token to the bucket
+	clock.Step(time.Hour)
+	assert.Zero(t, reserve("ns-a"))
+	cancel, wait := limiter.Reserve("ns-a")
+	assert.Zero(t, wait)
+	cancel()
+	assert.Zero(t, reserve("ns-a"))
+	assert.Equal(t, 500*time.Millisecond, reserve("ns-a"))
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"fmt"
+
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+)
+
+const (
+	// reasonIssuerNotReady is the reason of the IssuerNotReady condition if
+	// the issuer does not have a Ready condition yet.
+	reasonIssuerNotReady = "IssuerNotReady"
+)
+
+// setIssuerNotReadyCondition reflects the Ready condition of the
+// Certificate's issuer onto its IssuerNotReady condition. The condition is
+// set, with the reason and message of the issuer's Ready condition, if the
+// issuer is not Ready, and removed otherwise. Issuers of other API groups, and
+// issuers that cannot be read, such as those that do not exist yet, are left
+// to be reported by the controllers that issue the Certificate.
+func (c *controller) setIssuerNotReadyCondition(crt *cmapi.Certificate) {
+	issuerRef := crt.Spec.IssuerRef
+	if c.issuerHelper == nil || (issuerRef.Group != "" && issuerRef.Group != certmanager.GroupName) {
+		apiutil.RemoveCertificateCondition(crt, cmapi.CertificateConditionIssuerNotReady)
+		return
+	}
+
+	iss, err := c.issuerHelper.GetGenericIssuer(issuerRef, crt.Namespace)
+	if err != nil {
+		apiutil.RemoveCertificateCondition(crt, cmapi.CertificateConditionIssuerNotReady)
+		return
+	}
+
+	var ready *cmapi.IssuerCondition
+	for i, cond := range iss.GetStatus().Conditions {
+		if cond.Type == cmapi.IssuerConditionReady {
+			ready = &iss.GetStatus().Conditions[i]
+			break
+		}
+	}
+
+	if ready != nil && ready.Status == cmmeta.ConditionTrue {
+		apiutil.RemoveCertificateCondition(crt, cmapi.CertificateConditionIssuerNotReady)
+		return
+	}
+
+	reason := reasonIssuerNotReady
+	message := fmt.Sprintf("Referenced %s %q does not have a Ready status condition", apiutil.IssuerKind(issuerRef), issuerRef.Name)
+	if ready != nil {
+		if ready.Reason != "" {
+			reason = ready.Reason
+		}
+		message = fmt.Sprintf("Referenced %s %q is not ready: %s", apiutil.IssuerKind(issuerRef), issuerRef.Name, ready.Message)
+	}
+	apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionIssuerNotReady, cmmeta.ConditionTrue, reason, message)
+}
@@ -18,6 +18,7 @@ package readiness
 
 import (
 	"context"
+	"crypto/x509"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -41,7 +42,9 @@ import (
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates/trigger/policies"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/scheduler"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/pkg/util/predicate"
 )
@@ -51,6 +54,10 @@ const (
 	ControllerName = "certificates-readiness"
 	// ReadyReason is the 'Ready' reason of a Certificate.
 	ReadyReason = "Ready"
+
+	// maxSummarySANs is the maximum number of entries of each list of subject
+	// alternative names recorded in a Certificate's status.
+	maxSummarySANs = 50
 )
 
 type controller struct {
@@ -65,6 +72,16 @@ type controller struct {
 	policyEvaluator policyEvaluatorFunc
 	// renewalTimeCalculator calculates renewal time of a certificate
 	renewalTimeCalculator certificates.RenewalTimeFunc
+	clock                 clock.Clock
+	// clockSkewTolerance is the tolerance the policy chain applies to the
+	// notBefore of not yet valid certificates.
+	clockSkewTolerance time.Duration
+	// scheduledWorkQueue is used to re-check Certificates whose current
+	// certificate is not yet valid once its notBefore has been reached.
+	scheduledWorkQueue scheduler.ScheduledWorkQueue
+	// issuerHelper is used to read the issuers of Certificates, whose
+	// conditions are reflected onto the Certificates. If nil, they are not.
+	issuerHelper issuer.Helper
 }
 
 // readyConditionFunc is custom function type that builds certificate's Ready condition
@@ -76,6 +93,8 @@ func NewController(
 	client cmclient.Interface,
 	factory informers.SharedInformerFactory,
 	cmFactory cminformers.SharedInformerFactory,
+	clock clock.Clock,
+	clockSkewTolerance time.Duration,
 	chain policies.Chain,
 	renewalTimeCalculator certificates.RenewalTimeFunc,
 	policyEvaluator policyEvaluatorFunc,
@@ -100,6 +119,11 @@ func NewController(
 		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
 			predicate.ExtractResourceName(predicate.CertificateSecretName)),
 	})
+	// When a Secret resource changes, enqueue any Certificate resources that name it as spec.trustAnchors.
+	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
+			predicate.ExtractResourceName(predicate.CertificateTrustAnchorsSecretName)),
+	})
 
 	// build a list of InformerSynced functions that will be returned by the Register method.
 	// the controller will only begin processing items once all of these informers have synced.
@@ -121,6 +145,9 @@ func NewController(
 		},
 		policyEvaluator:       policyEvaluator,
 		renewalTimeCalculator: renewalTimeCalculator,
+		clock:                 clock,
+		clockSkewTolerance:    clockSkewTolerance,
+		scheduledWorkQueue:    scheduler.NewScheduledWorkQueue(clock, queue.Add),
 	}, queue, mustSync
 }
 
@@ -155,6 +182,7 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	oldCrt := crt
 	crt = crt.DeepCopy()
 	apiutil.SetCertificateCondition(crt, crt.Generation, condition.Type, condition.Status, condition.Reason, condition.Message)
+	c.setIssuerNotReadyCondition(crt)
 
 	switch {
 	case input.Secret != nil && input.Secret.Data != nil:
@@ -164,6 +192,7 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 			crt.Status.NotAfter = nil
 			crt.Status.NotBefore = nil
 			crt.Status.RenewalTime = nil
+			crt.Status.IssuedCertificate = nil
 			break
 		}
 
@@ -171,22 +200,43 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		notAfter := metav1.NewTime(x509cert.NotAfter)
 		renewBeforeHint := crt.Spec.RenewBefore
 		renewalTime := c.renewalTimeCalculator(x509cert.NotBefore, x509cert.NotAfter, renewBeforeHint)
+		renewalTime = certificates.RenewalTimeWithIssuanceLatency(renewalTime, x509cert.NotBefore, x509cert.NotAfter, crt.Status.IssuanceLatency)
+		// The trigger controller defers renewals until the Certificate's
+		// renewal window opens, so report the deferred time.
+		if renewalTime != nil && crt.Spec.RenewalWindow != nil {
+			windowTime, err := apiutil.RenewalTimeInWindow(crt.Spec.RenewalWindow, renewalTime.Time, x509cert.NotAfter)
+			if err != nil {
+				log.Error(err, "invalid renewal window, not deferring renewal time")
+			} else {
+				renewalTime = &metav1.Time{Time: windowTime}
+			}
+		}
 
 		//update Certificate's Status
 		crt.Status.NotBefore = &notBefore
 		crt.Status.NotAfter = &notAfter
 		crt.Status.RenewalTime = renewalTime
+		crt.Status.IssuedCertificate = issuedCertificateSummary(x509cert)
+
+		// Nothing else will cause the Certificate to be re-synced once the
+		// certificate becomes valid, so schedule it for the time its notBefore
+		// is within the clock skew tolerance.
+		if condition.Reason == policies.NotYetValid {
+			c.scheduledWorkQueue.Add(key, x509cert.NotBefore.Add(-c.clockSkewTolerance).Sub(c.clock.Now()))
+		}
 
 	default:
 		// clear status fields if the secret does not have any data
 		crt.Status.NotAfter = nil
 		crt.Status.NotBefore = nil
 		crt.Status.RenewalTime = nil
+		crt.Status.IssuedCertificate = nil
 	}
 	if !apiequality.Semantic.DeepEqual(oldCrt.Status, crt.Status) {
 		log.V(logf.DebugLevel).Info("updating status fields", "notAfter",
 			crt.Status.NotAfter, "notBefore", crt.Status.NotBefore, "renewalTime",
 			crt.Status.RenewalTime)
+		certificates.RecordReconciliation(crt, ControllerName, c.clock.Now())
 		_, err = c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
 		if err != nil {
 			return err
@@ -196,6 +246,31 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 
 }
 
+// issuedCertificateSummary summarises the given certificate for the
+// Certificate's status. Each list of subject alternative names is truncated
+// to maxSummarySANs entries to keep the status bounded.
+func issuedCertificateSummary(cert *x509.Certificate) *cmapi.IssuedCertificateSummary {
+	summary := &cmapi.IssuedCertificateSummary{
+		SerialNumber:     cert.SerialNumber.Text(16),
+		Subject:          cert.Subject.String(),
+		IssuerCommonName: cert.Issuer.CommonName,
+	}
+
+	truncate := func(names []string) []string {
+		if len(names) > maxSummarySANs {
+			summary.Truncated = true
+			return names[:maxSummarySANs]
+		}
+		return names
+	}
+	summary.DNSNames = truncate(cert.DNSNames)
+	summary.IPAddresses = truncate(pki.IPAddressesToString(cert.IPAddresses))
+	summary.URIs = truncate(pki.URLsToString(cert.URIs))
+	summary.EmailAddresses = truncate(cert.EmailAddresses)
+
+	return summary
+}
+
 // policyEvaluator builds Certificate's Ready condition using the result of policy chain evaluation
 func policyEvaluator(chain policies.Chain, input policies.Input) cmapi.CertificateCondition {
 	reason, message, violationsFound := chain.Evaluate(input)
@@ -216,14 +291,22 @@ func policyEvaluator(chain policies.Chain, input policies.Input) cmapi.Certifica
 }
 
 // NewReadinessPolicyChain constructs an ordered chain of policies
-// that can be used to determine Certificate's Ready condition
-func NewReadinessPolicyChain(c clock.Clock) policies.Chain {
+// that can be used to determine Certificate's Ready condition.
+// clockSkewTolerance is the maximum amount of time a certificate's notBefore
+// may be in the future for it to still be considered Ready. secretLister is
+// used to read the trust anchors of Certificates. maxChainDepth is the maximum
+// number of certificates in an issued chain, or not positive for no maximum.
+func NewReadinessPolicyChain(c clock.Clock, clockSkewTolerance time.Duration, secretLister corelisters.SecretLister, maxChainDepth int) policies.Chain {
 	return policies.Chain{
 		policies.SecretDoesNotExist,
 		policies.SecretIsMissingData,
 		policies.SecretPublicKeysDiffer,
+		policies.SecretPrivateKeyMatchesSpec,
 		policies.CurrentCertificateRequestNotValidForSpec,
+		policies.CurrentCertificateNotYetValid(c, clockSkewTolerance),
 		policies.CurrentCertificateHasExpired(c),
+		policies.CurrentCertificateChainNotTrusted(c, secretLister),
+		policies.CurrentCertificateChainTooLong(maxChainDepth),
 	}
 }
 
@@ -241,10 +324,18 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.CMClient,
 		ctx.KubeSharedInformerFactory,
 		ctx.SharedInformerFactory,
-		NewReadinessPolicyChain(ctx.Clock),
+		ctx.Clock,
+		ctx.CertificateOptions.ClockSkewTolerance,
+		NewReadinessPolicyChain(ctx.Clock, ctx.CertificateOptions.ClockSkewTolerance, ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister(), ctx.CertificateOptions.MaxChainDepth),
 		certificates.RenewalTimeWrapper(cmapi.DefaultRenewBefore),
 		policyEvaluator,
 	)
+	// When an Issuer or ClusterIssuer changes, enqueue the Certificates that
+	// reference it so that its conditions are reflected onto them.
+	certificates.EnqueueCertificatesForIssuers(ctx, log, queue, ctrl.certificateLister)
+	issuerHelper, issuerSynced := certificates.NewIssuerHelper(ctx)
+	ctrl.issuerHelper = issuerHelper
+	mustSync = append(mustSync, issuerSynced...)
 	c.controller = ctrl
 
 	return queue, mustSync, nil
@@ -18,12 +18,20 @@ package readiness
 
 import (
 	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"reflect"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	coretesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	fakeclock "k8s.io/utils/clock/testing"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
@@ -33,6 +41,8 @@ import (
 	internaltest "github.com/jetstack/cert-manager/pkg/controller/certificates/internal/test"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates/trigger/policies"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	schedulertest "github.com/jetstack/cert-manager/pkg/scheduler/test"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
@@ -54,15 +64,31 @@ func TestProcessItem(t *testing.T) {
 	// now time is the current UTC time at the start of the test
 	now := time.Now().UTC()
 	metaNow := metav1.NewTime(now)
+	// start of a renewal window that opens after the renewal time
+	windowOpens := now.Add(90 * time.Minute).Truncate(time.Minute)
 	// private key to be used to generate X509 certificate
 	privKey := internaltest.MustCreatePEMPrivateKey(t)
 	cert := &cmapi.Certificate{
 		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
 		Spec: cmapi.CertificateSpec{
-			SecretName: "test-secret",
-			DNSNames:   []string{"example.com"},
+			SecretName:     "test-secret",
+			CommonName:     "example.com",
+			DNSNames:       []string{"example.com"},
+			IPAddresses:    []string{"10.0.0.1"},
+			URIs:           []string{"spiffe://cluster.local/ns/testns/sa/test"},
+			EmailAddresses: []string{"admin@example.com"},
 		},
 	}
+	// summary of the X509 cert built from the base Certificate, excluding
+	// the randomly generated serial number
+	issuedCertificate := &cmapi.IssuedCertificateSummary{
+		Subject:          "CN=example.com",
+		IssuerCommonName: "example.com",
+		DNSNames:         []string{"example.com"},
+		IPAddresses:      []string{"10.0.0.1"},
+		URIs:             []string{"spiffe://cluster.local/ns/testns/sa/test"},
+		EmailAddresses:   []string{"admin@example.com"},
+	}
 	// base Secret to be used in tests
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -98,9 +124,20 @@ func TestProcessItem(t *testing.T) {
 		// as the updated Certificate's status.notBefore
 		notBefore *metav1.Time
 
-		// renewalTime will be the updated Certificate's status.renewalTime
+		// renewalTime will be returned by the renewal time calculator and,
+		// unless expectedRenewalTime is set, be the updated Certificate's
+		// status.renewalTime
 		renewalTime *metav1.Time
 
+		// expectedRenewalTime, if set, will be the updated Certificate's
+		// status.renewalTime
+		expectedRenewalTime *metav1.Time
+
+		// issuedCertificate will be the updated Certificate's
+		// status.issuedCertificate. Its serial number is set to that of the
+		// X509 cert built for the test.
+		issuedCertificate *cmapi.IssuedCertificateSummary
+
 		wantsErr bool
 	}{
 		"do nothing if an empty 'key' is used": {},
@@ -124,6 +161,26 @@ func TestProcessItem(t *testing.T) {
 			notAfter:          func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Add(time.Hour * 2).Truncate(time.Second))),
 			notBefore:         func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Truncate(time.Second))),
 			renewalTime:       func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Add(time.Hour))),
+			issuedCertificate: issuedCertificate,
+		},
+		"update status for a Certificate with a renewal window to the time the window opens after the renewal time": {
+			condition: cmapi.CertificateCondition{
+				Type:               cmapi.CertificateConditionReady,
+				Status:             cmmeta.ConditionTrue,
+				Reason:             ReadyReason,
+				Message:            "ready message",
+				LastTransitionTime: &metaNow,
+			},
+			cert: gen.CertificateFrom(cert, gen.SetCertificateRenewalWindow(cmapi.CertificateRenewalWindow{
+				Ranges: []string{windowOpens.Format("15:04") + "-" + windowOpens.Add(30*time.Minute).Format("15:04")},
+			})),
+			certShouldUpdate:    true,
+			secretShouldExist:   true,
+			notAfter:            func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Add(time.Hour * 4).Truncate(time.Second))),
+			notBefore:           func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Truncate(time.Second))),
+			renewalTime:         func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Add(time.Hour))),
+			expectedRenewalTime: func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(windowOpens)),
+			issuedCertificate:   issuedCertificate,
 		},
 		"update status for a Certificate that is evaluated as not Ready and whose spec.secretName secret contains a valid X509 cert": {
 			condition: cmapi.CertificateCondition{
@@ -139,6 +196,7 @@ func TestProcessItem(t *testing.T) {
 			notAfter:          func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Add(time.Hour * 2).Truncate(time.Second))),
 			notBefore:         func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Truncate(time.Second))),
 			renewalTime:       func(m metav1.Time) *metav1.Time { return &m }(metav1.NewTime(now.Add(time.Hour))),
+			issuedCertificate: issuedCertificate,
 		},
 		"update status for a Certificate whose spec.secretName secret does not exist": {
 			condition: cmapi.CertificateCondition{
@@ -164,6 +222,20 @@ func TestProcessItem(t *testing.T) {
 			certShouldUpdate:  true,
 			secretShouldExist: true,
 		},
+		"clear the issued certificate summary of a Certificate whose spec.secretName secret no longer contains a TLS certificate": {
+			condition: cmapi.CertificateCondition{
+				Type:               cmapi.CertificateConditionReady,
+				Status:             cmmeta.ConditionFalse,
+				Reason:             "some reason",
+				Message:            "some message",
+				LastTransitionTime: &metaNow,
+			},
+			cert: gen.CertificateFrom(cert, func(crt *cmapi.Certificate) {
+				crt.Status.IssuedCertificate = &cmapi.IssuedCertificateSummary{SerialNumber: "1234", DNSNames: []string{"example.com"}}
+			}),
+			certShouldUpdate:  true,
+			secretShouldExist: true,
+		},
 		"update status for a Certificate that currently has Ready condition false, but policy evaluates to True": {
 			condition: cmapi.CertificateCondition{
 				Type:               cmapi.CertificateConditionReady,
@@ -249,6 +321,7 @@ func TestProcessItem(t *testing.T) {
 				builder.CertManagerObjects = append(builder.CertManagerObjects, test.cert)
 			}
 
+			var issuedCertificate *cmapi.IssuedCertificateSummary
 			if test.secretShouldExist {
 				mods := make([]gen.SecretModifier, 0)
 				// If the test scenario needs a secret with a valid X509 cert.
@@ -258,6 +331,15 @@ func TestProcessItem(t *testing.T) {
 						gen.SetSecretData(map[string][]byte{
 							"tls.crt": x509Bytes,
 						}))
+
+					if test.issuedCertificate != nil {
+						x509Cert, err := pki.DecodeX509CertificateBytes(x509Bytes)
+						if err != nil {
+							t.Fatal(err)
+						}
+						issuedCertificate = test.issuedCertificate.DeepCopy()
+						issuedCertificate.SerialNumber = x509Cert.SerialNumber.Text(16)
+					}
 				}
 				// Ensure secret is loaded into the builder's fake clientset.
 				builder.KubeObjects = append(builder.KubeObjects,
@@ -289,6 +371,13 @@ func TestProcessItem(t *testing.T) {
 				c.Status.NotAfter = test.notAfter
 				c.Status.NotBefore = test.notBefore
 				c.Status.RenewalTime = test.renewalTime
+				if test.expectedRenewalTime != nil {
+					c.Status.RenewalTime = test.expectedRenewalTime
+				}
+				c.Status.IssuedCertificate = issuedCertificate
+				c.Status.LastReconciledBy = []cmapi.CertificateReconciliationRecord{
+					{Controller: ControllerName, Time: metav1.NewTime(now)},
+				}
 
 				builder.ExpectedActions = append(builder.ExpectedActions,
 					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
@@ -326,7 +415,235 @@ func TestProcessItem(t *testing.T) {
 	}
 }
 
+func TestProcessItemIssuerNotReady(t *testing.T) {
+	now := time.Now().UTC()
+	metaNow := metav1.NewTime(now)
+	readyCondition := cmapi.CertificateCondition{
+		Type:               cmapi.CertificateConditionReady,
+		Status:             cmmeta.ConditionFalse,
+		Reason:             "some reason",
+		Message:            "some message",
+		LastTransitionTime: &metaNow,
+	}
+	issuerNotReadyCondition := func(reason, message string) cmapi.CertificateCondition {
+		return cmapi.CertificateCondition{
+			Type:               cmapi.CertificateConditionIssuerNotReady,
+			Status:             cmmeta.ConditionTrue,
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: &metaNow,
+		}
+	}
+	baseCert := gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateSecretName("test-secret"),
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: cmapi.IssuerKind}),
+		gen.SetCertificateStatusCondition(readyCondition),
+	)
+	baseIssuer := gen.Issuer("ca-issuer", gen.SetIssuerNamespace("testns"))
+
+	tests := map[string]struct {
+		cert   *cmapi.Certificate
+		issuer cmapi.GenericIssuer
+		// the expected conditions of the Certificate if it should be updated
+		expectedConditions []cmapi.CertificateCondition
+	}{
+		"an Issuer that is not Ready should add an IssuerNotReady condition with its reason": {
+			cert: gen.CertificateFrom(baseCert),
+			issuer: gen.IssuerFrom(baseIssuer, gen.AddIssuerCondition(cmapi.IssuerCondition{
+				Type: cmapi.IssuerConditionReady, Status: cmmeta.ConditionFalse, Reason: "ErrGetKeyPair", Message: "Error getting keypair for CA issuer",
+			})),
+			expectedConditions: []cmapi.CertificateCondition{
+				readyCondition,
+				issuerNotReadyCondition("ErrGetKeyPair", `Referenced Issuer "ca-issuer" is not ready: Error getting keypair for CA issuer`),
+			},
+		},
+		"an Issuer without a Ready condition should add an IssuerNotReady condition": {
+			cert:   gen.CertificateFrom(baseCert),
+			issuer: gen.IssuerFrom(baseIssuer),
+			expectedConditions: []cmapi.CertificateCondition{
+				readyCondition,
+				issuerNotReadyCondition("IssuerNotReady", `Referenced Issuer "ca-issuer" does not have a Ready status condition`),
+			},
+		},
+		"a ClusterIssuer that is not Ready should add an IssuerNotReady condition with its reason": {
+			cert: gen.CertificateFrom(baseCert, gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: cmapi.ClusterIssuerKind})),
+			issuer: gen.ClusterIssuer("ca-issuer", gen.AddIssuerCondition(cmapi.IssuerCondition{
+				Type: cmapi.IssuerConditionReady, Status: cmmeta.ConditionFalse, Reason: "ErrInitIssuer", Message: "Error initializing issuer",
+			})),
+			expectedConditions: []cmapi.CertificateCondition{
+				readyCondition,
+				issuerNotReadyCondition("ErrInitIssuer", `Referenced ClusterIssuer "ca-issuer" is not ready: Error initializing issuer`),
+			},
+		},
+		"an IssuerNotReady condition should be updated if the reason of the Issuer changes": {
+			cert: gen.CertificateFrom(baseCert, gen.SetCertificateStatusCondition(
+				issuerNotReadyCondition("IssuerNotReady", `Referenced Issuer "ca-issuer" does not have a Ready status condition`))),
+			issuer: gen.IssuerFrom(baseIssuer, gen.AddIssuerCondition(cmapi.IssuerCondition{
+				Type: cmapi.IssuerConditionReady, Status: cmmeta.ConditionFalse, Reason: "ErrGetKeyPair", Message: "Error getting keypair for CA issuer",
+			})),
+			expectedConditions: []cmapi.CertificateCondition{
+				readyCondition,
+				issuerNotReadyCondition("ErrGetKeyPair", `Referenced Issuer "ca-issuer" is not ready: Error getting keypair for CA issuer`),
+			},
+		},
+		"an IssuerNotReady condition should be removed once the Issuer is Ready": {
+			cert: gen.CertificateFrom(baseCert, gen.SetCertificateStatusCondition(
+				issuerNotReadyCondition("ErrGetKeyPair", `Referenced Issuer "ca-issuer" is not ready: Error getting keypair for CA issuer`))),
+			issuer: gen.IssuerFrom(baseIssuer, gen.AddIssuerCondition(cmapi.IssuerCondition{
+				Type: cmapi.IssuerConditionReady, Status: cmmeta.ConditionTrue, Reason: "KeyPairVerified", Message: "Signing CA verified",
+			})),
+			expectedConditions: []cmapi.CertificateCondition{readyCondition},
+		},
+		"an IssuerNotReady condition should be removed if the Issuer does not exist": {
+			cert: gen.CertificateFrom(baseCert, gen.SetCertificateStatusCondition(
+				issuerNotReadyCondition("ErrGetKeyPair", `Referenced Issuer "ca-issuer" is not ready: Error getting keypair for CA issuer`))),
+			expectedConditions: []cmapi.CertificateCondition{readyCondition},
+		},
+		"no update should be made if the Issuer is Ready": {
+			cert: gen.CertificateFrom(baseCert),
+			issuer: gen.IssuerFrom(baseIssuer, gen.AddIssuerCondition(cmapi.IssuerCondition{
+				Type: cmapi.IssuerConditionReady, Status: cmmeta.ConditionTrue, Reason: "KeyPairVerified", Message: "Signing CA verified",
+			})),
+		},
+		"no update should be made for issuers of other API groups": {
+			cert: gen.CertificateFrom(baseCert, gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "ca-issuer", Kind: "Issuer", Group: "example.com"})),
+			issuer: gen.IssuerFrom(baseIssuer, gen.AddIssuerCondition(cmapi.IssuerCondition{
+				Type: cmapi.IssuerConditionReady, Status: cmmeta.ConditionFalse, Reason: "ErrGetKeyPair", Message: "Error getting keypair for CA issuer",
+			})),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := &testpkg.Builder{
+				T:                  t,
+				Clock:              fakeclock.NewFakeClock(now),
+				CertManagerObjects: []runtime.Object{test.cert},
+			}
+			if test.issuer != nil {
+				builder.CertManagerObjects = append(builder.CertManagerObjects, test.issuer)
+			}
+			builder.Init()
+
+			w := &controllerWrapper{}
+			if _, _, err := w.Register(builder.Context); err != nil {
+				t.Fatal(err)
+			}
+			w.controller.policyEvaluator = policyEvaluatorBuilder(readyCondition)
+
+			if test.expectedConditions != nil {
+				c := gen.CertificateFrom(test.cert)
+				c.Status.Conditions = test.expectedConditions
+				c.Status.LastReconciledBy = []cmapi.CertificateReconciliationRecord{
+					{Controller: ControllerName, Time: metav1.NewTime(now)},
+				}
+				builder.ExpectedActions = append(builder.ExpectedActions,
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						c.Namespace,
+						c)))
+			}
+
+			builder.Start()
+			defer builder.Stop()
+
+			key, err := controllerpkg.KeyFunc(test.cert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.controller.ProcessItem(context.Background(), key); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := builder.AllActionsExecuted(); err != nil {
+				builder.T.Error(err)
+			}
+			if err := builder.AllReactorsCalled(); err != nil {
+				builder.T.Error(err)
+			}
+		})
+	}
+}
+
 // Test the evaluation of the ordered policy chain as a whole.
+func TestProcessItemNotYetValid(t *testing.T) {
+	// certificate validity times have a resolution of a second
+	now := time.Now().UTC().Truncate(time.Second)
+	privKey := internaltest.MustCreatePEMPrivateKey(t)
+	cert := gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateSecretName("test-secret"),
+		gen.SetCertificateCommonName("example.com"),
+	)
+
+	tests := map[string]struct {
+		reason             string
+		clockSkewTolerance time.Duration
+		// the expected delay after which the Certificate is re-synced, or
+		// nil if it should not be scheduled
+		expectedDelay *time.Duration
+	}{
+		"a Certificate that is not yet valid should be re-synced at its notBefore": {
+			reason:        policies.NotYetValid,
+			expectedDelay: func() *time.Duration { d := time.Hour; return &d }(),
+		},
+		"a Certificate that is not yet valid should be re-synced once its notBefore is within the clock skew tolerance": {
+			reason:             policies.NotYetValid,
+			clockSkewTolerance: 5 * time.Minute,
+			expectedDelay:      func() *time.Duration { d := 55 * time.Minute; return &d }(),
+		},
+		"a Certificate that is not Ready for another reason should not be scheduled": {
+			reason: policies.Expired,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			x509Bytes := internaltest.MustCreateCertWithNotBeforeAfter(t, privKey, cert, now.Add(time.Hour), now.Add(48*time.Hour))
+			builder := &testpkg.Builder{
+				T:                  t,
+				Clock:              fakeclock.NewFakeClock(now),
+				CertManagerObjects: []runtime.Object{cert},
+				KubeObjects: []runtime.Object{gen.Secret("test-secret",
+					gen.SetSecretNamespace("testns"),
+					gen.SetSecretData(map[string][]byte{"tls.crt": x509Bytes}),
+				)},
+			}
+			builder.Init()
+			builder.Context.CertificateOptions.ClockSkewTolerance = test.clockSkewTolerance
+
+			w := &controllerWrapper{}
+			if _, _, err := w.Register(builder.Context); err != nil {
+				t.Fatal(err)
+			}
+			w.controller.policyEvaluator = policyEvaluatorBuilder(cmapi.CertificateCondition{
+				Type:   cmapi.CertificateConditionReady,
+				Status: cmmeta.ConditionFalse,
+				Reason: test.reason,
+			})
+			var scheduled *time.Duration
+			w.controller.scheduledWorkQueue = &schedulertest.FakeScheduler{
+				AddFunc: func(_ interface{}, d time.Duration) { scheduled = &d },
+			}
+
+			builder.Start()
+			defer builder.Stop()
+
+			key, err := controllerpkg.KeyFunc(cert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.controller.ProcessItem(context.Background(), key); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(test.expectedDelay, scheduled) {
+				t.Errorf("expected the Certificate to be scheduled after %v, got %v", test.expectedDelay, scheduled)
+			}
+		})
+	}
+}
+
 func TestNewReadinessPolicyChain(t *testing.T) {
 	clock := &fakeclock.FakeClock{}
 	privKey := internaltest.MustCreatePEMPrivateKey(t)
@@ -402,6 +719,34 @@ func TestNewReadinessPolicyChain(t *testing.T) {
 			message:        "Issuing certificate as Secret contains an invalid key-pair: tls: private key does not match public key",
 			violationFound: true,
 		},
+		"Certificate not Ready as Secret contains a private key of a different algorithm to spec": {
+			cert: gen.Certificate("something",
+				gen.SetCertificateCommonName("example.com"),
+				gen.SetCertificateKeyAlgorithm(cmapi.ECDSAKeyAlgorithm)),
+			secret: gen.Secret("something", gen.SetSecretData(
+				map[string][]byte{
+					corev1.TLSPrivateKeyKey: privKey,
+					corev1.TLSCertKey: internaltest.MustCreateCert(t, privKey,
+						gen.Certificate("something", gen.SetCertificateCommonName("example.com"))),
+				})),
+			reason:         policies.SecretMismatch,
+			message:        "Existing private key is not up to date for spec: [spec.keyAlgorithm]",
+			violationFound: true,
+		},
+		"Certificate not Ready as Secret contains a private key of a different size to spec": {
+			cert: gen.Certificate("something",
+				gen.SetCertificateCommonName("example.com"),
+				gen.SetCertificateKeySize(4096)),
+			secret: gen.Secret("something", gen.SetSecretData(
+				map[string][]byte{
+					corev1.TLSPrivateKeyKey: privKey,
+					corev1.TLSCertKey: internaltest.MustCreateCert(t, privKey,
+						gen.Certificate("something", gen.SetCertificateCommonName("example.com"))),
+				})),
+			reason:         policies.SecretMismatch,
+			message:        "Existing private key is not up to date for spec: [spec.keySize]",
+			violationFound: true,
+		},
 		"Certificate not Ready when CertificateRequest does not match certificate spec": {
 			cert: gen.Certificate("something",
 				gen.SetCertificateCommonName("new.example.com"),
@@ -472,6 +817,71 @@ func TestNewReadinessPolicyChain(t *testing.T) {
 			message:        "Certificate expired on Sun, 31 Dec 0000 23:00:00 UTC",
 			violationFound: true,
 		},
+		"Certificate is Ready when its notBefore is in the future within the clock skew tolerance": {
+			cert: gen.Certificate("something",
+				gen.SetCertificateCommonName("new.example.com"),
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				})),
+			secret: gen.Secret("something",
+				gen.SetSecretAnnotations(map[string]string{
+					cmapi.IssuerNameAnnotationKey:  "testissuer",
+					cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+					cmapi.IssuerGroupAnnotationKey: "group.example.com",
+				}),
+				gen.SetSecretData(
+					map[string][]byte{
+						corev1.TLSPrivateKeyKey: privKey,
+						corev1.TLSCertKey: internaltest.MustCreateCertWithNotBeforeAfter(t, privKey,
+							gen.Certificate("something", gen.SetCertificateCommonName("new.example.com")),
+							clock.Now().Add(3*time.Minute), clock.Now().Add(time.Hour*3),
+						),
+					},
+				)),
+			cr: gen.CertificateRequest("something",
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				})),
+			reason:  "",
+			message: "",
+		},
+		"Certificate is not Ready when its notBefore is in the future beyond the clock skew tolerance": {
+			cert: gen.Certificate("something",
+				gen.SetCertificateCommonName("new.example.com"),
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				})),
+			secret: gen.Secret("something",
+				gen.SetSecretAnnotations(map[string]string{
+					cmapi.IssuerNameAnnotationKey:  "testissuer",
+					cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+					cmapi.IssuerGroupAnnotationKey: "group.example.com",
+				}),
+				gen.SetSecretData(
+					map[string][]byte{
+						corev1.TLSPrivateKeyKey: privKey,
+						corev1.TLSCertKey: internaltest.MustCreateCertWithNotBeforeAfter(t, privKey,
+							gen.Certificate("something", gen.SetCertificateCommonName("new.example.com")),
+							clock.Now().Add(10*time.Minute), clock.Now().Add(time.Hour*3),
+						),
+					},
+				)),
+			cr: gen.CertificateRequest("something",
+				gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				})),
+			reason:         policies.NotYetValid,
+			message:        "Certificate is not valid until Mon, 01 Jan 0001 00:10:00 UTC",
+			violationFound: true,
+		},
 		"Certificate is Ready, no policy violations found": {
 			cert: gen.Certificate("something",
 				gen.SetCertificateCommonName("new.example.com"),
@@ -511,8 +921,55 @@ func TestNewReadinessPolicyChain(t *testing.T) {
 			reason:  "",
 			message: "",
 		},
+		"Certificate is Ready when the issuer has rounded the notAfter of the certificate": {
+			cert: gen.Certificate("something",
+				gen.SetCertificateCommonName("new.example.com"),
+				gen.SetCertificateDuration(time.Hour*3),
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				})),
+			secret: gen.Secret("something",
+				gen.SetSecretAnnotations(
+					map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					}),
+				gen.SetSecretData(
+					map[string][]byte{
+						corev1.TLSPrivateKeyKey: privKey,
+						// notAfter has been rounded up to the end of the day
+						corev1.TLSCertKey: func() []byte {
+							template := &x509.Certificate{NotBefore: clock.Now(), NotAfter: clock.Now().Add(time.Hour * 3)}
+							pki.RoundNotAfter(template, time.Hour*24, true, time.Hour)
+							return internaltest.MustCreateCertWithNotBeforeAfter(t, privKey,
+								&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "new.example.com"}},
+								template.NotBefore, template.NotAfter,
+							)
+						}(),
+					},
+				)),
+			cr: gen.CertificateRequest("something",
+				gen.SetCertificateRequestIssuer(
+					cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+				),
+				gen.SetCertificateRequestDuration(&metav1.Duration{Duration: time.Hour * 3}),
+				gen.SetCertificateRequestCSR(internaltest.MustGenerateCSRImpl(t, privKey,
+					gen.Certificate("something",
+						gen.SetCertificateCommonName("new.example.com")))),
+			),
+			reason:  "",
+			message: "",
+		},
 	}
-	policyChain := NewReadinessPolicyChain(clock)
+	secretLister := corelisters.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+	policyChain := NewReadinessPolicyChain(clock, 5*time.Minute, secretLister, 0)
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			reason, message, violationFound := policyChain.Evaluate(policies.Input{
@@ -532,3 +989,164 @@ func TestNewReadinessPolicyChain(t *testing.T) {
 		})
 	}
 }
+
+func TestNewReadinessPolicyChainTrustAnchors(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	privKey := internaltest.MustCreatePEMPrivateKey(t)
+	issuerRef := cmmeta.ObjectReference{Name: "testissuer", Kind: "IssuerKind", Group: "group.example.com"}
+
+	// trust anchors that did not issue the certificate
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(gen.Secret("trust-anchors", gen.SetSecretData(map[string][]byte{
+		cmmeta.TLSCAKey: internaltest.MustCreateCertWithNotBeforeAfter(t, internaltest.MustCreatePEMPrivateKey(t),
+			&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "untrusted-ca"}},
+			clock.Now(), clock.Now().Add(time.Hour*3),
+		),
+	}))); err != nil {
+		t.Fatal(err)
+	}
+	policyChain := NewReadinessPolicyChain(clock, 5*time.Minute, corelisters.NewSecretLister(indexer), 0)
+
+	reason, message, violationFound := policyChain.Evaluate(policies.Input{
+		Certificate: gen.Certificate("something",
+			gen.SetCertificateCommonName("new.example.com"),
+			gen.SetCertificateIssuer(issuerRef),
+			gen.SetCertificateTrustAnchors("trust-anchors", ""),
+		),
+		Secret: gen.Secret("something",
+			gen.SetSecretAnnotations(map[string]string{
+				cmapi.IssuerNameAnnotationKey:  issuerRef.Name,
+				cmapi.IssuerKindAnnotationKey:  issuerRef.Kind,
+				cmapi.IssuerGroupAnnotationKey: issuerRef.Group,
+			}),
+			gen.SetSecretData(map[string][]byte{
+				corev1.TLSPrivateKeyKey: privKey,
+				corev1.TLSCertKey: internaltest.MustCreateCertWithNotBeforeAfter(t, privKey,
+					&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "new.example.com"}},
+					clock.Now(), clock.Now().Add(time.Hour*3),
+				),
+			}),
+		),
+		CurrentRevisionRequest: gen.CertificateRequest("something",
+			gen.SetCertificateRequestIssuer(issuerRef),
+			gen.SetCertificateRequestCSR(internaltest.MustGenerateCSRImpl(t, privKey,
+				gen.Certificate("something", gen.SetCertificateCommonName("new.example.com")))),
+		),
+	})
+	if !violationFound {
+		t.Fatal("expected a violation to be found")
+	}
+	if reason != policies.ChainInvalid {
+		t.Errorf("unexpected 'reason' exp=%s, got=%s", policies.ChainInvalid, reason)
+	}
+	expMessage := `Issued certificate does not chain to the trust anchors in Secret "trust-anchors": x509: certificate signed by unknown authority`
+	if message != expMessage {
+		t.Errorf("unexpected 'message' exp=%s, got=%s", expMessage, message)
+	}
+}
+
+func TestNewReadinessPolicyChainMaxChainDepth(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	privKey := internaltest.MustCreatePEMPrivateKey(t)
+	issuerRef := cmmeta.ObjectReference{Name: "testissuer", Kind: "IssuerKind", Group: "group.example.com"}
+
+	// a leaf certificate followed by two intermediates
+	chain := internaltest.MustCreateCertWithNotBeforeAfter(t, privKey,
+		&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "new.example.com"}},
+		clock.Now(), clock.Now().Add(time.Hour*3),
+	)
+	for _, name := range []string{"intermediate-1", "intermediate-2"} {
+		chain = append(chain, internaltest.MustCreateCertWithNotBeforeAfter(t, internaltest.MustCreatePEMPrivateKey(t),
+			&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: name, IsCA: true}},
+			clock.Now(), clock.Now().Add(time.Hour*3),
+		)...)
+	}
+	policyChain := NewReadinessPolicyChain(clock, 5*time.Minute, corelisters.NewSecretLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})), 2)
+
+	reason, message, violationFound := policyChain.Evaluate(policies.Input{
+		Certificate: gen.Certificate("something",
+			gen.SetCertificateCommonName("new.example.com"),
+			gen.SetCertificateIssuer(issuerRef),
+		),
+		Secret: gen.Secret("something",
+			gen.SetSecretAnnotations(map[string]string{
+				cmapi.IssuerNameAnnotationKey:  issuerRef.Name,
+				cmapi.IssuerKindAnnotationKey:  issuerRef.Kind,
+				cmapi.IssuerGroupAnnotationKey: issuerRef.Group,
+			}),
+			gen.SetSecretData(map[string][]byte{
+				corev1.TLSPrivateKeyKey: privKey,
+				corev1.TLSCertKey:       chain,
+			}),
+		),
+		CurrentRevisionRequest: gen.CertificateRequest("something",
+			gen.SetCertificateRequestIssuer(issuerRef),
+			gen.SetCertificateRequestCSR(internaltest.MustGenerateCSRImpl(t, privKey,
+				gen.Certificate("something", gen.SetCertificateCommonName("new.example.com")))),
+		),
+	})
+	if !violationFound {
+		t.Fatal("expected a violation to be found")
+	}
+	if reason != policies.ChainTooLong {
+		t.Errorf("unexpected 'reason' exp=%s, got=%s", policies.ChainTooLong, reason)
+	}
+	expMessage := "Issued certificate chain contains 3 certificates, more than the maximum of 2"
+	if message != expMessage {
+		t.Errorf("unexpected 'message' exp=%s, got=%s", expMessage, message)
+	}
+}
+
+func TestIssuedCertificateSummary(t *testing.T) {
+	names := func(n int, format string) []string {
+		var out []string
+		for i := 0; i < n; i++ {
+			out = append(out, fmt.Sprintf(format, i))
+		}
+		return out
+	}
+	x509Cert := func(dnsNames []string) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber:   big.NewInt(0xabc),
+			Subject:        pkix.Name{CommonName: "example.com", Organization: []string{"Example"}},
+			Issuer:         pkix.Name{CommonName: "Example CA"},
+			DNSNames:       dnsNames,
+			EmailAddresses: []string{"admin@example.com"},
+		}
+	}
+
+	tests := map[string]struct {
+		cert *x509.Certificate
+		exp  *cmapi.IssuedCertificateSummary
+	}{
+		"should summarise a certificate with fewer subject alternative names than the limit": {
+			cert: x509Cert(names(maxSummarySANs, "%d.example.com")),
+			exp: &cmapi.IssuedCertificateSummary{
+				SerialNumber:     "abc",
+				Subject:          "CN=example.com,O=Example",
+				IssuerCommonName: "Example CA",
+				DNSNames:         names(maxSummarySANs, "%d.example.com"),
+				EmailAddresses:   []string{"admin@example.com"},
+			},
+		},
+		"should truncate subject alternative names beyond the limit": {
+			cert: x509Cert(names(maxSummarySANs+10, "%d.example.com")),
+			exp: &cmapi.IssuedCertificateSummary{
+				SerialNumber:     "abc",
+				Subject:          "CN=example.com,O=Example",
+				IssuerCommonName: "Example CA",
+				DNSNames:         names(maxSummarySANs, "%d.example.com"),
+				EmailAddresses:   []string{"admin@example.com"},
+				Truncated:        true,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			summary := issuedCertificateSummary(test.cert)
+			if !reflect.DeepEqual(test.exp, summary) {
+				t.Errorf("unexpected summary exp=%+v, got=%+v", test.exp, summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requestmanager
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
+	"github.com/jetstack/cert-manager/pkg/controller/certificates"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+)
+
+// issuerNotFound returns true if the given issuer is a cert-manager issuer
+// that does not exist. Issuers of other API groups, issuers without a name,
+// which can never be created, and errors other than the issuer not existing
+// are left to be reported by the controllers that sign the
+// CertificateRequest.
+func (c *controller) issuerNotFound(issuerRef cmmeta.ObjectReference, namespace string) bool {
+	if c.issuerHelper == nil || issuerRef.Name == "" || (issuerRef.Group != "" && issuerRef.Group != certmanager.GroupName) {
+		return false
+	}
+	_, err := c.issuerHelper.GetGenericIssuer(issuerRef, namespace)
+	return apierrors.IsNotFound(err)
+}
+
+// issuerNotReady returns true if the given issuer is a cert-manager issuer
+// whose Ready condition is False. Issuers that have not reported a Ready
+// condition yet, e.g. as they have just been created, are not considered to
+// be not ready.
+func (c *controller) issuerNotReady(issuerRef cmmeta.ObjectReference, namespace string) bool {
+	if c.issuerHelper == nil {
+		return false
+	}
+	issuerObj, ok := certificates.CertManagerIssuer(c.issuerHelper, issuerRef, namespace)
+	return ok && apiutil.IssuerHasCondition(issuerObj, cmapi.IssuerCondition{
+		Type:   cmapi.IssuerConditionReady,
+		Status: cmmeta.ConditionFalse,
+	})
+}
+
+// availableIssuerRef returns the given issuer or, if it does not exist or is
+// not ready, the first of the fallback issuers after it in the Certificate's
+// list of issuers that is. If none of them are available, the last issuer in
+// the list is returned.
+func (c *controller) availableIssuerRef(crt *cmapi.Certificate, issuerRef cmmeta.ObjectReference) cmmeta.ObjectReference {
+	for c.issuerNotFound(issuerRef, crt.Namespace) || c.issuerNotReady(issuerRef, crt.Namespace) {
+		next, ok := certificates.NextIssuerRef(crt.Spec, issuerRef)
+		if !ok {
+			break
+		}
+		issuerRef = next
+	}
+	return issuerRef
+}
+
+// requestNotIssuableReason returns why the given CertificateRequest will not
+// be issued without intervention, or an empty string if it may still be
+// issued. This is the case if it has been denied or has failed, or if it is
+// in flight but its issuer does not exist or is not ready.
+func (c *controller) requestNotIssuableReason(req *cmapi.CertificateRequest, namespace string) string {
+	switch {
+	case apiutil.CertificateRequestIsDenied(req):
+		return cmapi.CertificateRequestReasonDenied
+	case certificateRequestFailed(req):
+		return cmapi.CertificateRequestReasonFailed
+	case !certificates.RequestInFlight(req):
+		return ""
+	case c.issuerNotFound(req.Spec.IssuerRef, namespace):
+		return reasonIssuerNotFound
+	case c.issuerNotReady(req.Spec.IssuerRef, namespace):
+		return reasonIssuerNotReady
+	}
+	return ""
+}
+
+// waitForIssuer schedules the Certificate to be processed again with
+// exponential back-off, as its issuer does not exist yet. The first time this
+// happens, an event is recorded and the reason of the Certificate's Issuing
+// condition is set to IssuerNotFound. No error is returned, so that
+// Certificates created before their issuer do not repeatedly log errors.
+func (c *controller) waitForIssuer(ctx context.Context, crt *cmapi.Certificate, issuerRef cmmeta.ObjectReference) error {
+	key, err := controllerpkg.KeyFunc(crt)
+	if err != nil {
+		return err
+	}
+	wait := c.issuerBackoff.When(key)
+	c.scheduledWorkQueue.Add(key, wait)
+
+	message := fmt.Sprintf("Waiting for referenced %s %q to be created", apiutil.IssuerKind(issuerRef), issuerRef.Name)
+	logf.FromContext(ctx).V(logf.DebugLevel).Info(message, "retryIn", wait)
+
+	if cond := apiutil.GetCertificateCondition(crt, cmapi.CertificateConditionIssuing); cond != nil && cond.Reason == reasonIssuerNotFound {
+		return nil
+	}
+
+	c.recorder.Event(crt, corev1.EventTypeWarning, reasonIssuerNotFound, message)
+	crt = crt.DeepCopy()
+	apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionTrue, reasonIssuerNotFound, message)
+	_, err = c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
+	return err
+}
@@ -20,12 +20,15 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,6 +39,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
 
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
@@ -45,15 +49,37 @@ import (
 	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates"
+	"github.com/jetstack/cert-manager/pkg/controller/certificates/internal/failurewebhook"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/scheduler"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/pkg/util/predicate"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 )
 
 const (
-	ControllerName      = "certificates-request-manager"
-	reasonRequestFailed = "RequestFailed"
-	reasonRequested     = "Requested"
+	ControllerName        = "certificates-request-manager"
+	reasonRequestFailed   = "RequestFailed"
+	reasonRequested       = "Requested"
+	reasonInvalidCSR      = "InvalidCSR"
+	reasonIssuanceTimeout = "IssuanceTimeout"
+	reasonRateLimited     = "RateLimited"
+	reasonStagingIssued   = "StagingIssued"
+	reasonCNOmitted       = "CommonNameOmitted"
+	reasonIssuerNotFound  = "IssuerNotFound"
+	reasonIssuerNotReady  = "IssuerNotReady"
+)
+
+const (
+	// RequestNamingRandom names CertificateRequests after their Certificate
+	// with a random suffix.
+	RequestNamingRandom = "random"
+
+	// RequestNamingRevision names CertificateRequests
+	// '<certificate>-<revision>'. If a CertificateRequest with that name
+	// already exists, a random suffix is added to the name.
+	RequestNamingRevision = "revision"
 )
 
 var (
@@ -66,6 +92,46 @@ type controller struct {
 	secretLister             corelisters.SecretLister
 	client                   cmclient.Interface
 	recorder                 record.EventRecorder
+	clock                    clock.Clock
+
+	// scheduledWorkQueue is used to re-queue Certificates once their
+	// issuance timeout has elapsed
+	scheduledWorkQueue scheduler.ScheduledWorkQueue
+
+	// issuerHelper is used to read the issuer referenced by a Certificate,
+	// so that the generation of the issuer can be recorded on the
+	// CertificateRequests created for it
+	issuerHelper issuer.Helper
+
+	// issuerBackoff is used to back-off re-queuing Certificates whose issuer
+	// does not exist yet
+	issuerBackoff workqueue.RateLimiter
+
+	// tracer is used to trace the creation of CertificateRequests
+	tracer trace.Tracer
+
+	// rateLimiter, if set, limits the rate at which CertificateRequests are
+	// created in each namespace
+	rateLimiter *certificates.RateLimiter
+
+	// nameByRevision, if true, names CertificateRequests after the revision
+	// of the Certificate they are created for rather than with a random
+	// suffix
+	nameByRevision bool
+
+	// requesterAnnotations are the annotations not copied from a Certificate
+	// onto its CertificateRequests, as they would attribute the request to
+	// another user
+	requesterAnnotations []string
+
+	// failureNotifier, if not nil, is notified when issuance fails because
+	// it did not complete within the issuance timeout
+	failureNotifier *failurewebhook.Notifier
+
+	// specSettle, if set, records changes to the spec of each Certificate
+	// so that a CertificateRequest that no longer matches the spec is only
+	// replaced once the spec has been unchanged for the configured period
+	specSettle *certificates.SpecSettleTracker
 }
 
 func NewController(
@@ -74,6 +140,7 @@ func NewController(
 	factory informers.SharedInformerFactory,
 	cmFactory cminformers.SharedInformerFactory,
 	recorder record.EventRecorder,
+	clock clock.Clock,
 ) (*controller, workqueue.RateLimitingInterface, []cache.InformerSynced) {
 	// create a queue used to queue up items to be processed
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Second*1, time.Second*30), ControllerName)
@@ -96,6 +163,11 @@ func NewController(
 			predicate.ResourceOwnerOf,
 		),
 	})
+	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
+		// Trigger reconciles on changes to the Secret named `spec.csrSecretRef.name`
+		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
+			predicate.ExtractResourceName(predicate.CertificateCSRSecretName)),
+	})
 
 	// build a list of InformerSynced functions that will be returned by the Register method.
 	// the controller will only begin processing items once all of these informers have synced.
@@ -111,6 +183,10 @@ func NewController(
 		secretLister:             secretsInformer.Lister(),
 		client:                   client,
 		recorder:                 recorder,
+		clock:                    clock,
+		scheduledWorkQueue:       scheduler.NewScheduledWorkQueue(clock, queue.Add),
+		issuerBackoff:            workqueue.NewItemExponentialFailureRateLimiter(time.Second*5, time.Minute*5),
+		tracer:                   tracing.Tracer(nil),
 	}, queue, mustSync
 }
 
@@ -127,12 +203,19 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	crt, err := c.certificateLister.Certificates(namespace).Get(name)
 	if apierrors.IsNotFound(err) {
 		log.Error(err, "certificate not found for key")
+		if c.specSettle != nil {
+			c.specSettle.Forget(key)
+		}
 		return nil
 	}
 	if err != nil {
 		return err
 	}
 
+	// The generation is recorded before checking whether an issuance is in
+	// progress, so that edits made before the issuance was triggered, which
+	// the trigger controller has already waited to settle, do not defer it.
+	settleDelay, unsettled := c.specSettleDelay(key, crt)
 	if !apiutil.CertificateHasCondition(crt, cmapi.CertificateCondition{
 		Type:   cmapi.CertificateConditionIssuing,
 		Status: cmmeta.ConditionTrue,
@@ -140,27 +223,55 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		return nil
 	}
 
-	// Check for and fetch the 'status.nextPrivateKeySecretName' secret
-	if crt.Status.NextPrivateKeySecretName == nil {
-		log.V(logf.DebugLevel).Info("status.nextPrivateKeySecretName not yet set, waiting for keymanager before processing certificate")
-		return nil
-	}
-	nextPrivateKeySecret, err := c.secretLister.Secrets(crt.Namespace).Get(*crt.Status.NextPrivateKeySecretName)
-	if apierrors.IsNotFound(err) {
-		log.V(logf.DebugLevel).Info("nextPrivateKeySecretName Secret resource does not exist, waiting for keymanager to create it before continuing")
-		return nil
-	}
-	if err != nil {
-		return err
-	}
-	if nextPrivateKeySecret.Data == nil || len(nextPrivateKeySecret.Data[corev1.TLSPrivateKeyKey]) == 0 {
-		log.V(logf.DebugLevel).Info("Next private key secret does not contain any valid data, waiting for keymanager before processing certificate")
-		return nil
-	}
-	pk, err := pki.DecodePrivateKeyBytes(nextPrivateKeySecret.Data[corev1.TLSPrivateKeyKey])
-	if err != nil {
-		log.Error(err, "Failed to decode next private key secret data, waiting for keymanager before processing certificate")
-		return nil
+	ctx, span := c.tracer.Start(ctx, ControllerName, trace.WithAttributes(
+		attribute.String("namespace", crt.Namespace),
+		attribute.String("name", crt.Name),
+	))
+	defer span.End()
+
+	var (
+		// pk is the next private key, and is nil if the Certificate uses a
+		// user supplied CSR.
+		pk                       crypto.Signer
+		publicKey                crypto.PublicKey
+		suppliedCSR              []byte
+		nextPrivateKeySecretName string
+	)
+	if crt.Spec.CSRSecretRef != nil {
+		var x509CSR *x509.CertificateRequest
+		suppliedCSR, x509CSR, err = c.fetchSuppliedCSR(crt)
+		if err != nil {
+			return err
+		}
+		if x509CSR == nil {
+			return nil
+		}
+		publicKey = x509CSR.PublicKey
+	} else {
+		// Check for and fetch the 'status.nextPrivateKeySecretName' secret
+		if crt.Status.NextPrivateKeySecretName == nil {
+			log.V(logf.DebugLevel).Info("status.nextPrivateKeySecretName not yet set, waiting for keymanager before processing certificate")
+			return nil
+		}
+		nextPrivateKeySecret, err := c.secretLister.Secrets(crt.Namespace).Get(*crt.Status.NextPrivateKeySecretName)
+		if apierrors.IsNotFound(err) {
+			log.V(logf.DebugLevel).Info("nextPrivateKeySecretName Secret resource does not exist, waiting for keymanager to create it before continuing")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if nextPrivateKeySecret.Data == nil || len(nextPrivateKeySecret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+			log.V(logf.DebugLevel).Info("Next private key secret does not contain any valid data, waiting for keymanager before processing certificate")
+			return nil
+		}
+		pk, err = pki.DecodePrivateKeyBytes(nextPrivateKeySecret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			log.Error(err, "Failed to decode next private key secret data, waiting for keymanager before processing certificate")
+			return nil
+		}
+		publicKey = pk.Public()
+		nextPrivateKeySecretName = nextPrivateKeySecret.Name
 	}
 
 	// Discover all 'owned' CertificateRequests
@@ -186,11 +297,27 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		return err
 	}
 
-	requests, err = c.deleteRequestsNotMatchingSpec(ctx, crt, pk.Public(), requests...)
+	// Replacing a CertificateRequest that no longer matches the spec is
+	// deferred until the spec has settled, so that several edits made during
+	// an issuance result in a single new CertificateRequest.
+	if unsettled && c.anyRequestNotMatchingSpec(crt, requests) {
+		log.V(logf.InfoLevel).Info("Deferring replacement of CertificateRequest until the Certificate's spec has stopped changing", "delay", settleDelay)
+		c.scheduledWorkQueue.Add(key, settleDelay)
+		return nil
+	}
+
+	requests, err = c.deleteRequestsNotMatchingSpec(ctx, crt, publicKey, requests...)
 	if err != nil {
 		return err
 	}
 
+	if suppliedCSR != nil {
+		requests, err = c.deleteRequestsNotMatchingCSR(ctx, suppliedCSR, requests...)
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(requests) > 1 {
 		// TODO: we should handle this case better, but for now do nothing to
 		//  avoid getting into loops where we keep creating multiple requests
@@ -200,12 +327,169 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	}
 
 	if len(requests) == 1 {
+		req := requests[0]
+		// If the CertificateRequest was denied or has failed and its issuer has
+		// since been changed, replace it with a fresh request so that the
+		// change to the issuer takes effect.
+		if (apiutil.CertificateRequestIsDenied(req) || certificateRequestFailed(req)) && certificates.RequestPredatesIssuerGeneration(c.issuerHelper, req) {
+			log := logf.WithRelatedResource(log, req)
+			log.V(logf.InfoLevel).Info("CertificateRequest was not successful and its issuer has changed since it was created, deleting CertificateRequest and creating a new one")
+			if err := c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+			return c.createNewCertificateRequest(ctx, crt, pk, suppliedCSR, req.Spec.IssuerRef, nextRevision, nextPrivateKeySecretName)
+		}
+
+		// If the CertificateRequest was denied before the Certificate spec was
+		// last changed, replace it with a fresh request. Requests denied for
+		// the current generation are only replaced by a request to a fallback
+		// issuer, so we do not keep creating requests that will be denied
+		// again.
+		if apiutil.CertificateRequestIsDenied(req) && certificates.RequestPredatesGeneration(req, crt) {
+			log := logf.WithRelatedResource(log, req)
+			log.V(logf.InfoLevel).Info("CertificateRequest was denied for an older generation of the Certificate, deleting CertificateRequest and creating a new one")
+			if err := c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+			return c.createNewCertificateRequest(ctx, crt, pk, suppliedCSR, c.initialIssuerRef(crt), nextRevision, nextPrivateKeySecretName)
+		}
+
+		// Once the staging issuer has issued the certificate, replace the
+		// staging request with a request to the Certificate's issuer.
+		if certificates.IsStagingRequest(req) && apiutil.CertificateRequestHasCondition(req, cmapi.CertificateRequestCondition{
+			Type:   cmapi.CertificateRequestConditionReady,
+			Status: cmmeta.ConditionTrue,
+			Reason: cmapi.CertificateRequestReasonIssued,
+		}) {
+			log := logf.WithRelatedResource(log, req)
+			log.V(logf.InfoLevel).Info("CertificateRequest was issued by the staging issuer, deleting CertificateRequest and requesting the certificate from the Certificate's issuer")
+			message := fmt.Sprintf("Staging issuer %q issued CertificateRequest %q, requesting the certificate from issuer %q", req.Spec.IssuerRef.Name, req.Name, crt.Spec.IssuerRef.Name)
+			// Record the staging issuance on the Certificate before deleting
+			// the staging request, so that it is not lost along with it.
+			crt = crt.DeepCopy()
+			apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionStagingIssued, cmmeta.ConditionTrue, reasonStagingIssued, message)
+			updated, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
+			if err != nil {
+				return err
+			}
+			crt = updated
+			if err := c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil {
+				return err
+			}
+			c.recorder.Event(crt, corev1.EventTypeNormal, reasonStagingIssued, message)
+			return c.createNewCertificateRequest(ctx, crt, pk, suppliedCSR, crt.Spec.IssuerRef, nextRevision, nextPrivateKeySecretName)
+		}
+
+		// If the CertificateRequest will not be issued and a fallback issuer
+		// remains, replace it with a request to the next issuer in the list.
+		if reason := c.requestNotIssuableReason(req, crt.Namespace); reason != "" {
+			if issuerRef, ok := certificates.NextIssuerRef(crt.Spec, req.Spec.IssuerRef); ok {
+				log := logf.WithRelatedResource(log, req)
+				log.V(logf.InfoLevel).Info("CertificateRequest will not be issued, deleting CertificateRequest and retrying with the next issuer", "reason", reason, "issuer", issuerRef.Name)
+				if err := c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil {
+					return err
+				}
+				return c.createNewCertificateRequest(ctx, crt, pk, suppliedCSR, issuerRef, nextRevision, nextPrivateKeySecretName)
+			}
+		}
+
+		// If the CertificateRequest has not completed within the issuance
+		// timeout, fail the Certificate so that issuance is retried following
+		// the usual back-off for failed Certificates.
+		if crt.Spec.IssuanceTimeout != nil && certificates.RequestInFlight(req) {
+			remaining := req.CreationTimestamp.Add(crt.Spec.IssuanceTimeout.Duration).Sub(c.clock.Now())
+			if remaining <= 0 {
+				return c.failIssuanceTimeout(ctx, crt, req)
+			}
+			log.V(logf.DebugLevel).Info("CertificateRequest is in progress, scheduling check of the issuance timeout", "remaining", remaining.String())
+			c.scheduledWorkQueue.Add(key, remaining)
+			return nil
+		}
+
 		// Nothing to do as we've already verified that the CertificateRequest
 		// is up to date above.
 		return nil
 	}
 
-	return c.createNewCertificateRequest(ctx, crt, pk, nextRevision, nextPrivateKeySecret.Name)
+	return c.createNewCertificateRequest(ctx, crt, pk, suppliedCSR, c.initialIssuerRef(crt), nextRevision, nextPrivateKeySecretName)
+}
+
+// initialIssuerRef returns the issuer that the first CertificateRequest for a
+// revision of the given Certificate is created for. This is the staging
+// issuer of the Certificate if it has one, otherwise spec.issuerRef.
+func (c *controller) initialIssuerRef(crt *cmapi.Certificate) cmmeta.ObjectReference {
+	if ref, ok := certificates.StagingIssuerRef(c.issuerHelper, crt); ok {
+		return ref
+	}
+	return crt.Spec.IssuerRef
+}
+
+// fetchSuppliedCSR returns the PEM encoded CSR referenced by the given
+// Certificate's spec.csrSecretRef, along with its decoded form. If the CSR is
+// invalid, an event is recorded and a nil CSR is returned as the request cannot
+// be used until the user updates it.
+func (c *controller) fetchSuppliedCSR(crt *cmapi.Certificate) ([]byte, *x509.CertificateRequest, error) {
+	ref := crt.Spec.CSRSecretRef
+	key := ref.Key
+	if key == "" {
+		key = cmmeta.TLSCSRKey
+	}
+
+	secret, err := c.secretLister.Secrets(crt.Namespace).Get(ref.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching CSR Secret %q: %w", ref.Name, err)
+	}
+	csrPEM := secret.Data[key]
+	if len(csrPEM) == 0 {
+		return nil, nil, fmt.Errorf("CSR Secret %q contains no data for key %q", ref.Name, key)
+	}
+
+	x509CSR, err := pki.DecodeX509CertificateRequestBytes(csrPEM)
+	if err == nil {
+		err = x509CSR.CheckSignature()
+	}
+	if err != nil {
+		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonInvalidCSR, "Supplied CSR in Secret %q is invalid: %v", ref.Name, err)
+		return nil, nil, nil
+	}
+
+	return csrPEM, x509CSR, nil
+}
+
+// failIssuanceTimeout deletes the given CertificateRequest as it has not
+// completed within the Certificate's issuance timeout, and marks the Issuing
+// condition of the Certificate as failed. The trigger controller will retry
+// issuance once the failure back-off has elapsed.
+func (c *controller) failIssuanceTimeout(ctx context.Context, crt *cmapi.Certificate, req *cmapi.CertificateRequest) error {
+	log := logf.WithRelatedResource(logf.FromContext(ctx), req)
+	log.V(logf.InfoLevel).Info("CertificateRequest did not complete within the issuance timeout, deleting CertificateRequest and marking the Certificate as failed")
+	if err := c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Issuance did not complete within the issuance timeout of %s and will be retried", crt.Spec.IssuanceTimeout.Duration)
+
+	crt = crt.DeepCopy()
+	nowTime := metav1.NewTime(c.clock.Now())
+	crt.Status.LastFailureTime = &nowTime
+	apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, message)
+	certificates.RecordReconciliation(crt, ControllerName, c.clock.Now())
+	if _, err := c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	c.recorder.Event(crt, corev1.EventTypeWarning, reasonIssuanceTimeout, message)
+	if c.failureNotifier != nil {
+		c.failureNotifier.Notify(crt, reasonIssuanceTimeout, message)
+	}
+	return nil
+}
+
+// certificateRequestFailed returns true if the given CertificateRequest has a
+// Ready condition with reason Failed.
+func certificateRequestFailed(req *cmapi.CertificateRequest) bool {
+	cond := apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionReady)
+	return cond != nil && cond.Reason == cmapi.CertificateRequestReasonFailed
 }
 
 func (c *controller) deleteRequestsWithoutRevision(ctx context.Context, reqs ...*cmapi.CertificateRequest) ([]*cmapi.CertificateRequest, error) {
@@ -254,12 +538,35 @@ func requestsWithRevision(reqs []*cmapi.CertificateRequest, revision int) ([]*cm
 	return remaining, nil
 }
 
+// specSettleDelay returns how long replacing the CertificateRequests of the
+// Certificate must be deferred for until its spec has settled. False is
+// returned if debouncing is disabled or the spec has settled.
+func (c *controller) specSettleDelay(key string, crt *cmapi.Certificate) (time.Duration, bool) {
+	if c.specSettle == nil {
+		return 0, false
+	}
+	return c.specSettle.Delay(key, crt)
+}
+
+// anyRequestNotMatchingSpec returns true if any of the given
+// CertificateRequests would be deleted by deleteRequestsNotMatchingSpec
+// because it does not match the spec of the Certificate.
+func (c *controller) anyRequestNotMatchingSpec(crt *cmapi.Certificate, reqs []*cmapi.CertificateRequest) bool {
+	for _, req := range reqs {
+		violations, err := certificates.RequestMatchesCertificate(c.issuerHelper, req, crt)
+		if err != nil || len(violations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *controller) deleteRequestsNotMatchingSpec(ctx context.Context, crt *cmapi.Certificate, publicKey crypto.PublicKey, reqs ...*cmapi.CertificateRequest) ([]*cmapi.CertificateRequest, error) {
 	log := logf.FromContext(ctx)
 	var remaining []*cmapi.CertificateRequest
 	for _, req := range reqs {
 		log := logf.WithRelatedResource(log, req)
-		violations, err := certificates.RequestMatchesSpec(req, crt.Spec)
+		violations, err := certificates.RequestMatchesCertificate(c.issuerHelper, req, crt)
 		if err != nil {
 			log.Error(err, "Failed to check if CertificateRequest matches spec, deleting CertificateRequest")
 			if err := c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil {
@@ -295,54 +602,242 @@ func (c *controller) deleteRequestsNotMatchingSpec(ctx context.Context, crt *cma
 	return remaining, nil
 }
 
-func (c *controller) createNewCertificateRequest(ctx context.Context, crt *cmapi.Certificate, pk crypto.Signer, nextRevision int, nextPrivateKeySecretName string) error {
+// deleteRequestsNotMatchingCSR deletes any of the given CertificateRequests
+// that were not created from the given user supplied CSR.
+func (c *controller) deleteRequestsNotMatchingCSR(ctx context.Context, csrPEM []byte, reqs ...*cmapi.CertificateRequest) ([]*cmapi.CertificateRequest, error) {
 	log := logf.FromContext(ctx)
-	x509CSR, err := pki.GenerateCSR(crt)
-	if err != nil {
-		log.Error(err, "Failed to generate CSR - will not retry")
-		return nil
+	var remaining []*cmapi.CertificateRequest
+	for _, req := range reqs {
+		if !bytes.Equal(req.Spec.Request, csrPEM) {
+			logf.WithRelatedResource(log, req).V(logf.DebugLevel).Info("CertificateRequest does not contain the supplied CSR, deleting CertificateRequest")
+			if err := c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		remaining = append(remaining, req)
 	}
-	csrDER, err := pki.EncodeCSR(x509CSR, pk)
-	if err != nil {
-		return err
+	return remaining, nil
+}
+
+// findExistingRequest returns an owned CertificateRequest for the given
+// revision that is identical to the one that would otherwise be created, or
+// nil if there is none. If CertificateRequests are named after the revision
+// the request is fetched from the apiserver by name, so that a request
+// created just before the controller was restarted is found even if it has
+// not yet been observed by the informer cache. Otherwise the informer cache
+// is searched.
+func (c *controller) findExistingRequest(ctx context.Context, crt *cmapi.Certificate, pk crypto.Signer, suppliedCSR []byte, issuerRef cmmeta.ObjectReference, revision int) (*cmapi.CertificateRequest, error) {
+	var reqs []*cmapi.CertificateRequest
+	if c.nameByRevision {
+		req, err := c.client.CertmanagerV1().CertificateRequests(crt.Namespace).Get(ctx, requestNameForRevision(crt, revision), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		reqs = []*cmapi.CertificateRequest{req}
+	} else {
+		var err error
+		reqs, err = certificates.ListCertificateRequestsMatchingPredicates(c.certificateRequestLister.CertificateRequests(crt.Namespace), labels.Everything(),
+			predicate.ResourceOwnedBy(crt), predicate.CertificateRequestRevision(revision))
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	csrPEM := bytes.NewBuffer([]byte{})
-	err = pem.Encode(csrPEM, &pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+	isOwned := predicate.ResourceOwnedBy(crt)
+	isRevision := predicate.CertificateRequestRevision(revision)
+	for _, req := range reqs {
+		if req.DeletionTimestamp != nil || !isOwned(req) || !isRevision(req) {
+			continue
+		}
+		// Requests that will never be issued are not reused, as they are
+		// only replaced once they have been deleted and the informer cache
+		// may not yet have observed the deletion.
+		if apiutil.CertificateRequestIsDenied(req) || certificateRequestFailed(req) {
+			continue
+		}
+		if req.Spec.IssuerRef != issuerRef {
+			continue
+		}
+		if violations, err := certificates.RequestMatchesCertificate(c.issuerHelper, req, crt); err != nil || len(violations) > 0 {
+			continue
+		}
+
+		if suppliedCSR != nil {
+			if !bytes.Equal(req.Spec.Request, suppliedCSR) {
+				continue
+			}
+			return req, nil
+		}
+
+		x509Req, err := pki.DecodeX509CertificateRequestBytes(req.Spec.Request)
+		if err != nil {
+			continue
+		}
+		matches, err := pki.PublicKeyMatchesCSR(pk.Public(), x509Req)
+		if err != nil || !matches {
+			continue
+		}
+		return req, nil
+	}
+
+	return nil, nil
+}
+
+// createNewCertificateRequest creates a CertificateRequest for the given
+// Certificate. If suppliedCSR is set it is used as the request, otherwise a
+// CSR is generated from the Certificate spec and signed by pk. If an identical
+// CertificateRequest already exists for the revision it is reused instead.
+func (c *controller) createNewCertificateRequest(ctx context.Context, crt *cmapi.Certificate, pk crypto.Signer, suppliedCSR []byte, issuerRef cmmeta.ObjectReference, nextRevision int, nextPrivateKeySecretName string) error {
+	log := logf.FromContext(ctx)
+
+	issuerRef = c.availableIssuerRef(crt, issuerRef)
+	existing, err := c.findExistingRequest(ctx, crt, pk, suppliedCSR, issuerRef, nextRevision)
 	if err != nil {
 		return err
 	}
+	if existing != nil {
+		logf.WithRelatedResource(log, existing).V(logf.InfoLevel).Info("An identical CertificateRequest already exists for this revision, reusing it instead of creating a new one")
+		c.recorder.Eventf(crt, corev1.EventTypeNormal, reasonRequested, "Reusing existing CertificateRequest resource %q", existing.Name)
+		return nil
+	}
+
+	if c.issuerNotFound(issuerRef, crt.Namespace) {
+		return c.waitForIssuer(ctx, crt, issuerRef)
+	}
+	if key, err := controllerpkg.KeyFunc(crt); err == nil {
+		c.issuerBackoff.Forget(key)
+	}
+
+	// requested is set once the CertificateRequest has been created, so that
+	// the rate limit token is returned if it could not be.
+	requested := false
+	if c.rateLimiter != nil {
+		cancel, wait := c.rateLimiter.Reserve(crt.Namespace)
+		if wait > 0 {
+			key, err := controllerpkg.KeyFunc(crt)
+			if err != nil {
+				return err
+			}
+			message := fmt.Sprintf("Creation of CertificateRequests in namespace %q is being rate limited, retrying in %s", crt.Namespace, wait)
+			log.V(logf.InfoLevel).Info(message)
+			c.recorder.Event(crt, corev1.EventTypeWarning, reasonRateLimited, message)
+			c.scheduledWorkQueue.Add(key, wait)
+			return nil
+		}
+		defer func() {
+			if !requested {
+				cancel()
+			}
+		}()
+	}
+
+	csrPEM := suppliedCSR
+	if csrPEM == nil {
+		x509CSR, err := pki.GenerateCSR(crt)
+		if err != nil {
+			log.Error(err, "Failed to generate CSR - will not retry")
+			return nil
+		}
+		if _, omitted := pki.CommonNameForCertificate(crt); omitted {
+			c.recorder.Eventf(crt, corev1.EventTypeNormal, reasonCNOmitted,
+				"The first DNS name %q is longer than %d characters so cannot be used as the Common Name, it is only included in the subjectAltName extension",
+				crt.Spec.DNSNames[0], pki.MaxCommonNameLength)
+		}
+		csrDER, err := pki.EncodeCSR(x509CSR, pk)
+		if err != nil {
+			return err
+		}
+
+		csrPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+	}
 
 	annotations := make(map[string]string)
 	for k, v := range crt.Annotations {
 		annotations[k] = v
 	}
+	for _, k := range c.requesterAnnotations {
+		delete(annotations, k)
+	}
+	delete(annotations, cmapi.TraceContextAnnotationKey)
+	// Only requests created for the staging issuer below are staging
+	// requests, whatever the annotations of the Certificate.
+	delete(annotations, cmapi.CertificateRequestStagingAnnotationKey)
 	annotations[cmapi.CertificateRequestRevisionAnnotationKey] = strconv.Itoa(nextRevision)
-	annotations[cmapi.CertificateRequestPrivateKeyAnnotationKey] = nextPrivateKeySecretName
+	annotations[cmapi.CertificateRequestCertificateGenerationAnnotationKey] = strconv.FormatInt(crt.Generation, 10)
+	if crt.Spec.RenewBefore != nil {
+		annotations[cmapi.CertificateRequestRenewBeforeAnnotationKey] = crt.Spec.RenewBefore.Duration.String()
+	} else {
+		delete(annotations, cmapi.CertificateRequestRenewBeforeAnnotationKey)
+	}
+	if c.issuerHelper != nil {
+		if issuerObj, ok := certificates.CertManagerIssuer(c.issuerHelper, issuerRef, crt.Namespace); ok {
+			annotations[cmapi.CertificateRequestIssuerGenerationAnnotationKey] = strconv.FormatInt(issuerObj.GetObjectMeta().Generation, 10)
+			if uid := issuerObj.GetObjectMeta().UID; uid != "" {
+				annotations[cmapi.CertificateRequestIssuerUIDAnnotationKey] = string(uid)
+			}
+		}
+	}
+	if nextPrivateKeySecretName != "" {
+		annotations[cmapi.CertificateRequestPrivateKeyAnnotationKey] = nextPrivateKeySecretName
+	}
 	annotations[cmapi.CertificateNameKey] = crt.Name
+	if ref, ok := certificates.StagingIssuerRef(c.issuerHelper, crt); ok && ref == issuerRef {
+		annotations[cmapi.CertificateRequestStagingAnnotationKey] = "true"
+	}
 
 	cr := &cmapi.CertificateRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:       crt.Namespace,
-			GenerateName:    apiutil.DNSSafeShortenTo52Characters(crt.Name) + "-",
+			GenerateName:    requestNamePrefix(crt),
 			Annotations:     annotations,
 			Labels:          crt.Labels,
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(crt, certificateGvk)},
 		},
 		Spec: cmapi.CertificateRequestSpec{
-			Duration:  crt.Spec.Duration,
-			IssuerRef: crt.Spec.IssuerRef,
-			Request:   csrPEM.Bytes(),
-			IsCA:      crt.Spec.IsCA,
-			Usages:    crt.Spec.Usages,
+			Duration:           crt.Spec.Duration,
+			IssuerRef:          issuerRef,
+			Request:            csrPEM,
+			IsCA:               crt.Spec.IsCA,
+			MaxPathLen:         crt.Spec.MaxPathLen,
+			SignatureAlgorithm: crt.Spec.SignatureAlgorithm,
+			Usages:             crt.Spec.Usages,
 		},
 	}
 
-	cr, err = c.client.CertmanagerV1().CertificateRequests(cr.Namespace).Create(ctx, cr, metav1.CreateOptions{})
+	// The requested notBefore only applies to the first issuance, as reusing
+	// it for renewals would issue the same, expiring, validity window again.
+	if crt.Status.Revision == nil {
+		cr.Spec.NotBefore = crt.Spec.NotBefore
+	}
+
+	// Record the trace context on the CertificateRequest so that the spans
+	// of the controllers that sign it and store the signed certificate are
+	// children of this span.
+	tracing.AnnotateObject(ctx, cr)
+
+	if c.nameByRevision {
+		cr.Name = requestNameForRevision(crt, nextRevision)
+		cr.GenerateName = ""
+	}
+	created, err := c.client.CertmanagerV1().CertificateRequests(cr.Namespace).Create(ctx, cr, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) && cr.Name != "" {
+		// The name may still be in use by a CertificateRequest for the same
+		// revision that is being deleted, so fall back to a unique name.
+		log.V(logf.InfoLevel).Info("A CertificateRequest with the same name already exists, adding a random suffix to the name", "name", cr.Name)
+		cr.GenerateName = cr.Name + "-"
+		cr.Name = ""
+		created, err = c.client.CertmanagerV1().CertificateRequests(cr.Namespace).Create(ctx, cr, metav1.CreateOptions{})
+	}
 	if err != nil {
 		c.recorder.Eventf(crt, corev1.EventTypeWarning, reasonRequestFailed, "Failed to create CertificateRequest: "+err.Error())
 		return err
 	}
+	requested = true
+	cr = created
 	c.recorder.Eventf(crt, corev1.EventTypeNormal, reasonRequested, "Created new CertificateRequest resource %q", cr.Name)
 	if err := c.waitForCertificateRequestToExist(cr.Namespace, cr.Name); err != nil {
 		return fmt.Errorf("failed whilst waiting for CertificateRequest to exist - this may indicate an apiserver running slowly. Request will be retried")
@@ -350,6 +845,19 @@ func (c *controller) createNewCertificateRequest(ctx context.Context, crt *cmapi
 	return nil
 }
 
+// requestNamePrefix returns the prefix of the names of CertificateRequests
+// created for the given Certificate.
+func requestNamePrefix(crt *cmapi.Certificate) string {
+	return apiutil.DNSSafeShortenTo52Characters(crt.Name) + "-"
+}
+
+// requestNameForRevision returns the name of the CertificateRequest for the
+// given revision of the Certificate when requests are named after the
+// revision.
+func requestNameForRevision(crt *cmapi.Certificate, revision int) string {
+	return requestNamePrefix(crt) + strconv.Itoa(revision)
+}
+
 func (c *controller) waitForCertificateRequestToExist(namespace, name string) error {
 	return wait.Poll(time.Millisecond*100, time.Second*5, func() (bool, error) {
 		_, err := c.certificateRequestLister.CertificateRequests(namespace).Get(name)
@@ -378,7 +886,28 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.KubeSharedInformerFactory,
 		ctx.SharedInformerFactory,
 		ctx.Recorder,
+		ctx.Clock,
 	)
+	// When an Issuer or ClusterIssuer changes, enqueue the Certificates that
+	// reference it so that Certificates waiting for their issuer to be
+	// created are processed as soon as it exists.
+	certificates.EnqueueCertificatesForIssuers(ctx, log, queue, ctrl.certificateLister)
+	issuerHelper, issuerSynced := certificates.NewIssuerHelper(ctx)
+	ctrl.issuerHelper = issuerHelper
+	ctrl.tracer = tracing.Tracer(ctx.TracerProvider)
+	if limit := ctx.CertificateOptions.IssuanceRateLimit; limit > 0 {
+		ctrl.rateLimiter = certificates.NewRateLimiter(ctx.Clock, limit/time.Hour.Seconds(), ctx.CertificateOptions.IssuanceRateBurst)
+	}
+	ctrl.nameByRevision = ctx.CertificateOptions.RequestNaming == RequestNamingRevision
+	ctrl.requesterAnnotations = ctx.CertificateOptions.RequesterAnnotations
+	if debounce := ctx.CertificateOptions.SpecChangeDebounce; debounce > 0 {
+		ctrl.specSettle = certificates.NewSpecSettleTracker(ctx.Clock, debounce)
+	}
+	if url := ctx.CertificateOptions.IssuanceFailureWebhookURL; url != "" {
+		ctrl.failureNotifier = failurewebhook.New(log, url, ctx.Metrics)
+		ctrl.failureNotifier.Start(ctx.RootContext)
+	}
+	mustSync = append(mustSync, issuerSynced...)
 	c.controller = ctrl
 
 	return queue, mustSync, nil
@@ -18,20 +18,30 @@ package requestmanager
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/kr/pretty"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
+	fakeclock "k8s.io/utils/clock/testing"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	schedulertest "github.com/jetstack/cert-manager/pkg/scheduler/test"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
@@ -60,6 +70,7 @@ func relaxedCertificateRequestMatcher(l coretesting.Action, r coretesting.Action
 }
 
 func TestProcessItem(t *testing.T) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
 	bundle1 := mustCreateCryptoBundle(t, &cmapi.Certificate{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "testns",
@@ -76,6 +87,21 @@ func TestProcessItem(t *testing.T) {
 		},
 		Spec: cmapi.CertificateSpec{CommonName: "test-bundle-2"}},
 	)
+	// suppliedCSRRequest is the CertificateRequest expected to be created for
+	// a Certificate that uses bundle1's CSR as a supplied CSR.
+	suppliedCSRRequest := gen.CertificateRequestFrom(bundle1.certificateRequest,
+		gen.SetCertificateRequestCSR(bundle1.csrBytes),
+		gen.SetCertificateRequestAnnotations(map[string]string{
+			cmapi.CertificateRequestRevisionAnnotationKey: "1",
+		}),
+	)
+	delete(suppliedCSRRequest.Annotations, cmapi.CertificateRequestPrivateKeyAnnotationKey)
+	requestedNotBefore := metav1.NewTime(fixedClock.Now().Add(time.Hour).Truncate(time.Second))
+	setCertificateSpecNotBefore := func(notBefore metav1.Time) gen.CertificateModifier {
+		return func(crt *cmapi.Certificate) {
+			crt.Spec.NotBefore = &notBefore
+		}
+	}
 	tests := map[string]struct {
 		// key that should be passed to ProcessItem.
 		// if not set, the 'namespace/name' of the 'Certificate' field will be used.
@@ -88,6 +114,9 @@ func TestProcessItem(t *testing.T) {
 
 		secrets []runtime.Object
 
+		// issuers, if set, will exist in the apiserver before the test is run.
+		issuers []runtime.Object
+
 		// Request, if set, will exist in the apiserver before the test is run.
 		requests []runtime.Object
 
@@ -169,6 +198,160 @@ func TestProcessItem(t *testing.T) {
 					)), relaxedCertificateRequestMatcher),
 			},
 		},
+		"create a CertificateRequest recording the renewBefore of the Certificate": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: bundle1.certificate.Namespace, Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateRenewBefore(time.Hour*2),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:  "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:    "1",
+							cmapi.CertificateRequestRenewBeforeAnnotationKey: "2h0m0s",
+						}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"create a CertificateRequest with the requested notBefore for the first issuance": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: bundle1.certificate.Namespace, Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				setCertificateSpecNotBefore(requestedNotBefore),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestNotBefore(requestedNotBefore),
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+						}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"create a CertificateRequest without the requested notBefore for a renewal": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: bundle1.certificate.Namespace, Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				setCertificateSpecNotBefore(requestedNotBefore),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateRevision(1),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:   "2",
+						}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"create a CertificateRequest from the supplied CSR without using a private key": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: bundle1.certificate.Namespace, Name: "csr"},
+					Data:       map[string][]byte{cmmeta.TLSCSRKey: bundle1.csrBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateCommonName(""),
+				gen.SetCertificateCSRSecretRef("csr", ""),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					suppliedCSRRequest)),
+			},
+		},
+		"delete a CertificateRequest that was not created from the supplied CSR and create a new one": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: bundle1.certificate.Namespace, Name: "csr"},
+					Data:       map[string][]byte{"csr.pem": bundle1.csrBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateCommonName(""),
+				gen.SetCertificateCSRSecretRef("csr", "csr.pem"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle2.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestRevisionAnnotationKey: "1",
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewAction(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					suppliedCSRRequest)),
+			},
+		},
+		"do nothing if existing CertificateRequest was created from the supplied CSR": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: bundle1.certificate.Namespace, Name: "csr"},
+					Data:       map[string][]byte{cmmeta.TLSCSRKey: bundle1.csrBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateCommonName(""),
+				gen.SetCertificateCSRSecretRef("csr", ""),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(suppliedCSRRequest, gen.SetCertificateRequestName("test")),
+			},
+		},
+		"do nothing and record an event if the supplied CSR is invalid": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: bundle1.certificate.Namespace, Name: "csr"},
+					Data:       map[string][]byte{cmmeta.TLSCSRKey: []byte("invalid")},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateCommonName(""),
+				gen.SetCertificateCSRSecretRef("csr", ""),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			expectedEvents: []string{`Warning InvalidCSR Supplied CSR in Secret "csr" is invalid: error decoding certificate request PEM block`},
+		},
+		"return an error if the supplied CSR Secret does not exist": {
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateCommonName(""),
+				gen.SetCertificateCSRSecretRef("csr", ""),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			err: `fetching CSR Secret "csr": secret "csr" not found`,
+		},
 		"delete the owned CertificateRequest and create a new one if existing one does not have the annotation": {
 			secrets: []runtime.Object{
 				&corev1.Secret{
@@ -466,6 +649,50 @@ func TestProcessItem(t *testing.T) {
 					)), relaxedCertificateRequestMatcher),
 			},
 		},
+		"should recreate the CertificateRequest signed with the csrSignatureAlgorithm if it does not match the existing CSR": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateCSRSignatureAlgorithm(cmapi.SHA512WithRSASignatureAlgorithm),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+						}),
+					)), func(l, r coretesting.Action) error {
+					if err := relaxedCertificateRequestMatcher(l, r); err != nil {
+						return err
+					}
+					csr, err := pki.DecodeX509CertificateRequestBytes(r.(coretesting.CreateAction).GetObject().(*cmapi.CertificateRequest).Spec.Request)
+					if err != nil {
+						return err
+					}
+					if csr.SignatureAlgorithm != x509.SHA512WithRSA {
+						return fmt.Errorf("expected CSR to be signed with %s, but got %s", x509.SHA512WithRSA, csr.SignatureAlgorithm)
+					}
+					return nil
+				}),
+			},
+		},
 		"should do nothing if request has an up to date CSR and it is still pending": {
 			secrets: []runtime.Object{
 				&corev1.Secret{
@@ -487,7 +714,7 @@ func TestProcessItem(t *testing.T) {
 				),
 			},
 		},
-		"should do nothing if multiple owned and up to date CertificateRequests for the current revision exist": {
+		"should do nothing if a pending CertificateRequest has not exceeded the issuance timeout": {
 			secrets: []runtime.Object{
 				&corev1.Secret{
 					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
@@ -495,6 +722,7 @@ func TestProcessItem(t *testing.T) {
 				},
 			},
 			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuanceTimeout(time.Hour),
 				gen.SetCertificateNextPrivateKeySecretName("exists"),
 				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
 				gen.SetCertificateRevision(5),
@@ -505,77 +733,1471 @@ func TestProcessItem(t *testing.T) {
 						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
 						cmapi.CertificateRequestRevisionAnnotationKey:   "6",
 					}),
+					gen.SetCertificateRequestCreationTimestamp(metav1.NewTime(fixedClock.Now().Add(-time.Minute*30))),
 				),
+			},
+		},
+		"should delete a pending CertificateRequest and fail the Certificate if the issuance timeout has elapsed": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuanceTimeout(time.Hour),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateRevision(5),
+			),
+			requests: []runtime.Object{
 				gen.CertificateRequestFrom(bundle1.certificateRequest,
-					gen.SetCertificateRequestName("another-name-2"),
 					gen.SetCertificateRequestAnnotations(map[string]string{
 						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
 						cmapi.CertificateRequestRevisionAnnotationKey:   "6",
 					}),
+					gen.SetCertificateRequestCreationTimestamp(metav1.NewTime(fixedClock.Now().Add(-time.Hour*2))),
 				),
 			},
+			expectedEvents: []string{
+				"Warning IssuanceTimeout Issuance did not complete within the issuance timeout of 1h0m0s and will be retried",
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(
+					cmapi.SchemeGroupVersion.WithResource("certificaterequests"),
+					"testns",
+					bundle1.certificateRequest.Name,
+				)),
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"testns",
+					gen.CertificateFrom(bundle1.certificate,
+						gen.SetCertificateIssuanceTimeout(time.Hour),
+						gen.SetCertificateNextPrivateKeySecretName("exists"),
+						gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+							Type:               cmapi.CertificateConditionIssuing,
+							Status:             cmmeta.ConditionFalse,
+							Reason:             cmapi.CertificateRequestReasonFailed,
+							Message:            "Issuance did not complete within the issuance timeout of 1h0m0s and will be retried",
+							LastTransitionTime: &metav1.Time{Time: fixedClock.Now()},
+						}),
+						gen.SetCertificateRevision(5),
+						gen.SetCertificateLastFailureTime(metav1.NewTime(fixedClock.Now())),
+						gen.AddCertificateLastReconciledBy(ControllerName, metav1.NewTime(fixedClock.Now())),
+					),
+				)),
+			},
 		},
-	}
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			// Create and initialise a new unit test builder
-			builder := &testpkg.Builder{
-				T:               t,
-				ExpectedEvents:  test.expectedEvents,
-				ExpectedActions: test.expectedActions,
-				StringGenerator: func(i int) string { return "notrandom" },
-			}
-			if test.certificate != nil {
-				builder.CertManagerObjects = append(builder.CertManagerObjects, test.certificate)
-			}
-			if test.secrets != nil {
-				builder.KubeObjects = append(builder.KubeObjects, test.secrets...)
-			}
-			for _, req := range test.requests {
-				builder.CertManagerObjects = append(builder.CertManagerObjects, req)
-			}
-			builder.Init()
-
-			// Register informers used by the controller using the registration wrapper
-			w := &controllerWrapper{}
-			_, _, err := w.Register(builder.Context)
-			if err != nil {
-				t.Fatal(err)
-			}
-			// Start the informers and begin processing updates
-			builder.Start()
-			defer builder.Stop()
-
-			key := test.key
-			if key == "" && test.certificate != nil {
-				key, err = controllerpkg.KeyFunc(test.certificate)
-				if err != nil {
-					t.Fatal(err)
-				}
-			}
-
-			// Call ProcessItem
-			err = w.controller.ProcessItem(context.Background(), key)
-			switch {
-			case err != nil:
-				if test.err != err.Error() {
-					t.Errorf("error text did not match, got=%s, exp=%s", err.Error(), test.err)
-				}
-			default:
-				if test.err != "" {
-					t.Errorf("got no error but expected: %s", test.err)
-				}
-			}
-
-			if err := builder.AllEventsCalled(); err != nil {
-				builder.T.Error(err)
-			}
-			if err := builder.AllActionsExecuted(); err != nil {
-				builder.T.Error(err)
-			}
-			if err := builder.AllReactorsCalled(); err != nil {
-				builder.T.Error(err)
-			}
-		})
-	}
+		"should not fail the Certificate if a CertificateRequest that exceeded the issuance timeout has been issued": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuanceTimeout(time.Hour),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateRevision(5),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "6",
+					}),
+					gen.SetCertificateRequestCreationTimestamp(metav1.NewTime(fixedClock.Now().Add(-time.Hour*2))),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionTrue,
+						Reason: cmapi.CertificateRequestReasonIssued,
+					}),
+				),
+			},
+		},
+		"should delete a failed CertificateRequest and create a new one for the next fallback issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.ClusterIssuer("fallback", gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateIssuerRefs(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonFailed,
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should delete a denied CertificateRequest and create a new one for the next fallback issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.ClusterIssuer("fallback", gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateIssuerRefs(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionDenied,
+						Status: cmmeta.ConditionTrue,
+						Reason: "Denied",
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should delete a pending CertificateRequest whose issuer does not exist and create a new one for the next fallback issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.ClusterIssuer("fallback", gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "primary", Kind: "Issuer"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateIssuerRefs(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "primary", Kind: "Issuer"}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonPending,
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should delete a pending CertificateRequest whose issuer is not ready and create a new one for the next fallback issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("primary", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1), gen.AddIssuerCondition(cmapi.IssuerCondition{
+					Type:   cmapi.IssuerConditionReady,
+					Status: cmmeta.ConditionFalse,
+					Reason: "ErrInitIssuer",
+				})),
+				gen.ClusterIssuer("fallback", gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "primary", Kind: "Issuer"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateIssuerRefs(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "primary", Kind: "Issuer"}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonPending,
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should do nothing if a pending CertificateRequest's issuer is not ready and no fallback issuer remains": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("primary", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1), gen.AddIssuerCondition(cmapi.IssuerCondition{
+					Type:   cmapi.IssuerConditionReady,
+					Status: cmmeta.ConditionFalse,
+					Reason: "ErrInitIssuer",
+				})),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "primary", Kind: "Issuer"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "primary", Kind: "Issuer"}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonPending,
+					}),
+				),
+			},
+		},
+		"should create a CertificateRequest for the next fallback issuer if the issuer is not ready": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("primary", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1), gen.AddIssuerCondition(cmapi.IssuerCondition{
+					Type:   cmapi.IssuerConditionReady,
+					Status: cmmeta.ConditionFalse,
+					Reason: "ErrInitIssuer",
+				})),
+				gen.ClusterIssuer("fallback", gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "primary", Kind: "Issuer"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateIssuerRefs(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should create a CertificateRequest for the staging issuer if the Certificate has a staging issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("staging", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.StagingIssuerNameAnnotationKey: "staging"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.StagingIssuerNameAnnotationKey:                  "staging",
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+							cmapi.CertificateRequestStagingAnnotationKey:          "true",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "staging", Kind: bundle1.certificate.Spec.IssuerRef.Kind}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should create a CertificateRequest for the staging issuer named by the Certificate's issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("production", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1),
+					gen.AddIssuerAnnotations(map[string]string{cmapi.StagingIssuerNameAnnotationKey: "staging"})),
+				gen.Issuer("staging", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "production"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+							cmapi.CertificateRequestStagingAnnotationKey:          "true",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "staging"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should do nothing if the staging CertificateRequest has not been issued yet": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.StagingIssuerNameAnnotationKey: "staging"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+						cmapi.CertificateRequestStagingAnnotationKey:    "true",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "staging", Kind: bundle1.certificate.Spec.IssuerRef.Kind}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonPending,
+					}),
+				),
+			},
+		},
+		"should replace an issued staging CertificateRequest with a CertificateRequest for the Certificate's issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("production", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.StagingIssuerNameAnnotationKey: "staging"}),
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "production"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestName("test-staging"),
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+						cmapi.CertificateRequestStagingAnnotationKey:    "true",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "staging"}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionTrue,
+						Reason: cmapi.CertificateRequestReasonIssued,
+					}),
+				),
+			},
+			expectedEvents: []string{
+				`Normal StagingIssued Staging issuer "staging" issued CertificateRequest "test-staging", requesting the certificate from issuer "production"`,
+				`Normal Requested Created new CertificateRequest resource "test-notrandom"`,
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+					cmapi.SchemeGroupVersion.WithResource("certificates"),
+					"status",
+					"testns",
+					gen.CertificateFrom(bundle1.certificate,
+						gen.AddCertificateAnnotations(map[string]string{cmapi.StagingIssuerNameAnnotationKey: "staging"}),
+						gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "production"}),
+						gen.SetCertificateNextPrivateKeySecretName("exists"),
+						gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+						gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+							Type:               cmapi.CertificateConditionStagingIssued,
+							Status:             cmmeta.ConditionTrue,
+							Reason:             "StagingIssued",
+							Message:            `Staging issuer "staging" issued CertificateRequest "test-staging", requesting the certificate from issuer "production"`,
+							LastTransitionTime: &metav1.Time{Time: fixedClock.Now()},
+						}),
+					),
+				)),
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test-staging")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.StagingIssuerNameAnnotationKey:                  "staging",
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "production"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should not copy the staging request annotation from the Certificate onto the CertificateRequest": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("production", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.AddCertificateAnnotations(map[string]string{cmapi.CertificateRequestStagingAnnotationKey: "true"}),
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "production"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "production"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should delete a failed CertificateRequest and create a new one if the issuer has changed since it was created": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("issuer-1", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(2)),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+						cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonFailed,
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "2",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should delete a failed CertificateRequest and create a new one if the issuer has been recreated since it was created": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			issuers: []runtime.Object{
+				gen.Issuer("issuer-1", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1), gen.SetIssuerUID("uid-2")),
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+						cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+						cmapi.CertificateRequestIssuerUIDAnnotationKey:        "uid-1",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonFailed,
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+							cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+							cmapi.CertificateRequestIssuerUIDAnnotationKey:        "uid-2",
+						}),
+						gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should do nothing if a failed CertificateRequest was for the last fallback issuer": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateIssuerRefs(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "fallback", Kind: "ClusterIssuer"}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: cmapi.CertificateRequestReasonFailed,
+					}),
+				),
+			},
+		},
+		"should delete a denied CertificateRequest and create a new one if the Certificate spec has changed since it was created": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateGeneration(2),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey:            "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:              "1",
+						cmapi.CertificateRequestCertificateGenerationAnnotationKey: "1",
+					}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionDenied,
+						Status: cmmeta.ConditionTrue,
+						Reason: "Denied",
+					}),
+				),
+			},
+			expectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test")),
+				testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+					gen.CertificateRequestFrom(bundle1.certificateRequest,
+						gen.SetCertificateRequestAnnotations(map[string]string{
+							cmapi.CertificateRequestPrivateKeyAnnotationKey:            "exists",
+							cmapi.CertificateRequestRevisionAnnotationKey:              "1",
+							cmapi.CertificateRequestCertificateGenerationAnnotationKey: "2",
+						}),
+					)), relaxedCertificateRequestMatcher),
+			},
+		},
+		"should do nothing if a denied CertificateRequest was created for the current Certificate generation": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateGeneration(2),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey:            "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:              "1",
+						cmapi.CertificateRequestCertificateGenerationAnnotationKey: "2",
+					}),
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionDenied,
+						Status: cmmeta.ConditionTrue,
+						Reason: "Denied",
+					}),
+				),
+			},
+		},
+		"should do nothing if a denied CertificateRequest does not record the Certificate generation it was created for": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateGeneration(2),
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+					func(req *cmapi.CertificateRequest) {
+						delete(req.Annotations, cmapi.CertificateRequestCertificateGenerationAnnotationKey)
+					},
+					gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+						Type:   cmapi.CertificateRequestConditionDenied,
+						Status: cmmeta.ConditionTrue,
+						Reason: "Denied",
+					}),
+				),
+			},
+		},
+		"should do nothing if multiple owned and up to date CertificateRequests for the current revision exist": {
+			secrets: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+					Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle1.privateKeyBytes},
+				},
+			},
+			certificate: gen.CertificateFrom(bundle1.certificate,
+				gen.SetCertificateNextPrivateKeySecretName("exists"),
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateRevision(5),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "6",
+					}),
+				),
+				gen.CertificateRequestFrom(bundle1.certificateRequest,
+					gen.SetCertificateRequestName("another-name-2"),
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "6",
+					}),
+				),
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Create and initialise a new unit test builder
+			builder := &testpkg.Builder{
+				T:               t,
+				Clock:           fixedClock,
+				ExpectedEvents:  test.expectedEvents,
+				ExpectedActions: test.expectedActions,
+				StringGenerator: func(i int) string { return "notrandom" },
+			}
+			if test.certificate != nil {
+				builder.CertManagerObjects = append(builder.CertManagerObjects, test.certificate)
+			}
+			if test.secrets != nil {
+				builder.KubeObjects = append(builder.KubeObjects, test.secrets...)
+			}
+			for _, req := range test.requests {
+				builder.CertManagerObjects = append(builder.CertManagerObjects, req)
+			}
+			builder.CertManagerObjects = append(builder.CertManagerObjects, test.issuers...)
+			builder.Init()
+
+			// Register informers used by the controller using the registration wrapper
+			w := &controllerWrapper{}
+			_, _, err := w.Register(builder.Context)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Start the informers and begin processing updates
+			builder.Start()
+			defer builder.Stop()
+
+			key := test.key
+			if key == "" && test.certificate != nil {
+				key, err = controllerpkg.KeyFunc(test.certificate)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Call ProcessItem
+			err = w.controller.ProcessItem(context.Background(), key)
+			switch {
+			case err != nil:
+				if test.err != err.Error() {
+					t.Errorf("error text did not match, got=%s, exp=%s", err.Error(), test.err)
+				}
+			default:
+				if test.err != "" {
+					t.Errorf("got no error but expected: %s", test.err)
+				}
+			}
+
+			if err := builder.AllEventsCalled(); err != nil {
+				builder.T.Error(err)
+			}
+			if err := builder.AllActionsExecuted(); err != nil {
+				builder.T.Error(err)
+			}
+			if err := builder.AllReactorsCalled(); err != nil {
+				builder.T.Error(err)
+			}
+		})
+	}
+}
+
+func TestProcessItemTracing(t *testing.T) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+
+	// A trace context annotation set on the Certificate is not copied onto
+	// the CertificateRequest, nor used as the parent of the span.
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+		gen.AddCertificateAnnotations(map[string]string{
+			cmapi.TraceContextAnnotationKey: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		}),
+	)
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		StringGenerator:    func(i int) string { return "notrandom" },
+		CertManagerObjects: []runtime.Object{crt},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+			},
+		},
+		ExpectedEvents: []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`},
+	}
+	builder.Init()
+	builder.Context.TracerProvider = provider
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The request manager starts the trace of the issuance.
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != ControllerName {
+		t.Errorf("unexpected span name, exp=%s got=%s", ControllerName, span.Name())
+	}
+	if span.Parent().IsValid() {
+		t.Errorf("unexpected parent span %s", span.Parent().SpanID())
+	}
+
+	// The CertificateRequest carries the trace context of the request
+	// manager span.
+	sc := span.SpanContext()
+	builder.ExpectedActions = []testpkg.Action{
+		testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+			gen.CertificateRequestFrom(bundle.certificateRequest,
+				gen.SetCertificateRequestAnnotations(map[string]string{
+					cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+					cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					cmapi.TraceContextAnnotationKey:                 fmt.Sprintf("00-%s-%s-01", sc.TraceID(), sc.SpanID()),
+				}),
+			)), relaxedCertificateRequestMatcher),
+	}
+	builder.CheckAndFinish()
+}
+
+func TestProcessItemRateLimited(t *testing.T) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+	)
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		StringGenerator:    func(i int) string { return "notrandom" },
+		CertManagerObjects: []runtime.Object{crt},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+			},
+		},
+		ExpectedEvents: []string{
+			`Normal Requested Created new CertificateRequest resource "test-notrandom"`,
+			`Warning RateLimited Creation of CertificateRequests in namespace "testns" is being rate limited, retrying in 1h0m0s`,
+		},
+		ExpectedActions: []testpkg.Action{
+			testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+				gen.CertificateRequestFrom(bundle.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+					}),
+				)), relaxedCertificateRequestMatcher),
+		},
+	}
+	builder.Init()
+	// allow a single CertificateRequest to be created per hour in each
+	// namespace
+	builder.Context.CertificateOptions.IssuanceRateLimit = 1
+	builder.Context.CertificateOptions.IssuanceRateBurst = 1
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	// The first request is allowed. The CertificateRequest is deleted
+	// again so that the Certificate needs a new one.
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.FakeCMClient().CertmanagerV1().CertificateRequests("testns").Delete(context.Background(), "test-notrandom", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	builder.Sync()
+
+	// The second request exceeds the rate, so its creation is deferred.
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+
+	builder.ExpectedActions = append(builder.ExpectedActions,
+		testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test-notrandom")),
+	)
+	builder.CheckAndFinish()
+}
+
+func TestProcessItemRateLimitedCreateFailed(t *testing.T) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+	)
+	createAction := testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+		gen.CertificateRequestFrom(bundle.certificateRequest,
+			gen.SetCertificateRequestAnnotations(map[string]string{
+				cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+				cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+			}),
+		)), relaxedCertificateRequestMatcher)
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		StringGenerator:    func(i int) string { return "notrandom" },
+		CertManagerObjects: []runtime.Object{crt},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+			},
+		},
+		ExpectedEvents: []string{
+			`Warning RequestFailed Failed to create CertificateRequest: this is a simulated error`,
+			`Normal Requested Created new CertificateRequest resource "test-notrandom"`,
+		},
+		ExpectedActions: []testpkg.Action{createAction, createAction},
+	}
+	builder.Init()
+	// allow a single CertificateRequest to be created per hour in each
+	// namespace
+	builder.Context.CertificateOptions.IssuanceRateLimit = 1
+	builder.Context.CertificateOptions.IssuanceRateBurst = 1
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	// The first attempt fails to create the CertificateRequest, so it must
+	// not use up the rate limit of the namespace.
+	failed := false
+	builder.FakeCMClient().PrependReactor("create", "certificaterequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+		if failed {
+			return false, nil, nil
+		}
+		failed = true
+		return true, nil, errors.New("this is a simulated error")
+	})
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err == nil {
+		t.Fatal("expected the failure to create the CertificateRequest to be returned")
+	}
+
+	// The retry is allowed, as the first attempt returned its token.
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+	builder.CheckAndFinish()
+}
+
+func TestProcessItemIssuerNotFound(t *testing.T) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "missing"}),
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+	)
+	waitingCrt := gen.CertificateFrom(crt,
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+			Type:    cmapi.CertificateConditionIssuing,
+			Status:  cmmeta.ConditionTrue,
+			Reason:  reasonIssuerNotFound,
+			Message: `Waiting for referenced Issuer "missing" to be created`,
+		}),
+	)
+	iss := gen.Issuer("missing", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(1))
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		StringGenerator:    func(i int) string { return "notrandom" },
+		CertManagerObjects: []runtime.Object{crt},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+			},
+		},
+		ExpectedEvents: []string{
+			`Warning IssuerNotFound Waiting for referenced Issuer "missing" to be created`,
+			`Normal Requested Created new CertificateRequest resource "test-notrandom"`,
+		},
+		ExpectedActions: []testpkg.Action{
+			testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmapi.SchemeGroupVersion.WithResource("certificates"), "status", "testns", waitingCrt)),
+			testpkg.NewAction(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("issuers"), "testns", iss)),
+			testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+				gen.CertificateRequestFrom(bundle.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey:       "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:         "1",
+						cmapi.CertificateRequestIssuerGenerationAnnotationKey: "1",
+					}),
+					gen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "missing"}),
+				)), relaxedCertificateRequestMatcher),
+		},
+	}
+	builder.Init()
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	// The Issuer does not exist, so the Certificate is marked as waiting for
+	// it without returning an error.
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+	builder.Sync()
+
+	// Processing the Certificate again while the Issuer is still missing
+	// neither records another event nor updates the Certificate.
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the Issuer has been created, the CertificateRequest is created.
+	if _, err := builder.FakeCMClient().CertmanagerV1().Issuers("testns").Create(context.Background(), iss, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	builder.Sync()
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+
+	builder.CheckAndFinish()
+}
+
+func TestProcessItemRequestNamingRevision(t *testing.T) {
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateRevision(2),
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+	)
+	expectedRequest := gen.CertificateRequestFrom(bundle.certificateRequest,
+		gen.SetCertificateRequestAnnotations(map[string]string{
+			cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+			cmapi.CertificateRequestRevisionAnnotationKey:   "3",
+		}),
+	)
+
+	tests := map[string]struct {
+		// existingRequest is a CertificateRequest that is not owned by the
+		// Certificate, and so is neither reused nor deleted
+		existingRequest *cmapi.CertificateRequest
+
+		// uncachedRequest, if set, is returned when fetching the request for
+		// the next revision from the apiserver but has not been observed by
+		// the informer cache, e.g. as it was created just before the
+		// controller restarted.
+		uncachedRequest *cmapi.CertificateRequest
+
+		expectedRequests []*cmapi.CertificateRequest
+		expectedEvent    string
+	}{
+		"should name the CertificateRequest after the next revision of the Certificate": {
+			expectedRequests: []*cmapi.CertificateRequest{
+				gen.CertificateRequestFrom(expectedRequest, gen.SetCertificateRequestGenerateName(""), gen.SetCertificateRequestName("test-3")),
+			},
+			expectedEvent: `Normal Requested Created new CertificateRequest resource "test-3"`,
+		},
+		"should add a random suffix to the name if it is already in use": {
+			existingRequest: gen.CertificateRequest("test-3", gen.SetCertificateRequestNamespace("testns")),
+			expectedRequests: []*cmapi.CertificateRequest{
+				gen.CertificateRequestFrom(expectedRequest, gen.SetCertificateRequestGenerateName(""), gen.SetCertificateRequestName("test-3")),
+				gen.CertificateRequestFrom(expectedRequest, gen.SetCertificateRequestGenerateName("test-3-")),
+			},
+			expectedEvent: `Normal Requested Created new CertificateRequest resource "test-3-notrandom"`,
+		},
+		"should reuse an identical CertificateRequest for the revision that is not in the informer cache": {
+			uncachedRequest: gen.CertificateRequestFrom(expectedRequest, gen.SetCertificateRequestGenerateName(""), gen.SetCertificateRequestName("test-3")),
+			expectedEvent:   `Normal Requested Reusing existing CertificateRequest resource "test-3"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fixedClock := fakeclock.NewFakeClock(time.Now())
+			builder := &testpkg.Builder{
+				T:                  t,
+				Clock:              fixedClock,
+				StringGenerator:    func(i int) string { return "notrandom" },
+				CertManagerObjects: []runtime.Object{crt},
+				KubeObjects: []runtime.Object{
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+						Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+					},
+				},
+				ExpectedEvents: []string{test.expectedEvent},
+			}
+			if test.existingRequest != nil {
+				builder.CertManagerObjects = append(builder.CertManagerObjects, test.existingRequest)
+			}
+			builder.ExpectedActions = append(builder.ExpectedActions,
+				testpkg.NewAction(coretesting.NewGetAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "test-3")))
+			for _, req := range test.expectedRequests {
+				builder.ExpectedActions = append(builder.ExpectedActions,
+					testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", req),
+						relaxedCertificateRequestMatcher))
+			}
+			builder.Init()
+			builder.Context.CertificateOptions.RequestNaming = RequestNamingRevision
+
+			w := &controllerWrapper{}
+			if _, _, err := w.Register(builder.Context); err != nil {
+				t.Fatal(err)
+			}
+			builder.Start()
+			defer builder.Stop()
+
+			if test.uncachedRequest != nil {
+				// The informers have already been synced, so the request will
+				// only be visible when fetching it from the apiserver.
+				builder.FakeCMClient().PrependReactor("get", "certificaterequests", func(action coretesting.Action) (bool, runtime.Object, error) {
+					return true, test.uncachedRequest, nil
+				})
+			}
+
+			if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+				t.Fatal(err)
+			}
+			builder.CheckAndFinish()
+		})
+	}
+}
+
+func TestProcessItemIssuanceTimeoutNotifiesWebhook(t *testing.T) {
+	received := make(chan map[string]string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer srv.Close()
+
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateIssuanceTimeout(time.Hour),
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+		gen.SetCertificateRevision(5),
+	)
+	req := gen.CertificateRequestFrom(bundle.certificateRequest,
+		gen.SetCertificateRequestAnnotations(map[string]string{
+			cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+			cmapi.CertificateRequestRevisionAnnotationKey:   "6",
+		}),
+		gen.SetCertificateRequestCreationTimestamp(metav1.NewTime(fixedClock.Now().Add(-time.Hour*2))),
+	)
+	message := "Issuance did not complete within the issuance timeout of 1h0m0s and will be retried"
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		CertManagerObjects: []runtime.Object{crt, req},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+			},
+		},
+		ExpectedEvents: []string{"Warning IssuanceTimeout " + message},
+		ExpectedActions: []testpkg.Action{
+			testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", req.Name)),
+			testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmapi.SchemeGroupVersion.WithResource("certificates"), "status", "testns",
+				gen.CertificateFrom(crt,
+					gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+						Type:               cmapi.CertificateConditionIssuing,
+						Status:             cmmeta.ConditionFalse,
+						Reason:             cmapi.CertificateRequestReasonFailed,
+						Message:            message,
+						LastTransitionTime: &metav1.Time{Time: fixedClock.Now()},
+					}),
+					gen.SetCertificateLastFailureTime(metav1.NewTime(fixedClock.Now())),
+					gen.AddCertificateLastReconciledBy(ControllerName, metav1.NewTime(fixedClock.Now())),
+				),
+			)),
+		},
+	}
+	builder.Init()
+	builder.Context.CertificateOptions.IssuanceFailureWebhookURL = srv.URL
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+	builder.CheckAndFinish()
+
+	exp := map[string]string{
+		"name":      "test",
+		"namespace": "testns",
+		"reason":    reasonIssuanceTimeout,
+		"message":   message,
+	}
+	select {
+	case got := <-received:
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("unexpected payload received, exp=%+v got=%+v", exp, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the issuance failure notification")
+	}
+}
+
+func TestProcessItemStripsRequesterAnnotations(t *testing.T) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+	)
+	crt.Annotations = map[string]string{
+		cmapi.RequesterUsernameAnnotationKey: "user-1",
+		cmapi.RequesterGroupsAnnotationKey:   "group-1",
+		"example.com/copied":                 "true",
+	}
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		StringGenerator:    func(i int) string { return "notrandom" },
+		CertManagerObjects: []runtime.Object{crt},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+			},
+		},
+		ExpectedEvents: []string{
+			`Normal Requested Created new CertificateRequest resource "test-notrandom"`,
+		},
+		ExpectedActions: []testpkg.Action{
+			testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+				gen.CertificateRequestFrom(bundle.certificateRequest,
+					gen.SetCertificateRequestAnnotations(map[string]string{
+						cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+						cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+						"example.com/copied":                            "true",
+					}),
+				)), relaxedCertificateRequestMatcher),
+		},
+	}
+	builder.Init()
+	builder.Context.CertificateOptions.RequesterAnnotations = []string{cmapi.RequesterUsernameAnnotationKey, cmapi.RequesterGroupsAnnotationKey}
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Fatal(err)
+	}
+	builder.CheckAndFinish()
+}
+
+func TestProcessItemSpecChangeDebounce(t *testing.T) {
+	fixedNow := time.Now()
+	fixedClock := fakeclock.NewFakeClock(fixedNow)
+	const debounce = 10 * time.Second
+
+	bundle := mustCreateCryptoBundle(t, &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test", UID: "test"},
+		Spec:       cmapi.CertificateSpec{CommonName: "test-bundle"},
+	})
+	crt := gen.CertificateFrom(bundle.certificate,
+		gen.SetCertificateGeneration(1),
+		gen.SetCertificateNextPrivateKeySecretName("exists"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+	)
+	req := gen.CertificateRequestFrom(bundle.certificateRequest,
+		gen.SetCertificateRequestAnnotations(map[string]string{
+			cmapi.CertificateRequestPrivateKeyAnnotationKey: "exists",
+			cmapi.CertificateRequestRevisionAnnotationKey:   "1",
+		}),
+	)
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		StringGenerator:    func(i int) string { return "notrandom" },
+		CertManagerObjects: []runtime.Object{crt, req},
+		KubeObjects: []runtime.Object{
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "exists"},
+				Data:       map[string][]byte{corev1.TLSPrivateKeyKey: bundle.privateKeyBytes},
+			},
+		},
+	}
+	builder.Init()
+	builder.Context.CertificateOptions.SpecChangeDebounce = debounce
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	var scheduled []time.Duration
+	w.scheduledWorkQueue = &schedulertest.FakeScheduler{
+		AddFunc: func(_ interface{}, delay time.Duration) {
+			scheduled = append(scheduled, delay)
+		},
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	indexer := builder.SharedInformerFactory.Certmanager().V1().Certificates().Informer().GetIndexer()
+	processAt := func(offset time.Duration) {
+		fixedClock.SetTime(fixedNow.Add(offset))
+		if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	update := func(generation int64, commonName string, offset time.Duration) {
+		if err := indexer.Update(gen.CertificateFrom(crt,
+			gen.SetCertificateGeneration(generation),
+			gen.SetCertificateCommonName(commonName),
+		)); err != nil {
+			t.Fatal(err)
+		}
+		processAt(offset)
+	}
+
+	// The request matches the spec the issuance was triggered for.
+	processAt(0)
+	// Edits made during the issuance defer replacing the request until the
+	// spec has been unchanged for the debounce period.
+	update(2, "something-different", time.Second)
+	update(3, "something-else", 3*time.Second)
+	if !reflect.DeepEqual([]time.Duration{debounce, debounce}, scheduled) {
+		t.Errorf("expected replacing the request to be deferred twice by %v, got %v", debounce, scheduled)
+	}
+
+	// Once the spec has settled the request is replaced by a single new one
+	// for the latest generation.
+	builder.ExpectedActions = []testpkg.Action{
+		testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", req.Name)),
+		testpkg.NewCustomMatch(coretesting.NewCreateAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns",
+			gen.CertificateRequestFrom(bundle.certificateRequest,
+				gen.SetCertificateRequestAnnotations(map[string]string{
+					cmapi.CertificateRequestPrivateKeyAnnotationKey:            "exists",
+					cmapi.CertificateRequestRevisionAnnotationKey:              "1",
+					cmapi.CertificateRequestCertificateGenerationAnnotationKey: "3",
+				}),
+			)), relaxedCertificateRequestMatcher),
+	}
+	builder.ExpectedEvents = []string{`Normal Requested Created new CertificateRequest resource "test-notrandom"`}
+	processAt(13 * time.Second)
+	if len(scheduled) != 2 {
+		t.Errorf("expected no further deferral once the spec has settled, got %v", scheduled)
+	}
+
+	builder.CheckAndFinish()
 }
@@ -108,6 +108,7 @@ func createCryptoBundle(originalCert *cmapi.Certificate) (*cryptoBundle, error)
 		annotations[cmapi.CertificateRequestRevisionAnnotationKey] = "1"
 	}
 
+	annotations[cmapi.CertificateRequestCertificateGenerationAnnotationKey] = fmt.Sprintf("%d", crt.Generation)
 	annotations[cmapi.CertificateRequestPrivateKeyAnnotationKey] = crt.Spec.SecretName
 	annotations[cmapi.CertificateNameKey] = crt.Name
 	if crt.Status.NextPrivateKeySecretName != nil {
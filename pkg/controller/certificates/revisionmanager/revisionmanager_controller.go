@@ -27,6 +27,8 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -40,6 +42,7 @@ import (
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
+	"github.com/jetstack/cert-manager/pkg/scheduler"
 	"github.com/jetstack/cert-manager/pkg/util/predicate"
 )
 
@@ -51,6 +54,12 @@ type controller struct {
 	certificateLister        cmlisters.CertificateLister
 	certificateRequestLister cmlisters.CertificateRequestLister
 	client                   cmclient.Interface
+
+	// deleteLimiter, if set, limits the rate at which CertificateRequests
+	// are deleted. Certificates whose deletes are deferred are requeued
+	// using scheduledWorkQueue.
+	deleteLimiter      *certificates.RateLimiter
+	scheduledWorkQueue scheduler.ScheduledWorkQueue
 }
 
 type revision struct {
@@ -91,6 +100,8 @@ func NewController(log logr.Logger, client cmclient.Interface, cmFactory cminfor
 // ProcessItem will attempt to garbage collect old CertificateRequests based
 // upon `spec.revisionHistoryLimit`. This controller will only act on
 // Certificates which are in a Ready state and this value is set.
+// CertificateRequests owned by a previous Certificate of the same name are
+// always garbage collected.
 func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	log := logf.FromContext(ctx).WithValues("key", key)
 
@@ -111,6 +122,14 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	}
 
 	log = logf.WithResource(log, crt)
+	ctx = logf.NewContext(ctx, log)
+
+	// CertificateRequests left behind by a previous Certificate of the same
+	// name are not owned by this Certificate, and so would otherwise never be
+	// garbage collected.
+	if deferred, err := c.deleteStaleCertificateRequests(ctx, key, crt); err != nil || deferred {
+		return err
+	}
 
 	// If RevisionHistoryLimit is nil, don't attempt to garbage collect old
 	// CertificateRequests
@@ -138,6 +157,9 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	toDelete := certificateRequestsToDelete(log, limit, requests)
 
 	for _, req := range toDelete {
+		if !c.reserveDelete(ctx, key) {
+			return nil
+		}
 		logf.WithRelatedResourceName(log, req.Name, req.Namespace, cmapi.CertificateRequestKind).
 			WithValues("revision", req.rev).Info("garbage collecting old certificate request revsion")
 		err = c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{})
@@ -153,6 +175,70 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	return nil
 }
 
+// reserveDelete returns true if a CertificateRequest may be deleted now.
+// Otherwise the Certificate with the given key is requeued for when the
+// delete rate limit next allows a delete, and false is returned.
+func (c *controller) reserveDelete(ctx context.Context, key string) bool {
+	if c.deleteLimiter == nil {
+		return true
+	}
+	// deletes are limited across all Certificates, so share a single bucket
+	_, wait := c.deleteLimiter.Reserve("")
+	if wait == 0 {
+		return true
+	}
+	logf.FromContext(ctx).V(logf.DebugLevel).Info("deletion of certificate requests is being rate limited, retrying later", "retry_after", wait)
+	c.scheduledWorkQueue.Add(key, wait)
+	return false
+}
+
+// deleteStaleCertificateRequests deletes the CertificateRequests in the
+// Certificate's namespace that are controlled by a Certificate with the same
+// name but a different UID, i.e. by a previous Certificate that has since
+// been deleted and recreated with the same name. It returns true if the
+// remaining deletes have been deferred by the delete rate limit.
+func (c *controller) deleteStaleCertificateRequests(ctx context.Context, key string, crt *cmapi.Certificate) (bool, error) {
+	log := logf.FromContext(ctx)
+
+	requests, err := certificates.ListCertificateRequestsMatchingPredicates(
+		c.certificateRequestLister.CertificateRequests(crt.Namespace), labels.Everything(), ownedByPreviousCertificate(crt))
+	if err != nil {
+		return false, err
+	}
+
+	for _, req := range requests {
+		if !c.reserveDelete(ctx, key) {
+			return true, nil
+		}
+		logf.WithRelatedResource(log, req).WithValues("owner_uid", metav1.GetControllerOf(req).UID).
+			Info("garbage collecting certificate request owned by a previous certificate with the same name")
+		err = c.client.CertmanagerV1().CertificateRequests(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// ownedByPreviousCertificate will filter returned results to only those
+// controlled by a Certificate with the same name as the given Certificate but
+// a different UID.
+func ownedByPreviousCertificate(crt *cmapi.Certificate) predicate.Func {
+	return func(obj runtime.Object) bool {
+		ref := metav1.GetControllerOf(obj.(metav1.Object))
+		if ref == nil || ref.Kind != cmapi.CertificateKind || ref.Name != crt.Name || ref.UID == crt.UID {
+			return false
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		return err == nil && gv.Group == cmapi.SchemeGroupVersion.Group
+	}
+}
+
 // certificateRequestsToDelete will prune the given CertificateRequests for
 // those that have a valid revision number set, and return a slice of requests
 // that should be deleted according to the limit given. Oldest
@@ -206,6 +292,10 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 	log := logf.FromContext(ctx.RootContext, ControllerName)
 
 	ctrl, queue, mustSync := NewController(log, ctx.CMClient, ctx.SharedInformerFactory)
+	if limit := ctx.CertificateOptions.RequestDeleteRateLimit; limit > 0 {
+		ctrl.deleteLimiter = certificates.NewRateLimiter(ctx.Clock, limit, ctx.CertificateOptions.RequestDeleteRateBurst)
+		ctrl.scheduledWorkQueue = scheduler.NewScheduledWorkQueue(ctx.Clock, queue.Add)
+	}
 	c.controller = ctrl
 
 	return queue, mustSync, nil
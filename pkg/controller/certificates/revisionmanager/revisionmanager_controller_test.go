@@ -20,17 +20,20 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	coretesting "k8s.io/client-go/testing"
+	fakeclock "k8s.io/utils/clock/testing"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
 	logtest "github.com/jetstack/cert-manager/pkg/logs/testing"
+	schedulertest "github.com/jetstack/cert-manager/pkg/scheduler/test"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
@@ -48,6 +51,11 @@ func TestProcessItem(t *testing.T) {
 		),
 	)
 
+	// previousCrt is a Certificate with the same name as baseCrt, which was
+	// deleted before baseCrt was created.
+	previousCrt := gen.CertificateFrom(baseCrt, gen.SetCertificateUID("uid-0"))
+	otherCrt := gen.Certificate("other-cert", gen.SetCertificateNamespace("testns"), gen.SetCertificateUID("uid-2"))
+
 	tests := map[string]struct {
 		// key that should be passed to ProcessItem.
 		// if not set, the 'namespace/name' of the 'Certificate' field will be used.
@@ -212,6 +220,61 @@ func TestProcessItem(t *testing.T) {
 				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "cr-6")),
 			},
 		},
+		"delete requests owned by a previous Certificate with the same name": {
+			certificate: gen.CertificateFrom(baseCrt,
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(baseCR,
+					gen.SetCertificateRequestName("cr-1"),
+					gen.SetCertificateRequestRevision("1"),
+				),
+				gen.CertificateRequestFrom(baseCRNoOwner,
+					gen.SetCertificateRequestName("previous-cr-1"),
+					gen.SetCertificateRequestRevision("1"),
+					gen.AddCertificateRequestOwnerReferences(*metav1.NewControllerRef(
+						previousCrt, cmapi.SchemeGroupVersion.WithKind("Certificate")),
+					),
+				),
+				gen.CertificateRequestFrom(baseCRNoOwner,
+					gen.SetCertificateRequestName("other-cr-1"),
+					gen.SetCertificateRequestRevision("1"),
+					gen.AddCertificateRequestOwnerReferences(*metav1.NewControllerRef(
+						otherCrt, cmapi.SchemeGroupVersion.WithKind("Certificate")),
+					),
+				),
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "previous-cr-1")),
+			},
+		},
+		"delete requests owned by a previous Certificate before applying the limit": {
+			certificate: gen.CertificateFrom(baseCrt,
+				gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionReady, Status: cmmeta.ConditionTrue}),
+				gen.SetCertificateRevisionHistoryLimit(1),
+			),
+			requests: []runtime.Object{
+				gen.CertificateRequestFrom(baseCR,
+					gen.SetCertificateRequestName("cr-2"),
+					gen.SetCertificateRequestRevision("2"),
+				),
+				gen.CertificateRequestFrom(baseCR,
+					gen.SetCertificateRequestName("cr-1"),
+					gen.SetCertificateRequestRevision("1"),
+				),
+				gen.CertificateRequestFrom(baseCRNoOwner,
+					gen.SetCertificateRequestName("previous-cr-5"),
+					gen.SetCertificateRequestRevision("5"),
+					gen.AddCertificateRequestOwnerReferences(*metav1.NewControllerRef(
+						previousCrt, cmapi.SchemeGroupVersion.WithKind("Certificate")),
+					),
+				),
+			},
+			expectedActions: []testpkg.Action{
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "previous-cr-5")),
+				testpkg.NewAction(coretesting.NewDeleteAction(cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "cr-1")),
+			},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -272,6 +335,81 @@ func TestProcessItem(t *testing.T) {
 	}
 }
 
+func TestProcessItemDeleteRateLimited(t *testing.T) {
+	fixedClock := fakeclock.NewFakeClock(time.Now())
+	crt := gen.Certificate("test-cert",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateUID("uid-1"),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{Type: cmapi.CertificateConditionReady, Status: cmmeta.ConditionTrue}),
+		gen.SetCertificateRevisionHistoryLimit(1),
+	)
+	cr := gen.CertificateRequest("test-cr",
+		gen.SetCertificateRequestNamespace("testns"),
+		gen.AddCertificateRequestOwnerReferences(*metav1.NewControllerRef(
+			crt, cmapi.SchemeGroupVersion.WithKind("Certificate")),
+		),
+	)
+	var requests []runtime.Object
+	var expectedActions []testpkg.Action
+	for _, rev := range []string{"1", "2", "3", "4"} {
+		requests = append(requests, gen.CertificateRequestFrom(cr,
+			gen.SetCertificateRequestName("cr-"+rev),
+			gen.SetCertificateRequestRevision(rev),
+		))
+		if rev != "4" {
+			expectedActions = append(expectedActions, testpkg.NewAction(coretesting.NewDeleteAction(
+				cmapi.SchemeGroupVersion.WithResource("certificaterequests"), "testns", "cr-"+rev)))
+		}
+	}
+
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		CertManagerObjects: append([]runtime.Object{crt}, requests...),
+		ExpectedActions:    expectedActions,
+	}
+	builder.Init()
+	// one delete per second, with bursts of up to 2 deletes
+	builder.Context.CertificateOptions.RequestDeleteRateLimit = 1
+	builder.Context.CertificateOptions.RequestDeleteRateBurst = 2
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	var scheduled []time.Duration
+	w.controller.scheduledWorkQueue = &schedulertest.FakeScheduler{
+		AddFunc: func(obj interface{}, duration time.Duration) {
+			if obj != "testns/test-cert" {
+				t.Errorf("unexpected key scheduled: %v", obj)
+			}
+			scheduled = append(scheduled, duration)
+		},
+	}
+	builder.Start()
+
+	// Only the first two of the three requests over the limit are deleted,
+	// and the Certificate is requeued for when the next delete is allowed.
+	if err := w.controller.ProcessItem(context.Background(), "testns/test-cert"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scheduled, []time.Duration{time.Second}) {
+		t.Errorf("expected the Certificate to be requeued after 1s, got=%v", scheduled)
+	}
+	builder.Sync()
+
+	// Once the rate limit allows it, the remaining request is deleted.
+	fixedClock.Step(time.Second)
+	if err := w.controller.ProcessItem(context.Background(), "testns/test-cert"); err != nil {
+		t.Fatal(err)
+	}
+	if len(scheduled) != 1 {
+		t.Errorf("expected the Certificate not to be requeued again, got=%v", scheduled)
+	}
+
+	builder.CheckAndFinish()
+}
+
 func TestCertificateRequestsToDelete(t *testing.T) {
 	baseCR := gen.CertificateRequest("test")
 
@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secretwriter defines the interface used to store the material issued
+// for a Certificate, along with a registry of alternate implementations that
+// may be selected using the cert-manager.io/secret-writer annotation.
+package secretwriter
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// Interface stores the material issued for a Certificate.
+type Interface interface {
+	// Write stores the given Secret, which contains the issued private key,
+	// certificate and CA data using the same keys and metadata as the
+	// `spec.secretName` Secret resource.
+	// Implementations must not modify the given Secret.
+	Write(ctx context.Context, crt *cmapi.Certificate, secret *corev1.Secret) error
+}
+
+var (
+	writers     = make(map[string]Interface)
+	writersLock sync.RWMutex
+)
+
+// RegisterSecretWriter will register a secret writer so it can be selected
+// by Certificates using the cert-manager.io/secret-writer annotation. 'name'
+// should be unique, and is the value of the annotation used to select this
+// writer.
+func RegisterSecretWriter(name string, w Interface) {
+	writersLock.Lock()
+	defer writersLock.Unlock()
+	writers[name] = w
+}
+
+// SecretWriterFor returns the secret writer registered with the given name.
+// If no writer has been registered with that name, false is returned.
+func SecretWriterFor(name string) (Interface, bool) {
+	writersLock.RLock()
+	defer writersLock.RUnlock()
+	w, ok := writers[name]
+	return w, ok
+}
+
+// kubernetesWriter is the default Interface implementation, which stores the
+// issued material in a Kubernetes Secret resource.
+type kubernetesWriter struct {
+	kubeClient   kubernetes.Interface
+	secretLister corelisters.SecretLister
+}
+
+// NewKubernetes returns a secret writer that creates the given Secret resource
+// in the Kubernetes apiserver, or updates it if it already exists.
+// Existing Secrets that are immutable, or whose type differs from the given
+// Secret, are deleted and recreated instead. Existing Secrets that are
+// already identical to the given Secret are left unchanged.
+func NewKubernetes(kubeClient kubernetes.Interface, secretLister corelisters.SecretLister) Interface {
+	return &kubernetesWriter{
+		kubeClient:   kubeClient,
+		secretLister: secretLister,
+	}
+}
+
+func (k *kubernetesWriter) Write(ctx context.Context, _ *cmapi.Certificate, secret *corev1.Secret) error {
+	existing, err := k.secretLister.Secrets(secret.Namespace).Get(secret.Name)
+	if apierrors.IsNotFound(err) {
+		_, err = k.kubeClient.CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	// Skip writing the Secret if nothing has changed, so that reconciling an
+	// up to date Certificate does not modify the Secret resource.
+	if apiequality.Semantic.DeepEqual(existing, secret) {
+		return nil
+	}
+
+	// Immutable Secrets cannot be updated, and the type of a Secret cannot be
+	// changed, so they must be recreated.
+	if (existing.Immutable != nil && *existing.Immutable) || existing.Type != secret.Type {
+		return k.recreate(ctx, existing, secret)
+	}
+
+	_, err = k.kubeClient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+// recreate deletes the existing Secret and creates the given Secret in its
+// place. The delete is only performed if the existing Secret has not been
+// replaced in the meantime.
+func (k *kubernetesWriter) recreate(ctx context.Context, existing, secret *corev1.Secret) error {
+	err := k.kubeClient.CoreV1().Secrets(existing.Namespace).Delete(ctx, existing.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &existing.UID},
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	secret = secret.DeepCopy()
+	secret.UID = ""
+	secret.ResourceVersion = ""
+	_, err = k.kubeClient.CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+	return err
+}
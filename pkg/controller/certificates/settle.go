@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// SpecSettleTracker records the generation of each Certificate, and when it
+// was last seen to change, so that the certificates controllers can defer
+// acting on a spec until it has been unchanged for a settle period.
+type SpecSettleTracker struct {
+	clock  clock.Clock
+	period time.Duration
+
+	lock    sync.Mutex
+	changes map[string]specChange
+}
+
+// specChange records the generation of a Certificate and the time it was
+// observed to change to that generation.
+type specChange struct {
+	generation int64
+	changedAt  time.Time
+}
+
+// NewSpecSettleTracker returns a SpecSettleTracker whose Certificates have
+// settled once their spec has been unchanged for the given period.
+func NewSpecSettleTracker(clock clock.Clock, period time.Duration) *SpecSettleTracker {
+	return &SpecSettleTracker{
+		clock:   clock,
+		period:  period,
+		changes: make(map[string]specChange),
+	}
+}
+
+// Delay records the generation of the Certificate with the given key and
+// returns how long it is until its spec has been unchanged for the settle
+// period. False is returned if the spec has settled. The first generation
+// observed for a Certificate, e.g. when it is created or when the controller
+// starts, is treated as settled.
+func (t *SpecSettleTracker) Delay(key string, crt *cmapi.Certificate) (time.Duration, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	change, ok := t.changes[key]
+	if !ok {
+		t.changes[key] = specChange{generation: crt.Generation}
+		return 0, false
+	}
+	if change.generation != crt.Generation {
+		change = specChange{generation: crt.Generation, changedAt: t.clock.Now()}
+		t.changes[key] = change
+	}
+	if change.changedAt.IsZero() {
+		return 0, false
+	}
+
+	delay := change.changedAt.Add(t.period).Sub(t.clock.Now())
+	if delay <= 0 {
+		return 0, false
+	}
+	return delay, true
+}
+
+// Forget removes the recorded generation of a Certificate that no longer
+// exists.
+func (t *SpecSettleTracker) Forget(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.changes, key)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+func TestSpecSettleTracker(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	tracker := NewSpecSettleTracker(clock, 10*time.Second)
+
+	delay := func(generation int64) (time.Duration, bool) {
+		return tracker.Delay("ns/test", gen.Certificate("test", gen.SetCertificateGeneration(generation)))
+	}
+	assertDelay := func(expDelay time.Duration, expUnsettled bool, generation int64) {
+		t.Helper()
+		d, unsettled := delay(generation)
+		assert.Equal(t, expUnsettled, unsettled)
+		assert.Equal(t, expDelay, d)
+	}
+
+	// the first generation observed has settled
+	assertDelay(0, false, 1)
+
+	// a change to the generation must settle for the whole period
+	clock.Step(time.Second)
+	assertDelay(10*time.Second, true, 2)
+	clock.Step(4 * time.Second)
+	assertDelay(6*time.Second, true, 2)
+
+	// a further change restarts the period
+	assertDelay(10*time.Second, true, 3)
+	clock.Step(10 * time.Second)
+	assertDelay(0, false, 3)
+
+	// a forgotten Certificate is treated as observed for the first time
+	tracker.Forget("ns/test")
+	assertDelay(0, false, 4)
+}
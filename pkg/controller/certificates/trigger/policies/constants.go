@@ -32,6 +32,10 @@ const (
 	// IncorrectIssuer is a policy violation reason for a scenario where
 	// Certificate has been issued by incorrect Issuer.
 	IncorrectIssuer string = "IncorrectIssuer"
+	// IncorrectSecretType is a policy violation reason for a scenario where
+	// Certificate's spec.secretName secret is not of the type requested by
+	// the Certificate.
+	IncorrectSecretType string = "IncorrectSecretType"
 	// RequestChanged is a policy violation reason for a scenario where
 	// CertificateRequest not valid for Certificate's spec.
 	RequestChanged string = "RequestChanged"
@@ -41,4 +45,19 @@ const (
 	// Expired is a policy violation reason for a scenario where Certificate has
 	// expired.
 	Expired string = "Expired"
+	// NotYetValid is a policy violation reason for a scenario where
+	// Certificate's notBefore is in the future, beyond the allowed clock skew.
+	NotYetValid string = "NotYetValid"
+	// Revoked is a policy violation reason for a scenario where the
+	// Certificate's currently issued certificate has been revoked by its
+	// issuer.
+	Revoked string = "Revoked"
+	// ChainInvalid is a policy violation reason for a scenario where the
+	// Certificate's currently issued certificate does not chain to the trust
+	// anchors referenced by the Certificate.
+	ChainInvalid string = "ChainInvalid"
+	// ChainTooLong is a policy violation reason for a scenario where the
+	// Certificate's currently issued certificate chain contains more
+	// certificates than the configured maximum chain depth.
+	ChainTooLong string = "ChainTooLong"
 )
@@ -215,6 +215,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	corelisters "k8s.io/client-go/listers/core/v1"
 
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmlisters "github.com/jetstack/cert-manager/pkg/client/listers/certmanager/v1"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates"
@@ -246,6 +247,8 @@ func (g *Gatherer) DataForCertificate(ctx context.Context, crt *cmapi.Certificat
 	if err != nil && !apierrors.IsNotFound(err) {
 		return Input{}, err
 	}
+	// The policies inspect the Secret's data under the default key names.
+	secret = apiutil.SecretWithDefaultKeyNames(crt, secret)
 
 	// Attempt to fetch the CertificateRequest for the current status.revision.
 	//
@@ -19,13 +19,16 @@ package policies
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/utils/clock"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -77,6 +80,7 @@ func NewTriggerPolicyChain(c clock.Clock, defaultRenewBeforeExpiryDuration time.
 		SecretPublicKeysDiffer,
 		SecretPrivateKeyMatchesSpec,
 		SecretIssuerAnnotationsNotUpToDate,
+		SecretTypeMismatch,
 		CurrentCertificateRequestNotValidForSpec,
 		CurrentCertificateNearingExpiry(c, defaultRenewBeforeExpiryDuration),
 	}
@@ -95,7 +99,8 @@ func SecretIsMissingData(input Input) (string, string, bool) {
 	}
 	pkData := input.Secret.Data[corev1.TLSPrivateKeyKey]
 	certData := input.Secret.Data[corev1.TLSCertKey]
-	if len(pkData) == 0 {
+	// No private key is stored for Certificates using a user supplied CSR.
+	if len(pkData) == 0 && input.Certificate.Spec.CSRSecretRef == nil {
 		return MissingData, "Issuing certificate as Secret does not contain a private key", true
 	}
 	if len(certData) == 0 {
@@ -107,6 +112,14 @@ func SecretIsMissingData(input Input) (string, string, bool) {
 func SecretPublicKeysDiffer(input Input) (string, string, bool) {
 	pkData := input.Secret.Data[corev1.TLSPrivateKeyKey]
 	certData := input.Secret.Data[corev1.TLSCertKey]
+	if input.Certificate.Spec.CSRSecretRef != nil {
+		// There is no private key to compare against, so only ensure that
+		// the stored certificate can be decoded.
+		if _, err := pki.DecodeX509CertificateBytes(certData); err != nil {
+			return InvalidKeyPair, fmt.Sprintf("Issuing certificate as Secret contains an invalid certificate: %v", err), true
+		}
+		return "", "", false
+	}
 	// TODO: replace this with a generic decoder that can handle different
 	//  formats such as JKS, P12 etc (i.e. add proper support for keystores)
 	_, err := tls.X509KeyPair(certData, pkData)
@@ -117,6 +130,10 @@ func SecretPublicKeysDiffer(input Input) (string, string, bool) {
 }
 
 func SecretPrivateKeyMatchesSpec(input Input) (string, string, bool) {
+	if input.Certificate.Spec.CSRSecretRef != nil {
+		// The private key is managed by the user alongside the supplied CSR.
+		return "", "", false
+	}
 	if input.Secret.Data == nil || len(input.Secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
 		return SecretMismatch, "Existing issued Secret does not contain private key data", true
 	}
@@ -141,10 +158,32 @@ func SecretIssuerAnnotationsNotUpToDate(input Input) (string, string, bool) {
 	name := input.Secret.Annotations[cmapi.IssuerNameAnnotationKey]
 	kind := input.Secret.Annotations[cmapi.IssuerKindAnnotationKey]
 	group := input.Secret.Annotations[cmapi.IssuerGroupAnnotationKey]
-	if name != input.Certificate.Spec.IssuerRef.Name ||
-		!issuerKindsEqual(kind, input.Certificate.Spec.IssuerRef.Kind) ||
-		!issuerGroupsEqual(group, input.Certificate.Spec.IssuerRef.Group) {
-		return IncorrectIssuer, fmt.Sprintf("Issuing certificate as Secret was previously issued by %s", formatIssuerRef(name, kind, group)), true
+	// The Secret may have been issued by any of the Certificate's issuers.
+	for _, issuerRef := range certificates.IssuerRefs(input.Certificate.Spec) {
+		if name == issuerRef.Name &&
+			issuerKindsEqual(kind, issuerRef.Kind) &&
+			issuerGroupsEqual(group, issuerRef.Group) {
+			return "", "", false
+		}
+	}
+	return IncorrectIssuer, fmt.Sprintf("Issuing certificate as Secret was previously issued by %s", formatIssuerRef(name, kind, group)), true
+}
+
+// SecretTypeMismatch triggers an issuance if the Certificate requests a Secret
+// type that differs from the type of the existing Secret, so that the Secret
+// is recreated with the requested type. Secrets are not checked if the
+// Certificate does not request a type.
+func SecretTypeMismatch(input Input) (string, string, bool) {
+	want := corev1.SecretType(input.Certificate.Spec.SecretType)
+	if want == "" {
+		return "", "", false
+	}
+	got := input.Secret.Type
+	if got == "" {
+		got = corev1.SecretTypeOpaque
+	}
+	if got != want {
+		return IncorrectSecretType, fmt.Sprintf("Issuing certificate as Secret is of type %q but the Certificate requests type %q", got, want), true
 	}
 	return "", "", false
 }
@@ -215,6 +254,9 @@ func CurrentCertificateNearingExpiry(c clock.Clock, defaultRenewBeforeExpiryDura
 		crt := input.Certificate
 		renewalTimeCalculator := certificates.RenewalTimeWrapper(defaultRenewBeforeExpiryDuration)
 		renewalTime := renewalTimeCalculator(notBefore.Time, notAfter.Time, crt.Spec.RenewBefore)
+		// Start renewal early enough that a slow issuer will not cause a gap
+		// in validity between the current and next certificate.
+		renewalTime = certificates.RenewalTimeWithIssuanceLatency(renewalTime, notBefore.Time, notAfter.Time, crt.Status.IssuanceLatency)
 
 		renewIn := renewalTime.Time.Sub(c.Now())
 		if renewIn > 0 {
@@ -250,6 +292,113 @@ func CurrentCertificateHasExpired(c clock.Clock) Func {
 	}
 }
 
+// CurrentCertificateNotYetValid is used to check whether the current issued
+// certificate is not yet valid. A notBefore that is in the future by no more
+// than the given tolerance is treated as valid, to allow for clock skew between
+// cert-manager and the issuer.
+func CurrentCertificateNotYetValid(c clock.Clock, tolerance time.Duration) Func {
+	return func(input Input) (string, string, bool) {
+		certData, ok := input.Secret.Data[corev1.TLSCertKey]
+		if !ok {
+			return MissingData, "Missing Certificate data", true
+		}
+		cert, err := pki.DecodeX509CertificateBytes(certData)
+		if err != nil {
+			// This case should never happen as it should always be caught by the
+			// secretPublicKeysMatch function beforehand, but handle it just in case.
+			return "InvalidCertificate", fmt.Sprintf("Failed to decode stored certificate: %v", err), true
+		}
+
+		if c.Now().Add(tolerance).Before(cert.NotBefore) {
+			return NotYetValid, fmt.Sprintf("Certificate is not valid until %s", cert.NotBefore.Format(time.RFC1123)), true
+		}
+		return "", "", false
+	}
+}
+
+// CurrentCertificateChainNotTrusted is used to check whether the current
+// issued certificate, along with the intermediates stored with it, chains to
+// one of the trust anchors referenced by the Certificate's
+// spec.trustAnchors. Certificates without trust anchors are not checked.
+func CurrentCertificateChainNotTrusted(c clock.Clock, secretLister corelisters.SecretLister) Func {
+	return func(input Input) (string, string, bool) {
+		ref := input.Certificate.Spec.TrustAnchors
+		if ref == nil {
+			return "", "", false
+		}
+		key := ref.Key
+		if key == "" {
+			key = cmmeta.TLSCAKey
+		}
+
+		anchorsSecret, err := secretLister.Secrets(input.Certificate.Namespace).Get(ref.Name)
+		if err != nil {
+			return ChainInvalid, fmt.Sprintf("Failed to read trust anchors from Secret %q: %v", ref.Name, err), true
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(anchorsSecret.Data[key]) {
+			return ChainInvalid, fmt.Sprintf("Secret %q does not contain any PEM encoded trust anchors in key %q", ref.Name, key), true
+		}
+
+		chain, err := pki.DecodeX509CertificateChainBytes(input.Secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			// This case should never happen as it should always be caught by the
+			// secretPublicKeysMatch function beforehand, but handle it just in case.
+			return "InvalidCertificate", fmt.Sprintf("Failed to decode stored certificate: %v", err), true
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range chain[1:] {
+			intermediates.AddCert(cert)
+		}
+		if caCerts, err := pki.DecodeX509CertificateChainBytes(input.Secret.Data[cmmeta.TLSCAKey]); err == nil {
+			for _, cert := range caCerts {
+				intermediates.AddCert(cert)
+			}
+		}
+
+		// The validity period of the certificate is checked by other
+		// policies, so a certificate that is not yet valid is verified as of
+		// its notBefore.
+		leaf := chain[0]
+		now := c.Now()
+		if now.Before(leaf.NotBefore) {
+			now = leaf.NotBefore
+		}
+		if _, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   now,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return ChainInvalid, fmt.Sprintf("Issued certificate does not chain to the trust anchors in Secret %q: %v", ref.Name, err), true
+		}
+		return "", "", false
+	}
+}
+
+// CurrentCertificateChainTooLong is used to check whether the current issued
+// certificate chain, made up of the certificate and the intermediates stored
+// with it, contains more than maxDepth certificates. Excessively long chains
+// usually point to misconfigured cross-signed intermediates. Chains are not
+// checked if maxDepth is not positive.
+func CurrentCertificateChainTooLong(maxDepth int) Func {
+	return func(input Input) (string, string, bool) {
+		if maxDepth <= 0 {
+			return "", "", false
+		}
+		chain, err := pki.DecodeX509CertificateChainBytes(input.Secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			// This case should never happen as it should always be caught by the
+			// secretPublicKeysMatch function beforehand, but handle it just in case.
+			return "InvalidCertificate", fmt.Sprintf("Failed to decode stored certificate: %v", err), true
+		}
+		if len(chain) > maxDepth {
+			return ChainTooLong, fmt.Sprintf("Issued certificate chain contains %d certificates, more than the maximum of %d", len(chain), maxDepth), true
+		}
+		return "", "", false
+	}
+}
+
 func formatIssuerRef(name, kind, group string) string {
 	if group == "" {
 		group = "cert-manager.io"
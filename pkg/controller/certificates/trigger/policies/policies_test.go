@@ -17,16 +17,27 @@ limitations under the License.
 package policies
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	fakeclock "k8s.io/utils/clock/testing"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	internaltest "github.com/jetstack/cert-manager/pkg/controller/certificates/internal/test"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
 // Runs a full set of tests against the 'policy chain' once it is composed
@@ -37,6 +48,13 @@ import (
 func TestDefaultPolicyChain(t *testing.T) {
 	clock := &fakeclock.FakeClock{}
 	staticFixedPrivateKey := internaltest.MustCreatePEMPrivateKey(t)
+	// roundedNotAfter returns the notAfter that the CA and SelfSigned issuers
+	// sign a certificate with when rounding it down to the given boundary.
+	roundedNotAfter := func(notBefore time.Time, duration, boundary time.Duration) time.Time {
+		template := &x509.Certificate{NotBefore: notBefore, NotAfter: notBefore.Add(duration)}
+		pki.RoundNotAfter(template, boundary, false, duration/3)
+		return template.NotAfter
+	}
 	tests := map[string]struct {
 		// policy inputs
 		certificate *cmapi.Certificate
@@ -130,6 +148,40 @@ func TestDefaultPolicyChain(t *testing.T) {
 			message: "Issuing certificate as Secret contains an invalid key-pair: tls: private key does not match public key",
 			reissue: true,
 		},
+		"trigger issuance as Secret contains a private key of a different algorithm to spec": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				SecretName: "something",
+				PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+			}},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: internaltest.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+					),
+				},
+			},
+			reason:  SecretMismatch,
+			message: "Existing private key is not up to date for spec: [spec.keyAlgorithm]",
+			reissue: true,
+		},
+		"trigger issuance as Secret contains a private key of a different size to spec": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				SecretName: "something",
+				PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.RSAKeyAlgorithm, Size: 4096},
+			}},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: internaltest.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+					),
+				},
+			},
+			reason:  SecretMismatch,
+			message: "Existing private key is not up to date for spec: [spec.keySize]",
+			reissue: true,
+		},
 		"trigger issuance as Secret has old/incorrect 'issuer name' annotation": {
 			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
 				SecretName: "something",
@@ -208,6 +260,32 @@ func TestDefaultPolicyChain(t *testing.T) {
 			message: "Issuing certificate as Secret was previously issued by IssuerKind.new.example.com/testissuer",
 			reissue: true,
 		},
+		"trigger issuance as Secret is not of the type requested by the Certificate": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				SecretName: "something",
+				SecretType: string(corev1.SecretTypeOpaque),
+				IssuerRef: cmmeta.ObjectReference{
+					Name: "testissuer",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey: "testissuer",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: internaltest.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+					),
+				},
+				Type: corev1.SecretTypeTLS,
+			},
+			reason:  IncorrectSecretType,
+			message: `Issuing certificate as Secret is of type "kubernetes.io/tls" but the Certificate requests type "Opaque"`,
+			reissue: true,
+		},
 		// we only have a basic test here for this as unit tests for the
 		// `certificates.RequestMatchesSpec` function cover all other cases.
 		"trigger issuance when CertificateRequest does not match certificate spec": {
@@ -289,6 +367,90 @@ func TestDefaultPolicyChain(t *testing.T) {
 				}}),
 			}},
 		},
+		"do nothing if CertificateRequest has a Common Name derived from the first DNS name": {
+			certificate: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					cmapi.CommonNameFromDNSNamesAnnotationKey: "true",
+				}},
+				Spec: cmapi.CertificateSpec{
+					DNSNames: []string{"example.com", "www.example.com"},
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: internaltest.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "does-not-matter.example.com"}},
+					),
+				},
+			},
+			request: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					cmapi.CommonNameFromDNSNamesAnnotationKey: "true",
+				}},
+				Spec: cmapi.CertificateRequestSpec{
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					Request: internaltest.MustGenerateCSRImpl(t, staticFixedPrivateKey, &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+						CommonName: "example.com",
+						DNSNames:   []string{"example.com", "www.example.com"},
+					}}),
+				},
+			},
+		},
+		"do nothing if CertificateRequest excludes the Common Name from the DNS names": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName:                "example.com",
+				DNSNames:                  []string{"example.com", "www.example.com"},
+				ExcludeCommonNameFromSANs: true,
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+			}},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: internaltest.MustCreateCert(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "does-not-matter.example.com"}},
+					),
+				},
+			},
+			request: &cmapi.CertificateRequest{Spec: cmapi.CertificateRequestSpec{
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  "testissuer",
+					Kind:  "IssuerKind",
+					Group: "group.example.com",
+				},
+				Request: internaltest.MustGenerateCSRImpl(t, staticFixedPrivateKey, &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					DNSNames:   []string{"www.example.com"},
+				}}),
+			}},
+		},
 		"compare signed x509 certificate in Secret with spec if CertificateRequest does not exist": {
 			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
 				CommonName: "new.example.com",
@@ -450,6 +612,112 @@ func TestDefaultPolicyChain(t *testing.T) {
 				},
 			},
 		},
+		"does not trigger renewal if the notAfter of the x509 cert has been rounded by the issuer": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					Duration:    &metav1.Duration{Duration: time.Hour * 3},
+					RenewBefore: &metav1.Duration{Duration: time.Hour * 2},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					// rounding down to the day would leave the certificate
+					// expiring now, so it is rounded up instead
+					corev1.TLSCertKey: internaltest.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(-time.Minute),
+						roundedNotAfter(clock.Now().Add(-time.Minute), time.Hour*3, time.Hour*24),
+					),
+				},
+			},
+		},
+		"does not trigger renewal if observed issuance latency is low": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					RenewBefore: &metav1.Duration{Duration: time.Minute * 1},
+				},
+				Status: cmapi.CertificateStatus{
+					RenewalTime:     &metav1.Time{Time: clock.Now()},
+					IssuanceLatency: &metav1.Duration{Duration: time.Second * 30},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: internaltest.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Minute*-10),
+						// expires in 30 minutes time
+						clock.Now().Add(time.Minute*30),
+					),
+				},
+			},
+		},
+		"trigger renewal early if observed issuance latency is high": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					CommonName: "example.com",
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					RenewBefore: &metav1.Duration{Duration: time.Minute * 1},
+				},
+				Status: cmapi.CertificateStatus{
+					RenewalTime:     &metav1.Time{Time: clock.Now()},
+					IssuanceLatency: &metav1.Duration{Duration: time.Minute * 10},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSPrivateKeyKey: staticFixedPrivateKey,
+					corev1.TLSCertKey: internaltest.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Minute*-10),
+						// expires in 30 minutes time
+						clock.Now().Add(time.Minute*30),
+					),
+				},
+			},
+			reason:  Renewing,
+			message: "Renewing certificate as renewal was scheduled at 0001-01-01 00:00:00 +0000 UTC",
+			reissue: true,
+		},
 		"does not trigger renewal if renewal time is in 1 minute": {
 			certificate: &cmapi.Certificate{
 				Spec: cmapi.CertificateSpec{
@@ -484,6 +752,50 @@ func TestDefaultPolicyChain(t *testing.T) {
 				},
 			},
 		},
+		"does not trigger issuance if Secret has no private key for a Certificate using a supplied CSR": {
+			certificate: &cmapi.Certificate{
+				Spec: cmapi.CertificateSpec{
+					IssuerRef: cmmeta.ObjectReference{
+						Name:  "testissuer",
+						Kind:  "IssuerKind",
+						Group: "group.example.com",
+					},
+					CSRSecretRef: &cmmeta.SecretKeySelector{
+						LocalObjectReference: cmmeta.LocalObjectReference{Name: "csr"},
+					},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "something",
+					Annotations: map[string]string{
+						cmapi.IssuerNameAnnotationKey:  "testissuer",
+						cmapi.IssuerKindAnnotationKey:  "IssuerKind",
+						cmapi.IssuerGroupAnnotationKey: "group.example.com",
+					},
+				},
+				Data: map[string][]byte{
+					corev1.TLSCertKey: internaltest.MustCreateCertWithNotBeforeAfter(t, staticFixedPrivateKey,
+						&cmapi.Certificate{Spec: cmapi.CertificateSpec{CommonName: "example.com"}},
+						clock.Now().Add(time.Minute*-30),
+						clock.Now().Add(time.Hour*24),
+					),
+				},
+			},
+		},
+		"trigger issuance as Secret contains corrupt certificate data for a Certificate using a supplied CSR": {
+			certificate: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				SecretName: "something",
+				CSRSecretRef: &cmmeta.SecretKeySelector{
+					LocalObjectReference: cmmeta.LocalObjectReference{Name: "csr"},
+				},
+			}},
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "something"},
+				Data: map[string][]byte{corev1.TLSCertKey: []byte("test")},
+			},
+			reason:  InvalidKeyPair,
+			message: "Issuing certificate as Secret contains an invalid certificate: error decoding certificate PEM block",
+			reissue: true,
+		},
 	}
 	// we don't really test default renewal time here, it's just passed through
 	someDefaultRenewalTime := time.Hour * 5
@@ -508,3 +820,180 @@ func TestDefaultPolicyChain(t *testing.T) {
 		})
 	}
 }
+
+func TestCurrentCertificateChainNotTrusted(t *testing.T) {
+	now := time.Now()
+	clock := fakeclock.NewFakeClock(now)
+
+	// mustCreateCert returns a PEM encoded certificate for a new key, signed
+	// by the given parent or self-signed if the parent is nil.
+	mustCreateCert := func(name string, isCA bool, parent *x509.Certificate, parentKey crypto.Signer) (*x509.Certificate, crypto.Signer, []byte) {
+		key, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(time.Hour),
+			IsCA:                  isCA,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		certPEM, err := pki.EncodeX509(cert)
+		require.NoError(t, err)
+		return cert, key, certPEM
+	}
+
+	root, rootKey, rootPEM := mustCreateCert("root", true, nil, nil)
+	_, _, otherRootPEM := mustCreateCert("other-root", true, nil, nil)
+	intermediate, intermediateKey, intermediatePEM := mustCreateCert("intermediate", true, root, rootKey)
+	_, _, leafPEM := mustCreateCert("leaf", false, intermediate, intermediateKey)
+
+	anchors := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: name}, Data: data}
+	}
+	trustAnchorSecrets := []*corev1.Secret{
+		anchors("root", map[string][]byte{cmmeta.TLSCAKey: rootPEM}),
+		anchors("other-root", map[string][]byte{"roots.pem": otherRootPEM}),
+		anchors("empty", map[string][]byte{cmmeta.TLSCAKey: []byte("not a certificate")}),
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, secret := range trustAnchorSecrets {
+		require.NoError(t, indexer.Add(secret))
+	}
+	policy := CurrentCertificateChainNotTrusted(clock, corelisters.NewSecretLister(indexer))
+
+	tests := map[string]struct {
+		trustAnchors *cmmeta.SecretKeySelector
+		secretData   map[string][]byte
+
+		expReason  string
+		expMessage string
+		expInvalid bool
+	}{
+		"should not check a Certificate without trust anchors": {
+			secretData: map[string][]byte{corev1.TLSCertKey: leafPEM},
+		},
+		"should accept a chain that verifies against the trust anchors": {
+			trustAnchors: &cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "root"}},
+			secretData:   map[string][]byte{corev1.TLSCertKey: append(append([]byte{}, leafPEM...), intermediatePEM...)},
+		},
+		"should use the intermediates stored in ca.crt": {
+			trustAnchors: &cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "root"}},
+			secretData:   map[string][]byte{corev1.TLSCertKey: leafPEM, cmmeta.TLSCAKey: intermediatePEM},
+		},
+		"should reject a chain that does not verify against the trust anchors": {
+			trustAnchors: &cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "other-root"}, Key: "roots.pem"},
+			secretData:   map[string][]byte{corev1.TLSCertKey: append(append([]byte{}, leafPEM...), intermediatePEM...)},
+			expReason:    ChainInvalid,
+			expMessage:   `Issued certificate does not chain to the trust anchors in Secret "other-root": x509: certificate signed by unknown authority`,
+			expInvalid:   true,
+		},
+		"should reject a chain that is missing its intermediate": {
+			trustAnchors: &cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "root"}},
+			secretData:   map[string][]byte{corev1.TLSCertKey: leafPEM},
+			expReason:    ChainInvalid,
+			expMessage:   `Issued certificate does not chain to the trust anchors in Secret "root": x509: certificate signed by unknown authority`,
+			expInvalid:   true,
+		},
+		"should reject the chain if the trust anchors Secret does not exist": {
+			trustAnchors: &cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "missing"}},
+			secretData:   map[string][]byte{corev1.TLSCertKey: leafPEM},
+			expReason:    ChainInvalid,
+			expMessage:   `Failed to read trust anchors from Secret "missing": secret "missing" not found`,
+			expInvalid:   true,
+		},
+		"should reject the chain if the trust anchors Secret does not contain any certificates": {
+			trustAnchors: &cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "empty"}},
+			secretData:   map[string][]byte{corev1.TLSCertKey: leafPEM},
+			expReason:    ChainInvalid,
+			expMessage:   `Secret "empty" does not contain any PEM encoded trust anchors in key "ca.crt"`,
+			expInvalid:   true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, invalid := policy(Input{
+				Certificate: &cmapi.Certificate{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+					Spec:       cmapi.CertificateSpec{TrustAnchors: test.trustAnchors},
+				},
+				Secret: &corev1.Secret{Data: test.secretData},
+			})
+			assert.Equal(t, test.expReason, reason)
+			assert.Equal(t, test.expMessage, message)
+			assert.Equal(t, test.expInvalid, invalid)
+		})
+	}
+}
+
+func TestCurrentCertificateChainTooLong(t *testing.T) {
+	now := time.Now()
+
+	// Build a chain of a leaf certificate and three intermediates, each
+	// signed by the next.
+	var chainPEM []byte
+	var parent *x509.Certificate
+	var parentKey crypto.Signer
+	for i := 0; i < 4; i++ {
+		key, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(int64(i + 1)),
+			Subject:               pkix.Name{CommonName: fmt.Sprintf("ca-%d", i)},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		certPEM, err := pki.EncodeX509(cert)
+		require.NoError(t, err)
+		chainPEM = append(certPEM, chainPEM...)
+		parent, parentKey = cert, key
+	}
+
+	tests := map[string]struct {
+		maxDepth int
+
+		expReason  string
+		expMessage string
+		expInvalid bool
+	}{
+		"should not check the chain if no maximum depth is configured": {},
+		"should accept a chain that is as long as the maximum depth": {
+			maxDepth: 4,
+		},
+		"should reject a chain that is longer than the maximum depth": {
+			maxDepth:   3,
+			expReason:  ChainTooLong,
+			expMessage: "Issued certificate chain contains 4 certificates, more than the maximum of 3",
+			expInvalid: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason, message, invalid := CurrentCertificateChainTooLong(test.maxDepth)(Input{
+				Certificate: &cmapi.Certificate{},
+				Secret:      &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: chainPEM}},
+			})
+			assert.Equal(t, test.expReason, reason)
+			assert.Equal(t, test.expMessage, message)
+			assert.Equal(t, test.expInvalid, invalid)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/jetstack/cert-manager/pkg/util"
+)
+
+const (
+	// crlFetchTimeout is the timeout of a single CRL download.
+	crlFetchTimeout = 10 * time.Second
+
+	// maxCRLSize is the maximum size of a CRL that will be downloaded.
+	maxCRLSize = 10 << 20
+)
+
+// revocationChecker reports whether an issued certificate has been revoked by
+// its issuer.
+type revocationChecker interface {
+	// IsRevoked returns true if the certificate has been revoked. issuers are
+	// the candidate issuing certificates used to verify revocation data.
+	IsRevoked(ctx context.Context, cert *x509.Certificate, issuers []*x509.Certificate) (bool, error)
+}
+
+// cachedCRL is a downloaded CRL along with the time it must be fetched again.
+type cachedCRL struct {
+	crl     *pkix.CertificateList
+	expires time.Time
+}
+
+// crlChecker checks the revocation status of a certificate using the CRLs
+// published at the certificate's CRL distribution points. Downloaded CRLs are
+// cached until their next update, or until the refresh interval has elapsed
+// if that is sooner.
+type crlChecker struct {
+	client          *http.Client
+	clock           clock.Clock
+	refreshInterval time.Duration
+
+	lock  sync.Mutex
+	cache map[string]cachedCRL
+}
+
+func newCRLChecker(clock clock.Clock, refreshInterval time.Duration) *crlChecker {
+	return &crlChecker{
+		client:          &http.Client{Timeout: crlFetchTimeout},
+		clock:           clock,
+		refreshInterval: refreshInterval,
+		cache:           make(map[string]cachedCRL),
+	}
+}
+
+// IsRevoked returns true if the certificate's serial number is listed by the
+// CRL at any of its HTTP(S) CRL distribution points. Each CRL must be signed
+// by the issuer of the certificate, which must be one of the given issuers.
+// Certificates without CRL distribution points are never considered revoked.
+func (c *crlChecker) IsRevoked(ctx context.Context, cert *x509.Certificate, issuers []*x509.Certificate) (bool, error) {
+	var issuer *x509.Certificate
+	for _, candidate := range issuers {
+		if bytes.Equal(candidate.RawSubject, cert.RawIssuer) {
+			issuer = candidate
+			break
+		}
+	}
+
+	for _, dp := range cert.CRLDistributionPoints {
+		u, err := url.Parse(dp)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+
+		if issuer == nil {
+			return false, fmt.Errorf("cannot verify the CRL at %q as the issuing certificate is not available", dp)
+		}
+
+		crl, err := c.crlFor(ctx, dp)
+		if err != nil {
+			return false, err
+		}
+		if err := issuer.CheckCRLSignature(crl); err != nil {
+			return false, fmt.Errorf("CRL at %q is not signed by the issuer of the certificate: %w", dp, err)
+		}
+
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// crlFor returns the CRL at the given URL, downloading it if it is not cached
+// or the cached copy has expired.
+func (c *crlChecker) crlFor(ctx context.Context, crlURL string) (*pkix.CertificateList, error) {
+	now := c.clock.Now()
+
+	c.lock.Lock()
+	cached, ok := c.cache[crlURL]
+	c.lock.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.crl, nil
+	}
+
+	crl, err := c.fetchCRL(ctx, crlURL)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := now.Add(c.refreshInterval)
+	if next := crl.TBSCertList.NextUpdate; !next.IsZero() && next.Before(expires) {
+		expires = next
+	}
+
+	c.lock.Lock()
+	c.cache[crlURL] = cachedCRL{crl: crl, expires: expires}
+	c.lock.Unlock()
+
+	return crl, nil
+}
+
+func (c *crlChecker) fetchCRL(ctx context.Context, crlURL string) (*pkix.CertificateList, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, crlURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", util.CertManagerUserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching CRL from %q: %w", crlURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching CRL from %q: unexpected status code %d", crlURL, resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCRLSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading CRL from %q: %w", crlURL, err)
+	}
+	if len(data) > maxCRLSize {
+		return nil, fmt.Errorf("CRL at %q is larger than %d bytes", crlURL, maxCRLSize)
+	}
+
+	crl, err := x509.ParseCRL(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing CRL from %q: %w", crlURL, err)
+	}
+
+	return crl, nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trigger
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	fakeclock "k8s.io/utils/clock/testing"
+
+	"github.com/jetstack/cert-manager/pkg/util/pki"
+)
+
+// testCA is a CA that publishes a CRL from a test HTTP server.
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+
+	server *httptest.Server
+	// requests is the number of times the CRL has been fetched
+	requests int32
+	// revoked are the serial numbers listed by the published CRL
+	revoked []*big.Int
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		SubjectKeyId:          []byte(name),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key}
+	ca.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&ca.requests, 1)
+		w.Write(ca.crl(t, ca))
+	}))
+	t.Cleanup(ca.server.Close)
+	return ca
+}
+
+// crl returns a DER encoded CRL listing the revoked serial numbers, signed by
+// the given CA.
+func (ca *testCA) crl(t *testing.T, signer *testCA) []byte {
+	var revoked []pkix.RevokedCertificate
+	for _, serial := range ca.revoked {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: serial, RevocationTime: time.Now()})
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(1),
+		ThisUpdate:          time.Now().Add(-time.Minute),
+		NextUpdate:          time.Now().Add(24 * time.Hour),
+		RevokedCertificates: revoked,
+	}, signer.cert, signer.key)
+	require.NoError(t, err)
+	return crl
+}
+
+// issue returns a certificate with the given serial number signed by the CA,
+// which has the CA's CRL as its CRL distribution point.
+func (ca *testCA) issue(t *testing.T, serial int64) (*x509.Certificate, []byte) {
+	key, err := pki.GenerateECPrivateKey(pki.ECCurve256)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "example.com"},
+		DNSNames:              []string{"example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		CRLDistributionPoints: []string{ca.server.URL + "/crl"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	certPEM, err := pki.EncodeX509(cert)
+	require.NoError(t, err)
+	return cert, certPEM
+}
+
+func TestCRLCheckerIsRevoked(t *testing.T) {
+	tests := map[string]struct {
+		// mutate may modify the CA or the certificate before it is checked
+		mutate func(t *testing.T, ca *testCA, cert *x509.Certificate) (*x509.Certificate, []*x509.Certificate)
+
+		expRevoked  bool
+		expErr      bool
+		expRequests int32
+	}{
+		"should report a certificate whose serial number is listed by the CRL as revoked": {
+			mutate: func(t *testing.T, ca *testCA, cert *x509.Certificate) (*x509.Certificate, []*x509.Certificate) {
+				ca.revoked = []*big.Int{big.NewInt(5), cert.SerialNumber}
+				return cert, []*x509.Certificate{ca.cert}
+			},
+			expRevoked:  true,
+			expRequests: 1,
+		},
+		"should not report a certificate that is not listed by the CRL as revoked": {
+			mutate: func(t *testing.T, ca *testCA, cert *x509.Certificate) (*x509.Certificate, []*x509.Certificate) {
+				ca.revoked = []*big.Int{big.NewInt(5)}
+				return cert, []*x509.Certificate{ca.cert}
+			},
+			expRevoked:  false,
+			expRequests: 1,
+		},
+		"should not fetch a CRL for a certificate without CRL distribution points": {
+			mutate: func(t *testing.T, ca *testCA, cert *x509.Certificate) (*x509.Certificate, []*x509.Certificate) {
+				ca.revoked = []*big.Int{cert.SerialNumber}
+				cert.CRLDistributionPoints = nil
+				return cert, []*x509.Certificate{ca.cert}
+			},
+			expRevoked:  false,
+			expRequests: 0,
+		},
+		"should error if the issuing certificate is not available": {
+			mutate: func(t *testing.T, ca *testCA, cert *x509.Certificate) (*x509.Certificate, []*x509.Certificate) {
+				ca.revoked = []*big.Int{cert.SerialNumber}
+				return cert, nil
+			},
+			expErr:      true,
+			expRequests: 0,
+		},
+		"should error if the CRL is not signed by the issuer of the certificate": {
+			mutate: func(t *testing.T, ca *testCA, cert *x509.Certificate) (*x509.Certificate, []*x509.Certificate) {
+				ca.revoked = []*big.Int{cert.SerialNumber}
+				other := newTestCA(t, ca.cert.Subject.CommonName)
+				crl := ca.crl(t, other)
+				ca.server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					atomic.AddInt32(&ca.requests, 1)
+					w.Write(crl)
+				})
+				return cert, []*x509.Certificate{ca.cert}
+			},
+			expErr:      true,
+			expRequests: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ca := newTestCA(t, "test-ca")
+			cert, _ := ca.issue(t, 1234)
+			cert, issuers := test.mutate(t, ca, cert)
+
+			checker := newCRLChecker(fakeclock.NewFakeClock(time.Now()), time.Hour)
+			revoked, err := checker.IsRevoked(context.Background(), cert, issuers)
+			if test.expErr != (err != nil) {
+				t.Errorf("expected error=%t, got: %v", test.expErr, err)
+			}
+			assert.Equal(t, test.expRevoked, revoked)
+			assert.Equal(t, test.expRequests, atomic.LoadInt32(&ca.requests))
+		})
+	}
+}
+
+func TestCRLCheckerCachesCRLs(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	cert, _ := ca.issue(t, 1234)
+	clock := fakeclock.NewFakeClock(time.Now())
+	checker := newCRLChecker(clock, time.Hour)
+
+	revoked, err := checker.IsRevoked(context.Background(), cert, []*x509.Certificate{ca.cert})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// the cached CRL is used until the refresh interval has elapsed
+	ca.revoked = []*big.Int{cert.SerialNumber}
+	revoked, err = checker.IsRevoked(context.Background(), cert, []*x509.Certificate{ca.cert})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&ca.requests))
+
+	clock.Step(time.Hour)
+	revoked, err = checker.IsRevoked(context.Background(), cert, []*x509.Certificate{ca.cert})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&ca.requests))
+}
@@ -18,9 +18,12 @@ package trigger
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,9 +44,12 @@ import (
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates/trigger/policies"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 	"github.com/jetstack/cert-manager/pkg/scheduler"
+	utilpki "github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/pkg/util/predicate"
+	"github.com/jetstack/cert-manager/pkg/util/tracing"
 )
 
 const (
@@ -69,6 +75,24 @@ type controller struct {
 	recorder                 record.EventRecorder
 	scheduledWorkQueue       scheduler.ScheduledWorkQueue
 
+	// revocationChecker, if set, is used to check whether the currently
+	// issued certificate has been revoked every crlCheckInterval.
+	revocationChecker revocationChecker
+	crlCheckInterval  time.Duration
+
+	// issuerHelper, if set, is used to read the issuer referenced by the
+	// next CertificateRequest, so that a failing Certificate is not backed
+	// off if its issuer has changed since the request was created.
+	issuerHelper issuer.Helper
+
+	// tracer is used to start the span that each issuance is traced from.
+	tracer trace.Tracer
+
+	// specSettle, if set, records changes to the spec of each Certificate
+	// so that its issuance is only triggered once the spec has been
+	// unchanged for the configured period.
+	specSettle *certificates.SpecSettleTracker
+
 	// The following are used for testing purposes.
 	clock              clock.Clock
 	shouldReissue      policies.Func
@@ -99,6 +123,8 @@ func NewController(
 		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(), predicate.ResourceOwnerOf),
 	})
 	// When a Secret resource changes, enqueue any Certificate resources that name it as spec.secretName.
+	// This includes the Secret being deleted, so that the Certificate is re-issued immediately rather
+	// than on the next resync.
 	secretsInformer.Informer().AddEventHandler(&controllerpkg.BlockingEventHandler{
 		// Trigger reconciles on changes to the Secret named `spec.secretName`
 		WorkFunc: certificates.EnqueueCertificatesForResourceUsingPredicates(log, queue, certificateInformer.Lister(), labels.Everything(),
@@ -120,6 +146,7 @@ func NewController(
 		client:                   client,
 		recorder:                 recorder,
 		scheduledWorkQueue:       scheduler.NewScheduledWorkQueue(clock, queue.Add),
+		tracer:                   tracing.Tracer(nil),
 
 		// The following are used for testing purposes.
 		clock:         clock,
@@ -143,11 +170,19 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 	crt, err := c.certificateLister.Certificates(namespace).Get(name)
 	if apierrors.IsNotFound(err) {
 		log.Error(err, "certificate not found for key")
+		if c.specSettle != nil {
+			c.specSettle.Forget(key)
+		}
 		return nil
 	}
 	if err != nil {
 		return err
 	}
+	// The generation is recorded before checking whether an issuance is in
+	// progress, so that a re-issuance for edits made during an issuance is
+	// not triggered until the spec has settled. The requestmanager defers
+	// replacing the in-progress CertificateRequest for such edits.
+	settleDelay, unsettled := c.specSettleDelay(key, crt)
 	if apiutil.CertificateHasCondition(crt, cmapi.CertificateCondition{
 		Type:   cmapi.CertificateConditionIssuing,
 		Status: cmmeta.ConditionTrue,
@@ -155,6 +190,18 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 		// Do nothing if an issuance is already in progress.
 		return nil
 	}
+	if crt.DeletionTimestamp != nil {
+		// Do nothing if the Certificate is being deleted, as its Secret may
+		// be deleted along with it.
+		log.V(logf.DebugLevel).Info("Certificate is being deleted, not checking whether it must be re-issued")
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, ControllerName, trace.WithAttributes(
+		attribute.String("namespace", crt.Namespace),
+		attribute.String("name", crt.Name),
+	))
+	defer span.End()
 
 	input, err := c.dataForCertificate(ctx, crt)
 	if err != nil {
@@ -163,24 +210,49 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 
 	// Back off from re-issuing immediately when the certificate has been
 	// in failing mode for less than 1 hour.
-	backoff, delay := shouldBackoffReissuingOnFailure(log, c.clock, input.Certificate, input.NextRevisionRequest)
+	backoff, delay := shouldBackoffReissuingOnFailure(log, c.clock, c.issuerHelper, input.Certificate, input.NextRevisionRequest)
+	if backoff && input.NextRevisionRequest != nil && certificates.RequestPredatesIssuerGeneration(c.issuerHelper, input.NextRevisionRequest) {
+		log.V(logf.ExtendedInfoLevel).Info("Certificate is failing but its issuer has changed since the CertificateRequest was created, backoff is not required")
+		backoff = false
+	}
+	if backoff && externalKeyProvisioned(input.Certificate, input.Secret) {
+		log.V(logf.ExtendedInfoLevel).Info("Certificate is failing but its externally managed private key has since been provisioned, backoff is not required")
+		backoff = false
+	}
 	if backoff {
 		log.V(logf.InfoLevel).Info("Not re-issuing certificate as an attempt has been made in the last hour", "retry_delay", delay)
 		c.scheduleRecheckOfCertificateIfRequired(log, key, delay)
 		return nil
 	}
 
-	if crt.Status.RenewalTime != nil {
-		// ensure a resync is scheduled in the future so that we re-check
-		// Certificate resources and trigger them near expiry time
-		c.scheduleRecheckOfCertificateIfRequired(log, key, crt.Status.RenewalTime.Time.Sub(c.clock.Now()))
+	// ensure a resync is scheduled in the future so that we re-check
+	// Certificate resources and trigger them near expiry time, and so that
+	// their revocation status is periodically checked
+	if delay, ok := c.recheckDelay(crt); ok {
+		c.scheduleRecheckOfCertificateIfRequired(log, key, delay)
 	}
 
 	reason, message, reissue := c.shouldReissue(input)
+	if !reissue && c.revocationChecker != nil {
+		reason, message, reissue = c.certificateRevoked(ctx, input)
+	}
 	if !reissue {
 		// no re-issuance required, return early
 		return nil
 	}
+	if reason == policies.Renewing {
+		if delay, ok := c.renewalWindowDelay(log, input); ok {
+			log.V(logf.InfoLevel).Info("Deferring renewal of certificate until its renewal window opens", "delay", delay)
+			c.scheduleRecheckOfCertificateIfRequired(log, key, delay)
+			return nil
+		}
+	}
+
+	if unsettled {
+		log.V(logf.InfoLevel).Info("Deferring issuance of certificate until its spec has stopped changing", "delay", settleDelay)
+		c.scheduleRecheckOfCertificateIfRequired(log, key, settleDelay)
+		return nil
+	}
 
 	// Although the below recorder.Event already logs the event, the log
 	// line is quite unreadable (very long). Since this information is very
@@ -190,6 +262,7 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 
 	crt = crt.DeepCopy()
 	apiutil.SetCertificateCondition(crt, crt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionTrue, reason, message)
+	certificates.RecordReconciliation(crt, ControllerName, c.clock.Now())
 	_, err = c.client.CertmanagerV1().Certificates(crt.Namespace).UpdateStatus(ctx, crt, metav1.UpdateOptions{})
 	if err != nil {
 		return err
@@ -206,7 +279,7 @@ func (c *controller) ProcessItem(ctx context.Context, key string) error {
 //
 // Note that the request can be left nil: in that case, the returned back-off
 // will be 0 since it means the CR must be created immediately.
-func shouldBackoffReissuingOnFailure(log logr.Logger, c clock.Clock, crt *cmapi.Certificate, nextCR *cmapi.CertificateRequest) (backoff bool, delay time.Duration) {
+func shouldBackoffReissuingOnFailure(log logr.Logger, c clock.Clock, helper issuer.Helper, crt *cmapi.Certificate, nextCR *cmapi.CertificateRequest) (backoff bool, delay time.Duration) {
 	if crt.Status.LastFailureTime == nil {
 		return false, 0
 	}
@@ -224,7 +297,7 @@ func shouldBackoffReissuingOnFailure(log logr.Logger, c clock.Clock, crt *cmapi.
 	if nextCR == nil {
 		log.V(logf.InfoLevel).Info("next CertificateRequest not available, skipping checking if Certificate matches the CertificateRequest")
 	} else {
-		mismatches, err := certificates.RequestMatchesSpec(nextCR, crt.Spec)
+		mismatches, err := certificates.RequestMatchesCertificate(helper, nextCR, crt)
 		if err != nil {
 			log.V(logf.InfoLevel).Info("next CertificateRequest cannot be decoded, skipping checking if Certificate matches the CertificateRequest")
 			return false, 0
@@ -233,6 +306,14 @@ func shouldBackoffReissuingOnFailure(log logr.Logger, c clock.Clock, crt *cmapi.
 			log.V(logf.ExtendedInfoLevel).WithValues("mismatches", mismatches).Info("Certificate is failing but the Certificate differs from CertificateRequest, backoff is not required")
 			return false, 0
 		}
+		// A denied CertificateRequest may still match the spec if the change
+		// was to a field that is not part of the request, such as the
+		// private key, so also compare against the generation it was created
+		// for.
+		if apiutil.CertificateRequestIsDenied(nextCR) && certificates.RequestPredatesGeneration(nextCR, crt) {
+			log.V(logf.ExtendedInfoLevel).Info("Certificate is failing but the Certificate has changed since the CertificateRequest was denied, backoff is not required")
+			return false, 0
+		}
 	}
 
 	now := c.Now()
@@ -244,6 +325,84 @@ func shouldBackoffReissuingOnFailure(log logr.Logger, c clock.Clock, crt *cmapi.
 	return true, retryAfterLastFailure - durationSinceFailure
 }
 
+// recheckDelay returns how long to wait before the Certificate is next
+// checked, which is the sooner of its renewal time and the next revocation
+// check. False is returned if no recheck is required.
+func (c *controller) recheckDelay(crt *cmapi.Certificate) (time.Duration, bool) {
+	var delay time.Duration
+	ok := false
+	if crt.Status.RenewalTime != nil {
+		delay, ok = crt.Status.RenewalTime.Time.Sub(c.clock.Now()), true
+	}
+	if c.revocationChecker != nil && (!ok || c.crlCheckInterval < delay) {
+		delay, ok = c.crlCheckInterval, true
+	}
+	return delay, ok
+}
+
+// renewalWindowDelay returns how long the renewal of the Certificate must be
+// deferred for until its renewal window next opens. False is returned if the
+// Certificate has no renewal window or the window is open. It is also
+// returned if the current certificate would expire within
+// apiutil.RenewalWindowExpiryMargin of the window opening, so that it is
+// renewed before it expires.
+func (c *controller) renewalWindowDelay(log logr.Logger, input policies.Input) (time.Duration, bool) {
+	window := input.Certificate.Spec.RenewalWindow
+	if window == nil {
+		return 0, false
+	}
+
+	cert, err := utilpki.DecodeX509CertificateBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		log.Error(err, "failed to decode the certificate to check its expiry, not deferring renewal")
+		return 0, false
+	}
+
+	now := c.clock.Now()
+	next, err := apiutil.RenewalTimeInWindow(window, now, cert.NotAfter)
+	if err != nil {
+		log.Error(err, "invalid renewal window, not deferring renewal")
+		return 0, false
+	}
+	if !next.After(now) {
+		return 0, false
+	}
+
+	return next.Sub(now), true
+}
+
+// certificateRevoked triggers an issuance if the certificate currently stored
+// in the Secret has been revoked by its issuer. Errors checking the revocation
+// status are logged and do not trigger an issuance, as the check is retried
+// after the next crlCheckInterval.
+func (c *controller) certificateRevoked(ctx context.Context, input policies.Input) (string, string, bool) {
+	log := logf.FromContext(ctx)
+
+	certs, err := utilpki.DecodeX509CertificateChainBytes(input.Secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		log.Error(err, "failed to decode the certificate to check its revocation status")
+		return "", "", false
+	}
+
+	// The issuing certificate is usually the first intermediate in the
+	// chain, but may be the CA certificate if the chain has no intermediates.
+	issuers := certs[1:]
+	if caCerts, err := utilpki.DecodeX509CertificateChainBytes(input.Secret.Data[cmmeta.TLSCAKey]); err == nil {
+		issuers = append(issuers, caCerts...)
+	}
+
+	revoked, err := c.revocationChecker.IsRevoked(ctx, certs[0], issuers)
+	if err != nil {
+		log.Error(err, "failed to check the revocation status of the certificate")
+		return "", "", false
+	}
+	if !revoked {
+		return "", "", false
+	}
+
+	return policies.Revoked, fmt.Sprintf("Issuing certificate as the current certificate with serial number %s has been revoked by its issuer", certs[0].SerialNumber.Text(16)), true
+}
+
 // scheduleRecheckOfCertificateIfRequired will schedule the resource with the
 // given key to be re-queued for processing after the given amount of time
 // has elapsed.
@@ -265,6 +424,16 @@ func (c *controller) scheduleRecheckOfCertificateIfRequired(log logr.Logger, key
 	c.scheduledWorkQueue.Add(key, durationUntilRenewalTime)
 }
 
+// specSettleDelay returns how long the issuance of the Certificate must be
+// deferred for until its spec has settled. False is returned if debouncing is
+// disabled or the spec has settled.
+func (c *controller) specSettleDelay(key string, crt *cmapi.Certificate) (time.Duration, bool) {
+	if c.specSettle == nil {
+		return 0, false
+	}
+	return c.specSettle.Delay(key, crt)
+}
+
 // controllerWrapper wraps the `controller` structure to make it implement
 // the controllerpkg.queueingController interface
 type controllerWrapper struct {
@@ -283,11 +452,38 @@ func (c *controllerWrapper) Register(ctx *controllerpkg.Context) (workqueue.Rate
 		ctx.Clock,
 		policies.NewTriggerPolicyChain(ctx.Clock, cmapi.DefaultRenewBefore).Evaluate,
 	)
+	if interval := ctx.CertificateOptions.CRLCheckInterval; interval > 0 {
+		ctrl.revocationChecker = newCRLChecker(ctx.Clock, interval)
+		ctrl.crlCheckInterval = interval
+	}
+	if debounce := ctx.CertificateOptions.SpecChangeDebounce; debounce > 0 {
+		ctrl.specSettle = certificates.NewSpecSettleTracker(ctx.Clock, debounce)
+	}
+
+	// When an Issuer or ClusterIssuer changes, enqueue the Certificates that
+	// reference it so that any back-off from a failed issuance is reset.
+	certificates.EnqueueCertificatesForIssuers(ctx, log, queue, ctrl.certificateLister)
+	issuerHelper, issuerSynced := certificates.NewIssuerHelper(ctx)
+	ctrl.issuerHelper = issuerHelper
+	mustSync = append(mustSync, issuerSynced...)
+	ctrl.tracer = tracing.Tracer(ctx.TracerProvider)
 	c.controller = ctrl
 
 	return queue, mustSync, nil
 }
 
+// externalKeyProvisioned returns true if the last issuance of the Certificate
+// failed as its externally managed private key did not exist, and the given
+// Secret now contains a private key.
+func externalKeyProvisioned(crt *cmapi.Certificate, secret *corev1.Secret) bool {
+	cond := apiutil.GetCertificateCondition(crt, cmapi.CertificateConditionIssuing)
+	if cond == nil || cond.Reason != certificates.ReasonExternalKeyMissing || secret == nil {
+		return false
+	}
+	secret = apiutil.SecretWithDefaultKeyNames(crt, secret)
+	return len(secret.Data[corev1.TLSPrivateKeyKey]) > 0
+}
+
 func init() {
 	controllerpkg.Register(ControllerName, func(ctx *controllerpkg.Context) (controllerpkg.Interface, error) {
 		return controllerpkg.NewBuilder(ctx, ControllerName).
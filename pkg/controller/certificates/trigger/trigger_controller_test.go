@@ -19,20 +19,29 @@ package trigger
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
 	logtest "github.com/go-logr/logr/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	coretesting "k8s.io/client-go/testing"
 	fakeclock "k8s.io/utils/clock/testing"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	controllerpkg "github.com/jetstack/cert-manager/pkg/controller"
 	internaltest "github.com/jetstack/cert-manager/pkg/controller/certificates/internal/test"
 	"github.com/jetstack/cert-manager/pkg/controller/certificates/trigger/policies"
 	testpkg "github.com/jetstack/cert-manager/pkg/controller/test"
+	schedulertest "github.com/jetstack/cert-manager/pkg/scheduler/test"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
 	"github.com/jetstack/cert-manager/test/unit/gen"
 )
 
@@ -55,6 +64,9 @@ func Test_controller_ProcessItem(t *testing.T) {
 		// passed to ProcessItem instead.
 		existingCertificate *cmapi.Certificate
 
+		// Issuer to be loaded for the test, if any.
+		existingIssuer *cmapi.Issuer
+
 		mockDataForCertificateReturn    policies.Input
 		mockDataForCertificateReturnErr error
 		wantDataForCertificateCalled    bool
@@ -94,6 +106,12 @@ func Test_controller_ProcessItem(t *testing.T) {
 				}),
 			),
 		},
+		"should do nothing if Certificate is being deleted": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateSecretName("secret-1"),
+				gen.SetCertificateDeletionTimestamp(fixedNow),
+			),
+		},
 		"should call shouldReissue with the correct cert, secret and current CR": {
 			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
 				gen.SetCertificateSecretName("secret-1"),
@@ -207,6 +225,61 @@ func Test_controller_ProcessItem(t *testing.T) {
 				ObservedGeneration: 42,
 			}},
 		},
+		"should not set Issuing=True when cert has been failing for 59 minutes and the issuer has not changed since the next CR was created": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateRevision(1),
+				gen.SetCertificateDNSNames("example.com"),
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+				gen.SetCertificateLastFailureTime(metav1.NewTime(fixedNow.Add(-59*time.Minute))),
+			),
+			existingIssuer:               gen.Issuer("issuer-1", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(2)),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{
+				NextRevisionRequest: gen.CertificateRequestFrom(createCertificateRequestOrPanic(gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+					gen.SetCertificateUID("cert-1-uid"),
+					gen.SetCertificateRevision(2),
+					gen.SetCertificateDNSNames("example.com"),
+					gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+				)), gen.AddCertificateRequestAnnotations(map[string]string{cmapi.CertificateRequestIssuerGenerationAnnotationKey: "2"})),
+			},
+			wantShouldReissueCalled: false,
+		},
+		"should set Issuing=True when cert has been failing for 59 minutes but the issuer has changed since the next CR was created": {
+			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateGeneration(42),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateRevision(1),
+				gen.SetCertificateDNSNames("example.com"),
+				gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+				gen.SetCertificateLastFailureTime(metav1.NewTime(fixedNow.Add(-59*time.Minute))),
+			),
+			existingIssuer:               gen.Issuer("issuer-1", gen.SetIssuerNamespace("testns"), gen.SetIssuerGeneration(3)),
+			wantDataForCertificateCalled: true,
+			mockDataForCertificateReturn: policies.Input{
+				NextRevisionRequest: gen.CertificateRequestFrom(createCertificateRequestOrPanic(gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+					gen.SetCertificateUID("cert-1-uid"),
+					gen.SetCertificateRevision(2),
+					gen.SetCertificateDNSNames("example.com"),
+					gen.SetCertificateIssuer(cmmeta.ObjectReference{Name: "issuer-1"}),
+				)), gen.AddCertificateRequestAnnotations(map[string]string{cmapi.CertificateRequestIssuerGenerationAnnotationKey: "2"})),
+			},
+			wantShouldReissueCalled: true,
+			mockShouldReissue: func(*testing.T) policies.Func {
+				return func(policies.Input) (string, string, bool) {
+					return "DoesNotExist", "Issuing certificate as Secret does not exist", true
+				}
+			},
+			wantEvent: "Normal Issuing Issuing certificate as Secret does not exist",
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               "Issuing",
+				Status:             "True",
+				Reason:             "DoesNotExist",
+				Message:            "Issuing certificate as Secret does not exist",
+				LastTransitionTime: &fixedNow,
+				ObservedGeneration: 42,
+			}},
+		},
 		"should set Issuing=True when cert has been failing for 61 minutes and shouldReissue returns true": {
 			existingCertificate: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
 				gen.SetCertificateGeneration(42),
@@ -240,6 +313,9 @@ func Test_controller_ProcessItem(t *testing.T) {
 			if test.existingCertificate != nil {
 				builder.CertManagerObjects = append(builder.CertManagerObjects, test.existingCertificate)
 			}
+			if test.existingIssuer != nil {
+				builder.CertManagerObjects = append(builder.CertManagerObjects, test.existingIssuer)
+			}
 			builder.Init()
 
 			w := &controllerWrapper{}
@@ -275,6 +351,9 @@ func Test_controller_ProcessItem(t *testing.T) {
 				}
 				expectedCert := test.existingCertificate.DeepCopy()
 				expectedCert.Status.Conditions = test.wantConditions
+				expectedCert.Status.LastReconciledBy = []cmapi.CertificateReconciliationRecord{
+					{Controller: ControllerName, Time: fixedNow},
+				}
 				builder.ExpectedActions = append(builder.ExpectedActions,
 					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
 						cmapi.SchemeGroupVersion.WithResource("certificates"),
@@ -437,12 +516,515 @@ func Test_shouldBackoffReissuingOnFailure(t *testing.T) {
 			)),
 			wantBackoff: false,
 		},
+		"should not back off from reissuing when the next CR was denied for an older generation of the cert": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateGeneration(2),
+				gen.SetCertificateRevision(1),
+				gen.SetCertificateDNSNames("example.com"),
+				gen.SetCertificateLastFailureTime(metav1.NewTime(clock.Now())),
+			),
+			givenNextCR: gen.CertificateRequestFrom(createCertificateRequestOrPanic(gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateRevision(1),
+				gen.SetCertificateDNSNames("example.com"),
+			)),
+				gen.SetCertificateRequestAnnotations(map[string]string{cmapi.CertificateRequestCertificateGenerationAnnotationKey: "1"}),
+				gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionDenied,
+					Status: cmmeta.ConditionTrue,
+				}),
+			),
+			wantBackoff: false,
+		},
+		"should back off from reissuing when the next CR was denied for the current generation of the cert": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateGeneration(2),
+				gen.SetCertificateRevision(1),
+				gen.SetCertificateDNSNames("example.com"),
+				gen.SetCertificateLastFailureTime(metav1.NewTime(clock.Now())),
+			),
+			givenNextCR: gen.CertificateRequestFrom(createCertificateRequestOrPanic(gen.Certificate("cert-1", gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateUID("cert-1-uid"),
+				gen.SetCertificateRevision(1),
+				gen.SetCertificateDNSNames("example.com"),
+			)),
+				gen.SetCertificateRequestAnnotations(map[string]string{cmapi.CertificateRequestCertificateGenerationAnnotationKey: "2"}),
+				gen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionDenied,
+					Status: cmmeta.ConditionTrue,
+				}),
+			),
+			wantBackoff: true,
+			wantDelay:   1 * time.Hour,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotBackoff, gotDelay := shouldBackoffReissuingOnFailure(logtest.TestLogger{T: t}, clock, test.givenCert, test.givenNextCR)
+			gotBackoff, gotDelay := shouldBackoffReissuingOnFailure(logtest.TestLogger{T: t}, clock, nil, test.givenCert, test.givenNextCR)
 			assert.Equal(t, test.wantBackoff, gotBackoff)
 			assert.Equal(t, test.wantDelay, gotDelay)
 		})
 	}
 }
+
+func Test_externalKeyProvisioned(t *testing.T) {
+	externalKeyMissing := gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+		Type:   cmapi.CertificateConditionIssuing,
+		Status: cmmeta.ConditionFalse,
+		Reason: "ExternalKeyMissing",
+	})
+	secretWithKey := gen.Secret("secret-1", gen.SetSecretData(map[string][]byte{corev1.TLSPrivateKeyKey: []byte("key")}))
+	secretWithoutKey := gen.Secret("secret-1", gen.SetSecretData(map[string][]byte{corev1.TLSCertKey: []byte("cert")}))
+
+	tests := map[string]struct {
+		givenCert   *cmapi.Certificate
+		givenSecret *corev1.Secret
+		want        bool
+	}{
+		"the external key has been provisioned since the issuance failed": {
+			givenCert:   gen.Certificate("cert-1", gen.SetCertificateSecretName("secret-1"), externalKeyMissing),
+			givenSecret: secretWithKey,
+			want:        true,
+		},
+		"the external key has not been provisioned yet": {
+			givenCert:   gen.Certificate("cert-1", gen.SetCertificateSecretName("secret-1"), externalKeyMissing),
+			givenSecret: secretWithoutKey,
+		},
+		"the Secret does not exist yet": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateSecretName("secret-1"), externalKeyMissing),
+		},
+		"the issuance failed for another reason": {
+			givenCert: gen.Certificate("cert-1", gen.SetCertificateSecretName("secret-1"), gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+				Type:   cmapi.CertificateConditionIssuing,
+				Status: cmmeta.ConditionFalse,
+				Reason: "Failed",
+			})),
+			givenSecret: secretWithKey,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, externalKeyProvisioned(test.givenCert, test.givenSecret))
+		})
+	}
+}
+
+func Test_controller_ProcessItem_revokedCertificate(t *testing.T) {
+	fixedNow := metav1.NewTime(time.Now())
+	fixedClock := fakeclock.NewFakeClock(fixedNow.Time)
+
+	ca := newTestCA(t, "test-ca")
+	caPEM, err := pki.EncodeX509(ca.cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		revoked        bool
+		wantEvent      string
+		wantConditions []cmapi.CertificateCondition
+	}{
+		"should set Issuing=True if the current certificate is listed by its issuer's CRL": {
+			revoked:   true,
+			wantEvent: "Normal Issuing Issuing certificate as the current certificate with serial number 4d2 has been revoked by its issuer",
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               cmapi.CertificateConditionIssuing,
+				Status:             cmmeta.ConditionTrue,
+				Reason:             "Revoked",
+				Message:            "Issuing certificate as the current certificate with serial number 4d2 has been revoked by its issuer",
+				LastTransitionTime: &fixedNow,
+			}},
+		},
+		"should do nothing if the current certificate is not listed by its issuer's CRL": {
+			revoked: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, certPEM := ca.issue(t, 1234)
+			ca.revoked = nil
+			if test.revoked {
+				ca.revoked = []*big.Int{big.NewInt(1234)}
+			}
+
+			crt := gen.Certificate("test", gen.SetCertificateNamespace("testns"), gen.SetCertificateSecretName("test"))
+			builder := &testpkg.Builder{
+				T:                  t,
+				Clock:              fixedClock,
+				CertManagerObjects: []runtime.Object{crt},
+			}
+			builder.Init()
+
+			w := &controllerWrapper{}
+			if _, _, err := w.Register(builder.Context); err != nil {
+				t.Fatal(err)
+			}
+			w.revocationChecker = newCRLChecker(fixedClock, time.Hour)
+			w.crlCheckInterval = time.Hour
+			w.shouldReissue = func(policies.Input) (string, string, bool) {
+				return "", "", false
+			}
+			w.dataForCertificate = func(context.Context, *cmapi.Certificate) (policies.Input, error) {
+				return policies.Input{
+					Certificate: crt,
+					Secret: &corev1.Secret{Data: map[string][]byte{
+						corev1.TLSCertKey: certPEM,
+						cmmeta.TLSCAKey:   caPEM,
+					}},
+				}, nil
+			}
+
+			if test.wantConditions != nil {
+				expectedCert := crt.DeepCopy()
+				expectedCert.Status.Conditions = test.wantConditions
+				expectedCert.Status.LastReconciledBy = []cmapi.CertificateReconciliationRecord{
+					{Controller: ControllerName, Time: fixedNow},
+				}
+				builder.ExpectedActions = append(builder.ExpectedActions,
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						crt.Namespace,
+						expectedCert,
+					)),
+				)
+			}
+			if test.wantEvent != "" {
+				builder.ExpectedEvents = []string{test.wantEvent}
+			}
+
+			builder.Start()
+			defer builder.Stop()
+
+			if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			builder.CheckAndFinish()
+		})
+	}
+}
+
+func Test_controller_ProcessItem_renewalWindow(t *testing.T) {
+	fixedNow := metav1.NewTime(time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC))
+	fixedClock := fakeclock.NewFakeClock(fixedNow.Time)
+	privKey := internaltest.MustCreatePEMPrivateKey(t)
+
+	tests := map[string]struct {
+		window         cmapi.CertificateRenewalWindow
+		reason         string
+		notAfter       time.Time
+		wantConditions []cmapi.CertificateCondition
+	}{
+		"should defer the renewal if the renewal window is closed": {
+			window:   cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}},
+			reason:   policies.Renewing,
+			notAfter: fixedNow.Add(48 * time.Hour),
+		},
+		"should renew if the renewal window is open": {
+			window:   cmapi.CertificateRenewalWindow{Ranges: []string{"10:00-14:00"}},
+			reason:   policies.Renewing,
+			notAfter: fixedNow.Add(48 * time.Hour),
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               cmapi.CertificateConditionIssuing,
+				Status:             cmmeta.ConditionTrue,
+				Reason:             policies.Renewing,
+				Message:            "Re-issuing",
+				LastTransitionTime: &fixedNow,
+			}},
+		},
+		"should renew if the renewal window is closed but the certificate expires before it opens": {
+			window:   cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}},
+			reason:   policies.Renewing,
+			notAfter: fixedNow.Add(3 * time.Hour),
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               cmapi.CertificateConditionIssuing,
+				Status:             cmmeta.ConditionTrue,
+				Reason:             policies.Renewing,
+				Message:            "Re-issuing",
+				LastTransitionTime: &fixedNow,
+			}},
+		},
+		"should renew if the renewal window is closed but the certificate expires shortly after it opens": {
+			window:   cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}},
+			reason:   policies.Renewing,
+			notAfter: fixedNow.Add(6*time.Hour + 30*time.Minute),
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               cmapi.CertificateConditionIssuing,
+				Status:             cmmeta.ConditionTrue,
+				Reason:             policies.Renewing,
+				Message:            "Re-issuing",
+				LastTransitionTime: &fixedNow,
+			}},
+		},
+		"should not defer re-issuances for reasons other than renewal": {
+			window:   cmapi.CertificateRenewalWindow{Ranges: []string{"18:00-22:00"}},
+			reason:   policies.SecretMismatch,
+			notAfter: fixedNow.Add(48 * time.Hour),
+			wantConditions: []cmapi.CertificateCondition{{
+				Type:               cmapi.CertificateConditionIssuing,
+				Status:             cmmeta.ConditionTrue,
+				Reason:             policies.SecretMismatch,
+				Message:            "Re-issuing",
+				LastTransitionTime: &fixedNow,
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := gen.Certificate("test",
+				gen.SetCertificateNamespace("testns"),
+				gen.SetCertificateSecretName("test"),
+				gen.SetCertificateCommonName("example.com"),
+				gen.SetCertificateRenewalWindow(test.window),
+			)
+			certPEM := internaltest.MustCreateCertWithNotBeforeAfter(t, privKey, crt, fixedNow.Add(-24*time.Hour), test.notAfter)
+
+			builder := &testpkg.Builder{
+				T:                  t,
+				Clock:              fixedClock,
+				CertManagerObjects: []runtime.Object{crt},
+			}
+			builder.Init()
+
+			w := &controllerWrapper{}
+			if _, _, err := w.Register(builder.Context); err != nil {
+				t.Fatal(err)
+			}
+			w.shouldReissue = func(policies.Input) (string, string, bool) {
+				return test.reason, "Re-issuing", true
+			}
+			w.dataForCertificate = func(context.Context, *cmapi.Certificate) (policies.Input, error) {
+				return policies.Input{
+					Certificate: crt,
+					Secret: &corev1.Secret{Data: map[string][]byte{
+						corev1.TLSCertKey: certPEM,
+					}},
+				}, nil
+			}
+
+			if test.wantConditions != nil {
+				expectedCert := crt.DeepCopy()
+				expectedCert.Status.Conditions = test.wantConditions
+				expectedCert.Status.LastReconciledBy = []cmapi.CertificateReconciliationRecord{
+					{Controller: ControllerName, Time: fixedNow},
+				}
+				builder.ExpectedActions = append(builder.ExpectedActions,
+					testpkg.NewAction(coretesting.NewUpdateSubresourceAction(
+						cmapi.SchemeGroupVersion.WithResource("certificates"),
+						"status",
+						crt.Namespace,
+						expectedCert,
+					)),
+				)
+				builder.ExpectedEvents = []string{"Normal Issuing Re-issuing"}
+			}
+
+			builder.Start()
+			defer builder.Stop()
+
+			if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			builder.CheckAndFinish()
+		})
+	}
+}
+
+func Test_controller_ProcessItem_tracing(t *testing.T) {
+	fixedNow := metav1.NewTime(time.Now())
+	fixedClock := fakeclock.NewFakeClock(fixedNow.Time)
+	recorder := tracetest.NewSpanRecorder()
+
+	crt := gen.Certificate("test", gen.SetCertificateNamespace("testns"), gen.SetCertificateSecretName("test"))
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		CertManagerObjects: []runtime.Object{crt},
+		ExpectedEvents:     []string{"Normal Issuing Re-issuing"},
+	}
+	builder.Init()
+	builder.Context.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	w.shouldReissue = func(policies.Input) (string, string, bool) {
+		return "Reissue", "Re-issuing", true
+	}
+	w.dataForCertificate = func(context.Context, *cmapi.Certificate) (policies.Input, error) {
+		return policies.Input{Certificate: crt}, nil
+	}
+
+	builder.Start()
+	defer builder.Stop()
+
+	if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, ControllerName, spans[0].Name())
+	assert.False(t, spans[0].Parent().IsValid())
+
+	// The trace context is not recorded on the Certificate, as it belongs
+	// to the user.
+	expectedCert := gen.CertificateFrom(crt,
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+			Type:               cmapi.CertificateConditionIssuing,
+			Status:             cmmeta.ConditionTrue,
+			Reason:             "Reissue",
+			Message:            "Re-issuing",
+			LastTransitionTime: &fixedNow,
+		}),
+		gen.AddCertificateLastReconciledBy(ControllerName, fixedNow),
+	)
+	builder.ExpectedActions = []testpkg.Action{
+		testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmapi.SchemeGroupVersion.WithResource("certificates"), "status", crt.Namespace, expectedCert)),
+	}
+	builder.CheckAndFinish()
+}
+
+func Test_controller_ProcessItem_specChangeDebounce(t *testing.T) {
+	fixedNow := metav1.NewTime(time.Date(2021, time.May, 4, 12, 0, 0, 0, time.UTC))
+	fixedClock := fakeclock.NewFakeClock(fixedNow.Time)
+	const debounce = 10 * time.Second
+
+	crt := gen.Certificate("test",
+		gen.SetCertificateNamespace("testns"),
+		gen.SetCertificateSecretName("test"),
+		gen.SetCertificateGeneration(1),
+	)
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fixedClock,
+		CertManagerObjects: []runtime.Object{crt},
+	}
+	builder.Init()
+	builder.Context.CertificateOptions.SpecChangeDebounce = debounce
+
+	w := &controllerWrapper{}
+	if _, _, err := w.Register(builder.Context); err != nil {
+		t.Fatal(err)
+	}
+	var scheduled []time.Duration
+	w.scheduledWorkQueue = &schedulertest.FakeScheduler{
+		AddFunc: func(_ interface{}, delay time.Duration) {
+			scheduled = append(scheduled, delay)
+		},
+	}
+	// The first generation is already issued, and every later generation
+	// requires a re-issuance.
+	w.shouldReissue = func(input policies.Input) (string, string, bool) {
+		return policies.SecretMismatch, "Re-issuing", input.Certificate.Generation > 1
+	}
+	w.dataForCertificate = func(_ context.Context, crt *cmapi.Certificate) (policies.Input, error) {
+		return policies.Input{Certificate: crt}, nil
+	}
+
+	builder.Start()
+	defer builder.Stop()
+
+	indexer := builder.SharedInformerFactory.Certmanager().V1().Certificates().Informer().GetIndexer()
+	processAt := func(offset time.Duration) {
+		fixedClock.SetTime(fixedNow.Add(offset))
+		if err := w.controller.ProcessItem(context.Background(), "testns/test"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	update := func(generation int64, offset time.Duration) {
+		if err := indexer.Update(gen.CertificateFrom(crt, gen.SetCertificateGeneration(generation))); err != nil {
+			t.Fatal(err)
+		}
+		processAt(offset)
+	}
+
+	processAt(0)
+	// Three edits in quick succession each defer the issuance until the
+	// spec has been unchanged for the debounce period.
+	update(2, time.Second)
+	update(3, 3*time.Second)
+	update(4, 5*time.Second)
+	assert.Equal(t, []time.Duration{debounce, debounce, debounce}, scheduled)
+	// Processing the Certificate again before the spec has settled defers
+	// the issuance for the remainder of the period.
+	processAt(9 * time.Second)
+	assert.Equal(t, 6*time.Second, scheduled[3])
+
+	// Once the spec has settled a single issuance is triggered.
+	settledAt := metav1.NewTime(fixedNow.Add(15 * time.Second))
+	expectedCert := gen.CertificateFrom(crt,
+		gen.SetCertificateGeneration(4),
+		gen.SetCertificateStatusCondition(cmapi.CertificateCondition{
+			Type:               cmapi.CertificateConditionIssuing,
+			Status:             cmmeta.ConditionTrue,
+			Reason:             policies.SecretMismatch,
+			Message:            "Re-issuing",
+			LastTransitionTime: &settledAt,
+			ObservedGeneration: 4,
+		}),
+		gen.AddCertificateLastReconciledBy(ControllerName, settledAt),
+	)
+	builder.ExpectedActions = []testpkg.Action{
+		testpkg.NewAction(coretesting.NewUpdateSubresourceAction(cmapi.SchemeGroupVersion.WithResource("certificates"), "status", crt.Namespace, expectedCert)),
+	}
+	builder.ExpectedEvents = []string{"Normal Issuing Re-issuing"}
+	processAt(15 * time.Second)
+	assert.Len(t, scheduled, 4)
+
+	builder.CheckAndFinish()
+}
+
+func Test_controller_secretDeletedEnqueuesCertificate(t *testing.T) {
+	crt := gen.Certificate("test", gen.SetCertificateNamespace("testns"), gen.SetCertificateSecretName("test-secret"))
+	other := gen.Certificate("other", gen.SetCertificateNamespace("testns"), gen.SetCertificateSecretName("other-secret"))
+	builder := &testpkg.Builder{
+		T:                  t,
+		Clock:              fakeclock.NewFakeClock(time.Now()),
+		CertManagerObjects: []runtime.Object{crt, other},
+		KubeObjects:        []runtime.Object{gen.Secret("test-secret", gen.SetSecretNamespace("testns"))},
+	}
+	builder.Init()
+
+	w := &controllerWrapper{}
+	queue, _, err := w.Register(builder.Context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.Start()
+	defer builder.Stop()
+
+	get := func() string {
+		keys := make(chan string, 1)
+		go func() {
+			key, _ := queue.Get()
+			keys <- key.(string)
+		}()
+		select {
+		case key := <-keys:
+			queue.Done(key)
+			return key
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a Certificate to be enqueued")
+			return ""
+		}
+	}
+
+	// Drain the keys enqueued when the informers first synced.
+	for queue.Len() > 0 {
+		get()
+	}
+
+	err = builder.FakeKubeClient().CoreV1().Secrets("testns").Delete(context.Background(), "test-secret", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "testns/test", get())
+	assert.Equal(t, 0, queue.Len())
+}
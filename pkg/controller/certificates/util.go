@@ -20,19 +20,30 @@ import (
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/rsa"
+	"crypto/x509"
 	"fmt"
 	"reflect"
+	"strconv"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/jetstack/cert-manager/pkg/issuer"
 	"github.com/jetstack/cert-manager/pkg/util"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
+// ReasonExternalKeyMissing is the reason of the Issuing condition of
+// Certificates with the External private key rotation policy whose issuance
+// failed as the private key had not been provisioned in spec.secretName.
+const ReasonExternalKeyMissing = "ExternalKeyMissing"
+
 // PrivateKeyMatchesSpec returns an error if the private key bit size
 // doesn't match the provided spec. Both RSA and ECDSA are supported.
 // If any error is returned, a list of violations will also be returned.
@@ -87,7 +98,9 @@ func ecdsaPrivateKeyMatchesSpec(pk crypto.PrivateKey, spec cmapi.CertificateSpec
 	if spec.PrivateKey.Size > 0 {
 		expectedKeySize = spec.PrivateKey.Size
 	}
-	if expectedKeySize != ecdsaPk.Curve.Params().BitSize {
+	// An unsupported key size can never be matched by an existing key.
+	expectedCurve, err := pki.ECCurveForKeySize(expectedKeySize)
+	if err != nil || expectedCurve.Params().Name != ecdsaPk.Curve.Params().Name {
 		violations = append(violations, "spec.keySize")
 	}
 	return violations, nil
@@ -96,6 +109,10 @@ func ecdsaPrivateKeyMatchesSpec(pk crypto.PrivateKey, spec cmapi.CertificateSpec
 // RequestMatchesSpec compares a CertificateRequest with a CertificateSpec
 // and returns a list of field names on the Certificate that do not match their
 // counterpart fields on the CertificateRequest.
+// The Common Name is compared against the one derived from the dnsNames if
+// the request has the CommonNameFromDNSNamesAnnotationKey annotation, which
+// is copied from the Certificate. The Common Name is not expected in the
+// dnsNames or ipAddresses if excludeCommonNameFromSANs is set.
 // If decoding the x509 certificate request fails, an error will be returned.
 func RequestMatchesSpec(req *cmapi.CertificateRequest, spec cmapi.CertificateSpec) ([]string, error) {
 	x509req, err := pki.DecodeX509CertificateRequestBytes(req.Spec.Request)
@@ -103,6 +120,78 @@ func RequestMatchesSpec(req *cmapi.CertificateRequest, spec cmapi.CertificateSpe
 		return nil, err
 	}
 
+	var violations []string
+	// The subject and alt names of a user supplied CSR are not defined on the
+	// Certificate spec, so there is nothing to compare them against.
+	if spec.CSRSecretRef == nil {
+		// It is safe to mutate `spec` as it is not a pointer.
+		crt := &cmapi.Certificate{
+			ObjectMeta: metav1.ObjectMeta{Annotations: req.Annotations},
+			Spec:       spec,
+		}
+		spec.CommonName, _ = pki.CommonNameForCertificate(crt)
+		spec.DNSNames = pki.SANsWithoutCommonName(crt, spec.DNSNames)
+		spec.IPAddresses = pki.SANsWithoutCommonName(crt, spec.IPAddresses)
+		violations = requestSubjectMatchesSpec(x509req, spec)
+	}
+	if spec.CSRSignatureAlgorithm != "" && spec.CSRSecretRef == nil {
+		_, sigAlgo, err := pki.SignatureAlgorithm(&cmapi.Certificate{Spec: spec})
+		if err == nil && x509req.SignatureAlgorithm != sigAlgo {
+			violations = append(violations, "spec.csrSignatureAlgorithm")
+		}
+	}
+	if req.Spec.IsCA != spec.IsCA {
+		violations = append(violations, "spec.isCA")
+	}
+	if !int32PtrsEqual(req.Spec.MaxPathLen, spec.MaxPathLen) {
+		violations = append(violations, "spec.maxPathLen")
+	}
+	if req.Spec.SignatureAlgorithm != spec.SignatureAlgorithm {
+		violations = append(violations, "spec.signatureAlgorithm")
+	}
+	if !util.EqualKeyUsagesUnsorted(req.Spec.Usages, spec.Usages) {
+		violations = append(violations, "spec.usages")
+	}
+	if spec.Duration != nil && req.Spec.Duration != nil &&
+		spec.Duration.Duration != req.Spec.Duration.Duration {
+		violations = append(violations, "spec.duration")
+	}
+	// The requested notBefore is only used for the first issuance, so a
+	// request without one, such as for a renewal, still matches the spec.
+	if req.Spec.NotBefore != nil && !timesEqual(spec.NotBefore, req.Spec.NotBefore) {
+		violations = append(violations, "spec.notBefore")
+	}
+	if issuerRefIndex(spec, req.Spec.IssuerRef) < 0 {
+		violations = append(violations, "spec.issuerRef")
+	}
+
+	return violations, nil
+}
+
+// RequestMatchesCertificate is like RequestMatchesSpec, but the issuerRef of
+// a staging request is allowed to reference the staging issuer of the given
+// Certificate instead of one of its issuers.
+func RequestMatchesCertificate(helper issuer.Helper, req *cmapi.CertificateRequest, crt *cmapi.Certificate) ([]string, error) {
+	violations, err := RequestMatchesSpec(req, crt.Spec)
+	if err != nil || !IsStagingRequest(req) {
+		return violations, err
+	}
+	if ref, ok := StagingIssuerRef(helper, crt); !ok || ref != req.Spec.IssuerRef {
+		return violations, nil
+	}
+	var remaining []string
+	for _, violation := range violations {
+		if violation != "spec.issuerRef" {
+			remaining = append(remaining, violation)
+		}
+	}
+	return remaining, nil
+}
+
+// requestSubjectMatchesSpec compares the subject and alt names of an x509
+// certificate request with a CertificateSpec and returns a list of field
+// names on the Certificate that do not match.
+func requestSubjectMatchesSpec(x509req *x509.CertificateRequest, spec cmapi.CertificateSpec) []string {
 	// It is safe to mutate top-level fields in `spec` as it is not a pointer
 	// meaning changes will not effect the caller.
 	if spec.Subject == nil {
@@ -125,6 +214,9 @@ func RequestMatchesSpec(req *cmapi.CertificateRequest, spec cmapi.CertificateSpe
 	if !util.EqualUnsorted(x509req.EmailAddresses, spec.EmailAddresses) {
 		violations = append(violations, "spec.emailAddresses")
 	}
+	if otherNames, err := pki.OtherNamesFromExtensions(x509req.Extensions); err != nil || !otherNamesEqual(otherNames, spec.OtherNames) {
+		violations = append(violations, "spec.otherNames")
+	}
 	if x509req.Subject.SerialNumber != spec.Subject.SerialNumber {
 		violations = append(violations, "spec.subject.serialNumber")
 	}
@@ -149,21 +241,165 @@ func RequestMatchesSpec(req *cmapi.CertificateRequest, spec cmapi.CertificateSpe
 	if !util.EqualUnsorted(x509req.Subject.StreetAddress, spec.Subject.StreetAddresses) {
 		violations = append(violations, "spec.subject.streetAddresses")
 	}
-	if req.Spec.IsCA != spec.IsCA {
-		violations = append(violations, "spec.isCA")
+
+	return violations
+}
+
+// IssuerRefs returns the ordered list of issuers that may be used to issue a
+// Certificate with the given spec: spec.issuerRef followed by any fallback
+// issuers in spec.issuerRefs.
+func IssuerRefs(spec cmapi.CertificateSpec) []cmmeta.ObjectReference {
+	return append([]cmmeta.ObjectReference{spec.IssuerRef}, spec.IssuerRefs...)
+}
+
+// NextIssuerRef returns the issuer that should be attempted after the given
+// issuer has failed to issue a Certificate with the given spec. If there are
+// no more issuers to attempt, false is returned.
+func NextIssuerRef(spec cmapi.CertificateSpec, failed cmmeta.ObjectReference) (cmmeta.ObjectReference, bool) {
+	refs := IssuerRefs(spec)
+	i := issuerRefIndex(spec, failed)
+	if i < 0 || i+1 >= len(refs) {
+		return cmmeta.ObjectReference{}, false
+	}
+	return refs[i+1], true
+}
+
+// StagingIssuerRef returns the staging issuer named by the
+// StagingIssuerNameAnnotationKey annotation of the given Certificate or, if
+// the Certificate does not have the annotation, of the issuer it references.
+// The staging issuer has the same kind and group as spec.issuerRef. False is
+// returned if there is no staging issuer, or if it is one of the issuers of
+// the Certificate.
+func StagingIssuerRef(helper issuer.Helper, crt *cmapi.Certificate) (cmmeta.ObjectReference, bool) {
+	name := crt.Annotations[cmapi.StagingIssuerNameAnnotationKey]
+	if name == "" && helper != nil {
+		if issuerObj, ok := CertManagerIssuer(helper, crt.Spec.IssuerRef, crt.Namespace); ok {
+			name = issuerObj.GetObjectMeta().Annotations[cmapi.StagingIssuerNameAnnotationKey]
+		}
 	}
-	if !util.EqualKeyUsagesUnsorted(req.Spec.Usages, spec.Usages) {
-		violations = append(violations, "spec.usages")
+	if name == "" {
+		return cmmeta.ObjectReference{}, false
 	}
-	if spec.Duration != nil && req.Spec.Duration != nil &&
-		spec.Duration.Duration != req.Spec.Duration.Duration {
-		violations = append(violations, "spec.duration")
+
+	ref := crt.Spec.IssuerRef
+	ref.Name = name
+	if issuerRefIndex(crt.Spec, ref) >= 0 {
+		return cmmeta.ObjectReference{}, false
 	}
-	if !reflect.DeepEqual(spec.IssuerRef, req.Spec.IssuerRef) {
-		violations = append(violations, "spec.issuerRef")
+	return ref, true
+}
+
+// IsStagingRequest returns true if the given CertificateRequest was created
+// for the staging issuer of a Certificate.
+func IsStagingRequest(req *cmapi.CertificateRequest) bool {
+	return req.Annotations[cmapi.CertificateRequestStagingAnnotationKey] == "true"
+}
+
+// issuerRefIndex returns the position of the given issuer in the list of
+// issuers for the given spec, or -1 if it is not present.
+func issuerRefIndex(spec cmapi.CertificateSpec, ref cmmeta.ObjectReference) int {
+	for i, candidate := range IssuerRefs(spec) {
+		if reflect.DeepEqual(candidate, ref) {
+			return i
+		}
 	}
+	return -1
+}
 
-	return violations, nil
+// RequestPredatesGeneration returns true if the given CertificateRequest was
+// created for an older generation of the given Certificate, meaning the
+// Certificate spec has been changed since the request was created.
+// Requests that do not record the generation they were created for are
+// never considered to predate the Certificate.
+func RequestPredatesGeneration(req *cmapi.CertificateRequest, crt *cmapi.Certificate) bool {
+	genStr, ok := req.Annotations[cmapi.CertificateRequestCertificateGenerationAnnotationKey]
+	if !ok {
+		return false
+	}
+	gen, err := strconv.ParseInt(genStr, 10, 64)
+	if err != nil {
+		return false
+	}
+	return gen < crt.Generation
+}
+
+// CertManagerIssuer returns the cert-manager issuer referenced by issuerRef,
+// reading Issuers from the given namespace. False is returned if issuerRef
+// references an external issuer, or if the issuer cannot be read.
+func CertManagerIssuer(helper issuer.Helper, issuerRef cmmeta.ObjectReference, namespace string) (cmapi.GenericIssuer, bool) {
+	if issuerRef.Group != "" && issuerRef.Group != certmanager.GroupName {
+		return nil, false
+	}
+	issuerObj, err := helper.GetGenericIssuer(issuerRef, namespace)
+	if err != nil {
+		return nil, false
+	}
+	return issuerObj, true
+}
+
+// RequestPredatesIssuerGeneration returns true if the given CertificateRequest
+// was created for a different generation or UID of the cert-manager issuer
+// that it references, meaning the issuer has been changed or recreated since
+// the request was created. Requests that do not record the issuer generation
+// they were created for, or whose issuer cannot be read using the given
+// helper, are never considered to predate the issuer.
+func RequestPredatesIssuerGeneration(helper issuer.Helper, req *cmapi.CertificateRequest) bool {
+	genStr, ok := req.Annotations[cmapi.CertificateRequestIssuerGenerationAnnotationKey]
+	if !ok || helper == nil {
+		return false
+	}
+	gen, err := strconv.ParseInt(genStr, 10, 64)
+	if err != nil {
+		return false
+	}
+	issuerObj, ok := CertManagerIssuer(helper, req.Spec.IssuerRef, req.Namespace)
+	if !ok {
+		return false
+	}
+	if uid, ok := req.Annotations[cmapi.CertificateRequestIssuerUIDAnnotationKey]; ok && uid != string(issuerObj.GetObjectMeta().UID) {
+		return true
+	}
+	return gen != issuerObj.GetObjectMeta().Generation
+}
+
+// RequestInFlight returns true if the given CertificateRequest has not been
+// denied and has not yet been issued or failed.
+func RequestInFlight(req *cmapi.CertificateRequest) bool {
+	if apiutil.CertificateRequestIsDenied(req) {
+		return false
+	}
+	cond := apiutil.GetCertificateRequestCondition(req, cmapi.CertificateRequestConditionReady)
+	if cond == nil {
+		return true
+	}
+	return cond.Status != cmmeta.ConditionTrue && cond.Reason != cmapi.CertificateRequestReasonFailed
+}
+
+// timesEqual returns true if both times are unset, or both are set to the
+// same instant.
+func timesEqual(a, b *metav1.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Time.Equal(b.Time)
+}
+
+func otherNamesEqual(a, b []cmapi.OtherName) bool {
+	toStrings := func(names []cmapi.OtherName) []string {
+		s := make([]string, len(names))
+		for i, name := range names {
+			s[i] = name.OID + "=" + name.UTF8Value
+		}
+		return s
+	}
+	return util.EqualUnsorted(toStrings(a), toStrings(b))
+}
+
+func int32PtrsEqual(a, b *int32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 // SecretDataAltNamesMatchSpec will compare a Secret resource containing certificate
@@ -177,6 +413,12 @@ func SecretDataAltNamesMatchSpec(secret *corev1.Secret, spec cmapi.CertificateSp
 		return nil, err
 	}
 
+	// The alt names of a certificate issued from a user supplied CSR are
+	// not defined on the Certificate spec.
+	if spec.CSRSecretRef != nil {
+		return nil, nil
+	}
+
 	var violations []string
 
 	// Perform a 'loose' check on the x509 certificate to determine if the
@@ -289,3 +531,71 @@ func RenewalTimeWrapper(defaultRenewBeforeExpiryDuration time.Duration) RenewalT
 	}
 
 }
+
+const (
+	// IssuanceLatencySafetyFactor is the multiple of a Certificate's observed
+	// issuance latency before expiry at which renewal will be started at the
+	// latest.
+	IssuanceLatencySafetyFactor = 3
+
+	// issuanceLatencyWeight is the weight given to the most recent sample
+	// when updating the running average of a Certificate's issuance latency.
+	issuanceLatencyWeight = 0.3
+)
+
+// RenewalTimeWithIssuanceLatency brings the given renewal time forward, if
+// required, so that renewal starts at least IssuanceLatencySafetyFactor times
+// the observed issuance latency before notAfter. This guarantees overlap
+// between the current and next certificate for short-lived certificates issued
+// by slow issuers. The renewal time will never be brought forward to before
+// notBefore.
+func RenewalTimeWithIssuanceLatency(renewalTime *metav1.Time, notBefore, notAfter time.Time, issuanceLatency *metav1.Duration) *metav1.Time {
+	if renewalTime == nil || issuanceLatency == nil || issuanceLatency.Duration <= 0 {
+		return renewalTime
+	}
+
+	latest := notAfter.Add(-1 * IssuanceLatencySafetyFactor * issuanceLatency.Duration)
+	if latest.Before(notBefore) {
+		latest = notBefore
+	}
+	if !latest.Before(renewalTime.Time) {
+		return renewalTime
+	}
+
+	rt := metav1.NewTime(latest)
+	return &rt
+}
+
+// UpdateIssuanceLatency returns the running average of issuance latency after
+// the given sample has been observed. If no previous average is known, the
+// sample is returned as-is.
+func UpdateIssuanceLatency(average *metav1.Duration, sample time.Duration) *metav1.Duration {
+	if average == nil {
+		return &metav1.Duration{Duration: sample}
+	}
+
+	updated := time.Duration(float64(average.Duration)*(1-issuanceLatencyWeight) + float64(sample)*issuanceLatencyWeight)
+	return &metav1.Duration{Duration: updated}
+}
+
+// MaxReconciliationRecords is the maximum number of entries retained in a
+// Certificate's status.lastReconciledBy.
+const MaxReconciliationRecords = 10
+
+// RecordReconciliation records on the given Certificate's status that the
+// named controller acted on it at the given time. Only the most recent
+// MaxReconciliationRecords entries are retained.
+// This should only be called when the controller is about to update the
+// Certificate's status anyway, to avoid triggering further reconciles.
+func RecordReconciliation(crt *cmapi.Certificate, controller string, now time.Time) {
+	records := make([]cmapi.CertificateReconciliationRecord, 0, len(crt.Status.LastReconciledBy)+1)
+	records = append(records, crt.Status.LastReconciledBy...)
+	records = append(records, cmapi.CertificateReconciliationRecord{
+		Controller: controller,
+		Time:       metav1.NewTime(now),
+	})
+	if len(records) > MaxReconciliationRecords {
+		records = records[len(records)-MaxReconciliationRecords:]
+	}
+	crt.Status.LastReconciledBy = records
+}
@@ -18,6 +18,7 @@ package certificates
 
 import (
 	"crypto"
+	"encoding/pem"
 	"fmt"
 	"reflect"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
@@ -77,6 +79,28 @@ func TestPrivateKeyMatchesSpec(t *testing.T) {
 			expectedSize: pki.ECCurve521,
 			violations:   []string{"spec.keySize"},
 		},
+		"should match if keySize and algorithm are correct (ECDSA P-384)": {
+			key:          mustGenerateECDSA(t, pki.ECCurve384),
+			expectedAlgo: cmapi.ECDSAKeyAlgorithm,
+			expectedSize: pki.ECCurve384,
+		},
+		"should match if keySize and algorithm are correct (ECDSA P-521)": {
+			key:          mustGenerateECDSA(t, pki.ECCurve521),
+			expectedAlgo: cmapi.ECDSAKeyAlgorithm,
+			expectedSize: pki.ECCurve521,
+		},
+		"should not match if ECDSA key uses a different curve": {
+			key:          mustGenerateECDSA(t, pki.ECCurve384),
+			expectedAlgo: cmapi.ECDSAKeyAlgorithm,
+			expectedSize: pki.ECCurve521,
+			violations:   []string{"spec.keySize"},
+		},
+		"should not match if ECDSA keySize is unsupported": {
+			key:          mustGenerateECDSA(t, pki.ECCurve256),
+			expectedAlgo: cmapi.ECDSAKeyAlgorithm,
+			expectedSize: 100,
+			violations:   []string{"spec.keySize"},
+		},
 		"should not match if keyAlgorithm is incorrect": {
 			key:          mustGenerateECDSA(t, pki.ECCurve256),
 			expectedAlgo: cmapi.RSAKeyAlgorithm,
@@ -362,3 +386,174 @@ func TestRenewalTimeWrapper(t *testing.T) {
 		})
 	}
 }
+
+func TestRenewalTimeWithIssuanceLatency(t *testing.T) {
+	now := time.Now()
+	notBefore := now
+	notAfter := now.Add(time.Hour)
+	renewalTime := &metav1.Time{Time: now.Add(time.Minute * 40)}
+	tests := map[string]struct {
+		renewalTime         *metav1.Time
+		issuanceLatency     *metav1.Duration
+		expectedRenewalTime *metav1.Time
+	}{
+		"no observed issuance latency should not change renewal time": {
+			renewalTime:         renewalTime,
+			expectedRenewalTime: renewalTime,
+		},
+		"no renewal time should not be changed": {
+			issuanceLatency: &metav1.Duration{Duration: time.Minute},
+		},
+		"low issuance latency should not change renewal time": {
+			renewalTime:         renewalTime,
+			issuanceLatency:     &metav1.Duration{Duration: time.Minute},
+			expectedRenewalTime: renewalTime,
+		},
+		"high issuance latency should bring renewal time forward": {
+			renewalTime:         renewalTime,
+			issuanceLatency:     &metav1.Duration{Duration: time.Minute * 10},
+			expectedRenewalTime: &metav1.Time{Time: now.Add(time.Minute * 30)},
+		},
+		"very high issuance latency should not bring renewal time before notBefore": {
+			renewalTime:         renewalTime,
+			issuanceLatency:     &metav1.Duration{Duration: time.Hour},
+			expectedRenewalTime: &metav1.Time{Time: notBefore},
+		},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			got := RenewalTimeWithIssuanceLatency(test.renewalTime, notBefore, notAfter, test.issuanceLatency)
+			assert.Equal(t, test.expectedRenewalTime, got)
+		})
+	}
+}
+
+func TestUpdateIssuanceLatency(t *testing.T) {
+	tests := map[string]struct {
+		average  *metav1.Duration
+		sample   time.Duration
+		expected *metav1.Duration
+	}{
+		"no previous average should return sample": {
+			sample:   time.Minute,
+			expected: &metav1.Duration{Duration: time.Minute},
+		},
+		"higher sample should increase average": {
+			average:  &metav1.Duration{Duration: time.Minute},
+			sample:   time.Minute * 11,
+			expected: &metav1.Duration{Duration: time.Minute * 4},
+		},
+		"lower sample should decrease average": {
+			average:  &metav1.Duration{Duration: time.Minute * 10},
+			sample:   0,
+			expected: &metav1.Duration{Duration: time.Minute * 7},
+		},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			assert.Equal(t, test.expected, UpdateIssuanceLatency(test.average, test.sample))
+		})
+	}
+}
+
+func TestRecordReconciliation(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := func(controller string, offset int) cmapi.CertificateReconciliationRecord {
+		return cmapi.CertificateReconciliationRecord{
+			Controller: controller,
+			Time:       metav1.NewTime(now.Add(time.Duration(offset) * time.Second)),
+		}
+	}
+
+	var full []cmapi.CertificateReconciliationRecord
+	for i := 0; i < MaxReconciliationRecords; i++ {
+		full = append(full, record("old", i))
+	}
+
+	tests := map[string]struct {
+		existing []cmapi.CertificateReconciliationRecord
+		expected []cmapi.CertificateReconciliationRecord
+	}{
+		"no existing records should add a single record": {
+			expected: []cmapi.CertificateReconciliationRecord{record("new", 0)},
+		},
+		"existing records should be appended to": {
+			existing: []cmapi.CertificateReconciliationRecord{record("old", -1)},
+			expected: []cmapi.CertificateReconciliationRecord{record("old", -1), record("new", 0)},
+		},
+		"the oldest record should be dropped once the limit is reached": {
+			existing: full,
+			expected: append(append([]cmapi.CertificateReconciliationRecord{}, full[1:]...), record("new", 0)),
+		},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			crt := &cmapi.Certificate{Status: cmapi.CertificateStatus{LastReconciledBy: test.existing}}
+			RecordReconciliation(crt, "new", now)
+			assert.Equal(t, test.expected, crt.Status.LastReconciledBy)
+		})
+	}
+}
+
+func TestRequestMatchesCertificate(t *testing.T) {
+	crt := &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{cmapi.StagingIssuerNameAnnotationKey: "staging"},
+		},
+		Spec: cmapi.CertificateSpec{
+			CommonName: "example.com",
+			IssuerRef:  cmmeta.ObjectReference{Name: "production"},
+		},
+	}
+	csr, err := pki.GenerateCSR(crt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrDER, err := pki.EncodeCSR(csr, mustGenerateRSA(t, 2048).(crypto.Signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	request := func(issuerName string, staging bool) *cmapi.CertificateRequest {
+		req := &cmapi.CertificateRequest{
+			Spec: cmapi.CertificateRequestSpec{
+				IssuerRef: cmmeta.ObjectReference{Name: issuerName},
+				Request:   csrPEM,
+			},
+		}
+		if staging {
+			req.Annotations = map[string]string{cmapi.CertificateRequestStagingAnnotationKey: "true"}
+		}
+		return req
+	}
+
+	tests := map[string]struct {
+		req                *cmapi.CertificateRequest
+		expectedViolations []string
+	}{
+		"a request for the issuer of the Certificate should match": {
+			req: request("production", false),
+		},
+		"a staging request for the staging issuer of the Certificate should match": {
+			req: request("staging", true),
+		},
+		"a request for the staging issuer without the staging annotation should not match": {
+			req:                request("staging", false),
+			expectedViolations: []string{"spec.issuerRef"},
+		},
+		"a staging request for some other issuer should not match": {
+			req:                request("other", true),
+			expectedViolations: []string{"spec.issuerRef"},
+		},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			violations, err := RequestMatchesCertificate(nil, test.req, crt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expectedViolations, violations)
+		})
+	}
+}
@@ -117,6 +117,10 @@ func (c *CA) Sign(ctx context.Context, csr *certificatesv1.CertificateSigningReq
 		return err
 	}
 
+	// The signing Secret may contain cross-signed intermediates, in which
+	// case select the chain that terminates at the preferred root.
+	caCerts = pki.BuildCertificateChain(caCerts[0], caCerts[1:], issuerObj.GetSpec().CA.PreferredChain)
+
 	template, err := c.templateGenerator(csr)
 	if err != nil {
 		message := fmt.Sprintf("Error generating certificate template: %s", err)
@@ -129,6 +133,29 @@ func (c *CA) Sign(ctx context.Context, csr *certificatesv1.CertificateSigningReq
 	template.CRLDistributionPoints = issuerObj.GetSpec().CA.CRLDistributionPoints
 	template.OCSPServer = issuerObj.GetSpec().CA.OCSPServers
 
+	if method := issuerObj.GetSpec().CA.SubjectKeyIdentifierMethod; method != "" {
+		template.SubjectKeyId, err = pki.SubjectKeyIdentifier(template.PublicKey, method)
+		if err != nil {
+			message := fmt.Sprintf("Error deriving subject key identifier: %s", err)
+			c.recorder.Event(csr, corev1.EventTypeWarning, "SigningError", message)
+			util.CertificateSigningRequestSetFailed(csr, "SigningError", message)
+			_, err = c.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
+			return err
+		}
+	}
+
+	if policies := issuerObj.GetSpec().CA.PolicyIdentifiers; len(policies) > 0 {
+		extension, err := pki.CertificatePoliciesExtension(policies)
+		if err != nil {
+			message := fmt.Sprintf("Error building certificate policies extension: %s", err)
+			c.recorder.Event(csr, corev1.EventTypeWarning, "SigningError", message)
+			util.CertificateSigningRequestSetFailed(csr, "SigningError", message)
+			_, err = c.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
+			return err
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, extension)
+	}
+
 	bundle, err := c.signingFn(caCerts, caKey, template)
 	if err != nil {
 		message := fmt.Sprintf("Error signing certificate: %s", err)
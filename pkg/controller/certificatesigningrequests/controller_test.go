@@ -18,7 +18,9 @@ package certificatesigningrequests
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
+	"net/url"
 	"testing"
 	"time"
 
@@ -70,6 +72,56 @@ func TestController(t *testing.T) {
 		}
 	}
 
+	outOfPolicyRequest, _, err := gen.CSR(x509.RSA, gen.SetCSRDNSNames("foo.example.com", "example.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outOfTrustDomainRequest, _, err := gen.CSR(x509.RSA, gen.SetCSRURIs(&url.URL{Scheme: "spiffe", Host: "evil.org", Path: "/ns/hello/sa/app"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	approvedCSR := func(request []byte) *certificatesv1.CertificateSigningRequest {
+		return gen.CertificateSigningRequest("csr-1",
+			gen.SetCertificateSigningRequestSignerName("clusterissuers.cert-manager.io/world"),
+			gen.SetCertificateSigningRequestRequest(request),
+			gen.SetCertificateSigningRequestStatusCondition(certificatesv1.CertificateSigningRequestCondition{
+				Type:    certificatesv1.CertificateApproved,
+				Status:  corev1.ConditionTrue,
+				Reason:  "ApprovedReason",
+				Message: "Approved message",
+			}),
+		)
+	}
+	readyClusterIssuer := func(mods ...gen.IssuerModifier) *cmapi.ClusterIssuer {
+		return gen.ClusterIssuer("world", append([]gen.IssuerModifier{
+			gen.SetIssuerCA(cmapi.CAIssuer{SecretName: "tls"}),
+			gen.AddIssuerCondition(cmapi.IssuerCondition{
+				Type:   cmapi.IssuerConditionReady,
+				Status: cmmeta.ConditionTrue,
+			}),
+		}, mods...)...)
+	}
+	deniedConditions := func(reason, message string) []certificatesv1.CertificateSigningRequestCondition {
+		return []certificatesv1.CertificateSigningRequestCondition{
+			{
+				Type:    certificatesv1.CertificateApproved,
+				Status:  corev1.ConditionTrue,
+				Reason:  "ApprovedReason",
+				Message: "Approved message",
+			},
+			{
+				Type:               certificatesv1.CertificateFailed,
+				Status:             corev1.ConditionTrue,
+				Reason:             reason,
+				Message:            message,
+				LastTransitionTime: metaFixedClockStart,
+				LastUpdateTime:     metaFixedClockStart,
+			},
+		}
+	}
+	domainDeniedMessage := `Certificate signing request is not permitted by the referenced issuer: the DNS names [example.org] are not permitted by the allowedDomains [*.example.com] of ClusterIssuer "world"`
+	trustDomainDeniedMessage := `Certificate signing request is not permitted by the referenced issuer: the SPIFFE IDs [spiffe://evil.org/ns/hello/sa/app] do not belong to the trust domain "example.org" of ClusterIssuer "world"`
+
 	tests := map[string]struct {
 		// key that should be passed to ProcessItem. If not set, the
 		// 'namespace/name' of the 'CertificateSigningRequest' field will be used.
@@ -437,6 +489,24 @@ func TestController(t *testing.T) {
 				}),
 			),
 		},
+		"if CertificateSigningRequest requests a DNS name not permitted by the issuer's allowedDomains, should update Failed": {
+			signerType:     apiutil.IssuerCA,
+			existingCSR:    approvedCSR(outOfPolicyRequest),
+			existingIssuer: readyClusterIssuer(gen.SetIssuerAllowedDomains("*.example.com")),
+			signerImpl:     signerExpectNoCall,
+			sarReaction:    sarReactionExpectNoCall,
+			wantEvent:      "Warning DomainNotAllowed " + domainDeniedMessage,
+			wantConditions: deniedConditions("DomainNotAllowed", domainDeniedMessage),
+		},
+		"if CertificateSigningRequest requests a SPIFFE ID outside of the issuer's trust domain, should update Failed": {
+			signerType:     apiutil.IssuerCA,
+			existingCSR:    approvedCSR(outOfTrustDomainRequest),
+			existingIssuer: readyClusterIssuer(gen.SetIssuerSPIFFETrustDomain("example.org")),
+			signerImpl:     signerExpectNoCall,
+			sarReaction:    sarReactionExpectNoCall,
+			wantEvent:      "Warning SPIFFETrustDomainNotAllowed " + trustDomainDeniedMessage,
+			wantConditions: deniedConditions("SPIFFETrustDomainNotAllowed", trustDomainDeniedMessage),
+		},
 		"if CertificateSigningRequest called invoked sign but it errors, should return error": {
 			signerType: apiutil.IssuerCA,
 			existingCSR: gen.CertificateSigningRequest("csr-1",
@@ -30,6 +30,7 @@ import (
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	crutil "github.com/jetstack/cert-manager/pkg/controller/certificaterequests/util"
 	"github.com/jetstack/cert-manager/pkg/controller/certificatesigningrequests/util"
 	logf "github.com/jetstack/cert-manager/pkg/logs"
 )
@@ -127,11 +128,33 @@ func (c *Controller) Sync(ctx context.Context, csr *certificatesv1.CertificateSi
 		return nil
 	}
 
+	// The issuer's allowedDomains and SPIFFE trust domain are enforced for
+	// every signer, so that they cannot be bypassed by requesting a
+	// certificate with a CertificateSigningRequest rather than a
+	// CertificateRequest.
+	if err := crutil.CheckRequestAllowedDomains(issuerObj, kind, csr.Spec.Request); err != nil {
+		return c.failNotPermitted(ctx, csr, crutil.ReasonDomainNotAllowed, err)
+	}
+	if err := crutil.CheckRequestSPIFFETrustDomain(issuerObj, kind, csr.Spec.Request); err != nil {
+		return c.failNotPermitted(ctx, csr, crutil.ReasonSPIFFETrustDomainNotAllowed, err)
+	}
+
 	dbg.Info("invoking sign function as existing certificate does not exist")
 
 	return c.signer.Sign(ctx, csr, issuerObj)
 }
 
+// failNotPermitted marks the CertificateSigningRequest as Failed with the
+// given reason, as it requests a certificate that is not permitted by the
+// referenced issuer.
+func (c *Controller) failNotPermitted(ctx context.Context, csr *certificatesv1.CertificateSigningRequest, reason string, err error) error {
+	message := fmt.Sprintf("Certificate signing request is not permitted by the referenced issuer: %s", err)
+	c.recorder.Event(csr, corev1.EventTypeWarning, reason, message)
+	util.CertificateSigningRequestSetFailed(csr, reason, message)
+	_, err = c.certClient.UpdateStatus(ctx, csr, metav1.UpdateOptions{})
+	return err
+}
+
 // userCanReferenceSigner will return true if the CSR requester has a bound
 // role that allows them to reference a given Namespaced signer. The user must
 // have the permissions:
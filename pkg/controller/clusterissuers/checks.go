@@ -48,6 +48,12 @@ func (c *controller) issuersForSecret(secret *corev1.Secret) ([]*v1.ClusterIssue
 					continue
 				}
 			}
+			if iss.Spec.ACME.PreviousPrivateKey != nil {
+				if iss.Spec.ACME.PreviousPrivateKey.Name == secret.Name {
+					affected = append(affected, iss)
+					continue
+				}
+			}
 		case iss.Spec.CA != nil:
 			if iss.Spec.CA.SecretName == secret.Name {
 				affected = append(affected, iss)
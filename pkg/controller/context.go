@@ -20,12 +20,15 @@ import (
 	"context"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/resource"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/clock"
+	gwclient "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+	gwinformers "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
 
 	"github.com/jetstack/cert-manager/pkg/acme/accounts"
 	clientset "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
@@ -50,6 +53,9 @@ type Context struct {
 	Client kubernetes.Interface
 	// CMClient is a cert-manager clientset
 	CMClient clientset.Interface
+	// GWClient is a Gateway API clientset. It is only set if Gateway API
+	// support is enabled.
+	GWClient gwclient.Interface
 	// Recorder to record events to
 	Recorder record.EventRecorder
 
@@ -59,6 +65,9 @@ type Context struct {
 	// SharedInformerFactory can be used to obtain shared SharedIndexInformer
 	// instances
 	SharedInformerFactory informers.SharedInformerFactory
+	// GWShared can be used to obtain shared SharedIndexInformer instances for
+	// Gateway API types. It is only set if Gateway API support is enabled.
+	GWShared gwinformers.SharedInformerFactory
 
 	// Namespace is the namespace to operate within.
 	// If unset, operates on all namespaces
@@ -71,11 +80,17 @@ type Context struct {
 	// Metrics is used for exposing Prometheus metrics across the controllers
 	Metrics *metrics.Metrics
 
+	// TracerProvider is used to emit OpenTelemetry traces of the certificate
+	// issuance pipeline. If unset, no traces are emitted.
+	TracerProvider trace.TracerProvider
+
 	IssuerOptions
 	ACMEOptions
 	IngressShimOptions
 	CertificateOptions
 	SchedulerOptions
+	CABundleOptions
+	ApproverOptions
 }
 
 type IssuerOptions struct {
@@ -91,6 +106,27 @@ type IssuerOptions struct {
 	// IssuerAmbientCredentials controls whether an issuer should pick up ambient
 	// credentials, such as those from metadata services, to construct clients.
 	IssuerAmbientCredentials bool
+
+	// NotAfterRounding, if greater than zero, is the boundary that the
+	// notAfter of certificates signed by the CA and SelfSigned issuers is
+	// rounded to, e.g. a minute, hour or day.
+	NotAfterRounding time.Duration
+
+	// NotAfterRoundUp controls whether notAfter is rounded up to the next
+	// NotAfterRounding boundary rather than down to the previous one.
+	NotAfterRoundUp bool
+
+	// CAExpiryPolicy is what the CA issuer does when a requested certificate
+	// would be valid for longer than the CA certificate that signs it, either
+	// "Allow", "Clamp" or "Reject". If empty, certificates are issued
+	// unchanged.
+	CAExpiryPolicy string
+
+	// TransientSigningErrorRetryPeriod is how long to wait before retrying a
+	// CertificateRequest whose signer returned a transient error, such as a
+	// network error. Requests that keep failing with transient errors, and
+	// all requests if this is zero, are retried with exponential back-off.
+	TransientSigningErrorRetryPeriod time.Duration
 }
 
 type ACMEOptions struct {
@@ -124,6 +160,28 @@ type ACMEOptions struct {
 
 	// DNS01CheckRetryPeriod is the time the controller should wait between checking if a ACME dns entry exists.
 	DNS01CheckRetryPeriod time.Duration
+
+	// DNS01BatchWindow is the time to wait for further DNS01 records in the
+	// same zone to be presented or cleaned up, so that they can be sent to
+	// DNS providers that support it in a single call. If zero, records are
+	// always sent to the provider individually.
+	DNS01BatchWindow time.Duration
+
+	// DNS01AzureFederatedTokenFile is the path of the projected service
+	// account token file used by azureDNS solvers that authenticate with
+	// Azure Workload Identity. If empty, the AZURE_FEDERATED_TOKEN_FILE
+	// environment variable is used.
+	DNS01AzureFederatedTokenFile string
+
+	// Finalizer is the name of the finalizer added to ACME Challenge resources
+	// to ensure they are cleaned up before being deleted. If empty, the
+	// default ACME finalizer is used.
+	Finalizer string
+
+	// LegacyFinalizers are finalizer names that were previously used instead
+	// of Finalizer. They are removed from Challenge resources in the same way
+	// as Finalizer once the Challenge has been cleaned up.
+	LegacyFinalizers []string
 }
 
 type IngressShimOptions struct {
@@ -138,6 +196,117 @@ type CertificateOptions struct {
 	// EnableOwnerRef controls whether the certificate is configured as an owner of
 	// secret where the effective TLS certificate is stored.
 	EnableOwnerRef bool
+
+	// ClockSkewTolerance is the maximum amount of time a certificate's
+	// notBefore may be in the future and still be considered Ready.
+	ClockSkewTolerance time.Duration
+
+	// MaxChainDepth is the maximum number of certificates in an issued
+	// certificate chain for the Certificate to be considered Ready. Chains are
+	// not checked if it is not positive.
+	MaxChainDepth int
+
+	// EnableSecretEvents controls whether issuance events are also recorded
+	// against the Secret where the effective TLS certificate is stored.
+	EnableSecretEvents bool
+
+	// RequestAnnotationPrefix, if set, is the prefix of CertificateRequest
+	// annotations that will be copied onto the Secret where the effective TLS
+	// certificate is stored when the certificate is issued.
+	RequestAnnotationPrefix string
+
+	// RequesterAnnotations are the annotations that are not copied from a
+	// Certificate onto its CertificateRequests, so that users cannot attribute
+	// the CertificateRequests created by the controller to another user.
+	RequesterAnnotations []string
+
+	// IssuanceFailureWebhookURL, if set, is the URL that a JSON notification
+	// is POSTed to whenever the issuance of a certificate fails.
+	IssuanceFailureWebhookURL string
+
+	// IssuanceAuditLogPath, if set, is the path of a file that a JSON audit
+	// record of every successful and failed issuance is appended to. "-"
+	// writes the records to stdout.
+	IssuanceAuditLogPath string
+
+	// ManifestSigningKeyPath, if set, is the path of a PEM encoded private
+	// key that the issuing controller uses to sign a JSON manifest of every
+	// issued certificate, which is stored in its Secret with the signature.
+	ManifestSigningKeyPath string
+
+	// CRLCheckInterval, if greater than zero, is how often the trigger
+	// controller checks whether issued certificates have been revoked using
+	// the CRLs at their CRL distribution points, and triggers a re-issuance
+	// of revoked certificates.
+	CRLCheckInterval time.Duration
+
+	// SpecChangeDebounce, if greater than zero, is how long the spec of a
+	// Certificate must be unchanged for before the trigger controller
+	// triggers its issuance, or the requestmanager controller replaces the
+	// CertificateRequest of an issuance in progress, so that a burst of
+	// edits to the spec results in a single CertificateRequest.
+	SpecChangeDebounce time.Duration
+
+	// SecretRefreshInterval, if greater than zero, is how often the
+	// issuing controller re-creates the data derived from the issued
+	// certificate in the Secret, such as keystores and additional output
+	// formats, without re-issuing the certificate. This picks up changes to
+	// keystore passwords.
+	SecretRefreshInterval time.Duration
+
+	// IssuanceRateLimit, if greater than zero, is the maximum number of
+	// CertificateRequests created per hour by the request manager controller
+	// for the Certificates in each namespace. Requests beyond this rate are
+	// deferred until the namespace is within its rate again.
+	IssuanceRateLimit float64
+
+	// IssuanceRateBurst is the number of CertificateRequests that may be
+	// created at once for the Certificates in a namespace before
+	// IssuanceRateLimit applies.
+	IssuanceRateBurst int
+
+	// RequestDeleteRateLimit, if greater than zero, is the maximum number of
+	// old CertificateRequests deleted per second by the revision manager
+	// controller across all Certificates. Deletes beyond this rate are
+	// deferred, and the Certificate is requeued.
+	RequestDeleteRateLimit float64
+
+	// RequestDeleteRateBurst is the number of CertificateRequests that may
+	// be deleted at once before RequestDeleteRateLimit applies.
+	RequestDeleteRateBurst int
+
+	// RequestNaming is the scheme used by the request manager controller to
+	// name the CertificateRequests it creates, either "random" or
+	// "revision". If empty, CertificateRequests are named with a random
+	// suffix.
+	RequestNaming string
+}
+
+type CABundleOptions struct {
+	// ClusterIssuerName is the name of the CA ClusterIssuer whose CA
+	// certificates are distributed. The ca-bundle controller does nothing if
+	// it is empty.
+	ClusterIssuerName string
+
+	// ConfigMapName is the name of the ConfigMaps the CA certificates are
+	// written to.
+	ConfigMapName string
+
+	// Namespaces is the list of namespaces the ConfigMap is maintained in. If
+	// it contains "*", the ConfigMap is maintained in all namespaces.
+	Namespaces []string
+}
+
+type ApproverOptions struct {
+	// TrustedServiceAccounts is the list of ServiceAccounts, in the form
+	// '<namespace>:<name>', whose CertificateRequests are automatically
+	// approved. If empty, all CertificateRequests are approved.
+	TrustedServiceAccounts []string
+
+	// UntrustedRequestTimeout is how long CertificateRequests created by
+	// requesters that are not trusted are left for manual approval before
+	// they are denied. If zero, they are never denied.
+	UntrustedRequestTimeout time.Duration
 }
 
 type SchedulerOptions struct {
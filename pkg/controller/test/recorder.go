@@ -28,14 +28,20 @@ import (
 // thrown away in this case.
 type FakeRecorder struct {
 	Events []string
+
+	// Objects contains the object that each entry in Events was recorded
+	// against.
+	Objects []runtime.Object
 }
 
 func (f *FakeRecorder) Event(object runtime.Object, eventtype, reason, message string) {
 	f.Events = append(f.Events, fmt.Sprintf("%s %s %s", eventtype, reason, message))
+	f.Objects = append(f.Objects, object)
 }
 
 func (f *FakeRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
 	f.Events = append(f.Events, fmt.Sprintf(eventtype+" "+reason+" "+messageFmt, args...))
+	f.Objects = append(f.Objects, object)
 }
 
 func (f *FakeRecorder) PastEventf(object runtime.Object, timestamp metav1.Time, eventtype, reason, messageFmt string, args ...interface{}) {
@@ -34,6 +34,12 @@ const (
 	// ExperimentalCertificateSigningRequestControllers enables all CertificateSigningRequest
 	// controllers that sign Kubernetes CertificateSigningRequest resources
 	ExperimentalCertificateSigningRequestControllers featuregate.Feature = "ExperimentalCertificateSigningRequestControllers"
+
+	// alpha: v1.5.0
+	//
+	// ExperimentalGatewayAPISupport enables the gatewayHTTPRoute HTTP01
+	// solver, which solves challenges using Gateway API HTTPRoute resources
+	ExperimentalGatewayAPISupport featuregate.Feature = "ExperimentalGatewayAPISupport"
 )
 
 func init() {
@@ -46,4 +52,5 @@ func init() {
 var defaultKubernetesFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	ValidateCAA: {Default: false, PreRelease: featuregate.Alpha},
 	ExperimentalCertificateSigningRequestControllers: {Default: false, PreRelease: featuregate.Alpha},
+	ExperimentalGatewayAPISupport:                    {Default: false, PreRelease: featuregate.Alpha},
 }
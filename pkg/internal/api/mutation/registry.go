@@ -27,6 +27,7 @@ package mutation
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -51,8 +52,8 @@ type Registry struct {
 	mutateUpdateRegister map[schema.GroupVersionKind]MutateUpdateFunc
 }
 
-type MutateFunc func(req *admissionv1.AdmissionRequest, obj runtime.Object)
-type MutateUpdateFunc func(req *admissionv1.AdmissionRequest, old, new runtime.Object)
+type MutateFunc func(ctx context.Context, req *admissionv1.AdmissionRequest, obj runtime.Object)
+type MutateUpdateFunc func(ctx context.Context, req *admissionv1.AdmissionRequest, old, new runtime.Object)
 
 // NewRegistry creates a new empty registry, backed by the provided Scheme.
 func NewRegistry(scheme *runtime.Scheme) *Registry {
@@ -110,7 +111,7 @@ func (r *Registry) AddMutateUpdateFunc(obj runtime.Object, fn MutateUpdateFunc)
 // A JSON patch is then generated for the target resource version.
 // Defaulting is always applied against the given resource, regardless of
 // whether any mutation functions are defined.
-func (r *Registry) Mutate(req *admissionv1.AdmissionRequest) ([]byte, error) {
+func (r *Registry) Mutate(ctx context.Context, req *admissionv1.AdmissionRequest) ([]byte, error) {
 	// Create GroupVersionKind where the Version is set to internal.
 	gvk := schema.GroupVersionKind{
 		Group: req.RequestKind.Group,
@@ -134,7 +135,7 @@ func (r *Registry) Mutate(req *admissionv1.AdmissionRequest) ([]byte, error) {
 			break
 		}
 
-		mutate(req, internal)
+		mutate(ctx, req, internal)
 
 	case admissionv1.Update:
 		// Attempt to retrieve the registered UPDATE mutating functions, and apply
@@ -152,7 +153,7 @@ func (r *Registry) Mutate(req *admissionv1.AdmissionRequest) ([]byte, error) {
 		}
 
 		// Pass both the old and new internal types to mutate
-		mutate(req, oldInternal, internal)
+		mutate(ctx, req, oldInternal, internal)
 
 	default:
 		// If not under a CREATE or UPDATE operation, exit early
@@ -235,9 +236,9 @@ func (r *Registry) appendMutate(gvk schema.GroupVersionKind, fn MutateFunc) {
 		return
 	}
 
-	r.mutateRegister[gvk] = func(aspec *admissionv1.AdmissionRequest, obj runtime.Object) {
-		existing(aspec, obj)
-		fn(aspec, obj)
+	r.mutateRegister[gvk] = func(ctx context.Context, aspec *admissionv1.AdmissionRequest, obj runtime.Object) {
+		existing(ctx, aspec, obj)
+		fn(ctx, aspec, obj)
 	}
 }
 
@@ -248,8 +249,8 @@ func (r *Registry) appendMutateUpdate(gvk schema.GroupVersionKind, fn MutateUpda
 		return
 	}
 
-	r.mutateUpdateRegister[gvk] = func(aspec *admissionv1.AdmissionRequest, oldObj, newObj runtime.Object) {
-		existing(aspec, oldObj, newObj)
-		fn(aspec, oldObj, newObj)
+	r.mutateUpdateRegister[gvk] = func(ctx context.Context, aspec *admissionv1.AdmissionRequest, oldObj, newObj runtime.Object) {
+		existing(ctx, aspec, oldObj, newObj)
+		fn(ctx, aspec, oldObj, newObj)
 	}
 }
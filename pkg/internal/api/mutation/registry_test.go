@@ -18,6 +18,7 @@ package mutation_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -159,7 +160,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.Certificate),
 					fn: func(t *testing.T) mutation.MutateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _ runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _ runtime.Object) {
 							t.Error("unexpected call")
 						}
 					},
@@ -182,7 +183,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.Certificate),
 					fn: func(t *testing.T) mutation.MutateUpdateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
 							t.Error("unexpected call")
 						}
 					},
@@ -203,7 +204,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.CertificateRequest),
 					fn: func(t *testing.T) mutation.MutateFunc {
-						return func(_ *admissionv1.AdmissionRequest, obj runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, obj runtime.Object) {
 							cr := obj.(*cminternal.CertificateRequest)
 							cr.Spec.Request = []byte("mutation called")
 						}
@@ -214,7 +215,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.Certificate),
 					fn: func(t *testing.T) mutation.MutateUpdateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
 							t.Error("unexpected call")
 						}
 					},
@@ -235,7 +236,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.Certificate),
 					fn: func(t *testing.T) mutation.MutateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _ runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _ runtime.Object) {
 							t.Error("unexpected call")
 						}
 					},
@@ -245,7 +246,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.CertificateRequest),
 					fn: func(t *testing.T) mutation.MutateUpdateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _, obj runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _, obj runtime.Object) {
 							cr := obj.(*cminternal.CertificateRequest)
 							cr.Spec.Request = []byte("mutation called")
 						}
@@ -270,7 +271,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.CertificateRequest),
 					fn: func(t *testing.T) mutation.MutateFunc {
-						return func(_ *admissionv1.AdmissionRequest, obj runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, obj runtime.Object) {
 							cr := obj.(*cminternal.CertificateRequest)
 							cr.Spec.Request = []byte("mutation called")
 						}
@@ -279,7 +280,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.CertificateRequest),
 					fn: func(t *testing.T) mutation.MutateFunc {
-						return func(_ *admissionv1.AdmissionRequest, obj runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, obj runtime.Object) {
 							cr := obj.(*cminternal.CertificateRequest)
 							if cr.Annotations == nil {
 								cr.Annotations = make(map[string]string)
@@ -293,7 +294,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.Certificate),
 					fn: func(t *testing.T) mutation.MutateUpdateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
 							t.Error("unexpected call")
 						}
 					},
@@ -314,7 +315,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.Certificate),
 					fn: func(t *testing.T) mutation.MutateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _ runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _ runtime.Object) {
 							t.Error("unexpected call")
 						}
 					},
@@ -324,7 +325,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.CertificateRequest),
 					fn: func(t *testing.T) mutation.MutateUpdateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _, obj runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _, obj runtime.Object) {
 							cr := obj.(*cminternal.CertificateRequest)
 							cr.Spec.Request = []byte("mutation called")
 						}
@@ -333,7 +334,7 @@ func TestMutate(t *testing.T) {
 				{
 					obj: new(cminternal.CertificateRequest),
 					fn: func(t *testing.T) mutation.MutateUpdateFunc {
-						return func(_ *admissionv1.AdmissionRequest, _, obj runtime.Object) {
+						return func(_ context.Context, _ *admissionv1.AdmissionRequest, _, obj runtime.Object) {
 							cr := obj.(*cminternal.CertificateRequest)
 							if cr.Annotations == nil {
 								cr.Annotations = make(map[string]string)
@@ -374,7 +375,7 @@ func TestMutate(t *testing.T) {
 				}
 			}
 
-			patch, err := reg.Mutate(test.req)
+			patch, err := reg.Mutate(context.TODO(), test.req)
 			if test.expErr != (err != nil) {
 				t.Errorf("unexpected error, exp=%t got=%v",
 					test.expErr, err)
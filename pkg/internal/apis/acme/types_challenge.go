@@ -128,4 +128,9 @@ type ChallengeStatus struct {
 	// State contains the current 'state' of the challenge.
 	// If not set, the state of the challenge is unknown.
 	State State
+
+	// FailedAttempts is the number of times presenting this challenge, or
+	// accepting it with the ACME server, has failed. It is only recorded if
+	// the issuer sets maxChallengeAttempts.
+	FailedAttempts int32
 }
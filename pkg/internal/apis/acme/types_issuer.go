@@ -18,7 +18,9 @@ package acme
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	cmmeta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
 )
@@ -57,6 +59,11 @@ type ACMEIssuer struct {
 	// Defaults to false.
 	SkipTLSVerify bool
 
+	// UserAgent, if set, is sent as the User-Agent header of every request
+	// made to the ACME server instead of the default cert-manager user agent,
+	// e.g. so that requests are allowed by a proxy that filters on it.
+	UserAgent string
+
 	// ExternalAccountBinding is a reference to a CA external account of the ACME
 	// server.
 	// If set, upon registration cert-manager will attempt to associate the given
@@ -70,6 +77,18 @@ type ACMEIssuer struct {
 	// If `key` is not specified, a default of `tls.key` will be used.
 	PrivateKey cmmeta.SecretKeySelector
 
+	// PreviousPrivateKey is a reference to a Secret containing the private key
+	// that the ACME account referenced by `status.acme.uri` is currently
+	// registered with.
+	// If set, and the key in `privateKeySecretRef` is not yet associated with
+	// an ACME account, the existing account is rolled over to the key in
+	// `privateKeySecretRef` using the ACME key change flow, retaining the
+	// account URL. This field may be removed once the rollover has completed.
+	// Optionally, a `key` may be specified to select a specific entry within
+	// the named Secret resource.
+	// If `key` is not specified, a default of `tls.key` will be used.
+	PreviousPrivateKey *cmmeta.SecretKeySelector
+
 	// Solvers is a list of challenge solvers that will be used to solve
 	// ACME challenges for the matching domains.
 	// Solver configurations must be provided in order to obtain certificates
@@ -91,6 +110,15 @@ type ACMEIssuer struct {
 	// it it will create an error on the Order.
 	// Defaults to false.
 	EnableDurationFeature bool
+
+	// MaxChallengeAttempts is the number of times presenting a challenge, or
+	// accepting it with the ACME server, may fail before the Challenge and
+	// the Order it belongs to are marked as failed. Failed propagation self
+	// checks are not counted, as they are expected whilst waiting for the
+	// challenge to propagate. The count is kept per Challenge, so it starts
+	// again for every new Order.
+	// If not set, failed attempts are retried indefinitely.
+	MaxChallengeAttempts *int32
 }
 
 // ACMEExternalAccountBinding is a reference to a CA external account of the ACME
@@ -186,6 +214,13 @@ type ACMEChallengeSolverHTTP01 struct {
 	// '/.well-known/acme-challenge/XYZ' to 'challenge solver' pods that are
 	// provisioned by cert-manager for each Challenge to be completed.
 	Ingress *ACMEChallengeSolverHTTP01Ingress
+
+	// The Gateway API HTTP01 challenge solver will solve challenges by
+	// creating HTTPRoute resources attached to an existing Gateway in order
+	// to route requests for '/.well-known/acme-challenge/XYZ' to 'challenge
+	// solver' pods that are provisioned by cert-manager for each Challenge
+	// to be completed. Requires the ExperimentalGatewayAPISupport feature gate.
+	GatewayHTTPRoute *ACMEChallengeSolverHTTP01GatewayHTTPRoute
 }
 
 type ACMEChallengeSolverHTTP01Ingress struct {
@@ -194,9 +229,22 @@ type ACMEChallengeSolverHTTP01Ingress struct {
 
 	// The ingress class to use when creating Ingress resources to solve ACME
 	// challenges that use this challenge solver.
-	// Only one of 'class' or 'name' may be specified.
+	// Only one of 'class', 'ingressClassName' or 'name' may be specified.
 	Class *string
 
+	// The name of the IngressClass to set in the spec.ingressClassName field
+	// of Ingress resources created to solve ACME challenges that use this
+	// challenge solver. Unlike 'class', which sets the deprecated
+	// kubernetes.io/ingress.class annotation, this field is understood by
+	// ingress controllers that only honour the IngressClass API.
+	// Only one of 'class', 'ingressClassName' or 'name' may be specified.
+	IngressClassName *string
+
+	// The path type to set on the HTTP01 challenge path added to solver
+	// Ingress resources. Defaults to the ingress controller's behaviour for
+	// paths with no type set if not specified.
+	PathType *networkingv1.PathType
+
 	// The name of the ingress resource that should have ACME challenge solving
 	// routes inserted into it in order to solve HTTP01 challenges.
 	// This is typically used in conjunction with ingress controllers like
@@ -213,6 +261,33 @@ type ACMEChallengeSolverHTTP01Ingress struct {
 	IngressTemplate *ACMEChallengeSolverHTTP01IngressTemplate
 }
 
+// The ACMEChallengeSolverHTTP01GatewayHTTPRoute solver will create HTTPRoute
+// resources attached to a Gateway that route requests to an ACME challenge
+// solver pod.
+type ACMEChallengeSolverHTTP01GatewayHTTPRoute struct {
+	// Optional service type for Kubernetes solver service
+	ServiceType corev1.ServiceType
+
+	// Labels that should be added to the HTTPRoute created to solve the
+	// challenge. These should match the route selector of a listener on
+	// the referenced Gateway.
+	Labels map[string]string
+
+	// The Gateway that the HTTPRoute created to solve the challenge should
+	// be attached to.
+	GatewayRef ACMEChallengeSolverHTTP01GatewayReference
+}
+
+// ACMEChallengeSolverHTTP01GatewayReference identifies a Gateway resource.
+type ACMEChallengeSolverHTTP01GatewayReference struct {
+	// Name of the Gateway.
+	Name string
+
+	// Namespace of the Gateway. If not specified, the namespace of the
+	// Challenge is used.
+	Namespace string
+}
+
 type ACMEChallengeSolverHTTP01IngressPodTemplate struct {
 	// ObjectMeta overrides for the pod used to solve HTTP01 challenges.
 	// Only the 'labels' and 'annotations' fields may be set.
@@ -254,6 +329,11 @@ type ACMEChallengeSolverHTTP01IngressPodSpec struct {
 	// If specified, the pod's service account
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// If specified, the resource requirements of the solver container.
+	// These override the default requests and limits configured on the
+	// cert-manager controller.
+	Resources *corev1.ResourceRequirements
 }
 
 type ACMEChallengeSolverHTTP01IngressTemplate struct {
@@ -309,6 +389,15 @@ type ACMEChallengeSolverDNS01 struct {
 	// Configure an external webhook based DNS01 challenge solver to manage
 	// DNS01 challenge records.
 	Webhook *ACMEIssuerDNS01ProviderWebhook
+
+	// AdditionalProviders is a list of further DNS01 providers that the
+	// challenge record is also presented to and cleaned up from, for zones
+	// that are served by more than one independent DNS provider. Each entry
+	// must configure a single provider, and may not itself have additional
+	// providers. When set, the propagation self-check always queries every
+	// authoritative nameserver for the zone, so that it only passes once the
+	// record is served by all of the providers.
+	AdditionalProviders []ACMEChallengeSolverDNS01
 }
 
 // CNAMEStrategy configures how the DNS01 provider should handle CNAME records
@@ -344,6 +433,12 @@ type ACMEIssuerDNS01ProviderCloudDNS struct {
 	ServiceAccount *cmmeta.SecretKeySelector
 	Project        string
 	HostedZoneName string
+
+	// ServiceAccountFile is the path of a file mounted into the cert-manager
+	// controller that contains the service account key, for use instead of
+	// serviceAccountSecretRef when credentials are not stored in a Secret.
+	// Files are only read for issuers that may use ambient credentials.
+	ServiceAccountFile string
 }
 
 // ACMEIssuerDNS01ProviderCloudflare is a structure containing the DNS
@@ -379,10 +474,22 @@ type ACMEIssuerDNS01ProviderRoute53 struct {
 	// https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/configuring-sdk.html#specifying-credentials
 	SecretAccessKey cmmeta.SecretKeySelector
 
+	// SecretAccessKeyFile is the path of a file mounted into the cert-manager
+	// controller that contains the SecretAccessKey, for use instead of
+	// secretAccessKeySecretRef when credentials are not stored in a Secret.
+	// Files are only read for issuers that may use ambient credentials.
+	SecretAccessKeyFile string
+
 	// Role is a Role ARN which the Route53 provider will assume using either the explicit credentials AccessKeyID/SecretAccessKey
 	// or the inferred credentials from environment variables, shared credentials file or AWS Instance metadata
 	Role string
 
+	// ExternalID is the external ID passed to STS when assuming Role, as
+	// required by roles whose trust policy is conditional on one. Different
+	// roles may be used for different Certificates by configuring a solver
+	// per role and selecting between them with the solver's selector.
+	ExternalID string
+
 	// If set, the provider will manage only this zone in Route53 and will not do an lookup using the route53:ListHostedZonesByName api call.
 	HostedZoneID string
 
@@ -399,6 +506,12 @@ type ACMEIssuerDNS01ProviderAzureDNS struct {
 	// if both this and ClientID are left unset MSI will be used
 	ClientSecret *cmmeta.SecretKeySelector
 
+	// ClientSecretFile is the path of a file mounted into the cert-manager
+	// controller that contains the client secret, for use instead of
+	// clientSecretSecretRef when credentials are not stored in a Secret.
+	// Files are only read for issuers that may use ambient credentials.
+	ClientSecretFile string
+
 	SubscriptionID string
 
 	// when specifying ClientID and ClientSecret then this field is also needed
@@ -409,8 +522,23 @@ type ACMEIssuerDNS01ProviderAzureDNS struct {
 	HostedZoneName string
 
 	Environment AzureDNSEnvironment
+
+	// WorkloadIdentity, if set, authenticates with Azure using Azure
+	// Workload Identity: the federated token in cert-manager's projected
+	// service account token file is exchanged for an access token, instead of
+	// using a client secret or managed identity. The token file is configured
+	// on the controller, not on the issuer. ClientID and TenantID default to
+	// the AZURE_CLIENT_ID and AZURE_TENANT_ID environment variables if not set.
+	WorkloadIdentity *AzureWorkloadIdentity
 }
 
+// AzureWorkloadIdentity configures authentication with Azure using a
+// federated service account token. The path of the token file is set with the
+// controller's --dns01-azure-federated-token-file flag, and defaults to the
+// AZURE_FEDERATED_TOKEN_FILE environment variable set by the Azure Workload
+// Identity webhook.
+type AzureWorkloadIdentity struct{}
+
 type AzureDNSEnvironment string
 
 const (
@@ -475,6 +603,34 @@ type ACMEIssuerDNS01ProviderWebhook struct {
 	// For details on the schema of this field, consult the webhook provider
 	// implementation's documentation.
 	Config *apiext.JSON
+
+	// TLS configures requests to be sent directly to the webhook solver over
+	// a mutual TLS connection, bypassing the Kubernetes apiserver.
+	// If not specified, requests are sent through the Kubernetes apiserver
+	// aggregation layer using the credentials of the cert-manager controller.
+	TLS *ACMEIssuerDNS01ProviderWebhookTLS
+}
+
+// ACMEIssuerDNS01ProviderWebhookTLS configures a direct mutual TLS connection
+// to a webhook DNS01 provider.
+type ACMEIssuerDNS01ProviderWebhookTLS struct {
+	// URL is the https base URL of the webhook solver's apiserver, for
+	// example `https://my-solver.my-namespace.svc:443`.
+	// When TLS is configured, requests are sent directly to this URL instead
+	// of through the Kubernetes apiserver aggregation layer, so that the
+	// client certificate is presented to the webhook solver itself.
+	URL string
+
+	// ClientCertSecretRef is a reference to a Secret in the issuer's resource
+	// namespace containing the client certificate and private key that will
+	// be presented to the webhook solver, stored under the `tls.crt` and
+	// `tls.key` keys.
+	ClientCertSecretRef cmmeta.LocalObjectReference
+
+	// CABundle is a PEM encoded bundle of CA certificates used to verify the
+	// serving certificate of the webhook solver.
+	// If not specified, the system trust roots are used.
+	CABundle []byte
 }
 
 type ACMEIssuerStatus struct {
@@ -486,4 +642,13 @@ type ACMEIssuerStatus struct {
 	// ACME account, in order to track changes made to registered account
 	// associated with the  Issuer
 	LastRegisteredEmail string
+
+	// LastRateLimitError is the detail of the most recent rate limit error
+	// returned by the ACME server when creating an order for this issuer.
+	LastRateLimitError string
+
+	// RateLimitedUntil is the time until which no new orders will be created
+	// for this issuer, as the ACME server has rate limited it. It is set from
+	// the Retry-After header of the rate limit error, if present.
+	RateLimitedUntil *metav1.Time
 }
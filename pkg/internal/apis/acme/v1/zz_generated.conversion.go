@@ -29,6 +29,7 @@ import (
 	meta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
 	metav1 "github.com/jetstack/cert-manager/pkg/internal/apis/meta/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	pkgapismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
@@ -92,6 +93,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute)(nil), (*acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute(a.(*v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute), b.(*acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute)(nil), (*v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute(a.(*acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute), b.(*v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1.ACMEChallengeSolverHTTP01GatewayReference)(nil), (*acme.ACMEChallengeSolverHTTP01GatewayReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_ACMEChallengeSolverHTTP01GatewayReference_To_acme_ACMEChallengeSolverHTTP01GatewayReference(a.(*v1.ACMEChallengeSolverHTTP01GatewayReference), b.(*acme.ACMEChallengeSolverHTTP01GatewayReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*acme.ACMEChallengeSolverHTTP01GatewayReference)(nil), (*v1.ACMEChallengeSolverHTTP01GatewayReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_acme_ACMEChallengeSolverHTTP01GatewayReference_To_v1_ACMEChallengeSolverHTTP01GatewayReference(a.(*acme.ACMEChallengeSolverHTTP01GatewayReference), b.(*v1.ACMEChallengeSolverHTTP01GatewayReference), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1.ACMEChallengeSolverHTTP01Ingress)(nil), (*acme.ACMEChallengeSolverHTTP01Ingress)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_ACMEChallengeSolverHTTP01Ingress_To_acme_ACMEChallengeSolverHTTP01Ingress(a.(*v1.ACMEChallengeSolverHTTP01Ingress), b.(*acme.ACMEChallengeSolverHTTP01Ingress), scope)
 	}); err != nil {
@@ -262,6 +283,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1.ACMEIssuerDNS01ProviderWebhookTLS)(nil), (*acme.ACMEIssuerDNS01ProviderWebhookTLS)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_ACMEIssuerDNS01ProviderWebhookTLS_To_acme_ACMEIssuerDNS01ProviderWebhookTLS(a.(*v1.ACMEIssuerDNS01ProviderWebhookTLS), b.(*acme.ACMEIssuerDNS01ProviderWebhookTLS), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*acme.ACMEIssuerDNS01ProviderWebhookTLS)(nil), (*v1.ACMEIssuerDNS01ProviderWebhookTLS)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_acme_ACMEIssuerDNS01ProviderWebhookTLS_To_v1_ACMEIssuerDNS01ProviderWebhookTLS(a.(*acme.ACMEIssuerDNS01ProviderWebhookTLS), b.(*v1.ACMEIssuerDNS01ProviderWebhookTLS), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1.ACMEIssuerStatus)(nil), (*acme.ACMEIssuerStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_ACMEIssuerStatus_To_acme_ACMEIssuerStatus(a.(*v1.ACMEIssuerStatus), b.(*acme.ACMEIssuerStatus), scope)
 	}); err != nil {
@@ -272,6 +303,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1.AzureWorkloadIdentity)(nil), (*acme.AzureWorkloadIdentity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_AzureWorkloadIdentity_To_acme_AzureWorkloadIdentity(a.(*v1.AzureWorkloadIdentity), b.(*acme.AzureWorkloadIdentity), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*acme.AzureWorkloadIdentity)(nil), (*v1.AzureWorkloadIdentity)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_acme_AzureWorkloadIdentity_To_v1_AzureWorkloadIdentity(a.(*acme.AzureWorkloadIdentity), b.(*v1.AzureWorkloadIdentity), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1.CertificateDNSNameSelector)(nil), (*acme.CertificateDNSNameSelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_CertificateDNSNameSelector_To_acme_CertificateDNSNameSelector(a.(*v1.CertificateDNSNameSelector), b.(*acme.CertificateDNSNameSelector), scope)
 	}); err != nil {
@@ -531,7 +572,26 @@ func autoConvert_v1_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDNS01(in
 	} else {
 		out.RFC2136 = nil
 	}
-	out.Webhook = (*acme.ACMEIssuerDNS01ProviderWebhook)(unsafe.Pointer(in.Webhook))
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(acme.ACMEIssuerDNS01ProviderWebhook)
+		if err := Convert_v1_ACMEIssuerDNS01ProviderWebhook_To_acme_ACMEIssuerDNS01ProviderWebhook(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Webhook = nil
+	}
+	if in.AdditionalProviders != nil {
+		in, out := &in.AdditionalProviders, &out.AdditionalProviders
+		*out = make([]acme.ACMEChallengeSolverDNS01, len(*in))
+		for i := range *in {
+			if err := Convert_v1_ACMEChallengeSolverDNS01_To_acme_ACMEChallengeSolverDNS01(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalProviders = nil
+	}
 	return nil
 }
 
@@ -614,7 +674,26 @@ func autoConvert_acme_ACMEChallengeSolverDNS01_To_v1_ACMEChallengeSolverDNS01(in
 	} else {
 		out.RFC2136 = nil
 	}
-	out.Webhook = (*v1.ACMEIssuerDNS01ProviderWebhook)(unsafe.Pointer(in.Webhook))
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(v1.ACMEIssuerDNS01ProviderWebhook)
+		if err := Convert_acme_ACMEIssuerDNS01ProviderWebhook_To_v1_ACMEIssuerDNS01ProviderWebhook(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.Webhook = nil
+	}
+	if in.AdditionalProviders != nil {
+		in, out := &in.AdditionalProviders, &out.AdditionalProviders
+		*out = make([]v1.ACMEChallengeSolverDNS01, len(*in))
+		for i := range *in {
+			if err := Convert_acme_ACMEChallengeSolverDNS01_To_v1_ACMEChallengeSolverDNS01(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.AdditionalProviders = nil
+	}
 	return nil
 }
 
@@ -625,6 +704,7 @@ func Convert_acme_ACMEChallengeSolverDNS01_To_v1_ACMEChallengeSolverDNS01(in *ac
 
 func autoConvert_v1_ACMEChallengeSolverHTTP01_To_acme_ACMEChallengeSolverHTTP01(in *v1.ACMEChallengeSolverHTTP01, out *acme.ACMEChallengeSolverHTTP01, s conversion.Scope) error {
 	out.Ingress = (*acme.ACMEChallengeSolverHTTP01Ingress)(unsafe.Pointer(in.Ingress))
+	out.GatewayHTTPRoute = (*acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute)(unsafe.Pointer(in.GatewayHTTPRoute))
 	return nil
 }
 
@@ -635,6 +715,7 @@ func Convert_v1_ACMEChallengeSolverHTTP01_To_acme_ACMEChallengeSolverHTTP01(in *
 
 func autoConvert_acme_ACMEChallengeSolverHTTP01_To_v1_ACMEChallengeSolverHTTP01(in *acme.ACMEChallengeSolverHTTP01, out *v1.ACMEChallengeSolverHTTP01, s conversion.Scope) error {
 	out.Ingress = (*v1.ACMEChallengeSolverHTTP01Ingress)(unsafe.Pointer(in.Ingress))
+	out.GatewayHTTPRoute = (*v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute)(unsafe.Pointer(in.GatewayHTTPRoute))
 	return nil
 }
 
@@ -643,9 +724,61 @@ func Convert_acme_ACMEChallengeSolverHTTP01_To_v1_ACMEChallengeSolverHTTP01(in *
 	return autoConvert_acme_ACMEChallengeSolverHTTP01_To_v1_ACMEChallengeSolverHTTP01(in, out, s)
 }
 
+func autoConvert_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute(in *v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute, out *acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute, s conversion.Scope) error {
+	out.ServiceType = corev1.ServiceType(in.ServiceType)
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	if err := Convert_v1_ACMEChallengeSolverHTTP01GatewayReference_To_acme_ACMEChallengeSolverHTTP01GatewayReference(&in.GatewayRef, &out.GatewayRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute is an autogenerated conversion function.
+func Convert_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute(in *v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute, out *acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute, s conversion.Scope) error {
+	return autoConvert_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute(in, out, s)
+}
+
+func autoConvert_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute(in *acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute, out *v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute, s conversion.Scope) error {
+	out.ServiceType = corev1.ServiceType(in.ServiceType)
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	if err := Convert_acme_ACMEChallengeSolverHTTP01GatewayReference_To_v1_ACMEChallengeSolverHTTP01GatewayReference(&in.GatewayRef, &out.GatewayRef, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute is an autogenerated conversion function.
+func Convert_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute(in *acme.ACMEChallengeSolverHTTP01GatewayHTTPRoute, out *v1.ACMEChallengeSolverHTTP01GatewayHTTPRoute, s conversion.Scope) error {
+	return autoConvert_acme_ACMEChallengeSolverHTTP01GatewayHTTPRoute_To_v1_ACMEChallengeSolverHTTP01GatewayHTTPRoute(in, out, s)
+}
+
+func autoConvert_v1_ACMEChallengeSolverHTTP01GatewayReference_To_acme_ACMEChallengeSolverHTTP01GatewayReference(in *v1.ACMEChallengeSolverHTTP01GatewayReference, out *acme.ACMEChallengeSolverHTTP01GatewayReference, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Namespace = in.Namespace
+	return nil
+}
+
+// Convert_v1_ACMEChallengeSolverHTTP01GatewayReference_To_acme_ACMEChallengeSolverHTTP01GatewayReference is an autogenerated conversion function.
+func Convert_v1_ACMEChallengeSolverHTTP01GatewayReference_To_acme_ACMEChallengeSolverHTTP01GatewayReference(in *v1.ACMEChallengeSolverHTTP01GatewayReference, out *acme.ACMEChallengeSolverHTTP01GatewayReference, s conversion.Scope) error {
+	return autoConvert_v1_ACMEChallengeSolverHTTP01GatewayReference_To_acme_ACMEChallengeSolverHTTP01GatewayReference(in, out, s)
+}
+
+func autoConvert_acme_ACMEChallengeSolverHTTP01GatewayReference_To_v1_ACMEChallengeSolverHTTP01GatewayReference(in *acme.ACMEChallengeSolverHTTP01GatewayReference, out *v1.ACMEChallengeSolverHTTP01GatewayReference, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Namespace = in.Namespace
+	return nil
+}
+
+// Convert_acme_ACMEChallengeSolverHTTP01GatewayReference_To_v1_ACMEChallengeSolverHTTP01GatewayReference is an autogenerated conversion function.
+func Convert_acme_ACMEChallengeSolverHTTP01GatewayReference_To_v1_ACMEChallengeSolverHTTP01GatewayReference(in *acme.ACMEChallengeSolverHTTP01GatewayReference, out *v1.ACMEChallengeSolverHTTP01GatewayReference, s conversion.Scope) error {
+	return autoConvert_acme_ACMEChallengeSolverHTTP01GatewayReference_To_v1_ACMEChallengeSolverHTTP01GatewayReference(in, out, s)
+}
+
 func autoConvert_v1_ACMEChallengeSolverHTTP01Ingress_To_acme_ACMEChallengeSolverHTTP01Ingress(in *v1.ACMEChallengeSolverHTTP01Ingress, out *acme.ACMEChallengeSolverHTTP01Ingress, s conversion.Scope) error {
 	out.ServiceType = corev1.ServiceType(in.ServiceType)
 	out.Class = (*string)(unsafe.Pointer(in.Class))
+	out.IngressClassName = (*string)(unsafe.Pointer(in.IngressClassName))
+	out.PathType = (*networkingv1.PathType)(unsafe.Pointer(in.PathType))
 	out.Name = in.Name
 	out.PodTemplate = (*acme.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	out.IngressTemplate = (*acme.ACMEChallengeSolverHTTP01IngressTemplate)(unsafe.Pointer(in.IngressTemplate))
@@ -660,6 +793,8 @@ func Convert_v1_ACMEChallengeSolverHTTP01Ingress_To_acme_ACMEChallengeSolverHTTP
 func autoConvert_acme_ACMEChallengeSolverHTTP01Ingress_To_v1_ACMEChallengeSolverHTTP01Ingress(in *acme.ACMEChallengeSolverHTTP01Ingress, out *v1.ACMEChallengeSolverHTTP01Ingress, s conversion.Scope) error {
 	out.ServiceType = corev1.ServiceType(in.ServiceType)
 	out.Class = (*string)(unsafe.Pointer(in.Class))
+	out.IngressClassName = (*string)(unsafe.Pointer(in.IngressClassName))
+	out.PathType = (*networkingv1.PathType)(unsafe.Pointer(in.PathType))
 	out.Name = in.Name
 	out.PodTemplate = (*v1.ACMEChallengeSolverHTTP01IngressPodTemplate)(unsafe.Pointer(in.PodTemplate))
 	out.IngressTemplate = (*v1.ACMEChallengeSolverHTTP01IngressTemplate)(unsafe.Pointer(in.IngressTemplate))
@@ -721,6 +856,7 @@ func autoConvert_v1_ACMEChallengeSolverHTTP01IngressPodSpec_To_acme_ACMEChalleng
 	out.Tolerations = *(*[]corev1.Toleration)(unsafe.Pointer(&in.Tolerations))
 	out.PriorityClassName = in.PriorityClassName
 	out.ServiceAccountName = in.ServiceAccountName
+	out.Resources = (*corev1.ResourceRequirements)(unsafe.Pointer(in.Resources))
 	return nil
 }
 
@@ -735,6 +871,7 @@ func autoConvert_acme_ACMEChallengeSolverHTTP01IngressPodSpec_To_v1_ACMEChalleng
 	out.Tolerations = *(*[]corev1.Toleration)(unsafe.Pointer(&in.Tolerations))
 	out.PriorityClassName = in.PriorityClassName
 	out.ServiceAccountName = in.ServiceAccountName
+	out.Resources = (*corev1.ResourceRequirements)(unsafe.Pointer(in.Resources))
 	return nil
 }
 
@@ -830,6 +967,7 @@ func autoConvert_v1_ACMEIssuer_To_acme_ACMEIssuer(in *v1.ACMEIssuer, out *acme.A
 	out.Server = in.Server
 	out.PreferredChain = in.PreferredChain
 	out.SkipTLSVerify = in.SkipTLSVerify
+	out.UserAgent = in.UserAgent
 	if in.ExternalAccountBinding != nil {
 		in, out := &in.ExternalAccountBinding, &out.ExternalAccountBinding
 		*out = new(acme.ACMEExternalAccountBinding)
@@ -842,6 +980,15 @@ func autoConvert_v1_ACMEIssuer_To_acme_ACMEIssuer(in *v1.ACMEIssuer, out *acme.A
 	if err := metav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(&in.PrivateKey, &out.PrivateKey, s); err != nil {
 		return err
 	}
+	if in.PreviousPrivateKey != nil {
+		in, out := &in.PreviousPrivateKey, &out.PreviousPrivateKey
+		*out = new(meta.SecretKeySelector)
+		if err := metav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PreviousPrivateKey = nil
+	}
 	if in.Solvers != nil {
 		in, out := &in.Solvers, &out.Solvers
 		*out = make([]acme.ACMEChallengeSolver, len(*in))
@@ -855,6 +1002,7 @@ func autoConvert_v1_ACMEIssuer_To_acme_ACMEIssuer(in *v1.ACMEIssuer, out *acme.A
 	}
 	out.DisableAccountKeyGeneration = in.DisableAccountKeyGeneration
 	out.EnableDurationFeature = in.EnableDurationFeature
+	out.MaxChallengeAttempts = (*int32)(unsafe.Pointer(in.MaxChallengeAttempts))
 	return nil
 }
 
@@ -868,6 +1016,7 @@ func autoConvert_acme_ACMEIssuer_To_v1_ACMEIssuer(in *acme.ACMEIssuer, out *v1.A
 	out.Server = in.Server
 	out.PreferredChain = in.PreferredChain
 	out.SkipTLSVerify = in.SkipTLSVerify
+	out.UserAgent = in.UserAgent
 	if in.ExternalAccountBinding != nil {
 		in, out := &in.ExternalAccountBinding, &out.ExternalAccountBinding
 		*out = new(v1.ACMEExternalAccountBinding)
@@ -880,6 +1029,15 @@ func autoConvert_acme_ACMEIssuer_To_v1_ACMEIssuer(in *acme.ACMEIssuer, out *v1.A
 	if err := metav1.Convert_meta_SecretKeySelector_To_v1_SecretKeySelector(&in.PrivateKey, &out.PrivateKey, s); err != nil {
 		return err
 	}
+	if in.PreviousPrivateKey != nil {
+		in, out := &in.PreviousPrivateKey, &out.PreviousPrivateKey
+		*out = new(apismetav1.SecretKeySelector)
+		if err := metav1.Convert_meta_SecretKeySelector_To_v1_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.PreviousPrivateKey = nil
+	}
 	if in.Solvers != nil {
 		in, out := &in.Solvers, &out.Solvers
 		*out = make([]v1.ACMEChallengeSolver, len(*in))
@@ -893,6 +1051,7 @@ func autoConvert_acme_ACMEIssuer_To_v1_ACMEIssuer(in *acme.ACMEIssuer, out *v1.A
 	}
 	out.DisableAccountKeyGeneration = in.DisableAccountKeyGeneration
 	out.EnableDurationFeature = in.EnableDurationFeature
+	out.MaxChallengeAttempts = (*int32)(unsafe.Pointer(in.MaxChallengeAttempts))
 	return nil
 }
 
@@ -976,11 +1135,13 @@ func autoConvert_v1_ACMEIssuerDNS01ProviderAzureDNS_To_acme_ACMEIssuerDNS01Provi
 	} else {
 		out.ClientSecret = nil
 	}
+	out.ClientSecretFile = in.ClientSecretFile
 	out.SubscriptionID = in.SubscriptionID
 	out.TenantID = in.TenantID
 	out.ResourceGroupName = in.ResourceGroupName
 	out.HostedZoneName = in.HostedZoneName
 	out.Environment = acme.AzureDNSEnvironment(in.Environment)
+	out.WorkloadIdentity = (*acme.AzureWorkloadIdentity)(unsafe.Pointer(in.WorkloadIdentity))
 	return nil
 }
 
@@ -1000,11 +1161,13 @@ func autoConvert_acme_ACMEIssuerDNS01ProviderAzureDNS_To_v1_ACMEIssuerDNS01Provi
 	} else {
 		out.ClientSecret = nil
 	}
+	out.ClientSecretFile = in.ClientSecretFile
 	out.SubscriptionID = in.SubscriptionID
 	out.TenantID = in.TenantID
 	out.ResourceGroupName = in.ResourceGroupName
 	out.HostedZoneName = in.HostedZoneName
 	out.Environment = v1.AzureDNSEnvironment(in.Environment)
+	out.WorkloadIdentity = (*v1.AzureWorkloadIdentity)(unsafe.Pointer(in.WorkloadIdentity))
 	return nil
 }
 
@@ -1024,6 +1187,7 @@ func autoConvert_v1_ACMEIssuerDNS01ProviderCloudDNS_To_acme_ACMEIssuerDNS01Provi
 		out.ServiceAccount = nil
 	}
 	out.Project = in.Project
+	out.ServiceAccountFile = in.ServiceAccountFile
 	out.HostedZoneName = in.HostedZoneName
 	return nil
 }
@@ -1045,6 +1209,7 @@ func autoConvert_acme_ACMEIssuerDNS01ProviderCloudDNS_To_v1_ACMEIssuerDNS01Provi
 	}
 	out.Project = in.Project
 	out.HostedZoneName = in.HostedZoneName
+	out.ServiceAccountFile = in.ServiceAccountFile
 	return nil
 }
 
@@ -1168,7 +1333,9 @@ func autoConvert_v1_ACMEIssuerDNS01ProviderRoute53_To_acme_ACMEIssuerDNS01Provid
 	if err := metav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(&in.SecretAccessKey, &out.SecretAccessKey, s); err != nil {
 		return err
 	}
+	out.SecretAccessKeyFile = in.SecretAccessKeyFile
 	out.Role = in.Role
+	out.ExternalID = in.ExternalID
 	out.HostedZoneID = in.HostedZoneID
 	out.Region = in.Region
 	return nil
@@ -1184,7 +1351,9 @@ func autoConvert_acme_ACMEIssuerDNS01ProviderRoute53_To_v1_ACMEIssuerDNS01Provid
 	if err := metav1.Convert_meta_SecretKeySelector_To_v1_SecretKeySelector(&in.SecretAccessKey, &out.SecretAccessKey, s); err != nil {
 		return err
 	}
+	out.SecretAccessKeyFile = in.SecretAccessKeyFile
 	out.Role = in.Role
+	out.ExternalID = in.ExternalID
 	out.HostedZoneID = in.HostedZoneID
 	out.Region = in.Region
 	return nil
@@ -1199,6 +1368,15 @@ func autoConvert_v1_ACMEIssuerDNS01ProviderWebhook_To_acme_ACMEIssuerDNS01Provid
 	out.GroupName = in.GroupName
 	out.SolverName = in.SolverName
 	out.Config = (*v1beta1.JSON)(unsafe.Pointer(in.Config))
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(acme.ACMEIssuerDNS01ProviderWebhookTLS)
+		if err := Convert_v1_ACMEIssuerDNS01ProviderWebhookTLS_To_acme_ACMEIssuerDNS01ProviderWebhookTLS(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TLS = nil
+	}
 	return nil
 }
 
@@ -1211,6 +1389,15 @@ func autoConvert_acme_ACMEIssuerDNS01ProviderWebhook_To_v1_ACMEIssuerDNS01Provid
 	out.GroupName = in.GroupName
 	out.SolverName = in.SolverName
 	out.Config = (*v1beta1.JSON)(unsafe.Pointer(in.Config))
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(v1.ACMEIssuerDNS01ProviderWebhookTLS)
+		if err := Convert_acme_ACMEIssuerDNS01ProviderWebhookTLS_To_v1_ACMEIssuerDNS01ProviderWebhookTLS(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TLS = nil
+	}
 	return nil
 }
 
@@ -1219,9 +1406,39 @@ func Convert_acme_ACMEIssuerDNS01ProviderWebhook_To_v1_ACMEIssuerDNS01ProviderWe
 	return autoConvert_acme_ACMEIssuerDNS01ProviderWebhook_To_v1_ACMEIssuerDNS01ProviderWebhook(in, out, s)
 }
 
+func autoConvert_v1_ACMEIssuerDNS01ProviderWebhookTLS_To_acme_ACMEIssuerDNS01ProviderWebhookTLS(in *v1.ACMEIssuerDNS01ProviderWebhookTLS, out *acme.ACMEIssuerDNS01ProviderWebhookTLS, s conversion.Scope) error {
+	out.URL = in.URL
+	if err := metav1.Convert_v1_LocalObjectReference_To_meta_LocalObjectReference(&in.ClientCertSecretRef, &out.ClientCertSecretRef, s); err != nil {
+		return err
+	}
+	out.CABundle = *(*[]byte)(unsafe.Pointer(&in.CABundle))
+	return nil
+}
+
+// Convert_v1_ACMEIssuerDNS01ProviderWebhookTLS_To_acme_ACMEIssuerDNS01ProviderWebhookTLS is an autogenerated conversion function.
+func Convert_v1_ACMEIssuerDNS01ProviderWebhookTLS_To_acme_ACMEIssuerDNS01ProviderWebhookTLS(in *v1.ACMEIssuerDNS01ProviderWebhookTLS, out *acme.ACMEIssuerDNS01ProviderWebhookTLS, s conversion.Scope) error {
+	return autoConvert_v1_ACMEIssuerDNS01ProviderWebhookTLS_To_acme_ACMEIssuerDNS01ProviderWebhookTLS(in, out, s)
+}
+
+func autoConvert_acme_ACMEIssuerDNS01ProviderWebhookTLS_To_v1_ACMEIssuerDNS01ProviderWebhookTLS(in *acme.ACMEIssuerDNS01ProviderWebhookTLS, out *v1.ACMEIssuerDNS01ProviderWebhookTLS, s conversion.Scope) error {
+	out.URL = in.URL
+	if err := metav1.Convert_meta_LocalObjectReference_To_v1_LocalObjectReference(&in.ClientCertSecretRef, &out.ClientCertSecretRef, s); err != nil {
+		return err
+	}
+	out.CABundle = *(*[]byte)(unsafe.Pointer(&in.CABundle))
+	return nil
+}
+
+// Convert_acme_ACMEIssuerDNS01ProviderWebhookTLS_To_v1_ACMEIssuerDNS01ProviderWebhookTLS is an autogenerated conversion function.
+func Convert_acme_ACMEIssuerDNS01ProviderWebhookTLS_To_v1_ACMEIssuerDNS01ProviderWebhookTLS(in *acme.ACMEIssuerDNS01ProviderWebhookTLS, out *v1.ACMEIssuerDNS01ProviderWebhookTLS, s conversion.Scope) error {
+	return autoConvert_acme_ACMEIssuerDNS01ProviderWebhookTLS_To_v1_ACMEIssuerDNS01ProviderWebhookTLS(in, out, s)
+}
+
 func autoConvert_v1_ACMEIssuerStatus_To_acme_ACMEIssuerStatus(in *v1.ACMEIssuerStatus, out *acme.ACMEIssuerStatus, s conversion.Scope) error {
 	out.URI = in.URI
 	out.LastRegisteredEmail = in.LastRegisteredEmail
+	out.LastRateLimitError = in.LastRateLimitError
+	out.RateLimitedUntil = (*pkgapismetav1.Time)(unsafe.Pointer(in.RateLimitedUntil))
 	return nil
 }
 
@@ -1233,6 +1450,8 @@ func Convert_v1_ACMEIssuerStatus_To_acme_ACMEIssuerStatus(in *v1.ACMEIssuerStatu
 func autoConvert_acme_ACMEIssuerStatus_To_v1_ACMEIssuerStatus(in *acme.ACMEIssuerStatus, out *v1.ACMEIssuerStatus, s conversion.Scope) error {
 	out.URI = in.URI
 	out.LastRegisteredEmail = in.LastRegisteredEmail
+	out.LastRateLimitError = in.LastRateLimitError
+	out.RateLimitedUntil = (*pkgapismetav1.Time)(unsafe.Pointer(in.RateLimitedUntil))
 	return nil
 }
 
@@ -1241,6 +1460,24 @@ func Convert_acme_ACMEIssuerStatus_To_v1_ACMEIssuerStatus(in *acme.ACMEIssuerSta
 	return autoConvert_acme_ACMEIssuerStatus_To_v1_ACMEIssuerStatus(in, out, s)
 }
 
+func autoConvert_v1_AzureWorkloadIdentity_To_acme_AzureWorkloadIdentity(in *v1.AzureWorkloadIdentity, out *acme.AzureWorkloadIdentity, s conversion.Scope) error {
+	return nil
+}
+
+// Convert_v1_AzureWorkloadIdentity_To_acme_AzureWorkloadIdentity is an autogenerated conversion function.
+func Convert_v1_AzureWorkloadIdentity_To_acme_AzureWorkloadIdentity(in *v1.AzureWorkloadIdentity, out *acme.AzureWorkloadIdentity, s conversion.Scope) error {
+	return autoConvert_v1_AzureWorkloadIdentity_To_acme_AzureWorkloadIdentity(in, out, s)
+}
+
+func autoConvert_acme_AzureWorkloadIdentity_To_v1_AzureWorkloadIdentity(in *acme.AzureWorkloadIdentity, out *v1.AzureWorkloadIdentity, s conversion.Scope) error {
+	return nil
+}
+
+// Convert_acme_AzureWorkloadIdentity_To_v1_AzureWorkloadIdentity is an autogenerated conversion function.
+func Convert_acme_AzureWorkloadIdentity_To_v1_AzureWorkloadIdentity(in *acme.AzureWorkloadIdentity, out *v1.AzureWorkloadIdentity, s conversion.Scope) error {
+	return autoConvert_acme_AzureWorkloadIdentity_To_v1_AzureWorkloadIdentity(in, out, s)
+}
+
 func autoConvert_v1_CertificateDNSNameSelector_To_acme_CertificateDNSNameSelector(in *v1.CertificateDNSNameSelector, out *acme.CertificateDNSNameSelector, s conversion.Scope) error {
 	out.MatchLabels = *(*map[string]string)(unsafe.Pointer(&in.MatchLabels))
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
@@ -1388,6 +1625,7 @@ func autoConvert_v1_ChallengeStatus_To_acme_ChallengeStatus(in *v1.ChallengeStat
 	out.Presented = in.Presented
 	out.Reason = in.Reason
 	out.State = acme.State(in.State)
+	out.FailedAttempts = in.FailedAttempts
 	return nil
 }
 
@@ -1401,6 +1639,7 @@ func autoConvert_acme_ChallengeStatus_To_v1_ChallengeStatus(in *acme.ChallengeSt
 	out.Presented = in.Presented
 	out.Reason = in.Reason
 	out.State = v1.State(in.State)
+	out.FailedAttempts = in.FailedAttempts
 	return nil
 }
 
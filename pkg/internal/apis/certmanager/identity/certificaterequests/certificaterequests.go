@@ -19,11 +19,14 @@ limitations under the License.
 package certificaterequests
 
 import (
+	"context"
 	"reflect"
+	"strings"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
@@ -31,7 +34,51 @@ import (
 	"github.com/jetstack/cert-manager/pkg/util"
 )
 
-func ValidateCreate(req *admissionv1.AdmissionRequest, obj runtime.Object) (field.ErrorList, validation.WarningList) {
+// Options configures how a delegated requester identity is recorded on
+// CertificateRequests.
+type Options struct {
+	// TrustedDelegators are the usernames that may attribute the
+	// CertificateRequests they create to another user with the requester
+	// annotations. If empty, the requester annotations are always ignored.
+	TrustedDelegators []string
+
+	// RequesterUsernameAnnotation is the annotation holding the username of
+	// the user a CertificateRequest was created on behalf of. Defaults to
+	// RequesterUsernameAnnotationKey.
+	RequesterUsernameAnnotation string
+
+	// RequesterGroupsAnnotation is the annotation holding a comma separated
+	// list of the groups of the user a CertificateRequest was created on
+	// behalf of. Defaults to RequesterGroupsAnnotationKey.
+	RequesterGroupsAnnotation string
+}
+
+// Identity populates and enforces the identity of the user that created a
+// CertificateRequest, including the identity of the user it was created on
+// behalf of if it was created by a trusted delegator.
+type Identity struct {
+	trustedDelegators  sets.String
+	usernameAnnotation string
+	groupsAnnotation   string
+}
+
+// New returns an Identity configured with the given options.
+func New(opts Options) *Identity {
+	id := &Identity{
+		trustedDelegators:  sets.NewString(opts.TrustedDelegators...),
+		usernameAnnotation: opts.RequesterUsernameAnnotation,
+		groupsAnnotation:   opts.RequesterGroupsAnnotation,
+	}
+	if id.usernameAnnotation == "" {
+		id.usernameAnnotation = cmapi.RequesterUsernameAnnotationKey
+	}
+	if id.groupsAnnotation == "" {
+		id.groupsAnnotation = cmapi.RequesterGroupsAnnotationKey
+	}
+	return id
+}
+
+func (i *Identity) ValidateCreate(req *admissionv1.AdmissionRequest, obj runtime.Object) (field.ErrorList, validation.WarningList) {
 	cr := obj.(*cmapi.CertificateRequest)
 	fldPath := field.NewPath("spec")
 
@@ -45,7 +92,7 @@ func ValidateCreate(req *admissionv1.AdmissionRequest, obj runtime.Object) (fiel
 	if !util.EqualUnsorted(cr.Spec.Groups, req.UserInfo.Groups) {
 		el = append(el, field.Forbidden(fldPath.Child("groups"), "groups identity must be that of the requester"))
 	}
-	if !extrasMatch(cr.Spec.Extra, req.UserInfo.Extra) {
+	if !extrasMatch(cr.Spec.Extra, i.requesterExtra(cr, req.UserInfo)) {
 		el = append(el, field.Forbidden(fldPath.Child("extra"), "extra identity must be that of the requester"))
 	}
 
@@ -71,6 +118,42 @@ func extrasMatch(crExtra map[string][]string, reqExtra map[string]authentication
 	return true
 }
 
+// requesterExtra returns the extra identity fields expected on the
+// CertificateRequest: those of the requesting user, plus the delegated
+// requester identity recorded from the CertificateRequest's annotations if
+// the requesting user is a trusted delegator. Extra fields of the requesting
+// user always take precedence.
+func (i *Identity) requesterExtra(cr *cmapi.CertificateRequest, userInfo authenticationv1.UserInfo) map[string]authenticationv1.ExtraValue {
+	extra := make(map[string]authenticationv1.ExtraValue, len(userInfo.Extra))
+	for k, v := range userInfo.Extra {
+		extra[k] = v
+	}
+
+	if !i.trustedDelegators.Has(userInfo.Username) {
+		return extra
+	}
+
+	if username, ok := cr.Annotations[i.usernameAnnotation]; ok && len(username) > 0 {
+		if _, ok := extra[i.usernameAnnotation]; !ok {
+			extra[i.usernameAnnotation] = authenticationv1.ExtraValue{username}
+		}
+	}
+
+	if groups, ok := cr.Annotations[i.groupsAnnotation]; ok {
+		var values authenticationv1.ExtraValue
+		for _, group := range strings.Split(groups, ",") {
+			if group = strings.TrimSpace(group); len(group) > 0 {
+				values = append(values, group)
+			}
+		}
+		if _, ok := extra[i.groupsAnnotation]; !ok && len(values) > 0 {
+			extra[i.groupsAnnotation] = values
+		}
+	}
+
+	return extra
+}
+
 func ValidateUpdate(_ *admissionv1.AdmissionRequest, oldObj, newObj runtime.Object) (field.ErrorList, validation.WarningList) {
 	oldCR, newCR := oldObj.(*cmapi.CertificateRequest), newObj.(*cmapi.CertificateRequest)
 	fldPath := field.NewPath("spec")
@@ -92,7 +175,7 @@ func ValidateUpdate(_ *admissionv1.AdmissionRequest, oldObj, newObj runtime.Obje
 	return el, nil
 }
 
-func MutateCreate(req *admissionv1.AdmissionRequest, obj runtime.Object) {
+func (i *Identity) MutateCreate(_ context.Context, req *admissionv1.AdmissionRequest, obj runtime.Object) {
 	cr := obj.(*cmapi.CertificateRequest)
 	userInfo := req.DeepCopy().UserInfo
 
@@ -100,10 +183,10 @@ func MutateCreate(req *admissionv1.AdmissionRequest, obj runtime.Object) {
 	cr.Spec.Username = userInfo.Username
 	cr.Spec.Groups = userInfo.Groups
 	cr.Spec.Extra = make(map[string][]string)
-	for k, v := range userInfo.Extra {
+	for k, v := range i.requesterExtra(cr, userInfo) {
 		cr.Spec.Extra[k] = v
 	}
 }
 
-func MutateUpdate(_ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
+func MutateUpdate(_ context.Context, _ *admissionv1.AdmissionRequest, _, _ runtime.Object) {
 }
@@ -17,17 +17,24 @@ limitations under the License.
 package certificaterequests
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
 	cmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
 )
 
+// testDelegator is the username of the trusted delegator used in tests.
+const testDelegator = "system:serviceaccount:cert-manager:cert-manager"
+
+var testIdentity = New(Options{TrustedDelegators: []string{testDelegator}})
+
 func TestValidateCreate(t *testing.T) {
 	fldPath := field.NewPath("spec")
 
@@ -92,11 +99,86 @@ func TestValidateCreate(t *testing.T) {
 			},
 			wantE: nil,
 		},
+		"if extra identity fields match the requester annotations, should pass": {
+			req: &admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{
+					UID:      "abc",
+					Username: "system:serviceaccount:cert-manager:cert-manager",
+				},
+			},
+			cr: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-1",
+						cmapi.RequesterGroupsAnnotationKey:   "group-1, group-2",
+					},
+				},
+				Spec: cmapi.CertificateRequestSpec{
+					UID:      "abc",
+					Username: "system:serviceaccount:cert-manager:cert-manager",
+					Extra: map[string][]string{
+						cmapi.RequesterUsernameAnnotationKey: {"user-1"},
+						cmapi.RequesterGroupsAnnotationKey:   {"group-1", "group-2"},
+					},
+				},
+			},
+			wantE: nil,
+		},
+		"if extra identity fields don't match the requester annotations, should fail": {
+			req: &admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{
+					UID:      "abc",
+					Username: "system:serviceaccount:cert-manager:cert-manager",
+				},
+			},
+			cr: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-1",
+					},
+				},
+				Spec: cmapi.CertificateRequestSpec{
+					UID:      "abc",
+					Username: "system:serviceaccount:cert-manager:cert-manager",
+					Extra: map[string][]string{
+						cmapi.RequesterUsernameAnnotationKey: {"user-2"},
+					},
+				},
+			},
+			wantE: field.ErrorList{
+				field.Forbidden(fldPath.Child("extra"), "extra identity must be that of the requester"),
+			},
+		},
+		"if the requester is not a trusted delegator, extra identity fields from the requester annotations should fail": {
+			req: &admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{
+					UID:      "abc",
+					Username: "user-3",
+				},
+			},
+			cr: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-1",
+					},
+				},
+				Spec: cmapi.CertificateRequestSpec{
+					UID:      "abc",
+					Username: "user-3",
+					Extra: map[string][]string{
+						cmapi.RequesterUsernameAnnotationKey: {"user-1"},
+					},
+				},
+			},
+			wantE: field.ErrorList{
+				field.Forbidden(fldPath.Child("extra"), "extra identity must be that of the requester"),
+			},
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotE, gotW := ValidateCreate(test.req, test.cr)
+			gotE, gotW := testIdentity.ValidateCreate(test.req, test.cr)
 			if !reflect.DeepEqual(gotE, test.wantE) {
 				t.Errorf("errors from ValidateCreate() = %v, want %v", gotE, test.wantE)
 			}
@@ -250,15 +332,140 @@ func TestMutateCreate(t *testing.T) {
 				},
 			},
 		},
+		"should populate the requester extra fields from annotations on a CREATE operation": {
+			req: &admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{
+					UID:      "abc",
+					Username: "system:serviceaccount:cert-manager:cert-manager",
+					Groups:   []string{"system:serviceaccounts"},
+				},
+			},
+			existingCR: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-1",
+						cmapi.RequesterGroupsAnnotationKey:   "group-1, group-2,",
+					},
+				},
+			},
+			expectedCR: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-1",
+						cmapi.RequesterGroupsAnnotationKey:   "group-1, group-2,",
+					},
+				},
+				Spec: cmapi.CertificateRequestSpec{
+					UID:      "abc",
+					Username: "system:serviceaccount:cert-manager:cert-manager",
+					Groups:   []string{"system:serviceaccounts"},
+					Extra: map[string][]string{
+						cmapi.RequesterUsernameAnnotationKey: {"user-1"},
+						cmapi.RequesterGroupsAnnotationKey:   {"group-1", "group-2"},
+					},
+				},
+			},
+		},
+		"should not override extra fields of the requester with annotations on a CREATE operation": {
+			req: &admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{
+					UID:      "abc",
+					Username: testDelegator,
+					Extra: map[string]authenticationv1.ExtraValue{
+						cmapi.RequesterUsernameAnnotationKey: []string{"user-1"},
+					},
+				},
+			},
+			existingCR: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-2",
+					},
+				},
+			},
+			expectedCR: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-2",
+					},
+				},
+				Spec: cmapi.CertificateRequestSpec{
+					UID:      "abc",
+					Username: testDelegator,
+					Extra: map[string][]string{
+						cmapi.RequesterUsernameAnnotationKey: {"user-1"},
+					},
+				},
+			},
+		},
+		"should ignore the requester annotations if the requester is not a trusted delegator on a CREATE operation": {
+			req: &admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{
+					UID:      "abc",
+					Username: "user-3",
+				},
+			},
+			existingCR: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-1",
+						cmapi.RequesterGroupsAnnotationKey:   "group-1",
+					},
+				},
+			},
+			expectedCR: &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						cmapi.RequesterUsernameAnnotationKey: "user-1",
+						cmapi.RequesterGroupsAnnotationKey:   "group-1",
+					},
+				},
+				Spec: cmapi.CertificateRequestSpec{
+					UID:      "abc",
+					Username: "user-3",
+					Extra:    map[string][]string{},
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			cr := test.expectedCR.DeepCopy()
-			MutateCreate(test.req, cr)
+			cr := test.existingCR.DeepCopy()
+			testIdentity.MutateCreate(context.TODO(), test.req, cr)
 			if !reflect.DeepEqual(test.expectedCR, cr) {
 				t.Errorf("MutateCreate() = %v, want %v", cr, test.expectedCR)
 			}
 		})
 	}
 }
+
+func TestMutateCreateConfiguredAnnotations(t *testing.T) {
+	id := New(Options{
+		TrustedDelegators:           []string{testDelegator},
+		RequesterUsernameAnnotation: "example.com/on-behalf-of",
+		RequesterGroupsAnnotation:   "example.com/on-behalf-of-groups",
+	})
+	req := &admissionv1.AdmissionRequest{
+		UserInfo: authenticationv1.UserInfo{UID: "abc", Username: testDelegator},
+	}
+	cr := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"example.com/on-behalf-of":           "user-1",
+				"example.com/on-behalf-of-groups":    "group-1",
+				cmapi.RequesterUsernameAnnotationKey: "user-2",
+			},
+		},
+	}
+
+	id.MutateCreate(context.TODO(), req, cr)
+
+	expected := map[string][]string{
+		"example.com/on-behalf-of":        {"user-1"},
+		"example.com/on-behalf-of-groups": {"group-1"},
+	}
+	if !reflect.DeepEqual(expected, cr.Spec.Extra) {
+		t.Errorf("MutateCreate() extra = %v, want %v", cr.Spec.Extra, expected)
+	}
+}
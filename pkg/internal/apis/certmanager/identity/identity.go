@@ -23,8 +23,8 @@ import (
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/identity/certificaterequests"
 )
 
-func AddToValidationRegistry(reg *validation.Registry) error {
-	if err := reg.AddValidateFunc(&cmapi.CertificateRequest{}, certificaterequests.ValidateCreate); err != nil {
+func AddToValidationRegistry(reg *validation.Registry, opts certificaterequests.Options) error {
+	if err := reg.AddValidateFunc(&cmapi.CertificateRequest{}, certificaterequests.New(opts).ValidateCreate); err != nil {
 		return err
 	}
 	if err := reg.AddValidateUpdateFunc(&cmapi.CertificateRequest{}, certificaterequests.ValidateUpdate); err != nil {
@@ -34,8 +34,8 @@ func AddToValidationRegistry(reg *validation.Registry) error {
 	return nil
 }
 
-func AddToMutationRegistry(reg *mutation.Registry) error {
-	if err := reg.AddMutateFunc(&cmapi.CertificateRequest{}, certificaterequests.MutateCreate); err != nil {
+func AddToMutationRegistry(reg *mutation.Registry, opts certificaterequests.Options) error {
+	if err := reg.AddMutateFunc(&cmapi.CertificateRequest{}, certificaterequests.New(opts).MutateCreate); err != nil {
 		return err
 	}
 	if err := reg.AddMutateUpdateFunc(&cmapi.CertificateRequest{}, certificaterequests.MutateUpdate); err != nil {
@@ -26,6 +26,8 @@ import (
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
 	cmidentity "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/identity"
+	cridentity "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/identity/certificaterequests"
+	cmmutation "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/mutation"
 	v1 "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/v1"
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/v1alpha2"
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/v1alpha3"
@@ -48,13 +50,14 @@ func Install(scheme *runtime.Scheme) {
 
 // InstallValidation registers validation functions for the API group with a
 // validation registry
-func InstallValidation(registry *validation.Registry) {
-	utilruntime.Must(cmvalidation.AddToValidationRegistry(registry))
-	utilruntime.Must(cmidentity.AddToValidationRegistry(registry))
+func InstallValidation(registry *validation.Registry, opts cmvalidation.Options, identityOpts cridentity.Options) {
+	utilruntime.Must(cmvalidation.AddToValidationRegistry(registry, opts))
+	utilruntime.Must(cmidentity.AddToValidationRegistry(registry, identityOpts))
 }
 
 // InstallMutation registers mutation functions for the API group with a
 // mutation registry
-func InstallMutation(registry *mutation.Registry) {
-	utilruntime.Must(cmidentity.AddToMutationRegistry(registry))
+func InstallMutation(registry *mutation.Registry, opts cmmutation.Options, identityOpts cridentity.Options) {
+	utilruntime.Must(cmmutation.AddToMutationRegistry(registry, opts))
+	utilruntime.Must(cmidentity.AddToMutationRegistry(registry, identityOpts))
 }
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutation
+
+import (
+	"context"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
+	cmmeta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+)
+
+// Options configures the mutations applied to Certificates by the webhook.
+type Options struct {
+	// DefaultCertificateSecretName controls whether spec.secretName is set to
+	// the name of the Certificate when it is omitted on creation.
+	DefaultCertificateSecretName bool
+
+	// LowercaseCertificateDNSNames controls whether spec.dnsNames are
+	// converted to lower case on creation, so that they compare equal to the DNS names of
+	// issued certificates regardless of whether the issuer normalises their
+	// case.
+	LowercaseCertificateDNSNames bool
+
+	// NamespaceLister is used to look up the Namespace a Certificate is
+	// created in, so that the default Issuer annotations on that Namespace can
+	// be applied. If nil, no default Issuer is applied.
+	NamespaceLister corelisters.NamespaceLister
+}
+
+// certificateMutator applies the mutations configured by its Options to
+// Certificates as they are created.
+type certificateMutator struct {
+	opts Options
+}
+
+func newCertificateMutator(opts Options) *certificateMutator {
+	return &certificateMutator{opts: opts}
+}
+
+func (m *certificateMutator) Mutate(ctx context.Context, req *admissionv1.AdmissionRequest, obj runtime.Object) {
+	crt := obj.(*cmapi.Certificate)
+
+	// The name may not be known yet if the Certificate is created using
+	// metadata.generateName, in which case validation will reject it.
+	if m.opts.DefaultCertificateSecretName && crt.Spec.SecretName == "" && crt.Name != "" {
+		crt.Spec.SecretName = crt.Name
+	}
+
+	if crt.Spec.IssuerRef == (cmmeta.ObjectReference{}) {
+		m.defaultIssuerRef(ctx, req, crt)
+	}
+
+	// Only lower case the dnsNames of new Certificates, so that the specs of
+	// existing Certificates are not rewritten when they are next updated.
+	if m.opts.LowercaseCertificateDNSNames && req.Operation == admissionv1.Create {
+		for i, dnsName := range crt.Spec.DNSNames {
+			crt.Spec.DNSNames[i] = strings.ToLower(dnsName)
+		}
+	}
+}
+
+// defaultIssuerRef sets spec.issuerRef to the Issuer named by the
+// cert-manager.io/default-issuer annotation on the Certificate's Namespace, if
+// present. Failing to look up the Namespace is not fatal; the Certificate is
+// left unchanged and will be rejected by validation as having no issuerRef.
+func (m *certificateMutator) defaultIssuerRef(ctx context.Context, req *admissionv1.AdmissionRequest, crt *cmapi.Certificate) {
+	if m.opts.NamespaceLister == nil {
+		return
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = crt.Namespace
+	}
+	if namespace == "" {
+		return
+	}
+
+	ns, err := m.opts.NamespaceLister.Get(namespace)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "failed to get Namespace to apply default Issuer", "namespace", namespace)
+		return
+	}
+
+	name := ns.Annotations[cmapiv1.DefaultIssuerNameAnnotationKey]
+	if name == "" {
+		return
+	}
+
+	crt.Spec.IssuerRef = cmmeta.ObjectReference{
+		Name:  name,
+		Kind:  ns.Annotations[cmapiv1.DefaultIssuerKindAnnotationKey],
+		Group: ns.Annotations[cmapiv1.DefaultIssuerGroupAnnotationKey],
+	}
+}
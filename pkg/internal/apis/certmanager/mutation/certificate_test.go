@@ -0,0 +1,197 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
+	cmmeta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
+)
+
+func TestMutateCertificate(t *testing.T) {
+	tests := map[string]struct {
+		disabled bool
+		crt      *cmapi.Certificate
+		expected string
+	}{
+		"should default an omitted secretName to the name of the Certificate": {
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			},
+			expected: "test",
+		},
+		"should not override an explicit secretName": {
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec:       cmapi.CertificateSpec{SecretName: "explicit"},
+			},
+			expected: "explicit",
+		},
+		"should not default secretName if the Certificate has no name": {
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{GenerateName: "test-"},
+			},
+			expected: "",
+		},
+		"should not default secretName if defaulting is disabled": {
+			disabled: true,
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			},
+			expected: "",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newCertificateMutator(Options{DefaultCertificateSecretName: !test.disabled})
+			m.Mutate(context.TODO(), &admissionv1.AdmissionRequest{}, test.crt)
+			if test.crt.Spec.SecretName != test.expected {
+				t.Errorf("unexpected secretName, exp=%q got=%q", test.expected, test.crt.Spec.SecretName)
+			}
+		})
+	}
+}
+
+func TestMutateCertificateDefaultIssuer(t *testing.T) {
+	annotatedNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testns",
+			Annotations: map[string]string{
+				cmapiv1.DefaultIssuerNameAnnotationKey:  "default-issuer",
+				cmapiv1.DefaultIssuerKindAnnotationKey:  "ClusterIssuer",
+				cmapiv1.DefaultIssuerGroupAnnotationKey: "cert-manager.io",
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		namespace *corev1.Namespace
+		issuerRef cmmeta.ObjectReference
+		expected  cmmeta.ObjectReference
+	}{
+		"should apply the default Issuer if issuerRef is empty": {
+			namespace: annotatedNamespace,
+			expected: cmmeta.ObjectReference{
+				Name:  "default-issuer",
+				Kind:  "ClusterIssuer",
+				Group: "cert-manager.io",
+			},
+		},
+		"should apply only the name if the kind and group annotations are absent": {
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "testns",
+					Annotations: map[string]string{
+						cmapiv1.DefaultIssuerNameAnnotationKey: "default-issuer",
+					},
+				},
+			},
+			expected: cmmeta.ObjectReference{Name: "default-issuer"},
+		},
+		"should not override an explicit issuerRef": {
+			namespace: annotatedNamespace,
+			issuerRef: cmmeta.ObjectReference{Name: "explicit", Kind: "Issuer"},
+			expected:  cmmeta.ObjectReference{Name: "explicit", Kind: "Issuer"},
+		},
+		"should not override a partially set issuerRef": {
+			namespace: annotatedNamespace,
+			issuerRef: cmmeta.ObjectReference{Kind: "Issuer"},
+			expected:  cmmeta.ObjectReference{Kind: "Issuer"},
+		},
+		"should not set issuerRef if the Namespace has no default Issuer annotation": {
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "testns"}},
+			expected:  cmmeta.ObjectReference{},
+		},
+		"should not set issuerRef if the Namespace cannot be retrieved": {
+			expected: cmmeta.ObjectReference{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if test.namespace != nil {
+				if err := indexer.Add(test.namespace); err != nil {
+					t.Fatal(err)
+				}
+			}
+			m := newCertificateMutator(Options{NamespaceLister: corelisters.NewNamespaceLister(indexer)})
+
+			crt := &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec:       cmapi.CertificateSpec{IssuerRef: test.issuerRef},
+			}
+			m.Mutate(context.TODO(), &admissionv1.AdmissionRequest{Namespace: "testns"}, crt)
+			if crt.Spec.IssuerRef != test.expected {
+				t.Errorf("unexpected issuerRef, exp=%+v got=%+v", test.expected, crt.Spec.IssuerRef)
+			}
+		})
+	}
+}
+
+func TestMutateCertificateDNSNames(t *testing.T) {
+	tests := map[string]struct {
+		operation    admissionv1.Operation
+		preserveCase bool
+		dnsNames     []string
+		expected     []string
+	}{
+		"should lower case dnsNames": {
+			operation: admissionv1.Create,
+			dnsNames:  []string{"Example.COM", "*.Foo.example.com", "bar.example.com"},
+			expected:  []string{"example.com", "*.foo.example.com", "bar.example.com"},
+		},
+		"should not set dnsNames if none are specified": {
+			operation: admissionv1.Create,
+			dnsNames:  nil,
+			expected:  nil,
+		},
+		"should preserve the case of dnsNames if lower casing is disabled": {
+			operation:    admissionv1.Create,
+			preserveCase: true,
+			dnsNames:     []string{"Example.COM", "*.Foo.example.com"},
+			expected:     []string{"Example.COM", "*.Foo.example.com"},
+		},
+		"should preserve the case of dnsNames when a Certificate is updated": {
+			operation: admissionv1.Update,
+			dnsNames:  []string{"Example.COM", "*.Foo.example.com"},
+			expected:  []string{"Example.COM", "*.Foo.example.com"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newCertificateMutator(Options{LowercaseCertificateDNSNames: !test.preserveCase})
+			crt := &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec:       cmapi.CertificateSpec{DNSNames: test.dnsNames},
+			}
+			m.Mutate(context.TODO(), &admissionv1.AdmissionRequest{Operation: test.operation}, crt)
+			if !reflect.DeepEqual(crt.Spec.DNSNames, test.expected) {
+				t.Errorf("unexpected dnsNames, exp=%q got=%q", test.expected, crt.Spec.DNSNames)
+			}
+		})
+	}
+}
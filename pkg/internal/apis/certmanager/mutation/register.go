@@ -0,0 +1,31 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mutation contains the mutation functions applied by the webhook to
+// cert-manager API types.
+package mutation
+
+import (
+	"github.com/jetstack/cert-manager/pkg/internal/api/mutation"
+	cmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
+)
+
+func AddToMutationRegistry(reg *mutation.Registry, opts Options) error {
+	if err := reg.AddMutateFunc(&cmapi.Certificate{}, newCertificateMutator(opts).Mutate); err != nil {
+		return err
+	}
+	return nil
+}
@@ -73,6 +73,11 @@ type CertificateRequestSpec struct {
 	// This option may be ignored/overridden by some issuer types.
 	Duration *metav1.Duration
 
+	// The requested 'notBefore' time of the Certificate. If unset this
+	// defaults to the time of issuance.
+	// This option may be ignored/overridden by some issuer types.
+	NotBefore *metav1.Time
+
 	// IssuerRef is a reference to the issuer for this CertificateRequest.  If
 	// the `kind` field is not set, or set to `Issuer`, an Issuer resource with
 	// the given name in the same namespace as the CertificateRequest will be
@@ -91,6 +96,17 @@ type CertificateRequestSpec struct {
 	// This will automatically add the `cert sign` usage to the list of `usages`.
 	IsCA bool
 
+	// MaxPathLen is the maximum number of intermediate CA certificates that
+	// may follow the requested certificate in a valid certification path. It
+	// may only be set if isCA is true. If not set, no path length constraint
+	// will be requested.
+	MaxPathLen *int32
+
+	// SignatureAlgorithm is the signature algorithm the issuer should use to
+	// sign the requested certificate. It must be compatible with the issuer's
+	// private key. If not set, the issuer chooses the signature algorithm.
+	SignatureAlgorithm SignatureAlgorithm
+
 	// Usages is the set of x509 usages that are requested for the certificate.
 	// Defaults to `digital signature` and `key encipherment` if not specified.
 	Usages []KeyUsage
@@ -63,6 +63,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificateAdditionalOutputFormat)(nil), (*certmanager.CertificateAdditionalOutputFormat)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(a.(*v1alpha2.CertificateAdditionalOutputFormat), b.(*certmanager.CertificateAdditionalOutputFormat), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateAdditionalOutputFormat)(nil), (*v1alpha2.CertificateAdditionalOutputFormat)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateAdditionalOutputFormat_To_v1alpha2_CertificateAdditionalOutputFormat(a.(*certmanager.CertificateAdditionalOutputFormat), b.(*v1alpha2.CertificateAdditionalOutputFormat), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificateCondition)(nil), (*certmanager.CertificateCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_CertificateCondition_To_certmanager_CertificateCondition(a.(*v1alpha2.CertificateCondition), b.(*certmanager.CertificateCondition), scope)
 	}); err != nil {
@@ -93,11 +103,41 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificatePolicy)(nil), (*certmanager.CertificatePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CertificatePolicy_To_certmanager_CertificatePolicy(a.(*v1alpha2.CertificatePolicy), b.(*certmanager.CertificatePolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificatePolicy)(nil), (*v1alpha2.CertificatePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificatePolicy_To_v1alpha2_CertificatePolicy(a.(*certmanager.CertificatePolicy), b.(*v1alpha2.CertificatePolicy), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificatePrivateKey)(nil), (*certmanager.CertificatePrivateKey)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_CertificatePrivateKey_To_certmanager_CertificatePrivateKey(a.(*v1alpha2.CertificatePrivateKey), b.(*certmanager.CertificatePrivateKey), scope)
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificateReconciliationRecord)(nil), (*certmanager.CertificateReconciliationRecord)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(a.(*v1alpha2.CertificateReconciliationRecord), b.(*certmanager.CertificateReconciliationRecord), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateReconciliationRecord)(nil), (*v1alpha2.CertificateReconciliationRecord)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateReconciliationRecord_To_v1alpha2_CertificateReconciliationRecord(a.(*certmanager.CertificateReconciliationRecord), b.(*v1alpha2.CertificateReconciliationRecord), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificateRenewalWindow)(nil), (*certmanager.CertificateRenewalWindow)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(a.(*v1alpha2.CertificateRenewalWindow), b.(*certmanager.CertificateRenewalWindow), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateRenewalWindow)(nil), (*v1alpha2.CertificateRenewalWindow)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateRenewalWindow_To_v1alpha2_CertificateRenewalWindow(a.(*certmanager.CertificateRenewalWindow), b.(*v1alpha2.CertificateRenewalWindow), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificateRequest)(nil), (*certmanager.CertificateRequest)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_CertificateRequest_To_certmanager_CertificateRequest(a.(*v1alpha2.CertificateRequest), b.(*certmanager.CertificateRequest), scope)
 	}); err != nil {
@@ -138,6 +178,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificateSecretKeys)(nil), (*certmanager.CertificateSecretKeys)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(a.(*v1alpha2.CertificateSecretKeys), b.(*certmanager.CertificateSecretKeys), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateSecretKeys)(nil), (*v1alpha2.CertificateSecretKeys)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateSecretKeys_To_v1alpha2_CertificateSecretKeys(a.(*certmanager.CertificateSecretKeys), b.(*v1alpha2.CertificateSecretKeys), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha2.CertificateStatus)(nil), (*certmanager.CertificateStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_CertificateStatus_To_certmanager_CertificateStatus(a.(*v1alpha2.CertificateStatus), b.(*certmanager.CertificateStatus), scope)
 	}); err != nil {
@@ -168,6 +218,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.CustomExtensionPolicy)(nil), (*certmanager.CustomExtensionPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(a.(*v1alpha2.CustomExtensionPolicy), b.(*certmanager.CustomExtensionPolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CustomExtensionPolicy)(nil), (*v1alpha2.CustomExtensionPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CustomExtensionPolicy_To_v1alpha2_CustomExtensionPolicy(a.(*certmanager.CustomExtensionPolicy), b.(*v1alpha2.CustomExtensionPolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.IssuedCertificateSummary)(nil), (*certmanager.IssuedCertificateSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(a.(*v1alpha2.IssuedCertificateSummary), b.(*certmanager.IssuedCertificateSummary), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.IssuedCertificateSummary)(nil), (*v1alpha2.IssuedCertificateSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_IssuedCertificateSummary_To_v1alpha2_IssuedCertificateSummary(a.(*certmanager.IssuedCertificateSummary), b.(*v1alpha2.IssuedCertificateSummary), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha2.Issuer)(nil), (*certmanager.Issuer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_Issuer_To_certmanager_Issuer(a.(*v1alpha2.Issuer), b.(*certmanager.Issuer), scope)
 	}); err != nil {
@@ -238,6 +308,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.OtherName)(nil), (*certmanager.OtherName)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OtherName_To_certmanager_OtherName(a.(*v1alpha2.OtherName), b.(*certmanager.OtherName), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.OtherName)(nil), (*v1alpha2.OtherName)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_OtherName_To_v1alpha2_OtherName(a.(*certmanager.OtherName), b.(*v1alpha2.OtherName), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha2.PKCS12Keystore)(nil), (*certmanager.PKCS12Keystore)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_PKCS12Keystore_To_certmanager_PKCS12Keystore(a.(*v1alpha2.PKCS12Keystore), b.(*certmanager.PKCS12Keystore), scope)
 	}); err != nil {
@@ -248,6 +328,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.PolicyConstraints)(nil), (*certmanager.PolicyConstraints)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_PolicyConstraints_To_certmanager_PolicyConstraints(a.(*v1alpha2.PolicyConstraints), b.(*certmanager.PolicyConstraints), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.PolicyConstraints)(nil), (*v1alpha2.PolicyConstraints)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_PolicyConstraints_To_v1alpha2_PolicyConstraints(a.(*certmanager.PolicyConstraints), b.(*v1alpha2.PolicyConstraints), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1alpha2.SPIFFEOptions)(nil), (*certmanager.SPIFFEOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_SPIFFEOptions_To_certmanager_SPIFFEOptions(a.(*v1alpha2.SPIFFEOptions), b.(*certmanager.SPIFFEOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.SPIFFEOptions)(nil), (*v1alpha2.SPIFFEOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_SPIFFEOptions_To_v1alpha2_SPIFFEOptions(a.(*certmanager.SPIFFEOptions), b.(*v1alpha2.SPIFFEOptions), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1alpha2.SelfSignedIssuer)(nil), (*certmanager.SelfSignedIssuer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(a.(*v1alpha2.SelfSignedIssuer), b.(*certmanager.SelfSignedIssuer), scope)
 	}); err != nil {
@@ -370,6 +470,15 @@ func autoConvert_v1alpha2_CAIssuer_To_certmanager_CAIssuer(in *v1alpha2.CAIssuer
 	out.SecretName = in.SecretName
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
 	out.OCSPServers = *(*[]string)(unsafe.Pointer(&in.OCSPServers))
+	out.PolicyIdentifiers = *(*[]certmanager.CertificatePolicy)(unsafe.Pointer(&in.PolicyIdentifiers))
+	out.InhibitAnyPolicy = (*int32)(unsafe.Pointer(in.InhibitAnyPolicy))
+	out.PolicyConstraints = (*certmanager.PolicyConstraints)(unsafe.Pointer(in.PolicyConstraints))
+	out.AllowedCustomExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
+	out.PreferredChain = in.PreferredChain
+	out.SubjectKeyIdentifierMethod = certmanager.SubjectKeyIdentifierMethod(in.SubjectKeyIdentifierMethod)
+	out.LegacyCommonNameOnly = in.LegacyCommonNameOnly
+	out.RotationSecretNames = *(*[]string)(unsafe.Pointer(&in.RotationSecretNames))
 	return nil
 }
 
@@ -382,6 +491,15 @@ func autoConvert_certmanager_CAIssuer_To_v1alpha2_CAIssuer(in *certmanager.CAIss
 	out.SecretName = in.SecretName
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
 	out.OCSPServers = *(*[]string)(unsafe.Pointer(&in.OCSPServers))
+	out.PolicyIdentifiers = *(*[]v1alpha2.CertificatePolicy)(unsafe.Pointer(&in.PolicyIdentifiers))
+	out.InhibitAnyPolicy = (*int32)(unsafe.Pointer(in.InhibitAnyPolicy))
+	out.PolicyConstraints = (*v1alpha2.PolicyConstraints)(unsafe.Pointer(in.PolicyConstraints))
+	out.AllowedCustomExtensions = *(*[]v1alpha2.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]v1alpha2.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
+	out.PreferredChain = in.PreferredChain
+	out.SubjectKeyIdentifierMethod = v1alpha2.SubjectKeyIdentifierMethod(in.SubjectKeyIdentifierMethod)
+	out.LegacyCommonNameOnly = in.LegacyCommonNameOnly
+	out.RotationSecretNames = *(*[]string)(unsafe.Pointer(&in.RotationSecretNames))
 	return nil
 }
 
@@ -422,6 +540,26 @@ func Convert_certmanager_Certificate_To_v1alpha2_Certificate(in *certmanager.Cer
 	return autoConvert_certmanager_Certificate_To_v1alpha2_Certificate(in, out, s)
 }
 
+func autoConvert_v1alpha2_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(in *v1alpha2.CertificateAdditionalOutputFormat, out *certmanager.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	out.Type = certmanager.CertificateOutputFormatType(in.Type)
+	return nil
+}
+
+// Convert_v1alpha2_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat is an autogenerated conversion function.
+func Convert_v1alpha2_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(in *v1alpha2.CertificateAdditionalOutputFormat, out *certmanager.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateAdditionalOutputFormat_To_v1alpha2_CertificateAdditionalOutputFormat(in *certmanager.CertificateAdditionalOutputFormat, out *v1alpha2.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	out.Type = v1alpha2.CertificateOutputFormatType(in.Type)
+	return nil
+}
+
+// Convert_certmanager_CertificateAdditionalOutputFormat_To_v1alpha2_CertificateAdditionalOutputFormat is an autogenerated conversion function.
+func Convert_certmanager_CertificateAdditionalOutputFormat_To_v1alpha2_CertificateAdditionalOutputFormat(in *certmanager.CertificateAdditionalOutputFormat, out *v1alpha2.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateAdditionalOutputFormat_To_v1alpha2_CertificateAdditionalOutputFormat(in, out, s)
+}
+
 func autoConvert_v1alpha2_CertificateCondition_To_certmanager_CertificateCondition(in *v1alpha2.CertificateCondition, out *certmanager.CertificateCondition, s conversion.Scope) error {
 	out.Type = certmanager.CertificateConditionType(in.Type)
 	out.Status = meta.ConditionStatus(in.Status)
@@ -548,6 +686,28 @@ func Convert_certmanager_CertificateList_To_v1alpha2_CertificateList(in *certman
 	return autoConvert_certmanager_CertificateList_To_v1alpha2_CertificateList(in, out, s)
 }
 
+func autoConvert_v1alpha2_CertificatePolicy_To_certmanager_CertificatePolicy(in *v1alpha2.CertificatePolicy, out *certmanager.CertificatePolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.CPSURIs = *(*[]string)(unsafe.Pointer(&in.CPSURIs))
+	return nil
+}
+
+// Convert_v1alpha2_CertificatePolicy_To_certmanager_CertificatePolicy is an autogenerated conversion function.
+func Convert_v1alpha2_CertificatePolicy_To_certmanager_CertificatePolicy(in *v1alpha2.CertificatePolicy, out *certmanager.CertificatePolicy, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CertificatePolicy_To_certmanager_CertificatePolicy(in, out, s)
+}
+
+func autoConvert_certmanager_CertificatePolicy_To_v1alpha2_CertificatePolicy(in *certmanager.CertificatePolicy, out *v1alpha2.CertificatePolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.CPSURIs = *(*[]string)(unsafe.Pointer(&in.CPSURIs))
+	return nil
+}
+
+// Convert_certmanager_CertificatePolicy_To_v1alpha2_CertificatePolicy is an autogenerated conversion function.
+func Convert_certmanager_CertificatePolicy_To_v1alpha2_CertificatePolicy(in *certmanager.CertificatePolicy, out *v1alpha2.CertificatePolicy, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificatePolicy_To_v1alpha2_CertificatePolicy(in, out, s)
+}
+
 func autoConvert_v1alpha2_CertificatePrivateKey_To_certmanager_CertificatePrivateKey(in *v1alpha2.CertificatePrivateKey, out *certmanager.CertificatePrivateKey, s conversion.Scope) error {
 	out.RotationPolicy = certmanager.PrivateKeyRotationPolicy(in.RotationPolicy)
 	return nil
@@ -566,6 +726,50 @@ func autoConvert_certmanager_CertificatePrivateKey_To_v1alpha2_CertificatePrivat
 	return nil
 }
 
+func autoConvert_v1alpha2_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(in *v1alpha2.CertificateReconciliationRecord, out *certmanager.CertificateReconciliationRecord, s conversion.Scope) error {
+	out.Controller = in.Controller
+	out.Time = in.Time
+	return nil
+}
+
+// Convert_v1alpha2_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord is an autogenerated conversion function.
+func Convert_v1alpha2_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(in *v1alpha2.CertificateReconciliationRecord, out *certmanager.CertificateReconciliationRecord, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateReconciliationRecord_To_v1alpha2_CertificateReconciliationRecord(in *certmanager.CertificateReconciliationRecord, out *v1alpha2.CertificateReconciliationRecord, s conversion.Scope) error {
+	out.Controller = in.Controller
+	out.Time = in.Time
+	return nil
+}
+
+// Convert_certmanager_CertificateReconciliationRecord_To_v1alpha2_CertificateReconciliationRecord is an autogenerated conversion function.
+func Convert_certmanager_CertificateReconciliationRecord_To_v1alpha2_CertificateReconciliationRecord(in *certmanager.CertificateReconciliationRecord, out *v1alpha2.CertificateReconciliationRecord, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateReconciliationRecord_To_v1alpha2_CertificateReconciliationRecord(in, out, s)
+}
+
+func autoConvert_v1alpha2_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(in *v1alpha2.CertificateRenewalWindow, out *certmanager.CertificateRenewalWindow, s conversion.Scope) error {
+	out.Ranges = *(*[]string)(unsafe.Pointer(&in.Ranges))
+	out.TimeZone = in.TimeZone
+	return nil
+}
+
+// Convert_v1alpha2_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow is an autogenerated conversion function.
+func Convert_v1alpha2_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(in *v1alpha2.CertificateRenewalWindow, out *certmanager.CertificateRenewalWindow, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateRenewalWindow_To_v1alpha2_CertificateRenewalWindow(in *certmanager.CertificateRenewalWindow, out *v1alpha2.CertificateRenewalWindow, s conversion.Scope) error {
+	out.Ranges = *(*[]string)(unsafe.Pointer(&in.Ranges))
+	out.TimeZone = in.TimeZone
+	return nil
+}
+
+// Convert_certmanager_CertificateRenewalWindow_To_v1alpha2_CertificateRenewalWindow is an autogenerated conversion function.
+func Convert_certmanager_CertificateRenewalWindow_To_v1alpha2_CertificateRenewalWindow(in *certmanager.CertificateRenewalWindow, out *v1alpha2.CertificateRenewalWindow, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateRenewalWindow_To_v1alpha2_CertificateRenewalWindow(in, out, s)
+}
+
 func autoConvert_v1alpha2_CertificateRequest_To_certmanager_CertificateRequest(in *v1alpha2.CertificateRequest, out *certmanager.CertificateRequest, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha2_CertificateRequestSpec_To_certmanager_CertificateRequestSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -670,11 +874,14 @@ func Convert_certmanager_CertificateRequestList_To_v1alpha2_CertificateRequestLi
 
 func autoConvert_v1alpha2_CertificateRequestSpec_To_certmanager_CertificateRequestSpec(in *v1alpha2.CertificateRequestSpec, out *certmanager.CertificateRequestSpec, s conversion.Scope) error {
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
 	// WARNING: in.CSRPEM requires manual conversion: does not exist in peer-type
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
+	out.SignatureAlgorithm = certmanager.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.Usages = *(*[]certmanager.KeyUsage)(unsafe.Pointer(&in.Usages))
 	out.Username = in.Username
 	out.UID = in.UID
@@ -685,11 +892,14 @@ func autoConvert_v1alpha2_CertificateRequestSpec_To_certmanager_CertificateReque
 
 func autoConvert_certmanager_CertificateRequestSpec_To_v1alpha2_CertificateRequestSpec(in *certmanager.CertificateRequestSpec, out *v1alpha2.CertificateRequestSpec, s conversion.Scope) error {
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
 	// WARNING: in.Request requires manual conversion: does not exist in peer-type
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
+	out.SignatureAlgorithm = v1alpha2.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.Usages = *(*[]v1alpha2.KeyUsage)(unsafe.Pointer(&in.Usages))
 	out.Username = in.Username
 	out.UID = in.UID
@@ -724,6 +934,30 @@ func Convert_certmanager_CertificateRequestStatus_To_v1alpha2_CertificateRequest
 	return autoConvert_certmanager_CertificateRequestStatus_To_v1alpha2_CertificateRequestStatus(in, out, s)
 }
 
+func autoConvert_v1alpha2_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(in *v1alpha2.CertificateSecretKeys, out *certmanager.CertificateSecretKeys, s conversion.Scope) error {
+	out.PrivateKey = in.PrivateKey
+	out.Certificate = in.Certificate
+	out.CA = in.CA
+	return nil
+}
+
+// Convert_v1alpha2_CertificateSecretKeys_To_certmanager_CertificateSecretKeys is an autogenerated conversion function.
+func Convert_v1alpha2_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(in *v1alpha2.CertificateSecretKeys, out *certmanager.CertificateSecretKeys, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateSecretKeys_To_v1alpha2_CertificateSecretKeys(in *certmanager.CertificateSecretKeys, out *v1alpha2.CertificateSecretKeys, s conversion.Scope) error {
+	out.PrivateKey = in.PrivateKey
+	out.Certificate = in.Certificate
+	out.CA = in.CA
+	return nil
+}
+
+// Convert_certmanager_CertificateSecretKeys_To_v1alpha2_CertificateSecretKeys is an autogenerated conversion function.
+func Convert_certmanager_CertificateSecretKeys_To_v1alpha2_CertificateSecretKeys(in *certmanager.CertificateSecretKeys, out *v1alpha2.CertificateSecretKeys, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateSecretKeys_To_v1alpha2_CertificateSecretKeys(in, out, s)
+}
+
 func autoConvert_v1alpha2_CertificateSpec_To_certmanager_CertificateSpec(in *v1alpha2.CertificateSpec, out *certmanager.CertificateSpec, s conversion.Scope) error {
 	if in.Subject != nil {
 		in, out := &in.Subject, &out.Subject
@@ -735,14 +969,30 @@ func autoConvert_v1alpha2_CertificateSpec_To_certmanager_CertificateSpec(in *v1a
 		out.Subject = nil
 	}
 	out.CommonName = in.CommonName
+	out.ExcludeCommonNameFromSANs = in.ExcludeCommonNameFromSANs
 	// WARNING: in.Organization requires manual conversion: does not exist in peer-type
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.IssuanceTimeout = (*v1.Duration)(unsafe.Pointer(in.IssuanceTimeout))
+	out.RenewalWindow = (*certmanager.CertificateRenewalWindow)(unsafe.Pointer(in.RenewalWindow))
+	if in.TrustAnchors != nil {
+		in, out := &in.TrustAnchors, &out.TrustAnchors
+		*out = new(meta.SecretKeySelector)
+		if err := apismetav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TrustAnchors = nil
+	}
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
 	out.EmailSANs = *(*[]string)(unsafe.Pointer(&in.EmailSANs))
+	out.OtherNames = *(*[]certmanager.OtherName)(unsafe.Pointer(&in.OtherNames))
 	out.SecretName = in.SecretName
+	out.SecretType = in.SecretType
+	out.SecretKeys = (*certmanager.CertificateSecretKeys)(unsafe.Pointer(in.SecretKeys))
 	if in.Keystores != nil {
 		in, out := &in.Keystores, &out.Keystores
 		*out = new(certmanager.CertificateKeystores)
@@ -752,10 +1002,23 @@ func autoConvert_v1alpha2_CertificateSpec_To_certmanager_CertificateSpec(in *v1a
 	} else {
 		out.Keystores = nil
 	}
+	out.AdditionalOutputFormats = *(*[]certmanager.CertificateAdditionalOutputFormat)(unsafe.Pointer(&in.AdditionalOutputFormats))
 	if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
+	if in.IssuerRefs != nil {
+		in, out := &in.IssuerRefs, &out.IssuerRefs
+		*out = make([]meta.ObjectReference, len(*in))
+		for i := range *in {
+			if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.IssuerRefs = nil
+	}
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
 	out.Usages = *(*[]certmanager.KeyUsage)(unsafe.Pointer(&in.Usages))
 	// WARNING: in.KeySize requires manual conversion: does not exist in peer-type
 	// WARNING: in.KeyAlgorithm requires manual conversion: does not exist in peer-type
@@ -769,7 +1032,18 @@ func autoConvert_v1alpha2_CertificateSpec_To_certmanager_CertificateSpec(in *v1a
 	} else {
 		out.PrivateKey = nil
 	}
+	if in.CSRSecretRef != nil {
+		in, out := &in.CSRSecretRef, &out.CSRSecretRef
+		*out = new(meta.SecretKeySelector)
+		if err := apismetav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CSRSecretRef = nil
+	}
 	out.EncodeUsagesInRequest = (*bool)(unsafe.Pointer(in.EncodeUsagesInRequest))
+	out.CSRSignatureAlgorithm = certmanager.SignatureAlgorithm(in.CSRSignatureAlgorithm)
+	out.SignatureAlgorithm = certmanager.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.RevisionHistoryLimit = (*int32)(unsafe.Pointer(in.RevisionHistoryLimit))
 	return nil
 }
@@ -785,13 +1059,29 @@ func autoConvert_certmanager_CertificateSpec_To_v1alpha2_CertificateSpec(in *cer
 		out.Subject = nil
 	}
 	out.CommonName = in.CommonName
+	out.ExcludeCommonNameFromSANs = in.ExcludeCommonNameFromSANs
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.IssuanceTimeout = (*v1.Duration)(unsafe.Pointer(in.IssuanceTimeout))
+	out.RenewalWindow = (*v1alpha2.CertificateRenewalWindow)(unsafe.Pointer(in.RenewalWindow))
+	if in.TrustAnchors != nil {
+		in, out := &in.TrustAnchors, &out.TrustAnchors
+		*out = new(metav1.SecretKeySelector)
+		if err := apismetav1.Convert_meta_SecretKeySelector_To_v1_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TrustAnchors = nil
+	}
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
 	out.EmailSANs = *(*[]string)(unsafe.Pointer(&in.EmailSANs))
+	out.OtherNames = *(*[]v1alpha2.OtherName)(unsafe.Pointer(&in.OtherNames))
 	out.SecretName = in.SecretName
+	out.SecretType = in.SecretType
+	out.SecretKeys = (*v1alpha2.CertificateSecretKeys)(unsafe.Pointer(in.SecretKeys))
 	if in.Keystores != nil {
 		in, out := &in.Keystores, &out.Keystores
 		*out = new(v1alpha2.CertificateKeystores)
@@ -801,10 +1091,23 @@ func autoConvert_certmanager_CertificateSpec_To_v1alpha2_CertificateSpec(in *cer
 	} else {
 		out.Keystores = nil
 	}
+	out.AdditionalOutputFormats = *(*[]v1alpha2.CertificateAdditionalOutputFormat)(unsafe.Pointer(&in.AdditionalOutputFormats))
 	if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
+	if in.IssuerRefs != nil {
+		in, out := &in.IssuerRefs, &out.IssuerRefs
+		*out = make([]metav1.ObjectReference, len(*in))
+		for i := range *in {
+			if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.IssuerRefs = nil
+	}
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
 	out.Usages = *(*[]v1alpha2.KeyUsage)(unsafe.Pointer(&in.Usages))
 	if in.PrivateKey != nil {
 		in, out := &in.PrivateKey, &out.PrivateKey
@@ -815,7 +1118,18 @@ func autoConvert_certmanager_CertificateSpec_To_v1alpha2_CertificateSpec(in *cer
 	} else {
 		out.PrivateKey = nil
 	}
+	if in.CSRSecretRef != nil {
+		in, out := &in.CSRSecretRef, &out.CSRSecretRef
+		*out = new(metav1.SecretKeySelector)
+		if err := apismetav1.Convert_meta_SecretKeySelector_To_v1_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CSRSecretRef = nil
+	}
 	out.EncodeUsagesInRequest = (*bool)(unsafe.Pointer(in.EncodeUsagesInRequest))
+	out.CSRSignatureAlgorithm = v1alpha2.SignatureAlgorithm(in.CSRSignatureAlgorithm)
+	out.SignatureAlgorithm = v1alpha2.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.RevisionHistoryLimit = (*int32)(unsafe.Pointer(in.RevisionHistoryLimit))
 	return nil
 }
@@ -828,6 +1142,18 @@ func autoConvert_v1alpha2_CertificateStatus_To_certmanager_CertificateStatus(in
 	out.RenewalTime = (*v1.Time)(unsafe.Pointer(in.RenewalTime))
 	out.Revision = (*int)(unsafe.Pointer(in.Revision))
 	out.NextPrivateKeySecretName = (*string)(unsafe.Pointer(in.NextPrivateKeySecretName))
+	out.IssuanceLatency = (*v1.Duration)(unsafe.Pointer(in.IssuanceLatency))
+	if in.ActiveIssuerRef != nil {
+		in, out := &in.ActiveIssuerRef, &out.ActiveIssuerRef
+		*out = new(meta.ObjectReference)
+		if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ActiveIssuerRef = nil
+	}
+	out.LastReconciledBy = *(*[]certmanager.CertificateReconciliationRecord)(unsafe.Pointer(&in.LastReconciledBy))
+	out.IssuedCertificate = (*certmanager.IssuedCertificateSummary)(unsafe.Pointer(in.IssuedCertificate))
 	return nil
 }
 
@@ -844,6 +1170,18 @@ func autoConvert_certmanager_CertificateStatus_To_v1alpha2_CertificateStatus(in
 	out.RenewalTime = (*v1.Time)(unsafe.Pointer(in.RenewalTime))
 	out.Revision = (*int)(unsafe.Pointer(in.Revision))
 	out.NextPrivateKeySecretName = (*string)(unsafe.Pointer(in.NextPrivateKeySecretName))
+	out.IssuanceLatency = (*v1.Duration)(unsafe.Pointer(in.IssuanceLatency))
+	if in.ActiveIssuerRef != nil {
+		in, out := &in.ActiveIssuerRef, &out.ActiveIssuerRef
+		*out = new(metav1.ObjectReference)
+		if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ActiveIssuerRef = nil
+	}
+	out.LastReconciledBy = *(*[]v1alpha2.CertificateReconciliationRecord)(unsafe.Pointer(&in.LastReconciledBy))
+	out.IssuedCertificate = (*v1alpha2.IssuedCertificateSummary)(unsafe.Pointer(in.IssuedCertificate))
 	return nil
 }
 
@@ -926,6 +1264,62 @@ func Convert_certmanager_ClusterIssuerList_To_v1alpha2_ClusterIssuerList(in *cer
 	return autoConvert_certmanager_ClusterIssuerList_To_v1alpha2_ClusterIssuerList(in, out, s)
 }
 
+func autoConvert_v1alpha2_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(in *v1alpha2.CustomExtensionPolicy, out *certmanager.CustomExtensionPolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.AllowCritical = in.AllowCritical
+	return nil
+}
+
+// Convert_v1alpha2_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy is an autogenerated conversion function.
+func Convert_v1alpha2_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(in *v1alpha2.CustomExtensionPolicy, out *certmanager.CustomExtensionPolicy, s conversion.Scope) error {
+	return autoConvert_v1alpha2_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(in, out, s)
+}
+
+func autoConvert_certmanager_CustomExtensionPolicy_To_v1alpha2_CustomExtensionPolicy(in *certmanager.CustomExtensionPolicy, out *v1alpha2.CustomExtensionPolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.AllowCritical = in.AllowCritical
+	return nil
+}
+
+// Convert_certmanager_CustomExtensionPolicy_To_v1alpha2_CustomExtensionPolicy is an autogenerated conversion function.
+func Convert_certmanager_CustomExtensionPolicy_To_v1alpha2_CustomExtensionPolicy(in *certmanager.CustomExtensionPolicy, out *v1alpha2.CustomExtensionPolicy, s conversion.Scope) error {
+	return autoConvert_certmanager_CustomExtensionPolicy_To_v1alpha2_CustomExtensionPolicy(in, out, s)
+}
+
+func autoConvert_v1alpha2_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(in *v1alpha2.IssuedCertificateSummary, out *certmanager.IssuedCertificateSummary, s conversion.Scope) error {
+	out.SerialNumber = in.SerialNumber
+	out.Subject = in.Subject
+	out.IssuerCommonName = in.IssuerCommonName
+	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
+	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
+	out.URIs = *(*[]string)(unsafe.Pointer(&in.URIs))
+	out.EmailAddresses = *(*[]string)(unsafe.Pointer(&in.EmailAddresses))
+	out.Truncated = in.Truncated
+	return nil
+}
+
+// Convert_v1alpha2_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary is an autogenerated conversion function.
+func Convert_v1alpha2_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(in *v1alpha2.IssuedCertificateSummary, out *certmanager.IssuedCertificateSummary, s conversion.Scope) error {
+	return autoConvert_v1alpha2_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(in, out, s)
+}
+
+func autoConvert_certmanager_IssuedCertificateSummary_To_v1alpha2_IssuedCertificateSummary(in *certmanager.IssuedCertificateSummary, out *v1alpha2.IssuedCertificateSummary, s conversion.Scope) error {
+	out.SerialNumber = in.SerialNumber
+	out.Subject = in.Subject
+	out.IssuerCommonName = in.IssuerCommonName
+	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
+	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
+	out.URIs = *(*[]string)(unsafe.Pointer(&in.URIs))
+	out.EmailAddresses = *(*[]string)(unsafe.Pointer(&in.EmailAddresses))
+	out.Truncated = in.Truncated
+	return nil
+}
+
+// Convert_certmanager_IssuedCertificateSummary_To_v1alpha2_IssuedCertificateSummary is an autogenerated conversion function.
+func Convert_certmanager_IssuedCertificateSummary_To_v1alpha2_IssuedCertificateSummary(in *certmanager.IssuedCertificateSummary, out *v1alpha2.IssuedCertificateSummary, s conversion.Scope) error {
+	return autoConvert_certmanager_IssuedCertificateSummary_To_v1alpha2_IssuedCertificateSummary(in, out, s)
+}
+
 func autoConvert_v1alpha2_Issuer_To_certmanager_Issuer(in *v1alpha2.Issuer, out *certmanager.Issuer, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1alpha2_IssuerSpec_To_certmanager_IssuerSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1110,6 +1504,8 @@ func autoConvert_v1alpha2_IssuerSpec_To_certmanager_IssuerSpec(in *v1alpha2.Issu
 	if err := Convert_v1alpha2_IssuerConfig_To_certmanager_IssuerConfig(&in.IssuerConfig, &out.IssuerConfig, s); err != nil {
 		return err
 	}
+	out.AllowedDomains = *(*[]string)(unsafe.Pointer(&in.AllowedDomains))
+	out.SPIFFE = (*certmanager.SPIFFEOptions)(unsafe.Pointer(in.SPIFFE))
 	return nil
 }
 
@@ -1122,6 +1518,8 @@ func autoConvert_certmanager_IssuerSpec_To_v1alpha2_IssuerSpec(in *certmanager.I
 	if err := Convert_certmanager_IssuerConfig_To_v1alpha2_IssuerConfig(&in.IssuerConfig, &out.IssuerConfig, s); err != nil {
 		return err
 	}
+	out.AllowedDomains = *(*[]string)(unsafe.Pointer(&in.AllowedDomains))
+	out.SPIFFE = (*v1alpha2.SPIFFEOptions)(unsafe.Pointer(in.SPIFFE))
 	return nil
 }
 
@@ -1194,6 +1592,28 @@ func Convert_certmanager_JKSKeystore_To_v1alpha2_JKSKeystore(in *certmanager.JKS
 	return autoConvert_certmanager_JKSKeystore_To_v1alpha2_JKSKeystore(in, out, s)
 }
 
+func autoConvert_v1alpha2_OtherName_To_certmanager_OtherName(in *v1alpha2.OtherName, out *certmanager.OtherName, s conversion.Scope) error {
+	out.OID = in.OID
+	out.UTF8Value = in.UTF8Value
+	return nil
+}
+
+// Convert_v1alpha2_OtherName_To_certmanager_OtherName is an autogenerated conversion function.
+func Convert_v1alpha2_OtherName_To_certmanager_OtherName(in *v1alpha2.OtherName, out *certmanager.OtherName, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OtherName_To_certmanager_OtherName(in, out, s)
+}
+
+func autoConvert_certmanager_OtherName_To_v1alpha2_OtherName(in *certmanager.OtherName, out *v1alpha2.OtherName, s conversion.Scope) error {
+	out.OID = in.OID
+	out.UTF8Value = in.UTF8Value
+	return nil
+}
+
+// Convert_certmanager_OtherName_To_v1alpha2_OtherName is an autogenerated conversion function.
+func Convert_certmanager_OtherName_To_v1alpha2_OtherName(in *certmanager.OtherName, out *v1alpha2.OtherName, s conversion.Scope) error {
+	return autoConvert_certmanager_OtherName_To_v1alpha2_OtherName(in, out, s)
+}
+
 func autoConvert_v1alpha2_PKCS12Keystore_To_certmanager_PKCS12Keystore(in *v1alpha2.PKCS12Keystore, out *certmanager.PKCS12Keystore, s conversion.Scope) error {
 	out.Create = in.Create
 	if err := apismetav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(&in.PasswordSecretRef, &out.PasswordSecretRef, s); err != nil {
@@ -1220,8 +1640,52 @@ func Convert_certmanager_PKCS12Keystore_To_v1alpha2_PKCS12Keystore(in *certmanag
 	return autoConvert_certmanager_PKCS12Keystore_To_v1alpha2_PKCS12Keystore(in, out, s)
 }
 
+func autoConvert_v1alpha2_PolicyConstraints_To_certmanager_PolicyConstraints(in *v1alpha2.PolicyConstraints, out *certmanager.PolicyConstraints, s conversion.Scope) error {
+	out.RequireExplicitPolicy = (*int32)(unsafe.Pointer(in.RequireExplicitPolicy))
+	out.InhibitPolicyMapping = (*int32)(unsafe.Pointer(in.InhibitPolicyMapping))
+	return nil
+}
+
+// Convert_v1alpha2_PolicyConstraints_To_certmanager_PolicyConstraints is an autogenerated conversion function.
+func Convert_v1alpha2_PolicyConstraints_To_certmanager_PolicyConstraints(in *v1alpha2.PolicyConstraints, out *certmanager.PolicyConstraints, s conversion.Scope) error {
+	return autoConvert_v1alpha2_PolicyConstraints_To_certmanager_PolicyConstraints(in, out, s)
+}
+
+func autoConvert_certmanager_PolicyConstraints_To_v1alpha2_PolicyConstraints(in *certmanager.PolicyConstraints, out *v1alpha2.PolicyConstraints, s conversion.Scope) error {
+	out.RequireExplicitPolicy = (*int32)(unsafe.Pointer(in.RequireExplicitPolicy))
+	out.InhibitPolicyMapping = (*int32)(unsafe.Pointer(in.InhibitPolicyMapping))
+	return nil
+}
+
+// Convert_certmanager_PolicyConstraints_To_v1alpha2_PolicyConstraints is an autogenerated conversion function.
+func Convert_certmanager_PolicyConstraints_To_v1alpha2_PolicyConstraints(in *certmanager.PolicyConstraints, out *v1alpha2.PolicyConstraints, s conversion.Scope) error {
+	return autoConvert_certmanager_PolicyConstraints_To_v1alpha2_PolicyConstraints(in, out, s)
+}
+
+func autoConvert_v1alpha2_SPIFFEOptions_To_certmanager_SPIFFEOptions(in *v1alpha2.SPIFFEOptions, out *certmanager.SPIFFEOptions, s conversion.Scope) error {
+	out.TrustDomain = in.TrustDomain
+	return nil
+}
+
+// Convert_v1alpha2_SPIFFEOptions_To_certmanager_SPIFFEOptions is an autogenerated conversion function.
+func Convert_v1alpha2_SPIFFEOptions_To_certmanager_SPIFFEOptions(in *v1alpha2.SPIFFEOptions, out *certmanager.SPIFFEOptions, s conversion.Scope) error {
+	return autoConvert_v1alpha2_SPIFFEOptions_To_certmanager_SPIFFEOptions(in, out, s)
+}
+
+func autoConvert_certmanager_SPIFFEOptions_To_v1alpha2_SPIFFEOptions(in *certmanager.SPIFFEOptions, out *v1alpha2.SPIFFEOptions, s conversion.Scope) error {
+	out.TrustDomain = in.TrustDomain
+	return nil
+}
+
+// Convert_certmanager_SPIFFEOptions_To_v1alpha2_SPIFFEOptions is an autogenerated conversion function.
+func Convert_certmanager_SPIFFEOptions_To_v1alpha2_SPIFFEOptions(in *certmanager.SPIFFEOptions, out *v1alpha2.SPIFFEOptions, s conversion.Scope) error {
+	return autoConvert_certmanager_SPIFFEOptions_To_v1alpha2_SPIFFEOptions(in, out, s)
+}
+
 func autoConvert_v1alpha2_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *v1alpha2.SelfSignedIssuer, out *certmanager.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.AllowedCustomExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
 	return nil
 }
 
@@ -1232,6 +1696,8 @@ func Convert_v1alpha2_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *v1alp
 
 func autoConvert_certmanager_SelfSignedIssuer_To_v1alpha2_SelfSignedIssuer(in *certmanager.SelfSignedIssuer, out *v1alpha2.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.AllowedCustomExtensions = *(*[]v1alpha2.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]v1alpha2.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
 	return nil
 }
 
@@ -63,6 +63,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificateAdditionalOutputFormat)(nil), (*certmanager.CertificateAdditionalOutputFormat)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(a.(*v1beta1.CertificateAdditionalOutputFormat), b.(*certmanager.CertificateAdditionalOutputFormat), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateAdditionalOutputFormat)(nil), (*v1beta1.CertificateAdditionalOutputFormat)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateAdditionalOutputFormat_To_v1beta1_CertificateAdditionalOutputFormat(a.(*certmanager.CertificateAdditionalOutputFormat), b.(*v1beta1.CertificateAdditionalOutputFormat), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificateCondition)(nil), (*certmanager.CertificateCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_CertificateCondition_To_certmanager_CertificateCondition(a.(*v1beta1.CertificateCondition), b.(*certmanager.CertificateCondition), scope)
 	}); err != nil {
@@ -93,6 +103,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificatePolicy)(nil), (*certmanager.CertificatePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CertificatePolicy_To_certmanager_CertificatePolicy(a.(*v1beta1.CertificatePolicy), b.(*certmanager.CertificatePolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificatePolicy)(nil), (*v1beta1.CertificatePolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificatePolicy_To_v1beta1_CertificatePolicy(a.(*certmanager.CertificatePolicy), b.(*v1beta1.CertificatePolicy), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificatePrivateKey)(nil), (*certmanager.CertificatePrivateKey)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_CertificatePrivateKey_To_certmanager_CertificatePrivateKey(a.(*v1beta1.CertificatePrivateKey), b.(*certmanager.CertificatePrivateKey), scope)
 	}); err != nil {
@@ -103,6 +123,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificateReconciliationRecord)(nil), (*certmanager.CertificateReconciliationRecord)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(a.(*v1beta1.CertificateReconciliationRecord), b.(*certmanager.CertificateReconciliationRecord), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateReconciliationRecord)(nil), (*v1beta1.CertificateReconciliationRecord)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateReconciliationRecord_To_v1beta1_CertificateReconciliationRecord(a.(*certmanager.CertificateReconciliationRecord), b.(*v1beta1.CertificateReconciliationRecord), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificateRenewalWindow)(nil), (*certmanager.CertificateRenewalWindow)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(a.(*v1beta1.CertificateRenewalWindow), b.(*certmanager.CertificateRenewalWindow), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateRenewalWindow)(nil), (*v1beta1.CertificateRenewalWindow)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateRenewalWindow_To_v1beta1_CertificateRenewalWindow(a.(*certmanager.CertificateRenewalWindow), b.(*v1beta1.CertificateRenewalWindow), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificateRequest)(nil), (*certmanager.CertificateRequest)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_CertificateRequest_To_certmanager_CertificateRequest(a.(*v1beta1.CertificateRequest), b.(*certmanager.CertificateRequest), scope)
 	}); err != nil {
@@ -153,6 +193,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificateSecretKeys)(nil), (*certmanager.CertificateSecretKeys)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(a.(*v1beta1.CertificateSecretKeys), b.(*certmanager.CertificateSecretKeys), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CertificateSecretKeys)(nil), (*v1beta1.CertificateSecretKeys)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CertificateSecretKeys_To_v1beta1_CertificateSecretKeys(a.(*certmanager.CertificateSecretKeys), b.(*v1beta1.CertificateSecretKeys), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta1.CertificateSpec)(nil), (*certmanager.CertificateSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_CertificateSpec_To_certmanager_CertificateSpec(a.(*v1beta1.CertificateSpec), b.(*certmanager.CertificateSpec), scope)
 	}); err != nil {
@@ -193,6 +243,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.CustomExtensionPolicy)(nil), (*certmanager.CustomExtensionPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(a.(*v1beta1.CustomExtensionPolicy), b.(*certmanager.CustomExtensionPolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.CustomExtensionPolicy)(nil), (*v1beta1.CustomExtensionPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_CustomExtensionPolicy_To_v1beta1_CustomExtensionPolicy(a.(*certmanager.CustomExtensionPolicy), b.(*v1beta1.CustomExtensionPolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.IssuedCertificateSummary)(nil), (*certmanager.IssuedCertificateSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(a.(*v1beta1.IssuedCertificateSummary), b.(*certmanager.IssuedCertificateSummary), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.IssuedCertificateSummary)(nil), (*v1beta1.IssuedCertificateSummary)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_IssuedCertificateSummary_To_v1beta1_IssuedCertificateSummary(a.(*certmanager.IssuedCertificateSummary), b.(*v1beta1.IssuedCertificateSummary), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta1.Issuer)(nil), (*certmanager.Issuer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_Issuer_To_certmanager_Issuer(a.(*v1beta1.Issuer), b.(*certmanager.Issuer), scope)
 	}); err != nil {
@@ -263,6 +333,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.OtherName)(nil), (*certmanager.OtherName)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_OtherName_To_certmanager_OtherName(a.(*v1beta1.OtherName), b.(*certmanager.OtherName), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.OtherName)(nil), (*v1beta1.OtherName)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_OtherName_To_v1beta1_OtherName(a.(*certmanager.OtherName), b.(*v1beta1.OtherName), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta1.PKCS12Keystore)(nil), (*certmanager.PKCS12Keystore)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_PKCS12Keystore_To_certmanager_PKCS12Keystore(a.(*v1beta1.PKCS12Keystore), b.(*certmanager.PKCS12Keystore), scope)
 	}); err != nil {
@@ -273,6 +353,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.PolicyConstraints)(nil), (*certmanager.PolicyConstraints)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_PolicyConstraints_To_certmanager_PolicyConstraints(a.(*v1beta1.PolicyConstraints), b.(*certmanager.PolicyConstraints), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.PolicyConstraints)(nil), (*v1beta1.PolicyConstraints)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_PolicyConstraints_To_v1beta1_PolicyConstraints(a.(*certmanager.PolicyConstraints), b.(*v1beta1.PolicyConstraints), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*v1beta1.SPIFFEOptions)(nil), (*certmanager.SPIFFEOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_SPIFFEOptions_To_certmanager_SPIFFEOptions(a.(*v1beta1.SPIFFEOptions), b.(*certmanager.SPIFFEOptions), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*certmanager.SPIFFEOptions)(nil), (*v1beta1.SPIFFEOptions)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_certmanager_SPIFFEOptions_To_v1beta1_SPIFFEOptions(a.(*certmanager.SPIFFEOptions), b.(*v1beta1.SPIFFEOptions), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta1.SelfSignedIssuer)(nil), (*certmanager.SelfSignedIssuer)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(a.(*v1beta1.SelfSignedIssuer), b.(*certmanager.SelfSignedIssuer), scope)
 	}); err != nil {
@@ -370,6 +470,15 @@ func autoConvert_v1beta1_CAIssuer_To_certmanager_CAIssuer(in *v1beta1.CAIssuer,
 	out.SecretName = in.SecretName
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
 	out.OCSPServers = *(*[]string)(unsafe.Pointer(&in.OCSPServers))
+	out.PolicyIdentifiers = *(*[]certmanager.CertificatePolicy)(unsafe.Pointer(&in.PolicyIdentifiers))
+	out.InhibitAnyPolicy = (*int32)(unsafe.Pointer(in.InhibitAnyPolicy))
+	out.PolicyConstraints = (*certmanager.PolicyConstraints)(unsafe.Pointer(in.PolicyConstraints))
+	out.AllowedCustomExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
+	out.PreferredChain = in.PreferredChain
+	out.SubjectKeyIdentifierMethod = certmanager.SubjectKeyIdentifierMethod(in.SubjectKeyIdentifierMethod)
+	out.LegacyCommonNameOnly = in.LegacyCommonNameOnly
+	out.RotationSecretNames = *(*[]string)(unsafe.Pointer(&in.RotationSecretNames))
 	return nil
 }
 
@@ -382,6 +491,15 @@ func autoConvert_certmanager_CAIssuer_To_v1beta1_CAIssuer(in *certmanager.CAIssu
 	out.SecretName = in.SecretName
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
 	out.OCSPServers = *(*[]string)(unsafe.Pointer(&in.OCSPServers))
+	out.PolicyIdentifiers = *(*[]v1beta1.CertificatePolicy)(unsafe.Pointer(&in.PolicyIdentifiers))
+	out.InhibitAnyPolicy = (*int32)(unsafe.Pointer(in.InhibitAnyPolicy))
+	out.PolicyConstraints = (*v1beta1.PolicyConstraints)(unsafe.Pointer(in.PolicyConstraints))
+	out.AllowedCustomExtensions = *(*[]v1beta1.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]v1beta1.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
+	out.PreferredChain = in.PreferredChain
+	out.SubjectKeyIdentifierMethod = v1beta1.SubjectKeyIdentifierMethod(in.SubjectKeyIdentifierMethod)
+	out.LegacyCommonNameOnly = in.LegacyCommonNameOnly
+	out.RotationSecretNames = *(*[]string)(unsafe.Pointer(&in.RotationSecretNames))
 	return nil
 }
 
@@ -422,6 +540,26 @@ func Convert_certmanager_Certificate_To_v1beta1_Certificate(in *certmanager.Cert
 	return autoConvert_certmanager_Certificate_To_v1beta1_Certificate(in, out, s)
 }
 
+func autoConvert_v1beta1_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(in *v1beta1.CertificateAdditionalOutputFormat, out *certmanager.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	out.Type = certmanager.CertificateOutputFormatType(in.Type)
+	return nil
+}
+
+// Convert_v1beta1_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat is an autogenerated conversion function.
+func Convert_v1beta1_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(in *v1beta1.CertificateAdditionalOutputFormat, out *certmanager.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	return autoConvert_v1beta1_CertificateAdditionalOutputFormat_To_certmanager_CertificateAdditionalOutputFormat(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateAdditionalOutputFormat_To_v1beta1_CertificateAdditionalOutputFormat(in *certmanager.CertificateAdditionalOutputFormat, out *v1beta1.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	out.Type = v1beta1.CertificateOutputFormatType(in.Type)
+	return nil
+}
+
+// Convert_certmanager_CertificateAdditionalOutputFormat_To_v1beta1_CertificateAdditionalOutputFormat is an autogenerated conversion function.
+func Convert_certmanager_CertificateAdditionalOutputFormat_To_v1beta1_CertificateAdditionalOutputFormat(in *certmanager.CertificateAdditionalOutputFormat, out *v1beta1.CertificateAdditionalOutputFormat, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateAdditionalOutputFormat_To_v1beta1_CertificateAdditionalOutputFormat(in, out, s)
+}
+
 func autoConvert_v1beta1_CertificateCondition_To_certmanager_CertificateCondition(in *v1beta1.CertificateCondition, out *certmanager.CertificateCondition, s conversion.Scope) error {
 	out.Type = certmanager.CertificateConditionType(in.Type)
 	out.Status = meta.ConditionStatus(in.Status)
@@ -548,6 +686,28 @@ func Convert_certmanager_CertificateList_To_v1beta1_CertificateList(in *certmana
 	return autoConvert_certmanager_CertificateList_To_v1beta1_CertificateList(in, out, s)
 }
 
+func autoConvert_v1beta1_CertificatePolicy_To_certmanager_CertificatePolicy(in *v1beta1.CertificatePolicy, out *certmanager.CertificatePolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.CPSURIs = *(*[]string)(unsafe.Pointer(&in.CPSURIs))
+	return nil
+}
+
+// Convert_v1beta1_CertificatePolicy_To_certmanager_CertificatePolicy is an autogenerated conversion function.
+func Convert_v1beta1_CertificatePolicy_To_certmanager_CertificatePolicy(in *v1beta1.CertificatePolicy, out *certmanager.CertificatePolicy, s conversion.Scope) error {
+	return autoConvert_v1beta1_CertificatePolicy_To_certmanager_CertificatePolicy(in, out, s)
+}
+
+func autoConvert_certmanager_CertificatePolicy_To_v1beta1_CertificatePolicy(in *certmanager.CertificatePolicy, out *v1beta1.CertificatePolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.CPSURIs = *(*[]string)(unsafe.Pointer(&in.CPSURIs))
+	return nil
+}
+
+// Convert_certmanager_CertificatePolicy_To_v1beta1_CertificatePolicy is an autogenerated conversion function.
+func Convert_certmanager_CertificatePolicy_To_v1beta1_CertificatePolicy(in *certmanager.CertificatePolicy, out *v1beta1.CertificatePolicy, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificatePolicy_To_v1beta1_CertificatePolicy(in, out, s)
+}
+
 func autoConvert_v1beta1_CertificatePrivateKey_To_certmanager_CertificatePrivateKey(in *v1beta1.CertificatePrivateKey, out *certmanager.CertificatePrivateKey, s conversion.Scope) error {
 	out.RotationPolicy = certmanager.PrivateKeyRotationPolicy(in.RotationPolicy)
 	out.Encoding = certmanager.PrivateKeyEncoding(in.Encoding)
@@ -574,6 +734,50 @@ func Convert_certmanager_CertificatePrivateKey_To_v1beta1_CertificatePrivateKey(
 	return autoConvert_certmanager_CertificatePrivateKey_To_v1beta1_CertificatePrivateKey(in, out, s)
 }
 
+func autoConvert_v1beta1_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(in *v1beta1.CertificateReconciliationRecord, out *certmanager.CertificateReconciliationRecord, s conversion.Scope) error {
+	out.Controller = in.Controller
+	out.Time = in.Time
+	return nil
+}
+
+// Convert_v1beta1_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord is an autogenerated conversion function.
+func Convert_v1beta1_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(in *v1beta1.CertificateReconciliationRecord, out *certmanager.CertificateReconciliationRecord, s conversion.Scope) error {
+	return autoConvert_v1beta1_CertificateReconciliationRecord_To_certmanager_CertificateReconciliationRecord(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateReconciliationRecord_To_v1beta1_CertificateReconciliationRecord(in *certmanager.CertificateReconciliationRecord, out *v1beta1.CertificateReconciliationRecord, s conversion.Scope) error {
+	out.Controller = in.Controller
+	out.Time = in.Time
+	return nil
+}
+
+// Convert_certmanager_CertificateReconciliationRecord_To_v1beta1_CertificateReconciliationRecord is an autogenerated conversion function.
+func Convert_certmanager_CertificateReconciliationRecord_To_v1beta1_CertificateReconciliationRecord(in *certmanager.CertificateReconciliationRecord, out *v1beta1.CertificateReconciliationRecord, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateReconciliationRecord_To_v1beta1_CertificateReconciliationRecord(in, out, s)
+}
+
+func autoConvert_v1beta1_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(in *v1beta1.CertificateRenewalWindow, out *certmanager.CertificateRenewalWindow, s conversion.Scope) error {
+	out.Ranges = *(*[]string)(unsafe.Pointer(&in.Ranges))
+	out.TimeZone = in.TimeZone
+	return nil
+}
+
+// Convert_v1beta1_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow is an autogenerated conversion function.
+func Convert_v1beta1_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(in *v1beta1.CertificateRenewalWindow, out *certmanager.CertificateRenewalWindow, s conversion.Scope) error {
+	return autoConvert_v1beta1_CertificateRenewalWindow_To_certmanager_CertificateRenewalWindow(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateRenewalWindow_To_v1beta1_CertificateRenewalWindow(in *certmanager.CertificateRenewalWindow, out *v1beta1.CertificateRenewalWindow, s conversion.Scope) error {
+	out.Ranges = *(*[]string)(unsafe.Pointer(&in.Ranges))
+	out.TimeZone = in.TimeZone
+	return nil
+}
+
+// Convert_certmanager_CertificateRenewalWindow_To_v1beta1_CertificateRenewalWindow is an autogenerated conversion function.
+func Convert_certmanager_CertificateRenewalWindow_To_v1beta1_CertificateRenewalWindow(in *certmanager.CertificateRenewalWindow, out *v1beta1.CertificateRenewalWindow, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateRenewalWindow_To_v1beta1_CertificateRenewalWindow(in, out, s)
+}
+
 func autoConvert_v1beta1_CertificateRequest_To_certmanager_CertificateRequest(in *v1beta1.CertificateRequest, out *certmanager.CertificateRequest, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1beta1_CertificateRequestSpec_To_certmanager_CertificateRequestSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -678,11 +882,14 @@ func Convert_certmanager_CertificateRequestList_To_v1beta1_CertificateRequestLis
 
 func autoConvert_v1beta1_CertificateRequestSpec_To_certmanager_CertificateRequestSpec(in *v1beta1.CertificateRequestSpec, out *certmanager.CertificateRequestSpec, s conversion.Scope) error {
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
 	out.Request = *(*[]byte)(unsafe.Pointer(&in.Request))
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
+	out.SignatureAlgorithm = certmanager.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.Usages = *(*[]certmanager.KeyUsage)(unsafe.Pointer(&in.Usages))
 	out.Username = in.Username
 	out.UID = in.UID
@@ -698,11 +905,14 @@ func Convert_v1beta1_CertificateRequestSpec_To_certmanager_CertificateRequestSpe
 
 func autoConvert_certmanager_CertificateRequestSpec_To_v1beta1_CertificateRequestSpec(in *certmanager.CertificateRequestSpec, out *v1beta1.CertificateRequestSpec, s conversion.Scope) error {
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
 	out.Request = *(*[]byte)(unsafe.Pointer(&in.Request))
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
+	out.SignatureAlgorithm = v1beta1.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.Usages = *(*[]v1beta1.KeyUsage)(unsafe.Pointer(&in.Usages))
 	out.Username = in.Username
 	out.UID = in.UID
@@ -742,16 +952,56 @@ func Convert_certmanager_CertificateRequestStatus_To_v1beta1_CertificateRequestS
 	return autoConvert_certmanager_CertificateRequestStatus_To_v1beta1_CertificateRequestStatus(in, out, s)
 }
 
+func autoConvert_v1beta1_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(in *v1beta1.CertificateSecretKeys, out *certmanager.CertificateSecretKeys, s conversion.Scope) error {
+	out.PrivateKey = in.PrivateKey
+	out.Certificate = in.Certificate
+	out.CA = in.CA
+	return nil
+}
+
+// Convert_v1beta1_CertificateSecretKeys_To_certmanager_CertificateSecretKeys is an autogenerated conversion function.
+func Convert_v1beta1_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(in *v1beta1.CertificateSecretKeys, out *certmanager.CertificateSecretKeys, s conversion.Scope) error {
+	return autoConvert_v1beta1_CertificateSecretKeys_To_certmanager_CertificateSecretKeys(in, out, s)
+}
+
+func autoConvert_certmanager_CertificateSecretKeys_To_v1beta1_CertificateSecretKeys(in *certmanager.CertificateSecretKeys, out *v1beta1.CertificateSecretKeys, s conversion.Scope) error {
+	out.PrivateKey = in.PrivateKey
+	out.Certificate = in.Certificate
+	out.CA = in.CA
+	return nil
+}
+
+// Convert_certmanager_CertificateSecretKeys_To_v1beta1_CertificateSecretKeys is an autogenerated conversion function.
+func Convert_certmanager_CertificateSecretKeys_To_v1beta1_CertificateSecretKeys(in *certmanager.CertificateSecretKeys, out *v1beta1.CertificateSecretKeys, s conversion.Scope) error {
+	return autoConvert_certmanager_CertificateSecretKeys_To_v1beta1_CertificateSecretKeys(in, out, s)
+}
+
 func autoConvert_v1beta1_CertificateSpec_To_certmanager_CertificateSpec(in *v1beta1.CertificateSpec, out *certmanager.CertificateSpec, s conversion.Scope) error {
 	out.Subject = (*certmanager.X509Subject)(unsafe.Pointer(in.Subject))
 	out.CommonName = in.CommonName
+	out.ExcludeCommonNameFromSANs = in.ExcludeCommonNameFromSANs
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.IssuanceTimeout = (*v1.Duration)(unsafe.Pointer(in.IssuanceTimeout))
+	out.RenewalWindow = (*certmanager.CertificateRenewalWindow)(unsafe.Pointer(in.RenewalWindow))
+	if in.TrustAnchors != nil {
+		in, out := &in.TrustAnchors, &out.TrustAnchors
+		*out = new(meta.SecretKeySelector)
+		if err := apismetav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TrustAnchors = nil
+	}
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
 	out.EmailSANs = *(*[]string)(unsafe.Pointer(&in.EmailSANs))
+	out.OtherNames = *(*[]certmanager.OtherName)(unsafe.Pointer(&in.OtherNames))
 	out.SecretName = in.SecretName
+	out.SecretType = in.SecretType
+	out.SecretKeys = (*certmanager.CertificateSecretKeys)(unsafe.Pointer(in.SecretKeys))
 	if in.Keystores != nil {
 		in, out := &in.Keystores, &out.Keystores
 		*out = new(certmanager.CertificateKeystores)
@@ -761,13 +1011,37 @@ func autoConvert_v1beta1_CertificateSpec_To_certmanager_CertificateSpec(in *v1be
 	} else {
 		out.Keystores = nil
 	}
+	out.AdditionalOutputFormats = *(*[]certmanager.CertificateAdditionalOutputFormat)(unsafe.Pointer(&in.AdditionalOutputFormats))
 	if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
+	if in.IssuerRefs != nil {
+		in, out := &in.IssuerRefs, &out.IssuerRefs
+		*out = make([]meta.ObjectReference, len(*in))
+		for i := range *in {
+			if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.IssuerRefs = nil
+	}
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
 	out.Usages = *(*[]certmanager.KeyUsage)(unsafe.Pointer(&in.Usages))
 	out.PrivateKey = (*certmanager.CertificatePrivateKey)(unsafe.Pointer(in.PrivateKey))
+	if in.CSRSecretRef != nil {
+		in, out := &in.CSRSecretRef, &out.CSRSecretRef
+		*out = new(meta.SecretKeySelector)
+		if err := apismetav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CSRSecretRef = nil
+	}
 	out.EncodeUsagesInRequest = (*bool)(unsafe.Pointer(in.EncodeUsagesInRequest))
+	out.CSRSignatureAlgorithm = certmanager.SignatureAlgorithm(in.CSRSignatureAlgorithm)
+	out.SignatureAlgorithm = certmanager.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.RevisionHistoryLimit = (*int32)(unsafe.Pointer(in.RevisionHistoryLimit))
 	return nil
 }
@@ -780,13 +1054,29 @@ func Convert_v1beta1_CertificateSpec_To_certmanager_CertificateSpec(in *v1beta1.
 func autoConvert_certmanager_CertificateSpec_To_v1beta1_CertificateSpec(in *certmanager.CertificateSpec, out *v1beta1.CertificateSpec, s conversion.Scope) error {
 	out.Subject = (*v1beta1.X509Subject)(unsafe.Pointer(in.Subject))
 	out.CommonName = in.CommonName
+	out.ExcludeCommonNameFromSANs = in.ExcludeCommonNameFromSANs
 	out.Duration = (*v1.Duration)(unsafe.Pointer(in.Duration))
+	out.NotBefore = (*v1.Time)(unsafe.Pointer(in.NotBefore))
 	out.RenewBefore = (*v1.Duration)(unsafe.Pointer(in.RenewBefore))
+	out.IssuanceTimeout = (*v1.Duration)(unsafe.Pointer(in.IssuanceTimeout))
+	out.RenewalWindow = (*v1beta1.CertificateRenewalWindow)(unsafe.Pointer(in.RenewalWindow))
+	if in.TrustAnchors != nil {
+		in, out := &in.TrustAnchors, &out.TrustAnchors
+		*out = new(metav1.SecretKeySelector)
+		if err := apismetav1.Convert_meta_SecretKeySelector_To_v1_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.TrustAnchors = nil
+	}
 	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
 	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
 	out.URISANs = *(*[]string)(unsafe.Pointer(&in.URISANs))
 	out.EmailSANs = *(*[]string)(unsafe.Pointer(&in.EmailSANs))
+	out.OtherNames = *(*[]v1beta1.OtherName)(unsafe.Pointer(&in.OtherNames))
 	out.SecretName = in.SecretName
+	out.SecretType = in.SecretType
+	out.SecretKeys = (*v1beta1.CertificateSecretKeys)(unsafe.Pointer(in.SecretKeys))
 	if in.Keystores != nil {
 		in, out := &in.Keystores, &out.Keystores
 		*out = new(v1beta1.CertificateKeystores)
@@ -796,13 +1086,37 @@ func autoConvert_certmanager_CertificateSpec_To_v1beta1_CertificateSpec(in *cert
 	} else {
 		out.Keystores = nil
 	}
+	out.AdditionalOutputFormats = *(*[]v1beta1.CertificateAdditionalOutputFormat)(unsafe.Pointer(&in.AdditionalOutputFormats))
 	if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(&in.IssuerRef, &out.IssuerRef, s); err != nil {
 		return err
 	}
+	if in.IssuerRefs != nil {
+		in, out := &in.IssuerRefs, &out.IssuerRefs
+		*out = make([]metav1.ObjectReference, len(*in))
+		for i := range *in {
+			if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.IssuerRefs = nil
+	}
 	out.IsCA = in.IsCA
+	out.MaxPathLen = (*int32)(unsafe.Pointer(in.MaxPathLen))
 	out.Usages = *(*[]v1beta1.KeyUsage)(unsafe.Pointer(&in.Usages))
 	out.PrivateKey = (*v1beta1.CertificatePrivateKey)(unsafe.Pointer(in.PrivateKey))
+	if in.CSRSecretRef != nil {
+		in, out := &in.CSRSecretRef, &out.CSRSecretRef
+		*out = new(metav1.SecretKeySelector)
+		if err := apismetav1.Convert_meta_SecretKeySelector_To_v1_SecretKeySelector(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.CSRSecretRef = nil
+	}
 	out.EncodeUsagesInRequest = (*bool)(unsafe.Pointer(in.EncodeUsagesInRequest))
+	out.CSRSignatureAlgorithm = v1beta1.SignatureAlgorithm(in.CSRSignatureAlgorithm)
+	out.SignatureAlgorithm = v1beta1.SignatureAlgorithm(in.SignatureAlgorithm)
 	out.RevisionHistoryLimit = (*int32)(unsafe.Pointer(in.RevisionHistoryLimit))
 	return nil
 }
@@ -820,6 +1134,18 @@ func autoConvert_v1beta1_CertificateStatus_To_certmanager_CertificateStatus(in *
 	out.RenewalTime = (*v1.Time)(unsafe.Pointer(in.RenewalTime))
 	out.Revision = (*int)(unsafe.Pointer(in.Revision))
 	out.NextPrivateKeySecretName = (*string)(unsafe.Pointer(in.NextPrivateKeySecretName))
+	out.IssuanceLatency = (*v1.Duration)(unsafe.Pointer(in.IssuanceLatency))
+	if in.ActiveIssuerRef != nil {
+		in, out := &in.ActiveIssuerRef, &out.ActiveIssuerRef
+		*out = new(meta.ObjectReference)
+		if err := apismetav1.Convert_v1_ObjectReference_To_meta_ObjectReference(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ActiveIssuerRef = nil
+	}
+	out.LastReconciledBy = *(*[]certmanager.CertificateReconciliationRecord)(unsafe.Pointer(&in.LastReconciledBy))
+	out.IssuedCertificate = (*certmanager.IssuedCertificateSummary)(unsafe.Pointer(in.IssuedCertificate))
 	return nil
 }
 
@@ -836,6 +1162,18 @@ func autoConvert_certmanager_CertificateStatus_To_v1beta1_CertificateStatus(in *
 	out.RenewalTime = (*v1.Time)(unsafe.Pointer(in.RenewalTime))
 	out.Revision = (*int)(unsafe.Pointer(in.Revision))
 	out.NextPrivateKeySecretName = (*string)(unsafe.Pointer(in.NextPrivateKeySecretName))
+	out.IssuanceLatency = (*v1.Duration)(unsafe.Pointer(in.IssuanceLatency))
+	if in.ActiveIssuerRef != nil {
+		in, out := &in.ActiveIssuerRef, &out.ActiveIssuerRef
+		*out = new(metav1.ObjectReference)
+		if err := apismetav1.Convert_meta_ObjectReference_To_v1_ObjectReference(*in, *out, s); err != nil {
+			return err
+		}
+	} else {
+		out.ActiveIssuerRef = nil
+	}
+	out.LastReconciledBy = *(*[]v1beta1.CertificateReconciliationRecord)(unsafe.Pointer(&in.LastReconciledBy))
+	out.IssuedCertificate = (*v1beta1.IssuedCertificateSummary)(unsafe.Pointer(in.IssuedCertificate))
 	return nil
 }
 
@@ -918,6 +1256,62 @@ func Convert_certmanager_ClusterIssuerList_To_v1beta1_ClusterIssuerList(in *cert
 	return autoConvert_certmanager_ClusterIssuerList_To_v1beta1_ClusterIssuerList(in, out, s)
 }
 
+func autoConvert_v1beta1_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(in *v1beta1.CustomExtensionPolicy, out *certmanager.CustomExtensionPolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.AllowCritical = in.AllowCritical
+	return nil
+}
+
+// Convert_v1beta1_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy is an autogenerated conversion function.
+func Convert_v1beta1_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(in *v1beta1.CustomExtensionPolicy, out *certmanager.CustomExtensionPolicy, s conversion.Scope) error {
+	return autoConvert_v1beta1_CustomExtensionPolicy_To_certmanager_CustomExtensionPolicy(in, out, s)
+}
+
+func autoConvert_certmanager_CustomExtensionPolicy_To_v1beta1_CustomExtensionPolicy(in *certmanager.CustomExtensionPolicy, out *v1beta1.CustomExtensionPolicy, s conversion.Scope) error {
+	out.OID = in.OID
+	out.AllowCritical = in.AllowCritical
+	return nil
+}
+
+// Convert_certmanager_CustomExtensionPolicy_To_v1beta1_CustomExtensionPolicy is an autogenerated conversion function.
+func Convert_certmanager_CustomExtensionPolicy_To_v1beta1_CustomExtensionPolicy(in *certmanager.CustomExtensionPolicy, out *v1beta1.CustomExtensionPolicy, s conversion.Scope) error {
+	return autoConvert_certmanager_CustomExtensionPolicy_To_v1beta1_CustomExtensionPolicy(in, out, s)
+}
+
+func autoConvert_v1beta1_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(in *v1beta1.IssuedCertificateSummary, out *certmanager.IssuedCertificateSummary, s conversion.Scope) error {
+	out.SerialNumber = in.SerialNumber
+	out.Subject = in.Subject
+	out.IssuerCommonName = in.IssuerCommonName
+	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
+	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
+	out.URIs = *(*[]string)(unsafe.Pointer(&in.URIs))
+	out.EmailAddresses = *(*[]string)(unsafe.Pointer(&in.EmailAddresses))
+	out.Truncated = in.Truncated
+	return nil
+}
+
+// Convert_v1beta1_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary is an autogenerated conversion function.
+func Convert_v1beta1_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(in *v1beta1.IssuedCertificateSummary, out *certmanager.IssuedCertificateSummary, s conversion.Scope) error {
+	return autoConvert_v1beta1_IssuedCertificateSummary_To_certmanager_IssuedCertificateSummary(in, out, s)
+}
+
+func autoConvert_certmanager_IssuedCertificateSummary_To_v1beta1_IssuedCertificateSummary(in *certmanager.IssuedCertificateSummary, out *v1beta1.IssuedCertificateSummary, s conversion.Scope) error {
+	out.SerialNumber = in.SerialNumber
+	out.Subject = in.Subject
+	out.IssuerCommonName = in.IssuerCommonName
+	out.DNSNames = *(*[]string)(unsafe.Pointer(&in.DNSNames))
+	out.IPAddresses = *(*[]string)(unsafe.Pointer(&in.IPAddresses))
+	out.URIs = *(*[]string)(unsafe.Pointer(&in.URIs))
+	out.EmailAddresses = *(*[]string)(unsafe.Pointer(&in.EmailAddresses))
+	out.Truncated = in.Truncated
+	return nil
+}
+
+// Convert_certmanager_IssuedCertificateSummary_To_v1beta1_IssuedCertificateSummary is an autogenerated conversion function.
+func Convert_certmanager_IssuedCertificateSummary_To_v1beta1_IssuedCertificateSummary(in *certmanager.IssuedCertificateSummary, out *v1beta1.IssuedCertificateSummary, s conversion.Scope) error {
+	return autoConvert_certmanager_IssuedCertificateSummary_To_v1beta1_IssuedCertificateSummary(in, out, s)
+}
+
 func autoConvert_v1beta1_Issuer_To_certmanager_Issuer(in *v1beta1.Issuer, out *certmanager.Issuer, s conversion.Scope) error {
 	out.ObjectMeta = in.ObjectMeta
 	if err := Convert_v1beta1_IssuerSpec_To_certmanager_IssuerSpec(&in.Spec, &out.Spec, s); err != nil {
@@ -1102,6 +1496,8 @@ func autoConvert_v1beta1_IssuerSpec_To_certmanager_IssuerSpec(in *v1beta1.Issuer
 	if err := Convert_v1beta1_IssuerConfig_To_certmanager_IssuerConfig(&in.IssuerConfig, &out.IssuerConfig, s); err != nil {
 		return err
 	}
+	out.AllowedDomains = *(*[]string)(unsafe.Pointer(&in.AllowedDomains))
+	out.SPIFFE = (*certmanager.SPIFFEOptions)(unsafe.Pointer(in.SPIFFE))
 	return nil
 }
 
@@ -1114,6 +1510,8 @@ func autoConvert_certmanager_IssuerSpec_To_v1beta1_IssuerSpec(in *certmanager.Is
 	if err := Convert_certmanager_IssuerConfig_To_v1beta1_IssuerConfig(&in.IssuerConfig, &out.IssuerConfig, s); err != nil {
 		return err
 	}
+	out.AllowedDomains = *(*[]string)(unsafe.Pointer(&in.AllowedDomains))
+	out.SPIFFE = (*v1beta1.SPIFFEOptions)(unsafe.Pointer(in.SPIFFE))
 	return nil
 }
 
@@ -1186,6 +1584,28 @@ func Convert_certmanager_JKSKeystore_To_v1beta1_JKSKeystore(in *certmanager.JKSK
 	return autoConvert_certmanager_JKSKeystore_To_v1beta1_JKSKeystore(in, out, s)
 }
 
+func autoConvert_v1beta1_OtherName_To_certmanager_OtherName(in *v1beta1.OtherName, out *certmanager.OtherName, s conversion.Scope) error {
+	out.OID = in.OID
+	out.UTF8Value = in.UTF8Value
+	return nil
+}
+
+// Convert_v1beta1_OtherName_To_certmanager_OtherName is an autogenerated conversion function.
+func Convert_v1beta1_OtherName_To_certmanager_OtherName(in *v1beta1.OtherName, out *certmanager.OtherName, s conversion.Scope) error {
+	return autoConvert_v1beta1_OtherName_To_certmanager_OtherName(in, out, s)
+}
+
+func autoConvert_certmanager_OtherName_To_v1beta1_OtherName(in *certmanager.OtherName, out *v1beta1.OtherName, s conversion.Scope) error {
+	out.OID = in.OID
+	out.UTF8Value = in.UTF8Value
+	return nil
+}
+
+// Convert_certmanager_OtherName_To_v1beta1_OtherName is an autogenerated conversion function.
+func Convert_certmanager_OtherName_To_v1beta1_OtherName(in *certmanager.OtherName, out *v1beta1.OtherName, s conversion.Scope) error {
+	return autoConvert_certmanager_OtherName_To_v1beta1_OtherName(in, out, s)
+}
+
 func autoConvert_v1beta1_PKCS12Keystore_To_certmanager_PKCS12Keystore(in *v1beta1.PKCS12Keystore, out *certmanager.PKCS12Keystore, s conversion.Scope) error {
 	out.Create = in.Create
 	if err := apismetav1.Convert_v1_SecretKeySelector_To_meta_SecretKeySelector(&in.PasswordSecretRef, &out.PasswordSecretRef, s); err != nil {
@@ -1212,8 +1632,52 @@ func Convert_certmanager_PKCS12Keystore_To_v1beta1_PKCS12Keystore(in *certmanage
 	return autoConvert_certmanager_PKCS12Keystore_To_v1beta1_PKCS12Keystore(in, out, s)
 }
 
+func autoConvert_v1beta1_PolicyConstraints_To_certmanager_PolicyConstraints(in *v1beta1.PolicyConstraints, out *certmanager.PolicyConstraints, s conversion.Scope) error {
+	out.RequireExplicitPolicy = (*int32)(unsafe.Pointer(in.RequireExplicitPolicy))
+	out.InhibitPolicyMapping = (*int32)(unsafe.Pointer(in.InhibitPolicyMapping))
+	return nil
+}
+
+// Convert_v1beta1_PolicyConstraints_To_certmanager_PolicyConstraints is an autogenerated conversion function.
+func Convert_v1beta1_PolicyConstraints_To_certmanager_PolicyConstraints(in *v1beta1.PolicyConstraints, out *certmanager.PolicyConstraints, s conversion.Scope) error {
+	return autoConvert_v1beta1_PolicyConstraints_To_certmanager_PolicyConstraints(in, out, s)
+}
+
+func autoConvert_certmanager_PolicyConstraints_To_v1beta1_PolicyConstraints(in *certmanager.PolicyConstraints, out *v1beta1.PolicyConstraints, s conversion.Scope) error {
+	out.RequireExplicitPolicy = (*int32)(unsafe.Pointer(in.RequireExplicitPolicy))
+	out.InhibitPolicyMapping = (*int32)(unsafe.Pointer(in.InhibitPolicyMapping))
+	return nil
+}
+
+// Convert_certmanager_PolicyConstraints_To_v1beta1_PolicyConstraints is an autogenerated conversion function.
+func Convert_certmanager_PolicyConstraints_To_v1beta1_PolicyConstraints(in *certmanager.PolicyConstraints, out *v1beta1.PolicyConstraints, s conversion.Scope) error {
+	return autoConvert_certmanager_PolicyConstraints_To_v1beta1_PolicyConstraints(in, out, s)
+}
+
+func autoConvert_v1beta1_SPIFFEOptions_To_certmanager_SPIFFEOptions(in *v1beta1.SPIFFEOptions, out *certmanager.SPIFFEOptions, s conversion.Scope) error {
+	out.TrustDomain = in.TrustDomain
+	return nil
+}
+
+// Convert_v1beta1_SPIFFEOptions_To_certmanager_SPIFFEOptions is an autogenerated conversion function.
+func Convert_v1beta1_SPIFFEOptions_To_certmanager_SPIFFEOptions(in *v1beta1.SPIFFEOptions, out *certmanager.SPIFFEOptions, s conversion.Scope) error {
+	return autoConvert_v1beta1_SPIFFEOptions_To_certmanager_SPIFFEOptions(in, out, s)
+}
+
+func autoConvert_certmanager_SPIFFEOptions_To_v1beta1_SPIFFEOptions(in *certmanager.SPIFFEOptions, out *v1beta1.SPIFFEOptions, s conversion.Scope) error {
+	out.TrustDomain = in.TrustDomain
+	return nil
+}
+
+// Convert_certmanager_SPIFFEOptions_To_v1beta1_SPIFFEOptions is an autogenerated conversion function.
+func Convert_certmanager_SPIFFEOptions_To_v1beta1_SPIFFEOptions(in *certmanager.SPIFFEOptions, out *v1beta1.SPIFFEOptions, s conversion.Scope) error {
+	return autoConvert_certmanager_SPIFFEOptions_To_v1beta1_SPIFFEOptions(in, out, s)
+}
+
 func autoConvert_v1beta1_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *v1beta1.SelfSignedIssuer, out *certmanager.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.AllowedCustomExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]certmanager.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
 	return nil
 }
 
@@ -1224,6 +1688,8 @@ func Convert_v1beta1_SelfSignedIssuer_To_certmanager_SelfSignedIssuer(in *v1beta
 
 func autoConvert_certmanager_SelfSignedIssuer_To_v1beta1_SelfSignedIssuer(in *certmanager.SelfSignedIssuer, out *v1beta1.SelfSignedIssuer, s conversion.Scope) error {
 	out.CRLDistributionPoints = *(*[]string)(unsafe.Pointer(&in.CRLDistributionPoints))
+	out.AllowedCustomExtensions = *(*[]v1beta1.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCustomExtensions))
+	out.AllowedCSRExtensions = *(*[]v1beta1.CustomExtensionPolicy)(unsafe.Pointer(&in.AllowedCSRExtensions))
 	return nil
 }
 
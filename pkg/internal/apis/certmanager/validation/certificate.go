@@ -20,9 +20,14 @@ import (
 	"fmt"
 	"net"
 	"net/mail"
+	"time"
+	"unicode/utf8"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/jetstack/cert-manager/pkg/api/util"
@@ -30,20 +35,53 @@ import (
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
 	internalcmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
 	cmmeta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
 // Validation functions for cert-manager Certificate types
 
-func ValidateCertificateSpec(crt *internalcmapi.CertificateSpec, fldPath *field.Path) field.ErrorList {
+// certificateValidator validates Certificates using the Options that the
+// webhook was configured with.
+type certificateValidator struct {
+	minimumDuration time.Duration
+}
+
+func newCertificateValidator(opts Options) *certificateValidator {
+	minimumDuration := opts.MinimumCertificateDuration
+	if minimumDuration == 0 {
+		minimumDuration = cmapi.MinimumCertificateDuration
+	}
+	return &certificateValidator{minimumDuration: minimumDuration}
+}
+
+func ValidateCertificateSpec(crt *internalcmapi.CertificateSpec, minimumDuration time.Duration, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 	if crt.SecretName == "" {
 		el = append(el, field.Required(fldPath.Child("secretName"), "must be specified"))
 	}
 
-	el = append(el, validateIssuerRef(crt.IssuerRef, fldPath)...)
+	switch corev1.SecretType(crt.SecretType) {
+	case "", corev1.SecretTypeTLS, corev1.SecretTypeOpaque:
+	default:
+		el = append(el, field.NotSupported(fldPath.Child("secretType"), crt.SecretType,
+			[]string{string(corev1.SecretTypeTLS), string(corev1.SecretTypeOpaque)}))
+	}
 
-	if len(crt.CommonName) == 0 && len(crt.DNSNames) == 0 && len(crt.URISANs) == 0 && len(crt.EmailSANs) == 0 && len(crt.IPAddresses) == 0 {
-		el = append(el, field.Invalid(fldPath, "", "at least one of commonName, dnsNames, uris ipAddresses, or emailAddresses must be set"))
+	if crt.SecretKeys != nil {
+		el = append(el, validateSecretKeys(crt, fldPath.Child("secretKeys"))...)
+	}
+
+	el = append(el, validateAdditionalOutputFormats(crt.AdditionalOutputFormats, fldPath.Child("additionalOutputFormats"))...)
+
+	el = append(el, validateIssuerRef(crt.IssuerRef, fldPath.Child("issuerRef"))...)
+	for i, issuerRef := range crt.IssuerRefs {
+		el = append(el, validateIssuerRef(issuerRef, fldPath.Child("issuerRefs").Index(i))...)
+	}
+
+	if crt.CSRSecretRef != nil {
+		el = append(el, validateCSRSecretRef(crt, fldPath)...)
+	} else if len(crt.CommonName) == 0 && len(crt.DNSNames) == 0 && len(crt.URISANs) == 0 && len(crt.EmailSANs) == 0 && len(crt.IPAddresses) == 0 && len(crt.OtherNames) == 0 {
+		el = append(el, field.Invalid(fldPath, "", "at least one of commonName, dnsNames, uris ipAddresses, emailAddresses, or otherNames must be set"))
 	}
 
 	// if a common name has been specified, ensure it is no longer than 64 chars
@@ -59,6 +97,8 @@ func ValidateCertificateSpec(crt *internalcmapi.CertificateSpec, fldPath *field.
 		el = append(el, validateEmailAddresses(crt, fldPath)...)
 	}
 
+	el = append(el, validateOtherNames(crt.OtherNames, fldPath.Child("otherNames"))...)
+
 	if crt.PrivateKey != nil {
 		switch crt.PrivateKey.Algorithm {
 		case "", internalcmapi.RSAKeyAlgorithm:
@@ -67,19 +107,34 @@ func ValidateCertificateSpec(crt *internalcmapi.CertificateSpec, fldPath *field.
 			}
 		case internalcmapi.ECDSAKeyAlgorithm:
 			if crt.PrivateKey.Size > 0 && crt.PrivateKey.Size != 256 && crt.PrivateKey.Size != 384 && crt.PrivateKey.Size != 521 {
-				el = append(el, field.NotSupported(fldPath.Child("privateKey", "size"), crt.PrivateKey.Size, []string{"256", "384", "521"}))
+				el = append(el, field.Invalid(fldPath.Child("privateKey", "size"), crt.PrivateKey.Size, "must be one of 256 (P-256), 384 (P-384) or 521 (P-521) for ecdsa keyAlgorithm"))
 			}
 		default:
 			el = append(el, field.Invalid(fldPath.Child("privateKey", "algorithm"), crt.PrivateKey.Algorithm, "must be either empty or one of rsa or ecdsa"))
 		}
 	}
 
+	if crt.CSRSignatureAlgorithm != "" && crt.CSRSecretRef == nil {
+		el = append(el, validateCSRSignatureAlgorithm(crt, fldPath)...)
+	}
+
 	if crt.Duration != nil || crt.RenewBefore != nil {
-		el = append(el, ValidateDuration(crt, fldPath)...)
+		el = append(el, ValidateDuration(crt, minimumDuration, fldPath)...)
+	}
+	if crt.IssuanceTimeout != nil && crt.IssuanceTimeout.Duration <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("issuanceTimeout"), crt.IssuanceTimeout.Duration, "must be greater than zero"))
+	}
+	if crt.RenewalWindow != nil {
+		el = append(el, validateRenewalWindow(crt.RenewalWindow, fldPath.Child("renewalWindow"))...)
+	}
+	if crt.TrustAnchors != nil && crt.TrustAnchors.Name == "" {
+		el = append(el, field.Required(fldPath.Child("trustAnchors", "name"), "must be specified"))
 	}
 	if len(crt.Usages) > 0 {
-		el = append(el, validateUsages(crt, fldPath)...)
+		el = append(el, validateUsages(crt.Usages, fldPath)...)
 	}
+	el = append(el, validateMaxPathLen(crt.IsCA, crt.MaxPathLen, fldPath.Child("maxPathLen"))...)
+	el = append(el, validateSignatureAlgorithm(crt.SignatureAlgorithm, fldPath.Child("signatureAlgorithm"))...)
 	if crt.RevisionHistoryLimit != nil && *crt.RevisionHistoryLimit < 1 {
 		el = append(el, field.Invalid(fldPath.Child("revisionHistoryLimit"), *crt.RevisionHistoryLimit, "must not be less than 1"))
 	}
@@ -87,33 +142,212 @@ func ValidateCertificateSpec(crt *internalcmapi.CertificateSpec, fldPath *field.
 	return el
 }
 
-func ValidateCertificate(a *admissionv1.AdmissionRequest, obj runtime.Object) (field.ErrorList, validation.WarningList) {
+// validateMaxPathLen ensures that a path length constraint is only requested
+// for CA certificates, and is not negative.
+func validateMaxPathLen(isCA bool, maxPathLen *int32, fldPath *field.Path) field.ErrorList {
+	if maxPathLen == nil {
+		return nil
+	}
+	el := field.ErrorList{}
+	if !isCA {
+		el = append(el, field.Forbidden(fldPath, "may only be set when isCA is true"))
+	}
+	if *maxPathLen < 0 {
+		el = append(el, field.Invalid(fldPath, *maxPathLen, "must not be negative"))
+	}
+	return el
+}
+
+func (v *certificateValidator) ValidateCertificate(a *admissionv1.AdmissionRequest, obj runtime.Object) (field.ErrorList, validation.WarningList) {
 	crt := obj.(*internalcmapi.Certificate)
-	allErrs := ValidateCertificateSpec(&crt.Spec, field.NewPath("spec"))
+	allErrs := ValidateCertificateSpec(&crt.Spec, v.minimumDuration, field.NewPath("spec"))
+	allErrs = append(allErrs, validateOCSPNoCheck(crt.Annotations, crt.Spec.Usages, field.NewPath("metadata", "annotations"))...)
+	if crt.Spec.NotBefore != nil {
+		allErrs = append(allErrs, validateNotBefore(crt.Spec.NotBefore, time.Now(), field.NewPath("spec", "notBefore"))...)
+	}
 	w := validateAPIVersion(a.RequestKind)
+	w = append(w, validateNotAfterEncoding(&crt.Spec, time.Now())...)
 	return allErrs, w
 }
 
-func ValidateUpdateCertificate(a *admissionv1.AdmissionRequest, oldObj, obj runtime.Object) (field.ErrorList, validation.WarningList) {
-	crt := obj.(*internalcmapi.Certificate)
-	allErrs := ValidateCertificateSpec(&crt.Spec, field.NewPath("spec"))
+func (v *certificateValidator) ValidateUpdateCertificate(a *admissionv1.AdmissionRequest, oldObj, obj runtime.Object) (field.ErrorList, validation.WarningList) {
+	oldCrt, crt := oldObj.(*internalcmapi.Certificate), obj.(*internalcmapi.Certificate)
+	allErrs := ValidateCertificateSpec(&crt.Spec, v.minimumDuration, field.NewPath("spec"))
+	allErrs = append(allErrs, validateOCSPNoCheck(crt.Annotations, crt.Spec.Usages, field.NewPath("metadata", "annotations"))...)
+	// Only validate notBefore when it changes, so that updates to existing
+	// Certificates are not rejected as time passes.
+	if crt.Spec.NotBefore != nil && !crt.Spec.NotBefore.Equal(oldCrt.Spec.NotBefore) {
+		allErrs = append(allErrs, validateNotBefore(crt.Spec.NotBefore, time.Now(), field.NewPath("spec", "notBefore"))...)
+	}
 	w := validateAPIVersion(a.RequestKind)
+	w = append(w, validateNotAfterEncoding(&crt.Spec, time.Now())...)
 	return allErrs, w
 }
 
+// validateCSRSecretRef ensures that a Certificate using a supplied CSR does not
+// also set any of the fields that are instead taken from the CSR, or that
+// require cert-manager to manage the private key.
+func validateCSRSecretRef(crt *internalcmapi.CertificateSpec, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if crt.CSRSecretRef.Name == "" {
+		el = append(el, field.Required(fldPath.Child("csrSecretRef", "name"), "must be specified"))
+	}
+
+	const detail = "may not be set when csrSecretRef is specified"
+	if len(crt.CommonName) > 0 {
+		el = append(el, field.Forbidden(fldPath.Child("commonName"), detail))
+	}
+	if crt.Subject != nil {
+		el = append(el, field.Forbidden(fldPath.Child("subject"), detail))
+	}
+	if len(crt.DNSNames) > 0 {
+		el = append(el, field.Forbidden(fldPath.Child("dnsNames"), detail))
+	}
+	if len(crt.IPAddresses) > 0 {
+		el = append(el, field.Forbidden(fldPath.Child("ipAddresses"), detail))
+	}
+	if len(crt.URISANs) > 0 {
+		el = append(el, field.Forbidden(fldPath.Child("uris"), detail))
+	}
+	if len(crt.EmailSANs) > 0 {
+		el = append(el, field.Forbidden(fldPath.Child("emailAddresses"), detail))
+	}
+	if crt.PrivateKey != nil {
+		el = append(el, field.Forbidden(fldPath.Child("privateKey"), detail))
+	}
+	if crt.Keystores != nil {
+		el = append(el, field.Forbidden(fldPath.Child("keystores"), detail))
+	}
+	if crt.CSRSignatureAlgorithm != "" {
+		el = append(el, field.Forbidden(fldPath.Child("csrSignatureAlgorithm"), detail))
+	}
+	return el
+}
+
+// validateCSRSignatureAlgorithm ensures that the requested CSR signature
+// algorithm is supported and can be used with the private key algorithm.
+func validateCSRSignatureAlgorithm(crt *internalcmapi.CertificateSpec, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	keyAlgorithm := internalcmapi.RSAKeyAlgorithm
+	if crt.PrivateKey != nil && crt.PrivateKey.Algorithm != "" {
+		keyAlgorithm = crt.PrivateKey.Algorithm
+	}
+
+	var compatibleKeyAlgorithm internalcmapi.PrivateKeyAlgorithm
+	switch crt.CSRSignatureAlgorithm {
+	case internalcmapi.SHA256WithRSASignatureAlgorithm, internalcmapi.SHA384WithRSASignatureAlgorithm, internalcmapi.SHA512WithRSASignatureAlgorithm:
+		compatibleKeyAlgorithm = internalcmapi.RSAKeyAlgorithm
+	case internalcmapi.ECDSAWithSHA256SignatureAlgorithm, internalcmapi.ECDSAWithSHA384SignatureAlgorithm, internalcmapi.ECDSAWithSHA512SignatureAlgorithm:
+		compatibleKeyAlgorithm = internalcmapi.ECDSAKeyAlgorithm
+	default:
+		el = append(el, field.NotSupported(fldPath.Child("csrSignatureAlgorithm"), crt.CSRSignatureAlgorithm, supportedSignatureAlgorithms))
+		return el
+	}
+
+	if compatibleKeyAlgorithm != keyAlgorithm {
+		el = append(el, field.Invalid(fldPath.Child("csrSignatureAlgorithm"), crt.CSRSignatureAlgorithm, fmt.Sprintf("cannot be used with the %s private key algorithm", keyAlgorithm)))
+	}
+	return el
+}
+
+var supportedSignatureAlgorithms = []string{
+	string(internalcmapi.SHA256WithRSASignatureAlgorithm),
+	string(internalcmapi.SHA384WithRSASignatureAlgorithm),
+	string(internalcmapi.SHA512WithRSASignatureAlgorithm),
+	string(internalcmapi.ECDSAWithSHA256SignatureAlgorithm),
+	string(internalcmapi.ECDSAWithSHA384SignatureAlgorithm),
+	string(internalcmapi.ECDSAWithSHA512SignatureAlgorithm),
+}
+
+// validateSignatureAlgorithm ensures that the signature algorithm requested
+// for the issued certificate is supported. Whether it can be used with the
+// issuer's private key is only known when the certificate is signed.
+func validateSignatureAlgorithm(sigAlgo internalcmapi.SignatureAlgorithm, fldPath *field.Path) field.ErrorList {
+	if sigAlgo == "" {
+		return nil
+	}
+	for _, supported := range supportedSignatureAlgorithms {
+		if string(sigAlgo) == supported {
+			return nil
+		}
+	}
+	return field.ErrorList{field.NotSupported(fldPath, sigAlgo, supportedSignatureAlgorithms)}
+}
+
+// validateRenewalWindow ensures that the renewal window has at least one
+// valid time range and that its time zone is known.
+func validateRenewalWindow(window *internalcmapi.CertificateRenewalWindow, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if len(window.Ranges) == 0 {
+		el = append(el, field.Required(fldPath.Child("ranges"), "at least one range must be specified"))
+	}
+	for i, r := range window.Ranges {
+		if _, _, err := util.ParseTimeOfDayRange(r); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("ranges").Index(i), r, err.Error()))
+		}
+	}
+	if window.TimeZone != "" {
+		if _, err := time.LoadLocation(window.TimeZone); err != nil {
+			el = append(el, field.Invalid(fldPath.Child("timeZone"), window.TimeZone, "must be a valid IANA time zone name"))
+		}
+	}
+	return el
+}
+
+// validateNotBefore ensures that the requested notBefore is no further than
+// MaximumNotBeforeSkew in the past or future of now.
+func validateNotBefore(notBefore *metav1.Time, now time.Time, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if notBefore.Time.Before(now.Add(-cmapi.MaximumNotBeforeSkew)) || notBefore.Time.After(now.Add(cmapi.MaximumNotBeforeSkew)) {
+		el = append(el, field.Invalid(fldPath, notBefore.Time, fmt.Sprintf("must be no more than %s in the past or future", cmapi.MaximumNotBeforeSkew)))
+	}
+	return el
+}
+
+// utcTimeLimit is the first instant that cannot be encoded as an ASN.1
+// UTCTime. RFC 5280 requires certificate validity times from this point on
+// to be encoded as GeneralizedTime instead.
+var utcTimeLimit = time.Date(2050, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// generalizedTimeNotAfterWarning is the warning returned when a Certificate's
+// notAfter would be encoded as GeneralizedTime.
+const generalizedTimeNotAfterWarning = "spec.duration of %s results in a notAfter of %s, which is after 2049 and will " +
+	"be encoded as GeneralizedTime rather than UTCTime; some legacy certificate validators do not accept this"
+
+// validateNotAfterEncoding warns if the requested duration would result in a
+// certificate whose notAfter is encoded as GeneralizedTime rather than
+// UTCTime, as some legacy validators fail to parse GeneralizedTime. The
+// notAfter time is estimated from notBefore, or the given time if notBefore
+// is not set.
+func validateNotAfterEncoding(crt *internalcmapi.CertificateSpec, now time.Time) validation.WarningList {
+	duration := cmapi.DefaultCertificateDuration
+	if crt.Duration != nil {
+		duration = crt.Duration.Duration
+	}
+	notBefore := now
+	if crt.NotBefore != nil {
+		notBefore = crt.NotBefore.Time
+	}
+
+	notAfter := notBefore.Add(duration)
+	if notAfter.Before(utcTimeLimit) {
+		return nil
+	}
+	return validation.WarningList{fmt.Sprintf(generalizedTimeNotAfterWarning, duration, notAfter.UTC().Format(time.RFC3339))}
+}
+
 func validateIssuerRef(issuerRef cmmeta.ObjectReference, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
-	issuerRefPath := fldPath.Child("issuerRef")
 	if issuerRef.Name == "" {
-		el = append(el, field.Required(issuerRefPath.Child("name"), "must be specified"))
+		el = append(el, field.Required(fldPath.Child("name"), "must be specified"))
 	}
 	if issuerRef.Group == "" || issuerRef.Group == internalcmapi.SchemeGroupVersion.Group {
 		switch issuerRef.Kind {
 		case "":
 		case "Issuer", "ClusterIssuer":
 		default:
-			el = append(el, field.Invalid(issuerRefPath.Child("kind"), issuerRef.Kind, "must be one of Issuer or ClusterIssuer"))
+			el = append(el, field.Invalid(fldPath.Child("kind"), issuerRef.Kind, "must be one of Issuer or ClusterIssuer"))
 		}
 	}
 	return el
@@ -151,9 +385,29 @@ func validateEmailAddresses(a *internalcmapi.CertificateSpec, fldPath *field.Pat
 	return el
 }
 
-func validateUsages(a *internalcmapi.CertificateSpec, fldPath *field.Path) field.ErrorList {
+// validateOtherNames ensures that every otherName has a valid OID and a
+// non-empty UTF-8 value.
+func validateOtherNames(otherNames []internalcmapi.OtherName, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
-	for i, u := range a.Usages {
+	for i, name := range otherNames {
+		if _, err := pki.ParseObjectIdentifier(name.OID); err != nil {
+			el = append(el, field.Invalid(fldPath.Index(i).Child("oid"), name.OID, err.Error()))
+		}
+		if name.UTF8Value == "" {
+			el = append(el, field.Required(fldPath.Index(i).Child("utf8Value"), "must be specified"))
+		} else if !utf8.ValidString(name.UTF8Value) {
+			el = append(el, field.Invalid(fldPath.Index(i).Child("utf8Value"), name.UTF8Value, "must be valid UTF-8"))
+		}
+	}
+	return el
+}
+
+// validateUsages ensures that every usage is either a known key usage or a
+// known extended key usage, including 'any' (anyExtendedKeyUsage) and
+// 'ocsp signing' (id-kp-OCSPSigning).
+func validateUsages(usages []internalcmapi.KeyUsage, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	for i, u := range usages {
 		_, kok := util.KeyUsageType(cmapi.KeyUsage(u))
 		_, ekok := util.ExtKeyUsageType(cmapi.KeyUsage(u))
 		if !kok && !ekok {
@@ -163,7 +417,13 @@ func validateUsages(a *internalcmapi.CertificateSpec, fldPath *field.Path) field
 	return el
 }
 
-func ValidateDuration(crt *internalcmapi.CertificateSpec, fldPath *field.Path) field.ErrorList {
+// renewBeforeTooLongMessage is the message of the error returned when
+// renewBefore is not shorter than the certificate duration.
+const renewBeforeTooLongMessage = "certificate duration %s must be greater than renewBefore %s, otherwise the certificate would be renewed immediately after every issuance"
+
+// ValidateDuration ensures that the duration of the Certificate is at least
+// the given minimum, and that its renewBefore is valid for that duration.
+func ValidateDuration(crt *internalcmapi.CertificateSpec, minimumDuration time.Duration, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
 	duration := util.DefaultCertDuration(crt.Duration)
@@ -171,14 +431,91 @@ func ValidateDuration(crt *internalcmapi.CertificateSpec, fldPath *field.Path) f
 	if crt.RenewBefore != nil {
 		renewBefore = crt.RenewBefore.Duration
 	}
-	if duration < cmapi.MinimumCertificateDuration {
-		el = append(el, field.Invalid(fldPath.Child("duration"), duration, fmt.Sprintf("certificate duration must be greater than %s", cmapi.MinimumCertificateDuration)))
+	if duration <= 0 {
+		el = append(el, field.Invalid(fldPath.Child("duration"), duration, "certificate duration must be greater than zero"))
+	} else if duration < minimumDuration {
+		el = append(el, field.Invalid(fldPath.Child("duration"), duration, fmt.Sprintf("certificate duration must be greater than %s", minimumDuration)))
 	}
 	if renewBefore < cmapi.MinimumRenewBefore {
 		el = append(el, field.Invalid(fldPath.Child("renewBefore"), renewBefore, fmt.Sprintf("certificate renewBefore must be greater than %s", cmapi.MinimumRenewBefore)))
 	}
-	if duration <= renewBefore {
-		el = append(el, field.Invalid(fldPath.Child("renewBefore"), renewBefore, fmt.Sprintf("certificate duration %s must be greater than renewBefore %s", duration, renewBefore)))
+	// A renewBefore equal to or longer than the duration would cause the
+	// certificate to be renewed again as soon as it has been issued.
+	if duration > 0 && duration <= renewBefore {
+		el = append(el, field.Invalid(fldPath.Child("renewBefore"), renewBefore, fmt.Sprintf(renewBeforeTooLongMessage, duration, renewBefore)))
+	}
+	return el
+}
+
+// validateOCSPNoCheck ensures that the id-pkix-ocsp-nocheck extension is only
+// requested for OCSP responder certificates, i.e. together with the
+// "ocsp signing" usage.
+func validateOCSPNoCheck(annotations map[string]string, usages []internalcmapi.KeyUsage, fldPath *field.Path) field.ErrorList {
+	v, ok := annotations[internalcmapi.OCSPNoCheckAnnotationKey]
+	if !ok || v != "true" {
+		return nil
+	}
+	for _, u := range usages {
+		if u == internalcmapi.UsageOCSPSigning {
+			return nil
+		}
+	}
+	return field.ErrorList{field.Invalid(fldPath.Child(internalcmapi.OCSPNoCheckAnnotationKey), v,
+		fmt.Sprintf("may only be set if the %q usage is requested", internalcmapi.UsageOCSPSigning))}
+}
+
+// validateSecretKeys ensures that the key names configured for the
+// Certificate's Secret are valid Secret keys, are distinct, and can be stored
+// in a Secret of the requested type.
+func validateSecretKeys(crt *internalcmapi.CertificateSpec, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	names := []struct {
+		field, name, defaultName string
+	}{
+		{"privateKey", crt.SecretKeys.PrivateKey, corev1.TLSPrivateKeyKey},
+		{"certificate", crt.SecretKeys.Certificate, corev1.TLSCertKey},
+		{"ca", crt.SecretKeys.CA, cmmeta.TLSCAKey},
+	}
+	seen := make(map[string]bool)
+	for _, n := range names {
+		path := fldPath.Child(n.field)
+		name := n.name
+		if name == "" {
+			name = n.defaultName
+		}
+		for _, msg := range utilvalidation.IsConfigMapKey(name) {
+			el = append(el, field.Invalid(path, name, msg))
+		}
+		if seen[name] {
+			el = append(el, field.Duplicate(path, name))
+		}
+		seen[name] = true
+		if n.field != "ca" && name != n.defaultName && corev1.SecretType(crt.SecretType) == corev1.SecretTypeTLS {
+			el = append(el, field.Invalid(path, name, fmt.Sprintf("must be %q when secretType is %q", n.defaultName, corev1.SecretTypeTLS)))
+		}
+	}
+	return el
+}
+
+var supportedOutputFormats = []string{string(internalcmapi.CertificateOutputFormatChainPEM)}
+
+// validateAdditionalOutputFormats ensures that each additional output format
+// is supported and is requested at most once.
+func validateAdditionalOutputFormats(formats []internalcmapi.CertificateAdditionalOutputFormat, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	seen := make(map[internalcmapi.CertificateOutputFormatType]bool)
+	for i, f := range formats {
+		typePath := fldPath.Index(i).Child("type")
+		switch f.Type {
+		case internalcmapi.CertificateOutputFormatChainPEM:
+		default:
+			el = append(el, field.NotSupported(typePath, f.Type, supportedOutputFormats))
+			continue
+		}
+		if seen[f.Type] {
+			el = append(el, field.Duplicate(typePath, f.Type))
+		}
+		seen[f.Type] = true
 	}
 	return el
 }
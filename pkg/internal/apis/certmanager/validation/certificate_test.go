@@ -19,6 +19,7 @@ package validation
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -129,6 +130,37 @@ func TestValidateCertificate(t *testing.T) {
 				field.Invalid(fldPath.Child("issuerRef", "kind"), "invalid", "must be one of Issuer or ClusterIssuer"),
 			},
 		},
+		"valid with fallback issuerRefs": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					IssuerRefs: []cmmeta.ObjectReference{
+						{Name: "fallback", Kind: "ClusterIssuer"},
+					},
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid fallback issuerRefs": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					IssuerRefs: []cmmeta.ObjectReference{
+						validIssuerRef,
+						{Kind: "invalid"},
+					},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Required(fldPath.Child("issuerRefs").Index(1).Child("name"), "must be specified"),
+				field.Invalid(fldPath.Child("issuerRefs").Index(1).Child("kind"), "invalid", "must be one of Issuer or ClusterIssuer"),
+			},
+		},
 		"certificate missing secretName": {
 			cfg: &internalcmapi.Certificate{
 				Spec: internalcmapi.CertificateSpec{
@@ -150,7 +182,7 @@ func TestValidateCertificate(t *testing.T) {
 			},
 			a: someAdmissionRequest,
 			errs: []*field.Error{
-				field.Invalid(fldPath, "", "at least one of commonName, dnsNames, uris ipAddresses, or emailAddresses must be set"),
+				field.Invalid(fldPath, "", "at least one of commonName, dnsNames, uris ipAddresses, emailAddresses, or otherNames must be set"),
 			},
 		},
 		"certificate with no issuerRef": {
@@ -346,7 +378,7 @@ func TestValidateCertificate(t *testing.T) {
 			},
 			a: someAdmissionRequest,
 			errs: []*field.Error{
-				field.NotSupported(fldPath.Child("privateKey", "size"), 100, []string{"256", "384", "521"}),
+				field.Invalid(fldPath.Child("privateKey", "size"), 100, "must be one of 256 (P-256), 384 (P-384) or 521 (P-521) for ecdsa keyAlgorithm"),
 			},
 		},
 		"certificate with invalid keyAlgorithm": {
@@ -463,6 +495,17 @@ func TestValidateCertificate(t *testing.T) {
 			},
 			a: someAdmissionRequest,
 		},
+		"valid certificate with ocsp signing and any extended keyusage": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					Usages:     []internalcmapi.KeyUsage{"digital signature", "ocsp signing", "any"},
+				},
+			},
+			a: someAdmissionRequest,
+		},
 		"invalid certificate with nonexistent keyusage": {
 			cfg: &internalcmapi.Certificate{
 				Spec: internalcmapi.CertificateSpec{
@@ -538,6 +581,35 @@ func TestValidateCertificate(t *testing.T) {
 				field.Invalid(fldPath.Child("emailAddresses").Index(0), "mailto:alice@example.com", "invalid email address: mail: expected comma"),
 			},
 		},
+		"valid certificate with only a userPrincipalName otherName": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					OtherNames: []internalcmapi.OtherName{{OID: "1.3.6.1.4.1.311.20.2.3", UTF8Value: "alice@corp.example.com"}},
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with an otherName with an invalid oid and no value": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					OtherNames: []internalcmapi.OtherName{
+						{OID: "1.3.6.1.4.1.311.20.2.3", UTF8Value: "alice@corp.example.com"},
+						{OID: "upn", UTF8Value: "bob@corp.example.com"},
+						{OID: "1.3.6.1.4.1.311.20.2.3"},
+					},
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("otherNames").Index(1).Child("oid"), "upn", `invalid object identifier "upn": must contain at least two components`),
+				field.Required(fldPath.Child("otherNames").Index(2).Child("utf8Value"), "must be specified"),
+			},
+		},
 		"valid certificate with revision history limit == 1": {
 			cfg: &internalcmapi.Certificate{
 				Spec: internalcmapi.CertificateSpec{
@@ -563,6 +635,408 @@ func TestValidateCertificate(t *testing.T) {
 				field.Invalid(fldPath.Child("revisionHistoryLimit"), int32(0), "must not be less than 1"),
 			},
 		},
+		"valid certificate with Opaque secret type": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					SecretType: "Opaque",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with unsupported secret type": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					SecretType: "kubernetes.io/basic-auth",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("secretType"), "kubernetes.io/basic-auth", []string{"kubernetes.io/tls", "Opaque"}),
+			},
+		},
+		"valid OCSP responder certificate with the ocsp-no-check annotation": {
+			cfg: &internalcmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{internalcmapi.OCSPNoCheckAnnotationKey: "true"}},
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					Usages:     []internalcmapi.KeyUsage{internalcmapi.UsageDigitalSignature, internalcmapi.UsageOCSPSigning},
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with the ocsp-no-check annotation but without the ocsp signing usage": {
+			cfg: &internalcmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{internalcmapi.OCSPNoCheckAnnotationKey: "true"}},
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(field.NewPath("metadata", "annotations").Child(internalcmapi.OCSPNoCheckAnnotationKey), "true", `may only be set if the "ocsp signing" usage is requested`),
+			},
+		},
+		"valid certificate with custom secret key names": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					SecretKeys: &internalcmapi.CertificateSecretKeys{
+						PrivateKey:  "key.pem",
+						Certificate: "cert.pem",
+					},
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with invalid or duplicate secret key names": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					SecretKeys: &internalcmapi.CertificateSecretKeys{
+						PrivateKey:  "key/pem",
+						Certificate: "ca.crt",
+					},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("secretKeys", "privateKey"), "key/pem", "a valid config key must consist of alphanumeric characters, '-', '_' or '.' (e.g. 'key.name',  or 'KEY_NAME',  or 'key-name', regex used for validation is '[-._a-zA-Z0-9]+')"),
+				field.Duplicate(fldPath.Child("secretKeys", "ca"), "ca.crt"),
+			},
+		},
+		"invalid certificate with custom secret key names for a kubernetes.io/tls Secret": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					SecretType: "kubernetes.io/tls",
+					IssuerRef:  validIssuerRef,
+					SecretKeys: &internalcmapi.CertificateSecretKeys{
+						Certificate: "cert.pem",
+						CA:          "root.pem",
+					},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("secretKeys", "certificate"), "cert.pem", `must be "tls.crt" when secretType is "kubernetes.io/tls"`),
+			},
+		},
+		"valid certificate with ChainPEM additional output format": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					AdditionalOutputFormats: []internalcmapi.CertificateAdditionalOutputFormat{
+						{Type: internalcmapi.CertificateOutputFormatChainPEM},
+					},
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with unsupported or duplicate additional output formats": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					AdditionalOutputFormats: []internalcmapi.CertificateAdditionalOutputFormat{
+						{Type: internalcmapi.CertificateOutputFormatChainPEM},
+						{Type: "DER"},
+						{Type: internalcmapi.CertificateOutputFormatChainPEM},
+					},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("additionalOutputFormats").Index(1).Child("type"), internalcmapi.CertificateOutputFormatType("DER"), []string{"ChainPEM"}),
+				field.Duplicate(fldPath.Child("additionalOutputFormats").Index(2).Child("type"), internalcmapi.CertificateOutputFormatChainPEM),
+			},
+		},
+		"valid certificate with issuance timeout": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:      "abc",
+					SecretName:      "abc",
+					IssuerRef:       validIssuerRef,
+					IssuanceTimeout: &metav1.Duration{Duration: time.Hour},
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with issuance timeout of zero": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:      "abc",
+					SecretName:      "abc",
+					IssuerRef:       validIssuerRef,
+					IssuanceTimeout: &metav1.Duration{},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("issuanceTimeout"), time.Duration(0), "must be greater than zero"),
+			},
+		},
+		"valid certificate with renewal window": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					RenewalWindow: &internalcmapi.CertificateRenewalWindow{
+						Ranges:   []string{"22:00-06:00", "12:00-13:00"},
+						TimeZone: "Europe/London",
+					},
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with renewal window": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					RenewalWindow: &internalcmapi.CertificateRenewalWindow{
+						Ranges:   []string{"22:00-06:00", "25:00-26:00"},
+						TimeZone: "Not/AZone",
+					},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("renewalWindow", "ranges").Index(1), "25:00-26:00", `"25:00" is not a valid time of day of the form HH:MM`),
+				field.Invalid(fldPath.Child("renewalWindow", "timeZone"), "Not/AZone", "must be a valid IANA time zone name"),
+			},
+		},
+		"invalid certificate with renewal window without ranges": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:    "abc",
+					SecretName:    "abc",
+					IssuerRef:     validIssuerRef,
+					RenewalWindow: &internalcmapi.CertificateRenewalWindow{},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Required(fldPath.Child("renewalWindow", "ranges"), "at least one range must be specified"),
+			},
+		},
+		"certificate with trustAnchors without a Secret name": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:   "abc",
+					SecretName:   "abc",
+					IssuerRef:    validIssuerRef,
+					TrustAnchors: &cmmeta.SecretKeySelector{Key: "ca.crt"},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Required(fldPath.Child("trustAnchors", "name"), "must be specified"),
+			},
+		},
+		"valid CA certificate with maxPathLen": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					IsCA:       true,
+					MaxPathLen: int32Ptr(0),
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid leaf certificate with maxPathLen": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					MaxPathLen: int32Ptr(1),
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("maxPathLen"), "may only be set when isCA is true"),
+			},
+		},
+		"invalid CA certificate with negative maxPathLen": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					IsCA:       true,
+					MaxPathLen: int32Ptr(-1),
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("maxPathLen"), int32(-1), "must not be negative"),
+			},
+		},
+		"valid certificate with only csrSecretRef": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					CSRSecretRef: &cmmeta.SecretKeySelector{
+						LocalObjectReference: cmmeta.LocalObjectReference{Name: "csr"},
+					},
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with csrSecretRef missing name": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					SecretName:   "abc",
+					IssuerRef:    validIssuerRef,
+					CSRSecretRef: &cmmeta.SecretKeySelector{Key: "csr.pem"},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Required(fldPath.Child("csrSecretRef", "name"), "must be specified"),
+			},
+		},
+		"invalid certificate with csrSecretRef and fields taken from the CSR": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName: "abc",
+					DNSNames:   []string{"example.com"},
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					PrivateKey: &internalcmapi.CertificatePrivateKey{Algorithm: internalcmapi.ECDSAKeyAlgorithm},
+					CSRSecretRef: &cmmeta.SecretKeySelector{
+						LocalObjectReference: cmmeta.LocalObjectReference{Name: "csr"},
+					},
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("commonName"), "may not be set when csrSecretRef is specified"),
+				field.Forbidden(fldPath.Child("dnsNames"), "may not be set when csrSecretRef is specified"),
+				field.Forbidden(fldPath.Child("privateKey"), "may not be set when csrSecretRef is specified"),
+			},
+		},
+		"valid certificate with csrSignatureAlgorithm matching the default key algorithm": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:            "testcn",
+					SecretName:            "abc",
+					IssuerRef:             validIssuerRef,
+					CSRSignatureAlgorithm: internalcmapi.SHA384WithRSASignatureAlgorithm,
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"valid certificate with ecdsa keyAlgorithm and csrSignatureAlgorithm": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:            "testcn",
+					SecretName:            "abc",
+					IssuerRef:             validIssuerRef,
+					PrivateKey:            &internalcmapi.CertificatePrivateKey{Algorithm: internalcmapi.ECDSAKeyAlgorithm},
+					CSRSignatureAlgorithm: internalcmapi.ECDSAWithSHA512SignatureAlgorithm,
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with csrSignatureAlgorithm incompatible with the keyAlgorithm": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:            "testcn",
+					SecretName:            "abc",
+					IssuerRef:             validIssuerRef,
+					PrivateKey:            &internalcmapi.CertificatePrivateKey{Algorithm: internalcmapi.ECDSAKeyAlgorithm},
+					CSRSignatureAlgorithm: internalcmapi.SHA256WithRSASignatureAlgorithm,
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("csrSignatureAlgorithm"), internalcmapi.SHA256WithRSASignatureAlgorithm, "cannot be used with the ECDSA private key algorithm"),
+			},
+		},
+		"invalid certificate with unsupported csrSignatureAlgorithm": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:            "testcn",
+					SecretName:            "abc",
+					IssuerRef:             validIssuerRef,
+					CSRSignatureAlgorithm: internalcmapi.SignatureAlgorithm("MD5WithRSA"),
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("csrSignatureAlgorithm"), internalcmapi.SignatureAlgorithm("MD5WithRSA"), []string{
+					"SHA256WithRSA", "SHA384WithRSA", "SHA512WithRSA", "ECDSAWithSHA256", "ECDSAWithSHA384", "ECDSAWithSHA512",
+				}),
+			},
+		},
+		"valid certificate with signatureAlgorithm independent of the keyAlgorithm": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:         "testcn",
+					SecretName:         "abc",
+					IssuerRef:          validIssuerRef,
+					PrivateKey:         &internalcmapi.CertificatePrivateKey{Algorithm: internalcmapi.ECDSAKeyAlgorithm},
+					SignatureAlgorithm: internalcmapi.SHA384WithRSASignatureAlgorithm,
+				},
+			},
+			a: someAdmissionRequest,
+		},
+		"invalid certificate with unsupported signatureAlgorithm": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					CommonName:         "testcn",
+					SecretName:         "abc",
+					IssuerRef:          validIssuerRef,
+					SignatureAlgorithm: internalcmapi.SignatureAlgorithm("MD5WithRSA"),
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("signatureAlgorithm"), internalcmapi.SignatureAlgorithm("MD5WithRSA"), []string{
+					"SHA256WithRSA", "SHA384WithRSA", "SHA512WithRSA", "ECDSAWithSHA256", "ECDSAWithSHA384", "ECDSAWithSHA512",
+				}),
+			},
+		},
+		"invalid certificate with csrSecretRef and csrSignatureAlgorithm": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+					CSRSecretRef: &cmmeta.SecretKeySelector{
+						LocalObjectReference: cmmeta.LocalObjectReference{Name: "csr"},
+					},
+					CSRSignatureAlgorithm: internalcmapi.SHA256WithRSASignatureAlgorithm,
+				},
+			},
+			a: someAdmissionRequest,
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("csrSignatureAlgorithm"), "may not be set when csrSecretRef is specified"),
+			},
+		},
 		"v1alpha2 certificate created": {
 			cfg: &internalcmapi.Certificate{
 				Spec: internalcmapi.CertificateSpec{
@@ -629,7 +1103,7 @@ func TestValidateCertificate(t *testing.T) {
 	}
 	for n, s := range scenarios {
 		t.Run(n, func(t *testing.T) {
-			errs, warnings := ValidateCertificate(s.a, s.cfg)
+			errs, warnings := newCertificateValidator(Options{}).ValidateCertificate(s.a, s.cfg)
 			if len(errs) != len(s.errs) {
 				t.Errorf("Expected errors %v but got %v", s.errs, errs)
 				return
@@ -659,6 +1133,9 @@ func TestValidateDuration(t *testing.T) {
 		"ten minutes": {Duration: time.Minute * 10},
 		"half hour":   {Duration: time.Minute * 30},
 		"one hour":    {Duration: time.Hour},
+		"under hour":  {Duration: time.Hour - time.Second},
+		"zero":        {Duration: 0},
+		"negative":    {Duration: -time.Hour},
 		"one month":   {Duration: time.Hour * 24 * 30},
 		"half year":   {Duration: time.Hour * 24 * 180},
 		"one year":    {Duration: time.Hour * 24 * 365},
@@ -719,7 +1196,7 @@ func TestValidateDuration(t *testing.T) {
 					IssuerRef:   validIssuerRef,
 				},
 			},
-			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), usefulDurations["ten years"].Duration, fmt.Sprintf("certificate duration %s must be greater than renewBefore %s", cmapi.DefaultCertificateDuration, usefulDurations["ten years"].Duration))},
+			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), usefulDurations["ten years"].Duration, fmt.Sprintf(renewBeforeTooLongMessage, cmapi.DefaultCertificateDuration, usefulDurations["ten years"].Duration))},
 		},
 		"default renewBefore is bigger than the set duration": {
 			cfg: &internalcmapi.Certificate{
@@ -730,7 +1207,7 @@ func TestValidateDuration(t *testing.T) {
 					IssuerRef:  validIssuerRef,
 				},
 			},
-			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), cmapi.DefaultRenewBefore, fmt.Sprintf("certificate duration %s must be greater than renewBefore %s", usefulDurations["one hour"].Duration, cmapi.DefaultRenewBefore))},
+			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), cmapi.DefaultRenewBefore, fmt.Sprintf(renewBeforeTooLongMessage, usefulDurations["one hour"].Duration, cmapi.DefaultRenewBefore))},
 		},
 		"renewBefore is bigger than the duration": {
 			cfg: &internalcmapi.Certificate{
@@ -742,7 +1219,53 @@ func TestValidateDuration(t *testing.T) {
 					IssuerRef:   validIssuerRef,
 				},
 			},
-			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), usefulDurations["one year"].Duration, fmt.Sprintf("certificate duration %s must be greater than renewBefore %s", usefulDurations["one month"].Duration, usefulDurations["one year"].Duration))},
+			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), usefulDurations["one year"].Duration, fmt.Sprintf(renewBeforeTooLongMessage, usefulDurations["one month"].Duration, usefulDurations["one year"].Duration))},
+		},
+		"renewBefore is equal to the duration": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					Duration:    usefulDurations["one month"],
+					RenewBefore: usefulDurations["one month"],
+					CommonName:  "testcn",
+					SecretName:  "abc",
+					IssuerRef:   validIssuerRef,
+				},
+			},
+			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), usefulDurations["one month"].Duration, fmt.Sprintf(renewBeforeTooLongMessage, usefulDurations["one month"].Duration, usefulDurations["one month"].Duration))},
+		},
+		"renewBefore is one second shorter than the duration": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					Duration:    usefulDurations["one month"],
+					RenewBefore: &metav1.Duration{Duration: usefulDurations["one month"].Duration - time.Second},
+					CommonName:  "testcn",
+					SecretName:  "abc",
+					IssuerRef:   validIssuerRef,
+				},
+			},
+		},
+		"renewBefore is one second longer than the duration": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					Duration:    usefulDurations["one month"],
+					RenewBefore: &metav1.Duration{Duration: usefulDurations["one month"].Duration + time.Second},
+					CommonName:  "testcn",
+					SecretName:  "abc",
+					IssuerRef:   validIssuerRef,
+				},
+			},
+			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), usefulDurations["one month"].Duration+time.Second, fmt.Sprintf(renewBeforeTooLongMessage, usefulDurations["one month"].Duration, usefulDurations["one month"].Duration+time.Second))},
+		},
+		"renewBefore equal to the default duration": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					RenewBefore: &metav1.Duration{Duration: cmapi.DefaultCertificateDuration},
+					CommonName:  "testcn",
+					SecretName:  "abc",
+					IssuerRef:   validIssuerRef,
+				},
+			},
+			errs: []*field.Error{field.Invalid(fldPath.Child("renewBefore"), cmapi.DefaultCertificateDuration, fmt.Sprintf(renewBeforeTooLongMessage, cmapi.DefaultCertificateDuration, cmapi.DefaultCertificateDuration))},
 		},
 		"renewBefore is less than the minimum permitted value": {
 			cfg: &internalcmapi.Certificate{
@@ -767,10 +1290,137 @@ func TestValidateDuration(t *testing.T) {
 			},
 			errs: []*field.Error{field.Invalid(fldPath.Child("duration"), usefulDurations["half hour"].Duration, fmt.Sprintf("certificate duration must be greater than %s", cmapi.MinimumCertificateDuration))},
 		},
+		"duration is equal to the minimum permitted value": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					Duration:    usefulDurations["one hour"],
+					RenewBefore: usefulDurations["ten minutes"],
+					CommonName:  "testcn",
+					SecretName:  "abc",
+					IssuerRef:   validIssuerRef,
+				},
+			},
+		},
+		"duration is just less than the minimum permitted value": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					Duration:    usefulDurations["under hour"],
+					RenewBefore: usefulDurations["ten minutes"],
+					CommonName:  "testcn",
+					SecretName:  "abc",
+					IssuerRef:   validIssuerRef,
+				},
+			},
+			errs: []*field.Error{field.Invalid(fldPath.Child("duration"), usefulDurations["under hour"].Duration, fmt.Sprintf("certificate duration must be greater than %s", cmapi.MinimumCertificateDuration))},
+		},
+		"duration is zero": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					Duration:   usefulDurations["zero"],
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			errs: []*field.Error{field.Invalid(fldPath.Child("duration"), time.Duration(0), "certificate duration must be greater than zero")},
+		},
+		"duration is negative": {
+			cfg: &internalcmapi.Certificate{
+				Spec: internalcmapi.CertificateSpec{
+					Duration:   usefulDurations["negative"],
+					CommonName: "testcn",
+					SecretName: "abc",
+					IssuerRef:  validIssuerRef,
+				},
+			},
+			errs: []*field.Error{field.Invalid(fldPath.Child("duration"), -time.Hour, "certificate duration must be greater than zero")},
+		},
+	}
+	for n, s := range scenarios {
+		t.Run(n, func(t *testing.T) {
+			errs := ValidateDuration(&s.cfg.Spec, cmapi.MinimumCertificateDuration, fldPath)
+			if len(errs) != len(s.errs) {
+				t.Errorf("Expected %v but got %v", s.errs, errs)
+				return
+			}
+			for i, e := range errs {
+				expectedErr := s.errs[i]
+				if !reflect.DeepEqual(e, expectedErr) {
+					t.Errorf("Expected %v but got %v", expectedErr, e)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateDurationConfiguredMinimum(t *testing.T) {
+	fldPath := field.NewPath("spec")
+	tests := map[string]struct {
+		duration time.Duration
+		errs     field.ErrorList
+	}{
+		"duration longer than the configured minimum": {
+			duration: 25 * time.Hour,
+		},
+		"duration equal to the configured minimum": {
+			duration: 24 * time.Hour,
+		},
+		"duration shorter than the configured minimum": {
+			duration: 24*time.Hour - time.Second,
+			errs:     field.ErrorList{field.Invalid(fldPath.Child("duration"), 24*time.Hour-time.Second, "certificate duration must be greater than 24h0m0s")},
+		},
+		"duration accepted by the default minimum": {
+			duration: 2 * time.Hour,
+			errs:     field.ErrorList{field.Invalid(fldPath.Child("duration"), 2*time.Hour, "certificate duration must be greater than 24h0m0s")},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &internalcmapi.CertificateSpec{
+				Duration:    &metav1.Duration{Duration: test.duration},
+				RenewBefore: &metav1.Duration{Duration: time.Hour},
+			}
+			if test.errs == nil {
+				test.errs = field.ErrorList{}
+			}
+			errs := ValidateDuration(spec, 24*time.Hour, fldPath)
+			if !reflect.DeepEqual(test.errs, errs) {
+				t.Errorf("Expected %v but got %v", test.errs, errs)
+			}
+		})
+	}
+}
+
+func TestValidateNotBefore(t *testing.T) {
+	now := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	fldPath := field.NewPath("spec", "notBefore")
+	scenarios := map[string]struct {
+		notBefore metav1.Time
+		errs      []*field.Error
+	}{
+		"notBefore of now is valid": {
+			notBefore: metav1.NewTime(now),
+		},
+		"notBefore in the near past is valid": {
+			notBefore: metav1.NewTime(now.Add(-time.Hour * 24 * 30)),
+		},
+		"notBefore in the near future is valid": {
+			notBefore: metav1.NewTime(now.Add(time.Hour * 24 * 30)),
+		},
+		"notBefore too far in the past is invalid": {
+			notBefore: metav1.NewTime(now.Add(-cmapi.MaximumNotBeforeSkew - time.Hour)),
+			errs: []*field.Error{field.Invalid(fldPath, now.Add(-cmapi.MaximumNotBeforeSkew-time.Hour),
+				fmt.Sprintf("must be no more than %s in the past or future", cmapi.MaximumNotBeforeSkew))},
+		},
+		"notBefore too far in the future is invalid": {
+			notBefore: metav1.NewTime(now.Add(cmapi.MaximumNotBeforeSkew + time.Hour)),
+			errs: []*field.Error{field.Invalid(fldPath, now.Add(cmapi.MaximumNotBeforeSkew+time.Hour),
+				fmt.Sprintf("must be no more than %s in the past or future", cmapi.MaximumNotBeforeSkew))},
+		},
 	}
 	for n, s := range scenarios {
 		t.Run(n, func(t *testing.T) {
-			errs := ValidateDuration(&s.cfg.Spec, fldPath)
+			errs := validateNotBefore(&s.notBefore, now, fldPath)
 			if len(errs) != len(s.errs) {
 				t.Errorf("Expected %v but got %v", s.errs, errs)
 				return
@@ -784,3 +1434,101 @@ func TestValidateDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateNotAfterEncoding(t *testing.T) {
+	now := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	untilLimit := utcTimeLimit.Sub(now)
+	scenarios := map[string]struct {
+		duration  *metav1.Duration
+		notBefore *metav1.Time
+		warnings  validation.WarningList
+	}{
+		"default duration does not warn": {},
+		"duration ending before 2050 does not warn": {
+			duration: &metav1.Duration{Duration: untilLimit - time.Second},
+		},
+		"duration ending at the start of 2050 warns": {
+			duration: &metav1.Duration{Duration: untilLimit},
+			warnings: validation.WarningList{
+				fmt.Sprintf(generalizedTimeNotAfterWarning, untilLimit, "2050-01-01T00:00:00Z"),
+			},
+		},
+		"long duration ending after 2050 warns": {
+			duration: &metav1.Duration{Duration: time.Hour * 24 * 365 * 100},
+			warnings: validation.WarningList{
+				fmt.Sprintf(generalizedTimeNotAfterWarning, time.Hour*24*365*100, "2121-05-08T00:00:00Z"),
+			},
+		},
+		"notBefore is used as the start of the validity period if set": {
+			duration:  &metav1.Duration{Duration: untilLimit - time.Hour},
+			notBefore: &metav1.Time{Time: now.Add(2 * time.Hour)},
+			warnings: validation.WarningList{
+				fmt.Sprintf(generalizedTimeNotAfterWarning, untilLimit-time.Hour, "2050-01-01T01:00:00Z"),
+			},
+		},
+	}
+	for n, s := range scenarios {
+		t.Run(n, func(t *testing.T) {
+			warnings := validateNotAfterEncoding(&internalcmapi.CertificateSpec{
+				Duration:  s.duration,
+				NotBefore: s.notBefore,
+			}, now)
+			if !reflect.DeepEqual(warnings, s.warnings) {
+				t.Errorf("Expected warnings %v but got %v", s.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestValidateCertificateWarnsOnGeneralizedTimeNotAfter(t *testing.T) {
+	crt := &internalcmapi.Certificate{
+		Spec: internalcmapi.CertificateSpec{
+			CommonName: "testcn",
+			SecretName: "abc",
+			IssuerRef:  validIssuerRef,
+			Duration:   &metav1.Duration{Duration: time.Hour * 24 * 365 * 100},
+		},
+	}
+
+	v := newCertificateValidator(Options{})
+	errs, warnings := v.ValidateCertificate(someAdmissionRequest, crt)
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors but got %v", errs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "GeneralizedTime") {
+		t.Errorf("Expected a single GeneralizedTime warning on create but got %v", warnings)
+	}
+
+	errs, warnings = v.ValidateUpdateCertificate(someAdmissionRequest, crt, crt)
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors but got %v", errs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "GeneralizedTime") {
+		t.Errorf("Expected a single GeneralizedTime warning on update but got %v", warnings)
+	}
+}
+
+func TestValidateUpdateCertificateNotBefore(t *testing.T) {
+	stale := metav1.NewTime(time.Now().Add(-cmapi.MaximumNotBeforeSkew - time.Hour))
+	crt := &internalcmapi.Certificate{
+		Spec: internalcmapi.CertificateSpec{
+			CommonName: "testcn",
+			SecretName: "abc",
+			IssuerRef:  validIssuerRef,
+			NotBefore:  &stale,
+		},
+	}
+
+	v := newCertificateValidator(Options{})
+	errs, _ := v.ValidateUpdateCertificate(someAdmissionRequest, crt, crt)
+	if len(errs) != 0 {
+		t.Errorf("Expected no errors when notBefore is unchanged but got %v", errs)
+	}
+
+	oldCrt := crt.DeepCopy()
+	oldCrt.Spec.NotBefore = nil
+	errs, _ = v.ValidateUpdateCertificate(someAdmissionRequest, oldCrt, crt)
+	if len(errs) != 1 {
+		t.Errorf("Expected an error when notBefore is changed to an invalid value but got %v", errs)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/kr/pretty"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -30,6 +31,7 @@ import (
 
 	"github.com/jetstack/cert-manager/pkg/apis/acme"
 	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
+	cmapiv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
 	cmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
 	cmmeta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
@@ -42,6 +44,10 @@ var defaultInternalKeyUsages = []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cma
 func ValidateCertificateRequest(a *admissionv1.AdmissionRequest, obj runtime.Object) (field.ErrorList, validation.WarningList) {
 	cr := obj.(*cmapi.CertificateRequest)
 	allErrs := ValidateCertificateRequestSpec(&cr.Spec, field.NewPath("spec"), true)
+	allErrs = append(allErrs, validateOCSPNoCheck(cr.Annotations, cr.Spec.Usages, field.NewPath("metadata", "annotations"))...)
+	if cr.Spec.NotBefore != nil {
+		allErrs = append(allErrs, validateCertificateRequestNotBefore(&cr.Spec, time.Now(), field.NewPath("spec", "notBefore"))...)
+	}
 	allErrs = append(allErrs,
 		ValidateCertificateRequestApprovalCondition(cr.Status.Conditions, field.NewPath("status", "conditions"))...)
 
@@ -73,6 +79,21 @@ func ValidateUpdateCertificateRequest(a *admissionv1.AdmissionRequest, oldObj, n
 	return el, w
 }
 
+// validateCertificateRequestNotBefore ensures that the requested notBefore is
+// not too far in the past or future, and that the requested validity period
+// has not already ended.
+func validateCertificateRequestNotBefore(crSpec *cmapi.CertificateRequestSpec, now time.Time, fldPath *field.Path) field.ErrorList {
+	el := validateNotBefore(crSpec.NotBefore, now, fldPath)
+	duration := cmapiv1.DefaultCertificateDuration
+	if crSpec.Duration != nil {
+		duration = crSpec.Duration.Duration
+	}
+	if !crSpec.NotBefore.Add(duration).After(now) {
+		el = append(el, field.Invalid(fldPath, crSpec.NotBefore.Time, "the requested validity period has already ended"))
+	}
+	return el
+}
+
 func validateCertificateRequestAnnotations(objA, objB *cmapi.CertificateRequest, fieldPath *field.Path) field.ErrorList {
 	var el field.ErrorList
 	for k, v := range objA.Annotations {
@@ -90,7 +111,11 @@ func validateCertificateRequestAnnotations(objA, objB *cmapi.CertificateRequest,
 func ValidateCertificateRequestSpec(crSpec *cmapi.CertificateRequestSpec, fldPath *field.Path, validateCSRContent bool) field.ErrorList {
 	el := field.ErrorList{}
 
-	el = append(el, validateIssuerRef(crSpec.IssuerRef, fldPath)...)
+	el = append(el, validateIssuerRef(crSpec.IssuerRef, fldPath.Child("issuerRef"))...)
+	el = append(el, validateMaxPathLen(crSpec.IsCA, crSpec.MaxPathLen, fldPath.Child("maxPathLen"))...)
+	el = append(el, validateSignatureAlgorithm(crSpec.SignatureAlgorithm, fldPath.Child("signatureAlgorithm"))...)
+	usageErrs := validateUsages(crSpec.Usages, fldPath)
+	el = append(el, usageErrs...)
 
 	if len(crSpec.Request) == 0 {
 		el = append(el, field.Required(fldPath.Child("request"), "must be specified"))
@@ -99,8 +124,9 @@ func ValidateCertificateRequestSpec(crSpec *cmapi.CertificateRequestSpec, fldPat
 		if err != nil {
 			el = append(el, field.Invalid(fldPath.Child("request"), crSpec.Request, fmt.Sprintf("failed to decode csr: %s", err)))
 		} else {
-			// only compare usages if set on CR and in the CSR
-			if len(crSpec.Usages) > 0 && len(csr.Extensions) > 0 && validateCSRContent && !reflect.DeepEqual(crSpec.Usages, defaultInternalKeyUsages) {
+			// only compare usages if set on CR and in the CSR, and all of the
+			// usages on the CR are known
+			if len(usageErrs) == 0 && len(crSpec.Usages) > 0 && len(csr.Extensions) > 0 && validateCSRContent && !reflect.DeepEqual(crSpec.Usages, defaultInternalKeyUsages) {
 				if crSpec.IsCA {
 					crSpec.Usages = ensureCertSignIsSet(crSpec.Usages)
 				}
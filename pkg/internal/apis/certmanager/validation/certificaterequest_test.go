@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -566,6 +567,58 @@ func TestValidateCertificateRequest(t *testing.T) {
 			a:     someAdmissionRequest,
 			wantE: []*field.Error{},
 		},
+		"Test csr with the ocsp-no-check annotation without the ocsp signing usage": {
+			cr: &cminternal.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cminternal.OCSPNoCheckAnnotationKey: "true"}},
+				Spec: cminternal.CertificateRequestSpec{
+					Request:   mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"))),
+					IssuerRef: validIssuerRef,
+				},
+			},
+			a: someAdmissionRequest,
+			wantE: []*field.Error{
+				field.Invalid(field.NewPath("metadata", "annotations").Child(cminternal.OCSPNoCheckAnnotationKey), nil, `may only be set if the "ocsp signing" usage is requested`),
+			},
+		},
+		"Test csr with a notBefore in the recent past": {
+			cr: &cminternal.CertificateRequest{
+				Spec: cminternal.CertificateRequestSpec{
+					Request:   mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"))),
+					IssuerRef: validIssuerRef,
+					NotBefore: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+				},
+			},
+			a:     someAdmissionRequest,
+			wantE: []*field.Error{},
+		},
+		"Test csr with a notBefore whose validity period has already ended": {
+			cr: &cminternal.CertificateRequest{
+				Spec: cminternal.CertificateRequestSpec{
+					Request:   mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"))),
+					IssuerRef: validIssuerRef,
+					NotBefore: &metav1.Time{Time: time.Now().Add(-48 * time.Hour)},
+					Duration:  &metav1.Duration{Duration: 24 * time.Hour},
+				},
+			},
+			a: someAdmissionRequest,
+			wantE: []*field.Error{
+				field.Invalid(fldPath.Child("notBefore"), nil, "the requested validity period has already ended"),
+			},
+		},
+		"Test csr with a notBefore too far in the past": {
+			cr: &cminternal.CertificateRequest{
+				Spec: cminternal.CertificateRequestSpec{
+					Request:   mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"))),
+					IssuerRef: validIssuerRef,
+					NotBefore: &metav1.Time{Time: time.Now().Add(-2 * cmapi.MaximumNotBeforeSkew)},
+					Duration:  &metav1.Duration{Duration: 4 * cmapi.MaximumNotBeforeSkew},
+				},
+			},
+			a: someAdmissionRequest,
+			wantE: []*field.Error{
+				field.Invalid(fldPath.Child("notBefore"), nil, fmt.Sprintf("must be no more than %s in the past or future", cmapi.MaximumNotBeforeSkew)),
+			},
+		},
 		"Test csr with double signature usages": {
 			cr: &cminternal.CertificateRequest{
 				Spec: cminternal.CertificateRequestSpec{
@@ -623,6 +676,58 @@ func TestValidateCertificateRequest(t *testing.T) {
 			a:     someAdmissionRequest,
 			wantE: []*field.Error{},
 		},
+		"Test csr with ocsp signing and any extended usages": {
+			cr: &cminternal.CertificateRequest{
+				Spec: cminternal.CertificateRequestSpec{
+					Request:   mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"), gen.SetCertificateKeyUsages(cmapi.UsageDigitalSignature, cmapi.UsageOCSPSigning, cmapi.UsageAny))),
+					IssuerRef: validIssuerRef,
+					Usages:    []cminternal.KeyUsage{cminternal.UsageDigitalSignature, cminternal.UsageOCSPSigning, cminternal.UsageAny},
+				},
+			},
+			a:     someAdmissionRequest,
+			wantE: []*field.Error{},
+		},
+		"Error on csr with an unknown usage": {
+			cr: &cminternal.CertificateRequest{
+				Spec: cminternal.CertificateRequestSpec{
+					Request:   mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"))),
+					IssuerRef: validIssuerRef,
+					Usages:    []cminternal.KeyUsage{cminternal.UsageDigitalSignature, "nonexistent"},
+				},
+			},
+			a: someAdmissionRequest,
+			wantE: []*field.Error{
+				field.Invalid(fldPath.Child("usages").Index(1), nil, "unknown keyusage"),
+			},
+		},
+		"Error on csr that is not CA with maxPathLen set": {
+			cr: &cminternal.CertificateRequest{
+				Spec: cminternal.CertificateRequestSpec{
+					Request:    mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"))),
+					IssuerRef:  validIssuerRef,
+					MaxPathLen: int32Ptr(0),
+				},
+			},
+			a: someAdmissionRequest,
+			wantE: []*field.Error{
+				field.Forbidden(fldPath.Child("maxPathLen"), "may only be set when isCA is true"),
+			},
+		},
+		"Error on csr with an unsupported signatureAlgorithm": {
+			cr: &cminternal.CertificateRequest{
+				Spec: cminternal.CertificateRequestSpec{
+					Request:            mustGenerateCSR(t, gen.Certificate("test", gen.SetCertificateDNSNames("example.com"))),
+					IssuerRef:          validIssuerRef,
+					SignatureAlgorithm: cminternal.SignatureAlgorithm("MD5WithRSA"),
+				},
+			},
+			a: someAdmissionRequest,
+			wantE: []*field.Error{
+				field.NotSupported(fldPath.Child("signatureAlgorithm"), nil, []string{
+					"SHA256WithRSA", "SHA384WithRSA", "SHA512WithRSA", "ECDSAWithSHA256", "ECDSAWithSHA384", "ECDSAWithSHA512",
+				}),
+			},
+		},
 		"Error on csr not having all usages": {
 			cr: &cminternal.CertificateRequest{
 				Spec: cminternal.CertificateRequestSpec{
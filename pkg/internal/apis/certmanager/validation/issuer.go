@@ -19,11 +19,15 @@ package validation
 import (
 	"crypto/x509"
 	"fmt"
+	"net/url"
 	"strings"
+	"unicode"
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
@@ -31,6 +35,7 @@ import (
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/validation/util"
 	cmmeta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
+	"github.com/jetstack/cert-manager/pkg/util/pki"
 )
 
 // Validation functions for cert-manager Issuer types.
@@ -51,7 +56,43 @@ func ValidateUpdateIssuer(a *admissionv1.AdmissionRequest, oldObj, obj runtime.O
 }
 
 func ValidateIssuerSpec(iss *certmanager.IssuerSpec, fldPath *field.Path) (field.ErrorList, validation.WarningList) {
-	return ValidateIssuerConfig(&iss.IssuerConfig, fldPath)
+	el, warnings := ValidateIssuerConfig(&iss.IssuerConfig, fldPath)
+	el = append(el, validateAllowedDomains(iss.AllowedDomains, fldPath.Child("allowedDomains"))...)
+	if iss.SPIFFE != nil {
+		el = append(el, validateSPIFFEOptions(iss.SPIFFE, fldPath.Child("spiffe"))...)
+	}
+	return el, warnings
+}
+
+// validateSPIFFEOptions validates that the trust domain is set and only
+// contains the characters permitted in a SPIFFE trust domain: lowercase
+// letters, digits, dots, dashes and underscores.
+func validateSPIFFEOptions(spiffe *certmanager.SPIFFEOptions, fldPath *field.Path) field.ErrorList {
+	var el field.ErrorList
+	if len(spiffe.TrustDomain) == 0 {
+		return append(el, field.Required(fldPath.Child("trustDomain"), "must be set to the SPIFFE trust domain of the issuer"))
+	}
+	for _, r := range spiffe.TrustDomain {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			el = append(el, field.Invalid(fldPath.Child("trustDomain"), spiffe.TrustDomain,
+				"must only contain lowercase letters, digits, dots, dashes and underscores"))
+			break
+		}
+	}
+	return el
+}
+
+// validateAllowedDomains validates that each entry is a DNS name, optionally
+// prefixed with a "*." wildcard label.
+func validateAllowedDomains(allowedDomains []string, fldPath *field.Path) field.ErrorList {
+	var el field.ErrorList
+	for i, d := range allowedDomains {
+		domain := strings.TrimPrefix(strings.ToLower(strings.TrimSuffix(d, ".")), "*.")
+		if errs := utilvalidation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+			el = append(el, field.Invalid(fldPath.Index(i), d, "must be a DNS name, optionally prefixed with '*.' to only allow its subdomains"))
+		}
+	}
+	return el
 }
 
 func ValidateIssuerConfig(iss *certmanager.IssuerConfig, fldPath *field.Path) (field.ErrorList, validation.WarningList) {
@@ -129,6 +170,14 @@ func ValidateACMEIssuerConfig(iss *cmacme.ACMEIssuer, fldPath *field.Path) (fiel
 		}
 	}
 
+	if strings.IndexFunc(iss.UserAgent, unicode.IsControl) >= 0 {
+		el = append(el, field.Invalid(fldPath.Child("userAgent"), iss.UserAgent, "must not contain control characters"))
+	}
+
+	if iss.MaxChallengeAttempts != nil && *iss.MaxChallengeAttempts < 1 {
+		el = append(el, field.Invalid(fldPath.Child("maxChallengeAttempts"), *iss.MaxChallengeAttempts, "must be greater than zero"))
+	}
+
 	for i, sol := range iss.Solvers {
 		el = append(el, ValidateACMEIssuerChallengeSolverConfig(&sol, fldPath.Child("solvers").Index(i))...)
 	}
@@ -167,6 +216,14 @@ func ValidateACMEIssuerChallengeSolverHTTP01Config(http01 *cmacme.ACMEChallengeS
 		numDefined++
 		el = append(el, ValidateACMEIssuerChallengeSolverHTTP01IngressConfig(http01.Ingress, fldPath.Child("ingress"))...)
 	}
+	if http01.GatewayHTTPRoute != nil {
+		if numDefined > 0 {
+			el = append(el, field.Forbidden(fldPath.Child("gatewayHTTPRoute"), "may not specify more than one solver type in a single solver"))
+		} else {
+			numDefined++
+			el = append(el, ValidateACMEIssuerChallengeSolverHTTP01GatewayHTTPRouteConfig(http01.GatewayHTTPRoute, fldPath.Child("gatewayHTTPRoute"))...)
+		}
+	}
 	if numDefined == 0 {
 		el = append(el, field.Required(fldPath, "no HTTP01 solver type configured"))
 	}
@@ -174,11 +231,45 @@ func ValidateACMEIssuerChallengeSolverHTTP01Config(http01 *cmacme.ACMEChallengeS
 	return el
 }
 
+func ValidateACMEIssuerChallengeSolverHTTP01GatewayHTTPRouteConfig(route *cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+
+	if len(route.GatewayRef.Name) == 0 {
+		el = append(el, field.Required(fldPath.Child("gatewayRef", "name"), ""))
+	}
+	switch route.ServiceType {
+	case "", corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort:
+	default:
+		el = append(el, field.Invalid(fldPath.Child("serviceType"), route.ServiceType, `must be empty, "ClusterIP" or "NodePort"`))
+	}
+
+	return el
+}
+
 func ValidateACMEIssuerChallengeSolverHTTP01IngressConfig(ingress *cmacme.ACMEChallengeSolverHTTP01Ingress, fldPath *field.Path) field.ErrorList {
 	el := field.ErrorList{}
 
-	if ingress.Class != nil && len(ingress.Name) > 0 {
-		el = append(el, field.Forbidden(fldPath, "only one of 'name' or 'class' should be specified"))
+	numDefined := 0
+	if ingress.Class != nil {
+		numDefined++
+	}
+	if ingress.IngressClassName != nil {
+		numDefined++
+	}
+	if len(ingress.Name) > 0 {
+		numDefined++
+	}
+	if numDefined > 1 {
+		el = append(el, field.Forbidden(fldPath, "only one of 'name', 'class' or 'ingressClassName' should be specified"))
+	}
+	if ingress.PathType != nil {
+		switch *ingress.PathType {
+		case networkingv1.PathTypeExact, networkingv1.PathTypePrefix, networkingv1.PathTypeImplementationSpecific:
+		default:
+			el = append(el, field.NotSupported(fldPath.Child("pathType"), *ingress.PathType, []string{
+				string(networkingv1.PathTypeExact), string(networkingv1.PathTypePrefix), string(networkingv1.PathTypeImplementationSpecific),
+			}))
+		}
 	}
 	switch ingress.ServiceType {
 	case "", corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort:
@@ -199,11 +290,93 @@ func ValidateCAIssuerConfig(iss *certmanager.CAIssuer, fldPath *field.Path) fiel
 			el = append(el, field.Invalid(fldPath.Child("ocspServer").Index(i), ocspURL, "must be a valid URL, e.g., http://ocsp.int-x3.letsencrypt.org"))
 		}
 	}
+	el = append(el, ValidateCertificatePolicies(iss.PolicyIdentifiers, fldPath.Child("policyIdentifiers"))...)
+	if iss.InhibitAnyPolicy != nil && *iss.InhibitAnyPolicy < 0 {
+		el = append(el, field.Invalid(fldPath.Child("inhibitAnyPolicy"), *iss.InhibitAnyPolicy, "must not be negative"))
+	}
+	if iss.PolicyConstraints != nil {
+		el = append(el, ValidatePolicyConstraints(iss.PolicyConstraints, fldPath.Child("policyConstraints"))...)
+	}
+	el = append(el, ValidateCustomExtensionPolicies(iss.AllowedCustomExtensions, fldPath.Child("allowedCustomExtensions"))...)
+	el = append(el, ValidateCustomExtensionPolicies(iss.AllowedCSRExtensions, fldPath.Child("allowedCSRExtensions"))...)
+	switch iss.SubjectKeyIdentifierMethod {
+	case "", certmanager.SubjectKeyIdentifierMethod1, certmanager.SubjectKeyIdentifierMethod2:
+	default:
+		el = append(el, field.NotSupported(fldPath.Child("subjectKeyIdentifierMethod"), iss.SubjectKeyIdentifierMethod,
+			[]string{string(certmanager.SubjectKeyIdentifierMethod1), string(certmanager.SubjectKeyIdentifierMethod2)}))
+	}
+	return el
+}
+
+// ValidatePolicyConstraints validates that at least one policy constraint is
+// set, as the policyConstraints extension must not be empty, and that the
+// constraints that are set are not negative.
+func ValidatePolicyConstraints(constraints *certmanager.PolicyConstraints, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if constraints.RequireExplicitPolicy == nil && constraints.InhibitPolicyMapping == nil {
+		el = append(el, field.Required(fldPath, "at least one of requireExplicitPolicy or inhibitPolicyMapping must be set"))
+	}
+	if v := constraints.RequireExplicitPolicy; v != nil && *v < 0 {
+		el = append(el, field.Invalid(fldPath.Child("requireExplicitPolicy"), *v, "must not be negative"))
+	}
+	if v := constraints.InhibitPolicyMapping; v != nil && *v < 0 {
+		el = append(el, field.Invalid(fldPath.Child("inhibitPolicyMapping"), *v, "must not be negative"))
+	}
+	return el
+}
+
+// ValidateCertificatePolicies validates that each certificate policy has a
+// valid, unique OID and that its CPS URIs are absolute ASCII URIs, as they
+// are encoded as IA5Strings.
+func ValidateCertificatePolicies(policies []certmanager.CertificatePolicy, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	seen := make(map[string]bool)
+	for i, policy := range policies {
+		if _, err := pki.ParseObjectIdentifier(policy.OID); err != nil {
+			el = append(el, field.Invalid(fldPath.Index(i).Child("oid"), policy.OID, err.Error()))
+		} else if seen[policy.OID] {
+			el = append(el, field.Duplicate(fldPath.Index(i).Child("oid"), policy.OID))
+		}
+		seen[policy.OID] = true
+
+		for j, uri := range policy.CPSURIs {
+			if u, err := url.Parse(uri); err != nil || !u.IsAbs() || !isASCII(uri) {
+				el = append(el, field.Invalid(fldPath.Index(i).Child("cpsURIs").Index(j), uri, "must be an absolute URI containing only ASCII characters"))
+			}
+		}
+	}
 	return el
 }
 
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
 func ValidateSelfSignedIssuerConfig(iss *certmanager.SelfSignedIssuer, fldPath *field.Path) field.ErrorList {
-	return nil
+	el := ValidateCustomExtensionPolicies(iss.AllowedCustomExtensions, fldPath.Child("allowedCustomExtensions"))
+	el = append(el, ValidateCustomExtensionPolicies(iss.AllowedCSRExtensions, fldPath.Child("allowedCSRExtensions"))...)
+	return el
+}
+
+func ValidateCustomExtensionPolicies(policies []certmanager.CustomExtensionPolicy, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	seen := make(map[string]bool)
+	for i, policy := range policies {
+		if _, err := pki.ParseObjectIdentifier(policy.OID); err != nil {
+			el = append(el, field.Invalid(fldPath.Index(i).Child("oid"), policy.OID, err.Error()))
+			continue
+		}
+		if seen[policy.OID] {
+			el = append(el, field.Duplicate(fldPath.Index(i).Child("oid"), policy.OID))
+		}
+		seen[policy.OID] = true
+	}
+	return el
 }
 
 func ValidateVaultIssuerConfig(iss *certmanager.VaultIssuer, fldPath *field.Path) field.ErrorList {
@@ -300,16 +473,29 @@ func ValidateACMEChallengeSolverDNS01(p *cmacme.ACMEChallengeSolverDNS01, fldPat
 			el = append(el, field.Forbidden(fldPath.Child("azureDNS"), "may not specify more than one provider type"))
 		} else {
 			numProviders++
-			// if ClientID or ClientSecret or TenantID are defined then all of ClientID, ClientSecret and tenantID must be defined
+			// if WorkloadIdentity is defined then ClientSecret must not be, as
+			// the federated token is used instead. ClientID and TenantID are
+			// optional as they may be injected by the workload identity webhook.
+			// Otherwise, if ClientID or ClientSecret or TenantID are defined then all of ClientID, ClientSecret and tenantID must be defined
 			// We check things separately because
-			if len(p.AzureDNS.ClientID) > 0 || len(p.AzureDNS.TenantID) > 0 || p.AzureDNS.ClientSecret != nil {
+			if p.AzureDNS.WorkloadIdentity != nil {
+				if p.AzureDNS.ClientSecret != nil {
+					el = append(el, field.Forbidden(fldPath.Child("azureDNS", "clientSecretSecretRef"), "may not be set when workloadIdentity is specified"))
+				}
+				if len(p.AzureDNS.ClientSecretFile) > 0 {
+					el = append(el, field.Forbidden(fldPath.Child("azureDNS", "clientSecretFile"), "may not be set when workloadIdentity is specified"))
+				}
+			} else if len(p.AzureDNS.ClientID) > 0 || len(p.AzureDNS.TenantID) > 0 || p.AzureDNS.ClientSecret != nil || len(p.AzureDNS.ClientSecretFile) > 0 {
 				if len(p.AzureDNS.ClientID) == 0 {
 					el = append(el, field.Required(fldPath.Child("azureDNS", "clientID"), ""))
 				}
-				if p.AzureDNS.ClientSecret == nil {
-					el = append(el, field.Required(fldPath.Child("azureDNS", "clientSecretSecretRef"), ""))
-				} else {
+				switch {
+				case p.AzureDNS.ClientSecret != nil && len(p.AzureDNS.ClientSecretFile) > 0:
+					el = append(el, field.Forbidden(fldPath.Child("azureDNS", "clientSecretFile"), "may not be set when clientSecretSecretRef is specified"))
+				case p.AzureDNS.ClientSecret != nil:
 					el = append(el, ValidateSecretKeySelector(p.AzureDNS.ClientSecret, fldPath.Child("azureDNS", "clientSecretSecretRef"))...)
+				case len(p.AzureDNS.ClientSecretFile) == 0:
+					el = append(el, field.Required(fldPath.Child("azureDNS", "clientSecretSecretRef"), ""))
 				}
 				if len(p.AzureDNS.TenantID) == 0 {
 					el = append(el, field.Required(fldPath.Child("azureDNS", "tenantID"), ""))
@@ -340,6 +526,9 @@ func ValidateACMEChallengeSolverDNS01(p *cmacme.ACMEChallengeSolverDNS01, fldPat
 			// selector
 			if p.CloudDNS.ServiceAccount != nil {
 				el = append(el, ValidateSecretKeySelector(p.CloudDNS.ServiceAccount, fldPath.Child("cloudDNS", "serviceAccountSecretRef"))...)
+				if len(p.CloudDNS.ServiceAccountFile) > 0 {
+					el = append(el, field.Forbidden(fldPath.Child("cloudDNS", "serviceAccountFile"), "may not be set when serviceAccountSecretRef is specified"))
+				}
 			}
 			if len(p.CloudDNS.Project) == 0 {
 				el = append(el, field.Required(fldPath.Child("cloudDNS", "project"), ""))
@@ -377,6 +566,12 @@ func ValidateACMEChallengeSolverDNS01(p *cmacme.ACMEChallengeSolverDNS01, fldPat
 			if len(p.Route53.Region) == 0 {
 				el = append(el, field.Required(fldPath.Child("route53", "region"), ""))
 			}
+			if len(p.Route53.ExternalID) > 0 && len(p.Route53.Role) == 0 {
+				el = append(el, field.Forbidden(fldPath.Child("route53", "externalID"), "may only be specified when role is set"))
+			}
+			if len(p.Route53.SecretAccessKeyFile) > 0 && len(p.Route53.SecretAccessKey.Name) > 0 {
+				el = append(el, field.Forbidden(fldPath.Child("route53", "secretAccessKeyFile"), "may not be set when secretAccessKeySecretRef is specified"))
+			}
 		}
 	}
 	if p.AcmeDNS != nil {
@@ -439,12 +634,38 @@ func ValidateACMEChallengeSolverDNS01(p *cmacme.ACMEChallengeSolverDNS01, fldPat
 			if len(p.Webhook.SolverName) == 0 {
 				el = append(el, field.Required(fldPath.Child("webhook", "solverName"), "solver name must be specified"))
 			}
+			if p.Webhook.TLS != nil {
+				el = append(el, validateACMEIssuerDNS01ProviderWebhookTLS(p.Webhook.TLS, fldPath.Child("webhook", "tls"))...)
+			}
 		}
 	}
 	if numProviders == 0 {
 		el = append(el, field.Required(fldPath, "no DNS01 provider configured"))
 	}
 
+	for i := range p.AdditionalProviders {
+		additional := &p.AdditionalProviders[i]
+		additionalPath := fldPath.Child("additionalProviders").Index(i)
+		if len(additional.AdditionalProviders) > 0 {
+			el = append(el, field.Forbidden(additionalPath.Child("additionalProviders"), "additional providers may not be nested"))
+			continue
+		}
+		el = append(el, ValidateACMEChallengeSolverDNS01(additional, additionalPath)...)
+	}
+
+	return el
+}
+
+func validateACMEIssuerDNS01ProviderWebhookTLS(tlsCfg *cmacme.ACMEIssuerDNS01ProviderWebhookTLS, fldPath *field.Path) field.ErrorList {
+	el := field.ErrorList{}
+	if len(tlsCfg.URL) == 0 {
+		el = append(el, field.Required(fldPath.Child("url"), "the webhook solver URL is required"))
+	} else if u, err := url.Parse(tlsCfg.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		el = append(el, field.Invalid(fldPath.Child("url"), tlsCfg.URL, "must be an absolute https URL"))
+	}
+	if len(tlsCfg.ClientCertSecretRef.Name) == 0 {
+		el = append(el, field.Required(fldPath.Child("clientCertSecretRef", "name"), "secret name is required"))
+	}
 	return el
 }
 
@@ -25,6 +25,7 @@ import (
 
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
@@ -129,6 +130,44 @@ func TestValidateACMEIssuerConfig(t *testing.T) {
 				field.Required(fldPath.Child("server"), "acme server URL is a required field"),
 			},
 		},
+		"acme issuer with valid maxChallengeAttempts": {
+			spec: &cmacme.ACMEIssuer{
+				Email:                "valid-email",
+				Server:               "valid-server",
+				PrivateKey:           validSecretKeyRef,
+				MaxChallengeAttempts: int32Ptr(3),
+			},
+		},
+		"acme issuer with maxChallengeAttempts of zero": {
+			spec: &cmacme.ACMEIssuer{
+				Email:                "valid-email",
+				Server:               "valid-server",
+				PrivateKey:           validSecretKeyRef,
+				MaxChallengeAttempts: int32Ptr(0),
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("maxChallengeAttempts"), int32(0), "must be greater than zero"),
+			},
+		},
+		"acme issuer with a valid userAgent": {
+			spec: &cmacme.ACMEIssuer{
+				Email:      "valid-email",
+				Server:     "valid-server",
+				PrivateKey: validSecretKeyRef,
+				UserAgent:  "corp-proxy-allowed/1.0 (+https://example.com)",
+			},
+		},
+		"acme issuer with a userAgent containing control characters": {
+			spec: &cmacme.ACMEIssuer{
+				Email:      "valid-email",
+				Server:     "valid-server",
+				PrivateKey: validSecretKeyRef,
+				UserAgent:  "agent/1.0\r\nX-Injected: true",
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("userAgent"), "agent/1.0\r\nX-Injected: true", "must not contain control characters"),
+			},
+		},
 		"acme solver without any config": {
 			spec: &cmacme.ACMEIssuer{
 				Email:      "valid-email",
@@ -368,6 +407,30 @@ func TestValidateIssuerSpec(t *testing.T) {
 			},
 			errs: []*field.Error{field.Required(fldPath.Child("ca", "secretName"), "")},
 		},
+		"valid ca issuer with subject key identifier method": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName:                 "valid",
+						SubjectKeyIdentifierMethod: cmapi.SubjectKeyIdentifierMethod2,
+					},
+				},
+			},
+			errs: []*field.Error{},
+		},
+		"ca issuer with unsupported subject key identifier method": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName:                 "valid",
+						SubjectKeyIdentifierMethod: "RFC5280Method3",
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("ca", "subjectKeyIdentifierMethod"), cmapi.SubjectKeyIdentifierMethod("RFC5280Method3"), []string{"RFC5280Method1", "RFC5280Method2"}),
+			},
+		},
 		"valid self signed issuer": {
 			spec: &cmapi.IssuerSpec{
 				IssuerConfig: cmapi.IssuerConfig{
@@ -376,6 +439,154 @@ func TestValidateIssuerSpec(t *testing.T) {
 			},
 			errs: []*field.Error{},
 		},
+		"valid custom extension policies": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName: "valid",
+						AllowedCustomExtensions: []cmapi.CustomExtensionPolicy{
+							{OID: "1.3.6.1.4.1.311.21.7"},
+							{OID: "1.2.3.4", AllowCritical: true},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{},
+		},
+		"invalid custom extension policy oid": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{
+						AllowedCustomExtensions: []cmapi.CustomExtensionPolicy{
+							{OID: "not-an-oid"},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("selfSigned", "allowedCustomExtensions").Index(0).Child("oid"), "not-an-oid", `invalid object identifier "not-an-oid": must contain at least two components`),
+			},
+		},
+		"duplicate custom extension policy oid": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName: "valid",
+						AllowedCustomExtensions: []cmapi.CustomExtensionPolicy{
+							{OID: "1.2.3.4"},
+							{OID: "1.2.3.4", AllowCritical: true},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Duplicate(fldPath.Child("ca", "allowedCustomExtensions").Index(1).Child("oid"), "1.2.3.4"),
+			},
+		},
+		"invalid CSR extension policy oid": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{
+						AllowedCSRExtensions: []cmapi.CustomExtensionPolicy{
+							{OID: "not-an-oid"},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("selfSigned", "allowedCSRExtensions").Index(0).Child("oid"), "not-an-oid", `invalid object identifier "not-an-oid": must contain at least two components`),
+			},
+		},
+		"duplicate CSR extension policy oid": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName: "valid",
+						AllowedCSRExtensions: []cmapi.CustomExtensionPolicy{
+							{OID: "1.2.3.4"},
+							{OID: "1.2.3.4", AllowCritical: true},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Duplicate(fldPath.Child("ca", "allowedCSRExtensions").Index(1).Child("oid"), "1.2.3.4"),
+			},
+		},
+		"valid CA issuer policy identifiers": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName: "valid",
+						PolicyIdentifiers: []cmapi.CertificatePolicy{
+							{OID: "2.23.140.1.2.1"},
+							{OID: "1.2.3.4", CPSURIs: []string{"http://example.com/cps"}},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{},
+		},
+		"invalid CA issuer policy identifiers": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName: "valid",
+						PolicyIdentifiers: []cmapi.CertificatePolicy{
+							{OID: "not-an-oid"},
+							{OID: "1.2.3.4", CPSURIs: []string{"/relative/cps", "http://exämple.com/cps"}},
+							{OID: "1.2.3.4"},
+						},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ca", "policyIdentifiers").Index(0).Child("oid"), "not-an-oid", `invalid object identifier "not-an-oid": must contain at least two components`),
+				field.Invalid(fldPath.Child("ca", "policyIdentifiers").Index(1).Child("cpsURIs").Index(0), "/relative/cps", "must be an absolute URI containing only ASCII characters"),
+				field.Invalid(fldPath.Child("ca", "policyIdentifiers").Index(1).Child("cpsURIs").Index(1), "http://exämple.com/cps", "must be an absolute URI containing only ASCII characters"),
+				field.Duplicate(fldPath.Child("ca", "policyIdentifiers").Index(2).Child("oid"), "1.2.3.4"),
+			},
+		},
+		"valid CA issuer policy constraints": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName:        "valid",
+						InhibitAnyPolicy:  int32Ptr(0),
+						PolicyConstraints: &cmapi.PolicyConstraints{RequireExplicitPolicy: int32Ptr(0)},
+					},
+				},
+			},
+			errs: []*field.Error{},
+		},
+		"invalid CA issuer policy constraints": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName:        "valid",
+						InhibitAnyPolicy:  int32Ptr(-1),
+						PolicyConstraints: &cmapi.PolicyConstraints{InhibitPolicyMapping: int32Ptr(-2)},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("ca", "inhibitAnyPolicy"), int32(-1), "must not be negative"),
+				field.Invalid(fldPath.Child("ca", "policyConstraints", "inhibitPolicyMapping"), int32(-2), "must not be negative"),
+			},
+		},
+		"empty CA issuer policy constraints": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					CA: &cmapi.CAIssuer{
+						SecretName:        "valid",
+						PolicyConstraints: &cmapi.PolicyConstraints{},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("ca", "policyConstraints"), "at least one of requireExplicitPolicy or inhibitPolicyMapping must be set"),
+			},
+		},
 		"valid acme issuer": {
 			spec: &cmapi.IssuerSpec{
 				IssuerConfig: cmapi.IssuerConfig{
@@ -437,6 +648,58 @@ func TestValidateIssuerSpec(t *testing.T) {
 				field.Invalid(fldPath.Child("ca", "ocspServer").Index(0), "", `must be a valid URL, e.g., http://ocsp.int-x3.letsencrypt.org`),
 			},
 		},
+		"valid allowed domains": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{},
+				},
+				AllowedDomains: []string{"example.com", "*.internal.example.com", "Example.org."},
+			},
+			errs: []*field.Error{},
+		},
+		"invalid allowed domains": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{},
+				},
+				AllowedDomains: []string{"", "foo.*.example.com", "example.com"},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("allowedDomains").Index(0), "", "must be a DNS name, optionally prefixed with '*.' to only allow its subdomains"),
+				field.Invalid(fldPath.Child("allowedDomains").Index(1), "foo.*.example.com", "must be a DNS name, optionally prefixed with '*.' to only allow its subdomains"),
+			},
+		},
+		"valid SPIFFE trust domain": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{},
+				},
+				SPIFFE: &cmapi.SPIFFEOptions{TrustDomain: "prod_1.example-org.com"},
+			},
+			errs: []*field.Error{},
+		},
+		"missing SPIFFE trust domain": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{},
+				},
+				SPIFFE: &cmapi.SPIFFEOptions{},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("spiffe", "trustDomain"), "must be set to the SPIFFE trust domain of the issuer"),
+			},
+		},
+		"invalid SPIFFE trust domain": {
+			spec: &cmapi.IssuerSpec{
+				IssuerConfig: cmapi.IssuerConfig{
+					SelfSigned: &cmapi.SelfSignedIssuer{},
+				},
+				SPIFFE: &cmapi.SPIFFEOptions{TrustDomain: "Example.org:8443"},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("spiffe", "trustDomain"), "Example.org:8443", "must only contain lowercase letters, digits, dots, dashes and underscores"),
+			},
+		},
 	}
 	for n, s := range scenarios {
 		t.Run(n, func(t *testing.T) {
@@ -483,7 +746,77 @@ func TestValidateACMEIssuerHTTP01Config(t *testing.T) {
 				},
 			},
 			errs: []*field.Error{
-				field.Forbidden(fldPath.Child("ingress"), "only one of 'name' or 'class' should be specified"),
+				field.Forbidden(fldPath.Child("ingress"), "only one of 'name', 'class' or 'ingressClassName' should be specified"),
+			},
+		},
+		"ingress class name field specified": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{IngressClassName: strPtr("abc")},
+			},
+		},
+		"both ingress class and ingress class name fields specified": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+					Class:            strPtr("abc"),
+					IngressClassName: strPtr("abc"),
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("ingress"), "only one of 'name', 'class' or 'ingressClassName' should be specified"),
+			},
+		},
+		"gateway HTTPRoute solver specified": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				GatewayHTTPRoute: &cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute{
+					GatewayRef: cmacme.ACMEChallengeSolverHTTP01GatewayReference{Name: "gateway"},
+				},
+			},
+		},
+		"gateway HTTPRoute solver without a gateway name": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				GatewayHTTPRoute: &cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute{},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("gatewayHTTPRoute", "gatewayRef", "name"), ""),
+			},
+		},
+		"gateway HTTPRoute solver with an invalid serviceType": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				GatewayHTTPRoute: &cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute{
+					ServiceType: corev1.ServiceType("InvalidServiceType"),
+					GatewayRef:  cmacme.ACMEChallengeSolverHTTP01GatewayReference{Name: "gateway"},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("gatewayHTTPRoute", "serviceType"), corev1.ServiceType("InvalidServiceType"), `must be empty, "ClusterIP" or "NodePort"`),
+			},
+		},
+		"both ingress and gateway HTTPRoute solvers specified": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{},
+				GatewayHTTPRoute: &cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute{
+					GatewayRef: cmacme.ACMEChallengeSolverHTTP01GatewayReference{Name: "gateway"},
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("gatewayHTTPRoute"), "may not specify more than one solver type in a single solver"),
+			},
+		},
+		"acme issuer with valid http01 ingress pathType": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+					PathType: pathTypePtr(networkingv1.PathTypeExact),
+				},
+			},
+		},
+		"acme issuer with invalid http01 ingress pathType": {
+			cfg: &cmacme.ACMEChallengeSolverHTTP01{
+				Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+					PathType: pathTypePtr("Invalid"),
+				},
+			},
+			errs: []*field.Error{
+				field.NotSupported(fldPath.Child("ingress", "pathType"), networkingv1.PathType("Invalid"), []string{"Exact", "Prefix", "ImplementationSpecific"}),
 			},
 		},
 		"acme issuer with valid http01 service config serviceType ClusterIP": {
@@ -650,6 +983,26 @@ func TestValidateACMEIssuerDNS01Config(t *testing.T) {
 				field.Required(fldPath.Child("route53", "region"), ""),
 			},
 		},
+		"route53 externalID without role": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
+					Region:     "us-west-2",
+					ExternalID: "my-external-id",
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("route53", "externalID"), "may only be specified when role is set"),
+			},
+		},
+		"valid route53 role with externalID": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
+					Region:     "us-west-2",
+					Role:       "arn:aws:iam::123456789012:role/dns",
+					ExternalID: "my-external-id",
+				},
+			},
+		},
 		"missing provider config": {
 			cfg: &cmacme.ACMEChallengeSolverDNS01{},
 			errs: []*field.Error{
@@ -678,6 +1031,34 @@ func TestValidateACMEIssuerDNS01Config(t *testing.T) {
 					"must be either empty or one of AzurePublicCloud, AzureChinaCloud, AzureGermanCloud or AzureUSGovernmentCloud"),
 			},
 		},
+		"valid azuredns workload identity without clientID and tenantID": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
+					SubscriptionID:    "some-subscription-id",
+					ResourceGroupName: "some-resource-group",
+					WorkloadIdentity:  &cmacme.AzureWorkloadIdentity{},
+				},
+			},
+		},
+		"invalid azuredns workload identity with clientSecret": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
+					ClientID: "some-client-id",
+					ClientSecret: &cmmeta.SecretKeySelector{
+						Key: "some-key",
+						LocalObjectReference: cmmeta.LocalObjectReference{
+							Name: "some-secret-name",
+						},
+					},
+					SubscriptionID:    "some-subscription-id",
+					ResourceGroupName: "some-resource-group",
+					WorkloadIdentity:  &cmacme.AzureWorkloadIdentity{},
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("azureDNS", "clientSecretSecretRef"), "may not be set when workloadIdentity is specified"),
+			},
+		},
 		"invalid azuredns missing clientSecret and tenantID": {
 			cfg: &cmacme.ACMEChallengeSolverDNS01{
 				AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
@@ -753,6 +1134,37 @@ func TestValidateACMEIssuerDNS01Config(t *testing.T) {
 				field.Required(fldPath.Child("azureDNS", "resourceGroupName"), ""),
 			},
 		},
+		"valid azuredns with clientSecretFile": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
+					TenantID:          "some-tenant-id",
+					ClientID:          "some-client-id",
+					ClientSecretFile:  "/var/run/secrets/azure/client-secret",
+					SubscriptionID:    "some-subscription-id",
+					ResourceGroupName: "some-resource-group",
+				},
+			},
+		},
+		"invalid azuredns with both clientSecret and clientSecretFile": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
+					TenantID: "some-tenant-id",
+					ClientID: "some-client-id",
+					ClientSecret: &cmmeta.SecretKeySelector{
+						Key: "some-key",
+						LocalObjectReference: cmmeta.LocalObjectReference{
+							Name: "some-secret-name",
+						},
+					},
+					ClientSecretFile:  "/var/run/secrets/azure/client-secret",
+					SubscriptionID:    "some-subscription-id",
+					ResourceGroupName: "some-resource-group",
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("azureDNS", "clientSecretFile"), "may not be set when clientSecretSecretRef is specified"),
+			},
+		},
 		"invalid azuredns clientSecret missing key": {
 			cfg: &cmacme.ACMEChallengeSolverDNS01{
 				AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
@@ -866,6 +1278,42 @@ func TestValidateACMEIssuerDNS01Config(t *testing.T) {
 				field.Invalid(fldPath.Child("rfc2136", "nameserver"), "[]:53", "nameserver must be set in the form host:port where host is an IPv4 address, an enclosed IPv6 address or a hostname and port is an optional port number."),
 			},
 		},
+		"additional providers are validated": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				RFC2136: &cmacme.ACMEIssuerDNS01ProviderRFC2136{
+					Nameserver: "127.0.0.1",
+				},
+				AdditionalProviders: []cmacme.ACMEChallengeSolverDNS01{
+					{
+						RFC2136: &cmacme.ACMEIssuerDNS01ProviderRFC2136{
+							Nameserver: "127.0.0.2",
+						},
+					},
+					{},
+				},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("additionalProviders").Index(1), "no DNS01 provider configured"),
+			},
+		},
+		"nested additional providers": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				RFC2136: &cmacme.ACMEIssuerDNS01ProviderRFC2136{
+					Nameserver: "127.0.0.1",
+				},
+				AdditionalProviders: []cmacme.ACMEChallengeSolverDNS01{
+					{
+						RFC2136: &cmacme.ACMEIssuerDNS01ProviderRFC2136{
+							Nameserver: "127.0.0.2",
+						},
+						AdditionalProviders: []cmacme.ACMEChallengeSolverDNS01{{}},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Forbidden(fldPath.Child("additionalProviders").Index(0).Child("additionalProviders"), "additional providers may not be nested"),
+			},
+		},
 		"rfc2136 provider with IPv6 nameserver": {
 			cfg: &cmacme.ACMEChallengeSolverDNS01{
 				RFC2136: &cmacme.ACMEIssuerDNS01ProviderRFC2136{
@@ -943,6 +1391,43 @@ func TestValidateACMEIssuerDNS01Config(t *testing.T) {
 				field.Required(fldPath.Child("rfc2136", "tsigKeyName"), ""),
 			},
 		},
+		"valid webhook with tls config": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Webhook: &cmacme.ACMEIssuerDNS01ProviderWebhook{
+					SolverName: "something",
+					TLS: &cmacme.ACMEIssuerDNS01ProviderWebhookTLS{
+						URL:                 "https://my-solver.my-namespace.svc",
+						ClientCertSecretRef: cmmeta.LocalObjectReference{Name: "client-cert"},
+					},
+				},
+			},
+		},
+		"webhook tls config with a non-https url": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Webhook: &cmacme.ACMEIssuerDNS01ProviderWebhook{
+					SolverName: "something",
+					TLS: &cmacme.ACMEIssuerDNS01ProviderWebhookTLS{
+						URL:                 "http://my-solver.my-namespace.svc",
+						ClientCertSecretRef: cmmeta.LocalObjectReference{Name: "client-cert"},
+					},
+				},
+			},
+			errs: []*field.Error{
+				field.Invalid(fldPath.Child("webhook", "tls", "url"), "http://my-solver.my-namespace.svc", "must be an absolute https URL"),
+			},
+		},
+		"missing webhook tls client certificate secret name": {
+			cfg: &cmacme.ACMEChallengeSolverDNS01{
+				Webhook: &cmacme.ACMEIssuerDNS01ProviderWebhook{
+					SolverName: "something",
+					TLS:        &cmacme.ACMEIssuerDNS01ProviderWebhookTLS{},
+				},
+			},
+			errs: []*field.Error{
+				field.Required(fldPath.Child("webhook", "tls", "url"), "the webhook solver URL is required"),
+				field.Required(fldPath.Child("webhook", "tls", "clientCertSecretRef", "name"), "secret name is required"),
+			},
+		},
 		"multiple providers configured": {
 			cfg: &cmacme.ACMEChallengeSolverDNS01{
 				CloudDNS: &cmacme.ACMEIssuerDNS01ProviderCloudDNS{
@@ -1321,3 +1806,7 @@ func TestUpdateValidateIssuer(t *testing.T) {
 		})
 	}
 }
+
+func pathTypePtr(p networkingv1.PathType) *networkingv1.PathType {
+	return &p
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
+	internalcmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
+)
+
+// wildcard is responsible for rejecting Certificates that request a wildcard
+// DNS name from an ACME issuer that has no DNS01 solver configured. ACME
+// servers only permit wildcard identifiers to be validated using DNS01, so
+// such Certificates would otherwise only fail once an Order is created.
+type wildcard struct {
+	cmclient cmclient.Interface
+}
+
+func newWildcard() *wildcard {
+	return &wildcard{}
+}
+
+func (w *wildcard) Init(_ kubernetes.Interface, cmclient cmclient.Interface) {
+	w.cmclient = cmclient
+}
+
+// Validate will reject the creation of a Certificate with a wildcard DNS name
+// that references an ACME Issuer or ClusterIssuer without a DNS01 solver.
+// Updates are only checked if they change the DNS names or issuer reference,
+// so that existing Certificates are not blocked from unrelated changes.
+// Certificates referencing an issuer that does not exist yet, or that is not
+// an ACME issuer, are always permitted.
+func (w *wildcard) Validate(ctx context.Context, req *admissionv1.AdmissionRequest, oldObj, obj runtime.Object) *field.Error {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return nil
+	}
+
+	// Only Validate over Certificate resources
+	if req.RequestKind == nil || req.RequestKind.Group != certmanager.GroupName ||
+		req.RequestKind.Kind != cmapi.CertificateKind || req.SubResource != "" {
+		return nil
+	}
+
+	crt, ok := obj.(*internalcmapi.Certificate)
+	if !ok {
+		return nil
+	}
+	if req.Operation == admissionv1.Update {
+		if oldCrt, ok := oldObj.(*internalcmapi.Certificate); ok &&
+			reflect.DeepEqual(oldCrt.Spec.DNSNames, crt.Spec.DNSNames) &&
+			reflect.DeepEqual(oldCrt.Spec.IssuerRef, crt.Spec.IssuerRef) {
+			return nil
+		}
+	}
+
+	idx := -1
+	for i, name := range crt.Spec.DNSNames {
+		if strings.HasPrefix(name, "*.") {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return nil
+	}
+
+	ref := crt.Spec.IssuerRef
+	if ref.Name == "" || (ref.Group != "" && ref.Group != certmanager.GroupName) {
+		return nil
+	}
+
+	// Error if the client is not initialised
+	issuerRefPath := field.NewPath("spec", "issuerRef")
+	if w.cmclient == nil {
+		return field.InternalError(issuerRefPath, errors.New("wildcard validation not initialised"))
+	}
+
+	var (
+		spec *cmapi.IssuerSpec
+		kind string
+	)
+	switch ref.Kind {
+	case "", cmapi.IssuerKind:
+		kind = cmapi.IssuerKind
+		issuer, err := w.cmclient.CertmanagerV1().Issuers(crt.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return field.InternalError(issuerRefPath, err)
+		}
+		spec = &issuer.Spec
+	case cmapi.ClusterIssuerKind:
+		kind = cmapi.ClusterIssuerKind
+		issuer, err := w.cmclient.CertmanagerV1().ClusterIssuers().Get(ctx, ref.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return field.InternalError(issuerRefPath, err)
+		}
+		spec = &issuer.Spec
+	default:
+		return nil
+	}
+
+	if spec.ACME == nil {
+		return nil
+	}
+	for _, solver := range spec.ACME.Solvers {
+		if solver.DNS01 != nil {
+			return nil
+		}
+	}
+
+	return field.Forbidden(field.NewPath("spec", "dnsNames").Index(idx),
+		fmt.Sprintf("wildcard DNS name %q can only be validated by the ACME server using a DNS01 challenge, but %s %q has no DNS01 solver configured: add a dns01 solver to the %s or remove the wildcard DNS name",
+			crt.Spec.DNSNames[idx], kind, ref.Name, kind))
+}
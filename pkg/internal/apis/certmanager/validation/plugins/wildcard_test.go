@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	cmfake "github.com/jetstack/cert-manager/pkg/client/clientset/versioned/fake"
+	internalcmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
+	internalcmmeta "github.com/jetstack/cert-manager/pkg/internal/apis/meta"
+	"github.com/jetstack/cert-manager/test/unit/gen"
+)
+
+func TestWildcardValidate(t *testing.T) {
+	http01Solver := cmacme.ACMEChallengeSolver{HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+		Ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{},
+	}}
+	dns01Solver := cmacme.ACMEChallengeSolver{DNS01: &cmacme.ACMEChallengeSolverDNS01{
+		Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{Region: "us-west-2"},
+	}}
+
+	http01Issuer := gen.Issuer("http01", gen.SetIssuerNamespace("testns"),
+		gen.SetIssuerACMESolvers([]cmacme.ACMEChallengeSolver{http01Solver}))
+	dns01Issuer := gen.Issuer("dns01", gen.SetIssuerNamespace("testns"),
+		gen.SetIssuerACMESolvers([]cmacme.ACMEChallengeSolver{http01Solver, dns01Solver}))
+	http01ClusterIssuer := gen.ClusterIssuer("http01",
+		gen.SetIssuerACMESolvers([]cmacme.ACMEChallengeSolver{http01Solver}))
+	caIssuer := gen.Issuer("ca", gen.SetIssuerNamespace("testns"),
+		gen.SetIssuerCASecretName("ca"))
+
+	crt := func(issuerName, issuerKind string, dnsNames ...string) *internalcmapi.Certificate {
+		return &internalcmapi.Certificate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "test"},
+			Spec: internalcmapi.CertificateSpec{
+				DNSNames:  dnsNames,
+				IssuerRef: internalcmmeta.ObjectReference{Name: issuerName, Kind: issuerKind},
+			},
+		}
+	}
+	createReq := &admissionv1.AdmissionRequest{
+		Operation:   admissionv1.Create,
+		RequestKind: &metav1.GroupVersionKind{Group: "cert-manager.io", Kind: "Certificate"},
+	}
+	updateReq := &admissionv1.AdmissionRequest{
+		Operation:   admissionv1.Update,
+		RequestKind: &metav1.GroupVersionKind{Group: "cert-manager.io", Kind: "Certificate"},
+	}
+
+	tests := map[string]struct {
+		req          *admissionv1.AdmissionRequest
+		oldCrt       *internalcmapi.Certificate
+		crt          *internalcmapi.Certificate
+		issuers      []runtime.Object
+		expErr       *field.Error
+		expNoAPICall bool
+	}{
+		"should reject a wildcard from an Issuer with only an HTTP01 solver": {
+			req:     createReq,
+			crt:     crt("http01", "Issuer", "example.com", "*.example.com"),
+			issuers: []runtime.Object{http01Issuer},
+			expErr: field.Forbidden(field.NewPath("spec", "dnsNames").Index(1),
+				`wildcard DNS name "*.example.com" can only be validated by the ACME server using a DNS01 challenge, but Issuer "http01" has no DNS01 solver configured: add a dns01 solver to the Issuer or remove the wildcard DNS name`),
+		},
+		"should reject a wildcard from a ClusterIssuer with only an HTTP01 solver": {
+			req:     createReq,
+			crt:     crt("http01", "ClusterIssuer", "*.example.com"),
+			issuers: []runtime.Object{http01ClusterIssuer},
+			expErr: field.Forbidden(field.NewPath("spec", "dnsNames").Index(0),
+				`wildcard DNS name "*.example.com" can only be validated by the ACME server using a DNS01 challenge, but ClusterIssuer "http01" has no DNS01 solver configured: add a dns01 solver to the ClusterIssuer or remove the wildcard DNS name`),
+		},
+		"should allow a wildcard from an Issuer with a DNS01 solver": {
+			req:     createReq,
+			crt:     crt("dns01", "Issuer", "*.example.com"),
+			issuers: []runtime.Object{dns01Issuer},
+		},
+		"should allow a wildcard from a non-ACME Issuer": {
+			req:     createReq,
+			crt:     crt("ca", "", "*.example.com"),
+			issuers: []runtime.Object{caIssuer},
+		},
+		"should allow a wildcard if the Issuer does not exist": {
+			req: createReq,
+			crt: crt("http01", "Issuer", "*.example.com"),
+		},
+		"should allow non-wildcard names from an Issuer with only an HTTP01 solver": {
+			req:          createReq,
+			crt:          crt("http01", "Issuer", "example.com"),
+			issuers:      []runtime.Object{http01Issuer},
+			expNoAPICall: true,
+		},
+		"should not check updates that do not change the DNS names or issuer": {
+			req:          updateReq,
+			oldCrt:       crt("http01", "Issuer", "*.example.com"),
+			crt:          crt("http01", "Issuer", "*.example.com"),
+			issuers:      []runtime.Object{http01Issuer},
+			expNoAPICall: true,
+		},
+		"should reject updates that add a wildcard for an Issuer with only an HTTP01 solver": {
+			req:     updateReq,
+			oldCrt:  crt("http01", "Issuer", "example.com"),
+			crt:     crt("http01", "Issuer", "example.com", "*.example.com"),
+			issuers: []runtime.Object{http01Issuer},
+			expErr: field.Forbidden(field.NewPath("spec", "dnsNames").Index(1),
+				`wildcard DNS name "*.example.com" can only be validated by the ACME server using a DNS01 challenge, but Issuer "http01" has no DNS01 solver configured: add a dns01 solver to the Issuer or remove the wildcard DNS name`),
+		},
+		"should not check Certificates referencing an external issuer": {
+			req: createReq,
+			crt: func() *internalcmapi.Certificate {
+				c := crt("http01", "Issuer", "*.example.com")
+				c.Spec.IssuerRef.Group = "example.io"
+				return c
+			}(),
+			expNoAPICall: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := cmfake.NewSimpleClientset(test.issuers...)
+			w := newWildcard()
+			w.Init(nil, client)
+
+			var oldObj runtime.Object
+			if test.oldCrt != nil {
+				oldObj = test.oldCrt
+			}
+			err := w.Validate(context.Background(), test.req, oldObj, test.crt)
+			if !reflect.DeepEqual(test.expErr, err) {
+				t.Errorf("unexpected error, exp=%v got=%v", test.expErr, err)
+			}
+			if test.expNoAPICall && len(client.Actions()) > 0 {
+				t.Errorf("expected no API calls, got: %v", client.Actions())
+			}
+		})
+	}
+}
@@ -17,15 +17,27 @@ limitations under the License.
 package validation
 
 import (
+	"time"
+
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
 	cmapi "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager"
 )
 
-func AddToValidationRegistry(reg *validation.Registry) error {
-	if err := reg.AddValidateFunc(&cmapi.Certificate{}, ValidateCertificate); err != nil {
+// Options configures the validation applied to cert-manager resources by the
+// webhook.
+type Options struct {
+	// MinimumCertificateDuration is the shortest spec.duration that a
+	// Certificate may request, as shorter durations cause the certificate to
+	// be renewed almost continuously. If zero, the API default is used.
+	MinimumCertificateDuration time.Duration
+}
+
+func AddToValidationRegistry(reg *validation.Registry, opts Options) error {
+	crtValidator := newCertificateValidator(opts)
+	if err := reg.AddValidateFunc(&cmapi.Certificate{}, crtValidator.ValidateCertificate); err != nil {
 		return err
 	}
-	if err := reg.AddValidateUpdateFunc(&cmapi.Certificate{}, ValidateUpdateCertificate); err != nil {
+	if err := reg.AddValidateUpdateFunc(&cmapi.Certificate{}, crtValidator.ValidateUpdateCertificate); err != nil {
 		return err
 	}
 
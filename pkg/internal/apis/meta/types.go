@@ -72,4 +72,8 @@ type SecretKeySelector struct {
 const (
 	// Used as a data key in Secret resources to store a CA certificate.
 	TLSCAKey = "ca.crt"
+
+	// Used as a data key in Secret resources to store a certificate signing
+	// request.
+	TLSCSRKey = "tls.csr"
 )
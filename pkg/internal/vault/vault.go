@@ -129,7 +129,7 @@ func (v *Vault) Sign(csrPEM []byte, duration time.Duration) (cert []byte, ca []b
 
 	resp, err := v.client.RawRequest(request)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to sign certificate by vault: %s", err)
+		return nil, nil, fmt.Errorf("failed to sign certificate by vault: %w", err)
 	}
 
 	defer resp.Body.Close()
@@ -170,7 +170,7 @@ func (v *Vault) setToken(client Client) error {
 	if kubernetesAuth != nil {
 		token, err := v.requestTokenWithKubernetesAuth(client, kubernetesAuth)
 		if err != nil {
-			return fmt.Errorf("error reading Kubernetes service account token from %s: %s", kubernetesAuth.SecretRef.Name, err.Error())
+			return fmt.Errorf("error reading Kubernetes service account token from %s: %w", kubernetesAuth.SecretRef.Name, err)
 		}
 		client.SetToken(token)
 		return nil
@@ -270,7 +270,7 @@ func (v *Vault) requestTokenWithAppRoleRef(client Client, appRole *v1.VaultAppRo
 
 	resp, err := client.RawRequest(request)
 	if err != nil {
-		return "", fmt.Errorf("error logging in to Vault server: %s", err.Error())
+		return "", fmt.Errorf("error logging in to Vault server: %w", err)
 	}
 
 	defer resp.Body.Close()
@@ -331,7 +331,7 @@ func (v *Vault) requestTokenWithKubernetesAuth(client Client, kubernetesAuth *v1
 
 	resp, err := client.RawRequest(request)
 	if err != nil {
-		return "", fmt.Errorf("error calling Vault server: %s", err.Error())
+		return "", fmt.Errorf("error calling Vault server: %w", err)
 	}
 
 	defer resp.Body.Close()
@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	"github.com/jetstack/cert-manager/pkg/acme/accounts"
+	"github.com/jetstack/cert-manager/pkg/acme/client"
 	apiutil "github.com/jetstack/cert-manager/pkg/api/util"
 	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/jetstack/cert-manager/pkg/controller"
@@ -50,6 +51,10 @@ type Acme struct {
 	// clientBuilder builds a new ACME client.
 	clientBuilder accounts.NewClientFunc
 
+	// accountKeyRollover changes the key of an existing ACME account.
+	// It can be stubbed in unit tests.
+	accountKeyRollover client.AccountKeyRolloverFunc
+
 	// namespace of referenced resources when the given issuer is a ClusterIssuer
 	clusterResourceNamespace string
 	// used as a cache for ACME clients
@@ -71,6 +76,7 @@ func New(ctx *controller.Context, issuer v1.GenericIssuer) (issuer.Interface, er
 		issuer:                   issuer,
 		keyFromSecret:            newKeyFromSecret(secretsLister),
 		clientBuilder:            accounts.NewClient,
+		accountKeyRollover:       client.AccountKeyRollover,
 		secretsClient:            ctx.Client.CoreV1(),
 		recorder:                 ctx.Recorder,
 		clusterResourceNamespace: ctx.IssuerOptions.ClusterResourceNamespace,
@@ -13,6 +13,9 @@ package azuredns
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
 	"strings"
 
 	"github.com/go-logr/logr"
@@ -38,8 +41,10 @@ type DNSProvider struct {
 }
 
 // NewDNSProviderCredentials returns a DNSProvider instance configured for the Azure
-// DNS service using static credentials from its parameters
-func NewDNSProviderCredentials(environment, clientID, clientSecret, subscriptionID, tenantID, resourceGroupName, zoneName string, dns01Nameservers []string, ambient bool) (*DNSProvider, error) {
+// DNS service using static credentials from its parameters. If
+// federatedTokenFile is set, Azure Workload Identity is used to authenticate
+// with the federated token in that file instead of a client secret.
+func NewDNSProviderCredentials(environment, clientID, clientSecret, subscriptionID, tenantID, resourceGroupName, zoneName string, dns01Nameservers []string, ambient bool, federatedTokenFile string) (*DNSProvider, error) {
 	env := azure.PublicCloud
 	if environment != "" {
 		var err error
@@ -49,7 +54,7 @@ func NewDNSProviderCredentials(environment, clientID, clientSecret, subscription
 		}
 	}
 
-	spt, err := getAuthorization(env, clientID, clientSecret, subscriptionID, tenantID, ambient)
+	spt, err := getAuthorization(env, clientID, clientSecret, subscriptionID, tenantID, ambient, federatedTokenFile)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +75,10 @@ func NewDNSProviderCredentials(environment, clientID, clientSecret, subscription
 	}, nil
 }
 
-func getAuthorization(env azure.Environment, clientID, clientSecret, subscriptionID, tenantID string, ambient bool) (*adal.ServicePrincipalToken, error) {
+func getAuthorization(env azure.Environment, clientID, clientSecret, subscriptionID, tenantID string, ambient bool, federatedTokenFile string) (*adal.ServicePrincipalToken, error) {
+	if federatedTokenFile != "" {
+		return getWorkloadIdentityAuthorization(env, clientID, tenantID, ambient, federatedTokenFile)
+	}
 	if clientID != "" {
 		logf.Log.V(logf.InfoLevel).Info("azuredns authenticating with clientID and secret key")
 		oauthConfig, err := adal.NewOAuthConfig(env.ActiveDirectoryEndpoint, tenantID)
@@ -99,6 +107,69 @@ func getAuthorization(env azure.Environment, clientID, clientSecret, subscriptio
 	return spt, nil
 }
 
+const (
+	// Environment variables set by the Azure Workload Identity webhook
+	clientIDEnvVar = "AZURE_CLIENT_ID"
+	tenantIDEnvVar = "AZURE_TENANT_ID"
+	// FederatedTokenFileEnvVar is the environment variable that the Azure
+	// Workload Identity webhook sets to the path of the projected service
+	// account token file.
+	FederatedTokenFileEnvVar = "AZURE_FEDERATED_TOKEN_FILE"
+
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// federatedTokenSecret implements adal.ServicePrincipalSecret, authenticating
+// with a federated token as a client assertion. The token file is re-read on
+// every refresh, as the projected token is rotated by the kubelet.
+type federatedTokenSecret struct {
+	tokenFile string
+}
+
+// SetAuthenticationValues is a method of the interface ServicePrincipalSecret
+// which sets the federated token as the client assertion.
+func (s *federatedTokenSecret) SetAuthenticationValues(_ *adal.ServicePrincipalToken, v *url.Values) error {
+	token, err := ioutil.ReadFile(s.tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read federated token file: %v", err)
+	}
+	v.Set("client_assertion", strings.TrimSpace(string(token)))
+	v.Set("client_assertion_type", clientAssertionType)
+	return nil
+}
+
+// getWorkloadIdentityAuthorization returns a token that authenticates with
+// Azure Workload Identity, exchanging the federated token in the given file
+// for an access token. The clientID and tenantID default to those injected
+// by the Azure Workload Identity webhook.
+func getWorkloadIdentityAuthorization(env azure.Environment, clientID, tenantID string, ambient bool, federatedTokenFile string) (*adal.ServicePrincipalToken, error) {
+	logf.Log.V(logf.InfoLevel).Info("azuredns authenticating with workload identity", "tokenFile", federatedTokenFile)
+	// The federated token is that of cert-manager's own ServiceAccount, so
+	// using it is only permitted where ambient credentials are.
+	if !ambient {
+		return nil, fmt.Errorf("workload identity is configured but neither `--cluster-issuer-ambient-credentials` nor `--issuer-ambient-credentials` are set. These are necessary to enable Azure Workload Identity")
+	}
+	if clientID == "" {
+		clientID = os.Getenv(clientIDEnvVar)
+	}
+	if tenantID == "" {
+		tenantID = os.Getenv(tenantIDEnvVar)
+	}
+	if clientID == "" || tenantID == "" {
+		return nil, fmt.Errorf("workload identity requires clientID and tenantID to be set, either in the issuer or with the %s and %s environment variables", clientIDEnvVar, tenantIDEnvVar)
+	}
+
+	oauthConfig, err := adal.NewOAuthConfig(env.ActiveDirectoryEndpoint, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	spt, err := adal.NewServicePrincipalTokenWithSecret(*oauthConfig, clientID, env.ResourceManagerEndpoint, &federatedTokenSecret{tokenFile: federatedTokenFile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the workload identity token: %v", err)
+	}
+	return spt, nil
+}
+
 // Present creates a TXT record using the specified parameters
 func (c *DNSProvider) Present(domain, fqdn, value string) error {
 	return c.createRecord(fqdn, value, 60)
@@ -9,10 +9,17 @@ this directory.
 package azuredns
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -45,7 +52,7 @@ func TestLiveAzureDnsPresent(t *testing.T) {
 	if !azureLiveTest {
 		t.Skip("skipping live test")
 	}
-	provider, err := NewDNSProviderCredentials("", azureClientID, azureClientSecret, azuresubscriptionID, azureTenantID, azureResourceGroupName, azureHostedZoneName, util.RecursiveNameservers, false)
+	provider, err := NewDNSProviderCredentials("", azureClientID, azureClientSecret, azuresubscriptionID, azureTenantID, azureResourceGroupName, azureHostedZoneName, util.RecursiveNameservers, false, "")
 	assert.NoError(t, err)
 
 	err = provider.Present(azureDomain, "_acme-challenge."+azureDomain+".", "123d==")
@@ -59,7 +66,7 @@ func TestLiveAzureDnsCleanUp(t *testing.T) {
 
 	time.Sleep(time.Second * 5)
 
-	provider, err := NewDNSProviderCredentials("", azureClientID, azureClientSecret, azuresubscriptionID, azureTenantID, azureResourceGroupName, azureHostedZoneName, util.RecursiveNameservers, false)
+	provider, err := NewDNSProviderCredentials("", azureClientID, azureClientSecret, azuresubscriptionID, azureTenantID, azureResourceGroupName, azureHostedZoneName, util.RecursiveNameservers, false, "")
 	assert.NoError(t, err)
 
 	err = provider.CleanUp(azureDomain, "_acme-challenge."+azureDomain+".", "123d==")
@@ -69,10 +76,58 @@ func TestLiveAzureDnsCleanUp(t *testing.T) {
 func TestInvalidAzureDns(t *testing.T) {
 	validEnv := []string{"", "AzurePublicCloud", "AzureChinaCloud", "AzureGermanCloud", "AzureUSGovernmentCloud"}
 	for _, env := range validEnv {
-		_, err := NewDNSProviderCredentials(env, "cid", "secret", "", "", "", "", util.RecursiveNameservers, false)
+		_, err := NewDNSProviderCredentials(env, "cid", "secret", "", "", "", "", util.RecursiveNameservers, false, "")
 		assert.NoError(t, err)
 	}
 
-	_, err := NewDNSProviderCredentials("invalid env", "cid", "secret", "", "", "", "", util.RecursiveNameservers, false)
+	_, err := NewDNSProviderCredentials("invalid env", "cid", "secret", "", "", "", "", util.RecursiveNameservers, false, "")
 	assert.Error(t, err)
 }
+
+func TestWorkloadIdentityAuthorization(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "azure-identity-token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("federated-token\n"), 0600))
+
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tenant-id/oauth2/token", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+		form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"access-token","expires_in":"3600","expires_on":"%d","token_type":"Bearer"}`, time.Now().Add(time.Hour).Unix())
+	}))
+	defer srv.Close()
+
+	env := azure.PublicCloud
+	env.ActiveDirectoryEndpoint = srv.URL + "/"
+
+	spt, err := getAuthorization(env, "client-id", "", "", "tenant-id", true, tokenFile)
+	assert.NoError(t, err)
+	assert.NoError(t, spt.Refresh())
+
+	assert.Equal(t, "client_credentials", form.Get("grant_type"))
+	assert.Equal(t, "client-id", form.Get("client_id"))
+	assert.Equal(t, "federated-token", form.Get("client_assertion"))
+	assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", form.Get("client_assertion_type"))
+	assert.Empty(t, form.Get("client_secret"))
+	assert.Equal(t, "access-token", spt.OAuthToken())
+}
+
+func TestWorkloadIdentityAuthorizationDefaults(t *testing.T) {
+	env := azure.PublicCloud
+
+	os.Setenv(clientIDEnvVar, "env-client-id")
+	os.Setenv(tenantIDEnvVar, "env-tenant-id")
+	defer os.Unsetenv(clientIDEnvVar)
+	defer os.Unsetenv(tenantIDEnvVar)
+
+	_, err := getAuthorization(env, "", "", "", "", true, "/tmp/token")
+	assert.NoError(t, err, "clientID and tenantID should default to the environment")
+
+	_, err = getAuthorization(env, "", "", "", "", false, "/tmp/token")
+	assert.Error(t, err, "workload identity should require ambient credentials")
+
+	os.Unsetenv(clientIDEnvVar)
+	_, err = getAuthorization(env, "", "", "", "", true, "/tmp/token")
+	assert.Error(t, err, "workload identity should require a clientID")
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns/util"
+)
+
+// ErrBatchPending is returned by Present and CleanUp if the record has been
+// added to a batch that has not yet been sent to the DNS provider. The call
+// should be retried once the batch window has elapsed to get the result.
+var ErrBatchPending = errors.New("DNS01 record is waiting to be sent to the DNS provider as part of a batch")
+
+// errNotBatched is returned by the batcher if the record should be sent to
+// the DNS provider on its own.
+var errNotBatched = errors.New("DNS01 record should not be batched")
+
+// resultRetention is how long the result of a batch is kept for callers
+// that have not yet retried to collect it.
+const resultRetention = 10 * time.Minute
+
+// batchSolver may optionally be implemented by a solver whose provider is able
+// to change many TXT records in a single API call. All records passed to a
+// single call are in the same DNS zone.
+type batchSolver interface {
+	solver
+	PresentBatch(records []util.TXTRecord) error
+	CleanUpBatch(records []util.TXTRecord) error
+}
+
+type batchOperation string
+
+const (
+	batchPresent batchOperation = "Present"
+	batchCleanUp batchOperation = "CleanUp"
+)
+
+// batchKey identifies the calls that may be coalesced into a single batch.
+// Calls are only coalesced if they use the same provider configuration and
+// credentials, and the records are in the same zone.
+type batchKey struct {
+	operation batchOperation
+	zone      string
+	// provider is a serialised form of the provider configuration and the
+	// namespace its credentials are loaded from
+	provider string
+}
+
+// batchRecord identifies a record added to a batch.
+type batchRecord struct {
+	key    batchKey
+	record util.TXTRecord
+}
+
+// pendingBatch is a batch of records that is waiting for the batch window to
+// elapse before being sent to the provider.
+type pendingBatch struct {
+	slv     batchSolver
+	records []util.TXTRecord
+
+	// done is closed once the batch has been sent to the provider, after
+	// which err holds the result
+	done chan struct{}
+	err  error
+}
+
+// batcher coalesces Present and CleanUp calls for the same zone that are made
+// within a short window into a single call to the provider, for providers
+// that rate limit calls that change a single record. Callers are not blocked
+// whilst waiting for the window to elapse, instead they retry the call to
+// collect the result of the batch.
+type batcher struct {
+	window time.Duration
+	// after is used to wait for the batch window to elapse, and is
+	// overridden in tests
+	after func(time.Duration) <-chan time.Time
+
+	lock    sync.Mutex
+	pending map[batchKey]*pendingBatch
+	// batches is the batch each record was added to, until its result has
+	// been returned for the record
+	batches map[batchRecord]*pendingBatch
+	// unbatched are records whose last batch failed, which are sent on their
+	// own the next time they are retried so that a single bad record does
+	// not fail every batch it is added to
+	unbatched map[batchRecord]struct{}
+}
+
+func newBatcher(window time.Duration) *batcher {
+	return &batcher{
+		window:    window,
+		after:     time.After,
+		pending:   make(map[batchKey]*pendingBatch),
+		batches:   make(map[batchRecord]*pendingBatch),
+		unbatched: make(map[batchRecord]struct{}),
+	}
+}
+
+// do adds the record to the pending batch for the key, starting a new batch
+// if there is none, and returns ErrBatchPending. The given solver is used to
+// send the batch if a new batch is started. Once the batch has been sent, the
+// next call for the same record returns the result of the batch. All records
+// in a batch receive the same result. If the record should instead be sent on
+// its own, errNotBatched is returned.
+func (b *batcher) do(key batchKey, slv batchSolver, record util.TXTRecord) error {
+	r := batchRecord{key: key, record: record}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if p, ok := b.batches[r]; ok {
+		select {
+		case <-p.done:
+			delete(b.batches, r)
+			if p.err != nil && len(p.records) > 1 {
+				b.unbatched[r] = struct{}{}
+			}
+			return p.err
+		default:
+			return ErrBatchPending
+		}
+	}
+
+	if _, ok := b.unbatched[r]; ok {
+		delete(b.unbatched, r)
+		return errNotBatched
+	}
+
+	p, ok := b.pending[key]
+	if !ok {
+		p = &pendingBatch{slv: slv, done: make(chan struct{})}
+		b.pending[key] = p
+		go func() {
+			<-b.after(b.window)
+			b.flush(key, p)
+		}()
+	}
+	p.records = append(p.records, record)
+	b.batches[r] = p
+
+	return ErrBatchPending
+}
+
+func (b *batcher) flush(key batchKey, p *pendingBatch) {
+	// Remove the batch before sending it so that no more records are added to
+	// it, and so that calls made whilst it is being sent start a new batch.
+	b.lock.Lock()
+	delete(b.pending, key)
+	b.lock.Unlock()
+
+	switch key.operation {
+	case batchPresent:
+		p.err = p.slv.PresentBatch(p.records)
+	case batchCleanUp:
+		p.err = p.slv.CleanUpBatch(p.records)
+	}
+	close(p.done)
+
+	// Forget the result for records that are never retried, for example as
+	// their Challenge has been deleted.
+	<-b.after(resultRetention)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, record := range p.records {
+		r := batchRecord{key: key, record: record}
+		if b.batches[r] == p {
+			delete(b.batches, r)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns/util"
+)
+
+// fakeBatchSolver records the batches it is called with.
+type fakeBatchSolver struct {
+	err error
+
+	lock     sync.Mutex
+	presents [][]util.TXTRecord
+	cleanUps [][]util.TXTRecord
+}
+
+func (f *fakeBatchSolver) Present(domain, fqdn, value string) error {
+	return errors.New("Present should not be called")
+}
+
+func (f *fakeBatchSolver) CleanUp(domain, fqdn, value string) error {
+	return errors.New("CleanUp should not be called")
+}
+
+func (f *fakeBatchSolver) PresentBatch(records []util.TXTRecord) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.presents = append(f.presents, records)
+	return f.err
+}
+
+func (f *fakeBatchSolver) CleanUpBatch(records []util.TXTRecord) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.cleanUps = append(f.cleanUps, records)
+	return f.err
+}
+
+func TestBatcher(t *testing.T) {
+	presentExampleCom := batchKey{operation: batchPresent, zone: "example.com.", provider: "config"}
+	presentExampleOrg := batchKey{operation: batchPresent, zone: "example.org.", provider: "config"}
+	cleanUpExampleCom := batchKey{operation: batchCleanUp, zone: "example.com.", provider: "config"}
+
+	record := func(domain string) util.TXTRecord {
+		return util.TXTRecord{Domain: domain, FQDN: "_acme-challenge." + domain + ".", Value: "key-" + domain}
+	}
+
+	type call struct {
+		key    batchKey
+		record util.TXTRecord
+	}
+	tests := map[string]struct {
+		calls  []call
+		slvErr error
+
+		expPresents [][]util.TXTRecord
+		expCleanUps [][]util.TXTRecord
+		expErr      bool
+	}{
+		"should send multiple presents for the same zone in a single call": {
+			calls: []call{
+				{presentExampleCom, record("a.example.com")},
+				{presentExampleCom, record("b.example.com")},
+				{presentExampleCom, record("c.example.com")},
+			},
+			expPresents: [][]util.TXTRecord{
+				{record("a.example.com"), record("b.example.com"), record("c.example.com")},
+			},
+		},
+		"should send presents for different zones in separate calls": {
+			calls: []call{
+				{presentExampleCom, record("a.example.com")},
+				{presentExampleOrg, record("a.example.org")},
+				{presentExampleCom, record("b.example.com")},
+			},
+			expPresents: [][]util.TXTRecord{
+				{record("a.example.com"), record("b.example.com")},
+				{record("a.example.org")},
+			},
+		},
+		"should not combine presents and clean ups": {
+			calls: []call{
+				{presentExampleCom, record("a.example.com")},
+				{cleanUpExampleCom, record("b.example.com")},
+			},
+			expPresents: [][]util.TXTRecord{{record("a.example.com")}},
+			expCleanUps: [][]util.TXTRecord{{record("b.example.com")}},
+		},
+		"should return the provider error to every caller in the batch": {
+			calls: []call{
+				{presentExampleCom, record("a.example.com")},
+				{presentExampleCom, record("b.example.com")},
+			},
+			slvErr: errors.New("rate limited"),
+			expPresents: [][]util.TXTRecord{
+				{record("a.example.com"), record("b.example.com")},
+			},
+			expErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			slv := &fakeBatchSolver{err: test.slvErr}
+
+			// the batch window only elapses once all calls have been queued,
+			// and results are never forgotten
+			windowElapsed := make(chan time.Time)
+			b := newBatcher(time.Second)
+			b.after = fakeAfter(windowElapsed)
+
+			for _, c := range test.calls {
+				if err := b.do(c.key, slv, c.record); err != ErrBatchPending {
+					t.Errorf("expected the batch to be pending, got: %v", err)
+				}
+			}
+			close(windowElapsed)
+			waitForSentBatches(t, b)
+
+			for _, c := range test.calls {
+				err := b.do(c.key, slv, c.record)
+				if test.expErr != (err != nil) {
+					t.Errorf("expected error=%t, got: %v", test.expErr, err)
+				}
+			}
+
+			sortBatches(slv.presents)
+			if !reflect.DeepEqual(slv.presents, test.expPresents) {
+				t.Errorf("unexpected PresentBatch calls, exp=%v got=%v", test.expPresents, slv.presents)
+			}
+			if !reflect.DeepEqual(slv.cleanUps, test.expCleanUps) {
+				t.Errorf("unexpected CleanUpBatch calls, exp=%v got=%v", test.expCleanUps, slv.cleanUps)
+			}
+		})
+	}
+}
+
+func TestBatcherPendingUntilSent(t *testing.T) {
+	key := batchKey{operation: batchPresent, zone: "example.com."}
+	record := util.TXTRecord{Domain: "example.com", FQDN: "_acme-challenge.example.com.", Value: "key"}
+
+	windowElapsed := make(chan time.Time)
+	b := newBatcher(time.Second)
+	b.after = fakeAfter(windowElapsed)
+
+	slv := &fakeBatchSolver{}
+	for i := 0; i < 2; i++ {
+		if err := b.do(key, slv, record); err != ErrBatchPending {
+			t.Errorf("expected the batch to be pending, got: %v", err)
+		}
+	}
+	close(windowElapsed)
+	waitForSentBatches(t, b)
+
+	if err := b.do(key, slv, record); err != nil {
+		t.Errorf("expected the result of the batch, got: %v", err)
+	}
+	if !reflect.DeepEqual(slv.presents, [][]util.TXTRecord{{record}}) {
+		t.Errorf("expected the record to be sent once, got: %v", slv.presents)
+	}
+}
+
+func TestBatcherFailedBatchSentUnbatched(t *testing.T) {
+	key := batchKey{operation: batchCleanUp, zone: "example.com."}
+	recordA := util.TXTRecord{Domain: "a.example.com", FQDN: "_acme-challenge.a.example.com.", Value: "key"}
+	recordB := util.TXTRecord{Domain: "b.example.com", FQDN: "_acme-challenge.b.example.com.", Value: "key"}
+
+	windowElapsed := make(chan time.Time)
+	b := newBatcher(time.Second)
+	b.after = fakeAfter(windowElapsed)
+
+	slv := &fakeBatchSolver{err: errors.New("InvalidChangeBatch")}
+	for _, record := range []util.TXTRecord{recordA, recordB} {
+		if err := b.do(key, slv, record); err != ErrBatchPending {
+			t.Errorf("expected the batch to be pending, got: %v", err)
+		}
+	}
+	close(windowElapsed)
+	waitForSentBatches(t, b)
+
+	for _, record := range []util.TXTRecord{recordA, recordB} {
+		if err := b.do(key, slv, record); err != slv.err {
+			t.Errorf("expected the error of the batch, got: %v", err)
+		}
+		// the next attempt should be sent on its own
+		if err := b.do(key, slv, record); err != errNotBatched {
+			t.Errorf("expected the record to be sent on its own, got: %v", err)
+		}
+	}
+}
+
+// fakeAfter returns a function that waits for windowElapsed to be closed for
+// the batch window, and blocks forever when waiting to forget results.
+func fakeAfter(windowElapsed <-chan time.Time) func(time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		if d == resultRetention {
+			return nil
+		}
+		return windowElapsed
+	}
+}
+
+// waitForSentBatches waits for every batch that records have been added to
+// to have been sent to the provider.
+func waitForSentBatches(t *testing.T, b *batcher) {
+	err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		for _, p := range b.batches {
+			select {
+			case <-p.done:
+			default:
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		t.Fatal("timed out waiting for batches to be sent")
+	}
+}
+
+// sortBatches sorts batches by their first record, as batches for different
+// keys are sent concurrently.
+func sortBatches(batches [][]util.TXTRecord) {
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i][0].Domain < batches[j][0].Domain
+	})
+}
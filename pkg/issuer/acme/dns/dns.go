@@ -20,11 +20,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 
 	"github.com/jetstack/cert-manager/pkg/acme/webhook"
@@ -59,8 +62,8 @@ type solver interface {
 type dnsProviderConstructors struct {
 	cloudDNS     func(project string, serviceAccount []byte, dns01Nameservers []string, ambient bool, hostedZoneName string) (*clouddns.DNSProvider, error)
 	cloudFlare   func(email, apikey, apiToken string, dns01Nameservers []string) (*cloudflare.DNSProvider, error)
-	route53      func(accessKey, secretKey, hostedZoneID, region, role string, ambient bool, dns01Nameservers []string) (*route53.DNSProvider, error)
-	azureDNS     func(environment, clientID, clientSecret, subscriptionID, tenantID, resourceGroupName, hostedZoneName string, dns01Nameservers []string, ambient bool) (*azuredns.DNSProvider, error)
+	route53      func(accessKey, secretKey, hostedZoneID, region, role, externalID string, ambient bool, dns01Nameservers []string) (*route53.DNSProvider, error)
+	azureDNS     func(environment, clientID, clientSecret, subscriptionID, tenantID, resourceGroupName, hostedZoneName string, dns01Nameservers []string, ambient bool, federatedTokenFile string) (*azuredns.DNSProvider, error)
 	acmeDNS      func(host string, accountJson []byte, dns01Nameservers []string) (*acmedns.DNSProvider, error)
 	digitalOcean func(token string, dns01Nameservers []string) (*digitalocean.DNSProvider, error)
 }
@@ -73,14 +76,40 @@ type Solver struct {
 	secretLister            corev1listers.SecretLister
 	dnsProviderConstructors dnsProviderConstructors
 	webhookSolvers          map[string]webhook.Solver
+	// batcher coalesces calls to providers that implement batchSolver. It is
+	// nil if batching is disabled.
+	batcher *batcher
 }
 
 // Present performs the work to configure DNS to resolve a DNS01 challenge.
+// The record is presented to the challenge's DNS01 provider and to each of its
+// additional providers.
 func (s *Solver) Present(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
 	log := logf.WithResource(logf.FromContext(ctx, "Present"), ch).WithValues("domain", ch.Spec.DNSName)
 	ctx = logf.NewContext(ctx, log)
 
-	webhookSolver, req, err := s.prepareChallengeRequest(issuer, ch)
+	providerConfigs, err := challengeProviderConfigs(ch)
+	if err != nil {
+		return err
+	}
+
+	if len(providerConfigs) == 1 {
+		return s.presentForConfig(ctx, issuer, ch, providerConfigs[0])
+	}
+
+	for i, providerConfig := range providerConfigs {
+		if err := s.presentForConfig(logf.NewContext(ctx, log.WithValues("provider", i)), issuer, ch, providerConfig); err != nil {
+			return errors.Wrapf(err, "error presenting DNS01 challenge to provider %d", i)
+		}
+	}
+
+	return nil
+}
+
+func (s *Solver) presentForConfig(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge, providerConfig *cmacme.ACMEChallengeSolverDNS01) error {
+	log := logf.FromContext(ctx)
+
+	webhookSolver, req, err := s.prepareChallengeRequest(issuer, ch, providerConfig)
 	if err != nil && err != errNotFound {
 		return err
 	}
@@ -89,7 +118,7 @@ func (s *Solver) Present(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return webhookSolver.Present(req)
 	}
 
-	slv, providerConfig, err := s.solverForChallenge(ctx, issuer, ch)
+	slv, err := s.solverForConfig(ctx, issuer, providerConfig)
 	if err != nil {
 		return err
 	}
@@ -99,12 +128,27 @@ func (s *Solver) Present(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return err
 	}
 
+	if bslv, ok := slv.(batchSolver); ok && s.batcher != nil {
+		key, err := s.batchKey(batchPresent, issuer, providerConfig, fqdn)
+		if err != nil {
+			return err
+		}
+		log.V(logf.DebugLevel).Info("presenting DNS01 challenge for domain as part of a batch", "zone", key.zone)
+		if err := s.batcher.do(key, bslv, util.TXTRecord{Domain: ch.Spec.DNSName, FQDN: fqdn, Value: ch.Spec.Key}); err != errNotBatched {
+			return err
+		}
+		log.V(logf.DebugLevel).Info("the last batch containing the DNS01 challenge failed, sending it on its own", "zone", key.zone)
+	}
+
 	log.V(logf.DebugLevel).Info("presenting DNS01 challenge for domain")
 
 	return slv.Present(ch.Spec.DNSName, fqdn, ch.Spec.Key)
 }
 
 // Check verifies that the DNS records for the ACME challenge have propagated.
+// If the challenge has additional DNS01 providers, every authoritative
+// nameserver for the zone is queried so that the check only passes once the
+// record is served by all of the providers.
 func (s *Solver) Check(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
 	log := logf.WithResource(logf.FromContext(ctx, "Check"), ch).WithValues("domain", ch.Spec.DNSName)
 
@@ -113,10 +157,14 @@ func (s *Solver) Check(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.
 		return err
 	}
 
-	log.V(logf.DebugLevel).Info("checking DNS propagation", "nameservers", s.Context.DNS01Nameservers)
+	checkAuthoritative := s.Context.DNS01CheckAuthoritative
+	if ch.Spec.Solver.DNS01 != nil && len(ch.Spec.Solver.DNS01.AdditionalProviders) > 0 {
+		checkAuthoritative = true
+	}
+
+	log.V(logf.DebugLevel).Info("checking DNS propagation", "nameservers", s.Context.DNS01Nameservers, "authoritative", checkAuthoritative)
 
-	ok, err := util.PreCheckDNS(fqdn, ch.Spec.Key, s.Context.DNS01Nameservers,
-		s.Context.DNS01CheckAuthoritative)
+	ok, err := util.PreCheckDNS(fqdn, ch.Spec.Key, s.Context.DNS01Nameservers, checkAuthoritative)
 	if err != nil {
 		return err
 	}
@@ -133,12 +181,36 @@ func (s *Solver) Check(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.
 }
 
 // CleanUp removes DNS records which are no longer needed after
-// certificate issuance.
+// certificate issuance. The record is cleaned up from the challenge's DNS01
+// provider and from each of its additional providers, even if cleaning up from
+// one of them fails.
 func (s *Solver) CleanUp(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
 	log := logf.WithResource(logf.FromContext(ctx, "CleanUp"), ch).WithValues("domain", ch.Spec.DNSName)
 	ctx = logf.NewContext(ctx, log)
 
-	webhookSolver, req, err := s.prepareChallengeRequest(issuer, ch)
+	providerConfigs, err := challengeProviderConfigs(ch)
+	if err != nil {
+		return err
+	}
+
+	if len(providerConfigs) == 1 {
+		return s.cleanUpForConfig(ctx, issuer, ch, providerConfigs[0])
+	}
+
+	var errs []error
+	for i, providerConfig := range providerConfigs {
+		if err := s.cleanUpForConfig(logf.NewContext(ctx, log.WithValues("provider", i)), issuer, ch, providerConfig); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error cleaning up DNS01 challenge from provider %d", i))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (s *Solver) cleanUpForConfig(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge, providerConfig *cmacme.ACMEChallengeSolverDNS01) error {
+	log := logf.FromContext(ctx)
+
+	webhookSolver, req, err := s.prepareChallengeRequest(issuer, ch, providerConfig)
 	if err != nil && err != errNotFound {
 		return err
 	}
@@ -147,7 +219,7 @@ func (s *Solver) CleanUp(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return webhookSolver.CleanUp(req)
 	}
 
-	slv, providerConfig, err := s.solverForChallenge(ctx, issuer, ch)
+	slv, err := s.solverForConfig(ctx, issuer, providerConfig)
 	if err != nil {
 		return err
 	}
@@ -157,9 +229,41 @@ func (s *Solver) CleanUp(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 		return err
 	}
 
+	if bslv, ok := slv.(batchSolver); ok && s.batcher != nil {
+		key, err := s.batchKey(batchCleanUp, issuer, providerConfig, fqdn)
+		if err != nil {
+			return err
+		}
+		log.V(logf.DebugLevel).Info("cleaning up DNS01 challenge as part of a batch", "zone", key.zone)
+		if err := s.batcher.do(key, bslv, util.TXTRecord{Domain: ch.Spec.DNSName, FQDN: fqdn, Value: ch.Spec.Key}); err != errNotBatched {
+			return err
+		}
+		log.V(logf.DebugLevel).Info("the last batch containing the DNS01 challenge failed, sending it on its own", "zone", key.zone)
+	}
+
 	return slv.CleanUp(ch.Spec.DNSName, fqdn, ch.Spec.Key)
 }
 
+// batchKey returns the key used to coalesce calls to the provider for the
+// given record into a batch.
+func (s *Solver) batchKey(operation batchOperation, issuer v1.GenericIssuer, providerConfig *cmacme.ACMEChallengeSolverDNS01, fqdn string) (batchKey, error) {
+	zone, err := util.FindZoneByFqdn(fqdn, s.DNS01Nameservers)
+	if err != nil {
+		return batchKey{}, err
+	}
+
+	b, err := json.Marshal(providerConfig)
+	if err != nil {
+		return batchKey{}, err
+	}
+
+	return batchKey{
+		operation: operation,
+		zone:      zone,
+		provider:  fmt.Sprintf("%s/%t/%s", s.ResourceNamespace(issuer), s.CanUseAmbientCredentials(issuer), b),
+	}, nil
+}
+
 func followCNAME(strategy cmacme.CNAMEStrategy) bool {
 	return strategy == cmacme.FollowStrategy
 }
@@ -172,20 +276,48 @@ func extractChallengeSolverConfig(ch *cmacme.Challenge) (*cmacme.ACMEChallengeSo
 	return ch.Spec.Solver.DNS01, nil
 }
 
+// challengeProviderConfigs returns the configuration of each of the DNS01
+// providers that the challenge record must be presented to, starting with the
+// challenge's own provider followed by its additional providers.
+func challengeProviderConfigs(ch *cmacme.Challenge) ([]*cmacme.ACMEChallengeSolverDNS01, error) {
+	providerConfig, err := extractChallengeSolverConfig(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := []*cmacme.ACMEChallengeSolverDNS01{providerConfig}
+	for i := range providerConfig.AdditionalProviders {
+		configs = append(configs, &providerConfig.AdditionalProviders[i])
+	}
+	return configs, nil
+}
+
 // solverForChallenge returns a Solver for the given providerName.
 // The providerName is the name of an ACME DNS-01 challenge provider as
 // specified on the Issuer resource for the Solver.
 func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) (solver, *cmacme.ACMEChallengeSolverDNS01, error) {
+	providerConfig, err := extractChallengeSolverConfig(ch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	impl, err := s.solverForConfig(ctx, issuer, providerConfig)
+	return impl, providerConfig, err
+}
+
+// solverForConfig returns a Solver for the given DNS01 provider
+// configuration.
+// A new provider is constructed on every call using the current contents of
+// the referenced credentials Secrets, so that rotated credentials are used
+// without restarting the controller. Providers must not be cached here.
+func (s *Solver) solverForConfig(ctx context.Context, issuer v1.GenericIssuer, providerConfig *cmacme.ACMEChallengeSolverDNS01) (solver, error) {
 	log := logf.FromContext(ctx, "solverForChallenge")
 	dbg := log.V(logf.DebugLevel)
 
 	resourceNamespace := s.ResourceNamespace(issuer)
 	canUseAmbientCredentials := s.CanUseAmbientCredentials(issuer)
 
-	providerConfig, err := extractChallengeSolverConfig(ch)
-	if err != nil {
-		return nil, nil, err
-	}
+	var err error
 
 	var impl solver
 	switch {
@@ -193,17 +325,17 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 		dbg.Info("preparing to create Akamai provider")
 		clientToken, err := s.loadSecretData(&providerConfig.Akamai.ClientToken, resourceNamespace)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "error getting akamai client token")
+			return nil, errors.Wrap(err, "error getting akamai client token")
 		}
 
 		clientSecret, err := s.loadSecretData(&providerConfig.Akamai.ClientSecret, resourceNamespace)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "error getting akamai client secret")
+			return nil, errors.Wrap(err, "error getting akamai client secret")
 		}
 
 		accessToken, err := s.loadSecretData(&providerConfig.Akamai.AccessToken, resourceNamespace)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "error getting akamai access token")
+			return nil, errors.Wrap(err, "error getting akamai access token")
 		}
 
 		impl, err = akamai.NewDNSProvider(
@@ -213,37 +345,43 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 			string(accessToken),
 			s.DNS01Nameservers)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "error instantiating akamai challenge solver")
+			return nil, errors.Wrap(err, "error instantiating akamai challenge solver")
 		}
 	case providerConfig.CloudDNS != nil:
 		dbg.Info("preparing to create CloudDNS provider")
 		var keyData []byte
 
 		// if the serviceAccount field isn't nil we will load credentials from
-		// that secret.  If it is nil we will attempt to instantiate the
-		// provider using ambient credentials (if enabled).
-		if providerConfig.CloudDNS.ServiceAccount != nil {
+		// that secret, or from serviceAccountFile if that is set instead.  If
+		// neither is set we will attempt to instantiate the provider using
+		// ambient credentials (if enabled).
+		if providerConfig.CloudDNS.ServiceAccountFile != "" {
+			keyData, err = s.loadCredentialsFile(issuer, providerConfig.CloudDNS.ServiceAccountFile)
+			if err != nil {
+				return nil, fmt.Errorf("error getting clouddns service account: %s", err)
+			}
+		} else if providerConfig.CloudDNS.ServiceAccount != nil {
 			saSecret, err := s.secretLister.Secrets(resourceNamespace).Get(providerConfig.CloudDNS.ServiceAccount.Name)
 			if err != nil {
-				return nil, nil, fmt.Errorf("error getting clouddns service account: %s", err)
+				return nil, fmt.Errorf("error getting clouddns service account: %s", err)
 			}
 
 			saKey := providerConfig.CloudDNS.ServiceAccount.Key
 			keyData = saSecret.Data[saKey]
 			if len(keyData) == 0 {
-				return nil, nil, fmt.Errorf("specified key %q not found in secret %s/%s", saKey, saSecret.Namespace, saSecret.Name)
+				return nil, fmt.Errorf("specified key %q not found in secret %s/%s", saKey, saSecret.Namespace, saSecret.Name)
 			}
 		}
 
 		// attempt to construct the cloud dns provider
 		impl, err = s.dnsProviderConstructors.cloudDNS(providerConfig.CloudDNS.Project, keyData, s.DNS01Nameservers, s.CanUseAmbientCredentials(issuer), providerConfig.CloudDNS.HostedZoneName)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error instantiating google clouddns challenge solver: %s", err)
+			return nil, fmt.Errorf("error instantiating google clouddns challenge solver: %s", err)
 		}
 	case providerConfig.Cloudflare != nil:
 		dbg.Info("preparing to create Cloudflare provider")
 		if providerConfig.Cloudflare.APIKey != nil && providerConfig.Cloudflare.APIToken != nil {
-			return nil, nil, fmt.Errorf("API key and API token secret references are both present")
+			return nil, fmt.Errorf("API key and API token secret references are both present")
 		}
 
 		var saSecretName, saSecretKey string
@@ -257,12 +395,12 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 
 		saSecret, err := s.secretLister.Secrets(resourceNamespace).Get(saSecretName)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error getting cloudflare secret: %s", err)
+			return nil, fmt.Errorf("error getting cloudflare secret: %s", err)
 		}
 
 		keyData, ok := saSecret.Data[saSecretKey]
 		if !ok {
-			return nil, nil, fmt.Errorf("specified key %q not found in secret %s/%s", saSecretKey, saSecret.Namespace, saSecret.Name)
+			return nil, fmt.Errorf("specified key %q not found in secret %s/%s", saSecretKey, saSecret.Namespace, saSecret.Name)
 		}
 
 		var apiKey, apiToken string
@@ -275,33 +413,39 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 		email := providerConfig.Cloudflare.Email
 		impl, err = s.dnsProviderConstructors.cloudFlare(email, apiKey, apiToken, s.DNS01Nameservers)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error instantiating cloudflare challenge solver: %s", err)
+			return nil, fmt.Errorf("error instantiating cloudflare challenge solver: %s", err)
 		}
 	case providerConfig.DigitalOcean != nil:
 		dbg.Info("preparing to create DigitalOcean provider")
 		apiTokenSecret, err := s.secretLister.Secrets(resourceNamespace).Get(providerConfig.DigitalOcean.Token.Name)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error getting digitalocean token: %s", err)
+			return nil, fmt.Errorf("error getting digitalocean token: %s", err)
 		}
 
 		apiToken := string(apiTokenSecret.Data[providerConfig.DigitalOcean.Token.Key])
 
 		impl, err = s.dnsProviderConstructors.digitalOcean(strings.TrimSpace(apiToken), s.DNS01Nameservers)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error instantiating digitalocean challenge solver: %s", err.Error())
+			return nil, fmt.Errorf("error instantiating digitalocean challenge solver: %s", err.Error())
 		}
 	case providerConfig.Route53 != nil:
 		dbg.Info("preparing to create Route53 provider")
 		secretAccessKey := ""
-		if providerConfig.Route53.SecretAccessKey.Name != "" {
+		if providerConfig.Route53.SecretAccessKeyFile != "" {
+			secretAccessKeyBytes, err := s.loadCredentialsFile(issuer, providerConfig.Route53.SecretAccessKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error getting route53 secret access key: %s", err)
+			}
+			secretAccessKey = string(secretAccessKeyBytes)
+		} else if providerConfig.Route53.SecretAccessKey.Name != "" {
 			secretAccessKeySecret, err := s.secretLister.Secrets(resourceNamespace).Get(providerConfig.Route53.SecretAccessKey.Name)
 			if err != nil {
-				return nil, nil, fmt.Errorf("error getting route53 secret access key: %s", err)
+				return nil, fmt.Errorf("error getting route53 secret access key: %s", err)
 			}
 
 			secretAccessKeyBytes, ok := secretAccessKeySecret.Data[providerConfig.Route53.SecretAccessKey.Key]
 			if !ok {
-				return nil, nil, fmt.Errorf("error getting route53 secret access key: key '%s' not found in secret", providerConfig.Route53.SecretAccessKey.Key)
+				return nil, fmt.Errorf("error getting route53 secret access key: key '%s' not found in secret", providerConfig.Route53.SecretAccessKey.Key)
 			}
 			secretAccessKey = string(secretAccessKeyBytes)
 		}
@@ -312,26 +456,46 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 			providerConfig.Route53.HostedZoneID,
 			providerConfig.Route53.Region,
 			providerConfig.Route53.Role,
+			providerConfig.Route53.ExternalID,
 			canUseAmbientCredentials,
 			s.DNS01Nameservers,
 		)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error instantiating route53 challenge solver: %s", err)
+			return nil, fmt.Errorf("error instantiating route53 challenge solver: %s", err)
 		}
 	case providerConfig.AzureDNS != nil:
 		dbg.Info("preparing to create AzureDNS provider")
 		secret := ""
+		federatedTokenFile := ""
+		// if WorkloadIdentity is set, we authenticate with the federated token
+		// in the projected service account token file instead of a secret
 		// if ClientID is empty, then we try to use MSI (azure metadata API for credentials)
 		// if ClientID is empty we don't even try to get the ClientSecret because it would not be used
-		if providerConfig.AzureDNS.ClientID != "" {
+		if providerConfig.AzureDNS.WorkloadIdentity != nil {
+			// the token file is only configurable on the controller, so that
+			// issuers cannot cause arbitrary files to be read and sent to Azure
+			federatedTokenFile = s.DNS01AzureFederatedTokenFile
+			if federatedTokenFile == "" {
+				federatedTokenFile = os.Getenv(azuredns.FederatedTokenFileEnvVar)
+			}
+			if federatedTokenFile == "" {
+				return nil, fmt.Errorf("error getting azuredns workload identity token file: --dns01-azure-federated-token-file is not set and the %s environment variable is empty", azuredns.FederatedTokenFileEnvVar)
+			}
+		} else if providerConfig.AzureDNS.ClientID != "" && providerConfig.AzureDNS.ClientSecretFile != "" {
+			clientSecretBytes, err := s.loadCredentialsFile(issuer, providerConfig.AzureDNS.ClientSecretFile)
+			if err != nil {
+				return nil, fmt.Errorf("error getting azuredns client secret: %s", err)
+			}
+			secret = strings.TrimSpace(string(clientSecretBytes))
+		} else if providerConfig.AzureDNS.ClientID != "" {
 			clientSecret, err := s.secretLister.Secrets(resourceNamespace).Get(providerConfig.AzureDNS.ClientSecret.Name)
 			if err != nil {
-				return nil, nil, fmt.Errorf("error getting azuredns client secret: %s", err)
+				return nil, fmt.Errorf("error getting azuredns client secret: %s", err)
 			}
 
 			clientSecretBytes, ok := clientSecret.Data[providerConfig.AzureDNS.ClientSecret.Key]
 			if !ok {
-				return nil, nil, fmt.Errorf("error getting azure dns client secret: key '%s' not found in secret", providerConfig.AzureDNS.ClientSecret.Key)
+				return nil, fmt.Errorf("error getting azure dns client secret: key '%s' not found in secret", providerConfig.AzureDNS.ClientSecret.Key)
 			}
 			secret = string(clientSecretBytes)
 		}
@@ -345,20 +509,21 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 			providerConfig.AzureDNS.HostedZoneName,
 			s.DNS01Nameservers,
 			canUseAmbientCredentials,
+			federatedTokenFile,
 		)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error instantiating azuredns challenge solver: %s", err)
+			return nil, fmt.Errorf("error instantiating azuredns challenge solver: %s", err)
 		}
 	case providerConfig.AcmeDNS != nil:
 		dbg.Info("preparing to create ACMEDNS provider")
 		accountSecret, err := s.secretLister.Secrets(resourceNamespace).Get(providerConfig.AcmeDNS.AccountSecret.Name)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error getting acmedns accounts secret: %s", err)
+			return nil, fmt.Errorf("error getting acmedns accounts secret: %s", err)
 		}
 
 		accountSecretBytes, ok := accountSecret.Data[providerConfig.AcmeDNS.AccountSecret.Key]
 		if !ok {
-			return nil, nil, fmt.Errorf("error getting acmedns accounts secret: key '%s' not found in secret", providerConfig.AcmeDNS.AccountSecret.Key)
+			return nil, fmt.Errorf("error getting acmedns accounts secret: key '%s' not found in secret", providerConfig.AcmeDNS.AccountSecret.Key)
 		}
 
 		impl, err = s.dnsProviderConstructors.acmeDNS(
@@ -367,21 +532,16 @@ func (s *Solver) solverForChallenge(ctx context.Context, issuer v1.GenericIssuer
 			s.DNS01Nameservers,
 		)
 		if err != nil {
-			return nil, providerConfig, fmt.Errorf("error instantiating acmedns challenge solver: %s", err)
+			return nil, fmt.Errorf("error instantiating acmedns challenge solver: %s", err)
 		}
 	default:
-		return nil, providerConfig, fmt.Errorf("no dns provider config specified for challenge")
+		return nil, fmt.Errorf("no dns provider config specified for challenge")
 	}
 
-	return impl, providerConfig, nil
+	return impl, nil
 }
 
-func (s *Solver) prepareChallengeRequest(issuer v1.GenericIssuer, ch *cmacme.Challenge) (webhook.Solver, *whapi.ChallengeRequest, error) {
-	dns01Config, err := extractChallengeSolverConfig(ch)
-	if err != nil {
-		return nil, nil, err
-	}
-
+func (s *Solver) prepareChallengeRequest(issuer v1.GenericIssuer, ch *cmacme.Challenge, dns01Config *cmacme.ACMEChallengeSolverDNS01) (webhook.Solver, *whapi.ChallengeRequest, error) {
 	webhookSolver, cfg, err := s.dns01SolverForConfig(dns01Config)
 	if err != nil {
 		return nil, nil, err
@@ -449,7 +609,7 @@ func (s *Solver) dns01SolverForConfig(config *cmacme.ACMEChallengeSolverDNS01) (
 // provider.
 func NewSolver(ctx *controller.Context) (*Solver, error) {
 	webhookSolvers := []webhook.Solver{
-		&webhookslv.Webhook{},
+		webhookslv.New(webhookslv.WithNamespace(ctx.Namespace)),
 		rfc2136.New(rfc2136.WithNamespace(ctx.Namespace)),
 	}
 
@@ -468,6 +628,11 @@ func NewSolver(ctx *controller.Context) (*Solver, error) {
 		}
 	}
 
+	var b *batcher
+	if ctx.DNS01BatchWindow > 0 {
+		b = newBatcher(ctx.DNS01BatchWindow)
+	}
+
 	return &Solver{
 		Context:      ctx,
 		secretLister: ctx.KubeSharedInformerFactory.Core().V1().Secrets().Lister(),
@@ -480,6 +645,7 @@ func NewSolver(ctx *controller.Context) (*Solver, error) {
 			digitalocean.NewDNSProviderCredentials,
 		},
 		webhookSolvers: initialized,
+		batcher:        b,
 	}, nil
 }
 
@@ -495,3 +661,19 @@ func (s *Solver) loadSecretData(selector *cmmeta.SecretKeySelector, ns string) (
 
 	return nil, errors.Errorf("no key %q in secret %q", selector.Key, ns+"/"+selector.Name)
 }
+
+// loadCredentialsFile reads credentials from a file mounted into the
+// controller. As such files are shared by all issuers, they may only be used
+// by issuers that are permitted to use ambient credentials.
+func (s *Solver) loadCredentialsFile(issuer v1.GenericIssuer, path string) ([]byte, error) {
+	if !s.CanUseAmbientCredentials(issuer) {
+		return nil, errors.Errorf("credentials file %q may only be used by issuers that can use ambient credentials", path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read credentials file %q", path)
+	}
+
+	return data, nil
+}
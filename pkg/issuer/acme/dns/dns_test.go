@@ -18,21 +18,31 @@ package dns
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	restclient "k8s.io/client-go/rest"
 
+	"github.com/jetstack/cert-manager/pkg/acme/webhook"
+	whapi "github.com/jetstack/cert-manager/pkg/acme/webhook/apis/acme/v1alpha1"
 	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	"github.com/jetstack/cert-manager/pkg/controller"
 	"github.com/jetstack/cert-manager/pkg/controller/test"
 	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns/acmedns"
+	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns/azuredns"
 	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns/cloudflare"
 	"github.com/jetstack/cert-manager/pkg/issuer/acme/dns/util"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+	testserver "github.com/jetstack/cert-manager/test/acme/dns/server"
 )
 
 func newIssuer(name, namespace string) *v1.Issuer {
@@ -394,7 +404,7 @@ func TestRoute53TrimCreds(t *testing.T) {
 	expectedR53Call := []fakeDNSProviderCall{
 		{
 			name: "route53",
-			args: []interface{}{"test_with_spaces", "AKIENDINNEWLINE", "", "us-west-2", "", false, util.RecursiveNameservers},
+			args: []interface{}{"test_with_spaces", "AKIENDINNEWLINE", "", "us-west-2", "", "", false, util.RecursiveNameservers},
 		},
 	}
 
@@ -403,6 +413,77 @@ func TestRoute53TrimCreds(t *testing.T) {
 	}
 }
 
+// TestRoute53RotatedCreds ensures that the provider is rebuilt from the
+// current contents of the credentials Secret, so that rotated credentials are
+// used without restarting the controller.
+func TestRoute53RotatedCreds(t *testing.T) {
+	f := &solverFixture{
+		Builder: &test.Builder{
+			KubeObjects: []runtime.Object{
+				newSecret("route53", "default", map[string][]byte{
+					"secret": []byte("old-secret"),
+				}),
+			},
+		},
+		Issuer: newIssuer("test", "default"),
+		Challenge: &cmacme.Challenge{
+			Spec: cmacme.ChallengeSpec{
+				Solver: cmacme.ACMEChallengeSolver{
+					DNS01: &cmacme.ACMEChallengeSolverDNS01{
+						Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
+							AccessKeyID: "test",
+							Region:      "us-west-2",
+							SecretAccessKey: cmmeta.SecretKeySelector{
+								LocalObjectReference: cmmeta.LocalObjectReference{
+									Name: "route53",
+								},
+								Key: "secret",
+							},
+						},
+					},
+				},
+			},
+		},
+		dnsProviders: newFakeDNSProviders(),
+	}
+
+	f.Setup(t)
+	defer f.Finish(t)
+
+	s := f.Solver
+	if _, _, err := s.solverForChallenge(context.Background(), f.Issuer, f.Challenge); err != nil {
+		t.Fatalf("expected solverFor to not error, but got: %s", err)
+	}
+
+	// rotate the credentials
+	rotated := newSecret("route53", "default", map[string][]byte{
+		"secret": []byte("new-secret"),
+	})
+	if _, err := f.Client.CoreV1().Secrets("default").Update(context.Background(), rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	f.Builder.Sync()
+
+	if _, _, err := s.solverForChallenge(context.Background(), f.Issuer, f.Challenge); err != nil {
+		t.Fatalf("expected solverFor to not error, but got: %s", err)
+	}
+
+	expectedR53Calls := []fakeDNSProviderCall{
+		{
+			name: "route53",
+			args: []interface{}{"test", "old-secret", "", "us-west-2", "", "", false, util.RecursiveNameservers},
+		},
+		{
+			name: "route53",
+			args: []interface{}{"test", "new-secret", "", "us-west-2", "", "", false, util.RecursiveNameservers},
+		},
+	}
+
+	if !reflect.DeepEqual(expectedR53Calls, f.dnsProviders.calls) {
+		t.Fatalf("expected %+v == %+v", expectedR53Calls, f.dnsProviders.calls)
+	}
+}
+
 func TestRoute53AmbientCreds(t *testing.T) {
 	type result struct {
 		expectedCall *fakeDNSProviderCall
@@ -439,7 +520,7 @@ func TestRoute53AmbientCreds(t *testing.T) {
 			result{
 				expectedCall: &fakeDNSProviderCall{
 					name: "route53",
-					args: []interface{}{"", "", "", "us-west-2", "", true, util.RecursiveNameservers},
+					args: []interface{}{"", "", "", "us-west-2", "", "", true, util.RecursiveNameservers},
 				},
 			},
 		},
@@ -469,7 +550,7 @@ func TestRoute53AmbientCreds(t *testing.T) {
 			result{
 				expectedCall: &fakeDNSProviderCall{
 					name: "route53",
-					args: []interface{}{"", "", "", "us-west-2", "", false, util.RecursiveNameservers},
+					args: []interface{}{"", "", "", "us-west-2", "", "", false, util.RecursiveNameservers},
 				},
 			},
 		},
@@ -493,6 +574,209 @@ func TestRoute53AmbientCreds(t *testing.T) {
 	}
 }
 
+func TestAzureDNSWorkloadIdentity(t *testing.T) {
+	azureDNSSolver := func(wi *cmacme.AzureWorkloadIdentity) *cmacme.Challenge {
+		return &cmacme.Challenge{
+			Spec: cmacme.ChallengeSpec{
+				Solver: cmacme.ACMEChallengeSolver{
+					DNS01: &cmacme.ACMEChallengeSolverDNS01{
+						AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
+							ClientID:          "client-id",
+							TenantID:          "tenant-id",
+							SubscriptionID:    "subscription-id",
+							ResourceGroupName: "resource-group",
+							WorkloadIdentity:  wi,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		workloadIdentity *cmacme.AzureWorkloadIdentity
+		tokenFile        string
+		tokenFileEnv     string
+		expectedCall     *fakeDNSProviderCall
+		expectErr        bool
+	}{
+		"should use the token file configured on the controller": {
+			workloadIdentity: &cmacme.AzureWorkloadIdentity{},
+			tokenFile:        "/var/run/token",
+			tokenFileEnv:     "/var/run/env-token",
+			expectedCall: &fakeDNSProviderCall{
+				name: "azuredns",
+				args: []interface{}{"client-id", "", "subscription-id", "tenant-id", "resource-group", "", util.RecursiveNameservers, true, "/var/run/token"},
+			},
+		},
+		"should default the token file from the environment": {
+			workloadIdentity: &cmacme.AzureWorkloadIdentity{},
+			tokenFileEnv:     "/var/run/env-token",
+			expectedCall: &fakeDNSProviderCall{
+				name: "azuredns",
+				args: []interface{}{"client-id", "", "subscription-id", "tenant-id", "resource-group", "", util.RecursiveNameservers, true, "/var/run/env-token"},
+			},
+		},
+		"should fail if no token file is configured": {
+			workloadIdentity: &cmacme.AzureWorkloadIdentity{},
+			expectErr:        true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv(azuredns.FederatedTokenFileEnvVar, tt.tokenFileEnv)
+			defer os.Unsetenv(azuredns.FederatedTokenFileEnvVar)
+
+			f := solverFixture{
+				Builder: &test.Builder{
+					Context: &controller.Context{
+						IssuerOptions: controller.IssuerOptions{
+							IssuerAmbientCredentials: true,
+						},
+						ACMEOptions: controller.ACMEOptions{
+							DNS01AzureFederatedTokenFile: tt.tokenFile,
+						},
+					},
+				},
+				Issuer:       newIssuer("test", "default"),
+				dnsProviders: newFakeDNSProviders(),
+				Challenge:    azureDNSSolver(tt.workloadIdentity),
+			}
+			f.Setup(t)
+			defer f.Finish(t)
+
+			_, _, err := f.Solver.solverForChallenge(context.Background(), f.Issuer, f.Challenge)
+			if tt.expectErr != (err != nil) {
+				t.Fatalf("expected error=%t, got: %v", tt.expectErr, err)
+			}
+			if tt.expectedCall != nil && !reflect.DeepEqual([]fakeDNSProviderCall{*tt.expectedCall}, f.dnsProviders.calls) {
+				t.Fatalf("expected %+v == %+v", []fakeDNSProviderCall{*tt.expectedCall}, f.dnsProviders.calls)
+			}
+		})
+	}
+}
+
+func TestCredentialsFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dns-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	if err := ioutil.WriteFile(credentialsFile, []byte("file-credentials\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		solver         *cmacme.ACMEChallengeSolverDNS01
+		ambientAllowed bool
+		expectedCall   *fakeDNSProviderCall
+		expectErr      bool
+	}{
+		"should load the route53 secret access key from a file": {
+			solver: &cmacme.ACMEChallengeSolverDNS01{
+				Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
+					AccessKeyID:         "access-key-id",
+					SecretAccessKeyFile: credentialsFile,
+					Region:              "us-west-2",
+				},
+			},
+			ambientAllowed: true,
+			expectedCall: &fakeDNSProviderCall{
+				name: "route53",
+				args: []interface{}{"access-key-id", "file-credentials", "", "us-west-2", "", "", true, util.RecursiveNameservers},
+			},
+		},
+		"should load the clouddns service account from a file": {
+			solver: &cmacme.ACMEChallengeSolverDNS01{
+				CloudDNS: &cmacme.ACMEIssuerDNS01ProviderCloudDNS{
+					Project:            "project",
+					ServiceAccountFile: credentialsFile,
+				},
+			},
+			ambientAllowed: true,
+			expectedCall: &fakeDNSProviderCall{
+				name: "clouddns",
+				args: []interface{}{"project", []byte("file-credentials\n"), util.RecursiveNameservers, true, ""},
+			},
+		},
+		"should load the azuredns client secret from a file": {
+			solver: &cmacme.ACMEChallengeSolverDNS01{
+				AzureDNS: &cmacme.ACMEIssuerDNS01ProviderAzureDNS{
+					ClientID:          "client-id",
+					ClientSecretFile:  credentialsFile,
+					TenantID:          "tenant-id",
+					SubscriptionID:    "subscription-id",
+					ResourceGroupName: "resource-group",
+				},
+			},
+			ambientAllowed: true,
+			expectedCall: &fakeDNSProviderCall{
+				name: "azuredns",
+				args: []interface{}{"client-id", "file-credentials", "subscription-id", "tenant-id", "resource-group", "", util.RecursiveNameservers, true, ""},
+			},
+		},
+		"should fail if the file does not exist": {
+			solver: &cmacme.ACMEChallengeSolverDNS01{
+				Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
+					SecretAccessKeyFile: filepath.Join(dir, "missing"),
+					Region:              "us-west-2",
+				},
+			},
+			ambientAllowed: true,
+			expectErr:      true,
+		},
+		"should fail if the issuer may not use ambient credentials": {
+			solver: &cmacme.ACMEChallengeSolverDNS01{
+				Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
+					SecretAccessKeyFile: credentialsFile,
+					Region:              "us-west-2",
+				},
+			},
+			ambientAllowed: false,
+			expectErr:      true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := solverFixture{
+				Builder: &test.Builder{
+					Context: &controller.Context{
+						IssuerOptions: controller.IssuerOptions{
+							IssuerAmbientCredentials: tt.ambientAllowed,
+						},
+					},
+				},
+				Issuer:       newIssuer("test", "default"),
+				dnsProviders: newFakeDNSProviders(),
+				Challenge: &cmacme.Challenge{
+					Spec: cmacme.ChallengeSpec{
+						Solver: cmacme.ACMEChallengeSolver{
+							DNS01: tt.solver,
+						},
+					},
+				},
+			}
+			f.Setup(t)
+			defer f.Finish(t)
+
+			_, _, err := f.Solver.solverForChallenge(context.Background(), f.Issuer, f.Challenge)
+			if tt.expectErr != (err != nil) {
+				t.Fatalf("expected error=%t, got: %v", tt.expectErr, err)
+			}
+			if tt.expectErr && len(f.dnsProviders.calls) > 0 {
+				t.Fatalf("expected no DNS provider to be constructed, got %+v", f.dnsProviders.calls)
+			}
+			if tt.expectedCall != nil && !reflect.DeepEqual([]fakeDNSProviderCall{*tt.expectedCall}, f.dnsProviders.calls) {
+				t.Fatalf("expected %+v == %+v", []fakeDNSProviderCall{*tt.expectedCall}, f.dnsProviders.calls)
+			}
+		})
+	}
+}
+
 func TestRoute53AssumeRole(t *testing.T) {
 	type result struct {
 		expectedCall *fakeDNSProviderCall
@@ -530,7 +814,7 @@ func TestRoute53AssumeRole(t *testing.T) {
 			result{
 				expectedCall: &fakeDNSProviderCall{
 					name: "route53",
-					args: []interface{}{"", "", "", "us-west-2", "my-role", true, util.RecursiveNameservers},
+					args: []interface{}{"", "", "", "us-west-2", "my-role", "", true, util.RecursiveNameservers},
 				},
 			},
 		},
@@ -550,8 +834,9 @@ func TestRoute53AssumeRole(t *testing.T) {
 						Solver: cmacme.ACMEChallengeSolver{
 							DNS01: &cmacme.ACMEChallengeSolverDNS01{
 								Route53: &cmacme.ACMEIssuerDNS01ProviderRoute53{
-									Region: "us-west-2",
-									Role:   "my-other-role",
+									Region:     "us-west-2",
+									Role:       "my-other-role",
+									ExternalID: "my-external-id",
 								},
 							},
 						},
@@ -561,7 +846,7 @@ func TestRoute53AssumeRole(t *testing.T) {
 			result{
 				expectedCall: &fakeDNSProviderCall{
 					name: "route53",
-					args: []interface{}{"", "", "", "us-west-2", "my-other-role", false, util.RecursiveNameservers},
+					args: []interface{}{"", "", "", "us-west-2", "my-other-role", "my-external-id", false, util.RecursiveNameservers},
 				},
 			},
 		},
@@ -584,3 +869,136 @@ func TestRoute53AssumeRole(t *testing.T) {
 		}
 	}
 }
+
+// fakeWebhookSolver records the challenge requests it is called with.
+type fakeWebhookSolver struct {
+	name       string
+	cleanUpErr error
+
+	presented []*whapi.ChallengeRequest
+	cleanedUp []*whapi.ChallengeRequest
+}
+
+func (f *fakeWebhookSolver) Name() string {
+	return f.name
+}
+
+func (f *fakeWebhookSolver) Present(ch *whapi.ChallengeRequest) error {
+	f.presented = append(f.presented, ch)
+	return nil
+}
+
+func (f *fakeWebhookSolver) CleanUp(ch *whapi.ChallengeRequest) error {
+	f.cleanedUp = append(f.cleanedUp, ch)
+	return f.cleanUpErr
+}
+
+func (f *fakeWebhookSolver) Initialize(kubeClientConfig *restclient.Config, stopCh <-chan struct{}) error {
+	return nil
+}
+
+func TestAdditionalProviders(t *testing.T) {
+	ctx := logf.NewContext(context.Background(), nil, t.Name())
+
+	// the test server answers the SOA queries used to find the zone of the
+	// challenge record
+	server := &testserver.BasicServer{Zones: []string{"example.com."}}
+	if err := server.Run(ctx); err != nil {
+		t.Fatalf("failed to start test server: %v", err)
+	}
+	defer server.Shutdown()
+
+	newFixture := func(t *testing.T, cleanUpErr error) (*solverFixture, *fakeWebhookSolver, *fakeWebhookSolver) {
+		f := &solverFixture{
+			Issuer: newIssuer("test", "default"),
+			Challenge: &cmacme.Challenge{
+				Spec: cmacme.ChallengeSpec{
+					DNSName: "www.example.com",
+					Key:     "token",
+					Solver: cmacme.ACMEChallengeSolver{
+						DNS01: &cmacme.ACMEChallengeSolverDNS01{
+							Webhook: &cmacme.ACMEIssuerDNS01ProviderWebhook{GroupName: "example.com", SolverName: "primary"},
+							AdditionalProviders: []cmacme.ACMEChallengeSolverDNS01{
+								{RFC2136: &cmacme.ACMEIssuerDNS01ProviderRFC2136{Nameserver: "127.0.0.1"}},
+							},
+						},
+					},
+				},
+			},
+			dnsProviders: newFakeDNSProviders(),
+		}
+		f.Setup(t)
+
+		primary := &fakeWebhookSolver{name: "webhook", cleanUpErr: cleanUpErr}
+		additional := &fakeWebhookSolver{name: "rfc2136"}
+		f.Solver.webhookSolvers = map[string]webhook.Solver{
+			primary.name:    primary,
+			additional.name: additional,
+		}
+		f.Solver.DNS01Nameservers = []string{server.ListenAddr()}
+		return f, primary, additional
+	}
+
+	assertRequest := func(t *testing.T, reqs []*whapi.ChallengeRequest) {
+		if len(reqs) != 1 {
+			t.Fatalf("expected 1 challenge request, got %d", len(reqs))
+		}
+		req := reqs[0]
+		if req.ResolvedFQDN != "_acme-challenge.www.example.com." || req.ResolvedZone != "example.com." || req.Key != "token" {
+			t.Errorf("unexpected challenge request: %+v", req)
+		}
+	}
+
+	t.Run("presents the record to every provider", func(t *testing.T) {
+		f, primary, additional := newFixture(t, nil)
+		defer f.Finish(t)
+
+		if err := f.Solver.Present(ctx, f.Issuer, f.Challenge); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertRequest(t, primary.presented)
+		assertRequest(t, additional.presented)
+	})
+
+	t.Run("cleans up the record from every provider", func(t *testing.T) {
+		f, primary, additional := newFixture(t, nil)
+		defer f.Finish(t)
+
+		if err := f.Solver.CleanUp(ctx, f.Issuer, f.Challenge); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertRequest(t, primary.cleanedUp)
+		assertRequest(t, additional.cleanedUp)
+	})
+
+	t.Run("cleans up the record from the remaining providers if one fails", func(t *testing.T) {
+		f, primary, additional := newFixture(t, errors.New("cleanup failed"))
+		defer f.Finish(t)
+
+		if err := f.Solver.CleanUp(ctx, f.Issuer, f.Challenge); err == nil {
+			t.Fatal("expected an error but got none")
+		}
+		assertRequest(t, primary.cleanedUp)
+		assertRequest(t, additional.cleanedUp)
+	})
+
+	t.Run("checks every authoritative nameserver", func(t *testing.T) {
+		f, _, _ := newFixture(t, nil)
+		defer f.Finish(t)
+
+		preCheckDNS := util.PreCheckDNS
+		defer func() { util.PreCheckDNS = preCheckDNS }()
+		var checkedAuthoritative bool
+		util.PreCheckDNS = func(fqdn, value string, nameservers []string, useAuthoritative bool) (bool, error) {
+			checkedAuthoritative = useAuthoritative
+			return false, nil
+		}
+
+		if err := f.Solver.Check(ctx, f.Issuer, f.Challenge); err == nil {
+			t.Fatal("expected an error as the record has not propagated")
+		}
+		if !checkedAuthoritative {
+			t.Error("expected the authoritative nameservers to be checked")
+		}
+	})
+}
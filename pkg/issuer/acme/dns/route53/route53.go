@@ -49,6 +49,7 @@ type sessionProvider struct {
 	Ambient         bool
 	Region          string
 	Role            string
+	ExternalID      string
 	StsProvider     func(*session.Session) stsiface.STSAPI
 	log             logr.Logger
 }
@@ -90,10 +91,14 @@ func (d *sessionProvider) GetSession() (*session.Session, error) {
 	if d.Role != "" {
 		d.log.V(logf.DebugLevel).WithValues("role", d.Role).Info("assuming role")
 		stsSvc := d.StsProvider(sess)
-		result, err := stsSvc.AssumeRole(&sts.AssumeRoleInput{
+		input := &sts.AssumeRoleInput{
 			RoleArn:         aws.String(d.Role),
 			RoleSessionName: aws.String("cert-manager"),
-		})
+		}
+		if d.ExternalID != "" {
+			input.ExternalId = aws.String(d.ExternalID)
+		}
+		result, err := stsSvc.AssumeRole(input)
 		if err != nil {
 			return nil, fmt.Errorf("unable to assume role: %s", err)
 		}
@@ -122,13 +127,14 @@ func (d *sessionProvider) GetSession() (*session.Session, error) {
 	return sess, nil
 }
 
-func newSessionProvider(accessKeyID, secretAccessKey, region, role string, ambient bool) (*sessionProvider, error) {
+func newSessionProvider(accessKeyID, secretAccessKey, region, role, externalID string, ambient bool) (*sessionProvider, error) {
 	return &sessionProvider{
 		AccessKeyID:     accessKeyID,
 		SecretAccessKey: secretAccessKey,
 		Ambient:         ambient,
 		Region:          region,
 		Role:            role,
+		ExternalID:      externalID,
 		StsProvider:     defaultSTSProvider,
 		log:             logf.Log.WithName("route53-session-provider"),
 	}, nil
@@ -141,8 +147,9 @@ func defaultSTSProvider(sess *session.Session) stsiface.STSAPI {
 // NewDNSProvider returns a DNSProvider instance configured for the AWS
 // Route 53 service using static credentials from its parameters or, if they're
 // unset and the 'ambient' option is set, credentials from the environment.
-func NewDNSProvider(accessKeyID, secretAccessKey, hostedZoneID, region, role string, ambient bool, dns01Nameservers []string) (*DNSProvider, error) {
-	provider, err := newSessionProvider(accessKeyID, secretAccessKey, region, role, ambient)
+// If role is set it is assumed, passing externalID to STS if that is set.
+func NewDNSProvider(accessKeyID, secretAccessKey, hostedZoneID, region, role, externalID string, ambient bool, dns01Nameservers []string) (*DNSProvider, error) {
+	provider, err := newSessionProvider(accessKeyID, secretAccessKey, region, role, externalID, ambient)
 	if err != nil {
 		return nil, err
 	}
@@ -164,40 +171,77 @@ func NewDNSProvider(accessKeyID, secretAccessKey, hostedZoneID, region, role str
 
 // Present creates a TXT record using the specified parameters
 func (r *DNSProvider) Present(domain, fqdn, value string) error {
-	value = `"` + value + `"`
-	return r.changeRecord(route53.ChangeActionUpsert, fqdn, value, route53TTL)
+	return r.changeRecords(route53.ChangeActionUpsert, []util.TXTRecord{{Domain: domain, FQDN: fqdn, Value: value}}, route53TTL)
 }
 
 // CleanUp removes the TXT record matching the specified parameters
 func (r *DNSProvider) CleanUp(domain, fqdn, value string) error {
-	value = `"` + value + `"`
-	return r.changeRecord(route53.ChangeActionDelete, fqdn, value, route53TTL)
+	return r.changeRecords(route53.ChangeActionDelete, []util.TXTRecord{{Domain: domain, FQDN: fqdn, Value: value}}, route53TTL)
 }
 
-func (r *DNSProvider) changeRecord(action, fqdn, value string, ttl int) error {
-	hostedZoneID, err := r.getHostedZoneID(fqdn)
+// PresentBatch creates TXT records for all of the given records using a
+// single change batch. Records with the same FQDN are combined into a single
+// record set with multiple values.
+func (r *DNSProvider) PresentBatch(records []util.TXTRecord) error {
+	return r.changeRecords(route53.ChangeActionUpsert, records, route53TTL)
+}
+
+// CleanUpBatch removes the TXT records matching all of the given records
+// using a single change batch.
+func (r *DNSProvider) CleanUpBatch(records []util.TXTRecord) error {
+	return r.changeRecords(route53.ChangeActionDelete, records, route53TTL)
+}
+
+// changeRecords applies the action to the TXT record sets of the given
+// records, which must all be in the same hosted zone.
+func (r *DNSProvider) changeRecords(action string, records []util.TXTRecord, ttl int) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	hostedZoneID, err := r.getHostedZoneID(records[0].FQDN)
 	if err != nil {
 		return fmt.Errorf("failed to determine Route 53 hosted zone ID: %v", err)
 	}
 
-	recordSet := newTXTRecordSet(fqdn, value, ttl)
+	// Route 53 rejects change batches that change the same record set more
+	// than once, so group the values by FQDN whilst preserving their order.
+	var fqdns []string
+	values := make(map[string][]string)
+	for _, record := range records {
+		if _, ok := values[record.FQDN]; !ok {
+			fqdns = append(fqdns, record.FQDN)
+		}
+		values[record.FQDN] = append(values[record.FQDN], `"`+record.Value+`"`)
+	}
+
+	changes := make([]*route53.Change, 0, len(fqdns))
+	for _, fqdn := range fqdns {
+		changes = append(changes, &route53.Change{
+			Action:            aws.String(action),
+			ResourceRecordSet: newTXTRecordSet(fqdn, values[fqdn], ttl),
+		})
+	}
+
+	return r.submitChanges(action, hostedZoneID, changes)
+}
+
+func (r *DNSProvider) submitChanges(action, hostedZoneID string, changes []*route53.Change) error {
 	reqParams := &route53.ChangeResourceRecordSetsInput{
 		HostedZoneId: aws.String(hostedZoneID),
 		ChangeBatch: &route53.ChangeBatch{
 			Comment: aws.String("Managed by cert-manager"),
-			Changes: []*route53.Change{
-				{
-					Action:            &action,
-					ResourceRecordSet: recordSet,
-				},
-			},
+			Changes: changes,
 		},
 	}
 
 	resp, err := r.client.ChangeResourceRecordSets(reqParams)
 	if err != nil {
 		if awserr, ok := err.(awserr.Error); ok {
-			if action == route53.ChangeActionDelete && awserr.Code() == route53.ErrCodeInvalidChangeBatch {
+			// A batch that changes more than one record may have been
+			// rejected because of any of them, so the error is returned
+			// for the records to be retried on their own.
+			if action == route53.ChangeActionDelete && awserr.Code() == route53.ErrCodeInvalidChangeBatch && isSingleRecordChange(changes) {
 				r.log.V(logf.DebugLevel).WithValues("error", err).Info("ignoring InvalidChangeBatch error")
 				// If we try to delete something and get a 'InvalidChangeBatch' that
 				// means it's already deleted, no need to consider it an error.
@@ -271,14 +315,21 @@ func (r *DNSProvider) getHostedZoneID(fqdn string) (string, error) {
 	return hostedZoneID, nil
 }
 
-func newTXTRecordSet(fqdn, value string, ttl int) *route53.ResourceRecordSet {
+// isSingleRecordChange returns true if the changes change a single record.
+func isSingleRecordChange(changes []*route53.Change) bool {
+	return len(changes) == 1 && len(changes[0].ResourceRecordSet.ResourceRecords) == 1
+}
+
+func newTXTRecordSet(fqdn string, values []string, ttl int) *route53.ResourceRecordSet {
+	resourceRecords := make([]*route53.ResourceRecord, 0, len(values))
+	for _, value := range values {
+		resourceRecords = append(resourceRecords, &route53.ResourceRecord{Value: aws.String(value)})
+	}
 	return &route53.ResourceRecordSet{
-		Name: aws.String(fqdn),
-		Type: aws.String(route53.RRTypeTxt),
-		TTL:  aws.Int64(int64(ttl)),
-		ResourceRecords: []*route53.ResourceRecord{
-			{Value: aws.String(value)},
-		},
+		Name:            aws.String(fqdn),
+		Type:            aws.String(route53.RRTypeTxt),
+		TTL:             aws.Int64(int64(ttl)),
+		ResourceRecords: resourceRecords,
 	}
 }
 
@@ -9,10 +9,13 @@ this directory.
 package route53
 
 import (
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	logf "github.com/jetstack/cert-manager/pkg/logs"
@@ -61,7 +64,7 @@ func makeRoute53Provider(ts *httptest.Server) (*DNSProvider, error) {
 		return nil, err
 	}
 	client := route53.New(sess)
-	return &DNSProvider{client: client, dns01Nameservers: util.RecursiveNameservers}, nil
+	return &DNSProvider{client: client, dns01Nameservers: util.RecursiveNameservers, log: logf.Log.WithName("route53")}, nil
 }
 
 func TestAmbientCredentialsFromEnv(t *testing.T) {
@@ -70,7 +73,7 @@ func TestAmbientCredentialsFromEnv(t *testing.T) {
 	os.Setenv("AWS_REGION", "us-east-1")
 	defer restoreRoute53Env()
 
-	provider, err := NewDNSProvider("", "", "", "", "", true, util.RecursiveNameservers)
+	provider, err := NewDNSProvider("", "", "", "", "", "", true, util.RecursiveNameservers)
 	assert.NoError(t, err, "Expected no error constructing DNSProvider")
 
 	_, err = provider.client.Config.Credentials.Get()
@@ -84,7 +87,7 @@ func TestNoCredentialsFromEnv(t *testing.T) {
 	os.Setenv("AWS_REGION", "us-east-1")
 	defer restoreRoute53Env()
 
-	_, err := NewDNSProvider("", "", "", "", "", false, util.RecursiveNameservers)
+	_, err := NewDNSProvider("", "", "", "", "", "", false, util.RecursiveNameservers)
 	assert.Error(t, err, "Expected error constructing DNSProvider with no credentials and not ambient")
 }
 
@@ -92,7 +95,7 @@ func TestAmbientRegionFromEnv(t *testing.T) {
 	os.Setenv("AWS_REGION", "us-east-1")
 	defer restoreRoute53Env()
 
-	provider, err := NewDNSProvider("", "", "", "", "", true, util.RecursiveNameservers)
+	provider, err := NewDNSProvider("", "", "", "", "", "", true, util.RecursiveNameservers)
 	assert.NoError(t, err, "Expected no error constructing DNSProvider")
 
 	assert.Equal(t, "us-east-1", *provider.client.Config.Region, "Expected Region to be set from environment")
@@ -102,7 +105,7 @@ func TestNoRegionFromEnv(t *testing.T) {
 	os.Setenv("AWS_REGION", "us-east-1")
 	defer restoreRoute53Env()
 
-	provider, err := NewDNSProvider("marx", "swordfish", "", "", "", false, util.RecursiveNameservers)
+	provider, err := NewDNSProvider("marx", "swordfish", "", "", "", "", false, util.RecursiveNameservers)
 	assert.NoError(t, err, "Expected no error constructing DNSProvider")
 
 	assert.Equal(t, "", *provider.client.Config.Region, "Expected Region to not be set from environment")
@@ -149,6 +152,86 @@ func TestRoute53Present(t *testing.T) {
 	assert.Equal(t, `failed to change Route 53 record set: AccessDenied: User: arn:aws:iam::0123456789:user/test-cert-manager is not authorized to perform: route53:ChangeResourceRecordSets on resource: arn:aws:route53:::hostedzone/OPQRSTU`, err.Error())
 }
 
+// changeResourceRecordSetsRequest is the subset of the XML request body of a
+// ChangeResourceRecordSets call that is checked in tests.
+type changeResourceRecordSetsRequest struct {
+	Changes []struct {
+		Action string
+		Name   string   `xml:"ResourceRecordSet>Name"`
+		Values []string `xml:"ResourceRecordSet>ResourceRecords>ResourceRecord>Value"`
+	} `xml:"ChangeBatch>Changes>Change"`
+}
+
+func TestRoute53PresentBatch(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests []changeResourceRecordSetsRequest
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		switch r.URL.Path {
+		case "/2013-04-01/hostedzone/ABCDEFG/rrset/":
+			var req changeResourceRecordSetsRequest
+			assert.NoError(t, xml.NewDecoder(r.Body).Decode(&req))
+			lock.Lock()
+			requests = append(requests, req)
+			lock.Unlock()
+			_, _ = w.Write([]byte(ChangeResourceRecordSetsResponse))
+		case "/2013-04-01/change/123456":
+			_, _ = w.Write([]byte(GetChangeResponse))
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	provider, err := makeRoute53Provider(ts)
+	require.NoError(t, err, "Expected to make a Route 53 provider without error")
+	provider.hostedZoneID = "ABCDEFG"
+
+	err = provider.PresentBatch([]util.TXTRecord{
+		{Domain: "example.com", FQDN: "_acme-challenge.example.com.", Value: "key1"},
+		{Domain: "foo.example.com", FQDN: "_acme-challenge.foo.example.com.", Value: "key2"},
+		{Domain: "*.example.com", FQDN: "_acme-challenge.example.com.", Value: "key3"},
+	})
+	require.NoError(t, err, "Expected PresentBatch to return no error")
+
+	require.Len(t, requests, 1, "Expected a single ChangeResourceRecordSets request")
+	changes := requests[0].Changes
+	require.Len(t, changes, 2, "Expected the records to be grouped by FQDN")
+	assert.Equal(t, route53.ChangeActionUpsert, changes[0].Action)
+	assert.Equal(t, "_acme-challenge.example.com.", changes[0].Name)
+	assert.Equal(t, []string{`"key1"`, `"key3"`}, changes[0].Values)
+	assert.Equal(t, route53.ChangeActionUpsert, changes[1].Action)
+	assert.Equal(t, "_acme-challenge.foo.example.com.", changes[1].Name)
+	assert.Equal(t, []string{`"key2"`}, changes[1].Values)
+}
+
+func TestRoute53CleanUpInvalidChangeBatch(t *testing.T) {
+	mockResponses := MockResponseMap{
+		"/2013-04-01/hostedzone/ABCDEFG/rrset/": MockResponse{StatusCode: 400, Body: ChangeResourceRecordSetsInvalidChangeBatchResponse},
+	}
+
+	ts := newMockServer(t, mockResponses)
+	defer ts.Close()
+
+	provider, err := makeRoute53Provider(ts)
+	require.NoError(t, err, "Expected to make a Route 53 provider without error")
+	provider.hostedZoneID = "ABCDEFG"
+
+	// deleting a single record that does not exist means it has already been
+	// cleaned up
+	err = provider.CleanUp("example.com", "_acme-challenge.example.com.", "key1")
+	assert.NoError(t, err, "Expected CleanUp to ignore an InvalidChangeBatch error for a single record")
+
+	err = provider.CleanUpBatch([]util.TXTRecord{
+		{Domain: "example.com", FQDN: "_acme-challenge.example.com.", Value: "key1"},
+		{Domain: "foo.example.com", FQDN: "_acme-challenge.foo.example.com.", Value: "key2"},
+	})
+	assert.Error(t, err, "Expected CleanUpBatch to return an InvalidChangeBatch error for multiple records")
+}
+
 func TestAssumeRole(t *testing.T) {
 	creds := &sts.Credentials{
 		AccessKeyId:     aws.String("foo"),
@@ -156,16 +239,17 @@ func TestAssumeRole(t *testing.T) {
 		SessionToken:    aws.String("my-token"),
 	}
 	cases := []struct {
-		name      string
-		ambient   bool
-		role      string
-		expErr    bool
-		expCreds  *sts.Credentials
-		expRegion string
-		key       string
-		secret    string
-		region    string
-		mockSTS   *mockSTS
+		name       string
+		ambient    bool
+		role       string
+		externalID string
+		expErr     bool
+		expCreds   *sts.Credentials
+		expRegion  string
+		key        string
+		secret     string
+		region     string
+		mockSTS    *mockSTS
 	}{
 		{
 			name:      "should assume role w/ ambient creds",
@@ -202,6 +286,24 @@ func TestAssumeRole(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:       "should pass the external ID when assuming role",
+			ambient:    false,
+			role:       "my-role",
+			externalID: "my-external-id",
+			key:        "key",
+			secret:     "secret",
+			region:     "eu-central-1",
+			expErr:     false,
+			expCreds:   creds,
+			mockSTS: &mockSTS{
+				AssumeRoleFn: func(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+					return &sts.AssumeRoleOutput{
+						Credentials: creds,
+					}, nil
+				},
+			},
+		},
 		{
 			name:    "no role set: do NOT assume role and use provided credentials",
 			ambient: true,
@@ -244,7 +346,7 @@ func TestAssumeRole(t *testing.T) {
 		t.Run(c.name, func(t *testing.T) {
 			provider, err := makeMockSessionProvider(func(sess *session.Session) stsiface.STSAPI {
 				return c.mockSTS
-			}, c.key, c.secret, c.region, c.role, c.ambient)
+			}, c.key, c.secret, c.region, c.role, c.externalID, c.ambient)
 			assert.NoError(t, err)
 			sess, err := provider.GetSession()
 			if c.expErr {
@@ -252,6 +354,7 @@ func TestAssumeRole(t *testing.T) {
 			} else {
 				sessCreds, _ := sess.Config.Credentials.Get()
 				assert.Equal(t, c.mockSTS.assumedRole, c.role)
+				assert.Equal(t, c.mockSTS.externalID, c.externalID)
 				assert.Equal(t, *c.expCreds.SecretAccessKey, sessCreds.SecretAccessKey)
 				assert.Equal(t, *c.expCreds.AccessKeyId, sessCreds.AccessKeyID)
 				assert.Equal(t, c.region, *sess.Config.Region)
@@ -264,24 +367,27 @@ type mockSTS struct {
 	*sts.STS
 	AssumeRoleFn func(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error)
 	assumedRole  string
+	externalID   string
 }
 
 func (m *mockSTS) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
 	if m.AssumeRoleFn != nil {
 		m.assumedRole = *input.RoleArn
+		m.externalID = aws.StringValue(input.ExternalId)
 		return m.AssumeRoleFn(input)
 	}
 
 	return nil, nil
 }
 
-func makeMockSessionProvider(defaultSTSProvider func(sess *session.Session) stsiface.STSAPI, accessKeyID, secretAccessKey, region, role string, ambient bool) (*sessionProvider, error) {
+func makeMockSessionProvider(defaultSTSProvider func(sess *session.Session) stsiface.STSAPI, accessKeyID, secretAccessKey, region, role, externalID string, ambient bool) (*sessionProvider, error) {
 	return &sessionProvider{
 		AccessKeyID:     accessKeyID,
 		SecretAccessKey: secretAccessKey,
 		Ambient:         ambient,
 		Region:          region,
 		Role:            role,
+		ExternalID:      externalID,
 		StsProvider:     defaultSTSProvider,
 		log:             logf.Log.WithName("route53-session"),
 	}, nil
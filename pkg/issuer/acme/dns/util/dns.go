@@ -55,3 +55,14 @@ func FindBestMatch(query string, domains ...string) (string, error) {
 	}
 	return longest, nil
 }
+
+// TXTRecord is a DNS01 challenge record, as passed to the Present and CleanUp
+// methods of a DNS provider.
+type TXTRecord struct {
+	// Domain is the domain name being validated
+	Domain string
+	// FQDN is the fully qualified name of the TXT record
+	FQDN string
+	// Value is the value of the TXT record
+	Value string
+}
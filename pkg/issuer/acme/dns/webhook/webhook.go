@@ -21,11 +21,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 
 	"github.com/jetstack/cert-manager/pkg/acme/webhook/apis/acme/v1alpha1"
@@ -36,6 +41,31 @@ import (
 
 type Webhook struct {
 	restConfigShallowCopy rest.Config
+
+	// secretLister is used to load the client certificates referenced by
+	// webhook solver configurations that use mutual TLS.
+	secretLister corelisters.SecretLister
+
+	// If specified, namespace will cause the webhook provider to limit the
+	// scope of the lister/watcher to a single namespace, to allow for
+	// namespace restricted instances of cert-manager.
+	namespace string
+}
+
+type Option func(*Webhook)
+
+func WithNamespace(ns string) Option {
+	return func(r *Webhook) {
+		r.namespace = ns
+	}
+}
+
+func New(opts ...Option) *Webhook {
+	r := &Webhook{}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
 }
 
 func (r *Webhook) Name() string {
@@ -115,19 +145,37 @@ func (r *Webhook) CleanUp(ch *v1alpha1.ChallengeRequest) error {
 }
 
 func (r *Webhook) Initialize(kubeClientConfig *rest.Config, stopCh <-chan struct{}) error {
+	r.restConfigShallowCopy = webhookRESTConfig(kubeClientConfig)
+
+	cl, err := kubernetes.NewForConfig(kubeClientConfig)
+	if err != nil {
+		return err
+	}
+
+	// obtain a secret lister and start the informer factory to populate the
+	// secret cache
+	factory := informers.NewSharedInformerFactoryWithOptions(cl, time.Minute*5, informers.WithNamespace(r.namespace))
+	r.secretLister = factory.Core().V1().Secrets().Lister()
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	return nil
+}
+
+// webhookRESTConfig returns a shallow copy of the given config that is
+// suitable for POSTing ChallengePayload resources to a webhook apiserver.
+func webhookRESTConfig(kubeClientConfig *rest.Config) rest.Config {
 	cfgShallowCopy := *kubeClientConfig
 	cfgShallowCopy.APIPath = "/apis"
 	cfgShallowCopy.NegotiatedSerializer = serializer.WithoutConversionCodecFactory{CodecFactory: scheme.Codecs}
 	// We defer setting the GroupVersion of the rest client config to the
-	// restClientForGroup function.
+	// restClientForConfig function.
 
 	if cfgShallowCopy.UserAgent == "" {
 		cfgShallowCopy.UserAgent = rest.DefaultKubernetesUserAgent()
 	}
 
-	r.restConfigShallowCopy = cfgShallowCopy
-
-	return nil
+	return cfgShallowCopy
 }
 
 func (r *Webhook) buildPayload(ch *v1alpha1.ChallengeRequest, action v1alpha1.ChallengeAction) (*rest.RESTClient, *v1alpha1.ChallengePayload, string, error) {
@@ -141,7 +189,7 @@ func (r *Webhook) buildPayload(ch *v1alpha1.ChallengeRequest, action v1alpha1.Ch
 	}
 
 	// obtain a REST client that can be used to communicate with the webhook
-	cl, err := r.restClientForGroup(cfg.GroupName)
+	cl, err := r.restClientForConfig(cfg, req.ResourceNamespace)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -169,12 +217,57 @@ func loadConfig(cfgJSON apiext.JSON) (*cmacme.ACMEIssuerDNS01ProviderWebhook, er
 	return &cfg, nil
 }
 
-func (r *Webhook) restClientForGroup(g string) (*rest.RESTClient, error) {
-	cfg := r.restConfigShallowCopy
-	cfg.GroupVersion = &schema.GroupVersion{
-		Group:   g,
+func (r *Webhook) restClientForConfig(cfg *cmacme.ACMEIssuerDNS01ProviderWebhook, namespace string) (*rest.RESTClient, error) {
+	restCfg := r.restConfigShallowCopy
+	if cfg.TLS != nil {
+		var err error
+		restCfg, err = r.directRESTConfig(cfg.TLS, namespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+	restCfg.GroupVersion = &schema.GroupVersion{
+		Group:   cfg.GroupName,
 		Version: v1alpha1.SchemeGroupVersion.Version,
 	}
 
-	return rest.RESTClientFor(&cfg)
+	return rest.RESTClientFor(&restCfg)
+}
+
+// directRESTConfig builds a rest config that connects directly to the webhook
+// solver at the configured URL, presenting the client certificate referenced
+// by the given TLS configuration and verifying the solver's serving
+// certificate using the configured CA bundle.
+// None of the credentials used to communicate with the Kubernetes apiserver
+// are copied, so they are never sent to the webhook solver.
+func (r *Webhook) directRESTConfig(tlsCfg *cmacme.ACMEIssuerDNS01ProviderWebhookTLS, namespace string) (rest.Config, error) {
+	if r.secretLister == nil {
+		return rest.Config{}, fmt.Errorf("webhook solver has not been initialized with a secret lister")
+	}
+
+	name := tlsCfg.ClientCertSecretRef.Name
+	secret, err := r.secretLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return rest.Config{}, fmt.Errorf("error loading client certificate secret %q: %v", namespace+"/"+name, err)
+	}
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return rest.Config{}, fmt.Errorf("client certificate secret %q does not contain data for key %q", namespace+"/"+name, key)
+		}
+	}
+
+	restCfg := rest.Config{
+		Host:      tlsCfg.URL,
+		APIPath:   r.restConfigShallowCopy.APIPath,
+		UserAgent: r.restConfigShallowCopy.UserAgent,
+		Timeout:   r.restConfigShallowCopy.Timeout,
+		TLSClientConfig: rest.TLSClientConfig{
+			CertData: secret.Data[corev1.TLSCertKey],
+			KeyData:  secret.Data[corev1.TLSPrivateKeyKey],
+			CAData:   tlsCfg.CABundle,
+		},
+	}
+	restCfg.NegotiatedSerializer = r.restConfigShallowCopy.NegotiatedSerializer
+
+	return restCfg, nil
 }
@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/jetstack/cert-manager/pkg/acme/webhook/apis/acme/v1alpha1"
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+)
+
+const testClientCommonName = "test-webhook-client"
+
+func TestPresentWithMutualTLS(t *testing.T) {
+	caKey, caCert := mustCreateCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	clientKey, clientCert := mustCreateCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: testClientCommonName},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}, caCert, caKey)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+
+	var presentedCommonName, presentedAuthorization string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presentedAuthorization = r.Header.Get("Authorization")
+		if len(r.TLS.PeerCertificates) > 0 {
+			presentedCommonName = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v1alpha1.ChallengePayload{
+			Response: &v1alpha1.ChallengeResponse{Success: true},
+		})
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCABundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	clientKeyDER, err := x509.MarshalPKCS8PrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientCert.Raw})
+	clientKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: clientKeyDER})
+
+	tests := map[string]struct {
+		secretData map[string][]byte
+		tls        *cmacme.ACMEIssuerDNS01ProviderWebhookTLS
+
+		expectedErr string
+	}{
+		"should present the configured client certificate": {
+			secretData: map[string][]byte{
+				corev1.TLSCertKey:       clientCertPEM,
+				corev1.TLSPrivateKeyKey: clientKeyPEM,
+			},
+			tls: &cmacme.ACMEIssuerDNS01ProviderWebhookTLS{
+				URL:                 server.URL,
+				ClientCertSecretRef: cmmeta.LocalObjectReference{Name: "client-cert"},
+				CABundle:            serverCABundle,
+			},
+		},
+		"should error if the client certificate secret does not exist": {
+			tls: &cmacme.ACMEIssuerDNS01ProviderWebhookTLS{
+				URL:                 server.URL,
+				ClientCertSecretRef: cmmeta.LocalObjectReference{Name: "does-not-exist"},
+				CABundle:            serverCABundle,
+			},
+			expectedErr: `error loading client certificate secret "testns/does-not-exist"`,
+		},
+		"should error if the client certificate secret does not contain a private key": {
+			secretData: map[string][]byte{
+				corev1.TLSCertKey: clientCertPEM,
+			},
+			tls: &cmacme.ACMEIssuerDNS01ProviderWebhookTLS{
+				URL:                 server.URL,
+				ClientCertSecretRef: cmmeta.LocalObjectReference{Name: "client-cert"},
+				CABundle:            serverCABundle,
+			},
+			expectedErr: `client certificate secret "testns/client-cert" does not contain data for key "tls.key"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			presentedCommonName, presentedAuthorization = "", ""
+
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if test.secretData != nil {
+				if err := indexer.Add(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "client-cert"},
+					Data:       test.secretData,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := New()
+			r.restConfigShallowCopy = webhookRESTConfig(&rest.Config{
+				Host:        "https://kube-apiserver.invalid",
+				BearerToken: "kube-apiserver-token",
+			})
+			r.secretLister = corelisters.NewSecretLister(indexer)
+
+			cfg, err := json.Marshal(cmacme.ACMEIssuerDNS01ProviderWebhook{
+				GroupName:  "acme.example.com",
+				SolverName: "test",
+				TLS:        test.tls,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = r.Present(&v1alpha1.ChallengeRequest{
+				ResourceNamespace: "testns",
+				Config:            &apiext.JSON{Raw: cfg},
+			})
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error containing %q but got: %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if presentedCommonName != testClientCommonName {
+				t.Errorf("expected client certificate %q to be presented but got %q", testClientCommonName, presentedCommonName)
+			}
+			if presentedAuthorization != "" {
+				t.Errorf("expected no Kubernetes apiserver credentials to be sent to the webhook solver but got Authorization header %q", presentedAuthorization)
+			}
+		})
+	}
+}
+
+func mustCreateCertificate(t *testing.T, template, parent *x509.Certificate, parentKey crypto.Signer) (crypto.Signer, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
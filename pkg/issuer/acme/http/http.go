@@ -32,6 +32,7 @@ import (
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	networkingv1beta1listers "k8s.io/client-go/listers/networking/v1beta1"
+	gwlisters "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha1"
 
 	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
@@ -60,6 +61,8 @@ type Solver struct {
 	podLister     corev1listers.PodLister
 	serviceLister corev1listers.ServiceLister
 	ingressLister networkingv1beta1listers.IngressLister
+	// httpRouteLister is only set if Gateway API support is enabled
+	httpRouteLister gwlisters.HTTPRouteLister
 
 	testReachability reachabilityTest
 	requiredPasses   int
@@ -70,7 +73,7 @@ type reachabilityTest func(ctx context.Context, url *url.URL, key string) error
 // NewSolver returns a new ACME HTTP01 solver for the given Issuer and client.
 // TODO: refactor this to have fewer args
 func NewSolver(ctx *controller.Context) *Solver {
-	return &Solver{
+	s := &Solver{
 		Context:          ctx,
 		podLister:        ctx.KubeSharedInformerFactory.Core().V1().Pods().Lister(),
 		serviceLister:    ctx.KubeSharedInformerFactory.Core().V1().Services().Lister(),
@@ -78,6 +81,14 @@ func NewSolver(ctx *controller.Context) *Solver {
 		testReachability: testReachability,
 		requiredPasses:   5,
 	}
+	if ctx.GWShared != nil {
+		s.httpRouteLister = ctx.GWShared.Networking().V1alpha1().HTTPRoutes().Lister()
+	}
+	return s
+}
+
+func usesGatewayHTTPRoute(ch *cmacme.Challenge) bool {
+	return ch.Spec.Solver.HTTP01 != nil && ch.Spec.Solver.HTTP01.GatewayHTTPRoute != nil
 }
 
 func http01LogCtx(ctx context.Context) context.Context {
@@ -103,6 +114,10 @@ func (s *Solver) Present(ctx context.Context, issuer v1.GenericIssuer, ch *cmacm
 	if svcErr != nil {
 		return utilerrors.NewAggregate([]error{podErr, svcErr})
 	}
+	if usesGatewayHTTPRoute(ch) {
+		_, httpRouteErr := s.ensureGatewayHTTPRoute(ctx, ch, svc.Name)
+		return utilerrors.NewAggregate([]error{podErr, svcErr, httpRouteErr})
+	}
 	_, ingressErr := s.ensureIngress(ctx, ch, svc.Name)
 	return utilerrors.NewAggregate([]error{podErr, svcErr, ingressErr})
 }
@@ -146,13 +161,17 @@ func (s *Solver) Check(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.
 	return nil
 }
 
-// CleanUp will ensure the created service, ingress and pod are clean/deleted of any
-// cert-manager created data.
+// CleanUp will ensure the created service, ingress or HTTPRoute and pod are
+// clean/deleted of any cert-manager created data.
 func (s *Solver) CleanUp(ctx context.Context, issuer v1.GenericIssuer, ch *cmacme.Challenge) error {
 	var errs []error
 	errs = append(errs, s.cleanupPods(ctx, ch))
 	errs = append(errs, s.cleanupServices(ctx, ch))
-	errs = append(errs, s.cleanupIngresses(ctx, ch))
+	if usesGatewayHTTPRoute(ch) {
+		errs = append(errs, s.cleanupGatewayHTTPRoutes(ctx, ch))
+	} else {
+		errs = append(errs, s.cleanupIngresses(ctx, ch))
+	}
 	return utilerrors.NewAggregate(errs)
 }
 
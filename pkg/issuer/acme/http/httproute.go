@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	gwapi "sigs.k8s.io/gateway-api/apis/v1alpha1"
+
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	"github.com/jetstack/cert-manager/pkg/feature"
+	logf "github.com/jetstack/cert-manager/pkg/logs"
+)
+
+func gatewayHTTPRouteCfgForChallenge(ch *cmacme.Challenge) (*cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute, error) {
+	if ch.Spec.Solver.HTTP01 == nil || ch.Spec.Solver.HTTP01.GatewayHTTPRoute == nil {
+		return nil, fmt.Errorf("challenge's 'solver' field is specified but no HTTP01 gatewayHTTPRoute config provided. " +
+			"Ensure solvers[].http01.gatewayHTTPRoute is specified on your issuer resource")
+	}
+	return ch.Spec.Solver.HTTP01.GatewayHTTPRoute, nil
+}
+
+// getGatewayHTTPRoutesForChallenge returns a list of HTTPRoutes that were
+// created to solve http challenges for the given domain
+func (s *Solver) getGatewayHTTPRoutesForChallenge(ctx context.Context, ch *cmacme.Challenge) ([]*gwapi.HTTPRoute, error) {
+	log := logf.FromContext(ctx)
+
+	podLabels := podLabels(ch)
+	selector := labels.NewSelector()
+	for key, val := range podLabels {
+		req, err := labels.NewRequirement(key, selection.Equals, []string{val})
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*req)
+	}
+
+	log.V(logf.DebugLevel).Info("checking for existing HTTP01 solver HTTPRoutes")
+	httpRouteList, err := s.httpRouteLister.HTTPRoutes(ch.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var relevantHTTPRoutes []*gwapi.HTTPRoute
+	for _, httpRoute := range httpRouteList {
+		if !metav1.IsControlledBy(httpRoute, ch) {
+			logf.WithRelatedResource(log, httpRoute).Info("found existing solver HTTPRoute for this challenge resource, however " +
+				"it does not have an appropriate OwnerReference referencing this challenge. Skipping it altogether.")
+			continue
+		}
+		relevantHTTPRoutes = append(relevantHTTPRoutes, httpRoute)
+	}
+
+	return relevantHTTPRoutes, nil
+}
+
+// ensureGatewayHTTPRoute will ensure the HTTPRoute required to solve this
+// challenge exists and routes requests for the challenge path to the given
+// service.
+func (s *Solver) ensureGatewayHTTPRoute(ctx context.Context, ch *cmacme.Challenge, svcName string) (*gwapi.HTTPRoute, error) {
+	log := logf.FromContext(ctx).WithName("ensureGatewayHTTPRoute")
+	if s.httpRouteLister == nil {
+		return nil, fmt.Errorf("challenge uses the gatewayHTTPRoute HTTP01 solver, but Gateway API support is not enabled. "+
+			"Enable the %s feature gate to use this solver", feature.ExperimentalGatewayAPISupport)
+	}
+
+	expectedHTTPRoute, err := buildGatewayHTTPRoute(ch, svcName)
+	if err != nil {
+		return nil, err
+	}
+
+	existingHTTPRoutes, err := s.getGatewayHTTPRoutesForChallenge(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+	if len(existingHTTPRoutes) > 1 {
+		log.V(logf.InfoLevel).Info("multiple challenge solver HTTPRoutes found for challenge. cleaning up all existing HTTPRoutes.")
+		err := s.cleanupGatewayHTTPRoutes(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("multiple existing challenge solver HTTPRoutes found and cleaned up. retrying challenge sync")
+	}
+	if len(existingHTTPRoutes) == 1 {
+		existingHTTPRoute := existingHTTPRoutes[0]
+		log := logf.WithRelatedResource(log, existingHTTPRoute)
+		if apiequality.Semantic.DeepEqual(existingHTTPRoute.Spec, expectedHTTPRoute.Spec) &&
+			apiequality.Semantic.DeepEqual(existingHTTPRoute.Labels, expectedHTTPRoute.Labels) {
+			log.V(logf.DebugLevel).Info("found one existing HTTP01 solver HTTPRoute")
+			return existingHTTPRoute, nil
+		}
+
+		log.V(logf.DebugLevel).Info("existing HTTP01 solver HTTPRoute is out of date, updating")
+		existingHTTPRoute = existingHTTPRoute.DeepCopy()
+		existingHTTPRoute.Labels = expectedHTTPRoute.Labels
+		existingHTTPRoute.Spec = expectedHTTPRoute.Spec
+		return s.GWClient.NetworkingV1alpha1().HTTPRoutes(existingHTTPRoute.Namespace).Update(ctx, existingHTTPRoute, metav1.UpdateOptions{})
+	}
+
+	log.V(logf.DebugLevel).Info("creating HTTP01 challenge solver HTTPRoute")
+	return s.GWClient.NetworkingV1alpha1().HTTPRoutes(ch.Namespace).Create(ctx, expectedHTTPRoute, metav1.CreateOptions{})
+}
+
+func buildGatewayHTTPRoute(ch *cmacme.Challenge, svcName string) (*gwapi.HTTPRoute, error) {
+	routeCfg, err := gatewayHTTPRouteCfgForChallenge(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	// the solver labels are applied last, as they are used to find the
+	// HTTPRoutes created for this challenge
+	routeLabels := make(map[string]string)
+	for k, v := range routeCfg.Labels {
+		routeLabels[k] = v
+	}
+	for k, v := range podLabels(ch) {
+		routeLabels[k] = v
+	}
+
+	gatewayNamespace := routeCfg.GatewayRef.Namespace
+	if gatewayNamespace == "" {
+		gatewayNamespace = ch.Namespace
+	}
+
+	var hostnames []gwapi.Hostname
+	// if we need to verify ownership of an IP the challenge should propagate on all hosts
+	if net.ParseIP(ch.Spec.DNSName) == nil {
+		hostnames = []gwapi.Hostname{gwapi.Hostname(ch.Spec.DNSName)}
+	}
+
+	allow := gwapi.GatewayAllowFromList
+	pathMatchType := gwapi.PathMatchExact
+	path := solverPathFn(ch.Spec.Token)
+	port := gwapi.PortNumber(acmeSolverListenPort)
+
+	return &gwapi.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    "cm-acme-http-solver-",
+			Namespace:       ch.Namespace,
+			Labels:          routeLabels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ch, challengeGvk)},
+		},
+		Spec: gwapi.HTTPRouteSpec{
+			Gateways: &gwapi.RouteGateways{
+				Allow: &allow,
+				GatewayRefs: []gwapi.GatewayReference{
+					{
+						Name:      routeCfg.GatewayRef.Name,
+						Namespace: gatewayNamespace,
+					},
+				},
+			},
+			Hostnames: hostnames,
+			Rules: []gwapi.HTTPRouteRule{
+				{
+					Matches: []gwapi.HTTPRouteMatch{
+						{
+							Path: &gwapi.HTTPPathMatch{
+								Type:  &pathMatchType,
+								Value: &path,
+							},
+						},
+					},
+					ForwardTo: []gwapi.HTTPRouteForwardTo{
+						{
+							ServiceName: &svcName,
+							Port:        &port,
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// cleanupGatewayHTTPRoutes will delete the HTTPRoutes created to solve the
+// given challenge.
+func (s *Solver) cleanupGatewayHTTPRoutes(ctx context.Context, ch *cmacme.Challenge) error {
+	log := logf.FromContext(ctx, "cleanupGatewayHTTPRoutes")
+
+	// no HTTPRoutes can have been created if Gateway API support is disabled
+	if s.httpRouteLister == nil {
+		return nil
+	}
+
+	httpRoutes, err := s.getGatewayHTTPRoutesForChallenge(ctx, ch)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, httpRoute := range httpRoutes {
+		log := logf.WithRelatedResource(log, httpRoute).V(logf.DebugLevel)
+
+		log.V(logf.DebugLevel).Info("deleting HTTPRoute resource")
+		err := s.GWClient.NetworkingV1alpha1().HTTPRoutes(httpRoute.Namespace).Delete(ctx, httpRoute.Name, metav1.DeleteOptions{})
+		if err != nil {
+			log.V(logf.WarnLevel).Info("failed to delete HTTPRoute resource", "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		log.V(logf.DebugLevel).Info("successfully deleted HTTPRoute resource")
+	}
+	return utilerrors.NewAggregate(errs)
+}
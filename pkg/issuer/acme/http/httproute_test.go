@@ -0,0 +1,217 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	gwapi "sigs.k8s.io/gateway-api/apis/v1alpha1"
+
+	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+)
+
+func gatewayHTTPRouteChallenge(namespace string) *cmacme.Challenge {
+	return &cmacme.Challenge{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+		},
+		Spec: cmacme.ChallengeSpec{
+			DNSName: "example.com",
+			Token:   "token",
+			Solver: cmacme.ACMEChallengeSolver{
+				HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+					GatewayHTTPRoute: &cmacme.ACMEChallengeSolverHTTP01GatewayHTTPRoute{
+						Labels: map[string]string{"gateway": "selected"},
+						GatewayRef: cmacme.ACMEChallengeSolverHTTP01GatewayReference{
+							Name:      "gateway",
+							Namespace: namespace,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestEnsureGatewayHTTPRoute(t *testing.T) {
+	tests := map[string]solverFixture{
+		"should create an HTTPRoute forwarding the challenge path to the solver service": {
+			Challenge: gatewayHTTPRouteChallenge(""),
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				httpRoutes, err := s.Solver.httpRouteLister.List(labels.NewSelector())
+				if err != nil {
+					t.Fatalf("error listing HTTPRoutes: %v", err)
+				}
+				if len(httpRoutes) != 1 {
+					t.Fatalf("expected one HTTPRoute to be created, but got %d", len(httpRoutes))
+				}
+				httpRoute := httpRoutes[0]
+
+				if httpRoute.Labels["gateway"] != "selected" {
+					t.Errorf("expected HTTPRoute to have the configured labels, got %v", httpRoute.Labels)
+				}
+				if httpRoute.Labels[cmacme.SolverIdentificationLabelKey] != "true" {
+					t.Errorf("expected HTTPRoute to have the solver identification label, got %v", httpRoute.Labels)
+				}
+
+				expectedGateways := []gwapi.GatewayReference{{Name: "gateway", Namespace: "default"}}
+				if !reflect.DeepEqual(httpRoute.Spec.Gateways.GatewayRefs, expectedGateways) {
+					t.Errorf("unexpected gatewayRefs, exp=%v got=%v", expectedGateways, httpRoute.Spec.Gateways.GatewayRefs)
+				}
+				if !reflect.DeepEqual(httpRoute.Spec.Hostnames, []gwapi.Hostname{"example.com"}) {
+					t.Errorf("unexpected hostnames: %v", httpRoute.Spec.Hostnames)
+				}
+
+				if len(httpRoute.Spec.Rules) != 1 || len(httpRoute.Spec.Rules[0].Matches) != 1 || len(httpRoute.Spec.Rules[0].ForwardTo) != 1 {
+					t.Fatalf("expected a single rule with one match and one backend, got %+v", httpRoute.Spec.Rules)
+				}
+				path := httpRoute.Spec.Rules[0].Matches[0].Path
+				if *path.Type != gwapi.PathMatchExact || *path.Value != "/.well-known/acme-challenge/token" {
+					t.Errorf("unexpected path match: type=%s value=%s", *path.Type, *path.Value)
+				}
+				forwardTo := httpRoute.Spec.Rules[0].ForwardTo[0]
+				if *forwardTo.ServiceName != "fakeservice" || *forwardTo.Port != acmeSolverListenPort {
+					t.Errorf("unexpected backend: service=%s port=%d", *forwardTo.ServiceName, *forwardTo.Port)
+				}
+			},
+		},
+		"should reference a Gateway in a different namespace": {
+			Challenge: gatewayHTTPRouteChallenge("gateway-namespace"),
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				httpRoute := args[0].(*gwapi.HTTPRoute)
+				expectedGateways := []gwapi.GatewayReference{{Name: "gateway", Namespace: "gateway-namespace"}}
+				if !reflect.DeepEqual(httpRoute.Spec.Gateways.GatewayRefs, expectedGateways) {
+					t.Errorf("unexpected gatewayRefs, exp=%v got=%v", expectedGateways, httpRoute.Spec.Gateways.GatewayRefs)
+				}
+			},
+		},
+		"should update an existing HTTPRoute if the service name changes": {
+			Challenge: gatewayHTTPRouteChallenge(""),
+			PreFn: func(t *testing.T, s *solverFixture) {
+				httpRoute, err := buildGatewayHTTPRoute(s.Challenge, "anotherfakeservice")
+				if err != nil {
+					t.Fatalf("error preparing test: %v", err)
+				}
+				httpRoute.GenerateName = ""
+				httpRoute.Name = "existing"
+				_, err = s.Solver.GWClient.NetworkingV1alpha1().HTTPRoutes(s.Challenge.Namespace).Create(context.TODO(), httpRoute, metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("error preparing test: %v", err)
+				}
+				s.Builder.Sync()
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				httpRoutes, err := s.Solver.httpRouteLister.List(labels.NewSelector())
+				if err != nil {
+					t.Fatalf("error listing HTTPRoutes: %v", err)
+				}
+				if len(httpRoutes) != 1 || httpRoutes[0].Name != "existing" {
+					t.Fatalf("expected the existing HTTPRoute to be updated, got %v", httpRoutes)
+				}
+				if serviceName := *httpRoutes[0].Spec.Rules[0].ForwardTo[0].ServiceName; serviceName != "fakeservice" {
+					t.Errorf("expected HTTPRoute to forward to fakeservice, got %s", serviceName)
+				}
+			},
+		},
+		"should return an error if Gateway API support is not enabled": {
+			Challenge: gatewayHTTPRouteChallenge(""),
+			PreFn: func(t *testing.T, s *solverFixture) {
+				s.Solver.httpRouteLister = nil
+			},
+			Err: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.Setup(t)
+			resp, err := test.Solver.ensureGatewayHTTPRoute(context.TODO(), test.Challenge, "fakeservice")
+			if err != nil && !test.Err {
+				t.Errorf("Expected function to not error, but got: %v", err)
+			}
+			if err == nil && test.Err {
+				t.Errorf("Expected function to get an error, but got: %v", err)
+			}
+			test.Finish(t, resp, err)
+		})
+	}
+}
+
+func TestCleanupGatewayHTTPRoutes(t *testing.T) {
+	tests := map[string]solverFixture{
+		"should delete the HTTPRoute created for the challenge": {
+			Challenge: gatewayHTTPRouteChallenge(""),
+			PreFn: func(t *testing.T, s *solverFixture) {
+				_, err := s.Solver.ensureGatewayHTTPRoute(context.TODO(), s.Challenge, "fakeservice")
+				if err != nil {
+					t.Fatalf("error preparing test: %v", err)
+				}
+				s.Builder.Sync()
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				httpRoutes, err := s.Solver.httpRouteLister.List(labels.NewSelector())
+				if err != nil {
+					t.Fatalf("error listing HTTPRoutes: %v", err)
+				}
+				if len(httpRoutes) != 0 {
+					t.Errorf("expected HTTPRoutes to have been cleaned up, but there were %d left", len(httpRoutes))
+				}
+			},
+		},
+		"should not delete HTTPRoutes without the solver labels": {
+			Challenge: gatewayHTTPRouteChallenge(""),
+			PreFn: func(t *testing.T, s *solverFixture) {
+				httpRoute := &gwapi.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "unrelated",
+						Namespace: s.Challenge.Namespace,
+					},
+				}
+				_, err := s.Solver.GWClient.NetworkingV1alpha1().HTTPRoutes(s.Challenge.Namespace).Create(context.TODO(), httpRoute, metav1.CreateOptions{})
+				if err != nil {
+					t.Fatalf("error preparing test: %v", err)
+				}
+				s.Builder.Sync()
+			},
+			CheckFn: func(t *testing.T, s *solverFixture, args ...interface{}) {
+				httpRoutes, err := s.Solver.httpRouteLister.List(labels.NewSelector())
+				if err != nil {
+					t.Fatalf("error listing HTTPRoutes: %v", err)
+				}
+				if len(httpRoutes) != 1 {
+					t.Errorf("expected the unrelated HTTPRoute to be retained, but there were %d left", len(httpRoutes))
+				}
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.Setup(t)
+			err := test.Solver.cleanupGatewayHTTPRoutes(context.TODO(), test.Challenge)
+			if err != nil && !test.Err {
+				t.Errorf("Expected function to not error, but got: %v", err)
+			}
+			if err == nil && test.Err {
+				t.Errorf("Expected function to get an error, but got: %v", err)
+			}
+			test.Finish(t, err)
+		})
+	}
+}
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	"net"
 
+	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -155,7 +157,7 @@ func buildIngressResource(ch *cmacme.Challenge, svcName string) (*networkingv1be
 		ingAnnotations[cmapi.IngressClassAnnotationKey] = *ingClass
 	}
 
-	ingPathToAdd := ingressPath(ch.Spec.Token, svcName)
+	ingPathToAdd := ingressPath(ch.Spec.Token, svcName, httpDomainCfg.PathType)
 
 	httpHost := ch.Spec.DNSName
 	// if we need to verify ownership of an IP the challenge should propagate on all hosts
@@ -171,6 +173,7 @@ func buildIngressResource(ch *cmacme.Challenge, svcName string) (*networkingv1be
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ch, challengeGvk)},
 		},
 		Spec: networkingv1beta1.IngressSpec{
+			IngressClassName: httpDomainCfg.IngressClassName,
 			Rules: []networkingv1beta1.IngressRule{
 				{
 					Host: httpHost,
@@ -222,7 +225,7 @@ func (s *Solver) addChallengePathToIngress(ctx context.Context, ch *cmacme.Chall
 		return nil, err
 	}
 
-	ingPathToAdd := ingressPath(ch.Spec.Token, svcName)
+	ingPathToAdd := ingressPath(ch.Spec.Token, svcName, httpDomainCfg.PathType)
 	// check for an existing Rule for the given domain on the ingress resource
 	for _, rule := range ing.Spec.Rules {
 		if rule.Host == ch.Spec.DNSName {
@@ -235,7 +238,8 @@ func (s *Solver) addChallengePathToIngress(ctx context.Context, ch *cmacme.Chall
 				if p.Path == ingPathToAdd.Path {
 					// ingress resource is already up to date
 					if p.Backend.ServiceName == ingPathToAdd.Backend.ServiceName &&
-						p.Backend.ServicePort == ingPathToAdd.Backend.ServicePort {
+						p.Backend.ServicePort == ingPathToAdd.Backend.ServicePort &&
+						apiequality.Semantic.DeepEqual(p.PathType, ingPathToAdd.PathType) {
 						return ing, nil
 					}
 					rule.HTTP.Paths[i] = ingPathToAdd
@@ -349,15 +353,20 @@ func (s *Solver) cleanupIngresses(ctx context.Context, ch *cmacme.Challenge) err
 }
 
 // ingressPath returns the ingress HTTPIngressPath object needed to solve this
-// challenge.
-func ingressPath(token, serviceName string) networkingv1beta1.HTTPIngressPath {
-	return networkingv1beta1.HTTPIngressPath{
+// challenge. If pathType is nil, no path type is set on the returned path.
+func ingressPath(token, serviceName string, pathType *networkingv1.PathType) networkingv1beta1.HTTPIngressPath {
+	path := networkingv1beta1.HTTPIngressPath{
 		Path: solverPathFn(token),
 		Backend: networkingv1beta1.IngressBackend{
 			ServiceName: serviceName,
 			ServicePort: intstr.FromInt(acmeSolverListenPort),
 		},
 	}
+	if pathType != nil {
+		pt := networkingv1beta1.PathType(*pathType)
+		path.PathType = &pt
+	}
+	return path
 }
 
 var solverPathFn = func(token string) string {
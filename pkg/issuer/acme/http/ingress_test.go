@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"testing"
 
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/api/networking/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -470,6 +471,70 @@ func TestEnsureIngress(t *testing.T) {
 	}
 }
 
+func TestCreateIngressClassNameAndPathType(t *testing.T) {
+	exact := networkingv1.PathTypeExact
+	tests := map[string]struct {
+		ingress *cmacme.ACMEChallengeSolverHTTP01Ingress
+
+		expectedIngressClassName *string
+		expectedPathType         *v1beta1.PathType
+	}{
+		"should not set an ingress class name or path type by default": {
+			ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{},
+		},
+		"should set the ingress class name if specified": {
+			ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+				IngressClassName: strPtr("nginx"),
+			},
+			expectedIngressClassName: strPtr("nginx"),
+		},
+		"should set the path type if specified": {
+			ingress: &cmacme.ACMEChallengeSolverHTTP01Ingress{
+				PathType: &exact,
+			},
+			expectedPathType: v1beta1PathTypePtr(v1beta1.PathTypeExact),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &solverFixture{
+				Challenge: &cmacme.Challenge{
+					Spec: cmacme.ChallengeSpec{
+						DNSName: "example.com",
+						Token:   "token",
+						Solver: cmacme.ACMEChallengeSolver{
+							HTTP01: &cmacme.ACMEChallengeSolverHTTP01{
+								Ingress: test.ingress,
+							},
+						},
+					},
+				},
+			}
+			s.Setup(t)
+			ing, err := s.Solver.createIngress(context.TODO(), s.Challenge, "fakeservice")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			s.Finish(t)
+
+			if !reflect.DeepEqual(ing.Spec.IngressClassName, test.expectedIngressClassName) {
+				t.Errorf("unexpected ingressClassName, exp=%v got=%v", test.expectedIngressClassName, ing.Spec.IngressClassName)
+			}
+			if _, ok := ing.Annotations["kubernetes.io/ingress.class"]; ok {
+				t.Errorf("expected the ingress class annotation not to be set")
+			}
+			pathType := ing.Spec.Rules[0].HTTP.Paths[0].PathType
+			if !reflect.DeepEqual(pathType, test.expectedPathType) {
+				t.Errorf("unexpected pathType, exp=%v got=%v", test.expectedPathType, pathType)
+			}
+		})
+	}
+}
+
+func v1beta1PathTypePtr(p v1beta1.PathType) *v1beta1.PathType {
+	return &p
+}
+
 func TestMergeIngressObjectMetaWithIngressResourceTemplate(t *testing.T) {
 	const createdIngressKey = "createdIngressKey"
 	tests := map[string]solverFixture{
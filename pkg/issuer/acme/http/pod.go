@@ -255,5 +255,11 @@ func (s *Solver) mergePodObjectMetaWithPodTemplate(pod *corev1.Pod, podTempl *cm
 		pod.Spec.ServiceAccountName = podTempl.Spec.ServiceAccountName
 	}
 
+	if podTempl.Spec.Resources != nil {
+		for i := range pod.Spec.Containers {
+			pod.Spec.Containers[i].Resources = *podTempl.Spec.Resources
+		}
+	}
+
 	return pod
 }
@@ -125,6 +125,12 @@ func buildService(ch *cmacme.Challenge) (*corev1.Service, error) {
 	}
 
 	// checking for presence of http01 config and if set serviceType is set, override our default (NodePort)
+	if usesGatewayHTTPRoute(ch) {
+		if serviceType := ch.Spec.Solver.HTTP01.GatewayHTTPRoute.ServiceType; serviceType != "" {
+			service.Spec.Type = serviceType
+		}
+		return service, nil
+	}
 	httpDomainCfg, err := httpDomainCfgForChallenge(ch)
 	if err != nil {
 		return nil, err
@@ -21,6 +21,7 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 
@@ -44,17 +45,21 @@ const (
 	errorAccountRegistrationFailed = "ErrRegisterACMEAccount"
 	errorAccountVerificationFailed = "ErrVerifyACMEAccount"
 	errorAccountUpdateFailed       = "ErrUpdateACMEAccount"
+	errorAccountKeyRolloverFailed  = "ErrRolloverACMEAccountKey"
 	errorInvalidConfig             = "InvalidConfig"
 	errorInvalidURL                = "InvalidURL"
 
 	successAccountRegistered = "ACMEAccountRegistered"
 	successAccountVerified   = "ACMEAccountVerified"
+	successAccountKeyRolled  = "ACMEAccountKeyRolledOver"
 
 	messageAccountRegistrationFailed     = "Failed to register ACME account: "
 	messageAccountVerificationFailed     = "Failed to verify ACME account: "
 	messageAccountUpdateFailed           = "Failed to update ACME account:"
+	messageAccountKeyRolloverFailed      = "Failed to roll over ACME account key: "
 	messageAccountRegistered             = "The ACME account was registered with the ACME server"
 	messageAccountVerified               = "The ACME account was verified with the ACME server"
+	messageAccountKeyRolledOver          = "The ACME account key was rolled over to the key in privateKeySecretRef"
 	messageNoSecretKeyGenerationDisabled = "the ACME issuer config has 'disableAccountKeyGeneration' set to true, but the secret was not found: "
 	messageInvalidPrivateKey             = "Account private key is invalid: "
 
@@ -155,7 +160,7 @@ func (a *Acme) Setup(ctx context.Context) error {
 	// We could therefore move the removing of the client up to the start of
 	// this function.
 	a.accountRegistry.RemoveClient(string(a.issuer.GetUID()))
-	httpClient := accounts.BuildHTTPClient(a.metrics, a.issuer.GetSpec().ACME.SkipTLSVerify)
+	httpClient := accounts.BuildHTTPClient(a.metrics, a.issuer.GetSpec().ACME.SkipTLSVerify, a.issuer.GetSpec().ACME.UserAgent)
 	cl := a.clientBuilder(httpClient, *a.issuer.GetSpec().ACME, rsaPk)
 
 	// TODO: perform a complex check to determine whether we need to verify
@@ -195,7 +200,10 @@ func (a *Acme) Setup(ctx context.Context) error {
 	// and the cached email matches the registered email, then
 	// we skip re-checking the account status to save excess calls to the
 	// ACME api.
+	// If a previous account key is specified, the account must always be
+	// re-checked as its key may need to be rolled over.
 	if hasReadyCondition &&
+		a.issuer.GetSpec().ACME.PreviousPrivateKey == nil &&
 		a.issuer.GetStatus().ACMEStatus().URI != "" &&
 		parsedAccountURL.Host == parsedServerURL.Host &&
 		a.issuer.GetStatus().ACMEStatus().LastRegisteredEmail == a.issuer.GetSpec().ACME.Email {
@@ -248,6 +256,33 @@ func (a *Acme) Setup(ctx context.Context) error {
 		}
 	}
 
+	// If a previous account key is specified, roll the existing account over
+	// to the current key before registering so that the account URL is
+	// retained instead of a new account being registered.
+	if a.issuer.GetSpec().ACME.PreviousPrivateKey != nil && a.issuer.GetStatus().ACMEStatus().URI != "" {
+		if err := a.rolloverAccountKey(ctx, ns, cl, httpClient, rsaPk); err != nil {
+			reason = errorAccountKeyRolloverFailed
+			msg = messageAccountKeyRolloverFailed + err.Error()
+			log.Error(err, "failed to roll over ACME account key")
+			a.recorder.Event(a.issuer, corev1.EventTypeWarning, errorAccountKeyRolloverFailed, msg)
+
+			// Do not retry if the previous key cannot be loaded, as a resync
+			// will happen when the Secret is updated.
+			if apierrors.IsNotFound(err) || errors.IsInvalidData(err) {
+				return nil
+			}
+
+			// If the status code is 400 (BadRequest), we will *not* retry the
+			// rollover as it implies that the previous key is not the key
+			// the account is registered with.
+			if acmeErr, ok := err.(*acmeapi.Error); ok && acmeErr.StatusCode >= 400 && acmeErr.StatusCode < 500 {
+				return nil
+			}
+
+			return err
+		}
+	}
+
 	// register an ACME account or retrieve it if it already exists.
 	account, err := a.registerAccount(ctx, cl, eabAccount)
 	if err != nil {
@@ -349,6 +384,45 @@ func ensureEmailUpToDate(ctx context.Context, cl client.Interface, acc *acmeapi.
 	return acc, registeredEmail, nil
 }
 
+// rolloverAccountKey changes the key of the ACME account recorded in the
+// issuer's status from the key in previousPrivateKeySecretRef to newKey using
+// the client cl, which must be configured with newKey. It does nothing if
+// newKey is already associated with an ACME account.
+func (a *Acme) rolloverAccountKey(ctx context.Context, ns string, cl client.Interface, httpClient *http.Client, newKey *rsa.PrivateKey) error {
+	log := logf.FromContext(ctx)
+	accountURL := a.issuer.GetStatus().ACMEStatus().URI
+
+	acc, err := cl.GetReg(ctx, "")
+	switch {
+	case err == nil:
+		if acc.URI != accountURL {
+			log.V(logf.InfoLevel).Info("ACME account key is already associated with a different ACME account, "+
+				"skipping account key rollover", "account", acc.URI)
+		}
+		return nil
+	case err != acmeapi.ErrNoAccount:
+		return err
+	}
+
+	sel := acme.PrivateKeySelector(*a.issuer.GetSpec().ACME.PreviousPrivateKey)
+	pk, err := a.keyFromSecret(ctx, ns, sel.Name, sel.Key)
+	if err != nil {
+		return err
+	}
+	oldKey, ok := pk.(*rsa.PrivateKey)
+	if !ok {
+		return errors.NewInvalidData(messageTemplateNotRSA, sel.Name)
+	}
+
+	log.V(logf.InfoLevel).Info("rolling over ACME account key", "account", accountURL)
+	if err := a.accountKeyRollover(ctx, httpClient, a.issuer.GetSpec().ACME.Server, accountURL, oldKey, newKey); err != nil {
+		return err
+	}
+	a.recorder.Event(a.issuer, corev1.EventTypeNormal, successAccountKeyRolled, messageAccountKeyRolledOver)
+
+	return nil
+}
+
 // registerAccount will register a new ACME account with the server. If an
 // account with the clients private key already exists, it will attempt to look
 // up and verify the corresponding account, and will return that. If this fails
@@ -66,9 +66,12 @@ func TestAcme_Setup(t *testing.T) {
 			gen.SetIssuerConditionMessage(messageAccountRegistered),
 			gen.SetIssuerConditionLastTransitionTime(&nowMetaTime))
 		issuerSecretKeyName = "test"
+		previousKeyName     = "previous-key"
+		accountURL          = "https://acme-v02.api.letsencrypt.org/acme/acct/1"
 
 		ecdsaPrivKey = mustGenerateEDCSAKey(t)
 		rsaPrivKey   = mustGenerateRSAKey(t)
+		prevPrivKey  = mustGenerateRSAKey(t)
 
 		notFoundErr    = apierrors.NewNotFound(corev1.Resource("test"), "test")
 		invalidDataErr = errors.NewInvalidData("test")
@@ -108,6 +111,10 @@ func TestAcme_Setup(t *testing.T) {
 		// Error returned by keyFromSecret stub.
 		kfsErr error
 
+		// Private key returned by keyFromSecret stub for the previous
+		// account key.
+		previousKfsKey crypto.Signer
+
 		// Whether RemoveClient should be called.
 		removeClientShouldBeCalled bool
 
@@ -121,6 +128,14 @@ func TestAcme_Setup(t *testing.T) {
 		getRegAcc *acmeapi.Account
 		// Error returned by cl.GetReg
 		getRegErr error
+		// Error returned by cl.GetReg before the account key has been
+		// rolled over.
+		preRolloverGetRegErr error
+
+		// Error returned by the account key rollover stub.
+		rolloverErr error
+		// Whether the account key should be rolled over.
+		rolloverShouldBeCalled bool
 
 		// Error returned when creating ACME account key.
 		acmePrivKeySecretCreateErr error
@@ -135,6 +150,8 @@ func TestAcme_Setup(t *testing.T) {
 		// expected issuer conditions after Setup has been called.
 		expectedConditions []cmapi.IssuerCondition
 		expectedEvents     []string
+		// expected ACME account URL in the issuer's status, if set.
+		expectedAccountURL string
 		wantsErr           bool
 	}{
 		"LetsEncrypt ACME v1 prod URL specified, return early": {
@@ -382,6 +399,81 @@ func TestAcme_Setup(t *testing.T) {
 					gen.SetIssuerConditionMessage(messageAccountRegistered)),
 			},
 		},
+		"ACME account key is rolled over to the current key, retaining the account URL": {
+			issuer: gen.IssuerFrom(baseIssuer,
+				gen.SetIssuerACMEPreviousPrivKeyRef(previousKeyName),
+				gen.SetIssuerACMEAccountURL(accountURL)),
+			kfsKey:                     rsaPrivKey,
+			previousKfsKey:             prevPrivKey,
+			removeClientShouldBeCalled: true,
+			addClientShouldBeCalled:    true,
+			preRolloverGetRegErr:       acmeapi.ErrNoAccount,
+			rolloverShouldBeCalled:     true,
+			registerErr:                acmeapi.ErrAccountAlreadyExists,
+			getRegAcc:                  &acmeapi.Account{URI: accountURL},
+			expectedRegisteredAcc:      &acmeapi.Account{},
+			expectedConditions: []cmapi.IssuerCondition{
+				*gen.IssuerConditionFrom(readyTrueCondition),
+			},
+			expectedEvents: []string{
+				fmt.Sprintf("%s %s %s", corev1.EventTypeNormal, successAccountKeyRolled, messageAccountKeyRolledOver),
+			},
+			expectedAccountURL: accountURL,
+		},
+		"ACME account key is not rolled over if the current key is already registered": {
+			issuer: gen.IssuerFrom(baseIssuer,
+				gen.SetIssuerACMEPreviousPrivKeyRef(previousKeyName),
+				gen.SetIssuerACMEAccountURL(accountURL),
+				gen.AddIssuerCondition(*gen.IssuerConditionFrom(readyTrueCondition))),
+			kfsKey:                     rsaPrivKey,
+			previousKfsKey:             prevPrivKey,
+			removeClientShouldBeCalled: true,
+			addClientShouldBeCalled:    true,
+			registerErr:                acmeapi.ErrAccountAlreadyExists,
+			getRegAcc:                  &acmeapi.Account{URI: accountURL},
+			expectedRegisteredAcc:      &acmeapi.Account{},
+			expectedConditions: []cmapi.IssuerCondition{
+				*gen.IssuerConditionFrom(readyTrueCondition),
+			},
+			expectedAccountURL: accountURL,
+		},
+		"ACME account key rollover is rejected by the ACME server": {
+			issuer: gen.IssuerFrom(baseIssuer,
+				gen.SetIssuerACMEPreviousPrivKeyRef(previousKeyName),
+				gen.SetIssuerACMEAccountURL(accountURL)),
+			kfsKey:                     rsaPrivKey,
+			previousKfsKey:             prevPrivKey,
+			removeClientShouldBeCalled: true,
+			preRolloverGetRegErr:       acmeapi.ErrNoAccount,
+			rolloverShouldBeCalled:     true,
+			rolloverErr:                acmeErr450,
+			expectedConditions: []cmapi.IssuerCondition{
+				*gen.IssuerConditionFrom(readyFalseCondition,
+					gen.SetIssuerConditionReason(errorAccountKeyRolloverFailed),
+					gen.SetIssuerConditionMessage(messageAccountKeyRolloverFailed+acmeErr450.Error())),
+			},
+			expectedEvents: []string{
+				fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, errorAccountKeyRolloverFailed, messageAccountKeyRolloverFailed+acmeErr450.Error()),
+			},
+			expectedAccountURL: accountURL,
+		},
+		"Previous ACME account key is not an RSA key": {
+			issuer: gen.IssuerFrom(baseIssuer,
+				gen.SetIssuerACMEPreviousPrivKeyRef(previousKeyName),
+				gen.SetIssuerACMEAccountURL(accountURL)),
+			kfsKey:                     rsaPrivKey,
+			previousKfsKey:             ecdsaPrivKey,
+			removeClientShouldBeCalled: true,
+			preRolloverGetRegErr:       acmeapi.ErrNoAccount,
+			expectedConditions: []cmapi.IssuerCondition{
+				*gen.IssuerConditionFrom(readyFalseCondition,
+					gen.SetIssuerConditionReason(errorAccountKeyRolloverFailed),
+					gen.SetIssuerConditionMessage(messageAccountKeyRolloverFailed+fmt.Sprintf(messageTemplateNotRSA, previousKeyName))),
+			},
+			expectedEvents: []string{
+				fmt.Sprintf("%s %s %s", corev1.EventTypeWarning, errorAccountKeyRolloverFailed, messageAccountKeyRolloverFailed+fmt.Sprintf(messageTemplateNotRSA, previousKeyName)),
+			},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -403,6 +495,28 @@ func TestAcme_Setup(t *testing.T) {
 			// Set up a mock keyFromSecret.
 			kfsWasCalled := false
 			kfs := keyFromSecretMockBuilder(&(kfsWasCalled), test.kfsKey, test.kfsErr)
+			if test.previousKfsKey != nil {
+				currentKfs := kfs
+				kfs = func(ctx context.Context, namespace, name, keyName string) (crypto.Signer, error) {
+					if name == previousKeyName {
+						return test.previousKfsKey, nil
+					}
+					return currentKfs(ctx, namespace, name, keyName)
+				}
+			}
+
+			// Mock account key rollover.
+			rolloverWasCalled := false
+			rollover := func(_ context.Context, _ *http.Client, _, gotAccountURL string, oldKey, newKey *rsa.PrivateKey) error {
+				rolloverWasCalled = true
+				if gotAccountURL != accountURL {
+					t.Errorf("Expected account key rollover for account %q, got %q", accountURL, gotAccountURL)
+				}
+				if oldKey != test.previousKfsKey || newKey != test.kfsKey {
+					t.Errorf("Account key rollover called with unexpected keys")
+				}
+				return test.rolloverErr
+			}
 
 			// Mock ACME accounts registry.
 			removeClientWasCalled := false
@@ -424,6 +538,9 @@ func TestAcme_Setup(t *testing.T) {
 					return a, test.registerErr
 				},
 				FakeGetReg: func(context.Context, string) (*acmeapi.Account, error) {
+					if test.preRolloverGetRegErr != nil && !rolloverWasCalled {
+						return nil, test.preRolloverGetRegErr
+					}
 					return test.getRegAcc, test.getRegErr
 				},
 			}
@@ -431,12 +548,13 @@ func TestAcme_Setup(t *testing.T) {
 			// Mock events recorder.
 			recorder := new(controllertest.FakeRecorder)
 			a := Acme{
-				issuer:          test.issuer,
-				secretsClient:   secretsClient,
-				accountRegistry: ar,
-				keyFromSecret:   kfs,
-				clientBuilder:   clientBuilderMock(&cl),
-				recorder:        recorder,
+				issuer:             test.issuer,
+				secretsClient:      secretsClient,
+				accountRegistry:    ar,
+				keyFromSecret:      kfs,
+				clientBuilder:      clientBuilderMock(&cl),
+				accountKeyRollover: rollover,
+				recorder:           recorder,
 			}
 
 			// Stub the clock to get consistent last transition times on conditions.
@@ -463,6 +581,20 @@ func TestAcme_Setup(t *testing.T) {
 					addClientWasCalled)
 			}
 
+			// Verify that the account key was rolled over if expected.
+			if rolloverWasCalled != test.rolloverShouldBeCalled {
+				t.Errorf("Expected Acme.accountKeyRollover to be called: %v, was called: %v",
+					test.rolloverShouldBeCalled,
+					rolloverWasCalled)
+			}
+
+			// Verify that the expected account URL is set in the issuer's status.
+			if test.expectedAccountURL != "" && a.issuer.GetStatus().ACMEStatus().URI != test.expectedAccountURL {
+				t.Errorf("Expected ACME account URL %q, got %q",
+					test.expectedAccountURL,
+					a.issuer.GetStatus().ACMEStatus().URI)
+			}
+
 			// Verify that the expected account value was passed when the
 			// account was registered.
 			if !reflect.DeepEqual(gotAcc, test.expectedRegisteredAcc) {
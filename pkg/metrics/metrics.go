@@ -21,6 +21,15 @@ limitations under the License.
 // acme_client_request_count{"scheme", "host", "path", "method", "status"}
 // acme_client_request_duration_seconds{"scheme", "host", "path", "method", "status"}
 // controller_sync_call_count{"controller"}
+// failure_webhook_delivery_failure_count
+// certificate_request_approval_wait_seconds
+// workqueue_depth{"name"}
+// workqueue_adds_total{"name"}
+// workqueue_queue_duration_seconds{"name"}
+// workqueue_work_duration_seconds{"name"}
+// workqueue_unfinished_work_seconds{"name"}
+// workqueue_longest_running_processor_seconds{"name"}
+// workqueue_retries_total{"name"}
 package metrics
 
 import (
@@ -60,6 +69,9 @@ type Metrics struct {
 	acmeClientRequestDurationSeconds *prometheus.SummaryVec
 	acmeClientRequestCount           *prometheus.CounterVec
 	controllerSyncCallCount          *prometheus.CounterVec
+	failureWebhookDeliveryFailures   prometheus.Counter
+	approvalWaitSeconds              prometheus.Histogram
+	workqueueMetrics                 *workqueueMetrics
 }
 
 var readyConditionStatuses = [...]cmmeta.ConditionStatus{cmmeta.ConditionTrue, cmmeta.ConditionFalse, cmmeta.ConditionUnknown}
@@ -117,6 +129,27 @@ func New(log logr.Logger) *Metrics {
 			},
 			[]string{"controller"},
 		)
+
+		// failureWebhookDeliveryFailures counts the issuance failure
+		// notifications that could not be delivered to the configured webhook.
+		failureWebhookDeliveryFailures = prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "failure_webhook_delivery_failure_count",
+				Help:      "The number of issuance failure notifications that could not be delivered to the configured webhook.",
+			},
+		)
+
+		// approvalWaitSeconds observes how long CertificateRequests wait
+		// between being created and being approved.
+		approvalWaitSeconds = prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "certificate_request_approval_wait_seconds",
+				Help:      "The time in seconds between the creation and the approval of a certificate request.",
+				Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+			},
+		)
 	)
 
 	// Create server and register Prometheus metrics handler
@@ -129,6 +162,9 @@ func New(log logr.Logger) *Metrics {
 		acmeClientRequestCount:           acmeClientRequestCount,
 		acmeClientRequestDurationSeconds: acmeClientRequestDurationSeconds,
 		controllerSyncCallCount:          controllerSyncCallCount,
+		failureWebhookDeliveryFailures:   failureWebhookDeliveryFailures,
+		approvalWaitSeconds:              approvalWaitSeconds,
+		workqueueMetrics:                 newWorkqueueMetrics(),
 	}
 
 	return m
@@ -141,6 +177,9 @@ func (m *Metrics) Start(listenAddress string, enablePprof bool) (*http.Server, e
 	m.registry.MustRegister(m.acmeClientRequestDurationSeconds)
 	m.registry.MustRegister(m.acmeClientRequestCount)
 	m.registry.MustRegister(m.controllerSyncCallCount)
+	m.registry.MustRegister(m.failureWebhookDeliveryFailures)
+	m.registry.MustRegister(m.approvalWaitSeconds)
+	m.registry.MustRegister(m.workqueueMetrics.collectors()...)
 
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
@@ -179,6 +218,18 @@ func (m *Metrics) IncrementSyncCallCount(controllerName string) {
 	m.controllerSyncCallCount.WithLabelValues(controllerName).Inc()
 }
 
+// IncrementFailureWebhookDeliveryFailures will increase the counter of issuance
+// failure notifications that could not be delivered.
+func (m *Metrics) IncrementFailureWebhookDeliveryFailures() {
+	m.failureWebhookDeliveryFailures.Inc()
+}
+
+// ObserveCertificateRequestApprovalWait records how long a certificate request
+// waited to be approved.
+func (m *Metrics) ObserveCertificateRequestApprovalWait(wait time.Duration) {
+	m.approvalWaitSeconds.Observe(wait.Seconds())
+}
+
 func (m *Metrics) Shutdown(server *http.Server) {
 	m.log.V(logf.InfoLevel).Info("stopping Prometheus metrics server...")
 
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const workqueueSubsystem = "workqueue"
+
+// workqueueMetrics holds the Prometheus collectors used to expose the state
+// of each controller's workqueue, labelled by the name of the queue.
+type workqueueMetrics struct {
+	depth                   *prometheus.GaugeVec
+	adds                    *prometheus.CounterVec
+	latency                 *prometheus.HistogramVec
+	workDuration            *prometheus.HistogramVec
+	unfinishedWorkSeconds   *prometheus.GaugeVec
+	longestRunningProcessor *prometheus.GaugeVec
+	retries                 *prometheus.CounterVec
+}
+
+func newWorkqueueMetrics() *workqueueMetrics {
+	return &workqueueMetrics{
+		depth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: workqueueSubsystem,
+				Name:      "depth",
+				Help:      "Current depth of the workqueue.",
+			},
+			[]string{"name"},
+		),
+		adds: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: workqueueSubsystem,
+				Name:      "adds_total",
+				Help:      "Total number of adds handled by the workqueue.",
+			},
+			[]string{"name"},
+		),
+		latency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: workqueueSubsystem,
+				Name:      "queue_duration_seconds",
+				Help:      "How long in seconds an item stays in the workqueue before being requested.",
+				Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+			},
+			[]string{"name"},
+		),
+		workDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: workqueueSubsystem,
+				Name:      "work_duration_seconds",
+				Help:      "How long in seconds processing an item from the workqueue takes.",
+				Buckets:   prometheus.ExponentialBuckets(10e-9, 10, 10),
+			},
+			[]string{"name"},
+		),
+		unfinishedWorkSeconds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: workqueueSubsystem,
+				Name:      "unfinished_work_seconds",
+				Help: "How many seconds of work has been done that is in progress and hasn't been observed by work_duration. " +
+					"Large values indicate stuck threads.",
+			},
+			[]string{"name"},
+		),
+		longestRunningProcessor: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: workqueueSubsystem,
+				Name:      "longest_running_processor_seconds",
+				Help:      "How many seconds the longest running processor for the workqueue has been running.",
+			},
+			[]string{"name"},
+		),
+		retries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: workqueueSubsystem,
+				Name:      "retries_total",
+				Help:      "Total number of retries handled by the workqueue.",
+			},
+			[]string{"name"},
+		),
+	}
+}
+
+func (w *workqueueMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		w.depth,
+		w.adds,
+		w.latency,
+		w.workDuration,
+		w.unfinishedWorkSeconds,
+		w.longestRunningProcessor,
+		w.retries,
+	}
+}
+
+// WorkqueueMetricsProvider returns a workqueue.MetricsProvider which exposes
+// the metrics of all named workqueues through this Metrics' registry.
+func (m *Metrics) WorkqueueMetricsProvider() workqueue.MetricsProvider {
+	return m.workqueueMetrics
+}
+
+func (w *workqueueMetrics) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return w.depth.WithLabelValues(name)
+}
+
+func (w *workqueueMetrics) NewAddsMetric(name string) workqueue.CounterMetric {
+	return w.adds.WithLabelValues(name)
+}
+
+func (w *workqueueMetrics) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return w.latency.WithLabelValues(name)
+}
+
+func (w *workqueueMetrics) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return w.workDuration.WithLabelValues(name)
+}
+
+func (w *workqueueMetrics) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return w.unfinishedWorkSeconds.WithLabelValues(name)
+}
+
+func (w *workqueueMetrics) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return w.longestRunningProcessor.WithLabelValues(name)
+}
+
+func (w *workqueueMetrics) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return w.retries.WithLabelValues(name)
+}
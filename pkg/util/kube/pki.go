@@ -91,7 +91,8 @@ func SecretTLSCertChain(ctx context.Context, secretLister corelisters.SecretList
 
 // SecretTLSKeyPairAndCA returns the X.509 certificate chain and private key of
 // the leaf certificate contained in the target Secret. If the ca.crt field exists
-// on the Secret, it is parsed and added to the end of the certificate chain.
+// on the Secret, the certificates it contains are parsed and added to the end
+// of the certificate chain.
 func SecretTLSKeyPairAndCA(ctx context.Context, secretLister corelisters.SecretLister, namespace, name string) ([]*x509.Certificate, crypto.Signer, error) {
 	certs, key, err := SecretTLSKeyPair(ctx, secretLister, namespace, name)
 	if err != nil {
@@ -107,12 +108,39 @@ func SecretTLSKeyPairAndCA(ctx context.Context, secretLister corelisters.SecretL
 	if !ok || len(caBytes) == 0 {
 		return certs, key, nil
 	}
-	ca, err := pki.DecodeX509CertificateBytes(caBytes)
+	cas, err := pki.DecodeX509CertificateChainBytes(caBytes)
 	if err != nil {
 		return nil, key, errors.NewInvalidData(err.Error())
 	}
 
-	return append(certs, ca), key, nil
+	return append(certs, cas...), key, nil
+}
+
+// SecretTLSCertChainAndCA returns the X.509 certificate chain contained in the
+// target Secret, without requiring it to contain a private key. If the ca.crt
+// field exists on the Secret, the certificates it contains are parsed and
+// added to the end of the certificate chain.
+func SecretTLSCertChainAndCA(ctx context.Context, secretLister corelisters.SecretLister, namespace, name string) ([]*x509.Certificate, error) {
+	certs, err := SecretTLSCertChain(ctx, secretLister, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := secretLister.Secrets(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	caBytes, ok := secret.Data[cmmeta.TLSCAKey]
+	if !ok || len(caBytes) == 0 {
+		return certs, nil
+	}
+	cas, err := pki.DecodeX509CertificateChainBytes(caBytes)
+	if err != nil {
+		return nil, errors.NewInvalidData(err.Error())
+	}
+
+	return append(certs, cas...), nil
 }
 
 func SecretTLSKeyPair(ctx context.Context, secretLister corelisters.SecretLister, namespace, name string) ([]*x509.Certificate, crypto.Signer, error) {
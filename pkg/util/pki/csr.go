@@ -19,7 +19,10 @@ package pki
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
@@ -39,7 +42,7 @@ import (
 func IPAddressesForCertificate(crt *v1.Certificate) []net.IP {
 	var ipAddresses []net.IP
 	var ip net.IP
-	for _, ipName := range crt.Spec.IPAddresses {
+	for _, ipName := range SANsWithoutCommonName(crt, crt.Spec.IPAddresses) {
 		ip = net.ParseIP(ipName)
 		if ip != nil {
 			ipAddresses = append(ipAddresses, ip)
@@ -57,13 +60,53 @@ func URIsForCertificate(crt *v1.Certificate) ([]*url.URL, error) {
 	return uris, nil
 }
 
+// MaxCommonNameLength is the maximum length of a Common Name, as defined by
+// the ub-common-name upper bound of RFC 5280.
+const MaxCommonNameLength = 64
+
+// CommonNameForCertificate returns the Common Name that should be requested
+// for the given Certificate. This is `spec.commonName`, unless it is not set
+// and the Certificate has the CommonNameFromDNSNamesAnnotationKey annotation,
+// in which case it is the first of `spec.dnsNames`. If that name is longer
+// than MaxCommonNameLength, no Common Name is returned and omitted is true.
+func CommonNameForCertificate(crt *v1.Certificate) (commonName string, omitted bool) {
+	if len(crt.Spec.CommonName) > 0 || len(crt.Spec.DNSNames) == 0 ||
+		crt.Annotations[v1.CommonNameFromDNSNamesAnnotationKey] != "true" {
+		return crt.Spec.CommonName, false
+	}
+	if len(crt.Spec.DNSNames[0]) > MaxCommonNameLength {
+		return "", true
+	}
+	return crt.Spec.DNSNames[0], false
+}
+
 func DNSNamesForCertificate(crt *v1.Certificate) ([]string, error) {
 	_, err := URLsFromStrings(crt.Spec.DNSNames)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DNSNames: %s", err)
 	}
 
-	return crt.Spec.DNSNames, nil
+	return SANsWithoutCommonName(crt, crt.Spec.DNSNames), nil
+}
+
+// SANsWithoutCommonName returns the given subject alt names of the
+// Certificate with its Common Name removed if excludeCommonNameFromSANs is
+// set, and otherwise returns them unchanged.
+func SANsWithoutCommonName(crt *v1.Certificate, sans []string) []string {
+	if !crt.Spec.ExcludeCommonNameFromSANs {
+		return sans
+	}
+	commonName, _ := CommonNameForCertificate(crt)
+	if commonName == "" {
+		return sans
+	}
+	var filtered []string
+	for _, san := range sans {
+		if san != commonName {
+			filtered = append(filtered, san)
+		}
+	}
+	return filtered
 }
 
 func URLsFromStrings(urlStrs []string) ([]*url.URL, error) {
@@ -178,7 +221,7 @@ func BuildCertManagerKeyUsages(ku x509.KeyUsage, eku []x509.ExtKeyUsage) []v1.Ke
 // The CSR will not be signed, and should be passed to either EncodeCSR or
 // to the x509.CreateCertificateRequest function.
 func GenerateCSR(crt *v1.Certificate) (*x509.CertificateRequest, error) {
-	commonName := crt.Spec.CommonName
+	commonName, _ := CommonNameForCertificate(crt)
 	iPAddresses := IPAddressesForCertificate(crt)
 	organization := OrganizationForCertificate(crt)
 	subject := SubjectForCertificate(crt)
@@ -193,8 +236,8 @@ func GenerateCSR(crt *v1.Certificate) (*x509.CertificateRequest, error) {
 		return nil, err
 	}
 
-	if len(commonName) == 0 && len(dnsNames) == 0 && len(uriNames) == 0 && len(crt.Spec.EmailAddresses) == 0 && len(crt.Spec.IPAddresses) == 0 {
-		return nil, fmt.Errorf("no common name, DNS name, URI SAN, or Email SAN specified on certificate")
+	if len(commonName) == 0 && len(dnsNames) == 0 && len(uriNames) == 0 && len(crt.Spec.EmailAddresses) == 0 && len(crt.Spec.IPAddresses) == 0 && len(crt.Spec.OtherNames) == 0 {
+		return nil, fmt.Errorf("no common name, DNS name, URI SAN, Email SAN, or otherName SAN specified on certificate")
 	}
 
 	pubKeyAlgo, sigAlgo, err := SignatureAlgorithm(crt)
@@ -210,6 +253,20 @@ func GenerateCSR(crt *v1.Certificate) (*x509.CertificateRequest, error) {
 		}
 	}
 
+	// The standard library cannot encode otherNames, so the subjectAltName
+	// extension is built by hand if any are requested.
+	if len(crt.Spec.OtherNames) > 0 {
+		otherNames, err := marshalOtherNames(crt.Spec.OtherNames)
+		if err != nil {
+			return nil, err
+		}
+		sans := &x509.Certificate{DNSNames: dnsNames, IPAddresses: iPAddresses, URIs: uriNames, EmailAddresses: crt.Spec.EmailAddresses}
+		if err := setOtherNames(sans, otherNames); err != nil {
+			return nil, err
+		}
+		extraExtensions = append(extraExtensions, sans.ExtraExtensions...)
+	}
+
 	return &x509.CertificateRequest{
 		Version:            3,
 		SignatureAlgorithm: sigAlgo,
@@ -270,8 +327,8 @@ func buildKeyUsagesExtensionsForCertificate(crt *v1.Certificate) ([]pkix.Extensi
 // generated by GenerateCSR.
 // The PublicKey field must be populated by the caller.
 func GenerateTemplate(crt *v1.Certificate) (*x509.Certificate, error) {
-	commonName := crt.Spec.CommonName
-	dnsNames := crt.Spec.DNSNames
+	commonName, _ := CommonNameForCertificate(crt)
+	dnsNames := SANsWithoutCommonName(crt, crt.Spec.DNSNames)
 	ipAddresses := IPAddressesForCertificate(crt)
 	organization := OrganizationForCertificate(crt)
 	subject := SubjectForCertificate(crt)
@@ -284,10 +341,15 @@ func GenerateTemplate(crt *v1.Certificate) (*x509.Certificate, error) {
 		return nil, err
 	}
 
-	if len(commonName) == 0 && len(dnsNames) == 0 && len(ipAddresses) == 0 && len(uris) == 0 && len(crt.Spec.EmailAddresses) == 0 {
+	if len(commonName) == 0 && len(dnsNames) == 0 && len(ipAddresses) == 0 && len(uris) == 0 && len(crt.Spec.EmailAddresses) == 0 && len(crt.Spec.OtherNames) == 0 {
 		return nil, fmt.Errorf("no common name or subject alt names requested on certificate")
 	}
 
+	otherNames, err := marshalOtherNames(crt.Spec.OtherNames)
+	if err != nil {
+		return nil, err
+	}
+
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate serial number: %s", err.Error())
@@ -300,7 +362,7 @@ func GenerateTemplate(crt *v1.Certificate) (*x509.Certificate, error) {
 		return nil, err
 	}
 
-	return &x509.Certificate{
+	template := &x509.Certificate{
 		Version:               3,
 		BasicConstraintsValid: true,
 		SerialNumber:          serialNumber,
@@ -326,7 +388,13 @@ func GenerateTemplate(crt *v1.Certificate) (*x509.Certificate, error) {
 		IPAddresses:    ipAddresses,
 		URIs:           uris,
 		EmailAddresses: crt.Spec.EmailAddresses,
-	}, nil
+	}
+	setCAConstraints(template, crt.Spec.IsCA, crt.Spec.MaxPathLen)
+	if err := setOtherNames(template, otherNames); err != nil {
+		return nil, err
+	}
+
+	return template, nil
 }
 
 // GenerateTemplate will create a x509.Certificate for the given
@@ -337,7 +405,66 @@ func GenerateTemplateFromCertificateRequest(cr *v1.CertificateRequest) (*x509.Ce
 	if err != nil {
 		return nil, err
 	}
-	return GenerateTemplateFromCSRPEMWithUsages(cr.Spec.Request, certDuration, cr.Spec.IsCA, keyUsage, extKeyUsage)
+	template, err := GenerateTemplateFromCSRPEMWithUsages(cr.Spec.Request, certDuration, cr.Spec.IsCA, keyUsage, extKeyUsage)
+	if err != nil {
+		return nil, err
+	}
+	setCAConstraints(template, cr.Spec.IsCA, cr.Spec.MaxPathLen)
+
+	if cr.Spec.SignatureAlgorithm != "" {
+		sigAlgo, ok := signatureAlgorithms[cr.Spec.SignatureAlgorithm]
+		if !ok {
+			return nil, fmt.Errorf("unsupported signature algorithm specified: %s", cr.Spec.SignatureAlgorithm)
+		}
+		template.SignatureAlgorithm = sigAlgo.sigAlgo
+	}
+
+	// If a specific validity window has been requested, honor it rather than
+	// starting from now.
+	if cr.Spec.NotBefore != nil {
+		template.NotBefore = cr.Spec.NotBefore.Time
+		template.NotAfter = cr.Spec.NotBefore.Add(certDuration)
+	}
+
+	return template, nil
+}
+
+// RoundNotAfter rounds the NotAfter of the template to a multiple of the
+// given boundary since the zero time in UTC, e.g. to the start of a minute,
+// hour or day. NotAfter is rounded down, unless roundUp is true or rounding
+// down would leave the certificate valid for no longer than renewBefore after
+// NotBefore, in which case it is rounded up instead. This ensures that
+// rounding never causes a certificate to be due for renewal as soon as it has
+// been issued. It is left unchanged if boundary is not positive.
+func RoundNotAfter(template *x509.Certificate, boundary time.Duration, roundUp bool, renewBefore time.Duration) {
+	if boundary <= 0 {
+		return
+	}
+
+	notAfter := template.NotAfter.Truncate(boundary)
+	if notAfter.Equal(template.NotAfter) {
+		return
+	}
+	if roundUp || !notAfter.After(template.NotBefore.Add(renewBefore)) {
+		notAfter = notAfter.Add(boundary)
+	}
+
+	template.NotAfter = notAfter
+}
+
+// setCAConstraints adds the key usages required by a CA to the template if
+// isCA is true, and sets the pathLenConstraint of its BasicConstraints
+// extension if maxPathLen is set. A maxPathLen of zero is encoded explicitly,
+// rather than being treated as unset.
+func setCAConstraints(template *x509.Certificate, isCA bool, maxPathLen *int32) {
+	if !isCA {
+		return
+	}
+	template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	if maxPathLen != nil {
+		template.MaxPathLen = int(*maxPathLen)
+		template.MaxPathLenZero = *maxPathLen == 0
+	}
 }
 
 func GenerateTemplateFromCSRPEM(csrPEM []byte, duration time.Duration, isCA bool) (*x509.Certificate, error) {
@@ -368,7 +495,14 @@ func GenerateTemplateFromCSRPEMWithUsages(csrPEM []byte, duration time.Duration,
 		return nil, fmt.Errorf("failed to generate serial number: %s", err.Error())
 	}
 
-	return &x509.Certificate{
+	// otherName subject alternative names are not decoded by the standard
+	// library, so they are copied from the CSR's subjectAltName extension.
+	otherNames, err := rawOtherNames(csr.Extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
 		Version:               csr.Version,
 		BasicConstraintsValid: true,
 		SerialNumber:          serialNumber,
@@ -385,7 +519,12 @@ func GenerateTemplateFromCSRPEMWithUsages(csrPEM []byte, duration time.Duration,
 		IPAddresses:    csr.IPAddresses,
 		EmailAddresses: csr.EmailAddresses,
 		URIs:           csr.URIs,
-	}, nil
+	}
+	if err := setOtherNames(template, otherNames); err != nil {
+		return nil, err
+	}
+
+	return template, nil
 }
 
 // SignCertificate returns a signed *x509.Certificate given a template
@@ -426,6 +565,36 @@ func SignCSRTemplate(caCerts []*x509.Certificate, caKey crypto.Signer, template
 
 	issuingCACert := caCerts[0]
 
+	matches, err := PublicKeyMatchesCertificate(caKey.Public(), issuingCACert)
+	if err != nil {
+		return PEMBundle{}, fmt.Errorf("unsupported CA key: %w", err)
+	}
+	if !matches {
+		return PEMBundle{}, errors.New("the CA private key does not match the public key of the issuing CA certificate")
+	}
+
+	switch template.PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return PEMBundle{}, fmt.Errorf("unsupported public key type %T in certificate template", template.PublicKey)
+	}
+
+	// The signature of the issued certificate is made with the CA's key, so
+	// the signature algorithm depends only on the CA's key type and not on the
+	// type of the key being certified. This allows e.g. an ECDSA certificate
+	// to be issued by an RSA CA and vice versa.
+	if template.SignatureAlgorithm == x509.UnknownSignatureAlgorithm {
+		sigAlgo, err := DefaultSignatureAlgorithm(caKey.Public())
+		if err != nil {
+			return PEMBundle{}, err
+		}
+		tmpl := *template
+		tmpl.SignatureAlgorithm = sigAlgo
+		template = &tmpl
+	} else if err := SignatureAlgorithmMatchesKey(template.SignatureAlgorithm, caKey.Public()); err != nil {
+		return PEMBundle{}, err
+	}
+
 	_, cert, err := SignCertificate(template, issuingCACert, template.PublicKey, caKey)
 	if err != nil {
 		return PEMBundle{}, err
@@ -478,6 +647,31 @@ func EncodeX509Chain(certs []*x509.Certificate) ([]byte, error) {
 	return caPem.Bytes(), nil
 }
 
+// EncodeX509CABundle will encode the certificates of each of the given chains
+// into a single PEM bundle, in order and without duplicates. Unlike
+// EncodeX509Chain, self-signed certificates are included.
+func EncodeX509CABundle(chains ...[]*x509.Certificate) ([]byte, error) {
+	caPem := bytes.NewBuffer([]byte{})
+	var seen []*x509.Certificate
+	for _, chain := range chains {
+	certs:
+		for _, cert := range chain {
+			for _, s := range seen {
+				if s.Equal(cert) {
+					continue certs
+				}
+			}
+			seen = append(seen, cert)
+			err := pem.Encode(caPem, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return caPem.Bytes(), nil
+}
+
 // SignatureAlgorithm will determine the appropriate signature algorithm for
 // the given certificate.
 // Adapted from https://github.com/cloudflare/cfssl/blob/master/csr/csr.go#L102
@@ -525,5 +719,82 @@ func SignatureAlgorithm(crt *v1.Certificate) (x509.PublicKeyAlgorithm, x509.Sign
 	default:
 		return x509.UnknownPublicKeyAlgorithm, x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported algorithm specified: %s. should be either 'ecdsa' or 'rsa", crt.Spec.PrivateKey.Algorithm)
 	}
+
+	if crt.Spec.CSRSignatureAlgorithm != "" {
+		override, ok := signatureAlgorithms[crt.Spec.CSRSignatureAlgorithm]
+		if !ok {
+			return x509.UnknownPublicKeyAlgorithm, x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported csr signature algorithm specified: %s", crt.Spec.CSRSignatureAlgorithm)
+		}
+		if override.pubKeyAlgo != pubKeyAlgo {
+			return x509.UnknownPublicKeyAlgorithm, x509.UnknownSignatureAlgorithm, fmt.Errorf("csr signature algorithm %s cannot be used with the %s private key algorithm", crt.Spec.CSRSignatureAlgorithm, pubKeyAlgo)
+		}
+		sigAlgo = override.sigAlgo
+	}
+
 	return pubKeyAlgo, sigAlgo, nil
 }
+
+// SignatureAlgorithmMatchesKey returns an error if the given signature
+// algorithm cannot be used to sign with the private key corresponding to the
+// given public key. An unknown signature algorithm is always accepted, as it
+// leaves the choice of algorithm to the signer.
+func SignatureAlgorithmMatchesKey(sigAlgo x509.SignatureAlgorithm, publicKey crypto.PublicKey) error {
+	if sigAlgo == x509.UnknownSignatureAlgorithm {
+		return nil
+	}
+
+	var pubKeyAlgo x509.PublicKeyAlgorithm
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		pubKeyAlgo = x509.RSA
+	case *ecdsa.PublicKey:
+		pubKeyAlgo = x509.ECDSA
+	default:
+		return fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	for _, algo := range signatureAlgorithms {
+		if algo.sigAlgo == sigAlgo && algo.pubKeyAlgo == pubKeyAlgo {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature algorithm %s cannot be used with a %s key", sigAlgo, pubKeyAlgo)
+}
+
+// DefaultSignatureAlgorithm returns the signature algorithm used to sign
+// certificates with the private key corresponding to the given public key when
+// no algorithm has been requested. It matches the defaults of the Go standard
+// library: SHA-256 for RSA keys, and a hash sized to the curve for ECDSA keys.
+func DefaultSignatureAlgorithm(publicKey crypto.PublicKey) (x509.SignatureAlgorithm, error) {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		return x509.SHA256WithRSA, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return x509.ECDSAWithSHA256, nil
+		case elliptic.P384():
+			return x509.ECDSAWithSHA384, nil
+		case elliptic.P521():
+			return x509.ECDSAWithSHA512, nil
+		default:
+			return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported ecdsa curve %s", pub.Curve.Params().Name)
+		}
+	default:
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// signatureAlgorithms maps each supported signature algorithm to its x509
+// signature algorithm and the public key algorithm it can be used with.
+var signatureAlgorithms = map[v1.SignatureAlgorithm]struct {
+	sigAlgo    x509.SignatureAlgorithm
+	pubKeyAlgo x509.PublicKeyAlgorithm
+}{
+	v1.SHA256WithRSASignatureAlgorithm:   {x509.SHA256WithRSA, x509.RSA},
+	v1.SHA384WithRSASignatureAlgorithm:   {x509.SHA384WithRSA, x509.RSA},
+	v1.SHA512WithRSASignatureAlgorithm:   {x509.SHA512WithRSA, x509.RSA},
+	v1.ECDSAWithSHA256SignatureAlgorithm: {x509.ECDSAWithSHA256, x509.ECDSA},
+	v1.ECDSAWithSHA384SignatureAlgorithm: {x509.ECDSAWithSHA384, x509.ECDSA},
+	v1.ECDSAWithSHA512SignatureAlgorithm: {x509.ECDSAWithSHA512, x509.ECDSA},
+}
@@ -22,13 +22,17 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/pem"
 	"math/big"
+	"net"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/jetstack/cert-manager/pkg/util"
@@ -119,11 +123,14 @@ func TestBuildUsages(t *testing.T) {
 }
 
 func TestCommonNameForCertificate(t *testing.T) {
+	longDNSName := strings.Repeat("a", 60) + ".example.com"
 	type testT struct {
-		name        string
-		crtCN       string
-		crtDNSNames []string
-		expectedCN  string
+		name            string
+		crtCN           string
+		crtDNSNames     []string
+		fromDNSNames    bool
+		expectedCN      string
+		expectedOmitted bool
 	}
 	tests := []testT{
 		{
@@ -147,14 +154,46 @@ func TestCommonNameForCertificate(t *testing.T) {
 			crtDNSNames: []string{"dnsname1", "dnsname2"},
 			expectedCN:  "",
 		},
+		{
+			name:         "certificate deriving the common name from multiple dns names",
+			crtDNSNames:  []string{"dnsname1", "dnsname2"},
+			fromDNSNames: true,
+			expectedCN:   "dnsname1",
+		},
+		{
+			name:         "certificate deriving the common name with common name set",
+			crtCN:        "cn",
+			crtDNSNames:  []string{"dnsname1"},
+			fromDNSNames: true,
+			expectedCN:   "cn",
+		},
+		{
+			name:            "certificate deriving the common name from a first dns name longer than 64 characters",
+			crtDNSNames:     []string{longDNSName, "dnsname2"},
+			fromDNSNames:    true,
+			expectedCN:      "",
+			expectedOmitted: true,
+		},
+		{
+			name:        "certificate not deriving the common name from a first dns name longer than 64 characters",
+			crtDNSNames: []string{longDNSName},
+			expectedCN:  "",
+		},
 	}
 	testFn := func(test testT) func(*testing.T) {
 		return func(t *testing.T) {
-			actualCN := buildCertificate(test.crtCN, test.crtDNSNames...).Spec.CommonName
+			crt := buildCertificate(test.crtCN, test.crtDNSNames...)
+			if test.fromDNSNames {
+				crt.Annotations = map[string]string{cmapi.CommonNameFromDNSNamesAnnotationKey: "true"}
+			}
+			actualCN, omitted := CommonNameForCertificate(crt)
 			if actualCN != test.expectedCN {
 				t.Errorf("expected %q but got %q", test.expectedCN, actualCN)
 				return
 			}
+			if omitted != test.expectedOmitted {
+				t.Errorf("expected omitted=%t but got %t", test.expectedOmitted, omitted)
+			}
 		}
 	}
 	for _, test := range tests {
@@ -229,6 +268,7 @@ func TestSignatureAlgorithmForCertificate(t *testing.T) {
 		name            string
 		keyAlgo         cmapi.PrivateKeyAlgorithm
 		keySize         int
+		csrSigAlgo      cmapi.SignatureAlgorithm
 		expectErr       bool
 		expectedSigAlgo x509.SignatureAlgorithm
 		expectedKeyType x509.PublicKeyAlgorithm
@@ -312,11 +352,54 @@ func TestSignatureAlgorithmForCertificate(t *testing.T) {
 			keyAlgo:   cmapi.PrivateKeyAlgorithm("blah"),
 			expectErr: true,
 		},
+		{
+			name:            "certificate with KeyAlgorithm rsa and size 4096 and csr signature algorithm SHA256WithRSA",
+			keyAlgo:         cmapi.RSAKeyAlgorithm,
+			keySize:         4096,
+			csrSigAlgo:      cmapi.SHA256WithRSASignatureAlgorithm,
+			expectedSigAlgo: x509.SHA256WithRSA,
+			expectedKeyType: x509.RSA,
+		},
+		{
+			name:            "certificate with KeyAlgorithm not set and csr signature algorithm SHA512WithRSA",
+			keyAlgo:         cmapi.PrivateKeyAlgorithm(""),
+			csrSigAlgo:      cmapi.SHA512WithRSASignatureAlgorithm,
+			expectedSigAlgo: x509.SHA512WithRSA,
+			expectedKeyType: x509.RSA,
+		},
+		{
+			name:            "certificate with KeyAlgorithm ecdsa and size 256 and csr signature algorithm ECDSAWithSHA384",
+			keyAlgo:         cmapi.ECDSAKeyAlgorithm,
+			keySize:         256,
+			csrSigAlgo:      cmapi.ECDSAWithSHA384SignatureAlgorithm,
+			expectedSigAlgo: x509.ECDSAWithSHA384,
+			expectedKeyType: x509.ECDSA,
+		},
+		{
+			name:       "certificate with KeyAlgorithm ecdsa and an rsa csr signature algorithm",
+			keyAlgo:    cmapi.ECDSAKeyAlgorithm,
+			csrSigAlgo: cmapi.SHA256WithRSASignatureAlgorithm,
+			expectErr:  true,
+		},
+		{
+			name:       "certificate with KeyAlgorithm rsa and an ecdsa csr signature algorithm",
+			keyAlgo:    cmapi.RSAKeyAlgorithm,
+			csrSigAlgo: cmapi.ECDSAWithSHA256SignatureAlgorithm,
+			expectErr:  true,
+		},
+		{
+			name:       "certificate with csr signature algorithm set to unknown algorithm",
+			keyAlgo:    cmapi.RSAKeyAlgorithm,
+			csrSigAlgo: cmapi.SignatureAlgorithm("MD5WithRSA"),
+			expectErr:  true,
+		},
 	}
 
 	testFn := func(test testT) func(*testing.T) {
 		return func(t *testing.T) {
-			actualPKAlgo, actualSigAlgo, err := SignatureAlgorithm(buildCertificateWithKeyParams(test.keyAlgo, test.keySize))
+			crt := buildCertificateWithKeyParams(test.keyAlgo, test.keySize)
+			crt.Spec.CSRSignatureAlgorithm = test.csrSigAlgo
+			actualPKAlgo, actualSigAlgo, err := SignatureAlgorithm(crt)
 			if test.expectErr && err == nil {
 				t.Error("expected err, but got no error")
 				return
@@ -453,6 +536,64 @@ func TestGenerateCSR(t *testing.T) {
 				ExtraExtensions:    defaultExtraExtensions,
 			},
 		},
+		{
+			name: "Generate CSR deriving the CN from the first DNS name",
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.CommonNameFromDNSNamesAnnotationKey: "true"}},
+				Spec:       cmapi.CertificateSpec{DNSNames: []string{"example.org", "www.example.org"}},
+			},
+			want: &x509.CertificateRequest{Version: 3,
+				SignatureAlgorithm: x509.SHA256WithRSA,
+				PublicKeyAlgorithm: x509.RSA,
+				Subject:            pkix.Name{CommonName: "example.org"},
+				DNSNames:           []string{"example.org", "www.example.org"},
+				ExtraExtensions:    defaultExtraExtensions,
+			},
+		},
+		{
+			name: "Generate CSR omitting the CN if the first DNS name is longer than 64 characters",
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.CommonNameFromDNSNamesAnnotationKey: "true"}},
+				Spec:       cmapi.CertificateSpec{DNSNames: []string{strings.Repeat("a", 60) + ".example.org", "example.org"}},
+			},
+			want: &x509.CertificateRequest{Version: 3,
+				SignatureAlgorithm: x509.SHA256WithRSA,
+				PublicKeyAlgorithm: x509.RSA,
+				DNSNames:           []string{strings.Repeat("a", 60) + ".example.org", "example.org"},
+				ExtraExtensions:    defaultExtraExtensions,
+			},
+		},
+		{
+			name: "Generate CSR excluding the CN from the DNS names and IP addresses",
+			crt: &cmapi.Certificate{Spec: cmapi.CertificateSpec{
+				CommonName:                "example.org",
+				ExcludeCommonNameFromSANs: true,
+				DNSNames:                  []string{"example.org", "www.example.org"},
+				IPAddresses:               []string{"example.org", "10.0.0.1"},
+			}},
+			want: &x509.CertificateRequest{Version: 3,
+				SignatureAlgorithm: x509.SHA256WithRSA,
+				PublicKeyAlgorithm: x509.RSA,
+				Subject:            pkix.Name{CommonName: "example.org"},
+				DNSNames:           []string{"www.example.org"},
+				IPAddresses:        []net.IP{net.ParseIP("10.0.0.1")},
+				ExtraExtensions:    defaultExtraExtensions,
+			},
+		},
+		{
+			name: "Generate CSR excluding the CN derived from the first DNS name from the DNS names",
+			crt: &cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.CommonNameFromDNSNamesAnnotationKey: "true"}},
+				Spec:       cmapi.CertificateSpec{DNSNames: []string{"example.org", "www.example.org"}, ExcludeCommonNameFromSANs: true},
+			},
+			want: &x509.CertificateRequest{Version: 3,
+				SignatureAlgorithm: x509.SHA256WithRSA,
+				PublicKeyAlgorithm: x509.RSA,
+				Subject:            pkix.Name{CommonName: "example.org"},
+				DNSNames:           []string{"www.example.org"},
+				ExtraExtensions:    defaultExtraExtensions,
+			},
+		},
 		{
 			name:    "Error on generating CSR from certificate with no subject",
 			crt:     &cmapi.Certificate{Spec: cmapi.CertificateSpec{}},
@@ -473,6 +614,56 @@ func TestGenerateCSR(t *testing.T) {
 	}
 }
 
+func TestEncodeCSRSignatureAlgorithm(t *testing.T) {
+	tests := map[string]struct {
+		keyAlgo         cmapi.PrivateKeyAlgorithm
+		keySize         int
+		csrSigAlgo      cmapi.SignatureAlgorithm
+		expectedSigAlgo x509.SignatureAlgorithm
+	}{
+		"rsa key with default signature algorithm": {
+			keyAlgo:         cmapi.RSAKeyAlgorithm,
+			keySize:         3072,
+			expectedSigAlgo: x509.SHA384WithRSA,
+		},
+		"rsa key with overridden signature algorithm": {
+			keyAlgo:         cmapi.RSAKeyAlgorithm,
+			keySize:         3072,
+			csrSigAlgo:      cmapi.SHA256WithRSASignatureAlgorithm,
+			expectedSigAlgo: x509.SHA256WithRSA,
+		},
+		"ecdsa key with default signature algorithm": {
+			keyAlgo:         cmapi.ECDSAKeyAlgorithm,
+			keySize:         384,
+			expectedSigAlgo: x509.ECDSAWithSHA384,
+		},
+		"ecdsa key with overridden signature algorithm": {
+			keyAlgo:         cmapi.ECDSAKeyAlgorithm,
+			keySize:         384,
+			csrSigAlgo:      cmapi.ECDSAWithSHA512SignatureAlgorithm,
+			expectedSigAlgo: x509.ECDSAWithSHA512,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			crt := buildCertificateWithKeyParams(test.keyAlgo, test.keySize)
+			crt.Spec.CSRSignatureAlgorithm = test.csrSigAlgo
+
+			pk, err := GeneratePrivateKeyForCertificate(crt)
+			require.NoError(t, err)
+			template, err := GenerateCSR(crt)
+			require.NoError(t, err)
+			csrDER, err := EncodeCSR(template, pk)
+			require.NoError(t, err)
+
+			csr, err := x509.ParseCertificateRequest(csrDER)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedSigAlgo, csr.SignatureAlgorithm)
+			assert.NoError(t, csr.CheckSignature())
+		})
+	}
+}
+
 func Test_buildKeyUsagesExtensionsForCertificate(t *testing.T) {
 	// 0xa0 = DigitalSignature and Encipherment usage
 	asn1DefaultKeyUsage, err := asn1.Marshal(asn1.BitString{Bytes: []byte{0xa0}, BitLength: asn1BitLength([]byte{0xa0})})
@@ -675,3 +866,537 @@ func TestSignCSRTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestSignCSRTemplateMixedKeyTypes(t *testing.T) {
+	mustGenerateKey := func(algorithm x509.PublicKeyAlgorithm, size int) crypto.Signer {
+		var pk crypto.Signer
+		var err error
+		switch algorithm {
+		case x509.RSA:
+			pk, err = GenerateRSAPrivateKey(size)
+		case x509.ECDSA:
+			pk, err = GenerateECPrivateKey(size)
+		}
+		require.NoError(t, err)
+		return pk
+	}
+
+	mustCreateCA := func(pk crypto.Signer) *x509.Certificate {
+		tmpl := &x509.Certificate{
+			Version:               3,
+			BasicConstraintsValid: true,
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "ca"},
+			NotBefore:             time.Now(),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+			IsCA:                  true,
+		}
+		_, cert, err := SignCertificate(tmpl, tmpl, pk.Public(), pk)
+		require.NoError(t, err)
+		return cert
+	}
+
+	leafTemplate := func(pk crypto.Signer, sigAlgo x509.SignatureAlgorithm) *x509.Certificate {
+		return &x509.Certificate{
+			Version:            3,
+			SerialNumber:       big.NewInt(2),
+			Subject:            pkix.Name{CommonName: "leaf"},
+			DNSNames:           []string{"example.com"},
+			NotBefore:          time.Now(),
+			NotAfter:           time.Now().Add(time.Minute),
+			KeyUsage:           x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			PublicKey:          pk.Public(),
+			SignatureAlgorithm: sigAlgo,
+		}
+	}
+
+	rsaCAKey := mustGenerateKey(x509.RSA, 2048)
+	ecCAKey := mustGenerateKey(x509.ECDSA, 256)
+	ec384CAKey := mustGenerateKey(x509.ECDSA, 384)
+	rsaLeafKey := mustGenerateKey(x509.RSA, 2048)
+	ecLeafKey := mustGenerateKey(x509.ECDSA, 256)
+
+	tests := map[string]struct {
+		caKey   crypto.Signer
+		caCert  *x509.Certificate
+		leafKey crypto.Signer
+		sigAlgo x509.SignatureAlgorithm
+
+		expSigAlgo x509.SignatureAlgorithm
+		expErr     bool
+	}{
+		"RSA CA signing an RSA leaf": {
+			caKey:      rsaCAKey,
+			leafKey:    rsaLeafKey,
+			expSigAlgo: x509.SHA256WithRSA,
+		},
+		"RSA CA signing an ECDSA leaf": {
+			caKey:      rsaCAKey,
+			leafKey:    ecLeafKey,
+			expSigAlgo: x509.SHA256WithRSA,
+		},
+		"ECDSA CA signing an ECDSA leaf": {
+			caKey:      ecCAKey,
+			leafKey:    ecLeafKey,
+			expSigAlgo: x509.ECDSAWithSHA256,
+		},
+		"ECDSA CA signing an RSA leaf": {
+			caKey:      ecCAKey,
+			leafKey:    rsaLeafKey,
+			expSigAlgo: x509.ECDSAWithSHA256,
+		},
+		"ECDSA P-384 CA signing an RSA leaf uses a hash matching the curve": {
+			caKey:      ec384CAKey,
+			leafKey:    rsaLeafKey,
+			expSigAlgo: x509.ECDSAWithSHA384,
+		},
+		"RSA CA signing an ECDSA leaf with a requested RSA signature algorithm": {
+			caKey:      rsaCAKey,
+			leafKey:    ecLeafKey,
+			sigAlgo:    x509.SHA512WithRSA,
+			expSigAlgo: x509.SHA512WithRSA,
+		},
+		"ECDSA CA signing an RSA leaf with a requested ECDSA signature algorithm": {
+			caKey:      ecCAKey,
+			leafKey:    rsaLeafKey,
+			sigAlgo:    x509.ECDSAWithSHA512,
+			expSigAlgo: x509.ECDSAWithSHA512,
+		},
+		"should error if the requested signature algorithm cannot be used with an ECDSA CA key": {
+			caKey:   ecCAKey,
+			leafKey: ecLeafKey,
+			sigAlgo: x509.SHA256WithRSA,
+			expErr:  true,
+		},
+		"should error if the requested signature algorithm cannot be used with an RSA CA key": {
+			caKey:   rsaCAKey,
+			leafKey: rsaLeafKey,
+			sigAlgo: x509.ECDSAWithSHA256,
+			expErr:  true,
+		},
+		"should error if the CA key does not match the CA certificate": {
+			caKey:   rsaCAKey,
+			caCert:  mustCreateCA(ecCAKey),
+			leafKey: ecLeafKey,
+			expErr:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			caCert := test.caCert
+			if caCert == nil {
+				caCert = mustCreateCA(test.caKey)
+			}
+			template := leafTemplate(test.leafKey, test.sigAlgo)
+
+			bundle, err := SignCSRTemplate([]*x509.Certificate{caCert}, test.caKey, template)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// the caller's template must not be modified
+			assert.Equal(t, test.sigAlgo, template.SignatureAlgorithm)
+
+			cert, err := DecodeX509CertificateBytes(bundle.ChainPEM)
+			require.NoError(t, err)
+			assert.Equal(t, test.expSigAlgo, cert.SignatureAlgorithm)
+
+			matches, err := PublicKeyMatchesCertificate(test.leafKey.Public(), cert)
+			require.NoError(t, err)
+			assert.True(t, matches, "issued certificate does not contain the leaf public key")
+
+			roots := x509.NewCertPool()
+			roots.AddCert(caCert)
+			_, err = cert.Verify(x509.VerifyOptions{
+				DNSName: "example.com",
+				Roots:   roots,
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDefaultSignatureAlgorithm(t *testing.T) {
+	for _, test := range []struct {
+		algorithm  x509.PublicKeyAlgorithm
+		size       int
+		expSigAlgo x509.SignatureAlgorithm
+	}{
+		{x509.RSA, 2048, x509.SHA256WithRSA},
+		{x509.RSA, 4096, x509.SHA256WithRSA},
+		{x509.ECDSA, 256, x509.ECDSAWithSHA256},
+		{x509.ECDSA, 384, x509.ECDSAWithSHA384},
+		{x509.ECDSA, 521, x509.ECDSAWithSHA512},
+	} {
+		var pk crypto.Signer
+		var err error
+		if test.algorithm == x509.RSA {
+			pk, err = GenerateRSAPrivateKey(test.size)
+		} else {
+			pk, err = GenerateECPrivateKey(test.size)
+		}
+		require.NoError(t, err)
+
+		sigAlgo, err := DefaultSignatureAlgorithm(pk.Public())
+		require.NoError(t, err)
+		assert.Equal(t, test.expSigAlgo, sigAlgo, "%s %d", test.algorithm, test.size)
+	}
+
+	_, err := DefaultSignatureAlgorithm("not a key")
+	assert.Error(t, err)
+}
+
+func TestGenerateTemplateFromCertificateRequestBasicConstraints(t *testing.T) {
+	pk, err := GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	csr, err := GenerateCSR(&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+		CommonName: "intermediate",
+		PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+	}})
+	require.NoError(t, err)
+	csrDER, err := EncodeCSR(csr, pk)
+	require.NoError(t, err)
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	tests := map[string]struct {
+		isCA              bool
+		maxPathLen        *int32
+		expMaxPathLen     int
+		expMaxPathLenZero bool
+		expCAKeyUsages    bool
+	}{
+		"a leaf certificate should not be a CA": {
+			expMaxPathLen: -1,
+		},
+		"a CA without maxPathLen should have no path length constraint": {
+			isCA:           true,
+			expMaxPathLen:  -1,
+			expCAKeyUsages: true,
+		},
+		"a CA with maxPathLen should have a path length constraint": {
+			isCA:           true,
+			maxPathLen:     int32Ptr(2),
+			expMaxPathLen:  2,
+			expCAKeyUsages: true,
+		},
+		"a CA with a maxPathLen of zero should have a path length constraint of zero": {
+			isCA:              true,
+			maxPathLen:        int32Ptr(0),
+			expMaxPathLen:     0,
+			expMaxPathLenZero: true,
+			expCAKeyUsages:    true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template, err := GenerateTemplateFromCertificateRequest(&cmapi.CertificateRequest{
+				Spec: cmapi.CertificateRequestSpec{
+					Request:    csrPEM,
+					IsCA:       test.isCA,
+					MaxPathLen: test.maxPathLen,
+				},
+			})
+			require.NoError(t, err)
+
+			_, cert, err := SignCertificate(template, template, pk.Public(), pk)
+			require.NoError(t, err)
+
+			assert.True(t, cert.BasicConstraintsValid)
+			assert.Equal(t, test.isCA, cert.IsCA)
+			assert.Equal(t, test.expMaxPathLen, cert.MaxPathLen)
+			assert.Equal(t, test.expMaxPathLenZero, cert.MaxPathLenZero)
+
+			caKeyUsages := x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+			if test.expCAKeyUsages {
+				assert.Equal(t, caKeyUsages, cert.KeyUsage&caKeyUsages)
+			} else {
+				assert.Zero(t, cert.KeyUsage&caKeyUsages)
+			}
+		})
+	}
+}
+
+func TestGenerateTemplateFromCertificateRequestSignatureAlgorithm(t *testing.T) {
+	pk, err := GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	csr, err := GenerateCSR(&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+		CommonName: "leaf",
+		PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+	}})
+	require.NoError(t, err)
+	csrDER, err := EncodeCSR(csr, pk)
+	require.NoError(t, err)
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	tests := map[string]struct {
+		sigAlgo    cmapi.SignatureAlgorithm
+		expSigAlgo x509.SignatureAlgorithm
+		expErr     bool
+	}{
+		"should leave the signature algorithm to the signer if not set": {
+			expSigAlgo: x509.UnknownSignatureAlgorithm,
+		},
+		"should use the requested signature algorithm": {
+			sigAlgo:    cmapi.ECDSAWithSHA384SignatureAlgorithm,
+			expSigAlgo: x509.ECDSAWithSHA384,
+		},
+		"should error on an unsupported signature algorithm": {
+			sigAlgo: cmapi.SignatureAlgorithm("MD5WithRSA"),
+			expErr:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template, err := GenerateTemplateFromCertificateRequest(&cmapi.CertificateRequest{
+				Spec: cmapi.CertificateRequestSpec{
+					Request:            csrPEM,
+					SignatureAlgorithm: test.sigAlgo,
+				},
+			})
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expSigAlgo, template.SignatureAlgorithm)
+		})
+	}
+}
+
+func TestSignatureAlgorithmMatchesKey(t *testing.T) {
+	rsaPK, err := GenerateRSAPrivateKey(2048)
+	require.NoError(t, err)
+	ecPK, err := GenerateECPrivateKey(256)
+	require.NoError(t, err)
+
+	assert.NoError(t, SignatureAlgorithmMatchesKey(x509.UnknownSignatureAlgorithm, ecPK.Public()))
+	assert.NoError(t, SignatureAlgorithmMatchesKey(x509.SHA384WithRSA, rsaPK.Public()))
+	assert.NoError(t, SignatureAlgorithmMatchesKey(x509.ECDSAWithSHA512, ecPK.Public()))
+	assert.Error(t, SignatureAlgorithmMatchesKey(x509.SHA384WithRSA, ecPK.Public()))
+	assert.Error(t, SignatureAlgorithmMatchesKey(x509.ECDSAWithSHA256, rsaPK.Public()))
+	assert.Error(t, SignatureAlgorithmMatchesKey(x509.MD5WithRSA, rsaPK.Public()))
+}
+
+func TestGenerateTemplateFromCertificateRequestExtKeyUsages(t *testing.T) {
+	pk, err := GenerateECPrivateKey(256)
+	require.NoError(t, err)
+	csr, err := GenerateCSR(&cmapi.Certificate{Spec: cmapi.CertificateSpec{
+		CommonName: "ocsp-responder",
+		PrivateKey: &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm},
+	}})
+	require.NoError(t, err)
+	csrDER, err := EncodeCSR(csr, pk)
+	require.NoError(t, err)
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	tests := map[string]struct {
+		usages  []cmapi.KeyUsage
+		expEKUs []x509.ExtKeyUsage
+		expOIDs []asn1.ObjectIdentifier
+	}{
+		"ocsp signing should add the id-kp-OCSPSigning extended key usage": {
+			usages:  []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageOCSPSigning},
+			expEKUs: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+			expOIDs: []asn1.ObjectIdentifier{oidExtKeyUsageOCSPSigning},
+		},
+		"any should add the anyExtendedKeyUsage extended key usage": {
+			usages:  []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageAny},
+			expEKUs: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+			expOIDs: []asn1.ObjectIdentifier{oidExtKeyUsageAny},
+		},
+		"ocsp signing and any should add both extended key usages": {
+			usages:  []cmapi.KeyUsage{cmapi.UsageOCSPSigning, cmapi.UsageAny, cmapi.UsageServerAuth},
+			expEKUs: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning, x509.ExtKeyUsageAny, x509.ExtKeyUsageServerAuth},
+			expOIDs: []asn1.ObjectIdentifier{oidExtKeyUsageOCSPSigning, oidExtKeyUsageAny, oidExtKeyUsageServerAuth},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template, err := GenerateTemplateFromCertificateRequest(&cmapi.CertificateRequest{
+				Spec: cmapi.CertificateRequestSpec{
+					Request: csrPEM,
+					Usages:  test.usages,
+				},
+			})
+			require.NoError(t, err)
+
+			_, cert, err := SignCertificate(template, template, pk.Public(), pk)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expEKUs, cert.ExtKeyUsage)
+
+			var oids []asn1.ObjectIdentifier
+			for _, ext := range cert.Extensions {
+				if ext.Id.Equal(OIDExtensionExtendedKeyUsage) {
+					_, err := asn1.Unmarshal(ext.Value, &oids)
+					require.NoError(t, err)
+				}
+			}
+			assert.Equal(t, test.expOIDs, oids)
+		})
+	}
+}
+
+func TestGenerateTemplateFromCertificateRequestOtherNames(t *testing.T) {
+	upn := cmapi.OtherName{OID: "1.3.6.1.4.1.311.20.2.3", UTF8Value: "alice@corp.example.com"}
+	oidUPN := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+
+	tests := map[string]struct {
+		spec cmapi.CertificateSpec
+	}{
+		"an otherName should be added alongside the other subject alt names": {
+			spec: cmapi.CertificateSpec{
+				CommonName:     "alice",
+				DNSNames:       []string{"alice.corp.example.com"},
+				EmailAddresses: []string{"alice@example.com"},
+				OtherNames:     []cmapi.OtherName{upn},
+			},
+		},
+		"an otherName should be the only subject alt name if no others are requested": {
+			spec: cmapi.CertificateSpec{
+				OtherNames: []cmapi.OtherName{upn},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.spec.PrivateKey = &cmapi.CertificatePrivateKey{Algorithm: cmapi.ECDSAKeyAlgorithm}
+			pk, err := GenerateECPrivateKey(256)
+			require.NoError(t, err)
+			csr, err := GenerateCSR(&cmapi.Certificate{Spec: test.spec})
+			require.NoError(t, err)
+			csrDER, err := EncodeCSR(csr, pk)
+			require.NoError(t, err)
+			csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+			template, err := GenerateTemplateFromCertificateRequest(&cmapi.CertificateRequest{
+				Spec: cmapi.CertificateRequestSpec{Request: csrPEM},
+			})
+			require.NoError(t, err)
+			require.NoError(t, MarkSubjectAltNamesCritical(template, false))
+
+			_, cert, err := SignCertificate(template, template, pk.Public(), pk)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.spec.DNSNames, cert.DNSNames)
+			assert.Equal(t, test.spec.EmailAddresses, cert.EmailAddresses)
+
+			otherNames, err := OtherNamesFromExtensions(cert.Extensions)
+			require.NoError(t, err)
+			assert.Equal(t, []cmapi.OtherName{upn}, otherNames)
+
+			// The otherName must be encoded with the UPN type-id and an
+			// explicitly tagged UTF8String value.
+			var sans []asn1.RawValue
+			for _, ext := range cert.Extensions {
+				if ext.Id.Equal(oidExtensionSubjectAltName) {
+					_, err := asn1.Unmarshal(ext.Value, &sans)
+					require.NoError(t, err)
+					// RFC 5280 requires the extension to be critical if the
+					// subject is empty.
+					assert.Equal(t, test.spec.CommonName == "", ext.Critical)
+				}
+			}
+			var found bool
+			for _, san := range sans {
+				if san.Class != asn1.ClassContextSpecific || san.Tag != nameTypeOtherName {
+					continue
+				}
+				var typeID asn1.ObjectIdentifier
+				rest, err := asn1.Unmarshal(san.Bytes, &typeID)
+				require.NoError(t, err)
+				var value asn1.RawValue
+				_, err = asn1.Unmarshal(rest, &value)
+				require.NoError(t, err)
+				var utf8Value asn1.RawValue
+				_, err = asn1.Unmarshal(value.Bytes, &utf8Value)
+				require.NoError(t, err)
+
+				assert.Equal(t, oidUPN, typeID)
+				assert.Equal(t, 0, value.Tag)
+				assert.Equal(t, asn1.TagUTF8String, utf8Value.Tag)
+				assert.Equal(t, upn.UTF8Value, string(utf8Value.Bytes))
+				found = true
+			}
+			assert.True(t, found, "expected an otherName subject alt name")
+		})
+	}
+}
+
+func TestRoundNotAfter(t *testing.T) {
+	notBefore := time.Date(2021, time.March, 10, 9, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2021, time.March, 12, 14, 35, 20, 0, time.UTC)
+
+	tests := map[string]struct {
+		notAfter    time.Time
+		boundary    time.Duration
+		roundUp     bool
+		renewBefore time.Duration
+		expNotAfter time.Time
+	}{
+		"should not change notAfter if no boundary is set": {
+			notAfter:    notAfter,
+			expNotAfter: notAfter,
+		},
+		"should round notAfter down to the minute": {
+			notAfter:    notAfter,
+			boundary:    time.Minute,
+			expNotAfter: time.Date(2021, time.March, 12, 14, 35, 0, 0, time.UTC),
+		},
+		"should round notAfter up to the hour": {
+			notAfter:    notAfter,
+			boundary:    time.Hour,
+			roundUp:     true,
+			expNotAfter: time.Date(2021, time.March, 12, 15, 0, 0, 0, time.UTC),
+		},
+		"should round notAfter down to the day": {
+			notAfter:    notAfter,
+			boundary:    24 * time.Hour,
+			expNotAfter: time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC),
+		},
+		"should round notAfter up to the day": {
+			notAfter:    notAfter,
+			boundary:    24 * time.Hour,
+			roundUp:     true,
+			expNotAfter: time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC),
+		},
+		"should not change a notAfter that is already on the boundary when rounding up": {
+			notAfter:    time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC),
+			boundary:    24 * time.Hour,
+			roundUp:     true,
+			expNotAfter: time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC),
+		},
+		"should round notAfter up rather than down to before notBefore": {
+			notAfter:    time.Date(2021, time.March, 10, 10, 0, 0, 0, time.UTC),
+			boundary:    24 * time.Hour,
+			expNotAfter: time.Date(2021, time.March, 11, 0, 0, 0, 0, time.UTC),
+		},
+		"should round notAfter down if it remains beyond renewBefore after notBefore": {
+			notAfter:    notAfter,
+			boundary:    24 * time.Hour,
+			renewBefore: 36 * time.Hour,
+			expNotAfter: time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC),
+		},
+		"should round notAfter up if rounding down would leave it within renewBefore of notBefore": {
+			notAfter:    notAfter,
+			boundary:    24 * time.Hour,
+			renewBefore: 39 * time.Hour,
+			expNotAfter: time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template := &x509.Certificate{NotBefore: notBefore, NotAfter: test.notAfter}
+			RoundNotAfter(template, test.boundary, test.roundUp, test.renewBefore)
+			assert.Equal(t, test.expNotAfter, template.NotAfter)
+			assert.Equal(t, notBefore, template.NotBefore)
+		})
+	}
+}
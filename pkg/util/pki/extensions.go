@@ -0,0 +1,573 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// ParseObjectIdentifier parses an object identifier in dotted-decimal
+// notation, e.g. "1.3.6.1.4.1.311.21.7".
+func ParseObjectIdentifier(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid object identifier %q: must contain at least two components", s)
+	}
+
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid object identifier %q: component %q is not a non-negative integer", s, part)
+		}
+		oid[i] = n
+	}
+
+	return oid, nil
+}
+
+var (
+	oidExtensionSubjectAltName      = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionKeyUsage            = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionExtendedKeyUsage    = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtensionBasicConstraints    = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionCertificatePolicies = asn1.ObjectIdentifier{2, 5, 29, 32}
+	oidPolicyQualifierCPS           = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 1}
+	oidExtensionOCSPNoCheck         = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}
+	oidExtensionPolicyConstraints   = asn1.ObjectIdentifier{2, 5, 29, 36}
+	oidExtensionInhibitAnyPolicy    = asn1.ObjectIdentifier{2, 5, 29, 54}
+)
+
+// policyInformation is the ASN.1 PolicyInformation structure defined in
+// RFC 5280, section 4.2.1.4.
+type policyInformation struct {
+	PolicyIdentifier asn1.ObjectIdentifier
+	PolicyQualifiers []policyQualifierInfo `asn1:"optional,omitempty"`
+}
+
+// policyQualifierInfo is the ASN.1 PolicyQualifierInfo structure, restricted
+// to the CPS pointer qualifier.
+type policyQualifierInfo struct {
+	PolicyQualifierID asn1.ObjectIdentifier
+	Qualifier         string `asn1:"ia5"`
+}
+
+// CertificatePoliciesExtension builds a non-critical certificatePolicies
+// extension asserting each of the given policies, with a CPS pointer
+// qualifier for each of the policy's CPS URIs.
+// The standard library does not support encoding policy qualifiers, so the
+// returned extension should be added to a template's ExtraExtensions.
+func CertificatePoliciesExtension(policies []v1.CertificatePolicy) (pkix.Extension, error) {
+	infos := make([]policyInformation, len(policies))
+	for i, policy := range policies {
+		id, err := ParseObjectIdentifier(policy.OID)
+		if err != nil {
+			return pkix.Extension{}, err
+		}
+		infos[i].PolicyIdentifier = id
+		for _, uri := range policy.CPSURIs {
+			infos[i].PolicyQualifiers = append(infos[i].PolicyQualifiers, policyQualifierInfo{
+				PolicyQualifierID: oidPolicyQualifierCPS,
+				Qualifier:         uri,
+			})
+		}
+	}
+
+	value, err := asn1.Marshal(infos)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode certificate policies: %w", err)
+	}
+
+	return pkix.Extension{
+		Id:    oidExtensionCertificatePolicies,
+		Value: value,
+	}, nil
+}
+
+// InhibitAnyPolicyExtension builds the critical inhibitAnyPolicy extension
+// defined in RFC 5280, section 4.2.1.14, whose value is the number of
+// additional certificates that may appear in the path before anyPolicy is no
+// longer permitted.
+func InhibitAnyPolicyExtension(skipCerts int32) (pkix.Extension, error) {
+	if skipCerts < 0 {
+		return pkix.Extension{}, fmt.Errorf("inhibitAnyPolicy must not be negative, got %d", skipCerts)
+	}
+
+	value, err := asn1.Marshal(int(skipCerts))
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode inhibitAnyPolicy: %w", err)
+	}
+
+	return pkix.Extension{
+		Id:       oidExtensionInhibitAnyPolicy,
+		Critical: true,
+		Value:    value,
+	}, nil
+}
+
+// PolicyConstraintsExtension builds the critical policyConstraints extension
+// defined in RFC 5280, section 4.2.1.11. At least one of the constraints
+// must be set, as the extension must not be an empty sequence.
+// The fields are encoded by hand rather than with a struct, as
+// encoding/asn1 omits optional fields that are zero, and zero is a
+// meaningful value for both constraints.
+func PolicyConstraintsExtension(constraints v1.PolicyConstraints) (pkix.Extension, error) {
+	var seq []byte
+	for tag, skipCerts := range []*int32{constraints.RequireExplicitPolicy, constraints.InhibitPolicyMapping} {
+		if skipCerts == nil {
+			continue
+		}
+		if *skipCerts < 0 {
+			return pkix.Extension{}, fmt.Errorf("policy constraints must not be negative, got %d", *skipCerts)
+		}
+		// each constraint is an IMPLICIT context-specific tagged INTEGER
+		value, err := asn1.MarshalWithParams(int(*skipCerts), fmt.Sprintf("tag:%d", tag))
+		if err != nil {
+			return pkix.Extension{}, fmt.Errorf("failed to encode policy constraints: %w", err)
+		}
+		seq = append(seq, value...)
+	}
+	if len(seq) == 0 {
+		return pkix.Extension{}, errors.New("at least one of requireExplicitPolicy or inhibitPolicyMapping must be set")
+	}
+
+	value, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: seq})
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("failed to encode policy constraints: %w", err)
+	}
+
+	return pkix.Extension{
+		Id:       oidExtensionPolicyConstraints,
+		Critical: true,
+		Value:    value,
+	}, nil
+}
+
+// OCSPNoCheckFromAnnotations returns true if the `cert-manager.io/ocsp-no-check`
+// annotation requests the id-pkix-ocsp-nocheck extension.
+func OCSPNoCheckFromAnnotations(annotations map[string]string) bool {
+	return annotations[v1.OCSPNoCheckAnnotationKey] == "true"
+}
+
+// OCSPNoCheckExtension builds the non-critical id-pkix-ocsp-nocheck extension
+// defined in RFC 6960, section 4.2.2.2.1, whose value is an ASN.1 NULL.
+func OCSPNoCheckExtension() pkix.Extension {
+	return pkix.Extension{
+		Id:    oidExtensionOCSPNoCheck,
+		Value: asn1.NullBytes,
+	}
+}
+
+// SubjectKeyIdentifier derives the subject key identifier of the given public
+// key using one of the methods described in RFC 5280, section 4.2.1.2. Both
+// methods hash the subjectPublicKey BIT STRING of the key's
+// SubjectPublicKeyInfo, excluding the tag, length and unused bits.
+func SubjectKeyIdentifier(pub crypto.PublicKey, method v1.SubjectKeyIdentifierMethod) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+	sum := sha1.Sum(spki.PublicKey.Bytes)
+
+	switch method {
+	case v1.SubjectKeyIdentifierMethod1:
+		return sum[:], nil
+	case v1.SubjectKeyIdentifierMethod2:
+		ski := make([]byte, 8)
+		copy(ski, sum[len(sum)-8:])
+		ski[0] = 0x40 | ski[0]&0x0f
+		return ski, nil
+	default:
+		return nil, fmt.Errorf("unsupported subject key identifier method %q", method)
+	}
+}
+
+// CustomExtensionsFromAnnotations builds the list of custom X.509 extensions
+// requested using the `cert-manager.io/extension-<oid>` annotations.
+// Extensions listed in the `cert-manager.io/critical-extensions` annotation
+// will be marked as critical.
+// An error is returned if any requested extension is not permitted by the
+// given policies, or if an extension is marked as critical but the policy
+// does not allow it.
+// The returned extensions are sorted by OID.
+func CustomExtensionsFromAnnotations(annotations map[string]string, allowed []v1.CustomExtensionPolicy) ([]pkix.Extension, error) {
+	critical := make(map[string]bool)
+	for _, oid := range strings.Split(annotations[v1.CriticalExtensionsAnnotationKey], ",") {
+		if oid = strings.TrimSpace(oid); oid != "" {
+			critical[oid] = true
+		}
+	}
+
+	policies := make(map[string]v1.CustomExtensionPolicy)
+	for _, policy := range allowed {
+		policies[policy.OID] = policy
+	}
+
+	requested := make(map[string]bool)
+	var oids []string
+	for k := range annotations {
+		if !strings.HasPrefix(k, v1.CustomExtensionAnnotationKeyPrefix) {
+			continue
+		}
+		oid := strings.TrimPrefix(k, v1.CustomExtensionAnnotationKeyPrefix)
+		requested[oid] = true
+		oids = append(oids, oid)
+	}
+	sort.Strings(oids)
+
+	for oid := range critical {
+		if !requested[oid] {
+			return nil, fmt.Errorf("extension %q is marked as critical but has not been requested", oid)
+		}
+	}
+
+	var extensions []pkix.Extension
+	for _, oid := range oids {
+		policy, ok := policies[oid]
+		if !ok {
+			return nil, fmt.Errorf("extension %q is not permitted by the issuer", oid)
+		}
+		if critical[oid] && !policy.AllowCritical {
+			return nil, fmt.Errorf("extension %q is not permitted to be marked as critical by the issuer", oid)
+		}
+
+		id, err := ParseObjectIdentifier(oid)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := base64.StdEncoding.DecodeString(annotations[v1.CustomExtensionAnnotationKeyPrefix+oid])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode value of extension %q: %w", oid, err)
+		}
+
+		extensions = append(extensions, pkix.Extension{
+			Id:       id,
+			Critical: critical[oid],
+			Value:    value,
+		})
+	}
+
+	return extensions, nil
+}
+
+// derivedExtensions are the extensions that are built from the fields of a
+// CertificateRequest rather than copied from its CSR.
+var derivedExtensions = []asn1.ObjectIdentifier{
+	oidExtensionSubjectAltName,
+	oidExtensionKeyUsage,
+	oidExtensionExtendedKeyUsage,
+	oidExtensionBasicConstraints,
+}
+
+// CustomExtensionsFromCSR returns the extensions requested by the
+// extensionRequest attribute of the given PEM encoded CSR that are permitted
+// by the given policies, so that they can be copied to the issued
+// certificate. Extensions that are derived from the CertificateRequest, such
+// as the subjectAltName and key usage extensions, are never returned.
+// If no policies are given, requested extensions are ignored. Otherwise an
+// error is returned if an extension is requested that is not permitted, or
+// if an extension is marked as critical but the policy does not allow it.
+func CustomExtensionsFromCSR(csrPEM []byte, allowed []v1.CustomExtensionPolicy) ([]pkix.Extension, error) {
+	if len(allowed) == 0 {
+		return nil, nil
+	}
+
+	csr, err := DecodeX509CertificateRequestBytes(csrPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string]v1.CustomExtensionPolicy)
+	for _, policy := range allowed {
+		policies[policy.OID] = policy
+	}
+
+	var extensions []pkix.Extension
+	for _, extension := range csr.Extensions {
+		if isDerivedExtension(extension.Id) {
+			continue
+		}
+
+		oid := extension.Id.String()
+		policy, ok := policies[oid]
+		if !ok {
+			return nil, fmt.Errorf("extension %q requested by the CSR is not permitted by the issuer", oid)
+		}
+		if extension.Critical && !policy.AllowCritical {
+			return nil, fmt.Errorf("extension %q requested by the CSR is not permitted to be marked as critical by the issuer", oid)
+		}
+
+		extensions = append(extensions, extension)
+	}
+
+	return extensions, nil
+}
+
+func isDerivedExtension(id asn1.ObjectIdentifier) bool {
+	for _, derived := range derivedExtensions {
+		if id.Equal(derived) {
+			return true
+		}
+	}
+	return false
+}
+
+// GeneralName tags used when encoding the subjectAltName extension, as defined
+// in RFC 5280, section 4.2.1.6.
+const (
+	nameTypeOtherName = 0
+	nameTypeEmail     = 1
+	nameTypeDNS       = 2
+	nameTypeURI       = 6
+	nameTypeIP        = 7
+)
+
+// emptyASN1Subject is the DER encoding of an empty RDNSequence.
+var emptyASN1Subject = []byte{0x30, 0}
+
+// SubjectAltNamesCriticalFromAnnotations returns true if the
+// `cert-manager.io/critical-subject-alt-names` annotation requests that the
+// subjectAltName extension be marked as critical.
+func SubjectAltNamesCriticalFromAnnotations(annotations map[string]string) bool {
+	return annotations[v1.SubjectAltNamesCriticalAnnotationKey] == "true"
+}
+
+// MarkSubjectAltNamesCritical ensures that the subjectAltName extension of the
+// given template is marked as critical if the template's subject is empty, as
+// required by RFC 5280 section 4.2.1.6, or if force is true.
+// The extension is added to the template's ExtraExtensions so that its
+// criticality is explicit. Templates without any subject alternative names
+// are not modified.
+func MarkSubjectAltNamesCritical(template *x509.Certificate, force bool) error {
+	if len(template.DNSNames) == 0 && len(template.EmailAddresses) == 0 &&
+		len(template.IPAddresses) == 0 && len(template.URIs) == 0 &&
+		!hasExtension(template.ExtraExtensions, oidExtensionSubjectAltName) {
+		return nil
+	}
+
+	subjectEmpty, err := isSubjectEmpty(template)
+	if err != nil {
+		return err
+	}
+	if !subjectEmpty && !force {
+		return nil
+	}
+
+	// A subjectAltName extension may already have been requested as a custom
+	// extension, in which case it takes precedence over the template's fields.
+	for i := range template.ExtraExtensions {
+		if template.ExtraExtensions[i].Id.Equal(oidExtensionSubjectAltName) {
+			template.ExtraExtensions[i].Critical = true
+			return nil
+		}
+	}
+
+	value, err := marshalSubjectAltNames(template, nil)
+	if err != nil {
+		return fmt.Errorf("failed to encode subject alternative names: %w", err)
+	}
+
+	template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+		Id:       oidExtensionSubjectAltName,
+		Critical: true,
+		Value:    value,
+	})
+
+	return nil
+}
+
+func isSubjectEmpty(template *x509.Certificate) (bool, error) {
+	subject := template.RawSubject
+	if len(subject) == 0 {
+		var err error
+		subject, err = asn1.Marshal(template.Subject.ToRDNSequence())
+		if err != nil {
+			return false, fmt.Errorf("failed to encode subject: %w", err)
+		}
+	}
+	return bytes.Equal(subject, emptyASN1Subject), nil
+}
+
+func hasExtension(extensions []pkix.Extension, id asn1.ObjectIdentifier) bool {
+	for _, extension := range extensions {
+		if extension.Id.Equal(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalSubjectAltNames encodes the subject alternative names of the template
+// as a GeneralNames sequence, in the same order as the standard library,
+// followed by the given otherName GeneralNames.
+func marshalSubjectAltNames(template *x509.Certificate, otherNames []asn1.RawValue) ([]byte, error) {
+	var rawValues []asn1.RawValue
+	for _, name := range template.DNSNames {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeDNS, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
+	}
+	for _, email := range template.EmailAddresses {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeEmail, Class: asn1.ClassContextSpecific, Bytes: []byte(email)})
+	}
+	for _, rawIP := range template.IPAddresses {
+		// If possible, we always want to encode IPv4 addresses in 4 bytes.
+		ip := rawIP.To4()
+		if ip == nil {
+			ip = rawIP
+		}
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeIP, Class: asn1.ClassContextSpecific, Bytes: ip})
+	}
+	for _, uri := range template.URIs {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeURI, Class: asn1.ClassContextSpecific, Bytes: []byte(uri.String())})
+	}
+	rawValues = append(rawValues, otherNames...)
+	return asn1.Marshal(rawValues)
+}
+
+// setOtherNames adds a subjectAltName extension containing the subject
+// alternative names of the template and the given otherName GeneralNames to
+// the template's ExtraExtensions, as the standard library cannot encode
+// otherNames. Templates are not modified if no otherNames are given.
+func setOtherNames(template *x509.Certificate, otherNames []asn1.RawValue) error {
+	if len(otherNames) == 0 {
+		return nil
+	}
+	value, err := marshalSubjectAltNames(template, otherNames)
+	if err != nil {
+		return fmt.Errorf("failed to encode subject alternative names: %w", err)
+	}
+	template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+		Id:    oidExtensionSubjectAltName,
+		Value: value,
+	})
+	return nil
+}
+
+// otherName is the ASN.1 AnotherName structure defined in RFC 5280, section
+// 4.2.1.6, restricted to values that are UTF8Strings.
+type otherName struct {
+	TypeID asn1.ObjectIdentifier
+	Value  string `asn1:"explicit,tag:0,utf8"`
+}
+
+// marshalOtherNames encodes the given otherNames as GeneralNames.
+func marshalOtherNames(names []v1.OtherName) ([]asn1.RawValue, error) {
+	var rawValues []asn1.RawValue
+	for _, name := range names {
+		id, err := ParseObjectIdentifier(name.OID)
+		if err != nil {
+			return nil, err
+		}
+		der, err := asn1.MarshalWithParams(otherName{TypeID: id, Value: name.UTF8Value}, "tag:0")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode otherName %q: %w", name.OID, err)
+		}
+		var rawValue asn1.RawValue
+		if _, err := asn1.Unmarshal(der, &rawValue); err != nil {
+			return nil, fmt.Errorf("failed to encode otherName %q: %w", name.OID, err)
+		}
+		rawValues = append(rawValues, rawValue)
+	}
+	return rawValues, nil
+}
+
+// rawOtherNames returns the otherName GeneralNames of the subjectAltName
+// extension in the given extensions, if any.
+func rawOtherNames(extensions []pkix.Extension) ([]asn1.RawValue, error) {
+	for _, extension := range extensions {
+		if !extension.Id.Equal(oidExtensionSubjectAltName) {
+			continue
+		}
+		var rawValues []asn1.RawValue
+		if rest, err := asn1.Unmarshal(extension.Value, &rawValues); err != nil {
+			return nil, fmt.Errorf("failed to decode subject alternative names: %w", err)
+		} else if len(rest) != 0 {
+			return nil, errors.New("failed to decode subject alternative names: trailing data")
+		}
+		var otherNames []asn1.RawValue
+		for _, rawValue := range rawValues {
+			if rawValue.Class == asn1.ClassContextSpecific && rawValue.Tag == nameTypeOtherName {
+				otherNames = append(otherNames, rawValue)
+			}
+		}
+		return otherNames, nil
+	}
+	return nil, nil
+}
+
+// OtherNamesFromExtensions returns the otherName subject alternative names of
+// the subjectAltName extension in the given extensions, e.g. those of a
+// parsed certificate or CSR, which the standard library does not decode.
+// An error is returned for otherNames whose value is not a UTF8String.
+func OtherNamesFromExtensions(extensions []pkix.Extension) ([]v1.OtherName, error) {
+	rawValues, err := rawOtherNames(extensions)
+	if err != nil {
+		return nil, err
+	}
+	var names []v1.OtherName
+	for _, rawValue := range rawValues {
+		var name otherName
+		if _, err := asn1.UnmarshalWithParams(rawValue.FullBytes, &name, "tag:0"); err != nil {
+			return nil, fmt.Errorf("failed to decode otherName: %w", err)
+		}
+		names = append(names, v1.OtherName{OID: name.TypeID.String(), UTF8Value: name.Value})
+	}
+	return names, nil
+}
+
+// OmitCommonNameSubjectAltName removes the subject alternative names of the
+// given template if the only one is a DNS name equal to the template's Common
+// Name, so that the certificate is issued without a subjectAltName extension.
+// Templates requesting any other subject alternative names, including as a
+// custom extension, are not modified. It returns true if the template was
+// modified.
+func OmitCommonNameSubjectAltName(template *x509.Certificate) bool {
+	commonName := template.Subject.CommonName
+	if len(commonName) == 0 || len(template.DNSNames) != 1 || template.DNSNames[0] != commonName ||
+		len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0 || len(template.URIs) > 0 {
+		return false
+	}
+	for _, extension := range template.ExtraExtensions {
+		if extension.Id.Equal(oidExtensionSubjectAltName) {
+			return false
+		}
+	}
+
+	template.DNSNames = nil
+	return true
+}
@@ -0,0 +1,581 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pki
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+func TestParseObjectIdentifier(t *testing.T) {
+	tests := map[string]struct {
+		oid     string
+		want    asn1.ObjectIdentifier
+		wantErr bool
+	}{
+		"valid oid":           {oid: "1.3.6.1.4.1.311.21.7", want: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}},
+		"single component":    {oid: "1", wantErr: true},
+		"empty component":     {oid: "1..2", wantErr: true},
+		"non-numeric":         {oid: "1.3.foo", wantErr: true},
+		"negative component":  {oid: "1.-3", wantErr: true},
+		"empty string":        {oid: "", wantErr: true},
+		"two valid component": {oid: "2.5", want: asn1.ObjectIdentifier{2, 5}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseObjectIdentifier(test.oid)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCustomExtensionsFromAnnotations(t *testing.T) {
+	const (
+		oidA = "1.3.6.1.4.1.311.21.7"
+		oidB = "1.2.3.4"
+	)
+	tests := map[string]struct {
+		annotations map[string]string
+		allowed     []cmapi.CustomExtensionPolicy
+		want        []pkix.Extension
+		wantErr     string
+	}{
+		"no annotations should return no extensions": {
+			annotations: map[string]string{"foo": "bar"},
+			allowed:     []cmapi.CustomExtensionPolicy{{OID: oidA}},
+		},
+		"a permitted extension should be returned as non-critical": {
+			annotations: map[string]string{cmapi.CustomExtensionAnnotationKeyPrefix + oidA: "AQID"},
+			allowed:     []cmapi.CustomExtensionPolicy{{OID: oidA}},
+			want: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}, Value: []byte{1, 2, 3}},
+			},
+		},
+		"multiple extensions should be returned sorted by OID": {
+			annotations: map[string]string{
+				cmapi.CustomExtensionAnnotationKeyPrefix + oidA: "AQID",
+				cmapi.CustomExtensionAnnotationKeyPrefix + oidB: "BAU=",
+			},
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA}, {OID: oidB}},
+			want: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Value: []byte{4, 5}},
+				{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}, Value: []byte{1, 2, 3}},
+			},
+		},
+		"an extension not in the allowlist should error": {
+			annotations: map[string]string{cmapi.CustomExtensionAnnotationKeyPrefix + oidA: "AQID"},
+			allowed:     []cmapi.CustomExtensionPolicy{{OID: oidB}},
+			wantErr:     `extension "1.3.6.1.4.1.311.21.7" is not permitted by the issuer`,
+		},
+		"an extension requested with no allowlist should error": {
+			annotations: map[string]string{cmapi.CustomExtensionAnnotationKeyPrefix + oidA: "AQID"},
+			wantErr:     `extension "1.3.6.1.4.1.311.21.7" is not permitted by the issuer`,
+		},
+		"a critical extension should error if the policy does not allow critical": {
+			annotations: map[string]string{
+				cmapi.CustomExtensionAnnotationKeyPrefix + oidA: "AQID",
+				cmapi.CriticalExtensionsAnnotationKey:           oidA,
+			},
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA}},
+			wantErr: `extension "1.3.6.1.4.1.311.21.7" is not permitted to be marked as critical by the issuer`,
+		},
+		"a critical extension should be returned if the policy allows critical": {
+			annotations: map[string]string{
+				cmapi.CustomExtensionAnnotationKeyPrefix + oidA: "AQID",
+				cmapi.CriticalExtensionsAnnotationKey:           " " + oidA + " ",
+			},
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA, AllowCritical: true}},
+			want: []pkix.Extension{
+				{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}, Critical: true, Value: []byte{1, 2, 3}},
+			},
+		},
+		"an extension marked critical but not requested should error": {
+			annotations: map[string]string{cmapi.CriticalExtensionsAnnotationKey: oidA},
+			allowed:     []cmapi.CustomExtensionPolicy{{OID: oidA, AllowCritical: true}},
+			wantErr:     `extension "1.3.6.1.4.1.311.21.7" is marked as critical but has not been requested`,
+		},
+		"an invalid base64 value should error": {
+			annotations: map[string]string{cmapi.CustomExtensionAnnotationKeyPrefix + oidA: "not base64!"},
+			allowed:     []cmapi.CustomExtensionPolicy{{OID: oidA}},
+			wantErr:     `failed to decode value of extension "1.3.6.1.4.1.311.21.7": illegal base64 data at input byte 3`,
+		},
+		"an invalid OID in the allowlist and annotation should error": {
+			annotations: map[string]string{cmapi.CustomExtensionAnnotationKeyPrefix + "foo": "AQID"},
+			allowed:     []cmapi.CustomExtensionPolicy{{OID: "foo"}},
+			wantErr:     `invalid object identifier "foo": must contain at least two components`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := CustomExtensionsFromAnnotations(test.annotations, test.allowed)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCustomExtensionsFromCSR(t *testing.T) {
+	oidA := asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}
+	oidB := asn1.ObjectIdentifier{1, 2, 3, 4}
+
+	key, err := GenerateECPrivateKey(ECCurve256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrPEM := func(extensions ...pkix.Extension) []byte {
+		der, err := EncodeCSR(&x509.CertificateRequest{
+			Subject:         pkix.Name{CommonName: "example.com"},
+			DNSNames:        []string{"example.com"},
+			ExtraExtensions: extensions,
+		}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	}
+
+	tests := map[string]struct {
+		csr     []byte
+		allowed []cmapi.CustomExtensionPolicy
+		want    []pkix.Extension
+		wantErr string
+	}{
+		"requested extensions should be dropped if no allowlist is set": {
+			csr: csrPEM(pkix.Extension{Id: oidA, Value: []byte{1, 2, 3}}),
+		},
+		"a permitted extension should be returned": {
+			csr:     csrPEM(pkix.Extension{Id: oidA, Value: []byte{1, 2, 3}}),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA.String()}},
+			want:    []pkix.Extension{{Id: oidA, Value: []byte{1, 2, 3}}},
+		},
+		"multiple permitted extensions should be returned in the order they were requested": {
+			csr: csrPEM(
+				pkix.Extension{Id: oidA, Value: []byte{1, 2, 3}},
+				pkix.Extension{Id: oidB, Value: []byte{4, 5}},
+			),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidB.String()}, {OID: oidA.String()}},
+			want: []pkix.Extension{
+				{Id: oidA, Value: []byte{1, 2, 3}},
+				{Id: oidB, Value: []byte{4, 5}},
+			},
+		},
+		"extensions derived from the CertificateRequest should be dropped": {
+			csr: csrPEM(
+				pkix.Extension{Id: oidExtensionKeyUsage, Critical: true, Value: []byte{3, 2, 5, 160}},
+				pkix.Extension{Id: oidA, Value: []byte{1, 2, 3}},
+			),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA.String()}},
+			want:    []pkix.Extension{{Id: oidA, Value: []byte{1, 2, 3}}},
+		},
+		"no extensions should be returned for a CSR that only requests subject alternative names": {
+			csr:     csrPEM(),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA.String()}},
+		},
+		"an extension not in the allowlist should error": {
+			csr:     csrPEM(pkix.Extension{Id: oidA, Value: []byte{1, 2, 3}}),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidB.String()}},
+			wantErr: `extension "1.3.6.1.4.1.311.21.7" requested by the CSR is not permitted by the issuer`,
+		},
+		"a critical extension should error if the policy does not allow critical": {
+			csr:     csrPEM(pkix.Extension{Id: oidA, Critical: true, Value: []byte{1, 2, 3}}),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA.String()}},
+			wantErr: `extension "1.3.6.1.4.1.311.21.7" requested by the CSR is not permitted to be marked as critical by the issuer`,
+		},
+		"a critical extension should be returned if the policy allows critical": {
+			csr:     csrPEM(pkix.Extension{Id: oidA, Critical: true, Value: []byte{1, 2, 3}}),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA.String(), AllowCritical: true}},
+			want:    []pkix.Extension{{Id: oidA, Critical: true, Value: []byte{1, 2, 3}}},
+		},
+		"an invalid CSR should error": {
+			csr:     []byte("not a csr"),
+			allowed: []cmapi.CustomExtensionPolicy{{OID: oidA.String()}},
+			wantErr: "error decoding certificate request PEM block",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := CustomExtensionsFromCSR(test.csr, test.allowed)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCertificatePoliciesExtension(t *testing.T) {
+	tests := map[string]struct {
+		policies []cmapi.CertificatePolicy
+		want     []policyInformation
+		wantErr  string
+	}{
+		"a policy without CPS URIs should have no qualifiers": {
+			policies: []cmapi.CertificatePolicy{{OID: "2.23.140.1.2.1"}},
+			want: []policyInformation{
+				{PolicyIdentifier: asn1.ObjectIdentifier{2, 23, 140, 1, 2, 1}},
+			},
+		},
+		"CPS URIs should be encoded as CPS qualifiers": {
+			policies: []cmapi.CertificatePolicy{
+				{OID: "1.2.3.4", CPSURIs: []string{"http://example.com/cps", "http://example.org/cps"}},
+				{OID: "2.23.140.1.2.1"},
+			},
+			want: []policyInformation{
+				{
+					PolicyIdentifier: asn1.ObjectIdentifier{1, 2, 3, 4},
+					PolicyQualifiers: []policyQualifierInfo{
+						{PolicyQualifierID: oidPolicyQualifierCPS, Qualifier: "http://example.com/cps"},
+						{PolicyQualifierID: oidPolicyQualifierCPS, Qualifier: "http://example.org/cps"},
+					},
+				},
+				{PolicyIdentifier: asn1.ObjectIdentifier{2, 23, 140, 1, 2, 1}},
+			},
+		},
+		"an invalid OID should error": {
+			policies: []cmapi.CertificatePolicy{{OID: "foo"}},
+			wantErr:  `invalid object identifier "foo": must contain at least two components`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := CertificatePoliciesExtension(test.policies)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, oidExtensionCertificatePolicies, got.Id)
+			assert.False(t, got.Critical)
+
+			var infos []policyInformation
+			rest, err := asn1.Unmarshal(got.Value, &infos)
+			assert.NoError(t, err)
+			assert.Empty(t, rest)
+			assert.Equal(t, test.want, infos)
+		})
+	}
+}
+
+func TestInhibitAnyPolicyExtension(t *testing.T) {
+	tests := map[string]struct {
+		skipCerts int32
+		want      []byte
+		wantErr   string
+	}{
+		"zero should be encoded rather than omitted": {
+			skipCerts: 0,
+			want:      []byte{0x02, 0x01, 0x00},
+		},
+		"a positive value should be encoded as an INTEGER": {
+			skipCerts: 2,
+			want:      []byte{0x02, 0x01, 0x02},
+		},
+		"a negative value should error": {
+			skipCerts: -1,
+			wantErr:   "inhibitAnyPolicy must not be negative, got -1",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := InhibitAnyPolicyExtension(test.skipCerts)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, oidExtensionInhibitAnyPolicy, got.Id)
+			assert.True(t, got.Critical)
+			assert.Equal(t, test.want, got.Value)
+		})
+	}
+}
+
+func TestPolicyConstraintsExtension(t *testing.T) {
+	tests := map[string]struct {
+		constraints cmapi.PolicyConstraints
+		want        []byte
+		wantErr     string
+	}{
+		"both constraints should be encoded with their context-specific tags": {
+			constraints: cmapi.PolicyConstraints{RequireExplicitPolicy: pointer.Int32Ptr(0), InhibitPolicyMapping: pointer.Int32Ptr(2)},
+			want:        []byte{0x30, 0x06, 0x80, 0x01, 0x00, 0x81, 0x01, 0x02},
+		},
+		"only requireExplicitPolicy should be encoded if inhibitPolicyMapping is not set": {
+			constraints: cmapi.PolicyConstraints{RequireExplicitPolicy: pointer.Int32Ptr(1)},
+			want:        []byte{0x30, 0x03, 0x80, 0x01, 0x01},
+		},
+		"only inhibitPolicyMapping should be encoded if requireExplicitPolicy is not set": {
+			constraints: cmapi.PolicyConstraints{InhibitPolicyMapping: pointer.Int32Ptr(0)},
+			want:        []byte{0x30, 0x03, 0x81, 0x01, 0x00},
+		},
+		"no constraints should error": {
+			wantErr: "at least one of requireExplicitPolicy or inhibitPolicyMapping must be set",
+		},
+		"a negative constraint should error": {
+			constraints: cmapi.PolicyConstraints{InhibitPolicyMapping: pointer.Int32Ptr(-1)},
+			wantErr:     "policy constraints must not be negative, got -1",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := PolicyConstraintsExtension(test.constraints)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, oidExtensionPolicyConstraints, got.Id)
+			assert.True(t, got.Critical)
+			assert.Equal(t, test.want, got.Value)
+		})
+	}
+}
+
+func TestSubjectKeyIdentifier(t *testing.T) {
+	pk, err := GenerateECPrivateKey(ECCurve256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The standard library derives the subject key identifier of CA
+	// certificates using method 1, giving an independent expected value.
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	_, ca, err := SignCertificate(tmpl, tmpl, pk.Public(), pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method1 := ca.SubjectKeyId
+	if len(method1) != 20 {
+		t.Fatalf("expected a 20 byte subject key identifier on the CA, got %x", method1)
+	}
+	method2 := append([]byte{0x40 | method1[12]&0x0f}, method1[13:]...)
+
+	tests := map[string]struct {
+		method  cmapi.SubjectKeyIdentifierMethod
+		want    []byte
+		wantErr string
+	}{
+		"method 1 should be the full SHA-1 hash of the public key": {
+			method: cmapi.SubjectKeyIdentifierMethod1,
+			want:   method1,
+		},
+		"method 2 should be the type field followed by the least significant 60 bits of the hash": {
+			method: cmapi.SubjectKeyIdentifierMethod2,
+			want:   method2,
+		},
+		"an unknown method should error": {
+			method:  "foo",
+			wantErr: `unsupported subject key identifier method "foo"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := SubjectKeyIdentifier(pk.Public(), test.method)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMarkSubjectAltNamesCritical(t *testing.T) {
+	pk, err := GenerateECPrivateKey(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uri, err := url.Parse("spiffe://example.com/workload")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		subject  pkix.Name
+		dnsNames []string
+		ips      []net.IP
+		uris     []*url.URL
+		force    bool
+
+		// wantCritical is nil if no subjectAltName extension is expected
+		wantCritical *bool
+	}{
+		"SAN should be critical if the subject is empty": {
+			dnsNames:     []string{"example.com"},
+			ips:          []net.IP{net.ParseIP("10.0.0.1")},
+			uris:         []*url.URL{uri},
+			wantCritical: boolPtr(true),
+		},
+		"SAN should not be critical if the subject is not empty": {
+			subject:      pkix.Name{CommonName: "example.com"},
+			dnsNames:     []string{"example.com"},
+			wantCritical: boolPtr(false),
+		},
+		"SAN should be critical if the subject is not empty but criticality is forced": {
+			subject:      pkix.Name{CommonName: "example.com"},
+			dnsNames:     []string{"example.com"},
+			force:        true,
+			wantCritical: boolPtr(true),
+		},
+		"SAN should be critical if only an organization is set and criticality is forced": {
+			subject:      pkix.Name{Organization: []string{"cert-manager"}},
+			uris:         []*url.URL{uri},
+			force:        true,
+			wantCritical: boolPtr(true),
+		},
+		"no SAN extension should be added if there are no SANs": {
+			force: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(1),
+				Subject:      test.subject,
+				NotBefore:    time.Now(),
+				NotAfter:     time.Now().Add(time.Hour),
+				DNSNames:     test.dnsNames,
+				IPAddresses:  test.ips,
+				URIs:         test.uris,
+			}
+			assert.NoError(t, MarkSubjectAltNamesCritical(template, test.force))
+
+			// Self-sign with a non-empty issuer so that the subject of the
+			// issued certificate is that of the template.
+			issuer := &x509.Certificate{Subject: pkix.Name{CommonName: "issuer"}}
+			_, cert, err := SignCertificate(template, issuer, pk.Public(), pk)
+			assert.NoError(t, err)
+
+			var san *pkix.Extension
+			for i := range cert.Extensions {
+				if cert.Extensions[i].Id.Equal(oidExtensionSubjectAltName) {
+					san = &cert.Extensions[i]
+				}
+			}
+			if test.wantCritical == nil {
+				assert.Nil(t, san)
+				return
+			}
+			if !assert.NotNil(t, san) {
+				return
+			}
+			assert.Equal(t, *test.wantCritical, san.Critical)
+
+			// The SANs must be encoded the same as by the standard library
+			assert.Equal(t, test.dnsNames, cert.DNSNames)
+			assert.Equal(t, len(test.ips), len(cert.IPAddresses))
+			for i := range test.ips {
+				assert.True(t, test.ips[i].Equal(cert.IPAddresses[i]))
+			}
+			assert.Equal(t, test.uris, cert.URIs)
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestOmitCommonNameSubjectAltName(t *testing.T) {
+	tests := map[string]struct {
+		template     *x509.Certificate
+		wantOmitted  bool
+		wantDNSNames []string
+	}{
+		"SANs should be omitted if the only SAN is the common name": {
+			template: &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "example.com"},
+				DNSNames: []string{"example.com"},
+			},
+			wantOmitted: true,
+		},
+		"SANs should not be omitted if there are other DNS names": {
+			template: &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "example.com"},
+				DNSNames: []string{"example.com", "www.example.com"},
+			},
+			wantDNSNames: []string{"example.com", "www.example.com"},
+		},
+		"SANs should not be omitted if the DNS name differs from the common name": {
+			template: &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "example.com"},
+				DNSNames: []string{"www.example.com"},
+			},
+			wantDNSNames: []string{"www.example.com"},
+		},
+		"SANs should not be omitted if there are IP addresses": {
+			template: &x509.Certificate{
+				Subject:     pkix.Name{CommonName: "example.com"},
+				DNSNames:    []string{"example.com"},
+				IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+			},
+			wantDNSNames: []string{"example.com"},
+		},
+		"SANs should not be omitted if there is no common name": {
+			template: &x509.Certificate{
+				DNSNames: []string{"example.com"},
+			},
+			wantDNSNames: []string{"example.com"},
+		},
+		"SANs should not be omitted if requested as a custom extension": {
+			template: &x509.Certificate{
+				Subject:         pkix.Name{CommonName: "example.com"},
+				DNSNames:        []string{"example.com"},
+				ExtraExtensions: []pkix.Extension{{Id: oidExtensionSubjectAltName, Value: []byte{0x30, 0}}},
+			},
+			wantDNSNames: []string{"example.com"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.wantOmitted, OmitCommonNameSubjectAltName(test.template))
+			assert.Equal(t, test.wantDNSNames, test.template.DNSNames)
+		})
+	}
+}
@@ -92,39 +92,44 @@ func GenerateRSAPrivateKey(keySize int) (*rsa.PrivateKey, error) {
 	return rsa.GenerateKey(rand.Reader, keySize)
 }
 
-// GenerateECPrivateKey will generate an ECDSA private key of the given size.
-// It can be used to generate 256, 384 and 521 sized keys.
-func GenerateECPrivateKey(keySize int) (*ecdsa.PrivateKey, error) {
-	var ecCurve elliptic.Curve
-
+// ECCurveForKeySize returns the elliptic curve used for ECDSA keys of the
+// given size. Only the 256, 384 and 521 sizes are supported, which correspond
+// to the NIST P-256, P-384 and P-521 curves respectively.
+func ECCurveForKeySize(keySize int) (elliptic.Curve, error) {
 	switch keySize {
 	case ECCurve256:
-		ecCurve = elliptic.P256()
+		return elliptic.P256(), nil
 	case ECCurve384:
-		ecCurve = elliptic.P384()
+		return elliptic.P384(), nil
 	case ECCurve521:
-		ecCurve = elliptic.P521()
+		return elliptic.P521(), nil
 	default:
 		return nil, fmt.Errorf("unsupported ecdsa key size specified: %d", keySize)
 	}
+}
+
+// GenerateECPrivateKey will generate an ECDSA private key of the given size.
+// It can be used to generate 256, 384 and 521 sized keys.
+func GenerateECPrivateKey(keySize int) (*ecdsa.PrivateKey, error) {
+	ecCurve, err := ECCurveForKeySize(keySize)
+	if err != nil {
+		return nil, err
+	}
 
 	return ecdsa.GenerateKey(ecCurve, rand.Reader)
 }
 
 // EncodePrivateKey will encode a given crypto.PrivateKey by first inspecting
 // the type of key encoding and then inspecting the type of key provided.
-// It only supports encoding RSA or ECDSA keys.
+// The PKCS#1 encoding only applies to RSA keys; all other keys, such as ECDSA
+// and Ed25519 keys, are always encoded using PKCS#8.
 func EncodePrivateKey(pk crypto.PrivateKey, keyEncoding v1.PrivateKeyEncoding) ([]byte, error) {
 	switch keyEncoding {
 	case v1.PrivateKeyEncoding(""), v1.PKCS1:
-		switch k := pk.(type) {
-		case *rsa.PrivateKey:
+		if k, ok := pk.(*rsa.PrivateKey); ok {
 			return EncodePKCS1PrivateKey(k), nil
-		case *ecdsa.PrivateKey:
-			return EncodeECPrivateKey(k)
-		default:
-			return nil, fmt.Errorf("error encoding private key: unknown key type: %T", pk)
 		}
+		return EncodePKCS8PrivateKey(pk)
 	case v1.PKCS8:
 		return EncodePKCS8PrivateKey(pk)
 	default:
@@ -143,7 +148,7 @@ func EncodePKCS1PrivateKey(pk *rsa.PrivateKey) []byte {
 func EncodePKCS8PrivateKey(pk interface{}) ([]byte, error) {
 	keyBytes, err := x509.MarshalPKCS8PrivateKey(pk)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error encoding private key: %s", err.Error())
 	}
 	block := &pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}
 
@@ -19,6 +19,7 @@ package pki
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -215,6 +216,11 @@ func TestGeneratePrivateKeyForCertificate(t *testing.T) {
 						t.Error("expected key to be on specified curve")
 						return
 					}
+
+					if key.Curve.Params().Name != curve.Params().Name {
+						t.Errorf("expected key to use curve %s, but got %s", curve.Params().Name, key.Curve.Params().Name)
+						return
+					}
 				}
 			}
 		}
@@ -225,6 +231,52 @@ func TestGeneratePrivateKeyForCertificate(t *testing.T) {
 	}
 }
 
+func TestECCurveForKeySize(t *testing.T) {
+	tests := map[string]struct {
+		keySize       int
+		expectedCurve elliptic.Curve
+		expectedErr   string
+	}{
+		"256 selects P-256": {
+			keySize:       ECCurve256,
+			expectedCurve: elliptic.P256(),
+		},
+		"384 selects P-384": {
+			keySize:       ECCurve384,
+			expectedCurve: elliptic.P384(),
+		},
+		"521 selects P-521": {
+			keySize:       ECCurve521,
+			expectedCurve: elliptic.P521(),
+		},
+		"512 is not supported": {
+			keySize:     512,
+			expectedErr: "unsupported ecdsa key size specified: 512",
+		},
+		"0 is not supported": {
+			keySize:     0,
+			expectedErr: "unsupported ecdsa key size specified: 0",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			curve, err := ECCurveForKeySize(test.keySize)
+			if test.expectedErr != "" {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %q, but got: %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if curve != test.expectedCurve {
+				t.Errorf("expected curve %s, but got %s", test.expectedCurve.Params().Name, curve.Params().Name)
+			}
+		})
+	}
+}
+
 func signTestCert(key crypto.Signer) *x509.Certificate {
 	commonName := "testingcert"
 
@@ -455,6 +507,81 @@ O7WnDn8nuLFdW+NzzbIrTw==
 	}
 }
 
+func TestEncodePrivateKeyPEMBlockType(t *testing.T) {
+	rsaKey, err := GenerateRSAPrivateKey(MinRSAKeySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := GenerateECPrivateKey(ECCurve256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		key          crypto.PrivateKey
+		keyEncoding  v1.PrivateKeyEncoding
+		expBlockType string
+	}{
+		"rsa private key defaults to pkcs1": {
+			key:          rsaKey,
+			expBlockType: "RSA PRIVATE KEY",
+		},
+		"rsa private key with pkcs1 key encoding": {
+			key:          rsaKey,
+			keyEncoding:  v1.PKCS1,
+			expBlockType: "RSA PRIVATE KEY",
+		},
+		"rsa private key with pkcs8 key encoding": {
+			key:          rsaKey,
+			keyEncoding:  v1.PKCS8,
+			expBlockType: "PRIVATE KEY",
+		},
+		"ecdsa private key defaults to pkcs8": {
+			key:          ecKey,
+			expBlockType: "PRIVATE KEY",
+		},
+		"ecdsa private key with pkcs1 key encoding uses pkcs8": {
+			key:          ecKey,
+			keyEncoding:  v1.PKCS1,
+			expBlockType: "PRIVATE KEY",
+		},
+		"ecdsa private key with pkcs8 key encoding": {
+			key:          ecKey,
+			keyEncoding:  v1.PKCS8,
+			expBlockType: "PRIVATE KEY",
+		},
+		"ed25519 private key with pkcs1 key encoding uses pkcs8": {
+			key:          ed25519Key,
+			keyEncoding:  v1.PKCS1,
+			expBlockType: "PRIVATE KEY",
+		},
+		"ed25519 private key with pkcs8 key encoding": {
+			key:          ed25519Key,
+			keyEncoding:  v1.PKCS8,
+			expBlockType: "PRIVATE KEY",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			encodedKey, err := EncodePrivateKey(test.key, test.keyEncoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			block, _ := pem.Decode(encodedKey)
+			if block == nil {
+				t.Fatal("failed to decode PEM encoded private key")
+			}
+			if block.Type != test.expBlockType {
+				t.Errorf("expected PEM block type %q, got %q", test.expBlockType, block.Type)
+			}
+		})
+	}
+}
+
 func TestPublicKeysEqualECDSA(t *testing.T) {
 	key1, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
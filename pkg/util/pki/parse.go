@@ -17,6 +17,7 @@ limitations under the License.
 package pki
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/rsa"
 	"crypto/x509"
@@ -336,3 +337,81 @@ func (c *chainNode) root() *chainNode {
 
 	return c
 }
+
+// BuildCertificateChain returns the certificate chain of the given
+// certificate, built from the given candidate issuer certificates. The chain
+// contains the given certificate first followed by its issuers, and ends at
+// either a self-signed root or the highest certificate whose issuer is not
+// one of the candidates.
+//
+// If the candidates contain cross-signed certificates, more than one chain
+// may be built. In this case the first chain, in candidate order, whose
+// highest certificate was issued by a certificate with the CommonName
+// preferredRoot is returned. If preferredRoot is empty or no chain ends at
+// such a certificate, the first chain found is returned.
+//
+// Candidates that are not part of the returned chain are discarded.
+func BuildCertificateChain(cert *x509.Certificate, candidates []*x509.Certificate, preferredRoot string) []*x509.Certificate {
+	var first, preferred []*x509.Certificate
+
+	// walk extends the given chain with each candidate that issued its
+	// highest certificate in turn, recording complete chains as they are
+	// found. It returns true once no further chains need to be considered.
+	var walk func(chain []*x509.Certificate) bool
+	walk = func(chain []*x509.Certificate) bool {
+		top := chain[len(chain)-1]
+
+		extended := false
+		if !isSelfSigned(top) {
+			for _, candidate := range candidates {
+				if containsCertificate(chain, candidate) || top.CheckSignatureFrom(candidate) != nil {
+					continue
+				}
+				extended = true
+				// Copy the chain so that sibling branches do not share a
+				// backing array.
+				next := append(append([]*x509.Certificate{}, chain...), candidate)
+				if walk(next) {
+					return true
+				}
+			}
+		}
+		if extended {
+			return false
+		}
+
+		if first == nil {
+			first = chain
+		}
+		if preferredRoot == "" {
+			return true
+		}
+		if top.Issuer.CommonName == preferredRoot {
+			preferred = chain
+			return true
+		}
+		return false
+	}
+	walk([]*x509.Certificate{cert})
+
+	if preferred != nil {
+		return preferred
+	}
+	return first
+}
+
+// isSelfSigned returns true if the given certificate is signed by its own
+// key.
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// containsCertificate returns true if the given certificate is in the list.
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
@@ -333,3 +333,116 @@ func TestParseSingleCertificateChain(t *testing.T) {
 		})
 	}
 }
+
+// mustCrossSign returns a copy of the given bundle's certificate, with the
+// same subject and public key, that has been signed by the given issuer.
+func mustCrossSign(t *testing.T, bundle, issuer *testBundle) *testBundle {
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := *bundle.cert
+	template.SerialNumber = serialNumber
+
+	certPEM, cert, err := SignCertificate(&template, issuer.cert, bundle.cert.PublicKey, issuer.pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testBundle{pem: certPEM, cert: cert, pk: bundle.pk}
+}
+
+func TestBuildCertificateChain(t *testing.T) {
+	rootA := mustCreateBundle(t, nil, "root-a")
+	rootB := mustCreateBundle(t, nil, "root-b")
+	// rootA cross-signed by rootB, allowing chains under rootA to also chain
+	// to rootB.
+	crossA := mustCrossSign(t, rootA, rootB)
+	intermediate := mustCreateBundle(t, rootA, "intermediate")
+	random := mustCreateBundle(t, nil, "random")
+
+	tests := map[string]struct {
+		candidates    []*testBundle
+		preferredRoot string
+		expChain      []*testBundle
+	}{
+		"a certificate with no candidates should return only that certificate": {
+			expChain: []*testBundle{intermediate},
+		},
+		"a linear chain should be returned in order": {
+			candidates: []*testBundle{rootA},
+			expChain:   []*testBundle{intermediate, rootA},
+		},
+		"candidates that are not part of the chain should be discarded": {
+			candidates: []*testBundle{random, rootA},
+			expChain:   []*testBundle{intermediate, rootA},
+		},
+		"with no preferred root, the first chain in candidate order should be returned": {
+			candidates: []*testBundle{rootA, crossA, rootB},
+			expChain:   []*testBundle{intermediate, rootA},
+		},
+		"with no preferred root and a different candidate order, the first chain in candidate order should be returned": {
+			candidates: []*testBundle{crossA, rootB, rootA},
+			expChain:   []*testBundle{intermediate, crossA, rootB},
+		},
+		"a preferred root should select the chain through the cross-signed certificate": {
+			candidates:    []*testBundle{rootA, crossA, rootB},
+			preferredRoot: "root-b",
+			expChain:      []*testBundle{intermediate, crossA, rootB},
+		},
+		"a preferred root should select the chain ending at that root": {
+			candidates:    []*testBundle{crossA, rootB, rootA},
+			preferredRoot: "root-a",
+			expChain:      []*testBundle{intermediate, rootA},
+		},
+		"a preferred root that is not present should select the chain ending at a certificate it issued": {
+			candidates:    []*testBundle{rootA, crossA},
+			preferredRoot: "root-b",
+			expChain:      []*testBundle{intermediate, crossA},
+		},
+		"an unknown preferred root should fall back to the first chain": {
+			candidates:    []*testBundle{rootA, crossA, rootB},
+			preferredRoot: "unknown",
+			expChain:      []*testBundle{intermediate, rootA},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var candidates []*x509.Certificate
+			for _, b := range test.candidates {
+				candidates = append(candidates, b.cert)
+			}
+
+			chain := BuildCertificateChain(intermediate.cert, candidates, test.preferredRoot)
+
+			var got, exp []string
+			for _, c := range chain {
+				got = append(got, string(c.Raw))
+			}
+			for _, b := range test.expChain {
+				exp = append(exp, string(b.cert.Raw))
+			}
+			if !reflect.DeepEqual(got, exp) {
+				t.Errorf("unexpected chain, exp=%v got=%v", commonNames(test.expChain), chainCommonNames(chain))
+			}
+		})
+	}
+}
+
+func commonNames(bundles []*testBundle) []string {
+	var names []string
+	for _, b := range bundles {
+		names = append(names, b.cert.Subject.CommonName+" issued by "+b.cert.Issuer.CommonName)
+	}
+	return names
+}
+
+func chainCommonNames(chain []*x509.Certificate) []string {
+	var names []string
+	for _, c := range chain {
+		names = append(names, c.Subject.CommonName+" issued by "+c.Issuer.CommonName)
+	}
+	return names
+}
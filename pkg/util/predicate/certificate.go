@@ -19,7 +19,9 @@ package predicate
 import (
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/jetstack/cert-manager/pkg/apis/certmanager"
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 )
 
 // CertificateSecretName returns a predicate that used to filter Certificates
@@ -31,6 +33,55 @@ func CertificateSecretName(name string) Func {
 	}
 }
 
+// CertificateTrustAnchorsSecretName returns a predicate that used to filter
+// Certificates to only those with the given 'spec.trustAnchors.name'.
+func CertificateTrustAnchorsSecretName(name string) Func {
+	return func(obj runtime.Object) bool {
+		crt := obj.(*cmapi.Certificate)
+		return crt.Spec.TrustAnchors != nil && crt.Spec.TrustAnchors.Name == name
+	}
+}
+
+// CertificateCSRSecretName returns a predicate that used to filter
+// Certificates to only those with the given 'spec.csrSecretRef.name'.
+func CertificateCSRSecretName(name string) Func {
+	return func(obj runtime.Object) bool {
+		crt := obj.(*cmapi.Certificate)
+		return crt.Spec.CSRSecretRef != nil && crt.Spec.CSRSecretRef.Name == name
+	}
+}
+
+// CertificateKeystorePasswordSecretName returns a predicate that used to
+// filter Certificates to only those with the given
+// 'spec.keystores.pkcs12.passwordSecretRef.name' or
+// 'spec.keystores.jks.passwordSecretRef.name'.
+func CertificateKeystorePasswordSecretName(name string) Func {
+	return func(obj runtime.Object) bool {
+		crt := obj.(*cmapi.Certificate)
+		if crt.Spec.Keystores == nil {
+			return false
+		}
+		if ks := crt.Spec.Keystores.PKCS12; ks != nil && ks.PasswordSecretRef.Name == name {
+			return true
+		}
+		if ks := crt.Spec.Keystores.JKS; ks != nil && ks.PasswordSecretRef.Name == name {
+			return true
+		}
+		return false
+	}
+}
+
+// CertificateIntermediatesSecretName returns a predicate that used to filter
+// Certificates to only those with the given
+// 'cert-manager.io/intermediates-secret-name' annotation.
+func CertificateIntermediatesSecretName(name string) Func {
+	return func(obj runtime.Object) bool {
+		crt := obj.(*cmapi.Certificate)
+		secretName, ok := crt.Annotations[cmapi.IntermediatesSecretNameAnnotationKey]
+		return ok && secretName == name
+	}
+}
+
 // CertificateSecretName returns a predicate that used to filter Certificates
 // to only those with the given 'status.nextPrivateKeySecretName'.
 // It is not possible to select Certificates with a 'nil' secret name using
@@ -44,3 +95,26 @@ func CertificateNextPrivateKeySecretName(name string) Func {
 		return *crt.Status.NextPrivateKeySecretName == name
 	}
 }
+
+// CertificateIssuerRef returns a predicate that used to filter Certificates
+// to only those that reference the cert-manager issuer with the given kind
+// and name in either 'spec.issuerRef' or 'spec.issuerRefs'. References that
+// do not set a kind are treated as referencing an Issuer.
+func CertificateIssuerRef(kind, name string) Func {
+	return func(obj runtime.Object) bool {
+		crt := obj.(*cmapi.Certificate)
+		for _, ref := range append([]cmmeta.ObjectReference{crt.Spec.IssuerRef}, crt.Spec.IssuerRefs...) {
+			if ref.Group != "" && ref.Group != certmanager.GroupName {
+				continue
+			}
+			refKind := ref.Kind
+			if refKind == "" {
+				refKind = cmapi.IssuerKind
+			}
+			if refKind == kind && ref.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -19,9 +19,11 @@ package predicate
 import (
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
 	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 )
 
 func TestCertificateSecretName(t *testing.T) {
@@ -56,6 +58,126 @@ func TestCertificateSecretName(t *testing.T) {
 	}
 }
 
+func TestCertificateCSRSecretName(t *testing.T) {
+	certWithCSRSecretRef := func(ref *cmmeta.SecretKeySelector) *cmapi.Certificate {
+		return &cmapi.Certificate{
+			Spec: cmapi.CertificateSpec{CSRSecretRef: ref},
+		}
+	}
+	tests := map[string]struct {
+		secretName string
+		cert       *cmapi.Certificate
+		expected   bool
+	}{
+		"returns true if secret name matches": {
+			secretName: "abc",
+			cert:       certWithCSRSecretRef(&cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "abc"}}),
+			expected:   true,
+		},
+		"returns false if secret name does not match": {
+			secretName: "abc",
+			cert:       certWithCSRSecretRef(&cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: "abcd"}}),
+			expected:   false,
+		},
+		"returns false if csrSecretRef is nil": {
+			secretName: "",
+			cert:       certWithCSRSecretRef(nil),
+			expected:   false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CertificateCSRSecretName(test.secretName)(test.cert)
+			if got != test.expected {
+				t.Errorf("unexpected response: got=%t, exp=%t", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestCertificateKeystorePasswordSecretName(t *testing.T) {
+	ref := func(name string) cmmeta.SecretKeySelector {
+		return cmmeta.SecretKeySelector{LocalObjectReference: cmmeta.LocalObjectReference{Name: name}, Key: "password"}
+	}
+	tests := map[string]struct {
+		secretName string
+		cert       *cmapi.Certificate
+		expected   bool
+	}{
+		"returns false if no keystores are set": {
+			secretName: "abc",
+			cert:       &cmapi.Certificate{},
+			expected:   false,
+		},
+		"returns true if the PKCS12 password secret name matches": {
+			secretName: "abc",
+			cert: &cmapi.Certificate{Spec: cmapi.CertificateSpec{Keystores: &cmapi.CertificateKeystores{
+				PKCS12: &cmapi.PKCS12Keystore{PasswordSecretRef: ref("abc")},
+			}}},
+			expected: true,
+		},
+		"returns true if the JKS password secret name matches": {
+			secretName: "abc",
+			cert: &cmapi.Certificate{Spec: cmapi.CertificateSpec{Keystores: &cmapi.CertificateKeystores{
+				PKCS12: &cmapi.PKCS12Keystore{PasswordSecretRef: ref("abcd")},
+				JKS:    &cmapi.JKSKeystore{PasswordSecretRef: ref("abc")},
+			}}},
+			expected: true,
+		},
+		"returns false if no password secret name matches": {
+			secretName: "abc",
+			cert: &cmapi.Certificate{Spec: cmapi.CertificateSpec{Keystores: &cmapi.CertificateKeystores{
+				JKS: &cmapi.JKSKeystore{PasswordSecretRef: ref("abcd")},
+			}}},
+			expected: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CertificateKeystorePasswordSecretName(test.secretName)(test.cert)
+			if got != test.expected {
+				t.Errorf("unexpected response: got=%t, exp=%t", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestCertificateIntermediatesSecretName(t *testing.T) {
+	tests := map[string]struct {
+		secretName string
+		cert       *cmapi.Certificate
+		expected   bool
+	}{
+		"returns false if the annotation is not set": {
+			secretName: "abc",
+			cert:       &cmapi.Certificate{},
+			expected:   false,
+		},
+		"returns true if the annotation matches": {
+			secretName: "abc",
+			cert: &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{cmapi.IntermediatesSecretNameAnnotationKey: "abc"},
+			}},
+			expected: true,
+		},
+		"returns false if the annotation does not match": {
+			secretName: "abc",
+			cert: &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{cmapi.IntermediatesSecretNameAnnotationKey: "abcd"},
+			}},
+			expected: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CertificateIntermediatesSecretName(test.secretName)(test.cert)
+			if got != test.expected {
+				t.Errorf("unexpected response: got=%t, exp=%t", got, test.expected)
+			}
+		})
+	}
+}
+
 func TestCertificateNextPrivateKeySecretName(t *testing.T) {
 	certWithSecretName := func(s *string) *cmapi.Certificate {
 		return &cmapi.Certificate{
@@ -92,3 +214,61 @@ func TestCertificateNextPrivateKeySecretName(t *testing.T) {
 		})
 	}
 }
+
+func TestCertificateIssuerRef(t *testing.T) {
+	certWithIssuerRefs := func(refs ...cmmeta.ObjectReference) *cmapi.Certificate {
+		return &cmapi.Certificate{
+			Spec: cmapi.CertificateSpec{IssuerRef: refs[0], IssuerRefs: refs[1:]},
+		}
+	}
+	tests := map[string]struct {
+		kind, name string
+		cert       *cmapi.Certificate
+		expected   bool
+	}{
+		"returns true if issuerRef matches": {
+			kind:     cmapi.ClusterIssuerKind,
+			name:     "abc",
+			cert:     certWithIssuerRefs(cmmeta.ObjectReference{Name: "abc", Kind: cmapi.ClusterIssuerKind, Group: "cert-manager.io"}),
+			expected: true,
+		},
+		"returns true if an issuerRef without a kind matches an Issuer": {
+			kind:     cmapi.IssuerKind,
+			name:     "abc",
+			cert:     certWithIssuerRefs(cmmeta.ObjectReference{Name: "abc"}),
+			expected: true,
+		},
+		"returns true if a fallback issuerRef matches": {
+			kind:     cmapi.IssuerKind,
+			name:     "abc",
+			cert:     certWithIssuerRefs(cmmeta.ObjectReference{Name: "def"}, cmmeta.ObjectReference{Name: "abc", Kind: cmapi.IssuerKind}),
+			expected: true,
+		},
+		"returns false if the kind does not match": {
+			kind:     cmapi.ClusterIssuerKind,
+			name:     "abc",
+			cert:     certWithIssuerRefs(cmmeta.ObjectReference{Name: "abc"}),
+			expected: false,
+		},
+		"returns false if the name does not match": {
+			kind:     cmapi.IssuerKind,
+			name:     "abc",
+			cert:     certWithIssuerRefs(cmmeta.ObjectReference{Name: "abcd"}),
+			expected: false,
+		},
+		"returns false if the issuerRef is for an external issuer": {
+			kind:     cmapi.IssuerKind,
+			name:     "abc",
+			cert:     certWithIssuerRefs(cmmeta.ObjectReference{Name: "abc", Kind: cmapi.IssuerKind, Group: "example.com"}),
+			expected: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CertificateIssuerRef(test.kind, test.name)(test.cert)
+			if got != test.expected {
+				t.Errorf("unexpected response: got=%t, exp=%t", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing contains helpers used to emit OpenTelemetry traces of the
+// certificate issuance pipeline. As each stage of the pipeline is run by a
+// different controller, the trace context is carried between them in the
+// TraceContextAnnotationKey annotation of the CertificateRequest they act on.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+const (
+	// tracerName is the name of the tracer used to instrument cert-manager.
+	tracerName = "github.com/jetstack/cert-manager"
+
+	// traceparentHeader is the W3C trace context header that is stored in
+	// the trace context annotation.
+	traceparentHeader = "traceparent"
+)
+
+const (
+	// ExporterNone disables the export of traces.
+	ExporterNone = ""
+
+	// ExporterStdout writes each span as JSON to the given writer once it
+	// has ended.
+	ExporterStdout = "stdout"
+)
+
+var propagator = propagation.TraceContext{}
+
+// NewTracerProvider returns a TracerProvider that exports the spans of the
+// named service using the given exporter. If the exporter is ExporterNone, a
+// provider that records nothing is returned. The returned provider should be
+// passed to Shutdown once it is no longer used, so that any buffered spans
+// are exported.
+func NewTracerProvider(exporter, serviceName string, w io.Writer) (trace.TracerProvider, error) {
+	switch exporter {
+	case ExporterNone:
+		return trace.NewNoopTracerProvider(), nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, fmt.Errorf("error creating stdout trace exporter: %w", err)
+		}
+		return sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exp),
+			sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+}
+
+// Shutdown exports any spans buffered by the given provider and stops it. It
+// does nothing for providers that do not buffer spans.
+func Shutdown(ctx context.Context, provider trace.TracerProvider) error {
+	p, ok := provider.(*sdktrace.TracerProvider)
+	if !ok {
+		return nil
+	}
+	return p.Shutdown(ctx)
+}
+
+// Tracer returns the tracer used to instrument cert-manager from the given
+// provider. If the provider is nil, a tracer that records nothing is
+// returned.
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = trace.NewNoopTracerProvider()
+	}
+	return provider.Tracer(tracerName)
+}
+
+// ContextFromObject returns a copy of ctx that carries the remote span
+// context recorded in the trace context annotation of the given object, so
+// that spans started from it become children of that span. If the object
+// does not have a valid trace context annotation, ctx is returned unchanged.
+func ContextFromObject(ctx context.Context, obj metav1.Object) context.Context {
+	traceparent, ok := obj.GetAnnotations()[cmapi.TraceContextAnnotationKey]
+	if !ok {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{traceparentHeader: traceparent})
+}
+
+// AnnotateObject records the span context carried by ctx in the trace context
+// annotation of the given object. It returns true if the annotation was
+// changed. Nothing is recorded if ctx does not carry a valid span context,
+// e.g. because tracing is not enabled.
+func AnnotateObject(ctx context.Context, obj metav1.Object) bool {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	traceparent := carrier.Get(traceparentHeader)
+	if traceparent == "" {
+		return false
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations[cmapi.TraceContextAnnotationKey] == traceparent {
+		return false
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[cmapi.TraceContextAnnotationKey] = traceparent
+	obj.SetAnnotations(annotations)
+	return true
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The cert-manager Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+)
+
+func TestAnnotateObject(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	t.Run("does not annotate the object if tracing is not enabled", func(t *testing.T) {
+		ctx, span := Tracer(nil).Start(context.Background(), "test")
+		defer span.End()
+
+		crt := &cmapi.Certificate{}
+		assert.False(t, AnnotateObject(ctx, crt))
+		assert.Nil(t, crt.Annotations)
+	})
+
+	t.Run("records the span context in the annotation", func(t *testing.T) {
+		ctx, span := Tracer(provider).Start(context.Background(), "test")
+		defer span.End()
+
+		crt := &cmapi.Certificate{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"foo": "bar"}}}
+		assert.True(t, AnnotateObject(ctx, crt))
+		assert.Equal(t, "bar", crt.Annotations["foo"])
+		assert.Contains(t, crt.Annotations[cmapi.TraceContextAnnotationKey], span.SpanContext().SpanID().String())
+
+		// annotating the object again with the same span is a no-op
+		assert.False(t, AnnotateObject(ctx, crt))
+	})
+}
+
+func TestContextFromObject(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := Tracer(provider)
+
+	parentCtx, parent := tracer.Start(context.Background(), "parent")
+	parent.End()
+	cr := &cmapi.CertificateRequest{}
+	require.True(t, AnnotateObject(parentCtx, cr))
+
+	t.Run("spans started from the context are children of the annotated span", func(t *testing.T) {
+		_, child := tracer.Start(ContextFromObject(context.Background(), cr), "child")
+		child.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "child", spans[1].Name())
+		assert.Equal(t, parent.SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+		assert.True(t, spans[1].Parent().IsRemote())
+	})
+
+	t.Run("returns the context unchanged if the object is not annotated", func(t *testing.T) {
+		ctx := ContextFromObject(context.Background(), &cmapi.CertificateRequest{})
+		assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	})
+
+	t.Run("ignores an invalid annotation", func(t *testing.T) {
+		ctx := ContextFromObject(context.Background(), &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{cmapi.TraceContextAnnotationKey: "invalid"}},
+		})
+		assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+	})
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	t.Run("records nothing if no exporter is configured", func(t *testing.T) {
+		provider, err := NewTracerProvider(ExporterNone, "test", nil)
+		require.NoError(t, err)
+
+		_, span := Tracer(provider).Start(context.Background(), "test")
+		span.End()
+		assert.False(t, span.SpanContext().IsValid())
+		assert.NoError(t, Shutdown(context.Background(), provider))
+	})
+
+	t.Run("writes ended spans to the writer of the stdout exporter", func(t *testing.T) {
+		var buf bytes.Buffer
+		provider, err := NewTracerProvider(ExporterStdout, "test-service", &buf)
+		require.NoError(t, err)
+
+		_, span := Tracer(provider).Start(context.Background(), "test-span")
+		span.End()
+		require.NoError(t, Shutdown(context.Background(), provider))
+
+		assert.Contains(t, buf.String(), `"Name":"test-span"`)
+		assert.Contains(t, buf.String(), span.SpanContext().TraceID().String())
+		assert.Contains(t, buf.String(), "test-service")
+	})
+
+	t.Run("returns an error for an unknown exporter", func(t *testing.T) {
+		_, err := NewTracerProvider("jaeger", "test", nil)
+		assert.Error(t, err)
+	})
+}
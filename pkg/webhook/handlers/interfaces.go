@@ -23,6 +23,8 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/client-go/kubernetes"
+
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 )
 
 type ValidatingAdmissionHook interface {
@@ -32,7 +34,7 @@ type ValidatingAdmissionHook interface {
 
 	// InitPlugins will initialise all plugins which are registered for this
 	// validating admission hook.
-	InitPlugins(client kubernetes.Interface)
+	InitPlugins(client kubernetes.Interface, cmclient cmclient.Interface)
 }
 
 type MutatingAdmissionHook interface {
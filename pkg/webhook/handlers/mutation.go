@@ -46,12 +46,12 @@ func NewRegistryBackedMutator(log logr.Logger, scheme *runtime.Scheme, registry
 	}
 }
 
-func (c *RegistryBackedMutator) Mutate(_ context.Context, admissionSpec *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+func (c *RegistryBackedMutator) Mutate(ctx context.Context, admissionSpec *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
 	status := &admissionv1.AdmissionResponse{}
 	status.UID = admissionSpec.UID
 
 	// Generate a patch from the appropriate functions installed in the mutation registry
-	patch, err := c.registry.Mutate(admissionSpec)
+	patch, err := c.registry.Mutate(ctx, admissionSpec)
 	if err != nil {
 		status.Result = &metav1.Status{
 			Status: metav1.StatusFailure, Code: http.StatusInternalServerError, Reason: metav1.StatusReasonInternalError,
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 
+	cmclient "github.com/jetstack/cert-manager/pkg/client/clientset/versioned"
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
 	"github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/validation/plugins"
 )
@@ -51,9 +52,9 @@ func NewRegistryBackedValidator(log logr.Logger, scheme *runtime.Scheme, registr
 	}
 }
 
-func (r *registryBackedValidator) InitPlugins(client kubernetes.Interface) {
+func (r *registryBackedValidator) InitPlugins(client kubernetes.Interface, cmclient cmclient.Interface) {
 	for _, plugin := range r.plugins {
-		plugin.Init(client)
+		plugin.Init(client, cmclient)
 	}
 }
 
@@ -17,12 +17,18 @@ limitations under the License.
 package webhook
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
 
 	"github.com/jetstack/cert-manager/pkg/internal/api/mutation"
 	"github.com/jetstack/cert-manager/pkg/internal/api/validation"
 	acmeinstall "github.com/jetstack/cert-manager/pkg/internal/apis/acme/install"
+	cridentity "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/identity/certificaterequests"
 	cminstall "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/install"
+	cmmutation "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/mutation"
+	cmvalidation "github.com/jetstack/cert-manager/pkg/internal/apis/certmanager/validation"
 	metainstall "github.com/jetstack/cert-manager/pkg/internal/apis/meta/install"
 )
 
@@ -37,23 +43,76 @@ var (
 	// Scheme is a Kubernetes runtime.Scheme with all internal and external API
 	// versions for cert-manager types registered.
 	Scheme = runtime.NewScheme()
-
-	// ValidationRegistry is a validation registry with all required
-	// validations that should be enforced by the webhook component.
-	ValidationRegistry = validation.NewRegistry(Scheme)
-
-	// MutationRegistry is a mutation registry with all required
-	// mutations that should be enforced by the webhook component.
-	MutationRegistry = mutation.NewRegistry(Scheme)
 )
 
 func init() {
 	cminstall.Install(Scheme)
 	acmeinstall.Install(Scheme)
 	metainstall.Install(Scheme)
+}
+
+// Options configures the validation and mutation of cert-manager resources
+// performed by the webhook component.
+type Options struct {
+	// DefaultCertificateSecretName enables setting spec.secretName to the name
+	// of the Certificate when it is omitted.
+	DefaultCertificateSecretName bool
+
+	// LowercaseCertificateDNSNames enables converting the spec.dnsNames of
+	// Certificates to lower case.
+	LowercaseCertificateDNSNames bool
+
+	// MinimumCertificateDuration is the shortest spec.duration of
+	// Certificates that is accepted.
+	MinimumCertificateDuration time.Duration
 
-	cminstall.InstallValidation(ValidationRegistry)
-	acmeinstall.InstallValidation(ValidationRegistry)
+	// NamespaceLister is used to read the default Issuer annotations from the
+	// Namespace of a Certificate. If nil, no default Issuer is applied.
+	NamespaceLister corelisters.NamespaceLister
+
+	// TrustedRequestDelegators are the usernames that may attribute the
+	// CertificateRequests they create to another user with the requester
+	// annotations.
+	TrustedRequestDelegators []string
+
+	// RequesterUsernameAnnotation and RequesterGroupsAnnotation are the
+	// annotations read by the webhook to record the user a
+	// CertificateRequest was created on behalf of. If empty, the
+	// cert-manager.io/requester-username and cert-manager.io/requester-groups
+	// annotations are used.
+	RequesterUsernameAnnotation string
+	RequesterGroupsAnnotation   string
+}
+
+func (o Options) identityOptions() cridentity.Options {
+	return cridentity.Options{
+		TrustedDelegators:           o.TrustedRequestDelegators,
+		RequesterUsernameAnnotation: o.RequesterUsernameAnnotation,
+		RequesterGroupsAnnotation:   o.RequesterGroupsAnnotation,
+	}
+}
+
+// NewValidationRegistry returns a validation registry with all required
+// validations that should be enforced by the webhook component, configured
+// with the given options.
+func NewValidationRegistry(opts Options) *validation.Registry {
+	registry := validation.NewRegistry(Scheme)
+	cminstall.InstallValidation(registry, cmvalidation.Options{
+		MinimumCertificateDuration: opts.MinimumCertificateDuration,
+	}, opts.identityOptions())
+	acmeinstall.InstallValidation(registry)
+	return registry
+}
 
-	cminstall.InstallMutation(MutationRegistry)
+// NewMutationRegistry returns a mutation registry with all required mutations
+// that should be enforced by the webhook component, configured with the given
+// options.
+func NewMutationRegistry(opts Options) *mutation.Registry {
+	registry := mutation.NewRegistry(Scheme)
+	cminstall.InstallMutation(registry, cmmutation.Options{
+		DefaultCertificateSecretName: opts.DefaultCertificateSecretName,
+		LowercaseCertificateDNSNames: opts.LowercaseCertificateDNSNames,
+		NamespaceLister:              opts.NamespaceLister,
+	}, opts.identityOptions())
+	return registry
 }
@@ -37,6 +37,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	runtimeutil "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
 	ciphers "k8s.io/component-base/cli/flag"
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -103,6 +104,11 @@ type Server struct {
 	MutationWebhook   handlers.MutatingAdmissionHook
 	ConversionWebhook handlers.ConversionHook
 
+	// InformerFactory, if specified, is started when the server is run, and
+	// its caches are synced before webhook requests are served. It provides
+	// the listers used by the validation and mutation webhooks.
+	InformerFactory informers.SharedInformerFactory
+
 	// Log is an optional logger to write informational and error messages to.
 	// If not specified, no messages will be logged.
 	Log logr.Logger
@@ -134,6 +140,15 @@ func (s *Server) Run(stopCh <-chan struct{}) error {
 		}
 	}()
 
+	if s.InformerFactory != nil {
+		s.InformerFactory.Start(internalStopCh)
+		for informerType, synced := range s.InformerFactory.WaitForCacheSync(stopCh) {
+			if !synced {
+				return fmt.Errorf("failed to wait for %v informer cache to sync", informerType)
+			}
+		}
+	}
+
 	var healthzChan <-chan error
 	var certSourceChan <-chan error
 
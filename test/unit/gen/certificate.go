@@ -56,6 +56,27 @@ func SetCertificateIssuer(o cmmeta.ObjectReference) CertificateModifier {
 	}
 }
 
+func SetCertificateIssuerRefs(refs ...cmmeta.ObjectReference) CertificateModifier {
+	return func(c *v1.Certificate) {
+		c.Spec.IssuerRefs = refs
+	}
+}
+
+func SetCertificateActiveIssuerRef(o cmmeta.ObjectReference) CertificateModifier {
+	return func(c *v1.Certificate) {
+		c.Status.ActiveIssuerRef = &o
+	}
+}
+
+func AddCertificateLastReconciledBy(controller string, t metav1.Time) CertificateModifier {
+	return func(c *v1.Certificate) {
+		c.Status.LastReconciledBy = append(c.Status.LastReconciledBy, v1.CertificateReconciliationRecord{
+			Controller: controller,
+			Time:       t,
+		})
+	}
+}
+
 func SetCertificateDNSNames(dnsNames ...string) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		crt.Spec.DNSNames = dnsNames
@@ -86,6 +107,12 @@ func SetCertificateIsCA(isCA bool) CertificateModifier {
 	}
 }
 
+func SetCertificateMaxPathLen(maxPathLen int32) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.MaxPathLen = &maxPathLen
+	}
+}
+
 func SetCertificateKeyAlgorithm(keyAlgorithm v1.PrivateKeyAlgorithm) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		crt.Spec.PrivateKey.Algorithm = keyAlgorithm
@@ -110,6 +137,30 @@ func SetCertificateSecretName(secretName string) CertificateModifier {
 	}
 }
 
+func SetCertificateAdditionalOutputFormats(formats ...v1.CertificateAdditionalOutputFormat) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.AdditionalOutputFormats = formats
+	}
+}
+
+func SetCertificateKeystores(keystores *v1.CertificateKeystores) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.Keystores = keystores
+	}
+}
+
+func SetCertificateSecretType(secretType string) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.SecretType = secretType
+	}
+}
+
+func SetCertificateSecretKeys(keys v1.CertificateSecretKeys) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.SecretKeys = &keys
+	}
+}
+
 func SetCertificateDuration(duration time.Duration) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		crt.Spec.Duration = &metav1.Duration{Duration: duration}
@@ -122,12 +173,30 @@ func SetCertificateRenewBefore(renewBefore time.Duration) CertificateModifier {
 	}
 }
 
+func SetCertificateIssuanceTimeout(timeout time.Duration) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.IssuanceTimeout = &metav1.Duration{Duration: timeout}
+	}
+}
+
+func SetCertificateRenewalWindow(window v1.CertificateRenewalWindow) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.RenewalWindow = &window
+	}
+}
+
 func SetCertificateNextPrivateKeySecretName(name string) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		crt.Status.NextPrivateKeySecretName = &name
 	}
 }
 
+func UnsetCertificateNextPrivateKeySecretName() CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Status.NextPrivateKeySecretName = nil
+	}
+}
+
 func SetCertificateStatusCondition(c v1.CertificateCondition) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		if len(crt.Status.Conditions) == 0 {
@@ -168,6 +237,12 @@ func SetCertificateRenewalTIme(p metav1.Time) CertificateModifier {
 	}
 }
 
+func SetCertificateIssuanceLatency(d metav1.Duration) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Status.IssuanceLatency = &d
+	}
+}
+
 func SetCertificateOrganization(orgs ...string) CertificateModifier {
 	return func(ch *v1.Certificate) {
 		ch.Spec.Subject.Organizations = orgs
@@ -198,6 +273,12 @@ func SetCertificateUID(uid types.UID) CertificateModifier {
 	}
 }
 
+func SetCertificateDeletionTimestamp(ts metav1.Time) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.DeletionTimestamp = &ts
+	}
+}
+
 func SetCertificateGeneration(gen int64) CertificateModifier {
 	return func(crt *v1.Certificate) {
 		crt.Generation = gen
@@ -251,3 +332,33 @@ func SetCertificateRevisionHistoryLimit(limit int32) CertificateModifier {
 		crt.Spec.RevisionHistoryLimit = &limit
 	}
 }
+
+func SetCertificateCSRSecretRef(name, key string) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.CSRSecretRef = &cmmeta.SecretKeySelector{
+			LocalObjectReference: cmmeta.LocalObjectReference{Name: name},
+			Key:                  key,
+		}
+	}
+}
+
+func SetCertificateTrustAnchors(name, key string) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.TrustAnchors = &cmmeta.SecretKeySelector{
+			LocalObjectReference: cmmeta.LocalObjectReference{Name: name},
+			Key:                  key,
+		}
+	}
+}
+
+func SetCertificateCSRSignatureAlgorithm(sigAlgo v1.SignatureAlgorithm) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.CSRSignatureAlgorithm = sigAlgo
+	}
+}
+
+func SetCertificateSignatureAlgorithm(sigAlgo v1.SignatureAlgorithm) CertificateModifier {
+	return func(crt *v1.Certificate) {
+		crt.Spec.SignatureAlgorithm = sigAlgo
+	}
+}
@@ -62,12 +62,30 @@ func SetCertificateRequestIsCA(isCA bool) CertificateRequestModifier {
 	}
 }
 
+func SetCertificateRequestMaxPathLen(maxPathLen int32) CertificateRequestModifier {
+	return func(cr *v1.CertificateRequest) {
+		cr.Spec.MaxPathLen = &maxPathLen
+	}
+}
+
+func SetCertificateRequestSignatureAlgorithm(sigAlgo v1.SignatureAlgorithm) CertificateRequestModifier {
+	return func(cr *v1.CertificateRequest) {
+		cr.Spec.SignatureAlgorithm = sigAlgo
+	}
+}
+
 func SetCertificateRequestDuration(duration *metav1.Duration) CertificateRequestModifier {
 	return func(cr *v1.CertificateRequest) {
 		cr.Spec.Duration = duration
 	}
 }
 
+func SetCertificateRequestNotBefore(notBefore metav1.Time) CertificateRequestModifier {
+	return func(cr *v1.CertificateRequest) {
+		cr.Spec.NotBefore = &notBefore
+	}
+}
+
 func SetCertificateRequestCA(ca []byte) CertificateRequestModifier {
 	return func(cr *v1.CertificateRequest) {
 		cr.Status.CA = ca
@@ -114,6 +132,18 @@ func SetCertificateRequestName(name string) CertificateRequestModifier {
 	}
 }
 
+func SetCertificateRequestGenerateName(generateName string) CertificateRequestModifier {
+	return func(cr *v1.CertificateRequest) {
+		cr.ObjectMeta.GenerateName = generateName
+	}
+}
+
+func SetCertificateRequestCreationTimestamp(t metav1.Time) CertificateRequestModifier {
+	return func(cr *v1.CertificateRequest) {
+		cr.ObjectMeta.CreationTimestamp = t
+	}
+}
+
 func SetCertificateRequestKeyUsages(usages ...v1.KeyUsage) CertificateRequestModifier {
 	return func(cr *v1.CertificateRequest) {
 		cr.Spec.Usages = usages
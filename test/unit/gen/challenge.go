@@ -17,6 +17,8 @@ limitations under the License.
 package gen
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	cmacme "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 )
@@ -107,3 +109,21 @@ func SetChallengeProcessing(b bool) ChallengeModifier {
 		ch.Status.Processing = b
 	}
 }
+
+func SetChallengeFinalizers(finalizers []string) ChallengeModifier {
+	return func(ch *cmacme.Challenge) {
+		ch.Finalizers = finalizers
+	}
+}
+
+func SetChallengeDeletionTimestamp(ts metav1.Time) ChallengeModifier {
+	return func(ch *cmacme.Challenge) {
+		ch.DeletionTimestamp = &ts
+	}
+}
+
+func SetChallengeFailedAttempts(attempts int32) ChallengeModifier {
+	return func(ch *cmacme.Challenge) {
+		ch.Status.FailedAttempts = attempts
+	}
+}
@@ -21,6 +21,7 @@ import (
 	v1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type IssuerModifier func(v1.GenericIssuer)
@@ -127,6 +128,19 @@ func SetIssuerACMEPrivKeyRef(privateKeyName string) IssuerModifier {
 		}
 	}
 }
+func SetIssuerACMEPreviousPrivKeyRef(privateKeyName string) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		spec := iss.GetSpec()
+		if spec.ACME == nil {
+			spec.ACME = &cmacme.ACMEIssuer{}
+		}
+		spec.ACME.PreviousPrivateKey = &cmmeta.SecretKeySelector{
+			LocalObjectReference: cmmeta.LocalObjectReference{
+				Name: privateKeyName,
+			},
+		}
+	}
+}
 func SetIssuerACMESolvers(solvers []cmacme.ACMEChallengeSolver) IssuerModifier {
 	return func(iss v1.GenericIssuer) {
 		spec := iss.GetSpec()
@@ -226,6 +240,17 @@ func SetIssuerACMELastRegisteredEmail(email string) IssuerModifier {
 	}
 }
 
+func SetIssuerACMERateLimit(lastError string, until *metav1.Time) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		status := iss.GetStatus()
+		if status.ACME == nil {
+			status.ACME = &cmacme.ACMEIssuerStatus{}
+		}
+		status.ACME.LastRateLimitError = lastError
+		status.ACME.RateLimitedUntil = until
+	}
+}
+
 func SetIssuerCA(a v1.CAIssuer) IssuerModifier {
 	return func(iss v1.GenericIssuer) {
 		iss.GetSpec().CA = &a
@@ -340,6 +365,18 @@ func SetIssuerVenafi(a v1.VenafiIssuer) IssuerModifier {
 	}
 }
 
+func SetIssuerAllowedDomains(allowedDomains ...string) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		iss.GetSpec().AllowedDomains = allowedDomains
+	}
+}
+
+func SetIssuerSPIFFETrustDomain(trustDomain string) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		iss.GetSpec().SPIFFE = &v1.SPIFFEOptions{TrustDomain: trustDomain}
+	}
+}
+
 func AddIssuerCondition(c v1.IssuerCondition) IssuerModifier {
 	return func(iss v1.GenericIssuer) {
 		iss.GetStatus().Conditions = append(iss.GetStatus().Conditions, c)
@@ -351,3 +388,27 @@ func SetIssuerNamespace(namespace string) IssuerModifier {
 		iss.GetObjectMeta().Namespace = namespace
 	}
 }
+
+func SetIssuerGeneration(generation int64) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		iss.GetObjectMeta().Generation = generation
+	}
+}
+
+func SetIssuerUID(uid types.UID) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		iss.GetObjectMeta().UID = uid
+	}
+}
+
+func AddIssuerAnnotations(annotations map[string]string) IssuerModifier {
+	return func(iss v1.GenericIssuer) {
+		meta := iss.GetObjectMeta()
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		for k, v := range annotations {
+			meta.Annotations[k] = v
+		}
+	}
+}